@@ -62,12 +62,28 @@ func GetCommands() []*clitool.Command {
 			},
 			Flags: []clitool.Flag{},
 		},
+		{
+			Name:      utils.AuditWorkingDir,
+			Usage:     "Audits a single working directory. Used internally to scan working directories that may be interrupted by a timeout.",
+			ArgsUsage: "<audit file> <results file>",
+			Hidden:    true,
+			Action: func(ctx *clitool.Context) error {
+				if ctx.NArg() != 2 {
+					return fmt.Errorf("the %s command expects the audit file and the results file as its arguments", utils.AuditWorkingDir)
+				}
+				return utils.RunWorkingDirAuditProcess(ctx.Args().Get(0), ctx.Args().Get(1))
+			},
+		},
 	}
 }
 
 func Exec(command FrogbotCommand, commandName string) (err error) {
 	// Get frogbotDetails that contains the config, server, and VCS client
 	log.Info("Frogbot version:", utils.FrogbotVersion)
+	defer utils.ResetRequestTagging()
+	if err = utils.SetRequestTaggingFromEnv(); err != nil {
+		return err
+	}
 	frogbotDetails, err := utils.GetFrogbotDetails(commandName)
 	if err != nil {
 		return err
@@ -1,37 +1,59 @@
 package packagehandlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
 )
 
+// The technologies whose vulnerable dependencies Frogbot can fix
+var fixSupportedTechnologies = []techutils.Technology{
+	techutils.Go, techutils.Poetry, techutils.Pipenv, techutils.Npm, techutils.Yarn, techutils.Pip, techutils.Maven, techutils.Nuget, techutils.Gradle, techutils.Pnpm,
+}
+
+var (
+	// Matches the output of package manager commands that failed due to network or registry hiccups, which may succeed when retried
+	transientCommandErrorRegexp = regexp.MustCompile(`(?i)(ETIMEDOUT|ESOCKETTIMEDOUT|ECONNRESET|ECONNREFUSED|EAI_AGAIN|socket hang up|timed out|i/o timeout|connection reset|connection refused|could not resolve host|temporary failure in name resolution|TLS handshake timeout|unexpected EOF|\b(429|502|503|504)\b)`)
+	// The delay between the attempts of a package manager command that failed due to a transient error
+	commandRetryDelay = 5 * time.Second
+)
+
+// The time to wait for the output of a killed command, as processes it started may keep its output open
+const killedCommandWaitDelay = 10 * time.Second
+
 // PackageHandler interface to hold operations on packages
 type PackageHandler interface {
 	UpdateDependency(details *utils.VulnerabilityDetails) error
 	SetCommonParams(serverDetails *config.ServerDetails, depsRepo string)
+	SetInstallCommand(name string, args []string)
+	SetInstallRetryPolicy(retries int, timeout time.Duration)
 }
 
 func GetCompatiblePackageHandler(vulnDetails *utils.VulnerabilityDetails, details *utils.ScanDetails) (handler PackageHandler) {
 	switch vulnDetails.Technology {
 	case techutils.Go:
-		handler = &GoPackageHandler{}
+		handler = newGoPackageHandler(details)
 	case techutils.Poetry:
 		handler = &PythonPackageHandler{}
 	case techutils.Pipenv:
 		handler = &PythonPackageHandler{}
 	case techutils.Npm:
-		handler = &NpmPackageHandler{}
+		handler = newNpmPackageHandler(details)
 	case techutils.Yarn:
-		handler = &YarnPackageHandler{}
+		handler = newYarnPackageHandler(details)
 	case techutils.Pip:
 		handler = &PythonPackageHandler{pipRequirementsFile: details.PipRequirementsFile}
 	case techutils.Maven:
@@ -46,12 +68,30 @@ func GetCompatiblePackageHandler(vulnDetails *utils.VulnerabilityDetails, detail
 		handler = &UnsupportedPackageHandler{}
 	}
 	handler.SetCommonParams(details.ServerDetails, details.DepsRepo)
+	if details.Project != nil {
+		handler.SetInstallCommand(details.GetInstallCommand(vulnDetails.Technology))
+		handler.SetInstallRetryPolicy(details.GetInstallRetryPolicy())
+	}
 	return
 }
 
+// IsFixSupported returns true if Frogbot can fix the vulnerable dependencies of the technology
+func IsFixSupported(tech techutils.Technology) bool {
+	return slices.Contains(fixSupportedTechnologies, tech)
+}
+
 type CommonPackageHandler struct {
 	serverDetails *config.ServerDetails
 	depsRepo      string
+	// The install command of the project, used to regenerate the lockfile after updating a dependency
+	installCommandName string
+	installCommandArgs []string
+	// Environment variables added to the environment of the package manager commands
+	commandEnv []string
+	// The number of times a package manager command that failed due to a transient error is retried
+	installRetries int
+	// The timeout of each attempt of a package manager command. Zero means no timeout.
+	installTimeout time.Duration
 }
 
 // UpdateDependency updates the impacted package to the fixed version
@@ -63,7 +103,7 @@ func (cph *CommonPackageHandler) UpdateDependency(vulnDetails *utils.Vulnerabili
 	versionOperator := vulnDetails.Technology.GetPackageVersionOperator()
 	fixedPackageArgs := getFixedPackage(impactedPackage, versionOperator, vulnDetails.SuggestedFixedVersion)
 	commandArgs = append(commandArgs, fixedPackageArgs...)
-	return runPackageMangerCommand(vulnDetails.Technology.GetExecCommandName(), vulnDetails.Technology.String(), commandArgs)
+	return cph.runPackageManagerCommand(vulnDetails.Technology.GetExecCommandName(), vulnDetails.Technology.String(), commandArgs)
 }
 
 func (cph *CommonPackageHandler) SetCommonParams(serverDetails *config.ServerDetails, depsRepo string) {
@@ -71,22 +111,83 @@ func (cph *CommonPackageHandler) SetCommonParams(serverDetails *config.ServerDet
 	cph.depsRepo = depsRepo
 }
 
-func runPackageMangerCommand(commandName string, techName string, commandArgs []string) error {
+func (cph *CommonPackageHandler) SetInstallCommand(name string, args []string) {
+	cph.installCommandName = name
+	cph.installCommandArgs = args
+}
+
+func (cph *CommonPackageHandler) SetInstallRetryPolicy(retries int, timeout time.Duration) {
+	cph.installRetries = retries
+	cph.installTimeout = timeout
+}
+
+// Regenerates the lockfile with the install command of the project.
+// If no install command is set, the package manager of the technology runs with the given default args.
+func (cph *CommonPackageHandler) regenerateLockfile(tech techutils.Technology, defaultArgs ...string) error {
+	if cph.installCommandName == "" {
+		return cph.runPackageManagerCommand(tech.GetExecCommandName(), tech.String(), defaultArgs)
+	}
+	return cph.runPackageManagerCommand(cph.installCommandName, tech.String(), cph.installCommandArgs)
+}
+
+// Runs the package manager command with the command environment variables added to the environment of the current process.
+// An attempt that fails due to a transient error, such as a registry hiccup, or that exceeds the install timeout, is retried up to the configured number of retries.
+func (cph *CommonPackageHandler) runPackageManagerCommand(commandName string, techName string, commandArgs []string) (err error) {
 	fullCommand := commandName + " " + strings.Join(commandArgs, " ")
+	attempt := 1
+	for ; ; attempt++ {
+		var isTransient bool
+		if isTransient, err = cph.runCommandAttempt(commandName, commandArgs, fullCommand); err == nil {
+			return nil
+		}
+		if !isTransient || attempt > cph.installRetries {
+			break
+		}
+		log.Warn(fmt.Sprintf("'%s' failed with a transient error, retrying (%d/%d)...", fullCommand, attempt, cph.installRetries))
+		time.Sleep(commandRetryDelay)
+	}
+	if attempt > 1 {
+		return fmt.Errorf("failed to update %s dependency after %d attempts: %w", techName, attempt, err)
+	}
+	return fmt.Errorf("failed to update %s dependency: %w", techName, err)
+}
+
+// Runs a single attempt of the package manager command, which is killed if it exceeds the install timeout.
+// Returns whether the failure of the command is transient, and may not recur if the command is retried.
+func (cph *CommonPackageHandler) runCommandAttempt(commandName string, commandArgs []string, fullCommand string) (isTransient bool, err error) {
 	log.Debug(fmt.Sprintf("Running '%s'", fullCommand))
+	ctx := context.Background()
+	if cph.installTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cph.installTimeout)
+		defer cancel()
+	}
 	//#nosec G204 -- False positive - the subprocess only runs after the user's approval.
-	output, err := exec.Command(commandName, commandArgs...).CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to update %s dependency: '%s' command failed: %s\n%s", techName, fullCommand, err.Error(), output)
+	cmd := exec.CommandContext(ctx, commandName, commandArgs...)
+	cmd.WaitDelay = killedCommandWaitDelay
+	if len(cph.commandEnv) > 0 {
+		cmd.Env = append(os.Environ(), cph.commandEnv...)
 	}
-	return nil
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return false, nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return true, &utils.ErrCommandFailed{Command: fullCommand, Reason: fmt.Sprintf("was killed after exceeding the install timeout of %s", cph.installTimeout), Output: string(output)}
+	}
+	return transientCommandErrorRegexp.Match(output), &utils.ErrCommandFailed{Command: fullCommand, Reason: "failed: " + err.Error(), Output: string(output)}
 }
 
 // Returns the updated package and version as it should be run in the update command:
 // If the package manager expects a single string (example: <packName>@<version>) it returns []string{<packName>@<version>}
 // If the command args suppose to be seperated by spaces (example: <packName> -v <version>) it returns []string{<packName>, "-v", <version>}
 func getFixedPackage(impactedPackage string, versionOperator string, suggestedFixedVersion string) (fixedPackageArgs []string) {
-	fixedPackageString := strings.TrimSpace(impactedPackage) + versionOperator + strings.TrimSpace(suggestedFixedVersion)
+	suggestedFixedVersion = strings.TrimSpace(suggestedFixedVersion)
+	if strings.HasSuffix(versionOperator, "v") {
+		// The version operator of Go already includes the 'v' prefix of the version
+		suggestedFixedVersion = strings.TrimPrefix(suggestedFixedVersion, "v")
+	}
+	fixedPackageString := strings.TrimSpace(impactedPackage) + versionOperator + suggestedFixedVersion
 	fixedPackageArgs = strings.Split(fixedPackageString, " ")
 	return
 }
@@ -1,13 +1,28 @@
 package packagehandlers
 
 import (
+	"fmt"
+	"path/filepath"
+
 	"github.com/jfrog/frogbot/v2/utils"
 	golangutils "github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/golang"
 	goutils "github.com/jfrog/jfrog-cli-core/v2/utils/golang"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 )
 
 type GoPackageHandler struct {
 	CommonPackageHandler
+	// Leaves the vendor directory as it is, instead of re-vendoring the module after the fix
+	skipVendoredDeps bool
+}
+
+func newGoPackageHandler(scanDetails *utils.ScanDetails) *GoPackageHandler {
+	if scanDetails.Project == nil {
+		return &GoPackageHandler{}
+	}
+	return &GoPackageHandler{skipVendoredDeps: scanDetails.UpdateVendoredDependencies != nil && !*scanDetails.UpdateVendoredDependencies}
 }
 
 func (golang *GoPackageHandler) UpdateDependency(vulnDetails *utils.VulnerabilityDetails) error {
@@ -18,5 +33,23 @@ func (golang *GoPackageHandler) UpdateDependency(vulnDetails *utils.Vulnerabilit
 		}
 	}
 	// In Golang, we can address every dependency as a direct dependency.
-	return golang.CommonPackageHandler.UpdateDependency(vulnDetails, vulnDetails.Technology.GetPackageInstallationCommand())
+	if err := golang.CommonPackageHandler.UpdateDependency(vulnDetails, vulnDetails.Technology.GetPackageInstallationCommand()); err != nil {
+		return err
+	}
+	return golang.updateVendorDir(vulnDetails)
+}
+
+// Builds fail when the vendor directory doesn't match go.mod, so a vendored module is re-vendored after its dependency is updated
+func (golang *GoPackageHandler) updateVendorDir(vulnDetails *utils.VulnerabilityDetails) error {
+	isVendored, err := fileutils.IsFileExists(filepath.Join(utils.GoVendorDir, utils.GoVendorManifestFile), false)
+	if err != nil || !isVendored {
+		return err
+	}
+	if golang.skipVendoredDeps {
+		log.Warn(fmt.Sprintf("The vendor directory isn't updated with %s:%s, as updating vendored dependencies is disabled. The module may fail to build until 'go mod vendor' is run.", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+		vulnDetails.AddFixNote(fmt.Sprintf("The %s directory wasn't updated with the fix. Run 'go mod vendor' before merging.", utils.GoVendorDir))
+		return nil
+	}
+	log.Debug("Updating the vendor directory with", vulnDetails.ImpactedDependencyName)
+	return golang.runPackageManagerCommand(techutils.Go.GetExecCommandName(), techutils.Go.String(), []string{"mod", utils.GoVendorDir})
 }
@@ -11,6 +11,7 @@ import (
 const (
 	groovyDescriptorFileSuffix    = "build.gradle"
 	kotlinDescriptorFileSuffix    = "build.gradle.kts"
+	versionCatalogFileSuffix      = "libs.versions.toml"
 	apostrophes                   = "[\\\"|\\']"
 	directMapRegexpEntry          = "\\s*%s\\s*[:|=]\\s*"
 	directStringWithVersionFormat = "%s:%s:%s"
@@ -20,7 +21,25 @@ const (
 // Example: group: "junit", name: "junit", version: "1.0.0" | group = "junit", name = "junit", version = "1.0.0"
 var directMapWithVersionRegexp = getMapRegexpEntry("group") + "," + getMapRegexpEntry("name") + "," + getMapRegexpEntry("version")
 
-var gradleDescriptorsSuffixes = []string{groovyDescriptorFileSuffix, kotlinDescriptorFileSuffix}
+// Regexp pattern for dependencies declared with positional arguments in the Kotlin DSL
+// Example: implementation("junit", "junit", "1.0.0")
+var kotlinPositionalWithVersionRegexp = `\(\s*"%s"\s*,\s*"%s"\s*,\s*"%s"`
+
+// Regexp patterns for libraries declared in a version catalog, which are referenced by the 'libs.' accessors of the build files
+// Example: junit = { module = "junit:junit", version = "1.0.0" }
+var catalogModuleWithVersionRegexp = `module\s*=\s*"%s:%s"\s*,\s*version\s*=\s*"%s"`
+
+// Example: junit = { module = "junit:junit", version.ref = "junit" } | junit = { group = "junit", name = "junit", version.ref = "junit" }
+var catalogVersionRefRegexps = []string{
+	`module\s*=\s*"%s:%s"\s*,\s*version\.ref\s*=\s*"([^"]+)"`,
+	`group\s*=\s*"%s"\s*,\s*name\s*=\s*"%s"\s*,\s*version\.ref\s*=\s*"([^"]+)"`,
+}
+
+// Regexp pattern for a version declared in the [versions] table of a version catalog
+// Example: junit = "1.0.0"
+var catalogVersionEntryRegexp = `(?m)^(\s*%s\s*=\s*")%s(")`
+
+var gradleDescriptorsSuffixes = []string{groovyDescriptorFileSuffix, kotlinDescriptorFileSuffix, versionCatalogFileSuffix}
 
 func getMapRegexpEntry(mapEntry string) string {
 	return fmt.Sprintf(directMapRegexpEntry, mapEntry) + apostrophes + "%s" + apostrophes
@@ -113,12 +132,14 @@ func (gph *GradlePackageHandler) fixVulnerabilityIfExists(descriptorFilePath str
 
 	// Fixing all vulnerable rows given in a map format. For Example: implementation group: "junit", name: "junit", version: "4.7"
 	mapRegexpForVulnerability := fmt.Sprintf(directMapWithVersionRegexp, regexpAdjustedDepGroup, regexpAdjustedDepName, regexpAdjustedImpactedVersion)
-	regexpCompiler := regexp.MustCompile(mapRegexpForVulnerability)
-	if rowsMatches := regexpCompiler.FindAllString(fileContent, -1); rowsMatches != nil {
-		for _, entry := range rowsMatches {
-			fixedRow := strings.Replace(entry, vulnDetails.ImpactedDependencyVersion, vulnDetails.SuggestedFixedVersion, 1)
-			fileContent = strings.ReplaceAll(fileContent, entry, fixedRow)
-		}
+	fileContent = fixVersionInMatchingRows(fileContent, mapRegexpForVulnerability, vulnDetails)
+
+	switch {
+	case strings.HasSuffix(descriptorFilePath, kotlinDescriptorFileSuffix):
+		// Fixing all vulnerable rows given with positional arguments. For Example: implementation("junit", "junit", "4.7")
+		fileContent = fixVersionInMatchingRows(fileContent, fmt.Sprintf(kotlinPositionalWithVersionRegexp, regexpAdjustedDepGroup, regexpAdjustedDepName, regexpAdjustedImpactedVersion), vulnDetails)
+	case strings.HasSuffix(descriptorFilePath, versionCatalogFileSuffix):
+		fileContent = fixVersionCatalogLibrary(fileContent, regexpAdjustedDepGroup, regexpAdjustedDepName, regexpAdjustedImpactedVersion, vulnDetails)
 	}
 
 	// If there is no changes in the file we finish dealing with the current descriptor file
@@ -131,6 +152,28 @@ func (gph *GradlePackageHandler) fixVulnerabilityIfExists(descriptorFilePath str
 	return
 }
 
+// Replaces the impacted version with the fix version in all the rows that match the given regexp pattern
+func fixVersionInMatchingRows(fileContent, rowRegexp string, vulnDetails *utils.VulnerabilityDetails) string {
+	for _, entry := range regexp.MustCompile(rowRegexp).FindAllString(fileContent, -1) {
+		fixedRow := strings.Replace(entry, vulnDetails.ImpactedDependencyVersion, vulnDetails.SuggestedFixedVersion, 1)
+		fileContent = strings.ReplaceAll(fileContent, entry, fixedRow)
+	}
+	return fileContent
+}
+
+// Fixes the version of a library declared in a version catalog, either inline or as a reference to an entry of the [versions] table.
+// A referenced version is updated only if it is the impacted version, so libraries that share the reference are bumped together.
+func fixVersionCatalogLibrary(fileContent, depGroup, depName, impactedVersion string, vulnDetails *utils.VulnerabilityDetails) string {
+	fileContent = fixVersionInMatchingRows(fileContent, fmt.Sprintf(catalogModuleWithVersionRegexp, depGroup, depName, impactedVersion), vulnDetails)
+	for _, versionRefRegexp := range catalogVersionRefRegexps {
+		for _, match := range regexp.MustCompile(fmt.Sprintf(versionRefRegexp, depGroup, depName)).FindAllStringSubmatch(fileContent, -1) {
+			versionEntryRegexp := regexp.MustCompile(fmt.Sprintf(catalogVersionEntryRegexp, regexp.QuoteMeta(match[1]), impactedVersion))
+			fileContent = versionEntryRegexp.ReplaceAllString(fileContent, "${1}"+strings.ReplaceAll(vulnDetails.SuggestedFixedVersion, "$", "$$")+"${2}")
+		}
+	}
+	return fileContent
+}
+
 // Returns separated 'group' and 'name' for a given vulnerability name. In addition replaces every '.' char into '\\.' since the output will be used for a regexp
 func getVulnerabilityGroupAndName(impactedDependencyName string) (depGroup string, depName string, err error) {
 	seperatedImpactedDepName := strings.Split(impactedDependencyName, ":")
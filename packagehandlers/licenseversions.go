@@ -0,0 +1,159 @@
+package packagehandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/gofrog/version"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The maximal number of versions whose license is looked up, for the registries that are queried once per version
+const maxLicenseLookups = 20
+
+// The public registries the licenses of the package versions are looked up in, by ecosystem
+var licenseRegistryUrls = map[string]string{
+	"npm":  "https://registry.npmjs.org",
+	"pypi": "https://pypi.org/pypi",
+}
+
+// Returns the first of the versions, in the given order, whose license is acceptable
+type acceptableLicenseVersionLookup func(registryUrl, packageName string, versions func(available []string) []string, isAcceptable func(license string) bool) (string, error)
+
+var acceptableLicenseVersionLookups = map[string]acceptableLicenseVersionLookup{
+	"npm":  findNpmVersionWithAcceptableLicense,
+	"pypi": findPypiVersionWithAcceptableLicense,
+}
+
+var stableVersionRegexp = regexp.MustCompile(`^v?\d+(\.\d+)*$`)
+
+// FindVersionWithAcceptableLicense returns the lowest stable version of the package above the current version whose license is acceptable, or an empty string if there is none.
+// A license is acceptable if it's one of the allowed licenses, or if no licenses are allowed, if it differs from the violated license.
+func FindVersionWithAcceptableLicense(tech techutils.Technology, packageName, currentVersion, violatedLicense string, allowedLicenses []string) (string, error) {
+	ecosystem, _ := utils.VersionRegistries{}.GetRegistries(tech)
+	lookup, supported := acceptableLicenseVersionLookups[ecosystem]
+	if !supported {
+		return "", fmt.Errorf("looking up the licenses of %s packages isn't supported", tech.ToFormal())
+	}
+	newerVersions := func(available []string) []string {
+		return getNewerStableVersions(currentVersion, available)
+	}
+	isAcceptable := func(license string) bool {
+		return isAcceptableLicense(license, violatedLicense, allowedLicenses)
+	}
+	return lookup(licenseRegistryUrls[ecosystem], packageName, newerVersions, isAcceptable)
+}
+
+// Looks the licenses up in the versions of the package document, e.g. https://registry.npmjs.org/@types%2fnode
+func findNpmVersionWithAcceptableLicense(registryUrl, packageName string, versions func(available []string) []string, isAcceptable func(license string) bool) (string, error) {
+	content, err := getRegistryResource(registryUrl + "/" + url.PathEscape(packageName))
+	if err != nil || content == nil {
+		return "", err
+	}
+	var packageDocument struct {
+		Versions map[string]struct {
+			License json.RawMessage `json:"license"`
+		} `json:"versions"`
+	}
+	if err = json.Unmarshal(content, &packageDocument); err != nil {
+		return "", err
+	}
+	for _, candidate := range versions(maps.Keys(packageDocument.Versions)) {
+		if isAcceptable(parseNpmLicense(packageDocument.Versions[candidate].License)) {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// The license of an npm package is an SPDX expression, or an object with the license in its type in older packages
+func parseNpmLicense(rawLicense json.RawMessage) string {
+	var license string
+	if json.Unmarshal(rawLicense, &license) == nil {
+		return license
+	}
+	var licenseObject struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(rawLicense, &licenseObject) == nil {
+		return licenseObject.Type
+	}
+	return ""
+}
+
+// Looks the releases up in the project of the JSON API, e.g. https://pypi.org/pypi/requests/json, and the license of each release in its own document,
+// as the project only describes the license of the latest release
+func findPypiVersionWithAcceptableLicense(registryUrl, packageName string, versions func(available []string) []string, isAcceptable func(license string) bool) (string, error) {
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/json", registryUrl, packageName))
+	if err != nil || content == nil {
+		return "", err
+	}
+	var project struct {
+		Releases map[string]json.RawMessage `json:"releases"`
+	}
+	if err = json.Unmarshal(content, &project); err != nil {
+		return "", err
+	}
+	for i, candidate := range versions(maps.Keys(project.Releases)) {
+		if i == maxLicenseLookups {
+			log.Debug(fmt.Sprintf("The licenses of the first %d newer versions of %s aren't acceptable. Stopping the lookup...", maxLicenseLookups, packageName))
+			break
+		}
+		if content, err = getRegistryResource(fmt.Sprintf("%s/%s/%s/json", registryUrl, packageName, candidate)); err != nil {
+			return "", err
+		}
+		if content == nil {
+			continue
+		}
+		var release struct {
+			Info struct {
+				License           string `json:"license"`
+				LicenseExpression string `json:"license_expression"`
+			} `json:"info"`
+		}
+		if err = json.Unmarshal(content, &release); err != nil {
+			return "", err
+		}
+		license := release.Info.LicenseExpression
+		if license == "" {
+			license = release.Info.License
+		}
+		if isAcceptable(license) {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// Returns the stable versions above the current version, in ascending order
+func getNewerStableVersions(currentVersion string, available []string) (newer []string) {
+	for _, candidate := range available {
+		// The version library returns a positive number if its argument is the newer version
+		if stableVersionRegexp.MatchString(candidate) && version.NewVersion(currentVersion).Compare(candidate) > 0 {
+			newer = append(newer, candidate)
+		}
+	}
+	slices.SortFunc(newer, func(a, b string) int {
+		return version.NewVersion(b).Compare(a)
+	})
+	return
+}
+
+func isAcceptableLicense(license, violatedLicense string, allowedLicenses []string) bool {
+	if license = strings.Trim(strings.TrimSpace(license), "()"); license == "" {
+		return false
+	}
+	if len(allowedLicenses) == 0 {
+		return !strings.EqualFold(license, violatedLicense)
+	}
+	return slices.ContainsFunc(allowedLicenses, func(allowedLicense string) bool {
+		return strings.EqualFold(license, allowedLicense)
+	})
+}
@@ -12,14 +12,23 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
-const MavenVersionNotAvailableErrorFormat = "Version %s is not available for artifact"
+const (
+	MavenVersionNotAvailableErrorFormat = "Version %s is not available for artifact"
+	defaultMavenType                    = "jar"
+	// A test-jar dependency is the jar of the tests classifier
+	testJarMavenType       = "test-jar"
+	testJarMavenClassifier = "tests"
+)
 
 type gavCoordinate struct {
 	GroupId                     string `xml:"groupId"`
 	ArtifactId                  string `xml:"artifactId"`
 	Version                     string `xml:"version"`
+	Type                        string `xml:"type"`
+	Classifier                  string `xml:"classifier"`
 	foundInDependencyManagement bool
 }
 
@@ -31,9 +40,71 @@ func (gc *gavCoordinate) trimSpaces() *gavCoordinate {
 	gc.GroupId = strings.TrimSpace(gc.GroupId)
 	gc.ArtifactId = strings.TrimSpace(gc.ArtifactId)
 	gc.Version = strings.TrimSpace(gc.Version)
+	gc.Type = strings.TrimSpace(gc.Type)
+	gc.Classifier = strings.TrimSpace(gc.Classifier)
 	return gc
 }
 
+// Returns the coordinate of the dependency as declared in the pom, groupId:artifactId[:type[:classifier]].
+// The versions-maven-plugin matches the dependency by this coordinate.
+func (gc *gavCoordinate) declaredCoordinate() string {
+	coordinate := gc.GroupId + ":" + gc.ArtifactId
+	if gc.Classifier != "" {
+		return coordinate + ":" + getOrDefault(gc.Type, defaultMavenType) + ":" + gc.Classifier
+	}
+	if gc.Type != "" {
+		return coordinate + ":" + gc.Type
+	}
+	return coordinate
+}
+
+// Returns the key the dependency is matched to the vulnerable components by
+func (gc *gavCoordinate) key() string {
+	return getMavenDependencyKey(gc.GroupId, gc.ArtifactId, gc.Type, gc.Classifier)
+}
+
+// Returns groupId:artifactId, followed by the type and the classifier when they aren't the defaults, so artifacts that differ only by their classifier are told apart.
+// The test-jar type is keyed as the jar of the tests classifier, the way Maven resolves it.
+func getMavenDependencyKey(groupId, artifactId, dependencyType, classifier string) string {
+	if dependencyType == testJarMavenType {
+		dependencyType = defaultMavenType
+		classifier = getOrDefault(classifier, testJarMavenClassifier)
+	}
+	dependencyType = getOrDefault(dependencyType, defaultMavenType)
+	key := groupId + ":" + artifactId
+	if classifier != "" {
+		return key + ":" + dependencyType + ":" + classifier
+	}
+	if dependencyType != defaultMavenType {
+		return key + ":" + dependencyType
+	}
+	return key
+}
+
+// Normalizes the coordinate of a vulnerable component, groupId:artifactId[:type[:classifier]], to the key of the dependency it matches in the pom
+func getMavenCoordinateKey(coordinate string) string {
+	parts := strings.Split(coordinate, ":")
+	if len(parts) < 2 {
+		return coordinate
+	}
+	var dependencyType, classifier string
+	// Types never start with a digit, unlike a version that is left in the coordinate
+	if len(parts) > 2 && parts[2] != "" && !unicode.IsDigit(rune(parts[2][0])) {
+		dependencyType = parts[2]
+		if len(parts) > 3 {
+			classifier = parts[3]
+		}
+	}
+	return getMavenDependencyKey(parts[0], parts[1], dependencyType, classifier)
+}
+
+func getOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 type mavenDependency struct {
 	gavCoordinate
 	Dependencies         []mavenDependency `xml:"dependencies>dependency"`
@@ -91,9 +162,9 @@ func (mph *MavenPackageHandler) fillDependenciesMap(pomPath string) error {
 		if dependency.Version == "" {
 			continue
 		}
-		depName := fmt.Sprintf("%s:%s", dependency.GroupId, dependency.ArtifactId)
+		depName := dependency.key()
 		if _, exist := mph.pomDependencies[depName]; !exist {
-			mph.pomDependencies[depName] = pomDependencyDetails{foundInDependencyManagement: dependency.foundInDependencyManagement, currentVersion: dependency.Version}
+			mph.pomDependencies[depName] = pomDependencyDetails{foundInDependencyManagement: dependency.foundInDependencyManagement, currentVersion: dependency.Version, coordinate: dependency.declaredCoordinate()}
 		}
 		if strings.HasPrefix(dependency.Version, "${") {
 			trimmedVersion := strings.Trim(dependency.Version, "${}")
@@ -102,6 +173,7 @@ func (mph *MavenPackageHandler) fillDependenciesMap(pomPath string) error {
 					properties:                  append(mph.pomDependencies[depName].properties, trimmedVersion),
 					currentVersion:              dependency.Version,
 					foundInDependencyManagement: dependency.foundInDependencyManagement,
+					coordinate:                  dependency.declaredCoordinate(),
 				}
 			}
 		}
@@ -129,6 +201,8 @@ type pomDependencyDetails struct {
 	properties                  []string
 	currentVersion              string
 	foundInDependencyManagement bool
+	// The coordinate of the dependency as declared in the pom, including its type and classifier
+	coordinate string
 }
 
 func NewMavenPackageHandler(scanDetails *utils.ScanDetails) *MavenPackageHandler {
@@ -184,7 +258,7 @@ func (mph *MavenPackageHandler) UpdateDependency(vulnDetails *utils.Vulnerabilit
 	var depDetails pomDependencyDetails
 	var exists bool
 	// Check if the impacted package is a direct dependency
-	impactedDependency := vulnDetails.ImpactedDependencyName
+	impactedDependency := getMavenCoordinateKey(vulnDetails.ImpactedDependencyName)
 	if depDetails, exists = mph.pomDependencies[impactedDependency]; !exists {
 		return &utils.ErrUnsupportedFix{
 			PackageName:  vulnDetails.ImpactedDependencyName,
@@ -196,7 +270,7 @@ func (mph *MavenPackageHandler) UpdateDependency(vulnDetails *utils.Vulnerabilit
 		return mph.updateProperties(&depDetails, vulnDetails.SuggestedFixedVersion)
 	}
 
-	return mph.updatePackageVersion(vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, depDetails.foundInDependencyManagement)
+	return mph.updatePackageVersion(depDetails.coordinate, vulnDetails.SuggestedFixedVersion, depDetails.foundInDependencyManagement)
 }
 
 // Returns project's Pom paths. This function requires an execution of maven-dep-tree 'project' command prior to its execution
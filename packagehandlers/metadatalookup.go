@@ -0,0 +1,24 @@
+package packagehandlers
+
+import (
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Applies the metadata lookup policy to a failed lookup of the registry metadata the check of the fix version depends on.
+// Returns an ErrUnsupportedFix if the metadata is required. Otherwise, the fix proceeds without the check, which is noted in the fix.
+func handleMetadataLookupFailure(vulnDetails *utils.VulnerabilityDetails, policy utils.MetadataLookupPolicy, check string, err error) error {
+	log.Warn(fmt.Sprintf("Couldn't fetch the metadata to check the %s of %s %s:\n%s", check, vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, err.Error()))
+	if policy == utils.RequiredMetadataLookupPolicy {
+		return &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.MetadataLookupFailed,
+			Reason:       fmt.Sprintf("the %s couldn't be checked", check),
+		}
+	}
+	vulnDetails.AddFixNote(fmt.Sprintf("The %s of %s %s wasn't checked, as its metadata couldn't be fetched from the registry.", check, vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+	return nil
+}
@@ -0,0 +1,118 @@
+package packagehandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Yarn Berry lockfiles start with a metadata entry, which Yarn Classic lockfiles don't have
+const yarnBerryLockfileMetadata = "__metadata:"
+
+// A lockfile of one of the package managers that install npm packages, and the arguments that regenerate it from package.json without running the scripts of the packages
+type npmLockfile struct {
+	fileName string
+	tech     techutils.Technology
+	args     []string
+}
+
+var npmLockfiles = []npmLockfile{
+	{fileName: "package-lock.json", tech: techutils.Npm, args: []string{"install", npmInstallPackageLockOnlyFlag, npmInstallIgnoreScriptsFlag}},
+	{fileName: "yarn.lock", tech: techutils.Yarn},
+	{fileName: "pnpm-lock.yaml", tech: techutils.Pnpm, args: []string{"install", "--lockfile-only", npmInstallIgnoreScriptsFlag}},
+}
+
+// Updates the lockfiles of the other package managers that are present next to package.json, such as a package-lock.json left over from a migration to Yarn.
+// A stale lockfile keeps the vulnerable version for the builds that install with its package manager.
+// Unless updating all the lockfiles is enabled, only the lockfile of the package manager declared in the packageManager field of package.json is updated,
+// and the others are flagged in the pull request.
+func (cph *CommonPackageHandler) updateOtherNpmLockfiles(vulnDetails *utils.VulnerabilityDetails, updateAll bool) (err error) {
+	packageManager, err := getNpmPackageManager()
+	if err != nil {
+		return
+	}
+	var staleLockfiles []string
+	for _, lockfile := range npmLockfiles {
+		if lockfile.tech == vulnDetails.Technology {
+			continue
+		}
+		var exists bool
+		if exists, err = fileutils.IsFileExists(lockfile.fileName, false); err != nil {
+			return
+		}
+		if !exists {
+			continue
+		}
+		if !updateAll && lockfile.tech != packageManager {
+			staleLockfiles = append(staleLockfiles, lockfile.fileName)
+			continue
+		}
+		log.Info(fmt.Sprintf("Updating %s as well, as it is present next to %s", lockfile.fileName, npmDescriptorFileName))
+		if err = cph.regenerateNpmLockfile(lockfile); err != nil {
+			return
+		}
+	}
+	if len(staleLockfiles) > 0 {
+		log.Warn(fmt.Sprintf("The lockfiles of the other package managers (%s) weren't updated, as the packageManager field of %s doesn't declare them", strings.Join(staleLockfiles, ", "), npmDescriptorFileName))
+		vulnDetails.AddFixNote(fmt.Sprintf("The lockfiles of the other package managers (%s) weren't updated, and may still resolve the vulnerable version of %s. Remove the lockfiles of the package managers the project doesn't use, or update them as well.",
+			strings.Join(staleLockfiles, ", "), vulnDetails.ImpactedDependencyName))
+	}
+	return
+}
+
+// Regenerates the lockfile from the updated package.json.
+// Yarn Classic can't update only the lockfile, so the packages are installed into a temporary modules folder that isn't committed.
+func (cph *CommonPackageHandler) regenerateNpmLockfile(lockfile npmLockfile) (err error) {
+	args := lockfile.args
+	if lockfile.tech == techutils.Yarn {
+		var content []byte
+		if content, err = os.ReadFile(lockfile.fileName); err != nil {
+			return fmt.Errorf("failed to read %s: %s", lockfile.fileName, err.Error())
+		}
+		if strings.Contains(string(content), yarnBerryLockfileMetadata) {
+			args = []string{"install", "--mode=update-lockfile"}
+		} else {
+			var tmpNodeModulesDir string
+			if tmpNodeModulesDir, err = fileutils.CreateTempDir(); err != nil {
+				return
+			}
+			defer func() {
+				err = errors.Join(err, fileutils.RemoveTempDir(tmpNodeModulesDir))
+			}()
+			args = []string{"install", npmInstallIgnoreScriptsFlag, modulesFolderFlag + tmpNodeModulesDir}
+		}
+	}
+	return cph.runPackageManagerCommand(lockfile.tech.GetExecCommandName(), lockfile.tech.String(), args)
+}
+
+// Returns the package manager declared in the packageManager field of package.json, such as yarn for "yarn@3.6.0",
+// or an empty technology if the field is missing or declares a package manager that doesn't install npm packages.
+func getNpmPackageManager() (techutils.Technology, error) {
+	content, err := os.ReadFile(npmDescriptorFileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %s", npmDescriptorFileName, err.Error())
+	}
+	var descriptor struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if err = json.Unmarshal(content, &descriptor); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %s", npmDescriptorFileName, err.Error())
+	}
+	name, _, _ := strings.Cut(strings.TrimSpace(descriptor.PackageManager), "@")
+	for _, lockfile := range npmLockfiles {
+		if lockfile.tech.String() == name {
+			return lockfile.tech, nil
+		}
+	}
+	return "", nil
+}
@@ -1,25 +1,73 @@
 package packagehandlers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/jfrog/frogbot/v2/utils"
 	npmCommand "github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/npm"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
 )
 
 const (
 	npmInstallPackageLockOnlyFlag = "--package-lock-only"
 	npmInstallIgnoreScriptsFlag   = "--ignore-scripts"
+	npmDescriptorFileName         = "package.json"
+	npmGitRefSeparator            = "#"
+	npmGitDependencyLineFormat    = `("%s"\s*:\s*)"%s"`
+	npmPeerDependenciesSection    = "peerDependencies"
+	npmNodeModulesDir             = "node_modules"
+)
+
+var (
+	npmDependenciesSections = []string{"dependencies", "devDependencies", "optionalDependencies", npmPeerDependenciesSection}
+	// npm accepts both spellings of the bundled dependencies field
+	npmBundledDependenciesFields = []string{"bundleDependencies", "bundledDependencies"}
+	// Hosted git shortcuts and the URL of the hosts, such as github:org/pkg#v1.2.3
+	npmHostedGitShortcuts = map[string]string{"github:": "https://github.com/", "gitlab:": "https://gitlab.com/", "bitbucket:": "https://bitbucket.org/"}
+	npmGitUrlPrefixes     = []string{"git+", "git://", "git@"}
+	// GitHub shorthand, such as org/pkg#v1.2.3
+	npmGitHubShorthandRegex = regexp.MustCompile(`^[\w-][\w.-]*/[\w.-]+(#.*)?$`)
 )
 
 type NpmPackageHandler struct {
 	CommonPackageHandler
+	// Lists the tags of a remote git repository, replaceable for testing purposes
+	listGitTags func(repositoryUrl string) ([]string, error)
+	// Skips the vulnerabilities of peer dependencies, instead of fixing them and flagging them for a compatibility review
+	skipPeerDeps bool
+	// Updates the lockfiles of the other package managers present next to package.json, rather than only the one declared in its packageManager field
+	updateAllLockfiles bool
+	// Leaves the bundled copies of the dependencies in node_modules as they are, instead of reinstalling them
+	skipVendoredDeps bool
+}
+
+func newNpmPackageHandler(scanDetails *utils.ScanDetails) *NpmPackageHandler {
+	if scanDetails.Project == nil {
+		return &NpmPackageHandler{}
+	}
+	return &NpmPackageHandler{
+		skipPeerDeps:       scanDetails.FixPeerDeps != nil && !*scanDetails.FixPeerDeps,
+		updateAllLockfiles: scanDetails.UpdateAllLockfiles,
+		skipVendoredDeps:   scanDetails.UpdateVendoredDependencies != nil && !*scanDetails.UpdateVendoredDependencies,
+	}
 }
 
 func (npm *NpmPackageHandler) UpdateDependency(vulnDetails *utils.VulnerabilityDetails) error {
 	if vulnDetails.IsDirectDependency {
-		return npm.updateDirectDependency(vulnDetails)
+		if err := npm.updateDirectDependency(vulnDetails); err != nil {
+			return err
+		}
+		return npm.updateOtherNpmLockfiles(vulnDetails, npm.updateAllLockfiles)
 	} else {
 		return &utils.ErrUnsupportedFix{
 			PackageName:  vulnDetails.ImpactedDependencyName,
@@ -30,7 +78,11 @@ func (npm *NpmPackageHandler) UpdateDependency(vulnDetails *utils.VulnerabilityD
 }
 
 func (npm *NpmPackageHandler) updateDirectDependency(vulnDetails *utils.VulnerabilityDetails) (err error) {
-	isNodeModulesExists, err := fileutils.IsDirExists("node_modules", false)
+	dependencySpecifier, err := getNpmDependencySpecifier(vulnDetails.ImpactedDependencyName)
+	if err != nil {
+		return
+	}
+	isNodeModulesExists, err := fileutils.IsDirExists(npmNodeModulesDir, false)
 	if err != nil {
 		err = fmt.Errorf("failed while serching for node_modules in project: %s", err.Error())
 		return
@@ -41,7 +93,14 @@ func (npm *NpmPackageHandler) updateDirectDependency(vulnDetails *utils.Vulnerab
 		// In case node_modules don't exist in current dir the fix will update only package.json and package-lock.json
 		commandFlags = append(commandFlags, npmInstallPackageLockOnlyFlag)
 	}
+	if commandFlags, err = npm.handleBundledDependency(vulnDetails, isNodeModulesExists, commandFlags); err != nil {
+		return
+	}
 
+	// The scoped registries are read before resolving from Artifactory replaces the .npmrc file
+	if npm.commandEnv, err = getNpmScopedRegistriesEnv(npmrcFileName); err != nil {
+		return
+	}
 	// Configure resolution from an Artifactory server if needed
 	if npm.depsRepo != "" {
 		var clearResolutionServerFunc func() error
@@ -53,5 +112,288 @@ func (npm *NpmPackageHandler) updateDirectDependency(vulnDetails *utils.Vulnerab
 			err = errors.Join(err, clearResolutionServerFunc())
 		}()
 	}
+	if isNpmUrlSpecifier(dependencySpecifier) {
+		return npm.updateGitDependency(vulnDetails, dependencySpecifier, commandFlags...)
+	}
+	isPeerOnly, err := npm.handlePeerDependency(vulnDetails)
+	if err != nil {
+		return
+	}
+	if err = updateNpmDuplicateDeclarations(vulnDetails); err != nil {
+		return
+	}
+	if isPeerOnly {
+		// Installing the fix version would declare it as a regular dependency, so the peer declaration is updated and the lockfile is regenerated accordingly
+		if err = replaceNpmDependencySpecifier(vulnDetails.ImpactedDependencyName, dependencySpecifier, getNpmFixedSpecifier(dependencySpecifier, vulnDetails.SuggestedFixedVersion)); err != nil {
+			return
+		}
+		return npm.regenerateLockfile(vulnDetails.Technology, append([]string{vulnDetails.Technology.GetPackageInstallationCommand()}, commandFlags...)...)
+	}
 	return npm.CommonPackageHandler.UpdateDependency(vulnDetails, vulnDetails.Technology.GetPackageInstallationCommand(), commandFlags...)
 }
+
+// The bundled dependencies are packed from node_modules, so their copy there is regenerated by installing the fix version.
+// Returns the install command flags, which only update the lockfile if the bundled copy is left as it is.
+func (npm *NpmPackageHandler) handleBundledDependency(vulnDetails *utils.VulnerabilityDetails, isNodeModulesExists bool, commandFlags []string) ([]string, error) {
+	isBundled, err := isNpmBundledDependency(vulnDetails.ImpactedDependencyName)
+	if err != nil || !isBundled {
+		return commandFlags, err
+	}
+	switch {
+	case !isNodeModulesExists:
+		log.Warn(fmt.Sprintf("%s is a bundled dependency, but %s doesn't exist, so its bundled copy can't be regenerated. Run 'npm install' before packing the package.", vulnDetails.ImpactedDependencyName, npmNodeModulesDir))
+	case npm.skipVendoredDeps:
+		log.Warn(fmt.Sprintf("The bundled copy of %s isn't updated, as updating vendored dependencies is disabled", vulnDetails.ImpactedDependencyName))
+		vulnDetails.AddFixNote(fmt.Sprintf("%s is a bundled dependency, and its copy in %s wasn't updated with the fix. Run 'npm install' before packing the package.", vulnDetails.ImpactedDependencyName, npmNodeModulesDir))
+		commandFlags = append(commandFlags, npmInstallPackageLockOnlyFlag)
+	default:
+		log.Debug(fmt.Sprintf("Regenerating the bundled copy of %s in %s", vulnDetails.ImpactedDependencyName, npmNodeModulesDir))
+	}
+	return commandFlags, nil
+}
+
+// Returns true if the package.json file of the current directory bundles the dependency, either by listing it or by bundling all the dependencies
+func isNpmBundledDependency(packageName string) (bool, error) {
+	content, err := os.ReadFile(npmDescriptorFileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %s", npmDescriptorFileName, err.Error())
+	}
+	var descriptor map[string]json.RawMessage
+	if err = json.Unmarshal(content, &descriptor); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %s", npmDescriptorFileName, err.Error())
+	}
+	for _, field := range npmBundledDependenciesFields {
+		rawBundled, exists := descriptor[field]
+		if !exists {
+			continue
+		}
+		var bundledPackages []string
+		if json.Unmarshal(rawBundled, &bundledPackages) == nil && slices.Contains(bundledPackages, packageName) {
+			return true, nil
+		}
+		var bundlesAll bool
+		if json.Unmarshal(rawBundled, &bundlesAll) == nil && bundlesAll {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Updates a dependency that is declared with a git specifier by replacing its git ref with the tag of the fix version.
+// Installing the fix version from the registry would replace the git specifier, so the tag is set in package.json and the lockfile is updated accordingly.
+func (npm *NpmPackageHandler) updateGitDependency(vulnDetails *utils.VulnerabilityDetails, dependencySpecifier string, commandFlags ...string) (err error) {
+	unsupportedFixErr := func(reason string) error {
+		return &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.GitDependencyFixNotSupported,
+			Reason:       reason,
+		}
+	}
+	repositoryUrl, currentRef := parseNpmGitSpecifier(dependencySpecifier)
+	if repositoryUrl == "" {
+		return unsupportedFixErr(fmt.Sprintf("'%s' isn't a git specifier", dependencySpecifier))
+	}
+	listGitTags := npm.listGitTags
+	if listGitTags == nil {
+		listGitTags = listRemoteGitTags
+	}
+	tags, err := listGitTags(repositoryUrl)
+	if err != nil {
+		return unsupportedFixErr(fmt.Sprintf("failed to list the tags of %s: %s", repositoryUrl, err.Error()))
+	}
+	fixTag := getNpmGitFixTag(tags, currentRef, vulnDetails.SuggestedFixedVersion)
+	if fixTag == "" {
+		return unsupportedFixErr(fmt.Sprintf("no tag matching version %s was found in %s", vulnDetails.SuggestedFixedVersion, repositoryUrl))
+	}
+	fixedSpecifier := strings.SplitN(dependencySpecifier, npmGitRefSeparator, 2)[0] + npmGitRefSeparator + fixTag
+	log.Debug(fmt.Sprintf("Updating the git specifier of %s from '%s' to '%s'", vulnDetails.ImpactedDependencyName, dependencySpecifier, fixedSpecifier))
+	if err = replaceNpmDependencySpecifier(vulnDetails.ImpactedDependencyName, dependencySpecifier, fixedSpecifier); err != nil {
+		return
+	}
+	return npm.regenerateLockfile(vulnDetails.Technology, append([]string{vulnDetails.Technology.GetPackageInstallationCommand()}, commandFlags...)...)
+}
+
+// Classifies the dependency by the sections it is declared in. Peer dependencies are skipped if fixing them is disabled,
+// and otherwise flagged for a compatibility review, as bumping a peer dependency may require coordinating with the consumers of the package.
+// Optional dependencies are fixed like regular dependencies. isPeerOnly is true if the dependency is declared only as a peer dependency.
+func (npm *NpmPackageHandler) handlePeerDependency(vulnDetails *utils.VulnerabilityDetails) (isPeerOnly bool, err error) {
+	declarations, err := getNpmDependencyDeclarations(vulnDetails.ImpactedDependencyName)
+	if err != nil {
+		return
+	}
+	isPeer := slices.ContainsFunc(declarations, func(declaration npmDependencyDeclaration) bool {
+		return declaration.section == npmPeerDependenciesSection
+	})
+	if !isPeer {
+		return
+	}
+	if npm.skipPeerDeps {
+		return false, &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.PeerDependencyFixSkipped,
+		}
+	}
+	vulnDetails.AddFixNote(fmt.Sprintf("%s is a peer dependency — review the compatibility of version %s with the consumers of the package.", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+	return len(declarations) == 1, nil
+}
+
+// A declaration of a dependency in one of the dependencies sections of a package.json file
+type npmDependencyDeclaration struct {
+	section   string
+	specifier string
+}
+
+// Returns the specifier the dependency is declared with in the package.json file of the current directory, or an empty string if it isn't declared there.
+func getNpmDependencySpecifier(packageName string) (string, error) {
+	declarations, err := getNpmDependencyDeclarations(packageName)
+	if err != nil || len(declarations) == 0 {
+		return "", err
+	}
+	return declarations[0].specifier, nil
+}
+
+// Returns all the declarations of the dependency in the package.json file of the current directory, ordered by the dependencies sections.
+func getNpmDependencyDeclarations(packageName string) (declarations []npmDependencyDeclaration, err error) {
+	content, err := os.ReadFile(npmDescriptorFileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %s", npmDescriptorFileName, err.Error())
+	}
+	var descriptor map[string]json.RawMessage
+	if err = json.Unmarshal(content, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", npmDescriptorFileName, err.Error())
+	}
+	for _, section := range npmDependenciesSections {
+		var dependencies map[string]string
+		if rawDependencies, exists := descriptor[section]; !exists || json.Unmarshal(rawDependencies, &dependencies) != nil {
+			continue
+		}
+		if specifier, exists := dependencies[packageName]; exists {
+			declarations = append(declarations, npmDependencyDeclaration{section: section, specifier: specifier})
+		}
+	}
+	return
+}
+
+// Updates all the declarations of a dependency that is declared in more than one dependencies section of the package.json file.
+// Installing the fix version updates a single declaration, so the other declarations would remain vulnerable.
+func updateNpmDuplicateDeclarations(vulnDetails *utils.VulnerabilityDetails) error {
+	declarations, err := getNpmDependencyDeclarations(vulnDetails.ImpactedDependencyName)
+	if err != nil || len(declarations) < 2 {
+		return err
+	}
+	var sections, updatedSpecifiers []string
+	for _, declaration := range declarations {
+		sections = append(sections, declaration.section)
+		// Declarations with the same specifier are replaced together
+		if isNpmUrlSpecifier(declaration.specifier) || slices.Contains(updatedSpecifiers, declaration.specifier) {
+			continue
+		}
+		fixedSpecifier := getNpmFixedSpecifier(declaration.specifier, vulnDetails.SuggestedFixedVersion)
+		log.Debug(fmt.Sprintf("Updating the declaration of %s in %s from '%s' to '%s'", vulnDetails.ImpactedDependencyName, declaration.section, declaration.specifier, fixedSpecifier))
+		if err = replaceNpmDependencySpecifier(vulnDetails.ImpactedDependencyName, declaration.specifier, fixedSpecifier); err != nil {
+			return err
+		}
+		updatedSpecifiers = append(updatedSpecifiers, declaration.specifier)
+	}
+	vulnDetails.AddFixNote(fmt.Sprintf("%s is declared more than once in %s (%s). All of its declarations were updated to %s.",
+		vulnDetails.ImpactedDependencyName, npmDescriptorFileName, strings.Join(sections, ", "), vulnDetails.SuggestedFixedVersion))
+	return nil
+}
+
+// Returns the specifier of the fix version, keeping the ^ or ~ range of the current specifier.
+func getNpmFixedSpecifier(specifier, fixVersion string) string {
+	for _, rangeOperator := range []string{caretOperator, tildeOperator} {
+		if strings.HasPrefix(strings.TrimSpace(specifier), rangeOperator) {
+			return rangeOperator + fixVersion
+		}
+	}
+	return fixVersion
+}
+
+// Returns true if the specifier points to a git repository or a URL, rather than to a version in the registry.
+func isNpmUrlSpecifier(specifier string) bool {
+	if strings.HasPrefix(specifier, "http://") || strings.HasPrefix(specifier, "https://") {
+		return true
+	}
+	repositoryUrl, _ := parseNpmGitSpecifier(specifier)
+	return repositoryUrl != ""
+}
+
+// Returns the URL of the git repository and the git ref of a git specifier.
+// If the specifier isn't a git specifier, an empty URL is returned.
+// github:org/pkg#v1.2.3                      --> https://github.com/org/pkg.git, v1.2.3
+// org/pkg#v1.2.3                             --> https://github.com/org/pkg.git, v1.2.3
+// git+https://github.com/org/pkg.git#v1.2.3  --> https://github.com/org/pkg.git, v1.2.3
+func parseNpmGitSpecifier(specifier string) (repositoryUrl, ref string) {
+	location, ref, _ := strings.Cut(specifier, npmGitRefSeparator)
+	for shortcut, hostUrl := range npmHostedGitShortcuts {
+		if strings.HasPrefix(location, shortcut) {
+			return hostUrl + strings.TrimSuffix(strings.TrimPrefix(location, shortcut), ".git") + ".git", ref
+		}
+	}
+	for _, prefix := range npmGitUrlPrefixes {
+		if strings.HasPrefix(location, prefix) {
+			return strings.TrimPrefix(location, "git+"), ref
+		}
+	}
+	if npmGitHubShorthandRegex.MatchString(specifier) {
+		return npmHostedGitShortcuts["github:"] + strings.TrimSuffix(location, ".git") + ".git", ref
+	}
+	return "", ""
+}
+
+// Returns the tag of the fix version, keeping the 'v' prefix convention of the current ref when both tags exist.
+func getNpmGitFixTag(tags []string, currentRef, fixVersion string) string {
+	candidates := []string{fixVersion, "v" + fixVersion}
+	if strings.HasPrefix(currentRef, "v") {
+		candidates = []string{"v" + fixVersion, fixVersion}
+	}
+	for _, candidate := range candidates {
+		for _, tag := range tags {
+			if tag == candidate {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+func listRemoteGitTags(repositoryUrl string) (tags []string, err error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{repositoryUrl}})
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+	return
+}
+
+// Replaces the specifier of the dependency in the package.json file of the current directory, keeping the file's formatting.
+func replaceNpmDependencySpecifier(packageName, currentSpecifier, fixedSpecifier string) error {
+	fileInfo, err := os.Stat(npmDescriptorFileName)
+	if err != nil {
+		return fmt.Errorf("failed to get %s file info: %s", npmDescriptorFileName, err.Error())
+	}
+	content, err := os.ReadFile(npmDescriptorFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", npmDescriptorFileName, err.Error())
+	}
+	dependencyRegex := regexp.MustCompile(fmt.Sprintf(npmGitDependencyLineFormat, regexp.QuoteMeta(packageName), regexp.QuoteMeta(currentSpecifier)))
+	fixedContent := dependencyRegex.ReplaceAllString(string(content), `${1}"`+strings.ReplaceAll(fixedSpecifier, "$", "$$")+`"`)
+	if fixedContent == string(content) {
+		return fmt.Errorf("failed to find the declaration of %s in %s", packageName, npmDescriptorFileName)
+	}
+	return os.WriteFile(npmDescriptorFileName, []byte(fixedContent), fileInfo.Mode())
+}
@@ -0,0 +1,99 @@
+package packagehandlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	npmrcFileName = ".npmrc"
+	// npm reads its config from the environment variables with this prefix, which take precedence over the .npmrc files
+	npmConfigEnvPrefix = "npm_config_"
+)
+
+var (
+	// Matches the registry of a scope, such as @myorg:registry
+	npmScopedRegistryKeyRegexp = regexp.MustCompile(`^@[a-z0-9][\w.-]*:registry$`)
+	// The credentials npm sends to a registry, set per registry URL, such as //npm.myorg.com/:_authToken
+	npmRegistryAuthKeys = []string{"_authToken", "_auth", "username", "_password"}
+	// Matches the references to environment variables in .npmrc values, such as ${NPM_TOKEN}. A trailing ? makes the variable optional.
+	npmrcEnvReferenceRegexp = regexp.MustCompile(`\$\{([^${}?]+)(\?)?}`)
+)
+
+// Returns the environment variables that set the scoped registries of the .npmrc file and the credentials of their registries.
+// Resolving the dependencies from Artifactory replaces the .npmrc file of the project and points all the scopes to Artifactory,
+// so the scoped registries and their credentials are passed in the environment, where npm prefers them over the .npmrc files.
+// Entries that reference a missing environment variable are skipped, as npm can't resolve them either.
+func getNpmScopedRegistriesEnv(npmrcPath string) (commandEnv []string, err error) {
+	content, err := os.ReadFile(npmrcPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %s", npmrcPath, err.Error())
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, isScopedRegistryEntry := parseNpmrcScopedRegistryEntry(scanner.Text())
+		if !isScopedRegistryEntry {
+			continue
+		}
+		expandedValue, missingEnv := expandNpmrcEnvReferences(value)
+		if missingEnv != "" {
+			log.Warn(fmt.Sprintf("The %s entry of %s references the missing environment variable %s, so it isn't passed to npm", key, npmrcPath, missingEnv))
+			continue
+		}
+		commandEnv = append(commandEnv, npmConfigEnvPrefix+key+"="+expandedValue)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", npmrcPath, err.Error())
+	}
+	if len(commandEnv) > 0 {
+		log.Debug(fmt.Sprintf("Passing %d scoped registry and credentials entries of %s to npm", len(commandEnv), npmrcPath))
+	}
+	return
+}
+
+// Returns the key and value of a line of an .npmrc file, if it sets the registry of a scope or the credentials of a registry.
+// @myorg:registry=https://npm.myorg.com/       --> @myorg:registry, https://npm.myorg.com/
+// //npm.myorg.com/:_authToken=${NPM_TOKEN}    --> //npm.myorg.com/:_authToken, ${NPM_TOKEN}
+func parseNpmrcScopedRegistryEntry(line string) (key, value string, isScopedRegistryEntry bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+		return
+	}
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	isRegistryAuth := strings.HasPrefix(key, "//") && slices.ContainsFunc(npmRegistryAuthKeys, func(authKey string) bool {
+		return strings.HasSuffix(key, ":"+authKey)
+	})
+	return key, value, isRegistryAuth || npmScopedRegistryKeyRegexp.MatchString(key)
+}
+
+// Replaces the references to environment variables in an .npmrc value with their values, as npm does when it reads the file.
+// Returns the name of the first required variable that isn't set, if any.
+func expandNpmrcEnvReferences(value string) (expandedValue, missingEnv string) {
+	expandedValue = npmrcEnvReferenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		match := npmrcEnvReferenceRegexp.FindStringSubmatch(reference)
+		envValue, exists := os.LookupEnv(match[1])
+		if !exists && match[2] == "" && missingEnv == "" {
+			missingEnv = match[1]
+		}
+		return envValue
+	})
+	return
+}
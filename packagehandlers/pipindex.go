@@ -0,0 +1,236 @@
+package packagehandlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	defaultPipIndexUrl     = "https://pypi.org/simple"
+	pipConfigFile          = "pip.conf"
+	pipConfigFileEnv       = "PIP_CONFIG_FILE"
+	pipIndexUrlEnv         = "PIP_INDEX_URL"
+	pipExtraIndexUrlEnv    = "PIP_EXTRA_INDEX_URL"
+	pipIndexUrlKey         = "index-url"
+	pipExtraIndexUrlKey    = "extra-index-url"
+	pipIndexRequestTimeout = 30 * time.Second
+)
+
+var (
+	// Matches the index options declared in a requirements file, e.g. --index-url https://example.com/simple
+	pipRequirementsIndexOptionRegexp = regexp.MustCompile(`(?m)^\s*(-i|--index-url|--extra-index-url)(?:\s*=\s*|\s+)(\S+)`)
+	pipPackageNameSeparatorsRegexp   = regexp.MustCompile(`[-_.]+`)
+	pipIndexHttpClient               = &http.Client{Timeout: pipIndexRequestTimeout}
+)
+
+// The indexes pip installs the dependencies of the project from
+type pipIndexes struct {
+	indexUrl       string
+	extraIndexUrls []string
+}
+
+// Returns the indexes pip uses to install the dependencies of the project in the current directory.
+// Like pip, the indexes are read from the pip configuration files, then from the PIP_INDEX_URL and PIP_EXTRA_INDEX_URL environment variables,
+// and finally from the options declared in the requirements file. Each source overrides the indexes of the previous ones.
+func getPipIndexes(requirementsContent string) (indexes *pipIndexes, err error) {
+	indexes = &pipIndexes{indexUrl: defaultPipIndexUrl}
+	for _, configFile := range getPipConfigFiles() {
+		if err = indexes.readConfigFile(configFile); err != nil {
+			return
+		}
+	}
+	if indexUrl := os.Getenv(pipIndexUrlEnv); indexUrl != "" {
+		indexes.indexUrl = indexUrl
+	}
+	if extraIndexUrls := os.Getenv(pipExtraIndexUrlEnv); extraIndexUrls != "" {
+		indexes.extraIndexUrls = strings.Fields(extraIndexUrls)
+	}
+	for _, match := range pipRequirementsIndexOptionRegexp.FindAllStringSubmatch(requirementsContent, -1) {
+		if match[1] == "--extra-index-url" {
+			indexes.extraIndexUrls = append(indexes.extraIndexUrls, match[2])
+		} else {
+			indexes.indexUrl = match[2]
+		}
+	}
+	return
+}
+
+// Returns the pip configuration files, ordered from the lowest priority to the highest.
+// Besides the global, user and virtualenv configuration files pip reads, the pip.conf file of the project is read as well.
+func getPipConfigFiles() (configFiles []string) {
+	configFileEnv := os.Getenv(pipConfigFileEnv)
+	if configFileEnv == os.DevNull {
+		// Like pip, no configuration file is read
+		return
+	}
+	configFiles = append(configFiles, filepath.Join("/etc", pipConfigFile))
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		configFiles = append(configFiles, filepath.Join(homeDir, ".pip", pipConfigFile))
+	}
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		configFiles = append(configFiles, filepath.Join(userConfigDir, "pip", pipConfigFile))
+	}
+	if virtualEnv := os.Getenv("VIRTUAL_ENV"); virtualEnv != "" {
+		configFiles = append(configFiles, filepath.Join(virtualEnv, pipConfigFile))
+	}
+	configFiles = append(configFiles, pipConfigFile)
+	if configFileEnv != "" {
+		configFiles = append(configFiles, configFileEnv)
+	}
+	return
+}
+
+// Reads the index settings of the given pip configuration file, if it exists.
+// The settings of the 'install' section override the settings of the 'global' section.
+func (pi *pipIndexes) readConfigFile(configFile string) error {
+	content, err := os.ReadFile(filepath.Clean(configFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("an error occurred while attempting to read the pip configuration file %s:\n%s", configFile, err.Error())
+	}
+	sections := parsePipConfig(content)
+	for _, section := range []string{"global", "install"} {
+		if indexUrl, exists := sections[section][pipIndexUrlKey]; exists {
+			pi.indexUrl = strings.TrimSpace(indexUrl)
+		}
+		if extraIndexUrls, exists := sections[section][pipExtraIndexUrlKey]; exists {
+			pi.extraIndexUrls = strings.Fields(extraIndexUrls)
+		}
+	}
+	return nil
+}
+
+// Parses the INI content of a pip configuration file into its sections' settings.
+// A value may continue on the following indented lines, as in the extra-index-url setting that lists several indexes.
+func parsePipConfig(content []byte) map[string]map[string]string {
+	sections := map[string]map[string]string{}
+	var currentSection, currentKey string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmedLine := strings.TrimSpace(line)
+		switch {
+		case trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, ";"):
+			continue
+		case strings.HasPrefix(trimmedLine, "[") && strings.HasSuffix(trimmedLine, "]"):
+			currentSection = strings.ToLower(strings.TrimSpace(trimmedLine[1 : len(trimmedLine)-1]))
+			currentKey = ""
+			if sections[currentSection] == nil {
+				sections[currentSection] = map[string]string{}
+			}
+		case currentSection == "":
+			continue
+		case currentKey != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			sections[currentSection][currentKey] += "\n" + trimmedLine
+		default:
+			separatorIndex := strings.IndexAny(trimmedLine, "=:")
+			if separatorIndex == -1 {
+				currentKey = ""
+				continue
+			}
+			// pip accepts the settings with underscores as well
+			currentKey = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(trimmedLine[:separatorIndex])), "_", "-")
+			sections[currentSection][currentKey] = strings.TrimSpace(trimmedLine[separatorIndex+1:])
+		}
+	}
+	return sections
+}
+
+func (pi *pipIndexes) urls() []string {
+	return append([]string{pi.indexUrl}, pi.extraIndexUrls...)
+}
+
+// Returns the URLs of the indexes without the credentials they may contain, so they can be logged
+func (pi *pipIndexes) String() string {
+	var redactedUrls []string
+	for _, indexUrl := range pi.urls() {
+		if parsedUrl, err := url.Parse(indexUrl); err == nil {
+			indexUrl = parsedUrl.Redacted()
+		}
+		redactedUrls = append(redactedUrls, indexUrl)
+	}
+	return strings.Join(redactedUrls, ", ")
+}
+
+// Checks whether the given version of the package is published in any of the indexes, using their simple repository API (PEP 503).
+// An error is returned only if none of the indexes could be queried.
+func (pi *pipIndexes) isVersionAvailable(packageName, version string) (isAvailable bool, err error) {
+	normalizedName := strings.ToLower(pipPackageNameSeparatorsRegexp.ReplaceAllString(packageName, "-"))
+	// The distribution files of the version, e.g. PyJWT-2.4.0.tar.gz or PyJWT-2.4.0-py3-none-any.whl
+	distributionRegexp := regexp.MustCompile(`(?i)` + strings.ReplaceAll(regexp.QuoteMeta(normalizedName), "-", "[-_.]+") + "-" + regexp.QuoteMeta(version) + `(\.tar\.gz|\.tar\.bz2|\.zip|-)`)
+	queriedIndexes := 0
+	for _, indexUrl := range pi.urls() {
+		content, e := getPipIndexProjectPage(strings.TrimSuffix(indexUrl, "/") + "/" + normalizedName + "/")
+		if e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+		queriedIndexes++
+		if distributionRegexp.Match(content) {
+			return true, nil
+		}
+	}
+	if queriedIndexes > 0 {
+		return false, nil
+	}
+	return
+}
+
+// Returns the content of the project page in the simple repository API, or empty content if the index doesn't contain the project
+func getPipIndexProjectPage(projectUrl string) (content []byte, err error) {
+	resp, err := pipIndexHttpClient.Get(projectUrl)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		redactedUrl := projectUrl
+		if parsedUrl, e := url.Parse(projectUrl); e == nil {
+			redactedUrl = parsedUrl.Redacted()
+		}
+		return nil, fmt.Errorf("the request to %s returned status code %d", redactedUrl, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Verifies that the fixed version of the package is available on the indexes pip installs the project from, as a fix to a version that can't be installed would break the project.
+// If the indexes can't be queried, the fix isn't blocked.
+func verifyPipFixVersionAvailability(vulnDetails *utils.VulnerabilityDetails, requirementsContent string) error {
+	indexes, err := getPipIndexes(requirementsContent)
+	if err != nil {
+		return err
+	}
+	isAvailable, err := indexes.isVersionAvailable(vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
+	if err != nil {
+		log.Debug(fmt.Sprintf("Couldn't check whether version %s of %s is available on %s:\n%s", vulnDetails.SuggestedFixedVersion, vulnDetails.ImpactedDependencyName, indexes, err.Error()))
+		return nil
+	}
+	if !isAvailable {
+		return &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.FixVersionNotAvailableOnIndex,
+			Reason:       indexes.String(),
+		}
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package packagehandlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 	"os"
 	"path"
 	"path/filepath"
@@ -16,6 +20,10 @@ const (
 	pnpmDependencyRegexpPattern = "\\s*\"%s\"\\s*:\\s*\"[~|^]?%s\""
 	pnpmDescriptorFileSuffix    = "package.json"
 	nodeModulesPathPattern      = ".*node_modules.*"
+	pnpmConfigField             = "pnpm"
+	pnpmOverridesField          = "overrides"
+	pnpmLockfileOnlyFlag        = "--lockfile-only"
+	defaultNpmDescriptorIndent  = "  "
 )
 
 type PnpmPackageHandler struct {
@@ -26,12 +34,26 @@ func (pnpm *PnpmPackageHandler) UpdateDependency(vulnDetails *utils.Vulnerabilit
 	if vulnDetails.IsDirectDependency {
 		return pnpm.updateDirectDependency(vulnDetails)
 	}
+	return pnpm.updateIndirectDependency(vulnDetails)
+}
 
-	return &utils.ErrUnsupportedFix{
-		PackageName:  vulnDetails.ImpactedDependencyName,
-		FixedVersion: vulnDetails.SuggestedFixedVersion,
-		ErrorType:    utils.IndirectDependencyFixNotSupported,
+// Pins the vulnerable version of the transitive dependency to the fix version with an override in the pnpm field of package.json,
+// and regenerates the lockfile with the install command of the project.
+// pnpm only reads the overrides of the workspace root, so the package.json of the working directory is updated.
+func (pnpm *PnpmPackageHandler) updateIndirectDependency(vulnDetails *utils.VulnerabilityDetails) (err error) {
+	// Without the impacted version, every version of the dependency is overridden
+	selector := vulnDetails.ImpactedDependencyName
+	if vulnDetails.ImpactedDependencyVersion != "" {
+		selector += "@" + vulnDetails.ImpactedDependencyVersion
+	}
+	log.Debug(fmt.Sprintf("Overriding %s with version %s in %s", selector, vulnDetails.SuggestedFixedVersion, npmDescriptorFileName))
+	if err = addPnpmOverride(selector, vulnDetails.SuggestedFixedVersion); err != nil {
+		return
+	}
+	if err = pnpm.regenerateLockfile(techutils.Pnpm, "install", pnpmLockfileOnlyFlag, npmInstallIgnoreScriptsFlag); err != nil {
+		return fmt.Errorf("failed to update the lockfile after overriding %s with version %s: %s", selector, vulnDetails.SuggestedFixedVersion, err.Error())
 	}
+	return
 }
 
 func (pnpm *PnpmPackageHandler) updateDirectDependency(vulnDetails *utils.VulnerabilityDetails) (err error) {
@@ -101,3 +123,122 @@ func (pnpm *PnpmPackageHandler) fixVulnerabilityIfExists(vulnDetails *utils.Vuln
 	}
 	return isFileChanged, err
 }
+
+// Adds the override to the pnpm.overrides field of the package.json file of the current directory, creating the field if needed.
+// The fields keep their order and the file keeps its indentation, so only the override shows in the diff.
+func addPnpmOverride(selector, fixVersion string) error {
+	fileInfo, err := os.Stat(npmDescriptorFileName)
+	if err != nil {
+		return fmt.Errorf("failed to get %s file info: %s", npmDescriptorFileName, err.Error())
+	}
+	content, err := os.ReadFile(npmDescriptorFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", npmDescriptorFileName, err.Error())
+	}
+	descriptor, err := parseOrderedJsonObject(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %s", npmDescriptorFileName, err.Error())
+	}
+	pnpmConfig, err := parseOrderedJsonObject(descriptor.get(pnpmConfigField))
+	if err != nil {
+		return fmt.Errorf("failed to parse the %s field of %s: %s", pnpmConfigField, npmDescriptorFileName, err.Error())
+	}
+	overrides, err := parseOrderedJsonObject(pnpmConfig.get(pnpmOverridesField))
+	if err != nil {
+		return fmt.Errorf("failed to parse the %s.%s field of %s: %s", pnpmConfigField, pnpmOverridesField, npmDescriptorFileName, err.Error())
+	}
+	encodedFixVersion, err := json.Marshal(fixVersion)
+	if err != nil {
+		return err
+	}
+	overrides.set(selector, encodedFixVersion)
+	pnpmConfig.set(pnpmOverridesField, overrides.marshal())
+	descriptor.set(pnpmConfigField, pnpmConfig.marshal())
+	var fixedContent bytes.Buffer
+	if err = json.Indent(&fixedContent, descriptor.marshal(), "", getJsonIndent(content)); err != nil {
+		return err
+	}
+	if bytes.HasSuffix(content, []byte("\n")) {
+		fixedContent.WriteByte('\n')
+	}
+	return os.WriteFile(npmDescriptorFileName, fixedContent.Bytes(), fileInfo.Mode())
+}
+
+// A JSON object whose fields keep the order they were parsed in
+type orderedJsonObject []orderedJsonField
+
+type orderedJsonField struct {
+	name  string
+	value json.RawMessage
+}
+
+// Parses the fields of the JSON object in their order. A missing object has no fields.
+func parseOrderedJsonObject(content []byte) (object orderedJsonObject, err error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+	for decoder.More() {
+		var token json.Token
+		if token, err = decoder.Token(); err != nil {
+			return
+		}
+		field := orderedJsonField{name: token.(string)}
+		if err = decoder.Decode(&field.value); err != nil {
+			return
+		}
+		object = append(object, field)
+	}
+	return
+}
+
+func (object orderedJsonObject) get(name string) json.RawMessage {
+	for _, field := range object {
+		if field.name == name {
+			return field.value
+		}
+	}
+	return nil
+}
+
+// Replaces the value of the field, or appends the field if it's missing
+func (object *orderedJsonObject) set(name string, value json.RawMessage) {
+	for i := range *object {
+		if (*object)[i].name == name {
+			(*object)[i].value = value
+			return
+		}
+	}
+	*object = append(*object, orderedJsonField{name: name, value: value})
+}
+
+// Returns the compact JSON of the object. The values are valid JSON, so the fields are concatenated as they are.
+func (object orderedJsonObject) marshal() json.RawMessage {
+	var content bytes.Buffer
+	content.WriteByte('{')
+	for i, field := range object {
+		if i > 0 {
+			content.WriteByte(',')
+		}
+		// Encoding a string can't fail
+		name, _ := json.Marshal(field.name)
+		content.Write(name)
+		content.WriteByte(':')
+		content.Write(field.value)
+	}
+	content.WriteByte('}')
+	return content.Bytes()
+}
+
+// Returns the indentation of the first indented line of the JSON file, or the default indentation of npm if it has none
+func getJsonIndent(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; indent != "" && strings.TrimSpace(line) != "" {
+			return indent
+		}
+	}
+	return defaultNpmDescriptorIndent
+}
@@ -0,0 +1,206 @@
+package packagehandlers
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const slsaProvenancePredicatePrefix = "https://slsa.dev/provenance/"
+
+// The public registries the provenance attestations of the package versions are fetched from, by ecosystem
+var provenanceRegistryUrls = map[string]string{
+	"npm":  "https://registry.npmjs.org",
+	"pypi": "https://pypi.org",
+}
+
+// Verifies the provenance attestations of the package version against its published artifacts.
+// Returns the reason the provenance is unavailable or failed the verification.
+type provenanceCheck func(registryUrl, packageName, version string) (status utils.ProvenanceStatus, reason string, err error)
+
+var provenanceChecks = map[string]provenanceCheck{
+	"npm":  checkNpmProvenance,
+	"pypi": checkPypiProvenance,
+}
+
+// An in-toto statement, which attests the artifacts in its subjects
+type inTotoStatement struct {
+	Subject []inTotoSubject `json:"subject"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// VerifyFixVersionProvenance verifies the provenance attestations of the fix version if its ecosystem is one of the verified ecosystems,
+// and annotates the fix with the result. The attestations are verified by matching their subjects to the digests of the published artifacts,
+// while their signatures are left to the registry, which verifies them when the attestations are published.
+// A failed verification blocks the fix if blockFailed is true. Attestations that can't be fetched are handled by the metadata lookup policy.
+func VerifyFixVersionProvenance(vulnDetails *utils.VulnerabilityDetails, ecosystems []string, blockFailed bool, policy utils.MetadataLookupPolicy) error {
+	ecosystem, _ := utils.VersionRegistries{}.GetRegistries(vulnDetails.Technology)
+	if !slices.Contains(ecosystems, ecosystem) || policy == utils.DisabledMetadataLookupPolicy {
+		return nil
+	}
+	status, reason, err := provenanceChecks[ecosystem](provenanceRegistryUrls[ecosystem], vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
+	if err != nil {
+		if policy == utils.RequiredMetadataLookupPolicy {
+			return handleMetadataLookupFailure(vulnDetails, policy, "provenance", err)
+		}
+		log.Warn(fmt.Sprintf("Couldn't fetch the provenance attestations of %s %s:\n%s", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, err.Error()))
+		status, reason = utils.UnavailableProvenanceStatus, "the attestations couldn't be fetched"
+	}
+	log.Info(fmt.Sprintf("The provenance of %s %s is %s", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, status))
+	if status == utils.FailedProvenanceStatus && blockFailed {
+		return &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.ProvenanceVerificationFailed,
+			Reason:       reason,
+		}
+	}
+	note := fmt.Sprintf("%s %s provenance: %s", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, status)
+	if reason != "" {
+		note += fmt.Sprintf(" (%s)", reason)
+	}
+	vulnDetails.AddFixNote(note)
+	return nil
+}
+
+// Matches the provenance attestation of the version, e.g. https://registry.npmjs.org/-/npm/v1/attestations/minimist@1.2.6, to the integrity of its tarball
+func checkNpmProvenance(registryUrl, packageName, version string) (status utils.ProvenanceStatus, reason string, err error) {
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/%s", registryUrl, url.PathEscape(packageName), url.PathEscape(version)))
+	if err != nil || content == nil {
+		return utils.UnavailableProvenanceStatus, "the version isn't published on the registry", err
+	}
+	var versionDocument struct {
+		Dist struct {
+			Integrity    string `json:"integrity"`
+			Attestations *struct {
+				Url string `json:"url"`
+			} `json:"attestations"`
+		} `json:"dist"`
+	}
+	if err = json.Unmarshal(content, &versionDocument); err != nil {
+		return
+	}
+	if versionDocument.Dist.Attestations == nil {
+		return utils.UnavailableProvenanceStatus, "the version was published without attestations", nil
+	}
+	algorithm, encodedDigest, _ := strings.Cut(versionDocument.Dist.Integrity, "-")
+	tarballDigest, err := base64.StdEncoding.DecodeString(encodedDigest)
+	if algorithm != "sha512" || err != nil || len(tarballDigest) != sha512.Size {
+		return utils.FailedProvenanceStatus, "the integrity of the tarball isn't a SHA-512 digest", nil
+	}
+	if content, err = getRegistryResource(versionDocument.Dist.Attestations.Url); err != nil || content == nil {
+		return utils.UnavailableProvenanceStatus, "the attestations of the version aren't published on the registry", err
+	}
+	var attestations struct {
+		Attestations []struct {
+			PredicateType string `json:"predicateType"`
+			Bundle        struct {
+				DsseEnvelope struct {
+					Payload string `json:"payload"`
+				} `json:"dsseEnvelope"`
+			} `json:"bundle"`
+		} `json:"attestations"`
+	}
+	if err = json.Unmarshal(content, &attestations); err != nil {
+		return
+	}
+	purl := fmt.Sprintf("pkg:npm/%s@%s", strings.Replace(packageName, "@", "%40", 1), version)
+	for _, attestation := range attestations.Attestations {
+		if !strings.HasPrefix(attestation.PredicateType, slsaProvenancePredicatePrefix) {
+			continue
+		}
+		statement, err := decodeInTotoStatement(attestation.Bundle.DsseEnvelope.Payload)
+		if err != nil {
+			return utils.FailedProvenanceStatus, err.Error(), nil
+		}
+		if !statement.hasSubject(purl, "sha512", hex.EncodeToString(tarballDigest)) {
+			return utils.FailedProvenanceStatus, "the subject of the provenance attestation doesn't match the published tarball", nil
+		}
+		return utils.VerifiedProvenanceStatus, "", nil
+	}
+	return utils.UnavailableProvenanceStatus, "the version has no provenance attestation", nil
+}
+
+// Matches the provenance of each distribution file of the version, e.g. https://pypi.org/integrity/sampleproject/4.0.0/sampleproject-4.0.0.tar.gz/provenance, to its digest.
+// The version is verified if all the files that have provenance match it.
+func checkPypiProvenance(registryUrl, packageName, version string) (status utils.ProvenanceStatus, reason string, err error) {
+	content, err := getRegistryResource(fmt.Sprintf("%s/pypi/%s/%s/json", registryUrl, packageName, version))
+	if err != nil || content == nil {
+		return utils.UnavailableProvenanceStatus, "the version isn't published on the registry", err
+	}
+	var release struct {
+		Urls []struct {
+			Filename string `json:"filename"`
+			Digests  struct {
+				Sha256 string `json:"sha256"`
+			} `json:"digests"`
+		} `json:"urls"`
+	}
+	if err = json.Unmarshal(content, &release); err != nil {
+		return
+	}
+	status, reason = utils.UnavailableProvenanceStatus, "the version was published without attestations"
+	for _, distribution := range release.Urls {
+		if content, err = getRegistryResource(fmt.Sprintf("%s/integrity/%s/%s/%s/provenance", registryUrl, packageName, version, url.PathEscape(distribution.Filename))); err != nil {
+			return
+		}
+		if content == nil {
+			continue
+		}
+		var provenance struct {
+			AttestationBundles []struct {
+				Attestations []struct {
+					Envelope struct {
+						Statement string `json:"statement"`
+					} `json:"envelope"`
+				} `json:"attestations"`
+			} `json:"attestation_bundles"`
+		}
+		if err = json.Unmarshal(content, &provenance); err != nil {
+			return
+		}
+		for _, bundle := range provenance.AttestationBundles {
+			for _, attestation := range bundle.Attestations {
+				statement, decodeErr := decodeInTotoStatement(attestation.Envelope.Statement)
+				if decodeErr != nil {
+					return utils.FailedProvenanceStatus, decodeErr.Error(), nil
+				}
+				if !statement.hasSubject(distribution.Filename, "sha256", distribution.Digests.Sha256) {
+					return utils.FailedProvenanceStatus, fmt.Sprintf("the subject of the provenance attestation doesn't match %s", distribution.Filename), nil
+				}
+				status, reason = utils.VerifiedProvenanceStatus, ""
+			}
+		}
+	}
+	return
+}
+
+func decodeInTotoStatement(encodedStatement string) (*inTotoStatement, error) {
+	content, err := base64.StdEncoding.DecodeString(encodedStatement)
+	if err != nil {
+		return nil, fmt.Errorf("the attestation statement isn't base64 encoded: %s", err.Error())
+	}
+	var statement inTotoStatement
+	if err = json.Unmarshal(content, &statement); err != nil {
+		return nil, fmt.Errorf("the attestation statement isn't an in-toto statement: %s", err.Error())
+	}
+	return &statement, nil
+}
+
+func (statement *inTotoStatement) hasSubject(name, algorithm, digest string) bool {
+	return slices.ContainsFunc(statement.Subject, func(subject inTotoSubject) bool {
+		return subject.Name == name && strings.EqualFold(subject.Digest[algorithm], digest)
+	})
+}
@@ -18,6 +18,15 @@ const (
 	PythonPackageRegexPrefix = "(?i)"
 	// Match all possible operators and versions syntax
 	PythonPackageRegexSuffix = "\\s*(([\\=\\<\\>\\~]=)|([\\>\\<]))\\s*(\\.|\\d)*(\\d|(\\.\\*))(\\,\\s*(([\\=\\<\\>\\~]=)|([\\>\\<])).*\\s*(\\.|\\d)*(\\d|(\\.\\*)))?"
+	// Matches a dependency declared in pyproject.toml with a version string, e.g. pyjwt = "^1.7.1"
+	poetryDependencyRegexpPattern = `(?im)^\s*"?%s"?\s*=\s*"([^"]+)"`
+	poetryDescriptorFile          = "pyproject.toml"
+)
+
+var (
+	tomlTableHeaderRegexp = regexp.MustCompile(`(?m)^\s*\[([^\[\]]+)\]\s*$`)
+	// The tables of pyproject.toml that declare the dependencies of the project, e.g. [tool.poetry.dependencies] or [tool.poetry.group.dev.dependencies]
+	poetryDependenciesTableRegexp = regexp.MustCompile(`^tool\.poetry\.(dependencies|dev-dependencies|group\.[^.]+\.dependencies)$`)
 )
 
 // PythonPackageHandler Handles all the python package mangers as they share behavior
@@ -52,18 +61,74 @@ func (py *PythonPackageHandler) updateDirectDependency(vulnDetails *utils.Vulner
 }
 
 func (py *PythonPackageHandler) handlePoetry(vulnDetails *utils.VulnerabilityDetails) (err error) {
-	// Install the desired fixed version
-	if err = py.CommonPackageHandler.UpdateDependency(vulnDetails, vulnDetails.Technology.GetPackageInstallationCommand()); err != nil {
+	// Set the desired fixed version in pyproject.toml
+	fixed, err := fixPoetryConstraint(vulnDetails)
+	if err != nil {
 		return
 	}
+	if !fixed {
+		// The package isn't declared in pyproject.toml, so it's added with the fixed version
+		if err = py.CommonPackageHandler.UpdateDependency(vulnDetails, vulnDetails.Technology.GetPackageInstallationCommand()); err != nil {
+			return
+		}
+	}
 	// Update Poetry lock file as well
-	return runPackageMangerCommand(techutils.Poetry.GetExecCommandName(), techutils.Poetry.String(), []string{"update"})
+	return py.regenerateLockfile(techutils.Poetry, "update")
+}
+
+// Replaces the constraint of the package in pyproject.toml with one that allows the fixed version, keeping the operator of the declared constraint.
+// Returns false if the package's constraint can't be found.
+func fixPoetryConstraint(vulnDetails *utils.VulnerabilityDetails) (fixed bool, err error) {
+	data, err := os.ReadFile(poetryDescriptorFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("an error occurred while attempting to read %s:\n%s", poetryDescriptorFile, err.Error())
+	}
+	match := findPoetryDependencyConstraint(data, vulnDetails.ImpactedDependencyName)
+	if match == nil {
+		return false, nil
+	}
+	fixedConstraint, note := getFixedVersionConstraint(vulnDetails.ImpactedDependencyName, string(data[match[2]:match[3]]), vulnDetails.SuggestedFixedVersion)
+	if note != "" {
+		vulnDetails.AddFixNote(note)
+	}
+	fixedData := append(append(append([]byte{}, data[:match[2]]...), fixedConstraint...), data[match[3]:]...)
+	if err = os.WriteFile(poetryDescriptorFile, fixedData, 0600); err != nil {
+		return false, fmt.Errorf("an error occured while writing the fixed version of %s to %s:\n%s", vulnDetails.SuggestedFixedVersion, poetryDescriptorFile, err.Error())
+	}
+	return true, nil
+}
+
+// Returns the submatch indexes of the first constraint of the package that is declared in the dependencies tables of pyproject.toml,
+// so a key with the same name in other tables, such as [tool.poetry.scripts], isn't mistaken for it. Returns nil if there's no such constraint.
+func findPoetryDependencyConstraint(data []byte, packageName string) []int {
+	re := regexp.MustCompile(fmt.Sprintf(poetryDependencyRegexpPattern, regexp.QuoteMeta(packageName)))
+	headers := tomlTableHeaderRegexp.FindAllSubmatchIndex(data, -1)
+	for i, header := range headers {
+		if !poetryDependenciesTableRegexp.MatchString(strings.TrimSpace(string(data[header[2]:header[3]]))) {
+			continue
+		}
+		tableStart, tableEnd := header[1], len(data)
+		if i+1 < len(headers) {
+			tableEnd = headers[i+1][0]
+		}
+		match := re.FindSubmatchIndex(data[tableStart:tableEnd])
+		if match == nil {
+			continue
+		}
+		for j := range match {
+			match[j] += tableStart
+		}
+		return match
+	}
+	return nil
 }
 
 func (py *PythonPackageHandler) handlePip(vulnDetails *utils.VulnerabilityDetails) (err error) {
 	var fixedFile string
 	// This function assumes that the version of the dependencies is statically pinned in the requirements file or inside the 'install_requires' array in the setup.py file
-	fixedPackage := vulnDetails.ImpactedDependencyName + "==" + vulnDetails.SuggestedFixedVersion
 	if py.pipRequirementsFile == "" {
 		py.pipRequirementsFile = "setup.py"
 	}
@@ -85,11 +150,19 @@ func (py *PythonPackageHandler) handlePip(vulnDetails *utils.VulnerabilityDetail
 	// This regex will match the impactedPackage with it's pinned version e.py. PyJWT==1.7.1
 	re := regexp.MustCompile(PythonPackageRegexPrefix + "(" + vulnDetails.ImpactedDependencyName + "|" + strings.ToLower(vulnDetails.ImpactedDependencyName) + ")" + PythonPackageRegexSuffix)
 	if packageToReplace := re.FindString(currentFile); packageToReplace != "" {
-		fixedFile = strings.Replace(currentFile, packageToReplace, strings.ToLower(fixedPackage), 1)
+		// Keep the operator of the existing constraint when the fix version allows it
+		fixedConstraint, note := getFixedVersionConstraint(vulnDetails.ImpactedDependencyName, packageToReplace[len(vulnDetails.ImpactedDependencyName):], vulnDetails.SuggestedFixedVersion)
+		if note != "" {
+			vulnDetails.AddFixNote(note)
+		}
+		fixedFile = strings.Replace(currentFile, packageToReplace, strings.ToLower(vulnDetails.ImpactedDependencyName)+fixedConstraint, 1)
 	}
 	if fixedFile == "" {
 		return fmt.Errorf("impacted package %s not found, fix failed", vulnDetails.ImpactedDependencyName)
 	}
+	if err = verifyPipFixVersionAvailability(vulnDetails, currentFile); err != nil {
+		return
+	}
 	if err = os.WriteFile(py.pipRequirementsFile, []byte(fixedFile), 0600); err != nil {
 		err = fmt.Errorf("an error occured while writing the fixed version of %s to the requirements file:\n%s", vulnDetails.SuggestedFixedVersion, err.Error())
 	}
@@ -0,0 +1,154 @@
+package packagehandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const registryRequestTimeout = 30 * time.Second
+
+var registryHttpClient = &http.Client{Timeout: registryRequestTimeout}
+
+// Checks whether the version of the package is published on the registry, using the API of the ecosystem's registries
+type versionAvailabilityCheck func(registryUrl, packageName, version string) (isAvailable bool, err error)
+
+var versionAvailabilityChecks = map[string]versionAvailabilityCheck{
+	"npm":   isNpmVersionAvailable,
+	"pypi":  isPypiVersionAvailable,
+	"maven": isMavenVersionAvailable,
+	"go":    isGoVersionAvailable,
+	"nuget": isNugetVersionAvailable,
+}
+
+// VerifyFixVersionAvailability verifies that the fix version of the package is available on all the registries configured for its ecosystem.
+// A version that hasn't propagated to all the mirrors yet would break the builds that install from the others, so its fix is skipped until it has.
+// Registries that can't be queried are handled by the metadata lookup policy.
+func VerifyFixVersionAvailability(vulnDetails *utils.VulnerabilityDetails, versionRegistries utils.VersionRegistries, policy utils.MetadataLookupPolicy) error {
+	ecosystem, registries := versionRegistries.GetRegistries(vulnDetails.Technology)
+	if len(registries) == 0 || policy == utils.DisabledMetadataLookupPolicy {
+		return nil
+	}
+	var missingRegistries, unreachableRegistries []string
+	var errs error
+	for _, registryUrl := range registries {
+		isAvailable, err := versionAvailabilityChecks[ecosystem](registryUrl, vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
+		if err != nil {
+			unreachableRegistries = append(unreachableRegistries, registryUrl)
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if !isAvailable {
+			missingRegistries = append(missingRegistries, registryUrl)
+		}
+	}
+	if len(missingRegistries) > 0 {
+		return &utils.ErrUnsupportedFix{
+			PackageName:  vulnDetails.ImpactedDependencyName,
+			FixedVersion: vulnDetails.SuggestedFixedVersion,
+			ErrorType:    utils.FixVersionNotAvailableOnIndex,
+			Reason:       utils.RedactRegistryUrls(missingRegistries),
+		}
+	}
+	if len(unreachableRegistries) > 0 {
+		log.Debug(fmt.Sprintf("Couldn't query %s for version %s of %s", utils.RedactRegistryUrls(unreachableRegistries), vulnDetails.SuggestedFixedVersion, vulnDetails.ImpactedDependencyName))
+		return handleMetadataLookupFailure(vulnDetails, policy, "registry availability", errs)
+	}
+	return nil
+}
+
+// Looks the version up in the versions of the package document, e.g. https://registry.npmjs.org/@types%2fnode
+func isNpmVersionAvailable(registryUrl, packageName, version string) (isAvailable bool, err error) {
+	content, err := getRegistryResource(registryUrl + "/" + url.PathEscape(packageName))
+	if err != nil || content == nil {
+		return
+	}
+	var packageDocument struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err = json.Unmarshal(content, &packageDocument); err != nil {
+		return
+	}
+	_, isAvailable = packageDocument.Versions[version]
+	return
+}
+
+// Looks the distribution files of the version up in the project page of the simple repository API (PEP 503)
+func isPypiVersionAvailable(registryUrl, packageName, version string) (bool, error) {
+	return (&pipIndexes{indexUrl: registryUrl}).isVersionAvailable(packageName, version)
+}
+
+// Looks the POM of the version up in the Maven repository layout, e.g. https://repo.maven.apache.org/maven2/org/yaml/snakeyaml/2.0/snakeyaml-2.0.pom
+func isMavenVersionAvailable(registryUrl, packageName, version string) (isAvailable bool, err error) {
+	groupId, artifactId, found := strings.Cut(packageName, ":")
+	if !found {
+		return false, fmt.Errorf("the package name '%s' isn't in the format of <group>:<artifact>", packageName)
+	}
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", registryUrl, strings.ReplaceAll(groupId, ".", "/"), artifactId, version, artifactId, version))
+	return content != nil, err
+}
+
+// Looks the version up in the module proxy protocol, e.g. https://proxy.golang.org/github.com/!azure/azure-sdk-for-go/@v/v1.0.0.info
+func isGoVersionAvailable(registryUrl, packageName, version string) (isAvailable bool, err error) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/@v/%s.info", registryUrl, escapeGoModulePath(packageName), escapeGoModulePath(version)))
+	return content != nil, err
+}
+
+// The module proxy protocol escapes the upper-case letters as an exclamation mark followed by the lower-case letter, to support case-insensitive file systems
+func escapeGoModulePath(modulePath string) string {
+	var escapedPath strings.Builder
+	for _, char := range modulePath {
+		if unicode.IsUpper(char) {
+			escapedPath.WriteRune('!')
+			char = unicode.ToLower(char)
+		}
+		escapedPath.WriteRune(char)
+	}
+	return escapedPath.String()
+}
+
+// Looks the version up in the versions of the package in the flat container resource of the NuGet V3 API, e.g. https://api.nuget.org/v3-flatcontainer/newtonsoft.json/index.json
+func isNugetVersionAvailable(registryUrl, packageName, version string) (isAvailable bool, err error) {
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/index.json", registryUrl, strings.ToLower(packageName)))
+	if err != nil || content == nil {
+		return
+	}
+	var packageVersions struct {
+		Versions []string `json:"versions"`
+	}
+	if err = json.Unmarshal(content, &packageVersions); err != nil {
+		return
+	}
+	return slices.Contains(packageVersions.Versions, strings.ToLower(version)), nil
+}
+
+// Returns the content of the registry resource, or nil if the registry doesn't contain it
+func getRegistryResource(resourceUrl string) (content []byte, err error) {
+	resp, err := registryHttpClient.Get(resourceUrl)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the request to %s returned status code %d", utils.RedactRegistryUrls([]string{resourceUrl}), resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,362 @@
+package packagehandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/maps"
+)
+
+// The public registries the runtime requirements of the package versions are looked up in, by ecosystem
+var runtimeRegistryUrls = map[string]string{
+	"npm":  "https://registry.npmjs.org",
+	"pypi": "https://pypi.org/pypi",
+}
+
+// Returns the runtime requirement each version of the package declares, by version. The versions without a requirement are mapped to an empty requirement
+type runtimeRequirementsLookup func(registryUrl, packageName string) (map[string]string, error)
+
+type runtimeConstraint struct {
+	runtimeName        string
+	lookupRequirements runtimeRequirementsLookup
+	isSatisfied        func(runtimeVersion, requirement string) bool
+}
+
+var runtimeConstraints = map[string]runtimeConstraint{
+	"npm":  {runtimeName: "Node.js", lookupRequirements: getNpmRuntimeRequirements, isSatisfied: satisfiesNpmRange},
+	"pypi": {runtimeName: "Python", lookupRequirements: getPypiRuntimeRequirements, isSatisfied: satisfiesPythonSpecifiers},
+}
+
+// FindFixVersionWithSupportedRuntime returns the suggested fix version if the configured runtime of its ecosystem satisfies the runtime requirement of the version,
+// and otherwise the lowest newer stable version that is eligible and whose runtime requirement is satisfied.
+// If no such version exists, an ErrUnsupportedFix is returned. Requirements that can't be looked up are handled by the metadata lookup policy,
+// and unless they're required, the suggested fix version is kept.
+func FindFixVersionWithSupportedRuntime(vulnDetails *utils.VulnerabilityDetails, runtimeVersions utils.RuntimeVersions, isEligible func(candidate string) bool, policy utils.MetadataLookupPolicy) (string, error) {
+	ecosystem, _ := utils.VersionRegistries{}.GetRegistries(vulnDetails.Technology)
+	runtimeVersion, configured := runtimeVersions[ecosystem]
+	if !configured || policy == utils.DisabledMetadataLookupPolicy {
+		return vulnDetails.SuggestedFixedVersion, nil
+	}
+	constraint := runtimeConstraints[ecosystem]
+	requirements, err := constraint.lookupRequirements(runtimeRegistryUrls[ecosystem], vulnDetails.ImpactedDependencyName)
+	if err != nil {
+		return vulnDetails.SuggestedFixedVersion, handleMetadataLookupFailure(vulnDetails, policy, constraint.runtimeName+" requirement", err)
+	}
+	suggestedVersion := strings.TrimPrefix(vulnDetails.SuggestedFixedVersion, "v")
+	suggestedRequirement := requirements[suggestedVersion]
+	if constraint.isSatisfied(runtimeVersion, suggestedRequirement) {
+		return vulnDetails.SuggestedFixedVersion, nil
+	}
+	log.Info(fmt.Sprintf("%s %s requires %s %s, which isn't satisfied by the configured version %s. Looking up a newer version...", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, constraint.runtimeName, suggestedRequirement, runtimeVersion))
+	for _, candidate := range getNewerStableVersions(suggestedVersion, maps.Keys(requirements)) {
+		if isEligible(candidate) && constraint.isSatisfied(runtimeVersion, requirements[candidate]) {
+			vulnDetails.AddFixNote(fmt.Sprintf("%s %s requires %s %s, so %s was updated to %s, which supports the configured %s %s.",
+				vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, constraint.runtimeName, suggestedRequirement, vulnDetails.ImpactedDependencyName, candidate, constraint.runtimeName, runtimeVersion))
+			return candidate, nil
+		}
+	}
+	return "", &utils.ErrUnsupportedFix{
+		PackageName:  vulnDetails.ImpactedDependencyName,
+		FixedVersion: vulnDetails.SuggestedFixedVersion,
+		ErrorType:    utils.FixRequiresUnsupportedRuntime,
+		Reason:       fmt.Sprintf("%s %s is required, while the configured version is %s", constraint.runtimeName, suggestedRequirement, runtimeVersion),
+	}
+}
+
+// Looks the engines up in the versions of the package document, e.g. https://registry.npmjs.org/@types%2fnode
+func getNpmRuntimeRequirements(registryUrl, packageName string) (map[string]string, error) {
+	content, err := getRegistryResource(registryUrl + "/" + url.PathEscape(packageName))
+	if err != nil || content == nil {
+		return nil, err
+	}
+	var packageDocument struct {
+		Versions map[string]struct {
+			// Some old versions declare the engines as an array, which isn't enforced by npm
+			Engines json.RawMessage `json:"engines"`
+		} `json:"versions"`
+	}
+	if err = json.Unmarshal(content, &packageDocument); err != nil {
+		return nil, err
+	}
+	requirements := make(map[string]string, len(packageDocument.Versions))
+	for packageVersion, versionDocument := range packageDocument.Versions {
+		var engines struct {
+			Node string `json:"node"`
+		}
+		requirements[packageVersion] = ""
+		if json.Unmarshal(versionDocument.Engines, &engines) == nil {
+			requirements[packageVersion] = engines.Node
+		}
+	}
+	return requirements, nil
+}
+
+// Looks the requirements up in the distribution files of the releases of the project, e.g. https://pypi.org/pypi/requests/json
+func getPypiRuntimeRequirements(registryUrl, packageName string) (map[string]string, error) {
+	content, err := getRegistryResource(fmt.Sprintf("%s/%s/json", registryUrl, packageName))
+	if err != nil || content == nil {
+		return nil, err
+	}
+	var project struct {
+		Releases map[string][]struct {
+			RequiresPython string `json:"requires_python"`
+		} `json:"releases"`
+	}
+	if err = json.Unmarshal(content, &project); err != nil {
+		return nil, err
+	}
+	requirements := make(map[string]string, len(project.Releases))
+	for release, distributions := range project.Releases {
+		requirements[release] = ""
+		for _, distribution := range distributions {
+			if distribution.RequiresPython != "" {
+				requirements[release] = distribution.RequiresPython
+				break
+			}
+		}
+	}
+	return requirements, nil
+}
+
+// Checks the version against an npm semver range, such as ">=14.17 <15 || ^16.13.0", ">= 18", "14.x" or "12 - 16".
+// Ranges that can't be parsed are treated as satisfied, so the fixes aren't skipped because of them.
+func satisfiesNpmRange(runtimeVersion, versionRange string) bool {
+	current, parsed := parseNumericVersion(runtimeVersion)
+	if !parsed {
+		return true
+	}
+	for _, comparatorSet := range strings.Split(versionRange, "||") {
+		if satisfiesNpmComparatorSet(current, strings.TrimSpace(comparatorSet)) {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfiesNpmComparatorSet(current []int, comparatorSet string) bool {
+	if lower, upper, isHyphenRange := strings.Cut(comparatorSet, " - "); isHyphenRange {
+		return satisfiesNpmComparator(current, ">="+strings.TrimSpace(lower)) && satisfiesNpmComparator(current, "<="+strings.TrimSpace(upper))
+	}
+	var comparators []string
+	operator := ""
+	for _, field := range strings.Fields(comparatorSet) {
+		// The operators may be separated from their versions, as in ">= 18"
+		if strings.Trim(field, "<>=~^") == "" {
+			operator += field
+			continue
+		}
+		comparators = append(comparators, operator+field)
+		operator = ""
+	}
+	for _, comparator := range comparators {
+		if !satisfiesNpmComparator(current, comparator) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfiesNpmComparator(current []int, comparator string) bool {
+	operatorEnd := strings.IndexFunc(comparator, func(r rune) bool { return !strings.ContainsRune("<>=~^", r) })
+	if operatorEnd == -1 {
+		return true
+	}
+	operator := comparator[:operatorEnd]
+	partial, parsed := parsePartialVersion(comparator[operatorEnd:])
+	if !parsed {
+		return true
+	}
+	if len(partial) == 0 {
+		// Any version satisfies the wildcards, such as * and x
+		return true
+	}
+	lower := padVersion(partial)
+	switch operator {
+	case "^":
+		// The caret allows the changes that don't modify the first non-zero component
+		index := len(partial) - 1
+		for i, component := range partial {
+			if component != 0 {
+				index = i
+				break
+			}
+		}
+		return compareNumericVersions(current, lower) >= 0 && compareNumericVersions(current, incrementVersion(partial, index)) < 0
+	case "~":
+		// The tilde allows the patch changes, or the minor changes if only the major version is specified
+		return compareNumericVersions(current, lower) >= 0 && compareNumericVersions(current, incrementVersion(partial, min(len(partial)-1, 1))) < 0
+	}
+	// A partial version stands for the whole range of the versions it prefixes, as 14 stands for >=14.0.0 <15.0.0
+	isPartial := len(partial) < len(lower)
+	upper := lower
+	if isPartial {
+		upper = incrementVersion(partial, len(partial)-1)
+	}
+	switch operator {
+	case ">=":
+		return compareNumericVersions(current, lower) >= 0
+	case ">":
+		if isPartial {
+			return compareNumericVersions(current, upper) >= 0
+		}
+		return compareNumericVersions(current, lower) > 0
+	case "<":
+		return compareNumericVersions(current, lower) < 0
+	case "<=":
+		if isPartial {
+			return compareNumericVersions(current, upper) < 0
+		}
+		return compareNumericVersions(current, lower) <= 0
+	case "", "=":
+		if isPartial {
+			return compareNumericVersions(current, lower) >= 0 && compareNumericVersions(current, upper) < 0
+		}
+		return compareNumericVersions(current, lower) == 0
+	}
+	return true
+}
+
+// Checks the version against comma-separated Python version specifiers, such as ">=3.8, !=3.9.*" or "~=3.7".
+// Specifiers that can't be parsed are treated as satisfied, so the fixes aren't skipped because of them.
+func satisfiesPythonSpecifiers(runtimeVersion, specifiers string) bool {
+	current, parsed := parseNumericVersion(runtimeVersion)
+	if !parsed {
+		return true
+	}
+	for _, specifier := range strings.Split(specifiers, ",") {
+		if specifier = strings.TrimSpace(specifier); specifier != "" && !satisfiesPythonSpecifier(runtimeVersion, current, specifier) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfiesPythonSpecifier(runtimeVersion string, current []int, specifier string) bool {
+	for _, operator := range []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"} {
+		specifierVersion, found := strings.CutPrefix(specifier, operator)
+		if !found {
+			continue
+		}
+		specifierVersion = strings.TrimSpace(specifierVersion)
+		if operator == "===" {
+			return runtimeVersion == specifierVersion
+		}
+		prefix, isPrefixMatch := strings.CutSuffix(specifierVersion, ".*")
+		version, parsed := parseNumericVersion(prefix)
+		if !parsed {
+			return true
+		}
+		switch operator {
+		case "~=":
+			// The compatible release clause matches the versions that are at least the version, and have the same components except for its last one
+			return compareNumericVersions(current, version) >= 0 && (len(version) < 2 || hasVersionPrefix(current, version[:len(version)-1]))
+		case "==":
+			if isPrefixMatch {
+				return hasVersionPrefix(current, version)
+			}
+			return compareNumericVersions(current, version) == 0
+		case "!=":
+			if isPrefixMatch {
+				return !hasVersionPrefix(current, version)
+			}
+			return compareNumericVersions(current, version) != 0
+		case "<=":
+			return compareNumericVersions(current, version) <= 0
+		case ">=":
+			return compareNumericVersions(current, version) >= 0
+		case "<":
+			return compareNumericVersions(current, version) < 0
+		case ">":
+			return compareNumericVersions(current, version) > 0
+		}
+	}
+	return true
+}
+
+// Parses the numeric components of a version, such as 18.19.0. The pre-release and the build metadata are ignored
+func parseNumericVersion(rawVersion string) ([]int, bool) {
+	rawVersion = strings.TrimPrefix(strings.TrimSpace(rawVersion), "v")
+	if end := strings.IndexAny(rawVersion, "-+"); end != -1 {
+		rawVersion = rawVersion[:end]
+	}
+	var components []int
+	for _, rawComponent := range strings.Split(rawVersion, ".") {
+		component, err := strconv.Atoi(rawComponent)
+		if err != nil {
+			return nil, false
+		}
+		components = append(components, component)
+	}
+	return components, true
+}
+
+// Parses a version whose trailing components may be missing or wildcards, such as 14, 14.x or 1.2.*
+func parsePartialVersion(rawVersion string) ([]int, bool) {
+	rawVersion = strings.TrimPrefix(strings.TrimSpace(rawVersion), "v")
+	if end := strings.IndexAny(rawVersion, "-+"); end != -1 {
+		rawVersion = rawVersion[:end]
+	}
+	var components []int
+	for _, rawComponent := range strings.Split(rawVersion, ".") {
+		if rawComponent == "" || rawComponent == "x" || rawComponent == "X" || rawComponent == "*" {
+			break
+		}
+		component, err := strconv.Atoi(rawComponent)
+		if err != nil {
+			return nil, false
+		}
+		components = append(components, component)
+	}
+	return components, true
+}
+
+// Pads the version with zeros up to the major, minor and patch components
+func padVersion(components []int) []int {
+	padded := append([]int{}, components...)
+	for len(padded) < 3 {
+		padded = append(padded, 0)
+	}
+	return padded
+}
+
+// Returns the lowest version whose component at the index is greater than the component of the version, as 1.3.0 for the minor component of 1.2.5
+func incrementVersion(components []int, index int) []int {
+	incremented := padVersion(components[:index+1])
+	incremented[index]++
+	for i := index + 1; i < len(incremented); i++ {
+		incremented[i] = 0
+	}
+	return incremented
+}
+
+func compareNumericVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var componentA, componentB int
+		if i < len(a) {
+			componentA = a[i]
+		}
+		if i < len(b) {
+			componentB = b[i]
+		}
+		if componentA != componentB {
+			if componentA < componentB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func hasVersionPrefix(components, prefix []int) bool {
+	padded := padVersion(components)
+	for len(padded) < len(prefix) {
+		padded = append(padded, 0)
+	}
+	return compareNumericVersions(padded[:len(prefix)], prefix) == 0
+}
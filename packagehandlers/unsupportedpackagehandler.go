@@ -2,6 +2,8 @@ package packagehandlers
 
 import (
 	"errors"
+	"time"
+
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 )
@@ -15,3 +17,9 @@ func (uph *UnsupportedPackageHandler) UpdateDependency(vulnDetails *utils.Vulner
 
 func (uph *UnsupportedPackageHandler) SetCommonParams(serverDetails *config.ServerDetails, depsRepo string) {
 }
+
+func (uph *UnsupportedPackageHandler) SetInstallCommand(name string, args []string) {
+}
+
+func (uph *UnsupportedPackageHandler) SetInstallRetryPolicy(retries int, timeout time.Duration) {
+}
@@ -0,0 +1,168 @@
+package packagehandlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/gofrog/version"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	exactOperator             = "=="
+	arbitraryEqualityOperator = "==="
+	notEqualOperator          = "!="
+	compatibleReleaseOperator = "~="
+	caretOperator             = "^"
+	tildeOperator             = "~"
+	greaterOrEqualOperator    = ">="
+	greaterOperator           = ">"
+	lessOrEqualOperator       = "<="
+	lessOperator              = "<"
+	versionWildcardSuffix     = ".*"
+	// A Poetry constraint that any version satisfies
+	anyVersionWildcard = "*"
+)
+
+var versionConstraintClauseRegex = regexp.MustCompile(`^\s*(===|==|~=|>=|<=|!=|\^|~|>|<)?\s*([^\s,]+)\s*$`)
+
+type versionConstraintClause struct {
+	operator string
+	version  string
+}
+
+func (vcc versionConstraintClause) String() string {
+	return vcc.operator + vcc.version
+}
+
+func parseVersionConstraint(constraint string) (clauses []versionConstraintClause, err error) {
+	for _, clause := range strings.Split(constraint, ",") {
+		match := versionConstraintClauseRegex.FindStringSubmatch(clause)
+		if match == nil {
+			return nil, fmt.Errorf("failed to parse the version constraint '%s'", constraint)
+		}
+		clauses = append(clauses, versionConstraintClause{operator: match[1], version: match[2]})
+	}
+	return
+}
+
+// getFixedVersionConstraint returns a constraint that allows the fix version while keeping the operator of the current constraint.
+// If the fix version is outside the range allowed by the current operator, the range is widened to the fix version (^, ~ and ~=), or the constraint is replaced with an exact pin.
+// In these cases, a note describing the change is returned as well.
+func getFixedVersionConstraint(packageName, constraint, fixVersion string) (fixedConstraint, note string) {
+	var changed bool
+	clauses, err := parseVersionConstraint(constraint)
+	if err != nil {
+		log.Debug(err.Error(), "- pinning", packageName, "to the fix version")
+		fixedConstraint, changed = exactOperator+fixVersion, true
+	} else if len(clauses) > 1 {
+		fixedConstraint, changed = getFixedVersionRange(clauses, fixVersion)
+	} else {
+		fixedConstraint, changed = getFixedVersionClause(clauses[0], fixVersion)
+	}
+	if changed {
+		note = fmt.Sprintf("The version constraint of %s was changed from `%s` to `%s`, since the fix version %s is outside the range allowed by the original constraint.", packageName, strings.TrimSpace(constraint), fixedConstraint, fixVersion)
+	}
+	return
+}
+
+func getFixedVersionClause(clause versionConstraintClause, fixVersion string) (fixedClause string, changed bool) {
+	switch clause.operator {
+	case "", exactOperator, arbitraryEqualityOperator:
+		if clause.operator == "" && clause.version == anyVersionWildcard {
+			// The fix version is already allowed, and it's picked when the lockfile is regenerated
+			return clause.version, false
+		}
+		if strings.HasSuffix(clause.version, versionWildcardSuffix) && !isSatisfied(clause, fixVersion) {
+			return exactOperator + fixVersion, true
+		}
+		return clause.operator + fixVersion, false
+	case greaterOrEqualOperator, greaterOperator:
+		return greaterOrEqualOperator + fixVersion, false
+	case compatibleReleaseOperator, caretOperator, tildeOperator:
+		return clause.operator + fixVersion, !isSatisfied(clause, fixVersion)
+	default:
+		// Upper bounds and exclusions can't be moved to the fix version, so the fix version is pinned
+		return exactOperator + fixVersion, true
+	}
+}
+
+// A version range keeps its upper bounds as long as the fix version satisfies them, and its lower bound is raised to the fix version.
+func getFixedVersionRange(clauses []versionConstraintClause, fixVersion string) (fixedRange string, changed bool) {
+	fixedClauses := []string{greaterOrEqualOperator + fixVersion}
+	for _, clause := range clauses {
+		switch clause.operator {
+		case greaterOrEqualOperator, greaterOperator:
+			continue
+		case lessOperator, lessOrEqualOperator, notEqualOperator:
+			if !isSatisfied(clause, fixVersion) {
+				return exactOperator + fixVersion, true
+			}
+			fixedClauses = append(fixedClauses, clause.String())
+		default:
+			return exactOperator + fixVersion, true
+		}
+	}
+	return strings.Join(fixedClauses, ","), false
+}
+
+// Returns true if the version satisfies the constraint clause.
+func isSatisfied(clause versionConstraintClause, versionToCheck string) bool {
+	constraintVersion := version.NewVersion(clause.version)
+	switch clause.operator {
+	case "", exactOperator, arbitraryEqualityOperator:
+		if clause.operator == "" && clause.version == anyVersionWildcard {
+			return true
+		}
+		if strings.HasSuffix(clause.version, versionWildcardSuffix) {
+			return strings.HasPrefix(versionToCheck+".", strings.TrimSuffix(clause.version, "*"))
+		}
+		return constraintVersion.Compare(versionToCheck) == 0
+	case notEqualOperator:
+		return constraintVersion.Compare(versionToCheck) != 0
+	case greaterOrEqualOperator:
+		return constraintVersion.Compare(versionToCheck) >= 0
+	case greaterOperator:
+		return constraintVersion.Compare(versionToCheck) > 0
+	case lessOrEqualOperator:
+		return constraintVersion.Compare(versionToCheck) <= 0
+	case lessOperator:
+		return constraintVersion.Compare(versionToCheck) < 0
+	case compatibleReleaseOperator:
+		// ~=1.2.3 is equivalent to >=1.2.3,==1.2.*
+		return constraintVersion.Compare(versionToCheck) >= 0 && hasSamePrefix(clause.version, versionToCheck, len(strings.Split(clause.version, "."))-1)
+	case caretOperator:
+		// ^1.2.3 allows changes that do not modify the left-most non-zero component
+		return constraintVersion.Compare(versionToCheck) >= 0 && hasSamePrefix(clause.version, versionToCheck, caretPrefixLength(clause.version))
+	case tildeOperator:
+		// ~1.2.3 allows patch-level changes, ~1 allows minor-level changes
+		return constraintVersion.Compare(versionToCheck) >= 0 && hasSamePrefix(clause.version, versionToCheck, min(2, len(strings.Split(clause.version, "."))))
+	}
+	return false
+}
+
+func caretPrefixLength(constraintVersion string) int {
+	components := strings.Split(constraintVersion, ".")
+	for i, component := range components {
+		if component != "0" {
+			return i + 1
+		}
+	}
+	return len(components)
+}
+
+// Returns true if both versions share the same first prefixLength components.
+func hasSamePrefix(firstVersion, secondVersion string, prefixLength int) bool {
+	if prefixLength < 1 {
+		prefixLength = 1
+	}
+	firstComponents, secondComponents := strings.Split(firstVersion, "."), strings.Split(secondVersion, ".")
+	for i := 0; i < prefixLength; i++ {
+		if utils.GetVersionComponent(firstComponents, i) != utils.GetVersionComponent(secondComponents, i) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,8 @@ package packagehandlers
 import (
 	"errors"
 	"fmt"
+	"sync"
+
 	biUtils "github.com/jfrog/build-info-go/build/utils"
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/gofrog/version"
@@ -15,15 +17,40 @@ const (
 	yarnV1PackageUpdateCmd = "upgrade"
 	yarnV2PackageUpdateCmd = "up"
 	modulesFolderFlag      = "--modules-folder="
+	cacheFolderFlag        = "--cache-folder"
+	preferOfflineFlag      = "--prefer-offline"
+	yarnGlobalFolderEnv    = "YARN_GLOBAL_FOLDER"
+	yarnOfflineModeEnv     = "YARN_ENABLE_OFFLINE_MODE"
 )
 
+// Yarn doesn't support concurrent writes to the same cache, so the commands that use the shared cache run one at a time
+var yarnCacheLock sync.Mutex
+
 type YarnPackageHandler struct {
 	CommonPackageHandler
+	// A cache directory shared by all the Yarn commands of the run
+	cacheDir string
+	// Whether to install packages from the cache, without fetching them from the registry when possible
+	offlineMirror bool
+	// Updates the lockfiles of the other package managers present next to package.json, rather than only the one declared in its packageManager field
+	updateAllLockfiles bool
+}
+
+func newYarnPackageHandler(scanDetails *utils.ScanDetails) *YarnPackageHandler {
+	if scanDetails.Project == nil {
+		return &YarnPackageHandler{}
+	}
+	return &YarnPackageHandler{cacheDir: scanDetails.YarnCacheDir, offlineMirror: scanDetails.YarnOfflineMirror, updateAllLockfiles: scanDetails.UpdateAllLockfiles}
 }
 
 func (yarn *YarnPackageHandler) UpdateDependency(vulnDetails *utils.VulnerabilityDetails) error {
 	if vulnDetails.IsDirectDependency {
-		return yarn.updateDirectDependency(vulnDetails)
+		if err := yarn.updateDirectDependency(vulnDetails); err != nil {
+			return err
+		}
+		// The other package managers don't use the cache of Yarn
+		yarn.commandEnv = nil
+		return yarn.updateOtherNpmLockfiles(vulnDetails, yarn.updateAllLockfiles)
 	} else {
 		return &utils.ErrUnsupportedFix{
 			PackageName:  vulnDetails.ImpactedDependencyName,
@@ -58,6 +85,13 @@ func (yarn *YarnPackageHandler) updateDirectDependency(vulnDetails *utils.Vulner
 	} else {
 		installationCommand = yarnV2PackageUpdateCmd
 	}
+	cacheArgs, cacheEnv := yarn.getCacheArgsAndEnv(isYarn1)
+	extraArgs = append(extraArgs, cacheArgs...)
+	yarn.commandEnv = cacheEnv
+	if yarn.cacheDir != "" {
+		yarnCacheLock.Lock()
+		defer yarnCacheLock.Unlock()
+	}
 	err = yarn.CommonPackageHandler.UpdateDependency(vulnDetails, installationCommand, extraArgs...)
 	if err != nil {
 		err = fmt.Errorf("running 'yarn %s for '%s' failed:\n%s\nHint: The Yarn version that was used is: %s. If your project was built with a different major version of Yarn, please configure your CI runner to include it",
@@ -69,6 +103,27 @@ func (yarn *YarnPackageHandler) updateDirectDependency(vulnDetails *utils.Vulner
 	return
 }
 
+// Returns the args and the environment variables that make Yarn use the shared cache directory.
+// Yarn Classic gets the cache directory as a flag, while Yarn Berry is configured through its environment variables.
+// Yarn Berry keeps a copy of the packages in the global folder when the mirror is enabled (the default), so the project's cache is left intact.
+func (yarn *YarnPackageHandler) getCacheArgsAndEnv(isYarn1 bool) (args, env []string) {
+	if yarn.cacheDir == "" {
+		return
+	}
+	if isYarn1 {
+		args = append(args, cacheFolderFlag, yarn.cacheDir)
+		if yarn.offlineMirror {
+			args = append(args, preferOfflineFlag)
+		}
+		return
+	}
+	env = append(env, yarnGlobalFolderEnv+"="+yarn.cacheDir)
+	if yarn.offlineMirror {
+		env = append(env, yarnOfflineModeEnv+"=true")
+	}
+	return
+}
+
 // isYarnV1Project gets the current executed yarn version and returns whether the current yarn version is V1 or not
 func isYarnV1Project() (isYarn1 bool, executableYarnVersion string, err error) {
 	// NOTICE: in case your global yarn version is 1.x this function will always return true even if the project is originally in higher yarn version
@@ -132,7 +132,8 @@ func auditPullRequest(repoConfig *utils.Repository, client vcsclient.VcsClient,
 	scanDetails := utils.NewScanDetails(client, &repoConfig.Server, &repoConfig.Git).
 		SetXrayGraphScanParams(repoConfig.Watches, repoConfig.JFrogProjectKey, len(repoConfig.AllowedLicenses) > 0).
 		SetFixableOnly(repoConfig.FixableOnly).
-		SetFailOnInstallationErrors(*repoConfig.FailOnSecurityIssues)
+		SetFailOnInstallationErrors(*repoConfig.FailOnSecurityIssues).
+		SetScanPhases(repoConfig.ScanPhaseOrder, repoConfig.EarlyExitOnCritical)
 	if scanDetails, err = scanDetails.SetMinSeverity(repoConfig.MinSeverity); err != nil {
 		return
 	}
@@ -216,6 +217,9 @@ func auditTargetBranch(repoConfig *utils.Repository, scanDetails *utils.ScanDeta
 	var targetResults *securityutils.Results
 	workingDirs := utils.GetFullPathWorkingDirs(scanDetails.Project.WorkingDirs, targetBranchWd)
 	log.Info("Scanning target branch...")
+	// The issues the target branch scan would skip would be reported as new, so the target branch is scanned without exiting early
+	scanDetails.SetScanPhases(repoConfig.ScanPhaseOrder, false)
+	defer scanDetails.SetScanPhases(repoConfig.ScanPhaseOrder, repoConfig.EarlyExitOnCritical)
 	targetResults, err = scanDetails.RunInstallAndAudit(workingDirs...)
 	if err != nil {
 		return
@@ -0,0 +1,60 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/maps"
+)
+
+// The time to wait for the output of a killed command, as processes it started may keep its output open
+const killedCommandWaitDelay = 10 * time.Second
+
+// Prepares the scan of the next working directory.
+// Working directories may share state, such as a root node_modules directory, so the configured command resets the state the previous scan left behind.
+func (cfp *ScanRepositoryCmd) prepareWorkingDirScan() error {
+	cfp.scannedWorkingDirs++
+	if cfp.betweenDirsCommand == "" || cfp.scannedWorkingDirs == 1 {
+		return nil
+	}
+	return cfp.runBetweenDirsCommand()
+}
+
+// Runs the command between the scans of the working directories, in the base working directory.
+func (cfp *ScanRepositoryCmd) runBetweenDirsCommand() error {
+	return cfp.runRepositoryCommand(context.Background(), cfp.betweenDirsCommand, "command between working directories", cfp.baseWd)
+}
+
+// Runs a configured command in the given directory of the repository. The commandName describes the command in the logs and errors.
+// The command must leave the local branches intact, as the fix branches are created and pushed from the local repository.
+// The command is killed if the context is done before it completes.
+func (cfp *ScanRepositoryCmd) runRepositoryCommand(ctx context.Context, command, commandName, dir string) error {
+	branchesBefore, err := cfp.gitManager.GetLocalBranchesHashes()
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Running the %s:", commandName), command)
+	var cmd *exec.Cmd
+	if coreutils.IsWindows() {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.WaitDelay = killedCommandWaitDelay
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("the %s '%s' failed: %s\n%s", commandName, command, err.Error(), strings.TrimSpace(string(output)))
+	}
+	log.Debug(strings.TrimSpace(string(output)))
+	branchesAfter, err := cfp.gitManager.GetLocalBranchesHashes()
+	if err != nil || !maps.Equal(branchesBefore, branchesAfter) {
+		return fmt.Errorf("the %s '%s' changed the git repository. The command must not modify the .git directory or the local branches", commandName, command)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package scanrepository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBetweenDirsCommand(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{}"), 0600))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	commitHash, err := worktree.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}})
+	require.NoError(t, err)
+	// An un-pushed fix branch
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("frogbot-fix"), commitHash)))
+
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+
+	cfp := ScanRepositoryCmd{gitManager: gitManager, baseWd: repoDir, betweenDirsCommand: "echo cleaned >> between-dirs.log"}
+	// The command runs between the scans of the working directories, but not before the first one
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cfp.prepareWorkingDirScan())
+	}
+	content, err := os.ReadFile(filepath.Join(repoDir, "between-dirs.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "cleaned"))
+
+	// A command that deletes a local branch fails the scan
+	cfp.betweenDirsCommand = "rm " + filepath.ToSlash(filepath.Join(".", ".git", "refs", "heads", "frogbot-fix"))
+	assert.ErrorContains(t, cfp.prepareWorkingDirScan(), "changed the git repository")
+
+	// A failing command fails the scan
+	cfp.betweenDirsCommand = "exit 3"
+	assert.ErrorContains(t, cfp.prepareWorkingDirScan(), "failed")
+}
@@ -0,0 +1,22 @@
+package scanrepository
+
+import (
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+)
+
+// Comments on the scanned commit with a summary of the detected vulnerabilities and the fix pull requests opened for them.
+// The checksum of the detected vulnerabilities is recorded in the comment, so repeated runs on the same commit update the comment only when the vulnerabilities change.
+func (cfp *ScanRepositoryCmd) commentOnScannedCommit() error {
+	checksum, err := utils.VulnerabilityDetailsToMD5Hash(cfp.branchVulnerabilities...)
+	if err != nil {
+		return err
+	}
+	if cfp.redactCommitComment {
+		// The output writer is shared with the pull requests, whose content isn't redacted
+		cfp.OutputWriter.SetRedacted(true)
+		defer cfp.OutputWriter.SetRedacted(false)
+	}
+	content := outputwriter.CommitSummaryContent(cfp.branchVulnerabilities, cfp.branchPullRequests, cfp.OutputWriter)
+	return utils.UpsertGitHubCommitComment(cfp.scanDetails.APIEndpoint, cfp.scanDetails.Token, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scannedCommit, content, checksum)
+}
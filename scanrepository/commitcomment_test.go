@@ -0,0 +1,76 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentOnScannedCommit(t *testing.T) {
+	const commit = "6f2e1c1b5b3ed6d5a7c5e0c8c1cbbd3e2b4f7a9d"
+	var commitComment string
+	var posts, patches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/repo/commits/"+commit+"/comments":
+			comments := []map[string]any{{"id": 1, "body": "LGTM"}}
+			if commitComment != "" {
+				comments = append(comments, map[string]any{"id": 2, "body": commitComment})
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(comments))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/repo/commits/"+commit+"/comments":
+			posts++
+			commitComment = readJsonRequestBody(t, r)["body"]
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/jfrog/repo/comments/2":
+			patches++
+			commitComment = readJsonRequestBody(t, r)["body"]
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfp := ScanRepositoryCmd{
+		OutputWriter:  &outputwriter.StandardOutput{},
+		scanDetails:   utils.NewScanDetails(nil, nil, &utils.Git{VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}, RepoOwner: "jfrog", RepoName: "repo"}),
+		scannedCommit: commit,
+		branchVulnerabilities: []formats.VulnerabilityOrViolationRow{{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "High"},
+				ImpactedDependencyName:    "minimist",
+				ImpactedDependencyVersion: "1.2.5",
+			},
+			FixedVersions: []string{"[1.2.6]"},
+			Cves:          []formats.CveRow{{Id: "CVE-2021-44906"}},
+		}},
+		branchPullRequests: []outputwriter.FixPullRequestRow{{Title: "[🐸 Frogbot] Update version of minimist to 1.2.6", Url: "https://github.com/jfrog/repo/pull/1"}},
+	}
+	require.NoError(t, cfp.commentOnScannedCommit())
+	assert.Equal(t, 1, posts)
+	assert.Contains(t, commitComment, "Frogbot Scan Summary")
+	assert.Contains(t, commitComment, "minimist 1.2.5")
+	assert.Contains(t, commitComment, "CVE-2021-44906")
+	assert.Contains(t, commitComment, "[[🐸 Frogbot] Update version of minimist to 1.2.6](https://github.com/jfrog/repo/pull/1)")
+
+	// A repeated run with the same vulnerabilities leaves the comment as is
+	cfp.branchPullRequests = nil
+	require.NoError(t, cfp.commentOnScannedCommit())
+	assert.Equal(t, 1, posts)
+	assert.Equal(t, 0, patches)
+
+	// A run with different vulnerabilities updates the existing comment
+	cfp.branchVulnerabilities = nil
+	require.NoError(t, cfp.commentOnScannedCommit())
+	assert.Equal(t, 1, posts)
+	assert.Equal(t, 1, patches)
+	assert.Contains(t, commitComment, "No vulnerable dependencies were detected in this commit.")
+}
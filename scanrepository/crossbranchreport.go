@@ -0,0 +1,13 @@
+package scanrepository
+
+// Adds the vulnerabilities of the current branch to the cross-branch report, if requested
+func (cfp *ScanRepositoryCmd) addBranchToCrossBranchReport() {
+	if cfp.crossBranchResults == nil {
+		return
+	}
+	branch := cfp.scanDetails.BaseBranch()
+	cfp.crossBranchResults.AddBranch(branch)
+	for _, vulnerability := range cfp.branchVulnerabilities {
+		cfp.crossBranchResults.AddVulnerability(branch, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, vulnerability.Severity, getTrackedVulnerabilityIds(vulnerability))
+	}
+}
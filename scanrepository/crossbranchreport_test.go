@@ -0,0 +1,58 @@
+package scanrepository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossBranchReport(t *testing.T) {
+	newVulnerability := func(packageName, version, severity, cve string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: packageName, ImpactedDependencyVersion: version},
+			Cves:                      []formats.CveRow{{Id: cve}},
+		}
+	}
+	lodash := newVulnerability("lodash", "4.17.20", "High", "CVE-2021-23337")
+	minimist := newVulnerability("minimist", "1.2.5", "Critical", "CVE-2021-44906")
+	pyjwt := newVulnerability("pyjwt", "1.7.1", "Medium", "CVE-2022-29217")
+	branchesVulnerabilities := map[string][]formats.VulnerabilityOrViolationRow{
+		// The same vulnerability detected in two projects of the branch is listed once
+		"master":      {lodash, minimist, lodash},
+		"release/1.0": {lodash, pyjwt},
+		"release/0.9": {},
+	}
+
+	reportPath := filepath.Join(t.TempDir(), "reports", "cross-branch.md")
+	cfp := &ScanRepositoryCmd{
+		scanDetails:        utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}),
+		crossBranchResults: utils.NewCrossBranchReport("jfrog/frogbot"),
+	}
+	for _, branch := range []string{"master", "release/1.0", "release/0.9"} {
+		cfp.scanDetails.SetBaseBranch(branch)
+		cfp.branchVulnerabilities = branchesVulnerabilities[branch]
+		cfp.addBranchToCrossBranchReport()
+	}
+	require.NoError(t, utils.WriteCrossBranchReport(cfp.crossBranchResults, reportPath))
+
+	content, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	expected := "# Frogbot Cross-Branch Report\n\n" +
+		"Repository: jfrog/frogbot\n\n" +
+		"| Severity | Package | Vulnerability | master | release/1.0 | release/0.9 |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| Critical | minimist:1.2.5 | CVE-2021-44906 | ✔ |  |  |\n" +
+		"| High | lodash:4.17.20 | CVE-2021-23337 | ✔ | ✔ |  |\n" +
+		"| Medium | pyjwt:1.7.1 | CVE-2022-29217 |  | ✔ |  |\n"
+	assert.Equal(t, expected, string(content))
+
+	// Branches without vulnerabilities are listed in the report
+	emptyReport := utils.NewCrossBranchReport("jfrog/frogbot")
+	emptyReport.AddBranch("master")
+	assert.Contains(t, utils.RenderCrossBranchReport(emptyReport), "No vulnerabilities were detected on the scanned branches: master")
+}
@@ -0,0 +1,71 @@
+package scanrepository
+
+import (
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+)
+
+// DryRunResult is everything a dry run would do on the Git provider, so it can be asserted on without a mock VCS server.
+type DryRunResult struct {
+	// The pull requests that would be opened or updated, in the order they were prepared
+	PullRequests []DryRunPullRequest
+}
+
+// DryRunPullRequest is a pull request a dry run would open or update, along with the changes of its fix branch
+type DryRunPullRequest struct {
+	Action       utils.PullRequestAction
+	RepoOwner    string
+	RepoName     string
+	SourceBranch string
+	TargetBranch string
+	Title        string
+	Body         string
+	Comments     []string
+	Draft        bool
+	// The reviewers requested in addition to the default reviewers of the repository
+	Reviewers []string
+	// The packages the pull request fixes, sorted by their names
+	Packages []outputwriter.PackageStatusRow
+	// The changes of the fix branch relative to the target branch, in the unified diff format
+	Patch string
+}
+
+// GetPullRequest returns the pull request from the given fix branch, or nil if the dry run wouldn't open it.
+func (drr *DryRunResult) GetPullRequest(sourceBranch string) *DryRunPullRequest {
+	for i := range drr.PullRequests {
+		if drr.PullRequests[i].SourceBranch == sourceBranch {
+			return &drr.PullRequests[i]
+		}
+	}
+	return nil
+}
+
+// DryRunResult returns the pull requests the last dry run would open or update, or nil if the command didn't run as a dry run.
+func (cfp *ScanRepositoryCmd) DryRunResult() *DryRunResult {
+	return cfp.dryRunResult
+}
+
+// Records the pull request of the checked out fix branch in the dry run result
+func (cfp *ScanRepositoryCmd) recordDryRunPullRequest(operation *utils.PullRequestOperation, vulnerabilities []*utils.VulnerabilityDetails) (err error) {
+	pullRequest := DryRunPullRequest{
+		Action:       operation.Action,
+		RepoOwner:    operation.RepoOwner,
+		RepoName:     operation.RepoName,
+		SourceBranch: operation.SourceBranch,
+		TargetBranch: operation.TargetBranch,
+		Title:        operation.Title,
+		Body:         operation.Body,
+		Comments:     operation.Comments,
+		Draft:        operation.Draft,
+		Reviewers:    operation.Reviewers,
+		Packages:     utils.ExtractFixedPackagesStatus(vulnerabilities),
+	}
+	if _, _, pullRequest.Patch, err = cfp.gitManager.GetChangesFromBranch(operation.TargetBranch); err != nil {
+		return
+	}
+	if cfp.dryRunResult == nil {
+		cfp.dryRunResult = &DryRunResult{}
+	}
+	cfp.dryRunResult.PullRequests = append(cfp.dryRunResult.PullRequests, pullRequest)
+	return
+}
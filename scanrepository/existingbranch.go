@@ -0,0 +1,54 @@
+package scanrepository
+
+import (
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// The maximal numeric suffix of a new fix branch that is pushed in place of an existing branch.
+// Reaching it means the fix keeps changing while its branches aren't merged, so no further branches are opened.
+const maxExistingBranchSuffix = 5
+
+// Compares the committed fix with the fix of the existing fix branch, and returns the branch to push the fix to,
+// or an empty string if the fix was already pushed.
+// The existing branch is replaced when its stale pull request is rebased, or by the update policy.
+// By the new policy, the fix is pushed to the first branch with a numeric suffix that doesn't exist yet, unless one of the suffixed branches already holds the fix.
+func (cfp *ScanRepositoryCmd) resolveExistingFixBranch(fixBranchName string, isRebase bool) (string, error) {
+	if isRebase {
+		// The fix of a stale branch is the same fix on top of an older base, which is exactly what the rebase replaces
+		return fixBranchName, nil
+	}
+	isEqual, err := cfp.gitManager.IsRemoteBranchFixEqual(fixBranchName)
+	if err != nil {
+		return "", err
+	}
+	if isEqual {
+		log.Info(fmt.Sprintf("The fix branch '%s' already contains the fix. Skipping...", fixBranchName))
+		return "", nil
+	}
+	if cfp.onExistingBranch != utils.NewExistingBranchPolicy {
+		return fixBranchName, nil
+	}
+	for suffix := 2; suffix <= maxExistingBranchSuffix; suffix++ {
+		newBranchName := fmt.Sprintf("%s-%d", fixBranchName, suffix)
+		exists, err := cfp.gitManager.BranchExistsInRemote(newBranchName)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			log.Info(fmt.Sprintf("The fix of the branch '%s' differs from the fix. Pushing the fix to the new branch '%s'...", fixBranchName, newBranchName))
+			return newBranchName, cfp.gitManager.CreateBranchAndCheckout(newBranchName, false)
+		}
+		if isEqual, err = cfp.gitManager.IsRemoteBranchFixEqual(newBranchName); err != nil {
+			return "", err
+		}
+		if isEqual {
+			log.Info(fmt.Sprintf("The fix branch '%s' already contains the fix. Skipping...", newBranchName))
+			return "", nil
+		}
+	}
+	log.Warn(fmt.Sprintf("The limit of %d branches of the fix '%s' was reached. Merge its pull requests or delete their branches to let Frogbot push the fix again", maxExistingBranchSuffix, fixBranchName))
+	return "", fmt.Errorf("couldn't push the fix to a new branch, as the branches '%[1]s-2' to '%[1]s-%[2]d' already exist", fixBranchName, maxExistingBranchSuffix)
+}
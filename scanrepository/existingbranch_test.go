@@ -0,0 +1,216 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/packagehandlers"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnExistingBranch(t *testing.T) {
+	const (
+		vulnerableContent = `{"dependencies": {"minimist": "1.2.5"}}`
+		fixContent        = `{"dependencies": {"minimist": "1.2.6"}}`
+		// The fix branch was pushed by someone else, with another change on top of the upgrade
+		manualFixContent = `{"dependencies": {"minimist": "1.2.6", "lodash": "4.17.21"}}`
+	)
+	testCases := []struct {
+		name            string
+		policy          utils.ExistingBranchPolicy
+		existingContent string
+		// The expected content of package.json in the existing fix branch, and in the new suffixed branch if one is expected
+		expectedFixBranchContent   string
+		expectedNewBranchContent   string
+		expectedUpdatedPullRequest bool
+		// The base branch moved since the existing fix branch was pushed
+		baseBranchMoved bool
+	}{
+		{
+			name:                       "update replaces the existing branch",
+			policy:                     utils.UpdateExistingBranchPolicy,
+			existingContent:            manualFixContent,
+			expectedFixBranchContent:   fixContent,
+			expectedUpdatedPullRequest: true,
+		},
+		{
+			name:                     "update leaves a branch that already holds the fix",
+			policy:                   utils.UpdateExistingBranchPolicy,
+			existingContent:          fixContent,
+			expectedFixBranchContent: fixContent,
+		},
+		{
+			name:                     "skip leaves the existing branch",
+			policy:                   utils.SkipExistingBranchPolicy,
+			existingContent:          manualFixContent,
+			expectedFixBranchContent: manualFixContent,
+		},
+		{
+			name:                     "new leaves a branch that holds the fix on top of an older base",
+			policy:                   utils.NewExistingBranchPolicy,
+			existingContent:          fixContent,
+			expectedFixBranchContent: fixContent,
+			baseBranchMoved:          true,
+		},
+		{
+			name:                     "new pushes the fix to a suffixed branch",
+			policy:                   utils.NewExistingBranchPolicy,
+			existingContent:          manualFixContent,
+			expectedFixBranchContent: manualFixContent,
+			expectedNewBranchContent: fixContent,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			remoteDir, repoDir := filepath.Join(tmpDir, "remote"), filepath.Join(tmpDir, "repo")
+			remote, err := git.PlainInit(remoteDir, true)
+			require.NoError(t, err)
+			repo, err := git.PlainInit(repoDir, false)
+			require.NoError(t, err)
+			_, err = repo.CreateRemote(&config.RemoteConfig{Name: vcsutils.RemoteName, URLs: []string{remoteDir}})
+			require.NoError(t, err)
+			worktree, err := repo.Worktree()
+			require.NoError(t, err)
+			commitFileAndPush := func(file, content, message, branch string) {
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, file), []byte(content), 0600))
+				_, err = worktree.Add(file)
+				require.NoError(t, err)
+				_, err = worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: "maintainer", Email: "maintainer@example.com", When: time.Now()}})
+				require.NoError(t, err)
+				require.NoError(t, repo.Push(&git.PushOptions{RemoteName: vcsutils.RemoteName, RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("refs/heads/%[1]s:refs/heads/%[1]s", branch))}}))
+			}
+			commitAndPush := func(content, message, branch string) {
+				commitFileAndPush("package.json", content, message, branch)
+			}
+			restoreDir, err := utils.Chdir(repoDir)
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, restoreDir())
+			}()
+			gitParams := &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot"}
+			gitManager, err := utils.NewGitManager().SetLocalRepository()
+			require.NoError(t, err)
+			_, err = gitManager.SetGitParams(gitParams)
+			require.NoError(t, err)
+			fixBranchName, err := gitManager.GenerateFixBranchName("master", "minimist", "1.2.6", "")
+			require.NoError(t, err)
+
+			// The fix branch already exists on the remote, with an open pull request
+			commitAndPush(vulnerableContent, "Initial commit", "master")
+			require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(fixBranchName), Create: true}))
+			commitAndPush(test.existingContent, "Upgrade minimist", fixBranchName)
+			require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}))
+			if test.baseBranchMoved {
+				commitFileAndPush("README.md", "# frogbot", "Add a readme", "master")
+			}
+			// The fix branches aren't cloned, as only the base branch is
+			removeFixBranches := func() {
+				branches, err := repo.Branches()
+				require.NoError(t, err)
+				require.NoError(t, branches.ForEach(func(branch *plumbing.Reference) error {
+					if branch.Name() == plumbing.Master {
+						return nil
+					}
+					return repo.Storer.RemoveReference(branch.Name())
+				}))
+			}
+			removeFixBranches()
+
+			// A mock GitHub server that records the created and updated pull requests
+			pullRequestJson := func(number int, branch string) string {
+				return fmt.Sprintf(`{"number": %[1]d, "html_url": "https://github.com/jfrog/frogbot/pull/%[1]d", "head": {"ref": "%[2]s", "label": "jfrog:%[2]s", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}, "base": {"ref": "master", "label": "jfrog:master", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}}`, number, branch)
+			}
+			pullRequests := []string{pullRequestJson(7, fixBranchName)}
+			var createdBranches []string
+			updatedPullRequest := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+					_, err := w.Write([]byte("[" + strings.Join(pullRequests, ",") + "]"))
+					assert.NoError(t, err)
+				case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+					var createdPullRequest map[string]any
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&createdPullRequest))
+					branch := strings.TrimPrefix(fmt.Sprint(createdPullRequest["head"]), "jfrog:")
+					createdBranches = append(createdBranches, branch)
+					pullRequests = append(pullRequests, pullRequestJson(7+len(createdBranches), branch))
+					w.WriteHeader(http.StatusCreated)
+					_, err := w.Write([]byte(pullRequests[len(pullRequests)-1]))
+					assert.NoError(t, err)
+				case r.Method == http.MethodPatch && r.URL.Path == "/repos/jfrog/frogbot/pulls/7":
+					updatedPullRequest = true
+					_, err := w.Write([]byte(pullRequests[0]))
+					assert.NoError(t, err)
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/comments"):
+					_, err := w.Write([]byte("[]"))
+					assert.NoError(t, err)
+				default:
+					assert.Fail(t, "unexpected request", "%s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+			require.NoError(t, err)
+
+			cfp := &ScanRepositoryCmd{
+				OutputWriter:     &outputwriter.StandardOutput{},
+				gitManager:       gitManager,
+				scanDetails:      utils.NewScanDetails(client, nil, gitParams).SetBaseBranch("master"),
+				handlers:         map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: packageJsonFixHandler},
+				onExistingBranch: test.policy,
+			}
+			repository := &utils.Repository{OutputWriter: cfp.OutputWriter, Params: utils.Params{Git: utils.Git{RepoOwner: "jfrog", RepoName: "frogbot", PullRequestDetails: vcsclient.PullRequestInfo{ID: 7, Target: vcsclient.BranchInfo{Name: "master", Repository: "frogbot", Owner: "jfrog"}}}}}
+			vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+				Technology:                techutils.Npm,
+			}, "1.2.6")
+			// The second run finds the branches of the first run, so it leaves them as they are
+			for run := 0; run < 2; run++ {
+				require.NoError(t, cfp.fixSinglePackageAndCreatePR(repository, vulnDetails, ""))
+				require.NoError(t, gitManager.Checkout("master"))
+				removeFixBranches()
+			}
+
+			branches, err := remote.Branches()
+			require.NoError(t, err)
+			actualBranches := map[string]string{}
+			require.NoError(t, branches.ForEach(func(branch *plumbing.Reference) error {
+				commit, err := remote.CommitObject(branch.Hash())
+				require.NoError(t, err)
+				descriptor, err := commit.File("package.json")
+				require.NoError(t, err)
+				actualBranches[branch.Name().Short()], err = descriptor.Contents()
+				return err
+			}))
+			expectedBranches := map[string]string{"master": vulnerableContent, fixBranchName: test.expectedFixBranchContent}
+			var expectedCreatedBranches []string
+			if test.expectedNewBranchContent != "" {
+				expectedBranches[fixBranchName+"-2"] = test.expectedNewBranchContent
+				expectedCreatedBranches = append(expectedCreatedBranches, fixBranchName+"-2")
+			}
+			assert.Equal(t, expectedBranches, actualBranches)
+			assert.Equal(t, expectedCreatedBranches, createdBranches)
+			assert.Equal(t, test.expectedUpdatedPullRequest, updatedPullRequest)
+		})
+	}
+}
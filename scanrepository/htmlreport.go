@@ -0,0 +1,57 @@
+package scanrepository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+)
+
+// Writes the HTML report of the current branch, if requested.
+// The report is written on dry runs as well, as it doesn't change the repository.
+func (cfp *ScanRepositoryCmd) writeHtmlReport() error {
+	if cfp.htmlReport == "" {
+		return nil
+	}
+	return utils.WriteHtmlReport(cfp.createHtmlReport(), cfp.htmlReport)
+}
+
+// Creates an HTML report with a row per vulnerable package and vulnerability, stating whether Frogbot fixes it.
+func (cfp *ScanRepositoryCmd) createHtmlReport() *utils.HtmlReport {
+	report := &utils.HtmlReport{
+		Repository:  fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName),
+		Branch:      cfp.scanDetails.BaseBranch(),
+		GeneratedAt: time.Now(),
+		DryRun:      cfp.dryRun,
+	}
+	for _, vulnerability := range cfp.getUniqueBranchVulnerabilities() {
+		unfixedReason := cfp.getUnfixedReason(vulnerability.VulnerabilityOrViolationRow)
+		report.Rows = append(report.Rows, utils.HtmlReportRow{
+			PackageName:      vulnerability.ImpactedDependencyName,
+			PackageVersion:   vulnerability.ImpactedDependencyVersion,
+			Severity:         vulnerability.Severity,
+			Applicable:       vulnerability.Applicable,
+			VulnerabilityIds: vulnerability.ids,
+			FixVersion:       cfp.getHtmlReportFixVersion(vulnerability.VulnerabilityOrViolationRow),
+			Fixed:            unfixedReason == "",
+			UnfixedReason:    unfixedReason,
+		})
+	}
+	return report
+}
+
+// Returns the version Frogbot fixes the package to, or the fix versions Xray suggests if the package isn't fixed
+func (cfp *ScanRepositoryCmd) getHtmlReportFixVersion(vulnerability formats.VulnerabilityOrViolationRow) string {
+	for _, fix := range cfp.sbomFixes {
+		if fix.ImpactedDependencyName == vulnerability.ImpactedDependencyName && fix.ImpactedDependencyVersion == vulnerability.ImpactedDependencyVersion {
+			return fix.SuggestedFixedVersion
+		}
+	}
+	var fixVersions []string
+	for _, fixVersion := range vulnerability.FixedVersions {
+		fixVersions = append(fixVersions, strings.Trim(fixVersion, "[]"))
+	}
+	return strings.Join(fixVersions, ", ")
+}
@@ -0,0 +1,76 @@
+package scanrepository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHtmlReport(t *testing.T) {
+	newVulnerability := func(name, version, severity string, cves []string, fixedVersions ...string) formats.VulnerabilityOrViolationRow {
+		row := formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			FixedVersions:             fixedVersions,
+			Applicable:                "Applicable",
+		}
+		for _, cve := range cves {
+			row.Cves = append(row.Cves, formats.CveRow{Id: cve})
+		}
+		return row
+	}
+	cfp := ScanRepositoryCmd{
+		dryRun:      true,
+		htmlReport:  filepath.Join(t.TempDir(), "reports", "frogbot.html"),
+		scanDetails: utils.NewScanDetails(nil, nil, &utils.Git{}),
+		branchVulnerabilities: []formats.VulnerabilityOrViolationRow{
+			newVulnerability("lodash", "4.17.20", "High", []string{"CVE-2021-23337"}, "[4.17.21]"),
+			newVulnerability("minimist", "1.2.5", "Critical", []string{"CVE-2021-44906"}, "[1.2.6]"),
+			newVulnerability("<script>alert(1)</script>", "5.7.1", "Medium", []string{"CVE-2022-25883"}, "[5.7.2]", "[6.3.1]"),
+			// The same vulnerability in another project of the repository is reported once
+			newVulnerability("lodash", "4.17.20", "High", []string{"CVE-2021-23337"}, "[4.17.21]"),
+		},
+		unsupportedFixes: map[string]*utils.ErrUnsupportedFix{},
+	}
+	cfp.scanDetails.SetRepoOwner("jfrog").SetRepoName("frogbot")
+	lodash := utils.NewVulnerabilityDetails(cfp.branchVulnerabilities[0], "4.17.21")
+	minimist := utils.NewVulnerabilityDetails(cfp.branchVulnerabilities[1], "1.2.6")
+	cfp.sbomFixes = []*utils.VulnerabilityDetails{lodash, minimist}
+	cfp.recordUnsupportedFix(minimist, &utils.ErrUnsupportedFix{PackageName: "minimist", FixedVersion: "1.2.6", ErrorType: utils.IndirectDependencyFixNotSupported})
+	require.NoError(t, cfp.writeHtmlReport())
+
+	content, err := os.ReadFile(cfp.htmlReport)
+	require.NoError(t, err)
+	report := string(content)
+	assert.Contains(t, report, "jfrog/frogbot")
+	assert.Contains(t, report, "Dry run")
+	assert.Contains(t, report, "<strong>Vulnerabilities:</strong> 3")
+	assert.Contains(t, report, "<strong>Fixed:</strong> 1")
+	// The report is self-contained
+	assert.NotContains(t, report, "<link")
+	assert.NotContains(t, report, "src=")
+
+	// The fixed package
+	assert.Contains(t, report, `<td>lodash</td>
+<td>4.17.20</td>
+<td class="severity-High" data-sort="`)
+	assert.Contains(t, report, `<td>CVE-2021-23337</td>
+<td>4.17.21</td>
+<td class="fixed">✅ Fixed</td>`)
+	// The package whose fix isn't supported
+	assert.Contains(t, report, `<td>minimist</td>
+<td>1.2.5</td>`)
+	assert.Contains(t, report, `<td>CVE-2021-44906</td>
+<td>1.2.6</td>
+<td class="unfixed">Not fixed: `)
+	// The package without a suggested fix lists the fix versions of Xray, and its name is escaped
+	assert.Contains(t, report, `<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>`)
+	assert.NotContains(t, report, "<script>alert(1)</script>")
+	assert.Contains(t, report, `<td>CVE-2022-25883</td>
+<td>5.7.2, 6.3.1</td>
+<td class="unfixed">Not fixed: No fix was suggested for this vulnerability in the current run.</td>`)
+}
@@ -0,0 +1,67 @@
+package scanrepository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"golang.org/x/exp/slices"
+)
+
+// Writes the JUnit report of the current branch, if requested.
+// The report is written on dry runs as well, as it doesn't change the repository.
+func (cfp *ScanRepositoryCmd) writeJunitReport() error {
+	if cfp.junitOutput == "" {
+		return nil
+	}
+	return utils.WriteJunitReport(cfp.createJunitReport(), cfp.junitOutput)
+}
+
+// Creates a JUnit report in which each vulnerability is a test case, grouped into a test suite per vulnerable package.
+// A vulnerability Frogbot fixes passes. A vulnerability that isn't fixed fails, unless its severity is below the failure severity, in which case it's skipped.
+func (cfp *ScanRepositoryCmd) createJunitReport() *utils.JunitTestSuites {
+	report := utils.NewJunitReport()
+	for _, vulnerability := range cfp.getUniqueBranchVulnerabilities() {
+		packageName := fmt.Sprintf("%s:%s", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+		unfixedReason := cfp.getUnfixedReason(vulnerability.VulnerabilityOrViolationRow)
+		for _, vulnerabilityId := range vulnerability.ids {
+			testCase := utils.JunitTestCase{Name: fmt.Sprintf("%s [%s]", vulnerabilityId, vulnerability.Severity)}
+			switch {
+			case unfixedReason == "":
+			case cfp.isBelowJunitFailureSeverity(vulnerability.VulnerabilityOrViolationRow):
+				testCase.Skipped = &utils.JunitSkipped{Message: unfixedReason}
+			default:
+				testCase.Failure = &utils.JunitFailure{
+					Message: unfixedReason,
+					Type:    vulnerability.Severity,
+					Details: fmt.Sprintf("%s %s in %s isn't fixed: %s", vulnerability.Severity, vulnerabilityId, packageName, unfixedReason),
+				}
+			}
+			report.AddTestCase(packageName, testCase)
+		}
+	}
+	return report
+}
+
+// Returns the reason the vulnerability isn't fixed, or an empty string if Frogbot fixes it
+func (cfp *ScanRepositoryCmd) getUnfixedReason(vulnerability formats.VulnerabilityOrViolationRow) string {
+	if unsupportedFix := cfp.getUnsupportedFix(vulnerability); unsupportedFix != nil {
+		return strings.TrimSpace(unsupportedFix.Error())
+	}
+	isFixed := slices.ContainsFunc(cfp.sbomFixes, func(fix *utils.VulnerabilityDetails) bool {
+		return fix.ImpactedDependencyName == vulnerability.ImpactedDependencyName && fix.ImpactedDependencyVersion == vulnerability.ImpactedDependencyVersion
+	})
+	if !isFixed {
+		return "No fix was suggested for this vulnerability in the current run."
+	}
+	return ""
+}
+
+func (cfp *ScanRepositoryCmd) isBelowJunitFailureSeverity(vulnerability formats.VulnerabilityOrViolationRow) bool {
+	if cfp.junitFailureSeverity == "" {
+		return false
+	}
+	return severityutils.CompareSeverity(severityutils.GetSeverity(vulnerability.Severity), cfp.junitFailureSeverity) < 0
+}
@@ -0,0 +1,75 @@
+package scanrepository
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJunitReport(t *testing.T) {
+	newVulnerability := func(name, version, severity string, cves []string, fixedVersions ...string) formats.VulnerabilityOrViolationRow {
+		row := formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			FixedVersions:             fixedVersions,
+		}
+		for _, cve := range cves {
+			row.Cves = append(row.Cves, formats.CveRow{Id: cve})
+		}
+		return row
+	}
+	cfp := ScanRepositoryCmd{
+		junitOutput:          filepath.Join(t.TempDir(), "reports", "frogbot.xml"),
+		junitFailureSeverity: severityutils.High,
+		branchVulnerabilities: []formats.VulnerabilityOrViolationRow{
+			newVulnerability("lodash", "4.17.20", "High", []string{"CVE-2021-23337", "CVE-2020-28500"}, "[4.17.21]"),
+			newVulnerability("minimist", "1.2.5", "Critical", []string{"CVE-2021-44906"}, "[1.2.6]"),
+			newVulnerability("semver", "5.7.1", "Medium", []string{"CVE-2022-25883"}),
+			// The same vulnerability in another project of the repository is reported once
+			newVulnerability("semver", "5.7.1", "Medium", []string{"CVE-2022-25883"}),
+		},
+		unsupportedFixes: map[string]*utils.ErrUnsupportedFix{},
+	}
+	lodash := utils.NewVulnerabilityDetails(cfp.branchVulnerabilities[0], "4.17.21")
+	minimist := utils.NewVulnerabilityDetails(cfp.branchVulnerabilities[1], "1.2.6")
+	cfp.sbomFixes = []*utils.VulnerabilityDetails{lodash, minimist}
+	cfp.recordUnsupportedFix(minimist, &utils.ErrUnsupportedFix{PackageName: "minimist", FixedVersion: "1.2.6", ErrorType: utils.IndirectDependencyFixNotSupported})
+	require.NoError(t, cfp.writeJunitReport())
+
+	content, err := os.ReadFile(cfp.junitOutput)
+	require.NoError(t, err)
+	var report utils.JunitTestSuites
+	require.NoError(t, xml.Unmarshal(content, &report))
+	assert.Equal(t, "Frogbot", report.Name)
+	assert.Equal(t, 4, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.TestSuites, 3)
+
+	// The fixed vulnerabilities pass
+	assert.Equal(t, "lodash:4.17.20", report.TestSuites[0].Name)
+	assert.Equal(t, 2, report.TestSuites[0].Tests)
+	for _, testCase := range report.TestSuites[0].TestCases {
+		assert.Equal(t, "lodash:4.17.20", testCase.ClassName)
+		assert.Nil(t, testCase.Failure)
+		assert.Nil(t, testCase.Skipped)
+	}
+	// An unfixed vulnerability at the failure severity fails
+	assert.Equal(t, "minimist:1.2.5", report.TestSuites[1].Name)
+	require.Len(t, report.TestSuites[1].TestCases, 1)
+	assert.Equal(t, "CVE-2021-44906 [Critical]", report.TestSuites[1].TestCases[0].Name)
+	require.NotNil(t, report.TestSuites[1].TestCases[0].Failure)
+	assert.Equal(t, "Critical", report.TestSuites[1].TestCases[0].Failure.Type)
+	assert.Equal(t, 1, report.TestSuites[1].Failures)
+	// An unfixed vulnerability below the failure severity is skipped
+	assert.Equal(t, "semver:5.7.1", report.TestSuites[2].Name)
+	require.Len(t, report.TestSuites[2].TestCases, 1)
+	require.NotNil(t, report.TestSuites[2].TestCases[0].Skipped)
+	assert.Contains(t, report.TestSuites[2].TestCases[0].Skipped.Message, "No fixed version of semver is available yet.")
+}
@@ -0,0 +1,68 @@
+package scanrepository
+
+import (
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/packagehandlers"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+// Records the license violations of the scanned technology for the reports, and if fixing them is enabled,
+// adds the violating packages to the vulnerabilities map with the lowest newer version whose license is acceptable
+func (cfp *ScanRepositoryCmd) addLicenseViolations(scaResult *securityutils.ScaScanResult, scanResults *securityutils.Results, isMultipleRoots bool, vulnerabilitiesMap map[string]*utils.VulnerabilityDetails) error {
+	for _, xrayResult := range scaResult.XrayResults {
+		if len(xrayResult.Violations) == 0 {
+			continue
+		}
+		_, licenseViolations, _, err := securityutils.PrepareViolations(xrayResult.Violations, scanResults, isMultipleRoots, true)
+		if err != nil {
+			return err
+		}
+		for _, licenseViolation := range licenseViolations {
+			if slices.Contains(cfp.workspacePackages, licenseViolation.ImpactedDependencyName) {
+				log.Debug(fmt.Sprintf("Skipping the license violation of '%s:%s' (%s), as it is a workspace package that is maintained in the repository", licenseViolation.ImpactedDependencyName, licenseViolation.ImpactedDependencyVersion, licenseViolation.LicenseKey))
+				continue
+			}
+			log.Info(fmt.Sprintf("The license %s of '%s:%s' isn't allowed", licenseViolation.LicenseKey, licenseViolation.ImpactedDependencyName, licenseViolation.ImpactedDependencyVersion))
+			cfp.branchLicenseViolations = append(cfp.branchLicenseViolations, licenseViolation)
+			if !cfp.fixLicenseViolations {
+				continue
+			}
+			if cfp.metadataLookupPolicy == utils.DisabledMetadataLookupPolicy {
+				log.Debug(fmt.Sprintf("Skipping the lookup of a version of '%s' whose license is acceptable, as the metadata lookups are disabled", licenseViolation.ImpactedDependencyName))
+				continue
+			}
+			acceptableVersion, err := packagehandlers.FindVersionWithAcceptableLicense(scaResult.Technology, licenseViolation.ImpactedDependencyName, licenseViolation.ImpactedDependencyVersion, licenseViolation.LicenseKey, cfp.allowedLicenses)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Couldn't look up a version of '%s' whose license is acceptable:\n%s", licenseViolation.ImpactedDependencyName, err.Error()))
+				continue
+			}
+			if acceptableVersion == "" {
+				log.Info(fmt.Sprintf("No newer version of '%s' has an acceptable license. Skipping...", licenseViolation.ImpactedDependencyName))
+				continue
+			}
+			vulnerability := toLicenseViolationRow(licenseViolation, scaResult.Technology, acceptableVersion)
+			if err = cfp.addVulnerabilityToFixVersionsMap(&vulnerability, vulnerabilitiesMap); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// The license violation is fixed like a vulnerability whose only fixed version is the version with the acceptable license
+func toLicenseViolationRow(licenseViolation formats.LicenseRow, tech techutils.Technology, acceptableVersion string) formats.VulnerabilityOrViolationRow {
+	return formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: licenseViolation.ImpactedDependencyDetails,
+		Summary:                   fmt.Sprintf("The license %s of %s isn't allowed", licenseViolation.LicenseKey, licenseViolation.ImpactedDependencyName),
+		FixedVersions:             []string{fmt.Sprintf("[%s]", acceptableVersion)},
+		IssueId:                   licenseViolation.LicenseKey,
+		ImpactPaths:               licenseViolation.ImpactPaths,
+		Technology:                tech,
+	}
+}
@@ -0,0 +1,29 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jfrog/frogbot/v2/utils"
+)
+
+// Runs the lockfile validation command of the technology in the working directory of the fix, such as 'npm ci --dry-run',
+// to catch a corrupt lockfile regeneration before its pull request is opened. A failed validation aborts the fix with the output of the command.
+func (cfp *ScanRepositoryCmd) validateFixedLockfile(vulnDetails *utils.VulnerabilityDetails) error {
+	if cfp.scanDetails.Project == nil {
+		return nil
+	}
+	command := cfp.scanDetails.GetLockfileValidateCommand(vulnDetails.Technology)
+	if command == "" {
+		return nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err = cfp.runRepositoryCommand(context.Background(), command, "lockfile validation command", wd); err != nil {
+		return fmt.Errorf("the lockfile is invalid after updating %s to version %s: %w", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package scanrepository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/packagehandlers"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFixedLockfile(t *testing.T) {
+	testCases := []struct {
+		name             string
+		validateCommand  string
+		expectedErr      string
+		expectedPrsCount int
+	}{
+		{name: "No validation", expectedPrsCount: 1},
+		{name: "Valid lockfile", validateCommand: `grep -q '"minimist": "1.2.6"' package.json`, expectedPrsCount: 1},
+		{name: "Invalid lockfile", validateCommand: "echo 'npm ERR! lockfile is out of sync with package.json' && exit 1", expectedErr: "npm ERR! lockfile is out of sync with package.json"},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			repo, err := git.PlainInit(repoDir, false)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(`{"dependencies": {"minimist": "1.2.5"}}`), 0600))
+			worktree, err := repo.Worktree()
+			require.NoError(t, err)
+			_, err = worktree.Add("package.json")
+			require.NoError(t, err)
+			_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}})
+			require.NoError(t, err)
+			restoreDir, err := utils.Chdir(repoDir)
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, restoreDir())
+			}()
+
+			gitParams := &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}
+			gitManager, err := utils.NewGitManager().SetDryRun(true, "").SetLocalRepository()
+			require.NoError(t, err)
+			_, err = gitManager.SetGitParams(gitParams)
+			require.NoError(t, err)
+			project := &utils.Project{}
+			if test.validateCommand != "" {
+				project.LockfileValidateCommands = map[string]string{techutils.Npm.String(): test.validateCommand}
+			}
+			cfp := &ScanRepositoryCmd{
+				OutputWriter:    &outputwriter.StandardOutput{},
+				gitManager:      gitManager,
+				scanDetails:     utils.NewScanDetails(nil, nil, gitParams).SetBaseBranch("master").SetProject(project),
+				baseWd:          repoDir,
+				dryRun:          true,
+				dryRunOutput:    &strings.Builder{},
+				pullRequestSink: &discardPullRequestSink{},
+				handlers:        map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: packageJsonFixHandler},
+			}
+			vulnerabilitiesByPathMap := map[string]map[string]*utils.VulnerabilityDetails{
+				repoDir: {"minimist": utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+					ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+					Technology:                techutils.Npm,
+				}, "1.2.6")},
+			}
+			err = cfp.fixVulnerablePackages(&utils.Repository{}, vulnerabilitiesByPathMap)
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				// The fix is aborted with the output of the validation command
+				assert.ErrorContains(t, err, "the lockfile is invalid after updating minimist to version 1.2.6")
+				assert.ErrorContains(t, err, test.expectedErr)
+			}
+			if test.expectedPrsCount == 0 {
+				assert.Nil(t, cfp.DryRunResult())
+			} else {
+				require.NotNil(t, cfp.DryRunResult())
+				assert.Len(t, cfp.DryRunResult().PullRequests, test.expectedPrsCount)
+			}
+		})
+	}
+}
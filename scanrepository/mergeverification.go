@@ -0,0 +1,96 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/gofrog/datastructures"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+// Loads the fix pull requests recorded in the previous runs of the current branch.
+// The pull requests that are still open remain tracked, while the ones that are no longer open are verified after the scan.
+func (cfp *ScanRepositoryCmd) loadMergedFixPullRequests() (err error) {
+	cfp.fixPullRequestsRecord = utils.NewFixPullRequestsRecord(cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch())
+	cfp.mergedFixPullRequests = []utils.FixPullRequest{}
+	cfp.detectedPackages = datastructures.MakeSet[string]()
+	record, err := utils.LoadFixPullRequestsRecord(cfp.stateDir, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch())
+	if err != nil || record == nil {
+		return
+	}
+	openPullRequests, err := cfp.scanDetails.Client().ListOpenPullRequests(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName)
+	if err != nil {
+		return
+	}
+	openPullRequestsIds := datastructures.MakeSet[int64]()
+	for _, pullRequest := range openPullRequests {
+		openPullRequestsIds.Add(pullRequest.ID)
+	}
+	for _, pullRequest := range record.PullRequests {
+		if openPullRequestsIds.Exists(pullRequest.ID) {
+			cfp.fixPullRequestsRecord.AddPullRequest(pullRequest)
+			continue
+		}
+		cfp.mergedFixPullRequests = append(cfp.mergedFixPullRequests, pullRequest)
+	}
+	log.Debug(fmt.Sprintf("Found %d fix pull requests that were merged since the last run", len(cfp.mergedFixPullRequests)))
+	return
+}
+
+// Escalates the merged fix pull requests whose packages are still vulnerable.
+// A note is added to the new fix, and the stale fix branch is removed, so that a new pull request is opened for it.
+func (cfp *ScanRepositoryCmd) escalatePersistingVulnerabilities(vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) {
+	for _, vulnerabilities := range vulnerabilitiesByPathMap {
+		for packageName, vulnDetails := range vulnerabilities {
+			cfp.detectedPackages.Add(packageName)
+			for _, pullRequest := range cfp.mergedFixPullRequests {
+				if !slices.Contains(pullRequest.Packages, packageName) {
+					continue
+				}
+				log.Info(fmt.Sprintf("The fix of '%s' was merged in %s, but the vulnerability is still detected. Opening a new fix...", packageName, pullRequest.URL))
+				vulnDetails.AddFixNote(fmt.Sprintf("A previous fix of %s was merged in %s, but %s %s is still vulnerable. Please review this fix carefully.",
+					packageName, pullRequest.URL, packageName, vulnDetails.ImpactedDependencyVersion))
+				cfp.removeStaleFixBranch(pullRequest.SourceBranch)
+			}
+		}
+	}
+}
+
+func (cfp *ScanRepositoryCmd) removeStaleFixBranch(branchName string) {
+	existsInRemote, err := cfp.gitManager.BranchExistsInRemote(branchName)
+	if err == nil && existsInRemote {
+		err = cfp.gitManager.RemoveRemoteBranch(branchName)
+	}
+	if err != nil {
+		log.Warn(fmt.Sprintf("Failed to remove the stale fix branch '%s': %s", branchName, err.Error()))
+	}
+}
+
+// Confirms the remediation on the merged fix pull requests whose packages are no longer detected, and records the fix pull requests to verify in the next run.
+func (cfp *ScanRepositoryCmd) confirmMergedFixPullRequests() (err error) {
+	for _, pullRequest := range cfp.mergedFixPullRequests {
+		if slices.ContainsFunc(pullRequest.Packages, cfp.detectedPackages.Exists) {
+			continue
+		}
+		log.Info("Remediation confirmed for", pullRequest.URL)
+		content := outputwriter.RemediationConfirmedContent(pullRequest.Packages, cfp.scanDetails.BaseBranch(), cfp.OutputWriter)
+		if e := cfp.scanDetails.Client().AddPullRequestComment(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, content, int(pullRequest.ID)); e != nil {
+			log.Warn(fmt.Sprintf("Failed to add the remediation confirmation to %s: %s", pullRequest.URL, e.Error()))
+		}
+	}
+	return utils.SaveFixPullRequestsRecord(cfp.stateDir, cfp.fixPullRequestsRecord)
+}
+
+func (cfp *ScanRepositoryCmd) recordFixPullRequest(pullRequestInfo *vcsclient.PullRequestInfo, fixBranchName string, vulnerabilities []*utils.VulnerabilityDetails) {
+	packages := datastructures.MakeSet[string]()
+	for _, vulnDetails := range vulnerabilities {
+		packages.Add(vulnDetails.ImpactedDependencyName)
+	}
+	fixedPackages := packages.ToSlice()
+	slices.Sort(fixedPackages)
+	cfp.fixPullRequestsRecord.AddPullRequest(utils.FixPullRequest{ID: pullRequestInfo.ID, URL: pullRequestInfo.URL, SourceBranch: fixBranchName, Packages: fixedPackages})
+}
@@ -0,0 +1,96 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v45/github"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAfterMerge(t *testing.T) {
+	// Pull request #3 is still open, while #1 and #2 were merged since the last run
+	openPullRequestId := 3
+	sourceLabel, targetLabel := "jfrog:frogbot-master-uuid", "jfrog:master"
+	repoName, owner := "repo", "jfrog"
+	openPullRequests := []*github.PullRequest{{
+		Number: &openPullRequestId,
+		Head:   &github.PullRequestBranch{Label: &sourceLabel, Repo: &github.Repository{Name: &repoName, Owner: &github.User{Login: &owner}}},
+		Base:   &github.PullRequestBranch{Label: &targetLabel, Repo: &github.Repository{Name: &repoName, Owner: &github.User{Login: &owner}}},
+	}}
+	comments := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/repo/pulls":
+			content, err := json.Marshal(openPullRequests)
+			assert.NoError(t, err)
+			_, err = w.Write(content)
+			assert.NoError(t, err)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/repos/jfrog/repo/issues/"):
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			comments[r.URL.Path] = string(body)
+			w.WriteHeader(http.StatusCreated)
+			_, err = w.Write([]byte("{}"))
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	stateDir := t.TempDir()
+	record := utils.NewFixPullRequestsRecord("jfrog", "repo", "master")
+	record.AddPullRequest(utils.FixPullRequest{ID: 1, URL: "https://github.com/jfrog/repo/pull/1", SourceBranch: "frogbot-master-minimist", Packages: []string{"minimist"}})
+	record.AddPullRequest(utils.FixPullRequest{ID: 2, URL: "https://github.com/jfrog/repo/pull/2", SourceBranch: "frogbot-master-lodash", Packages: []string{"lodash"}})
+	record.AddPullRequest(utils.FixPullRequest{ID: 3, URL: "https://github.com/jfrog/repo/pull/3", SourceBranch: "frogbot-master-uuid", Packages: []string{"uuid"}})
+	require.NoError(t, utils.SaveFixPullRequestsRecord(stateDir, record))
+
+	cfp := ScanRepositoryCmd{
+		OutputWriter:     &outputwriter.StandardOutput{},
+		scanDetails:      utils.NewScanDetails(client, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "repo"}).SetBaseBranch("master"),
+		gitManager:       utils.NewGitManager().SetDryRun(true, ""),
+		stateDir:         stateDir,
+		verifyAfterMerge: true,
+	}
+	require.NoError(t, cfp.loadMergedFixPullRequests())
+	require.Len(t, cfp.mergedFixPullRequests, 2)
+
+	// minimist was remediated, while lodash is still vulnerable after its fix was merged
+	lodash := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"}}, "4.17.21")
+	uuid := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "uuid", ImpactedDependencyVersion: "3.0.0"}}, "3.0.1")
+	cfp.escalatePersistingVulnerabilities(map[string]map[string]*utils.VulnerabilityDetails{"wd": {"lodash": lodash, "uuid": uuid}})
+	assert.Equal(t, []string{"A previous fix of lodash was merged in https://github.com/jfrog/repo/pull/2, but lodash 4.17.20 is still vulnerable. Please review this fix carefully."}, lodash.FixNotes)
+	assert.Empty(t, uuid.FixNotes)
+	// The persisting vulnerability is fixed in a new pull request
+	cfp.recordFixPullRequest(&vcsclient.PullRequestInfo{ID: 4, URL: "https://github.com/jfrog/repo/pull/4"}, "frogbot-master-lodash", []*utils.VulnerabilityDetails{lodash})
+
+	require.NoError(t, cfp.confirmMergedFixPullRequests())
+	require.Len(t, comments, 1)
+	confirmation, exists := comments["/repos/jfrog/repo/issues/1/comments"]
+	require.True(t, exists)
+	assert.Contains(t, confirmation, "Remediation Confirmed")
+	assert.Contains(t, confirmation, "minimist")
+
+	// The open pull request and the new one are verified in the next run
+	record, err = utils.LoadFixPullRequestsRecord(stateDir, "jfrog", "repo", "master")
+	require.NoError(t, err)
+	var recordedIds []string
+	for _, pullRequest := range record.PullRequests {
+		recordedIds = append(recordedIds, fmt.Sprint(pullRequest.ID))
+	}
+	assert.ElementsMatch(t, []string{"3", "4"}, recordedIds)
+}
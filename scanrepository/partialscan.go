@@ -0,0 +1,59 @@
+package scanrepository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Handles a scan that failed, according to the partial scan policy, if Xray returned results for some of the scanned components.
+// Returns the results of the analyzed components if the run should continue with them.
+// A scan that has no results for any of its components fails the run, whatever the policy is.
+func (cfp *ScanRepositoryCmd) handlePartialScan(auditResults *securityutils.Results, scanErr error, currentWorkingDir string) (*securityutils.Results, error) {
+	if auditResults == nil {
+		return nil, scanErr
+	}
+	analyzedComponents, unanalyzedComponents := cfp.getScaScansComponents(auditResults)
+	if len(analyzedComponents) == 0 {
+		return nil, scanErr
+	}
+	workingDir := utils.GetRelativeWd(currentWorkingDir, cfp.baseWd)
+	if workingDir == "" {
+		workingDir = "."
+	}
+	message := fmt.Sprintf("The scan of '%s' returned partial results. ", workingDir)
+	if len(unanalyzedComponents) > 0 {
+		message += "The following components weren't analyzed: " + strings.Join(unanalyzedComponents, ", ")
+	} else {
+		message += "Some of its components weren't analyzed"
+	}
+	if cfp.onPartialScan != utils.WarnAndContinuePartialScanPolicy {
+		return nil, fmt.Errorf("%s. Set %s to %s to fix the vulnerabilities of the analyzed components:\n%s", message, utils.OnPartialScanEnv, utils.WarnAndContinuePartialScanPolicy, scanErr.Error())
+	}
+	log.Warn(fmt.Sprintf("%s. Their vulnerabilities are left unfixed:\n%s", message, scanErr.Error()))
+	if cfp.runSummary != nil {
+		cfp.runSummary.AddWarning(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), message)
+	}
+	return auditResults, nil
+}
+
+// Returns the components of the SCA scans, as their technologies and working directories, split by whether Xray returned their results.
+// The scan of a component that Xray returned results for is marked as a single or multiple root project, which isn't set if its scan request failed.
+func (cfp *ScanRepositoryCmd) getScaScansComponents(auditResults *securityutils.Results) (analyzedComponents, unanalyzedComponents []string) {
+	for _, scaResult := range auditResults.ScaResults {
+		target := utils.GetRelativeWd(scaResult.Target, cfp.baseWd)
+		if target == "" {
+			target = "."
+		}
+		component := fmt.Sprintf("%s in '%s'", scaResult.Technology.ToFormal(), target)
+		if scaResult.IsMultipleRootProject == nil {
+			unanalyzedComponents = append(unanalyzedComponents, component)
+			continue
+		}
+		analyzedComponents = append(analyzedComponents, component)
+	}
+	return
+}
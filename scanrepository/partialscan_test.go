@@ -0,0 +1,72 @@
+package scanrepository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlePartialScan(t *testing.T) {
+	baseWd := t.TempDir()
+	// Xray returned the results of npm, while the scan request of pip failed
+	newPartialResults := func() *securityutils.Results {
+		results := securityutils.NewAuditResults()
+		results.ScaResults = []*securityutils.ScaScanResult{
+			{Target: baseWd, Technology: techutils.Npm, IsMultipleRootProject: clientutils.Pointer(false), XrayResults: []services.ScanResponse{{ScanId: "npm-scan"}}},
+			{Target: filepath.Join(baseWd, "services", "api"), Technology: techutils.Pip},
+		}
+		return results
+	}
+	scanErr := errors.New("audit command in 'services/api' failed:\nXray dependency tree scan request on 'pip' failed:\nserver response: 500 Internal Server Error")
+
+	testCases := []struct {
+		name          string
+		policy        utils.PartialScanPolicy
+		expectedError string
+	}{
+		{name: "Default policy", policy: "", expectedError: "The scan of '.' returned partial results. The following components weren't analyzed: Pip in 'services" + string(filepath.Separator) + "api'. Set JF_ON_PARTIAL_SCAN to warn-and-continue"},
+		{name: "Fail", policy: utils.FailPartialScanPolicy, expectedError: "Xray dependency tree scan request on 'pip' failed"},
+		{name: "Warn and continue", policy: utils.WarnAndContinuePartialScanPolicy},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfp := &ScanRepositoryCmd{
+				baseWd:        baseWd,
+				onPartialScan: test.policy,
+				runSummary:    &utils.RunSummary{},
+				scanDetails:   utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}),
+			}
+			partialResults := newPartialResults()
+			results, err := cfp.handlePartialScan(partialResults, scanErr, baseWd)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				assert.Nil(t, results)
+				assert.Empty(t, cfp.runSummary.Warnings)
+				return
+			}
+			// The results of the analyzed components are kept, and the components that weren't analyzed are listed in the run summary
+			require.NoError(t, err)
+			assert.Same(t, partialResults, results)
+			assert.Equal(t, []services.ScanResponse{{ScanId: "npm-scan"}}, results.GetScaScansXrayResults())
+			assert.Equal(t, []string{"jfrog/frogbot: The scan of '.' returned partial results. The following components weren't analyzed: Pip in 'services" + string(filepath.Separator) + "api'"}, cfp.runSummary.Warnings)
+		})
+	}
+
+	// A scan without results for any of its components fails under every policy
+	cfp := &ScanRepositoryCmd{baseWd: baseWd, onPartialScan: utils.WarnAndContinuePartialScanPolicy, runSummary: &utils.RunSummary{}}
+	failedResults := newPartialResults()
+	failedResults.ScaResults = failedResults.ScaResults[1:]
+	_, err := cfp.handlePartialScan(failedResults, scanErr, baseWd)
+	assert.Equal(t, scanErr, err)
+	_, err = cfp.handlePartialScan(nil, scanErr, baseWd)
+	assert.Equal(t, scanErr, err)
+	assert.Empty(t, cfp.runSummary.Warnings)
+}
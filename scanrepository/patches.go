@@ -0,0 +1,39 @@
+package scanrepository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const patchFileExtension = ".patch"
+
+// Writes the changes of the checked out fix branch relative to the base branch to a patch file named by the fix branch,
+// so they can be applied with 'git apply' in environments Frogbot can't push from.
+// The changes are read from the local repository, so the patches are written on dry runs and offline as well.
+func (cfp *ScanRepositoryCmd) emitPatch(fixBranchName string) error {
+	if cfp.patchesDir == "" {
+		return nil
+	}
+	_, _, patch, err := cfp.gitManager.GetChangesFromBranch(cfp.scanDetails.BaseBranch())
+	if err != nil {
+		return fmt.Errorf("couldn't get the changes of the fix branch %s: %w", fixBranchName, err)
+	}
+	if err = os.MkdirAll(cfp.patchesDir, 0755); err != nil {
+		return err
+	}
+	patchPath := filepath.Join(cfp.patchesDir, getPatchFileName(fixBranchName))
+	if err = os.WriteFile(patchPath, []byte(patch), 0644); err != nil {
+		return err
+	}
+	log.Info("The changes of the fix branch", fixBranchName, "were written to", patchPath)
+	return nil
+}
+
+// The fix branches of Go modules may contain slashes, which are replaced so the patch files are written to the patches directory itself
+func getPatchFileName(fixBranchName string) string {
+	return strings.ReplaceAll(fixBranchName, "/", "-") + patchFileExtension
+}
@@ -0,0 +1,90 @@
+package scanrepository
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitPatches(t *testing.T) {
+	// A local repository with a fix branch that upgrades minimist in the descriptor, and adds its lockfile
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}
+	descriptor := "{\n  \"name\": \"example\",\n  \"dependencies\": {\n    \"minimist\": \"1.2.5\"\n  }\n}\n"
+	fixedDescriptor := strings.Replace(descriptor, "1.2.5", "1.2.6", 1)
+	lockfile := "{\n  \"lockfileVersion\": 3,\n  \"packages\": {\n    \"node_modules/minimist\": {\n      \"version\": \"1.2.6\"\n    }\n  }\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(descriptor), 0600))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	fixBranchName := "frogbot-npm-minimist-1.2.6"
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(fixBranchName), Create: true}))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(fixedDescriptor), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package-lock.json"), []byte(lockfile), 0600))
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Upgrade minimist to 1.2.6", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+
+	patchesDir := filepath.Join(t.TempDir(), "patches")
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      gitManager,
+		scanDetails:     utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}).SetBaseBranch("master"),
+		dryRun:          true,
+		dryRunOutput:    &strings.Builder{},
+		pullRequestSink: &discardPullRequestSink{},
+		patchesDir:      patchesDir,
+	}
+	vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+			SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+			ImpactedDependencyName:    "minimist",
+			ImpactedDependencyVersion: "1.2.5",
+		},
+	}, "1.2.6")
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, vulnDetails))
+
+	// The patch is named by the fix branch
+	patchPath := filepath.Join(patchesDir, fixBranchName+".patch")
+	require.FileExists(t, patchPath)
+
+	// The patch applies cleanly on the base branch, and reproduces the descriptor and lockfile of the fix branch
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}))
+	output, err := exec.Command("git", "-C", repoDir, "apply", patchPath).CombinedOutput()
+	require.NoError(t, err, string(output))
+	content, err := os.ReadFile(filepath.Join(repoDir, "package.json"))
+	require.NoError(t, err)
+	assert.Equal(t, fixedDescriptor, string(content))
+	content, err = os.ReadFile(filepath.Join(repoDir, "package-lock.json"))
+	require.NoError(t, err)
+	assert.Equal(t, lockfile, string(content))
+
+	// The slashes of the fix branches of Go modules aren't part of the patch file path
+	assert.Equal(t, "frogbot-go-golang.org-x-crypto-0.17.0.patch", getPatchFileName("frogbot-go-golang.org/x/crypto-0.17.0"))
+}
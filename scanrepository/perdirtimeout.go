@@ -0,0 +1,91 @@
+package scanrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Returns the command that audits a working directory in a process of its own, replaceable for testing purposes
+var getWorkingDirAuditCommand = func() (name string, args []string, err error) {
+	name, err = os.Executable()
+	return name, []string{utils.AuditWorkingDir}, err
+}
+
+// Runs the resolve command and the scan of the working directory. If the scan exceeds the per directory timeout, timedOut is returned,
+// the vulnerabilities of the working directory aren't fixed and the run fails once the other working directories are handled.
+// The audit changes the working directory of the process, so it can't be interrupted within the process. When a timeout is set,
+// it runs in a process of its own instead, which is killed along with the resolve command once the timeout is exceeded.
+func (cfp *ScanRepositoryCmd) scanWorkingDir(fullPathWd string) (scanResults *securityutils.Results, timedOut bool, err error) {
+	perDirTimeout := cfp.scanDetails.GetPerDirTimeout()
+	if perDirTimeout <= 0 {
+		if err = cfp.runResolveCommand(context.Background(), fullPathWd); err != nil {
+			return
+		}
+		scanResults, err = cfp.scan(fullPathWd)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), perDirTimeout)
+	defer cancel()
+	auditResults, auditErr := cfp.resolveAndAuditInProcess(ctx, fullPathWd)
+	// The killed resolve command or audit fails, so a failure after the timeout is reported as a timeout
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		scanResults, err = cfp.processAuditResults(auditResults, auditErr, fullPathWd)
+		return
+	}
+	workingDir := utils.GetRelativeWd(fullPathWd, cfp.baseWd)
+	if workingDir == "" {
+		workingDir = "."
+	}
+	message := fmt.Sprintf("scan timed out for dir %s after %s, so its vulnerabilities weren't fixed", workingDir, perDirTimeout)
+	log.Warn(message)
+	cfp.runSummary.AddWarning(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), message)
+	cfp.timedOutDirs = append(cfp.timedOutDirs, workingDir)
+	return nil, true, nil
+}
+
+// Runs the resolve command of the working directory and audits it in a process of its own. Both are killed once the context is done.
+func (cfp *ScanRepositoryCmd) resolveAndAuditInProcess(ctx context.Context, fullPathWd string) (results *securityutils.Results, err error) {
+	if err = cfp.runResolveCommand(ctx, fullPathWd); err != nil {
+		return
+	}
+	tempDir, err := fileutils.CreateTempDir()
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, fileutils.RemoveTempDir(tempDir))
+	}()
+	auditFile, resultsFile := filepath.Join(tempDir, "audit.json"), filepath.Join(tempDir, "results.json")
+	if err = utils.WriteWorkingDirAudit(auditFile, cfp.scanDetails.NewWorkingDirAudit(fullPathWd, cfp.inputSbom)); err != nil {
+		return
+	}
+	name, args, err := getWorkingDirAuditCommand()
+	if err != nil {
+		return
+	}
+	cmd := exec.CommandContext(ctx, name, append(args, auditFile, resultsFile)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.WaitDelay = killedCommandWaitDelay
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("the audit of '%s' failed: %s", fullPathWd, err.Error())
+	}
+	return utils.ReadWorkingDirAuditResults(resultsFile)
+}
+
+// Returns the error that fails the run if the scans of any working directories timed out, as their vulnerabilities weren't fixed
+func (cfp *ScanRepositoryCmd) getPerDirTimeoutError() error {
+	if len(cfp.timedOutDirs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the scans of the following working directories timed out, so their vulnerabilities weren't fixed: %s", strings.Join(cfp.timedOutDirs, ", "))
+}
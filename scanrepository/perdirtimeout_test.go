@@ -0,0 +1,80 @@
+package scanrepository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsutils"
+	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/xsc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+)
+
+// Runs in place of the process that audits a working directory. The audit of the hanging working directory never completes.
+func TestWorkingDirAuditHelperProcess(t *testing.T) {
+	auditedDirsFile := os.Getenv("FROGBOT_TEST_AUDITED_DIRS_FILE")
+	if auditedDirsFile == "" {
+		return
+	}
+	auditFile, resultsFile := os.Args[len(os.Args)-2], os.Args[len(os.Args)-1]
+	workingDirAudit, err := utils.ReadWorkingDirAudit(auditFile)
+	if err != nil {
+		os.Exit(1)
+	}
+	auditedDirs, _ := os.ReadFile(auditedDirsFile)
+	_ = os.WriteFile(auditedDirsFile, append(auditedDirs, filepath.Base(workingDirAudit.WorkingDir)+"\n"...), 0644)
+	if filepath.Base(workingDirAudit.WorkingDir) == "hanging" {
+		time.Sleep(time.Hour)
+	}
+	if err = utils.WriteWorkingDirAuditResults(resultsFile, securityutils.NewAuditResults(), nil); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestScanProjectWithPerDirTimeout(t *testing.T) {
+	baseWd := t.TempDir()
+	for _, workingDir := range []string{"fast", "hanging", "other"} {
+		require.NoError(t, os.Mkdir(filepath.Join(baseWd, workingDir), 0755))
+	}
+	auditedDirsFile := filepath.Join(t.TempDir(), "audited-dirs")
+	t.Setenv("FROGBOT_TEST_AUDITED_DIRS_FILE", auditedDirsFile)
+	defaultGetWorkingDirAuditCommand := getWorkingDirAuditCommand
+	getWorkingDirAuditCommand = func() (string, []string, error) {
+		return os.Args[0], []string{"-test.run=TestWorkingDirAuditHelperProcess", "--"}, nil
+	}
+	defer func() {
+		getWorkingDirAuditCommand = defaultGetWorkingDirAuditCommand
+	}()
+	cfp := &ScanRepositoryCmd{
+		scanDetails:  utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}),
+		baseWd:       baseWd,
+		OutputWriter: &outputwriter.StandardOutput{},
+		runSummary:   &utils.RunSummary{},
+		// Events aren't reported by an analytics service without a server
+		analyticsService: &xsc.AnalyticsMetricsService{},
+	}
+	cfp.scanDetails.Project = &utils.Project{WorkingDirs: []string{"fast", "hanging", "other"}, PerDirTimeout: "3s"}
+
+	vulnerabilitiesByPathMap := make(map[string]map[string]*utils.VulnerabilityDetails)
+	start := time.Now()
+	repository := &utils.Repository{Params: utils.Params{Git: utils.Git{GitProvider: vcsutils.GitLab}}}
+	_, err := cfp.scanProject(repository, vulnerabilitiesByPathMap)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 30*time.Second)
+
+	// The audit of the hanging working directory is killed, and the working directories after it are scanned while it would still hang
+	auditedDirs, err := os.ReadFile(auditedDirsFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fast", "hanging", "other"}, strings.Fields(string(auditedDirs)))
+	assert.ElementsMatch(t, []string{filepath.Join(baseWd, "fast"), filepath.Join(baseWd, "other")}, maps.Keys(vulnerabilitiesByPathMap))
+	assert.Equal(t, []string{"jfrog/frogbot: scan timed out for dir hanging after 3s, so its vulnerabilities weren't fixed"}, cfp.runSummary.Warnings)
+	assert.EqualError(t, cfp.getPerDirTimeoutError(), "the scans of the following working directories timed out, so their vulnerabilities weren't fixed: hanging")
+}
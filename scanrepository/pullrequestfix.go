@@ -0,0 +1,203 @@
+package scanrepository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+// The suffixes of the files in which dependencies are declared
+var dependencyDescriptorsSuffixes = []string{
+	"package.json", "go.mod", "pom.xml", "build.gradle", "build.gradle.kts", "libs.versions.toml",
+	"requirements.txt", "setup.py", "Pipfile", "pyproject.toml", ".csproj", "packages.config",
+}
+
+// In a pom.xml, the version of a dependency is declared on its own line below the dependency name.
+// The lines preceding a changed line are therefore considered changed as well, so a bumped version is attributed to its dependency.
+const pomChangedLineContext = 2
+
+// Scans the source branch of the given open pull request, and fixes only the vulnerable dependencies the pull request adds or bumps.
+// The fixes are committed to the source branch of the pull request, instead of being opened as new pull requests.
+func (cfp *ScanRepositoryCmd) scanAndFixPullRequest(repository *utils.Repository, client vcsclient.VcsClient) (err error) {
+	pullRequestId := int(repository.PullRequestDetails.ID)
+	pullRequestInfo, err := client.GetPullRequestByID(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, pullRequestId)
+	if err != nil {
+		return
+	}
+	// Frogbot's token can't push to a fork, and the code of a fork mustn't be installed with the credentials of the repository
+	if !strings.EqualFold(pullRequestInfo.Source.Owner, pullRequestInfo.Target.Owner) || !strings.EqualFold(pullRequestInfo.Source.Repository, pullRequestInfo.Target.Repository) {
+		return fmt.Errorf("pull request #%d was opened from a fork, and Frogbot can't commit fixes to its source branch", pullRequestId)
+	}
+	if cfp.scanDetails.PushRemoteUrl != "" {
+		return fmt.Errorf("fixes of pull request #%d are committed to its source branch, which can't be combined with a push remote", pullRequestId)
+	}
+	if cfp.pullRequestChangedLines, err = getPullRequestDescriptorsChangedLines(client, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, pullRequestInfo); err != nil {
+		return
+	}
+	if len(cfp.pullRequestChangedLines) == 0 {
+		log.Info(fmt.Sprintf("Pull request #%d doesn't change the dependencies of the repository", pullRequestId))
+		return
+	}
+	cfp.fixedPullRequest = &pullRequestInfo
+	cfp.scanDetails.SetBaseBranch(pullRequestInfo.Source.Name)
+	cfp.scanDetails.SetXscGitInfoContext(pullRequestInfo.Source.Name, repository.Project, client)
+	return cfp.scanAndFixBranch(repository)
+}
+
+// Returns the lines the pull request adds to the dependency descriptors it modifies.
+// The dependencies declared on these lines are the dependencies the pull request adds or bumps.
+func getPullRequestDescriptorsChangedLines(client vcsclient.VcsClient, owner, repo string, pullRequestInfo vcsclient.PullRequestInfo) (changedLines []string, err error) {
+	modifiedFiles, err := client.GetModifiedFiles(context.Background(), owner, repo, pullRequestInfo.Target.Name, pullRequestInfo.Source.Name)
+	if err != nil {
+		return
+	}
+	for _, modifiedFile := range modifiedFiles {
+		if !isDependencyDescriptor(modifiedFile) {
+			continue
+		}
+		var sourceContent, targetContent []byte
+		if sourceContent, err = downloadFileIfExists(client, owner, repo, pullRequestInfo.Source.Name, modifiedFile); err != nil {
+			return
+		}
+		if targetContent, err = downloadFileIfExists(client, owner, repo, pullRequestInfo.Target.Name, modifiedFile); err != nil {
+			return
+		}
+		contextLines := 0
+		if path.Base(modifiedFile) == "pom.xml" {
+			contextLines = pomChangedLineContext
+		}
+		changedLines = append(changedLines, getAddedLines(targetContent, sourceContent, contextLines)...)
+	}
+	return
+}
+
+func isDependencyDescriptor(filePath string) bool {
+	return slices.ContainsFunc(dependencyDescriptorsSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(filePath, suffix)
+	})
+}
+
+// Downloads a file from the given branch, returning empty content if the file doesn't exist in the branch
+func downloadFileIfExists(client vcsclient.VcsClient, owner, repo, branch, filePath string) ([]byte, error) {
+	content, statusCode, err := client.DownloadFileFromRepo(context.Background(), owner, repo, branch, filePath)
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download '%s' from branch '%s': %s", filePath, branch, err.Error())
+	}
+	return content, nil
+}
+
+// Returns the lines of newContent that don't appear in oldContent, each along with the given number of lines preceding it
+func getAddedLines(oldContent, newContent []byte, contextLines int) (addedLines []string) {
+	oldLines := splitLines(oldContent)
+	newLines := splitLines(newContent)
+	for i, line := range newLines {
+		if line == "" || slices.Contains(oldLines, line) {
+			continue
+		}
+		addedLines = append(addedLines, newLines[max(0, i-contextLines):i+1]...)
+	}
+	return
+}
+
+// Splits the content into its trimmed lines
+func splitLines(content []byte) (lines []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// A line that only gained a trailing comma, as another declaration was added after it, isn't considered changed
+		lines = append(lines, strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ","))
+	}
+	return
+}
+
+// Removes the vulnerabilities of dependencies that weren't added or bumped by the fixed pull request.
+// A vulnerability is kept if its dependency, or the direct dependency that brings it, is declared on one of the lines the pull request changed.
+// Returns true if any vulnerability is left to fix.
+func (cfp *ScanRepositoryCmd) excludeDependenciesUnchangedByPullRequest(vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) (fixNeeded bool) {
+	for _, vulnerabilities := range vulnerabilitiesByPathMap {
+		for packageName, vulnDetails := range vulnerabilities {
+			if !cfp.isChangedByPullRequest(vulnDetails) {
+				log.Debug(fmt.Sprintf("Skipping '%s:%s' as it wasn't added or bumped by pull request #%d", vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion, cfp.fixedPullRequest.ID))
+				delete(vulnerabilities, packageName)
+			}
+		}
+		if len(vulnerabilities) > 0 {
+			fixNeeded = true
+		}
+	}
+	return
+}
+
+func (cfp *ScanRepositoryCmd) isChangedByPullRequest(vulnDetails *utils.VulnerabilityDetails) bool {
+	dependencies := []string{vulnDetails.ImpactedDependencyName}
+	for _, directDependency := range vulnDetails.Components {
+		dependencies = append(dependencies, directDependency.Name)
+	}
+	for _, dependency := range dependencies {
+		// Maven and Gradle dependencies are named as group:artifact, while their declarations may split the name
+		if index := strings.LastIndex(dependency, ":"); index >= 0 {
+			dependency = dependency[index+1:]
+		}
+		if dependency == "" {
+			continue
+		}
+		dependencyRegex := regexp.MustCompile(`(?i)(^|[^\w.\-/@])` + regexp.QuoteMeta(dependency) + `($|[^\w.\-/@])`)
+		if slices.ContainsFunc(cfp.pullRequestChangedLines, dependencyRegex.MatchString) {
+			return true
+		}
+	}
+	return false
+}
+
+// Commits the fixes of the dependencies introduced by the fixed pull request to its source branch, and comments on the pull request with the fixed vulnerabilities.
+func (cfp *ScanRepositoryCmd) fixPullRequestSourceBranch(vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails) (err error) {
+	log.Info(fmt.Sprintf("Fixing the dependencies introduced by pull request #%d in its source branch '%s'", cfp.fixedPullRequest.ID, cfp.fixedPullRequest.Source.Name))
+	var fixedVulnerabilities []*utils.VulnerabilityDetails
+	for fullPath, vulnerabilities := range vulnerabilitiesMap {
+		currentFixes, e := cfp.fixMultiplePackages(fullPath, vulnerabilities)
+		if e != nil {
+			err = errors.Join(err, fmt.Errorf("the following errors occured while fixing vulnerabilities in %s:\n%s", fullPath, e))
+		}
+		fixedVulnerabilities = append(fixedVulnerabilities, currentFixes...)
+	}
+	isClean, e := cfp.gitManager.IsClean()
+	if e != nil || isClean || len(fixedVulnerabilities) == 0 {
+		if e == nil {
+			log.Info("There were no changes to commit after fixing the dependencies introduced by the pull request")
+		}
+		return errors.Join(err, e)
+	}
+	if e = cfp.writeFixManifestIfNeeded(fixedVulnerabilities...); e != nil {
+		return errors.Join(err, e)
+	}
+	if e = cfp.gitManager.AddAllAndCommit(cfp.gitManager.GenerateAggregatedCommitMessage(cfp.projectTech)); e != nil {
+		return errors.Join(err, e)
+	}
+	if e = cfp.gitManager.Push(false, cfp.fixedPullRequest.Source.Name); e != nil {
+		return errors.Join(err, e)
+	}
+	comment, _ := utils.GenerateFixPullRequestDetails(fixedVulnerabilities, cfp.OutputWriter)
+	if cfp.dryRun {
+		if e = cfp.renderDryRunPullRequest(cfp.fixedPullRequest.Source.Name, fmt.Sprintf("Comment on pull request #%d", cfp.fixedPullRequest.ID), comment, nil, false); e != nil {
+			return errors.Join(err, e)
+		}
+	}
+	if e = cfp.scanDetails.Client().AddPullRequestComment(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, comment, int(cfp.fixedPullRequest.ID)); e != nil {
+		err = errors.Join(err, errors.New("couldn't add pull request comment: "+e.Error()))
+	}
+	cfp.addPullRequestToRunSummary(fmt.Sprintf("Fixes committed to pull request #%d", cfp.fixedPullRequest.ID), cfp.fixedPullRequest.URL, true)
+	return
+}
@@ -0,0 +1,106 @@
+package scanrepository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+)
+
+// A client of a repository with an open pull request, in which the files are read from a map of branch -> path -> content
+type pullRequestFilesClient struct {
+	vcsclient.VcsClient
+	modifiedFiles []string
+	files         map[string]map[string]string
+	pullRequest   vcsclient.PullRequestInfo
+}
+
+func (c *pullRequestFilesClient) GetPullRequestByID(_ context.Context, _, _ string, _ int) (vcsclient.PullRequestInfo, error) {
+	return c.pullRequest, nil
+}
+
+func (c *pullRequestFilesClient) GetModifiedFiles(_ context.Context, _, _, _, _ string) ([]string, error) {
+	return c.modifiedFiles, nil
+}
+
+func (c *pullRequestFilesClient) DownloadFileFromRepo(_ context.Context, _, _, branch, path string) ([]byte, int, error) {
+	content, exists := c.files[branch][path]
+	if !exists {
+		return nil, http.StatusNotFound, assert.AnError
+	}
+	return []byte(content), http.StatusOK, nil
+}
+
+func TestExcludeDependenciesUnchangedByPullRequest(t *testing.T) {
+	client := &pullRequestFilesClient{
+		modifiedFiles: []string{"package.json", "src/index.js"},
+		files: map[string]map[string]string{
+			"main": {
+				"package.json": "{\n  \"dependencies\": {\n    \"lodash\": \"4.17.20\"\n  }\n}\n",
+			},
+			"add-minimist": {
+				"package.json": "{\n  \"dependencies\": {\n    \"lodash\": \"4.17.20\",\n    \"minimist\": \"1.2.5\"\n  }\n}\n",
+				"src/index.js": "require('uuid')\n",
+			},
+		},
+	}
+	pullRequestInfo := vcsclient.PullRequestInfo{
+		ID:     7,
+		Source: vcsclient.BranchInfo{Name: "add-minimist", Owner: "jfrog", Repository: "repo"},
+		Target: vcsclient.BranchInfo{Name: "main", Owner: "jfrog", Repository: "repo"},
+	}
+	changedLines, err := getPullRequestDescriptorsChangedLines(client, "jfrog", "repo", pullRequestInfo)
+	require.NoError(t, err)
+	// Only the dependency descriptors are compared
+	assert.Equal(t, []string{`"minimist": "1.2.5"`}, changedLines)
+
+	newVulnerability := func(name, version string, directDependencies ...string) *utils.VulnerabilityDetails {
+		row := formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: name, ImpactedDependencyVersion: version}}
+		for _, directDependency := range directDependencies {
+			row.Components = append(row.Components, formats.ComponentRow{Name: directDependency})
+		}
+		return utils.NewVulnerabilityDetails(row, "")
+	}
+	cfp := ScanRepositoryCmd{fixedPullRequest: &pullRequestInfo, pullRequestChangedLines: changedLines}
+	vulnerabilitiesByPathMap := map[string]map[string]*utils.VulnerabilityDetails{
+		"/repo": {
+			// Added by the pull request
+			"minimist": newVulnerability("minimist", "1.2.5", "minimist"),
+			// Brought by another dependency, whose declaration wasn't changed
+			"semver": newVulnerability("semver", "5.7.1", "nodemon"),
+			// Its name is a part of the name of a changed dependency only
+			"minim": newVulnerability("minim", "0.1.0", "minim"),
+		},
+		"/repo/other": {
+			"uuid": newVulnerability("uuid", "3.0.0", "uuid"),
+		},
+	}
+	assert.True(t, cfp.excludeDependenciesUnchangedByPullRequest(vulnerabilitiesByPathMap))
+	assert.Equal(t, []string{"minimist"}, maps.Keys(vulnerabilitiesByPathMap["/repo"]))
+	assert.Empty(t, vulnerabilitiesByPathMap["/repo/other"])
+}
+
+func TestScanAndFixPullRequestFromFork(t *testing.T) {
+	client := &pullRequestFilesClient{pullRequest: vcsclient.PullRequestInfo{
+		ID:     7,
+		Source: vcsclient.BranchInfo{Name: "add-minimist", Owner: "contributor", Repository: "repo"},
+		Target: vcsclient.BranchInfo{Name: "main", Owner: "jfrog", Repository: "repo"},
+	}}
+	cfp := &ScanRepositoryCmd{scanDetails: utils.NewScanDetails(client, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "repo"})}
+	repository := &utils.Repository{Params: utils.Params{Git: utils.Git{FixPullRequestBranch: true, PullRequestDetails: vcsclient.PullRequestInfo{ID: 7}}}}
+	assert.EqualError(t, cfp.scanAndFixPullRequest(repository, client), "pull request #7 was opened from a fork, and Frogbot can't commit fixes to its source branch")
+	assert.Nil(t, cfp.fixedPullRequest)
+}
+
+func TestGetAddedLinesOfPom(t *testing.T) {
+	oldPom := []byte("<dependency>\n  <groupId>org.yaml</groupId>\n  <artifactId>snakeyaml</artifactId>\n  <version>1.30</version>\n</dependency>\n")
+	newPom := []byte("<dependency>\n  <groupId>org.yaml</groupId>\n  <artifactId>snakeyaml</artifactId>\n  <version>1.32</version>\n</dependency>\n")
+	// A bumped version is attributed to the dependency declared above it
+	assert.Equal(t, []string{"<groupId>org.yaml</groupId>", "<artifactId>snakeyaml</artifactId>", "<version>1.32</version>"}, getAddedLines(oldPom, newPom, pomChangedLineContext))
+}
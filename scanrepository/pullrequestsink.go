@@ -0,0 +1,103 @@
+package scanrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// PullRequestSink carries out the operations that create or update the fix pull requests.
+type PullRequestSink interface {
+	// Publish carries out the operation, and returns the created or updated pull request if it's known.
+	// existingPullRequest is the pull request to update, or nil if a new pull request is created.
+	Publish(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error)
+}
+
+// Pushes the fix branches and opens the pull requests on the Git provider
+type vcsPullRequestSink struct {
+	cfp *ScanRepositoryCmd
+	// The client the pull requests are opened with, or the client of the scan details if nil
+	client vcsclient.VcsClient
+}
+
+func (vps *vcsPullRequestSink) Publish(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error) {
+	if err := vps.push(operation); err != nil {
+		return nil, err
+	}
+	return vps.openPullRequest(repository, operation, existingPullRequest)
+}
+
+func (vps *vcsPullRequestSink) push(operation *utils.PullRequestOperation) error {
+	return vps.cfp.gitManager.Push(operation.ForcePush, operation.SourceBranch)
+}
+
+// Creates or updates the pull request of the pushed fix branch, and adds its extra comments
+func (vps *vcsPullRequestSink) openPullRequest(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo) (pullRequestInfo *vcsclient.PullRequestInfo, err error) {
+	cfp := vps.cfp
+	client := vps.client
+	if client == nil {
+		client = cfp.scanDetails.Client()
+	}
+	if pullRequestInfo, err = cfp.createOrUpdatePullRequest(client, repository, existingPullRequest, operation.SourceBranch, operation.Title, operation.Body, operation.Draft); err != nil {
+		return
+	}
+	for _, comment := range operation.Comments {
+		if err = client.AddPullRequestComment(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, comment, int(pullRequestInfo.ID)); err != nil {
+			err = errors.New("couldn't add pull request comment: " + err.Error())
+			return
+		}
+	}
+	vps.requestReviewers(operation, pullRequestInfo)
+	return
+}
+
+// Requests the reviews of the reviewers of the fixed packages.
+// Failing to request them doesn't fail the pull request, as the default reviewers of the repository are still assigned.
+func (vps *vcsPullRequestSink) requestReviewers(operation *utils.PullRequestOperation, pullRequestInfo *vcsclient.PullRequestInfo) {
+	if len(operation.Reviewers) == 0 || pullRequestInfo == nil {
+		return
+	}
+	scanDetails := vps.cfp.scanDetails
+	if scanDetails.GitProvider != vcsutils.GitHub {
+		log.Warn("Requesting the reviewers of the fixed packages is not supported on", scanDetails.GitProvider.String())
+		return
+	}
+	log.Info(fmt.Sprintf("Requesting the reviews of %s on pull request #%d", strings.Join(operation.Reviewers, ", "), pullRequestInfo.ID))
+	waitForRateLimit(vps.client)
+	if err := utils.RequestGitHubPullRequestReviewers(scanDetails.APIEndpoint, scanDetails.Token, scanDetails.RepoOwner, scanDetails.RepoName, pullRequestInfo.ID, operation.Reviewers); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
+// Writes the pull request operations to a file, for environments in which Frogbot can't push to the Git provider.
+// A trusted agent consumes the file, applies the changes of each fix branch and creates or updates its pull request.
+type filePullRequestSink struct {
+	cfp      *ScanRepositoryCmd
+	filePath string
+}
+
+func (fps *filePullRequestSink) Publish(_ *utils.Repository, operation *utils.PullRequestOperation, _ *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error) {
+	var err error
+	// The fix branch isn't pushed, so its changes are part of the operation
+	if operation.BaseCommit, operation.CommitMessage, operation.Patch, err = fps.cfp.gitManager.GetChangesFromBranch(fps.cfp.scanDetails.BaseBranch()); err != nil {
+		return nil, err
+	}
+	if err = utils.AppendPullRequestOperation(fps.filePath, operation); err != nil {
+		return nil, err
+	}
+	log.Info("The pull request from", operation.SourceBranch, "to", operation.TargetBranch, "was written to", fps.filePath)
+	return nil, nil
+}
+
+func (cfp *ScanRepositoryCmd) getPullRequestSink() PullRequestSink {
+	if cfp.pullRequestSink == nil {
+		return &vcsPullRequestSink{cfp: cfp}
+	}
+	return cfp.pullRequestSink
+}
@@ -0,0 +1,324 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePullRequestSink(t *testing.T) {
+	// A local repository with a fix branch that upgrades minimist
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{\"dependencies\": {\"minimist\": \"1.2.5\"}}\n"), 0600))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	baseCommit, err := worktree.Commit("initial commit", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	fixBranchName := "frogbot-update-npm-dependencies-master"
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(fixBranchName), Create: true}))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{\"dependencies\": {\"minimist\": \"1.2.6\"}}\n"), 0600))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Upgrade minimist to 1.2.6", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+
+	sinkFilePath := filepath.Join(t.TempDir(), "pull-requests.jsonl")
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:   &outputwriter.StandardOutput{},
+		gitManager:     gitManager,
+		scanDetails:    utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}).SetBaseBranch("master"),
+		aggregateFixes: true,
+	}
+	cfp.pullRequestSink = &filePullRequestSink{cfp: cfp, filePath: sinkFilePath}
+	vulnDetails := &utils.VulnerabilityDetails{
+		VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+				ImpactedDependencyName:    "minimist",
+				ImpactedDependencyVersion: "1.2.5",
+			},
+			Cves: []formats.CveRow{{Id: "CVE-2021-44906"}},
+		},
+		SuggestedFixedVersion: "1.2.6",
+	}
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, true, vulnDetails))
+
+	content, err := os.ReadFile(sinkFilePath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 1)
+	var operation utils.PullRequestOperation
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &operation))
+	assert.Equal(t, utils.CreatePullRequestAction, operation.Action)
+	assert.Equal(t, "jfrog", operation.RepoOwner)
+	assert.Equal(t, "frogbot", operation.RepoName)
+	assert.Equal(t, fixBranchName, operation.SourceBranch)
+	assert.Equal(t, "master", operation.TargetBranch)
+	assert.True(t, operation.ForcePush)
+	assert.Equal(t, gitManager.GenerateAggregatedPullRequestTitle(nil), operation.Title)
+	assert.Equal(t, baseCommit.String(), operation.BaseCommit)
+	assert.Equal(t, "Upgrade minimist to 1.2.6", operation.CommitMessage)
+	assert.Contains(t, operation.Patch, "-{\"dependencies\": {\"minimist\": \"1.2.5\"}}")
+	assert.Contains(t, operation.Patch, "+{\"dependencies\": {\"minimist\": \"1.2.6\"}}")
+	assert.Contains(t, operation.Body, "minimist")
+	require.NotEmpty(t, operation.Checksum)
+	assert.Contains(t, operation.Body, "Checksum: "+operation.Checksum)
+	assert.Zero(t, operation.PullRequestId)
+}
+
+// Opens the pull requests on the Git provider, without pushing the fix branches
+type openOnlyPullRequestSink struct {
+	cfp        *ScanRepositoryCmd
+	operations []*utils.PullRequestOperation
+}
+
+func (ops *openOnlyPullRequestSink) Publish(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error) {
+	ops.operations = append(ops.operations, operation)
+	return ops.cfp.createOrUpdatePullRequest(ops.cfp.scanDetails.Client(), repository, existingPullRequest, operation.SourceBranch, operation.Title, operation.Body, operation.Draft)
+}
+
+func TestDraftPullRequestOnBreakingChanges(t *testing.T) {
+	fixBranchName := "frogbot-npm-minimist-1.2.6"
+	var createdPullRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&createdPullRequest))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+			_, err := w.Write([]byte(fmt.Sprintf(`[{"number": 7, "html_url": "https://github.com/jfrog/frogbot/pull/7", "head": {"ref": "%[1]s", "label": "jfrog:%[1]s", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}, "base": {"ref": "master", "label": "jfrog:master", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}}]`, fixBranchName)))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "unexpected request", "%s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	gitParams := &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot", VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}}
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      utils.NewGitManager(),
+		scanDetails:     utils.NewScanDetails(client, nil, gitParams).SetBaseBranch("master"),
+		draftOnBreaking: true,
+	}
+	sink := &openOnlyPullRequestSink{cfp: cfp}
+	cfp.pullRequestSink = sink
+	vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+			SeverityDetails:           formats.SeverityDetails{Severity: "Critical", SeverityNumValue: 17},
+			ImpactedDependencyName:    "minimist",
+			ImpactedDependencyVersion: "0.2.4",
+		},
+		Cves:                     []formats.CveRow{{Id: "CVE-2021-44906"}},
+		JfrogResearchInformation: &formats.JfrogResearchInformation{Remediation: "Upgrade minimist to 1.2.6. Version 1.0.0 has breaking changes in the parsing of numbers."},
+	}, "1.2.6")
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, vulnDetails))
+
+	// The fix is flagged as breaking, so the pull request is opened as a draft with a warning
+	require.Len(t, sink.operations, 1)
+	assert.True(t, sink.operations[0].Draft)
+	assert.Contains(t, sink.operations[0].Body, "⚠️ **Breaking changes:** Updating minimist to 1.2.6 may introduce breaking changes.")
+	require.NotNil(t, createdPullRequest)
+	assert.Equal(t, true, createdPullRequest["draft"])
+	assert.Equal(t, fixBranchName, createdPullRequest["head"])
+	assert.Equal(t, "master", createdPullRequest["base"])
+	assert.Contains(t, createdPullRequest["body"], "- Version 1.0.0 has breaking changes in the parsing of numbers.")
+	require.Len(t, cfp.branchPullRequests, 1)
+	assert.Equal(t, "https://github.com/jfrog/frogbot/pull/7", cfp.branchPullRequests[0].Url)
+
+	// Without JF_DRAFT_ON_BREAKING, the pull request is opened as usual, and only the warning is added
+	cfp.draftOnBreaking = false
+	sink.operations = nil
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, vulnDetails))
+	require.Len(t, sink.operations, 1)
+	assert.False(t, sink.operations[0].Draft)
+	assert.Contains(t, sink.operations[0].Body, "⚠️ **Breaking changes:**")
+}
+
+// Publishes nothing, as the dry run result is asserted on instead
+type discardPullRequestSink struct{}
+
+func (dps *discardPullRequestSink) Publish(*utils.Repository, *utils.PullRequestOperation, *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error) {
+	return nil, nil
+}
+
+func TestDryRunResult(t *testing.T) {
+	// A local repository with a fix branch that upgrades minimist
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{\"dependencies\": {\"minimist\": \"1.2.5\"}}\n"), 0600))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	fixBranchName := "frogbot-npm-minimist-1.2.6"
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(fixBranchName), Create: true}))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{\"dependencies\": {\"minimist\": \"1.2.6\"}}\n"), 0600))
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Upgrade minimist to 1.2.6", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      gitManager,
+		scanDetails:     utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}).SetBaseBranch("master"),
+		dryRun:          true,
+		dryRunOutput:    &strings.Builder{},
+		pullRequestSink: &discardPullRequestSink{},
+	}
+	vulnDetails := &utils.VulnerabilityDetails{
+		VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+				ImpactedDependencyName:    "minimist",
+				ImpactedDependencyVersion: "1.2.5",
+			},
+			Cves: []formats.CveRow{{Id: "CVE-2021-44906"}},
+		},
+		SuggestedFixedVersion: "1.2.6",
+	}
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, vulnDetails))
+
+	require.NotNil(t, cfp.DryRunResult())
+	require.Len(t, cfp.DryRunResult().PullRequests, 1)
+	pullRequest := cfp.DryRunResult().GetPullRequest(fixBranchName)
+	require.NotNil(t, pullRequest)
+	assert.Equal(t, utils.CreatePullRequestAction, pullRequest.Action)
+	assert.Equal(t, "jfrog", pullRequest.RepoOwner)
+	assert.Equal(t, "frogbot", pullRequest.RepoName)
+	assert.Equal(t, "master", pullRequest.TargetBranch)
+	assert.Equal(t, gitManager.GenerateFixPullRequestTitle(vulnDetails), pullRequest.Title)
+	assert.Contains(t, pullRequest.Body, "minimist")
+	assert.False(t, pullRequest.Draft)
+	assert.Equal(t, []outputwriter.PackageStatusRow{{PackageName: "minimist", CurrentVersion: "1.2.5", TargetVersion: "1.2.6", Severity: "High"}}, pullRequest.Packages)
+	assert.Contains(t, pullRequest.Patch, "+{\"dependencies\": {\"minimist\": \"1.2.6\"}}")
+	assert.Nil(t, cfp.DryRunResult().GetPullRequest("frogbot-npm-lodash-4.17.21"))
+}
+
+// Opens the pull requests on the Git provider and requests their reviewers, without pushing the fix branches
+type requestReviewersPullRequestSink struct {
+	cfp        *ScanRepositoryCmd
+	operations []*utils.PullRequestOperation
+}
+
+func (rps *requestReviewersPullRequestSink) Publish(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo) (*vcsclient.PullRequestInfo, error) {
+	rps.operations = append(rps.operations, operation)
+	return (&vcsPullRequestSink{cfp: rps.cfp}).openPullRequest(repository, operation, existingPullRequest)
+}
+
+func TestPackageReviewers(t *testing.T) {
+	fixBranchName := "frogbot-go-golang.org/x/crypto-0.17.0"
+	var requestedReviewers []map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte(`{"number": 7}`))
+			assert.NoError(t, err)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+			_, err := w.Write([]byte(fmt.Sprintf(`[{"number": 7, "html_url": "https://github.com/jfrog/frogbot/pull/7", "head": {"ref": "%[1]s", "label": "jfrog:%[1]s", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}, "base": {"ref": "master", "label": "jfrog:master", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}}]`, fixBranchName)))
+			assert.NoError(t, err)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/frogbot/pulls/7/requested_reviewers":
+			var request map[string][]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			requestedReviewers = append(requestedReviewers, request)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			assert.Fail(t, "unexpected request", "%s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	gitParams := &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot", VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}}
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:     &outputwriter.StandardOutput{},
+		gitManager:       utils.NewGitManager(),
+		scanDetails:      utils.NewScanDetails(client, nil, gitParams).SetBaseBranch("master"),
+		packageReviewers: map[string][]string{"golang.org/x/crypto": {"crypto-expert"}, "golang.org/x/net": {"net-expert", "jfrog/security"}},
+	}
+	sink := &requestReviewersPullRequestSink{cfp: cfp}
+	cfp.pullRequestSink = sink
+	vulnerability := func(packageName, version, fixVersion string) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+				ImpactedDependencyName:    packageName,
+				ImpactedDependencyVersion: version,
+			},
+		}, fixVersion)
+	}
+	cryptoVulnerability := vulnerability("golang.org/x/crypto", "0.16.0", "0.17.0")
+	netVulnerability := vulnerability("golang.org/x/net", "0.16.0", "0.17.0")
+	textVulnerability := vulnerability("golang.org/x/text", "0.3.7", "0.3.8")
+
+	// The fix of the crypto library requests the review of its expert
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, cryptoVulnerability))
+	require.Len(t, sink.operations, 1)
+	assert.Equal(t, []string{"crypto-expert"}, sink.operations[0].Reviewers)
+	require.Len(t, requestedReviewers, 1)
+	assert.Equal(t, map[string][]string{"reviewers": {"crypto-expert"}, "team_reviewers": {}}, requestedReviewers[0])
+
+	// An aggregated pull request requests the reviewers of all the packages it fixes
+	cfp.aggregateFixes = true
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, true, cryptoVulnerability, netVulnerability, textVulnerability))
+	require.Len(t, sink.operations, 2)
+	assert.Equal(t, []string{"crypto-expert", "jfrog/security", "net-expert"}, sink.operations[1].Reviewers)
+	require.Len(t, requestedReviewers, 2)
+	assert.Equal(t, map[string][]string{"reviewers": {"crypto-expert", "net-expert"}, "team_reviewers": {"security"}}, requestedReviewers[1])
+
+	// A fix of a package without reviewers doesn't request any review
+	cfp.aggregateFixes = false
+	require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, fixBranchName, nil, false, textVulnerability))
+	require.Len(t, sink.operations, 3)
+	assert.Empty(t, sink.operations[2].Reviewers)
+	assert.Len(t, requestedReviewers, 2)
+}
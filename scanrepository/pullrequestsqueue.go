@@ -0,0 +1,165 @@
+package scanrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// GitHub advises waiting at least a second between requests that create content, so its secondary rate limits aren't exceeded.
+// The interval separates each request of the concurrent workers to the Git provider, rather than only the pull requests they open.
+const pullRequestsRequestInterval = time.Second
+
+// pullRequestsQueue opens the fix pull requests of a branch concurrently, once their fix branches are pushed.
+// The fixes and the pushes remain serial, as they share the working tree of the cloned repository.
+type pullRequestsQueue struct {
+	concurrency int
+	// Shared by the clients of all the workers
+	rateLimiter *utils.RateLimiter
+	// Builds the client of each concurrent worker, as the VCS clients aren't safe for concurrent use
+	newClient    func() (vcsclient.VcsClient, error)
+	pullRequests []*queuedPullRequest
+}
+
+type queuedPullRequest struct {
+	// Opens the pull request on the Git provider, concurrently with the other pull requests
+	open func(client vcsclient.VcsClient) (*vcsclient.PullRequestInfo, error)
+	// Applies the result of opening the pull request, in the order the pull requests were queued
+	onOpened        func(pullRequestInfo *vcsclient.PullRequestInfo, err error) error
+	pullRequestInfo *vcsclient.PullRequestInfo
+	err             error
+}
+
+func newPullRequestsQueue(concurrency int, requestInterval time.Duration, newClient func() (vcsclient.VcsClient, error)) *pullRequestsQueue {
+	return &pullRequestsQueue{concurrency: concurrency, rateLimiter: utils.NewRateLimiter(requestInterval), newClient: newClient}
+}
+
+func (prq *pullRequestsQueue) add(open func(vcsclient.VcsClient) (*vcsclient.PullRequestInfo, error), onOpened func(*vcsclient.PullRequestInfo, error) error) {
+	prq.pullRequests = append(prq.pullRequests, &queuedPullRequest{open: open, onOpened: onOpened})
+}
+
+// Opens the queued pull requests, up to the configured number at a time, and empties the queue.
+// The results are applied in the order the pull requests were queued, so the run summary doesn't depend on the order the requests complete in.
+func (prq *pullRequestsQueue) flush() (err error) {
+	pullRequests := prq.pullRequests
+	prq.pullRequests = nil
+	if len(pullRequests) == 0 {
+		return
+	}
+	workers := min(prq.concurrency, len(pullRequests))
+	log.Info(fmt.Sprintf("Opening %d pull requests, up to %d at a time", len(pullRequests), workers))
+	queue := make(chan *queuedPullRequest, len(pullRequests))
+	for _, pullRequest := range pullRequests {
+		queue <- pullRequest
+	}
+	close(queue)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			client, clientErr := prq.newClient()
+			rateLimitedClient := &rateLimitedVcsClient{VcsClient: client, rateLimiter: prq.rateLimiter}
+			for pullRequest := range queue {
+				if clientErr != nil {
+					pullRequest.err = clientErr
+					continue
+				}
+				pullRequest.pullRequestInfo, pullRequest.err = pullRequest.open(rateLimitedClient)
+			}
+		}()
+	}
+	waitGroup.Wait()
+	for _, pullRequest := range pullRequests {
+		err = errors.Join(err, pullRequest.onOpened(pullRequest.pullRequestInfo, pullRequest.err))
+	}
+	return
+}
+
+// rateLimitedVcsClient waits for the rate limiter before each of the requests a queued pull request sends to the Git provider.
+// Only the methods that opening a pull request calls are limited.
+type rateLimitedVcsClient struct {
+	vcsclient.VcsClient
+	rateLimiter *utils.RateLimiter
+}
+
+func (rlc *rateLimitedVcsClient) CreatePullRequest(ctx context.Context, owner, repository, sourceBranch, targetBranch, title, description string) error {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.CreatePullRequest(ctx, owner, repository, sourceBranch, targetBranch, title, description)
+}
+
+func (rlc *rateLimitedVcsClient) UpdatePullRequest(ctx context.Context, owner, repository, title, body, targetBranchName string, prId int, state vcsutils.PullRequestState) error {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.UpdatePullRequest(ctx, owner, repository, title, body, targetBranchName, prId, state)
+}
+
+func (rlc *rateLimitedVcsClient) AddPullRequestComment(ctx context.Context, owner, repository, content string, pullRequestID int) error {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.AddPullRequestComment(ctx, owner, repository, content, pullRequestID)
+}
+
+func (rlc *rateLimitedVcsClient) ListPullRequestComments(ctx context.Context, owner, repository string, pullRequestID int) ([]vcsclient.CommentInfo, error) {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.ListPullRequestComments(ctx, owner, repository, pullRequestID)
+}
+
+func (rlc *rateLimitedVcsClient) DeletePullRequestComment(ctx context.Context, owner, repository string, pullRequestID, commentID int) error {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.DeletePullRequestComment(ctx, owner, repository, pullRequestID, commentID)
+}
+
+func (rlc *rateLimitedVcsClient) ListPullRequestReviewComments(ctx context.Context, owner, repository string, pullRequestID int) ([]vcsclient.CommentInfo, error) {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.ListPullRequestReviewComments(ctx, owner, repository, pullRequestID)
+}
+
+func (rlc *rateLimitedVcsClient) DeletePullRequestReviewComments(ctx context.Context, owner, repository string, pullRequestID int, comments ...vcsclient.CommentInfo) error {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.DeletePullRequestReviewComments(ctx, owner, repository, pullRequestID, comments...)
+}
+
+func (rlc *rateLimitedVcsClient) ListOpenPullRequestsWithBody(ctx context.Context, owner, repository string) ([]vcsclient.PullRequestInfo, error) {
+	rlc.rateLimiter.Wait()
+	return rlc.VcsClient.ListOpenPullRequestsWithBody(ctx, owner, repository)
+}
+
+// Waits for the rate limiter of the client, if it's limited, before a request Frogbot sends to the Git provider directly rather than through the client
+func waitForRateLimit(client vcsclient.VcsClient) {
+	if rateLimitedClient, isRateLimited := client.(*rateLimitedVcsClient); isRateLimited {
+		rateLimitedClient.rateLimiter.Wait()
+	}
+}
+
+// Pushes the fix branch, and queues its pull request to be opened along with the other pull requests of the branch
+func (cfp *ScanRepositoryCmd) queuePullRequest(repository *utils.Repository, operation *utils.PullRequestOperation, existingPullRequest *vcsclient.PullRequestInfo, vulnerabilities []*utils.VulnerabilityDetails) error {
+	if err := (&vcsPullRequestSink{cfp: cfp}).push(operation); err != nil {
+		return err
+	}
+	cfp.pullRequestsQueue.add(
+		func(client vcsclient.VcsClient) (*vcsclient.PullRequestInfo, error) {
+			return (&vcsPullRequestSink{cfp: cfp, client: client}).openPullRequest(repository, operation, existingPullRequest)
+		},
+		func(pullRequestInfo *vcsclient.PullRequestInfo, err error) error {
+			if err != nil {
+				return fmt.Errorf("failed to open the pull request from %s to %s: %w", operation.SourceBranch, operation.TargetBranch, err)
+			}
+			cfp.recordPublishedPullRequest(operation, pullRequestInfo, vulnerabilities)
+			return nil
+		})
+	return nil
+}
+
+// Opens the queued pull requests of the current branch, if the pull requests are opened concurrently
+func (cfp *ScanRepositoryCmd) openQueuedPullRequests() error {
+	if cfp.pullRequestsQueue == nil {
+		return nil
+	}
+	return cfp.pullRequestsQueue.flush()
+}
@@ -0,0 +1,144 @@
+package scanrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenPullRequestsConcurrently(t *testing.T) {
+	const concurrency = 3
+	// A mock GitHub server that tracks the pull requests it creates, and the number of requests it handles at a time
+	var mutex sync.Mutex
+	var createdBranches []string
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/jfrog/frogbot/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			mutex.Lock()
+			defer mutex.Unlock()
+			var pullRequests []string
+			for i, branch := range createdBranches {
+				pullRequests = append(pullRequests, fmt.Sprintf(`{"number": %[1]d, "html_url": "https://github.com/jfrog/frogbot/pull/%[1]d", "head": {"ref": "%[2]s", "label": "jfrog:%[2]s", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}, "base": {"ref": "master", "label": "jfrog:master", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}}`, i+1, branch))
+			}
+			_, err := w.Write([]byte("[" + strings.Join(pullRequests, ",") + "]"))
+			assert.NoError(t, err)
+			return
+		}
+		var pullRequest struct {
+			Head string `json:"head"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&pullRequest))
+		mutex.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mutex.Unlock()
+		// Keeps the request in flight, so the concurrent requests overlap
+		time.Sleep(50 * time.Millisecond)
+		mutex.Lock()
+		defer mutex.Unlock()
+		inFlight--
+		if strings.Contains(pullRequest.Head, "semver") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		// The head of the pull request is written as owner:branch
+		createdBranches = append(createdBranches, strings.TrimPrefix(pullRequest.Head, "jfrog:"))
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte("{}"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	cfp := &ScanRepositoryCmd{
+		OutputWriter: &outputwriter.StandardOutput{},
+		gitManager:   utils.NewGitManager().SetDryRun(true, ""),
+		scanDetails:  utils.NewScanDetails(client, nil, &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot"}).SetBaseBranch("master"),
+		pullRequestsQueue: newPullRequestsQueue(concurrency, 0, func() (vcsclient.VcsClient, error) {
+			return vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+		}),
+	}
+	packages := []string{"minimist", "lodash", "semver", "axios", "express", "qs"}
+	for _, packageName := range packages {
+		vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: packageName, ImpactedDependencyVersion: "1.0.0"},
+		}, "1.0.1")
+		require.NoError(t, cfp.handleFixPullRequestContent(&utils.Repository{}, "frogbot-"+packageName, nil, false, vulnDetails))
+	}
+	// The pull requests are opened only once the queue is flushed
+	assert.Empty(t, createdBranches)
+	err = cfp.openQueuedPullRequests()
+
+	// The pull requests are opened concurrently, up to the configured number at a time
+	assert.Greater(t, maxInFlight, 1)
+	assert.LessOrEqual(t, maxInFlight, concurrency)
+	assert.Len(t, createdBranches, len(packages)-1)
+	// The failure to open one pull request doesn't fail the others
+	assert.ErrorContains(t, err, "failed to open the pull request from frogbot-semver to master")
+	// The summary lists the pull requests in the order they were queued, regardless of the order they were opened in
+	var summaryTitles []string
+	for _, pullRequest := range cfp.branchPullRequests {
+		summaryTitles = append(summaryTitles, pullRequest.Title)
+	}
+	var expectedTitles []string
+	for _, packageName := range []string{"minimist", "lodash", "axios", "express", "qs"} {
+		expectedTitles = append(expectedTitles, cfp.gitManager.GenerateFixPullRequestTitle(&utils.VulnerabilityDetails{
+			VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: packageName}},
+			SuggestedFixedVersion:       "1.0.1",
+		}))
+	}
+	assert.Equal(t, expectedTitles, summaryTitles)
+	// The queue is emptied
+	assert.NoError(t, cfp.openQueuedPullRequests())
+}
+
+// A client that records the time of each of its requests
+type requestTimesClient struct {
+	vcsclient.VcsClient
+	requestTimes []time.Time
+}
+
+func (c *requestTimesClient) ListOpenPullRequestsWithBody(_ context.Context, _, _ string) ([]vcsclient.PullRequestInfo, error) {
+	c.requestTimes = append(c.requestTimes, time.Now())
+	return nil, nil
+}
+
+func (c *requestTimesClient) AddPullRequestComment(_ context.Context, _, _, _ string, _ int) error {
+	c.requestTimes = append(c.requestTimes, time.Now())
+	return nil
+}
+
+func TestRateLimitedVcsClient(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	client := &requestTimesClient{}
+	rateLimitedClient := &rateLimitedVcsClient{VcsClient: client, rateLimiter: utils.NewRateLimiter(interval)}
+	// Every request of a pull request waits, not only the request that opens it
+	_, err := rateLimitedClient.ListOpenPullRequestsWithBody(context.Background(), "jfrog", "frogbot")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, rateLimitedClient.AddPullRequestComment(context.Background(), "jfrog", "frogbot", "comment", 1))
+	}
+	require.Len(t, client.requestTimes, 3)
+	for i := 1; i < len(client.requestTimes); i++ {
+		assert.GreaterOrEqual(t, client.requestTimes[i].Sub(client.requestTimes[i-1]), interval-5*time.Millisecond)
+	}
+}
@@ -0,0 +1,50 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+// Returns true if the branch should be scanned: unless a full scan is forced, a push that changes no dependency descriptor or lock file
+// can't change the detected vulnerabilities, so its scan is skipped. Runs that aren't triggered by a push are always scanned.
+// The changed files are taken from the environment if provided, or otherwise from the diff of the latest commit of the branch against its parent.
+func hasDependencyChanges(repository *utils.Repository, client vcsclient.VcsClient, branch string) bool {
+	if repository.AlwaysScan {
+		return true
+	}
+	changedFiles := repository.PushChangedFiles
+	if len(changedFiles) == 0 {
+		if !utils.IsPushEvent() {
+			return true
+		}
+		var err error
+		if changedFiles, err = getLatestCommitChangedFiles(client, repository.RepoOwner, repository.RepoName, branch); err != nil {
+			log.Warn(fmt.Sprintf("Couldn't get the files the push to %s changed, so the branch is scanned:\n%s", branch, err.Error()))
+			return true
+		}
+		if changedFiles == nil {
+			// The first commit of the branch has no parent to compare to
+			return true
+		}
+	}
+	return slices.ContainsFunc(changedFiles, utils.IsDependencyFile)
+}
+
+// Returns the files the latest commit of the branch changed, or nil if the commit has no parent
+func getLatestCommitChangedFiles(client vcsclient.VcsClient, owner, repo, branch string) ([]string, error) {
+	commit, err := client.GetLatestCommit(context.Background(), owner, repo, branch)
+	if err != nil || len(commit.ParentHashes) == 0 {
+		return nil, err
+	}
+	changedFiles, err := client.GetModifiedFiles(context.Background(), owner, repo, commit.ParentHashes[0], commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	// A commit that changes no files still has changes to compare, unlike a commit without a parent
+	return append([]string{}, changedFiles...), nil
+}
@@ -0,0 +1,55 @@
+package scanrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/stretchr/testify/assert"
+)
+
+// A client of a repository whose latest commit changed the given files
+type pushChangedFilesClient struct {
+	vcsclient.VcsClient
+	parentHashes []string
+	changedFiles []string
+}
+
+func (c *pushChangedFilesClient) GetLatestCommit(_ context.Context, _, _, _ string) (vcsclient.CommitInfo, error) {
+	return vcsclient.CommitInfo{Hash: "head", ParentHashes: c.parentHashes}, nil
+}
+
+func (c *pushChangedFilesClient) GetModifiedFiles(_ context.Context, _, _, _, _ string) ([]string, error) {
+	return c.changedFiles, nil
+}
+
+func TestHasDependencyChanges(t *testing.T) {
+	testCases := []struct {
+		name             string
+		alwaysScan       bool
+		pushChangedFiles []string
+		isPushEvent      bool
+		client           *pushChangedFilesClient
+		expected         bool
+	}{
+		{name: "changed non-descriptor file exits early", pushChangedFiles: []string{"README.md", "src/index.js"}, expected: false},
+		{name: "changed lock file", pushChangedFiles: []string{"README.md", "frontend/package-lock.json"}, expected: true},
+		{name: "changed requirements file", pushChangedFiles: []string{"requirements-dev.txt"}, expected: true},
+		{name: "always scan", alwaysScan: true, pushChangedFiles: []string{"README.md"}, expected: true},
+		{name: "not a push event", client: &pushChangedFilesClient{parentHashes: []string{"parent"}, changedFiles: []string{"README.md"}}, expected: true},
+		{name: "push diff without descriptors", isPushEvent: true, client: &pushChangedFilesClient{parentHashes: []string{"parent"}, changedFiles: []string{"README.md"}}, expected: false},
+		{name: "push diff with descriptor", isPushEvent: true, client: &pushChangedFilesClient{parentHashes: []string{"parent"}, changedFiles: []string{"go.mod"}}, expected: true},
+		{name: "first commit", isPushEvent: true, client: &pushChangedFilesClient{}, expected: true},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GITHUB_EVENT_NAME", "schedule")
+			if test.isPushEvent {
+				t.Setenv("GITHUB_EVENT_NAME", "push")
+			}
+			repository := &utils.Repository{Params: utils.Params{Git: utils.Git{AlwaysScan: test.alwaysScan, PushChangedFiles: test.pushChangedFiles}}}
+			assert.Equal(t, test.expected, hasDependencyChanges(repository, test.client, "main"))
+		})
+	}
+}
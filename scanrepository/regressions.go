@@ -0,0 +1,34 @@
+package scanrepository
+
+import (
+	"fmt"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+func (cfp *ScanRepositoryCmd) loadMergedFixes() {
+	var err error
+	if cfp.mergedFixes, err = utils.GetGitHubMergedFixes(cfp.scanDetails.APIEndpoint, cfp.scanDetails.Token, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch()); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
+// Flags the vulnerabilities of packages that a merged fix pull request had already upgraded beyond their current version.
+// Such a fix was reverted, so the vulnerability is escalated as a regression in the fix pull request and in the run summary.
+func (cfp *ScanRepositoryCmd) flagRegressions(vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) {
+	for _, vulnerabilities := range vulnerabilitiesByPathMap {
+		for _, vulnDetails := range vulnerabilities {
+			regression := utils.FindRegression(cfp.mergedFixes, vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion)
+			if regression == nil {
+				continue
+			}
+			vulnDetails.Regression = regression
+			message := fmt.Sprintf("%s %s was reintroduced, after %s updated it to %s", vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion, regression.PullRequestUrl, regression.FixVersion)
+			log.Warn("Regression detected:", message)
+			if cfp.runSummary != nil {
+				cfp.runSummary.AddWarning(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), "Regression: "+message)
+			}
+		}
+	}
+}
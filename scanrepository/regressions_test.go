@@ -0,0 +1,77 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagRegressions(t *testing.T) {
+	fixBody := func(vulnerabilities ...*utils.VulnerabilityDetails) string {
+		body, _ := utils.GenerateFixPullRequestDetails(vulnerabilities, &outputwriter.StandardOutput{})
+		return body + utils.FixedPackagesMarker(vulnerabilities)
+	}
+	newVulnerability := func(name, version, fixVersion string) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			Cves:                      []formats.CveRow{{Id: "CVE-2021-44906"}},
+		}, fixVersion)
+	}
+	closedPullRequests := []map[string]any{
+		// The fix of minimist was merged, and later reverted
+		{"number": 1, "html_url": "https://github.com/jfrog/repo/pull/1", "merged_at": "2024-01-01T00:00:00Z", "body": fixBody(newVulnerability("minimist", "1.2.5", "1.2.6"))},
+		// The fix of lodash was closed without being merged
+		{"number": 2, "html_url": "https://github.com/jfrog/repo/pull/2", "merged_at": nil, "body": fixBody(newVulnerability("lodash", "4.17.20", "4.17.21"))},
+		// The fix of uuid was merged, and the package was upgraded further since
+		{"number": 3, "html_url": "https://github.com/jfrog/repo/pull/3", "merged_at": "2024-01-01T00:00:00Z", "body": fixBody(newVulnerability("uuid", "2.0.0", "3.0.0"))},
+		// Pull requests that weren't opened by Frogbot are ignored
+		{"number": 4, "html_url": "https://github.com/jfrog/repo/pull/4", "merged_at": "2024-01-01T00:00:00Z", "body": "[comment]: <> (Fixed packages: semver@7.5.2)"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/jfrog/repo/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "closed", r.URL.Query().Get("state"))
+		assert.Equal(t, "master", r.URL.Query().Get("base"))
+		assert.NoError(t, json.NewEncoder(w).Encode(closedPullRequests))
+	}))
+	defer server.Close()
+
+	cfp := ScanRepositoryCmd{
+		scanDetails: utils.NewScanDetails(nil, nil, &utils.Git{VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}, RepoOwner: "jfrog", RepoName: "repo"}).SetBaseBranch("master"),
+		runSummary:  &utils.RunSummary{},
+	}
+	cfp.loadMergedFixes()
+	require.Len(t, cfp.mergedFixes, 2)
+
+	vulnerabilitiesByPathMap := map[string]map[string]*utils.VulnerabilityDetails{
+		"/repo": {
+			"minimist": newVulnerability("minimist", "1.2.5", "1.2.6"),
+			"lodash":   newVulnerability("lodash", "4.17.20", "4.17.21"),
+			"uuid":     newVulnerability("uuid", "3.0.0", "3.0.1"),
+			"semver":   newVulnerability("semver", "7.5.1", "7.5.2"),
+		},
+	}
+	cfp.flagRegressions(vulnerabilitiesByPathMap)
+
+	minimist := vulnerabilitiesByPathMap["/repo"]["minimist"]
+	require.NotNil(t, minimist.Regression)
+	assert.Equal(t, utils.MergedFix{PackageName: "minimist", FixVersion: "1.2.6", PullRequestUrl: "https://github.com/jfrog/repo/pull/1"}, *minimist.Regression)
+	for _, packageName := range []string{"lodash", "uuid", "semver"} {
+		assert.Nil(t, vulnerabilitiesByPathMap["/repo"][packageName].Regression, packageName)
+	}
+	assert.Equal(t, []string{"jfrog/repo: Regression: minimist 1.2.5 was reintroduced, after https://github.com/jfrog/repo/pull/1 updated it to 1.2.6"}, cfp.runSummary.Warnings)
+
+	// The regression is escalated at the top of the new fix pull request
+	body, _ := utils.GenerateFixPullRequestDetails([]*utils.VulnerabilityDetails{minimist}, &outputwriter.StandardOutput{})
+	assert.Contains(t, body, "🔁 **Regression:** minimist 1.2.5 was reintroduced, after it was fixed by updating it to 1.2.6 in https://github.com/jfrog/repo/pull/1.")
+}
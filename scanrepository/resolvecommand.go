@@ -0,0 +1,13 @@
+package scanrepository
+
+import "context"
+
+// Runs the configured resolve command in the working directory, to produce the inputs its scan needs, such as a combined manifest of a monorepo tool.
+// The technologies of the working directory are detected after the command runs, so the files it generates are scanned and fixed like any other descriptor.
+// The command is killed if the context is done before it completes.
+func (cfp *ScanRepositoryCmd) runResolveCommand(ctx context.Context, fullPathWd string) error {
+	if cfp.resolveCommand == "" {
+		return nil
+	}
+	return cfp.runRepositoryCommand(ctx, cfp.resolveCommand, "resolve command", fullPathWd)
+}
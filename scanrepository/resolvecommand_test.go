@@ -0,0 +1,100 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jfrog/frogbot/v2/packagehandlers"
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixes the descriptor only if it exists, like a package manager would
+type existingDescriptorFixHandler struct {
+	*descriptorFixHandler
+}
+
+func (edfh *existingDescriptorFixHandler) UpdateDependency(vulnDetails *utils.VulnerabilityDetails) error {
+	if _, err := os.Stat(edfh.descriptor); err != nil {
+		return fmt.Errorf("the descriptor of %s doesn't exist: %s", vulnDetails.ImpactedDependencyName, err.Error())
+	}
+	return edfh.descriptorFixHandler.UpdateDependency(vulnDetails)
+}
+
+func TestRunResolveCommand(t *testing.T) {
+	// A repository whose npm descriptor is generated by the resolve command
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# resolved"), 0600))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("README.md")
+	require.NoError(t, err)
+	_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}})
+	require.NoError(t, err)
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+
+	gitParams := &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}
+	gitManager, err := utils.NewGitManager().SetDryRun(true, "").SetLocalRepository()
+	require.NoError(t, err)
+	_, err = gitManager.SetGitParams(gitParams)
+	require.NoError(t, err)
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      gitManager,
+		scanDetails:     utils.NewScanDetails(nil, nil, gitParams).SetBaseBranch("master"),
+		baseWd:          repoDir,
+		dryRun:          true,
+		dryRunOutput:    &strings.Builder{},
+		pullRequestSink: &discardPullRequestSink{},
+		resolveCommand:  `printf '{"dependencies": {"minimist": "1.2.5", "lodash": "4.17.20"}}' > package.json`,
+		handlers:        map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: &existingDescriptorFixHandler{packageJsonFixHandler}},
+	}
+
+	// The descriptor the command generates is detected by the scan
+	require.NoError(t, cfp.runResolveCommand(context.Background(), repoDir))
+	technologiesDescriptors, err := techutils.DetectTechnologiesDescriptors(repoDir, false, nil, nil, "")
+	require.NoError(t, err)
+	assert.Contains(t, technologiesDescriptors, techutils.Npm)
+
+	// Each fix branch starts from the base branch, so the descriptor is generated again before every fix
+	newVulnerability := func(packageName, version, fixVersion string) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: packageName, ImpactedDependencyVersion: version},
+			Technology:                techutils.Npm,
+		}, fixVersion)
+	}
+	vulnerabilitiesByPathMap := map[string]map[string]*utils.VulnerabilityDetails{
+		repoDir: {
+			"minimist": newVulnerability("minimist", "1.2.5", "1.2.6"),
+			"lodash":   newVulnerability("lodash", "4.17.20", "4.17.21"),
+		},
+	}
+	require.NoError(t, cfp.fixVulnerablePackages(&utils.Repository{}, vulnerabilitiesByPathMap))
+	require.Len(t, cfp.DryRunResult().PullRequests, 2)
+	for _, pullRequest := range cfp.DryRunResult().PullRequests {
+		assert.Contains(t, pullRequest.Patch, "package.json")
+		assert.Len(t, pullRequest.Packages, 1)
+		assert.Contains(t, pullRequest.Patch, fmt.Sprintf(`+{"dependencies": {"%s": "%s"}}`, pullRequest.Packages[0].PackageName, pullRequest.Packages[0].TargetVersion))
+	}
+
+	// A failing command fails the scan of the working directory
+	cfp.resolveCommand = "exit 3"
+	assert.ErrorContains(t, cfp.runResolveCommand(context.Background(), repoDir), "the resolve command 'exit 3' failed")
+}
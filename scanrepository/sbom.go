@@ -0,0 +1,28 @@
+package scanrepository
+
+import (
+	"path/filepath"
+
+	"github.com/jfrog/frogbot/v2/utils"
+)
+
+// Writes the SBOMs of the vulnerable and fixed state of the current branch, if requested.
+// The SBOMs are written on dry runs as well, as they don't change the repository.
+func (cfp *ScanRepositoryCmd) writeSboms() (err error) {
+	if cfp.sbomOutput != "" {
+		if err = utils.WriteCycloneDxSbom(utils.CreateCycloneDxSbom(cfp.branchVulnerabilities, nil), cfp.sbomOutput); err != nil {
+			return
+		}
+	}
+	if cfp.fixedSbomOutput != "" {
+		err = utils.WriteCycloneDxSbom(utils.CreateCycloneDxSbom(cfp.branchVulnerabilities, cfp.sbomFixes), cfp.fixedSbomOutput)
+	}
+	return
+}
+
+func getAbsPathIfProvided(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	return filepath.Abs(path)
+}
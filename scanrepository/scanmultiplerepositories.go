@@ -2,8 +2,10 @@ package scanrepository
 
 import (
 	"errors"
+	"fmt"
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 )
 
 type ScanMultipleRepositories struct {
@@ -11,15 +13,40 @@ type ScanMultipleRepositories struct {
 	dryRun bool
 	// When dryRun is enabled, dryRunRepoPath specifies the repository local path to clone
 	dryRunRepoPath string
+	// The summary of the run, across all the scanned repositories
+	runSummary *utils.RunSummary
 }
 
 func (saf *ScanMultipleRepositories) Run(repoAggregator utils.RepoAggregator, client vcsclient.VcsClient, frogbotRepoConnection *utils.UrlAccessChecker) (err error) {
-	scanRepositoryCmd := &ScanRepositoryCmd{dryRun: saf.dryRun, dryRunRepoPath: saf.dryRunRepoPath, baseWd: saf.dryRunRepoPath}
+	saf.runSummary = &utils.RunSummary{}
+	scanRepositoryCmd := &ScanRepositoryCmd{dryRun: saf.dryRun, dryRunRepoPath: saf.dryRunRepoPath, baseWd: saf.dryRunRepoPath, runSummary: saf.runSummary}
 	for repoNum := range repoAggregator {
 		repoAggregator[repoNum].OutputWriter.SetHasInternetConnection(frogbotRepoConnection.IsConnected())
-		if e := scanRepositoryCmd.scanAndFixRepository(&repoAggregator[repoNum], client); e != nil {
+		// A failure to scan a repository doesn't stop the scan of the others
+		if e := saf.scanAndFixRepository(scanRepositoryCmd, &repoAggregator[repoNum], client); e != nil {
 			err = errors.Join(err, e)
 		}
 	}
+	if len(repoAggregator) > 0 {
+		log.Info(saf.runSummary.GetRepositoriesSummary())
+		// A single summary is sent for all the repositories scanned in the run
+		utils.SendRunSummaryNotifications(repoAggregator[0].NotificationsDetails, saf.runSummary)
+	}
 	return
 }
+
+// Scans the repository and records its outcome in the run summary, along with the pull requests and vulnerabilities added by its scan
+func (saf *ScanMultipleRepositories) scanAndFixRepository(scanRepositoryCmd *ScanRepositoryCmd, repository *utils.Repository, client vcsclient.VcsClient) (err error) {
+	summary := saf.runSummary
+	pullRequestsBefore, vulnerabilitiesBefore := len(summary.PullRequests), len(summary.Vulnerabilities)
+	defer func() {
+		summary.AddRepository(repository.RepoOwner+"/"+repository.RepoName, len(summary.PullRequests)-pullRequestsBefore, len(summary.Vulnerabilities)-vulnerabilitiesBefore, err)
+		if err != nil {
+			err = fmt.Errorf("failed to scan %s/%s: %w", repository.RepoOwner, repository.RepoName, err)
+		}
+	}()
+	if len(repository.Branches) == 0 && !repository.FixPullRequestBranch {
+		return fmt.Errorf("no branches to scan were detected. Please set the branches of the repository in the %s file", utils.FrogbotConfigFile)
+	}
+	return scanRepositoryCmd.scanAndFixRepository(repository, client)
+}
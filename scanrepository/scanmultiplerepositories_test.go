@@ -8,9 +8,11 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
 	"github.com/jfrog/froggit-go/vcsclient"
 	"github.com/jfrog/froggit-go/vcsutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -57,13 +59,53 @@ func TestScanAndFixRepos(t *testing.T) {
 	}()
 
 	utils.CreateDotGitWithCommit(t, testDir, port, testRepositories...)
-	configAggregator, err := utils.BuildRepoAggregator(client, configData, &gitTestParams, &serverParams, utils.ScanMultipleRepositories)
+	configAggregator, err := utils.BuildRepoAggregator(client, [][]byte{configData}, &gitTestParams, &serverParams, utils.ScanMultipleRepositories)
 	assert.NoError(t, err)
 
 	var cmd = ScanMultipleRepositories{dryRun: true, dryRunRepoPath: testDir}
 	assert.NoError(t, cmd.Run(configAggregator, client, utils.MockHasConnection()))
 }
 
+func TestScanMultipleRepositoriesSummary(t *testing.T) {
+	// The first repository isn't found
+	var requestedRepositories []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedRepositories = append(requestedRepositories, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	failOnSecurityIssues := false
+	var repositories utils.RepoAggregator
+	for _, repoName := range []string{"first-repo", "second-repo"} {
+		repository := utils.Repository{Params: utils.Params{
+			Git:  utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: repoName},
+			Scan: utils.Scan{FailOnSecurityIssues: &failOnSecurityIssues},
+		}}
+		repository.OutputWriter = &outputwriter.StandardOutput{}
+		repositories = append(repositories, repository)
+	}
+	// No branches were detected for the second repository
+	repositories[0].Branches = []string{"master"}
+
+	var cmd = ScanMultipleRepositories{dryRun: true}
+	err = cmd.Run(repositories, client, utils.MockHasConnection())
+	// The failure of the first repository doesn't stop the scan of the second
+	assert.ErrorContains(t, err, "failed to scan jfrog/first-repo")
+	assert.ErrorContains(t, err, "failed to scan jfrog/second-repo: no branches to scan were detected")
+
+	// Both repositories are listed in the combined summary, along with their errors
+	require.Len(t, cmd.runSummary.Repositories, 2)
+	assert.Equal(t, "jfrog/first-repo", cmd.runSummary.Repositories[0].Repository)
+	assert.NotEmpty(t, cmd.runSummary.Repositories[0].Error)
+	assert.Equal(t, []string{"/repos/jfrog/first-repo"}, requestedRepositories)
+	assert.Equal(t, "jfrog/second-repo", cmd.runSummary.Repositories[1].Repository)
+	assert.Contains(t, cmd.runSummary.Repositories[1].Error, "no branches to scan were detected")
+	assert.True(t, strings.HasPrefix(cmd.runSummary.GetRepositoriesSummary(), "Scanned 2 repositories, 2 failed"))
+}
+
 func createScanRepoGitHubHandler(t *testing.T, port *string, response interface{}, projectNames ...string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		for _, projectName := range projectNames {
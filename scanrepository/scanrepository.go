@@ -4,18 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jfrog/frogbot/v2/packagehandlers"
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/frogbot/v2/utils/outputwriter"
 	"github.com/jfrog/froggit-go/vcsclient"
 	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/gofrog/datastructures"
 	"github.com/jfrog/gofrog/version"
 	"github.com/jfrog/jfrog-cli-security/formats"
 	securityutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/jfrog/jfrog-cli-security/utils/xsc"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
@@ -24,7 +30,12 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-const analyticsScanRepositoryScanType = "monitor"
+const (
+	analyticsScanRepositoryScanType = "monitor"
+	dryRunSeparator                 = "-----------------------------------------------------------------"
+	gitLabDraftTitlePrefix          = "Draft: "
+	lastUpdatePrefix                = "Last update: "
+)
 
 type ScanRepositoryCmd struct {
 	// The interface that Frogbot utilizes to format and style the displayed messages on the Git providers
@@ -33,6 +44,10 @@ type ScanRepositoryCmd struct {
 	dryRun bool
 	// When dryRun is enabled, dryRunRepoPath specifies the repository local path to clone
 	dryRunRepoPath string
+	// When dryRun is enabled, the title and body of every pull request are rendered into dryRunOutput (stdout by default)
+	dryRunOutput io.Writer
+	// When dryRun is enabled, the pull requests that would be opened or updated are recorded in dryRunResult
+	dryRunResult *DryRunResult
 	// The scanDetails of the current scan
 	scanDetails *utils.ScanDetails
 	// The base working directory
@@ -41,12 +56,165 @@ type ScanRepositoryCmd struct {
 	gitManager *utils.GitManager
 	// Determines whether to open a pull request for each vulnerability fix or to aggregate all fixes into one pull request
 	aggregateFixes bool
+	// The minimal interval between updates of the aggregated pull request
+	minPrUpdateInterval time.Duration
+	// The mechanism that stores the checksum and the last update time of an aggregated pull request in its body
+	checksumStorage utils.ChecksumStorage
+	// Determines whether the fix commits include a manifest that describes their fixes
+	writeFixManifest bool
+	// Carries out the operations that create or update the fix pull requests. The Git provider is used if nil
+	pullRequestSink PullRequestSink
+	// The maximal number of packages an aggregated pull request fixes, 0 if not limited
+	maxPackagesPerPr int
+	// The part of the aggregated fix that is currently fixed, when it's split into several pull requests. 0 if it isn't split
+	aggregatedPullRequestPart int
+	// Determines whether to open a pull request with all the fixes of each working directory, across its technologies, when the fixes aren't aggregated
+	groupFixesByDir bool
+	// The working directory whose fixes are currently fixed, relative to the repository root, when the fixes are grouped by working directory
+	groupedFixDir string
+	// The technologies of the vulnerabilities in the working directory whose fixes are currently fixed
+	groupedFixTech []techutils.Technology
+	// The working directories whose scans timed out during the run, which fail it
+	timedOutDirs []string
 	// The current project technology
 	projectTech []techutils.Technology
 	// Stores all package manager handlers for detected issues
 	handlers map[techutils.Technology]packagehandlers.PackageHandler
 	// The AnalyticsMetricsService used for analytics event report
 	analyticsService *xsc.AnalyticsMetricsService
+	// Determines whether to act only on vulnerabilities that weren't detected in the last successful run of the branch
+	onlyNewVulnerabilities bool
+	// The directory in which Frogbot persists data between runs
+	stateDir string
+	// The vulnerabilities detected in the last successful run of the current branch, nil if no baseline was recorded
+	vulnerabilitiesBaseline *utils.VulnerabilitiesBaseline
+	// The keys of the vulnerabilities detected in the current branch, recorded as the new baseline after a successful run
+	detectedVulnerabilities []string
+	// Limits the suggested fix versions to the major or minor version of the impacted version
+	fixVersionCeilingPolicy utils.FixVersionCeilingPolicy
+	// Limits how far the suggested fix versions may be from the impacted version, nil if not limited
+	maxVersionJump *utils.MaxVersionJump
+	// The registries and mirrors of each ecosystem the fix versions must be available on, before the packages are updated
+	versionRegistries utils.VersionRegistries
+	// The ecosystems whose fix versions are verified with their provenance attestations, and whether a failed verification skips the fix
+	provenanceEcosystems  []string
+	blockFailedProvenance bool
+	// The versions of the runtimes the fix versions must support, by ecosystem
+	runtimeVersions utils.RuntimeVersions
+	// Determines how to handle failed lookups of the registry metadata the checks of the fix versions depend on
+	metadataLookupPolicy utils.MetadataLookupPolicy
+	// Determines whether to derive a concrete fix version from fix versions that are expressed as ranges
+	resolveFixVersionRanges bool
+	// Determines whether to prefer a stable fix version over a newer pre-release when the impacted version is a pre-release
+	preferStableFixVersion bool
+	// The pull requests opened or updated during the run, posted to the configured notification channels
+	runSummary *utils.RunSummary
+	// Determines how to handle detected technologies whose vulnerable dependencies can't be fixed
+	onUnsupportedTech utils.UnsupportedTechPolicy
+	// Determines how to handle scans whose results Xray returned for some of the scanned components only
+	onPartialScan utils.PartialScanPolicy
+	// The time the vulnerabilities must be remediated within, by severity. nil if no SLA policy is configured
+	slaPolicy utils.SlaPolicy
+	// The time the state files are retained for since they were last updated, zero if they're retained while their branches exist
+	stateRetention time.Duration
+	// The lower severities that vulnerabilities that aren't applicable are treated as
+	applicabilitySeverityAdjustment utils.ApplicabilitySeverityAdjustment
+	// Determines whether the violations, the vulnerabilities or both drive the fixes
+	fixSource utils.FixSource
+	// The CVSS version whose scores prioritize the fixes
+	cvssVersionPreference utils.CvssVersion
+	// Determines whether to verify that the vulnerabilities fixed by merged fix pull requests are gone
+	verifyAfterMerge bool
+	// The fix pull requests to verify in the next run
+	fixPullRequestsRecord *utils.FixPullRequestsRecord
+	// The fix pull requests that were merged since the last run
+	mergedFixPullRequests []utils.FixPullRequest
+	// The names of the vulnerable packages detected in the current branch
+	detectedPackages *datastructures.Set[string]
+	// Determines whether to reference the security alerts of the provider that are addressed by the fix pull requests
+	linkSecurityAlerts bool
+	// The open security alerts of the repository
+	securityAlerts []outputwriter.SecurityAlertRow
+	// Determines whether to escalate vulnerabilities that were reintroduced after a merged fix pull request fixed them
+	detectRegressions bool
+	// The fixes of the fix pull requests that were merged into the current branch
+	mergedFixes []utils.MergedFix
+	// If provided, only vulnerabilities with one of these CVEs are fixed
+	onlyCves []string
+	// Vulnerabilities with one of these CVEs are not fixed
+	excludeCves []string
+	// Vulnerabilities that are reached only through dependency paths that match these rules are not fixed
+	ignoreRules []utils.IgnoreRule
+	// Excludes the vulnerabilities of the packages that are maintained in the workspaces of the scanned working directory from the reports and the fixes
+	excludeWorkspacePackages bool
+	workspacePackages        []string
+	// The absolute paths to write the CycloneDX SBOMs of the vulnerable and fixed state to
+	sbomOutput      string
+	fixedSbomOutput string
+	// The absolute path to the SBOM that is scanned instead of resolving the dependencies of the projects
+	inputSbom string
+	// The command to run in the base working directory between the scans of the working directories, to reset the state the previous scan left behind
+	betweenDirsCommand string
+	// The command to run in each working directory before its scan, to produce the dependency descriptors the scan detects
+	resolveCommand string
+	// The number of working directories scanned in the current branch
+	scannedWorkingDirs int
+	// The absolute path to write the JUnit report of the vulnerabilities to
+	junitOutput string
+	// Unfixed vulnerabilities below this severity are skipped in the JUnit report rather than failed
+	junitFailureSeverity severityutils.Severity
+	// The absolute path to write the HTML report of the vulnerabilities to
+	htmlReport string
+	// The absolute path to write the report of the vulnerabilities of all the scanned branches to
+	crossBranchReport string
+	// The vulnerabilities of the branches scanned so far, for the cross-branch report
+	crossBranchResults *utils.CrossBranchReport
+	// The absolute path of the directory to write the changes of every fix branch to, as a patch file named by the branch
+	patchesDir string
+	// The vulnerabilities detected in the current branch, as they were before computing the fix versions
+	branchVulnerabilities []formats.VulnerabilityOrViolationRow
+	// The fixes suggested for the vulnerabilities detected in the current branch
+	sbomFixes []*utils.VulnerabilityDetails
+	// Determines whether to comment on the scanned commit with a summary of the vulnerabilities and the fix pull requests
+	commentOnCommit bool
+	// Determines whether the comment on the scanned commit omits the details of the vulnerabilities
+	redactCommitComment bool
+	// The hash of the scanned commit of the current branch
+	scannedCommit string
+	// The pull requests opened or updated for the current branch
+	branchPullRequests []outputwriter.FixPullRequestRow
+	// Determines whether to open a tracking issue for each vulnerability that can't be fixed
+	createIssuesForUnfixable bool
+	// Opens the fix pull requests of a branch concurrently, if more than one pull request may be opened at a time
+	pullRequestsQueue *pullRequestsQueue
+	// Determines whether to open the fix pull requests as drafts, if the advisories note breaking changes in their fixes
+	draftOnBreaking bool
+	// The reasons the fixes of vulnerable packages in the current branch aren't supported, mapped by the packages
+	unsupportedFixes map[string]*utils.ErrUnsupportedFix
+	// The packages of the scanned projects that the policies exclude from fixing, listed in the aggregated pull request
+	excludedPackages map[string]outputwriter.PackageStatusRow
+	// The packages the current aggregated pull request skips, along with the reasons they were skipped
+	aggregatedSkippedPackages []outputwriter.PackageStatusRow
+	// The open pull request whose added or bumped dependencies are fixed in its source branch, nil when fixing the configured branches
+	fixedPullRequest *vcsclient.PullRequestInfo
+	// The lines the fixed pull request adds to the dependency descriptors
+	pullRequestChangedLines []string
+	// Determines whether the repository has several working directories, whose fix branches are told apart by their working directories
+	multipleWorkingDirs bool
+	// The file in the repository whose content is added to the fix pull requests body as the test matrix note
+	testMatrixNoteFile string
+	// Determines whether to rebase the open fix pull requests whose base branch advanced since they were opened
+	autoRebaseStalePrs bool
+	// The reviewers requested on the fix pull requests of the packages that match each pattern
+	packageReviewers map[string][]string
+	// Determines how to handle a fix branch that already exists on the remote
+	onExistingBranch utils.ExistingBranchPolicy
+	// Determines whether to upgrade the dependencies whose licenses aren't allowed to a version whose license is acceptable
+	fixLicenseViolations bool
+	// The licenses the fixed versions of the license violations may have
+	allowedLicenses []string
+	// The license violations detected in the current branch
+	branchLicenseViolations []formats.LicenseRow
 }
 
 func (cfp *ScanRepositoryCmd) Run(repoAggregator utils.RepoAggregator, client vcsclient.VcsClient, frogbotRepoConnection *utils.UrlAccessChecker) (err error) {
@@ -55,20 +223,67 @@ func (cfp *ScanRepositoryCmd) Run(repoAggregator utils.RepoAggregator, client vc
 	}
 	repository := repoAggregator[0]
 	repository.OutputWriter.SetHasInternetConnection(frogbotRepoConnection.IsConnected())
+	cfp.runSummary = &utils.RunSummary{}
+	if cfp.dryRun {
+		cfp.dryRunResult = &DryRunResult{}
+	}
+	defer func() {
+		utils.SendRunSummaryNotifications(repository.NotificationsDetails, cfp.runSummary)
+		if cfp.dryRun {
+			return
+		}
+		// Like the notifications, a failure to update the wiki page doesn't fail the run
+		if e := utils.UpdateWikiPageWithRunSummary(&repository, cfp.runSummary); e != nil {
+			log.Warn(e.Error())
+		}
+	}()
 	return cfp.scanAndFixRepository(&repository, client)
 }
 
 func (cfp *ScanRepositoryCmd) scanAndFixRepository(repository *utils.Repository, client vcsclient.VcsClient) (err error) {
+	cfp.timedOutDirs = nil
+	defer func() {
+		err = errors.Join(err, cfp.getPerDirTimeoutError())
+	}()
 	if err = cfp.setCommandPrerequisites(repository, client); err != nil {
 		return
 	}
+	if cfp.stateDir != "" {
+		// The state is pruned at the start of the run, so the branches scanned by the run keep their state
+		cfp.pruneStateFiles(client)
+	}
+	if repository.FixPullRequestBranch {
+		// Only the dependencies introduced by the pull request are fixed, in its source branch
+		return cfp.scanAndFixPullRequest(repository, client)
+	}
+	// The report covers all the branches, so it's written to the path of the repository even if a branch overrides it
+	crossBranchReport := cfp.crossBranchReport
+	cfp.crossBranchResults = nil
+	if crossBranchReport != "" {
+		cfp.crossBranchResults = utils.NewCrossBranchReport(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName))
+	}
+	currentRepository := repository
 	for _, branch := range repository.Branches {
+		// Branches with overrides in the config have their own params
+		if branchRepository := repository.GetBranchRepository(branch); branchRepository != currentRepository {
+			if err = cfp.setCommandPrerequisites(branchRepository, client); err != nil {
+				return
+			}
+			currentRepository = branchRepository
+		}
+		if !hasDependencyChanges(currentRepository, client, branch) {
+			log.Info(fmt.Sprintf("No dependency changes in the push to %s. Skipping the scan...", branch))
+			continue
+		}
 		cfp.scanDetails.SetBaseBranch(branch)
-		cfp.scanDetails.SetXscGitInfoContext(branch, repository.Project, client)
-		if err = cfp.scanAndFixBranch(repository); err != nil {
+		cfp.scanDetails.SetXscGitInfoContext(branch, currentRepository.Project, client)
+		if err = cfp.scanAndFixBranch(currentRepository); err != nil {
 			return
 		}
 	}
+	if cfp.crossBranchResults != nil {
+		err = utils.WriteCrossBranchReport(cfp.crossBranchResults, crossBranchReport)
+	}
 	return
 }
 
@@ -80,9 +295,18 @@ func (cfp *ScanRepositoryCmd) scanAndFixBranch(repository *utils.Repository) (er
 
 	clonedRepoDir, restoreBaseDir, err := cfp.cloneRepositoryAndCheckoutToBranch()
 	if err != nil {
+		var errEmptyBranch *utils.ErrEmptyBranch
+		if !errors.As(err, &errEmptyBranch) {
+			return
+		}
+		// There is nothing to scan on a branch without commits, as in a newly created repository
+		log.Info(fmt.Sprintf("Skipping the scan of %s/%s: %s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, err.Error()))
+		err = nil
+		if !cfp.dryRun {
+			err = fileutils.RemoveTempDir(clonedRepoDir)
+		}
 		return
 	}
-	cfp.baseWd = clonedRepoDir
 	defer func() {
 		// On dry run don't delete the folder as we want to validate results
 		if cfp.dryRun {
@@ -90,6 +314,9 @@ func (cfp *ScanRepositoryCmd) scanAndFixBranch(repository *utils.Repository) (er
 		}
 		err = errors.Join(err, restoreBaseDir(), fileutils.RemoveTempDir(clonedRepoDir))
 	}()
+	if cfp.testMatrixNoteFile != "" {
+		cfp.loadTestMatrixNote()
+	}
 
 	// If MSI exists we always need to report events
 	if cfp.analyticsService.GetMsi() != "" {
@@ -101,13 +328,84 @@ func (cfp *ScanRepositoryCmd) scanAndFixBranch(repository *utils.Repository) (er
 		}
 	}
 
-	for i := range repository.Projects {
-		cfp.scanDetails.Project = &repository.Projects[i]
+	if cfp.onlyNewVulnerabilities {
+		if cfp.vulnerabilitiesBaseline, err = utils.LoadVulnerabilitiesBaseline(cfp.stateDir, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch()); err != nil {
+			return
+		}
+		cfp.detectedVulnerabilities = []string{}
+	}
+	if cfp.verifyAfterMerge {
+		if err = cfp.loadMergedFixPullRequests(); err != nil {
+			return
+		}
+	}
+	if cfp.linkSecurityAlerts {
+		cfp.loadSecurityAlerts()
+	}
+	if cfp.detectRegressions {
+		cfp.loadMergedFixes()
+	}
+	cfp.branchVulnerabilities, cfp.sbomFixes, cfp.branchPullRequests, cfp.branchLicenseViolations = nil, nil, nil, nil
+	cfp.unsupportedFixes = make(map[string]*utils.ErrUnsupportedFix)
+	if cfp.commentOnCommit {
+		// The commit is recorded before the fixes check out other branches
+		if cfp.scannedCommit, err = cfp.gitManager.GetHeadCommitHash(); err != nil {
+			return
+		}
+	}
+
+	projectsGroups, err := cfp.groupProjectsBySharedLockfile(repository)
+	if err != nil {
+		return
+	}
+	for _, projects := range projectsGroups {
 		cfp.projectTech = []techutils.Technology{}
-		if err = cfp.scanAndFixProject(repository); err != nil {
+		if err = cfp.scanAndFixProjects(repository, projects); err != nil {
+			// The fix branches that were already pushed still get their pull requests
+			return errors.Join(err, cfp.openQueuedPullRequests())
+		}
+	}
+	if err = cfp.openQueuedPullRequests(); err != nil {
+		return
+	}
+
+	if cfp.onlyNewVulnerabilities {
+		if err = utils.SaveVulnerabilitiesBaseline(cfp.stateDir, utils.NewVulnerabilitiesBaseline(cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch(), cfp.detectedVulnerabilities)); err != nil {
 			return
 		}
 	}
+	if cfp.verifyAfterMerge {
+		if err = cfp.confirmMergedFixPullRequests(); err != nil {
+			return
+		}
+	}
+	if err = cfp.writeSboms(); err != nil {
+		return
+	}
+	if err = cfp.writeJunitReport(); err != nil {
+		return
+	}
+	if err = cfp.writeHtmlReport(); err != nil {
+		return
+	}
+	cfp.addBranchToCrossBranchReport()
+	if cfp.slaPolicy != nil {
+		if err = cfp.trackSlaStatuses(); err != nil {
+			return
+		}
+	}
+	if cfp.runSummary != nil {
+		cfp.runSummary.AddVulnerabilities(cfp.branchVulnerabilities...)
+		cfp.runSummary.AddLicenseViolations(cfp.branchLicenseViolations...)
+	}
+	if cfp.commentOnCommit {
+		if err = cfp.commentOnScannedCommit(); err != nil {
+			return
+		}
+	}
+	if cfp.createIssuesForUnfixable {
+		err = cfp.syncUnfixableVulnerabilitiesIssues()
+	}
 	return
 }
 
@@ -116,7 +414,8 @@ func (cfp *ScanRepositoryCmd) setCommandPrerequisites(repository *utils.Reposito
 	cfp.scanDetails = utils.NewScanDetails(client, &repository.Server, &repository.Git).
 		SetXrayGraphScanParams(repository.Watches, repository.JFrogProjectKey, len(repository.AllowedLicenses) > 0).
 		SetFailOnInstallationErrors(*repository.FailOnSecurityIssues).
-		SetFixableOnly(repository.FixableOnly)
+		SetFixableOnly(repository.FixableOnly).
+		SetScanPhases(repository.ScanPhaseOrder, repository.EarlyExitOnCritical)
 	if cfp.scanDetails, err = cfp.scanDetails.SetMinSeverity(repository.MinSeverity); err != nil {
 		return
 	}
@@ -127,9 +426,157 @@ func (cfp *ScanRepositoryCmd) setCommandPrerequisites(repository *utils.Reposito
 	cfp.scanDetails.Git.RepositoryCloneUrl = repositoryInfo.CloneInfo.HTTP
 	// Set the flag for aggregating fixes to generate a unified pull request for fixing vulnerabilities
 	cfp.aggregateFixes = repository.Git.AggregateFixes
+	cfp.groupFixesByDir = repository.Git.GroupFixesByDir && !cfp.aggregateFixes
+	if repository.Git.GroupFixesByDir && !cfp.groupFixesByDir {
+		log.Debug("The fixes are aggregated into a single pull request, so they aren't grouped by working directory")
+	}
+	if repository.Git.MinPrUpdateInterval != "" {
+		if cfp.minPrUpdateInterval, err = time.ParseDuration(repository.Git.MinPrUpdateInterval); err != nil {
+			return
+		}
+	}
+	cfp.checksumStorage = utils.ChecksumStorage(repository.Git.ChecksumStorage)
+	cfp.writeFixManifest = repository.Git.WriteFixManifest
+	cfp.maxPackagesPerPr = repository.Git.MaxPackagesPerPr
+	cfp.pullRequestSink = nil
+	if repository.Git.PullRequestSink == string(utils.FilePullRequestSink) {
+		// The path is resolved before cloning, as the clone changes the working directory
+		var sinkFilePath string
+		if sinkFilePath, err = filepath.Abs(repository.Git.PullRequestSinkFile); err != nil {
+			return
+		}
+		cfp.pullRequestSink = &filePullRequestSink{cfp: cfp, filePath: sinkFilePath}
+	}
+	// The operations of the file sink are written in order, so only the pull requests of the Git provider are opened concurrently
+	cfp.pullRequestsQueue = nil
+	if repository.Git.PrConcurrency > 1 && cfp.pullRequestSink == nil {
+		cfp.pullRequestsQueue = newPullRequestsQueue(repository.Git.PrConcurrency, pullRequestsRequestInterval, cfp.scanDetails.NewVcsClient)
+	}
+	cfp.multipleWorkingDirs = countWorkingDirs(repository.Projects) > 1
+	cfp.fixVersionCeilingPolicy = utils.FixVersionCeilingPolicy(repository.FixVersionCeilingPolicy)
+	cfp.maxVersionJump = nil
+	if repository.MaxVersionJump != "" {
+		if cfp.maxVersionJump, err = utils.ParseMaxVersionJump(repository.MaxVersionJump); err != nil {
+			return
+		}
+	}
+	if cfp.versionRegistries, err = utils.ParseVersionRegistries(repository.VerifyVersionAvailability); err != nil {
+		return
+	}
+	cfp.provenanceEcosystems = repository.VerifyProvenance
+	cfp.blockFailedProvenance = repository.BlockFailedProvenance
+	cfp.runtimeVersions = repository.GetRuntimeVersions()
+	cfp.metadataLookupPolicy = utils.MetadataLookupPolicy(repository.MetadataLookupPolicy)
+	cfp.resolveFixVersionRanges = repository.ResolveFixVersionRanges
+	cfp.preferStableFixVersion = repository.PreferStableFixVersion
+	cfp.onUnsupportedTech = utils.UnsupportedTechPolicy(repository.OnUnsupportedTech)
+	cfp.onPartialScan = utils.PartialScanPolicy(repository.OnPartialScan)
+	cfp.fixSource = utils.FixSource(repository.FixSource)
+	cfp.cvssVersionPreference = utils.CvssVersion(repository.CvssVersionPreference)
+	if cfp.fixSource == utils.ViolationsFixSource && len(repository.Watches) == 0 && repository.JFrogProjectKey == "" {
+		log.Warn(fmt.Sprintf("%s is set to %s, but no watches or JFrog project are configured. Without them no violations are detected, so no vulnerabilities will be fixed", utils.FixSourceEnv, utils.ViolationsFixSource))
+	}
+	cfp.onlyCves, cfp.excludeCves, cfp.ignoreRules = repository.OnlyCves, repository.ExcludeCves, repository.IgnoreRules
+	cfp.excludeWorkspacePackages = repository.ExcludeWorkspacePackages != nil && *repository.ExcludeWorkspacePackages
+	// Set the flag for acting only on vulnerabilities that are new since the last successful run
+	cfp.onlyNewVulnerabilities = repository.OnlyNewVulnerabilities
+	// Set the flag for verifying the remediation of merged fix pull requests
+	cfp.verifyAfterMerge = repository.VerifyAfterMerge
+	// Security alerts are only available on GitHub
+	cfp.linkSecurityAlerts = repository.LinkSecurityAlerts && repository.GitProvider == vcsutils.GitHub
+	if repository.LinkSecurityAlerts && !cfp.linkSecurityAlerts {
+		log.Debug("Linking security alerts is not supported on", repository.GitProvider.String())
+	}
+	// The merged pull requests are only listed on GitHub
+	cfp.detectRegressions = repository.DetectRegressions && repository.GitProvider == vcsutils.GitHub
+	if repository.DetectRegressions && !cfp.detectRegressions {
+		log.Debug("Detecting regressions is not supported on", repository.GitProvider.String())
+	}
+	// Commit comments are only available on GitHub
+	cfp.commentOnCommit = repository.CommentOnCommit && repository.GitProvider == vcsutils.GitHub
+	if repository.CommentOnCommit && !cfp.commentOnCommit {
+		log.Debug("Commenting on commits is not supported on", repository.GitProvider.String())
+	}
+	cfp.redactCommitComment = repository.IsRedacted(utils.CommitCommentSummaryTarget)
+	// Issues are only opened on GitHub
+	cfp.createIssuesForUnfixable = repository.CreateIssuesForUnfixable && repository.GitProvider == vcsutils.GitHub
+	if repository.CreateIssuesForUnfixable && !cfp.createIssuesForUnfixable {
+		log.Debug("Opening issues for unfixable vulnerabilities is not supported on", repository.GitProvider.String())
+	}
+	// Draft pull requests are only opened on GitHub and GitLab
+	cfp.draftOnBreaking = repository.DraftOnBreaking && (repository.GitProvider == vcsutils.GitHub || repository.GitProvider == vcsutils.GitLab)
+	if repository.DraftOnBreaking && !cfp.draftOnBreaking {
+		log.Debug("Opening draft pull requests is not supported on", repository.GitProvider.String())
+	}
+	// The SBOM paths are resolved before cloning, as the clone changes the working directory
+	if cfp.sbomOutput, err = getAbsPathIfProvided(repository.SbomOutput); err != nil {
+		return
+	}
+	if cfp.fixedSbomOutput, err = getAbsPathIfProvided(repository.FixedSbomOutput); err != nil {
+		return
+	}
+	if cfp.inputSbom, err = getAbsPathIfProvided(repository.InputSbom); err != nil {
+		return
+	}
+	if cfp.junitOutput, err = getAbsPathIfProvided(repository.JunitOutput); err != nil {
+		return
+	}
+	cfp.junitFailureSeverity = severityutils.Severity(repository.JunitFailureSeverity)
+	if cfp.htmlReport, err = getAbsPathIfProvided(repository.HtmlReport); err != nil {
+		return
+	}
+	if cfp.crossBranchReport, err = getAbsPathIfProvided(repository.CrossBranchReport); err != nil {
+		return
+	}
+	if cfp.patchesDir, err = getAbsPathIfProvided(repository.EmitPatches); err != nil {
+		return
+	}
+	cfp.betweenDirsCommand = repository.BetweenDirsCommand
+	cfp.resolveCommand = repository.ResolveCommand
+	cfp.applicabilitySeverityAdjustment = nil
+	if repository.ApplicabilitySeverityAdjust != "" {
+		if cfp.applicabilitySeverityAdjustment, err = utils.ParseApplicabilitySeverityAdjustment(repository.ApplicabilitySeverityAdjust); err != nil {
+			return
+		}
+	}
+	cfp.slaPolicy = nil
+	if repository.SlaPolicy != "" {
+		if cfp.slaPolicy, err = utils.ParseSlaPolicy(repository.SlaPolicy); err != nil {
+			return
+		}
+	}
+	cfp.stateRetention = 0
+	if repository.StateRetention != "" {
+		if cfp.stateRetention, err = utils.ParseStateRetention(repository.StateRetention); err != nil {
+			return
+		}
+	}
+	// The state directory is resolved before cloning, as the clone changes the working directory
+	if repository.StateDir != "" {
+		if cfp.stateDir, err = getAbsPathIfProvided(repository.StateDir); err != nil {
+			return
+		}
+	} else if (cfp.onlyNewVulnerabilities || cfp.verifyAfterMerge || cfp.slaPolicy != nil) && cfp.stateDir == "" {
+		if cfp.stateDir, err = filepath.Abs(utils.DefaultStateDir); err != nil {
+			return
+		}
+		log.Info(fmt.Sprintf("The Frogbot state is kept in %s. Unless the directory is cached between runs, set %s to a directory on persistent storage", cfp.stateDir, utils.StateDirEnv))
+	}
 	// Set the outputwriter interface for the relevant vcs git provider
-	cfp.OutputWriter = outputwriter.GetCompatibleOutputWriter(repository.GitProvider)
+	cfp.OutputWriter = outputwriter.GetOutputWriter(repository.GitProvider, outputwriter.OutputFormat(repository.OutputFormat))
 	cfp.OutputWriter.SetSizeLimit(client)
+	cfp.OutputWriter.SetShowApplicabilityEvidence(repository.ShowApplicabilityEvidence)
+	cfp.OutputWriter.SetPullRequestBodySections(repository.PullRequestBodySections)
+	cfp.OutputWriter.SetTestMatrixNote(repository.TestMatrixNote)
+	cfp.testMatrixNoteFile = repository.TestMatrixNoteFile
+	cfp.packageReviewers = repository.PackageReviewers
+	cfp.onExistingBranch = utils.ExistingBranchPolicy(repository.OnExistingBranch)
+	cfp.fixLicenseViolations, cfp.allowedLicenses = repository.FixLicenseViolations, repository.AllowedLicenses
+	// The fix branches on a push remote can't be inspected through the VCS client, so their staleness is unknown
+	cfp.autoRebaseStalePrs = repository.AutoRebaseStalePrs && repository.PushRemoteUrl == ""
+	if repository.AutoRebaseStalePrs && !cfp.autoRebaseStalePrs {
+		log.Warn("Rebasing stale fix pull requests is not supported when the fix branches are pushed to a push remote")
+	}
 	// Set the git client to perform git operations
 	cfp.gitManager, err = utils.NewGitManager().
 		SetAuth(cfp.scanDetails.Username, cfp.scanDetails.Token).
@@ -138,21 +585,119 @@ func (cfp *ScanRepositoryCmd) setCommandPrerequisites(repository *utils.Reposito
 	if err != nil {
 		return
 	}
-	_, err = cfp.gitManager.SetGitParams(cfp.scanDetails.Git)
+	if _, err = cfp.gitManager.SetGitParams(cfp.scanDetails.Git); err != nil {
+		return
+	}
+	cfp.gitManager.SetCvssVersionPreference(cfp.cvssVersionPreference).SetStateDir(cfp.stateDir)
+	// Push the fix branches to a separate remote, if provided. The pull requests are still opened on the repository itself.
+	if cfp.scanDetails.Git.PushRemoteUrl != "" {
+		log.Info("Fix branches will be pushed to the configured push remote")
+		cfp.gitManager.SetPushRemote(cfp.scanDetails.Git.PushRemoteUrl, cfp.scanDetails.Username, cfp.scanDetails.Git.PushRemoteToken)
+		if !cfp.dryRun {
+			err = cfp.gitManager.ValidatePushRemote()
+		}
+	}
 	return
 }
 
-func (cfp *ScanRepositoryCmd) scanAndFixProject(repository *utils.Repository) error {
+// Groups the repository projects, so that projects sharing a lockfile (e.g. npm workspaces) are scanned and fixed together.
+// Fixing such projects separately would make the fixes of one project override the lockfile changes of the other.
+func (cfp *ScanRepositoryCmd) groupProjectsBySharedLockfile(repository *utils.Repository) (projectsGroups [][]*utils.Project, err error) {
+	groupSharedLockfiles := repository.GroupSharedLockfiles != nil && *repository.GroupSharedLockfiles
+	lockfilesGroups := make(map[string]int)
+	for i := range repository.Projects {
+		project := &repository.Projects[i]
+		if !groupSharedLockfiles {
+			projectsGroups = append(projectsGroups, []*utils.Project{project})
+			continue
+		}
+		groupIndex := -1
+		var projectLockfiles []string
+		for _, fullPathWd := range utils.GetFullPathWorkingDirs(project.WorkingDirs, cfp.baseWd) {
+			var lockfile string
+			if lockfile, err = utils.GetSharedLockfilePath(fullPathWd, cfp.baseWd); err != nil {
+				return
+			}
+			if lockfile == "" {
+				continue
+			}
+			projectLockfiles = append(projectLockfiles, lockfile)
+			if index, exists := lockfilesGroups[lockfile]; exists && groupIndex == -1 {
+				groupIndex = index
+			}
+		}
+		if groupIndex == -1 {
+			projectsGroups = append(projectsGroups, []*utils.Project{project})
+			groupIndex = len(projectsGroups) - 1
+		} else {
+			log.Info(fmt.Sprintf("Project %v shares a lockfile with a previous project, their vulnerabilities will be fixed together", project.WorkingDirs))
+			projectsGroups[groupIndex] = append(projectsGroups[groupIndex], project)
+		}
+		for _, lockfile := range projectLockfiles {
+			if _, exists := lockfilesGroups[lockfile]; !exists {
+				lockfilesGroups[lockfile] = groupIndex
+			}
+		}
+	}
+	return
+}
+
+// Scans all the given projects and fixes the vulnerabilities found in them together.
+func (cfp *ScanRepositoryCmd) scanAndFixProjects(repository *utils.Repository, projects []*utils.Project) error {
 	var fixNeeded bool
 	// A map that contains the full project paths as a keys
 	// The value is a map of vulnerable package names -> the scanDetails of the vulnerable packages.
 	// That means we have a map of all the vulnerabilities that were found in a specific folder, along with their full scanDetails.
 	vulnerabilitiesByPathMap := make(map[string]map[string]*utils.VulnerabilityDetails)
+	cfp.scannedWorkingDirs = 0
+	cfp.excludedPackages = make(map[string]outputwriter.PackageStatusRow)
+	for _, project := range projects {
+		cfp.scanDetails.Project = project
+		projectFixNeeded, err := cfp.scanProject(repository, vulnerabilitiesByPathMap)
+		if err != nil {
+			return err
+		}
+		fixNeeded = fixNeeded || projectFixNeeded
+	}
+	if cfp.verifyAfterMerge {
+		cfp.escalatePersistingVulnerabilities(vulnerabilitiesByPathMap)
+	}
+	if len(cfp.securityAlerts) > 0 {
+		cfp.linkVulnerabilitiesToSecurityAlerts(vulnerabilitiesByPathMap)
+	}
+	if len(cfp.mergedFixes) > 0 {
+		cfp.flagRegressions(vulnerabilitiesByPathMap)
+	}
+	if cfp.onlyNewVulnerabilities {
+		fixNeeded = cfp.excludeBaselineVulnerabilities(vulnerabilitiesByPathMap)
+	}
+	if cfp.fixedPullRequest != nil {
+		fixNeeded = cfp.excludeDependenciesUnchangedByPullRequest(vulnerabilitiesByPathMap) && fixNeeded
+	}
+	if cfp.fixedSbomOutput != "" || cfp.junitOutput != "" || cfp.htmlReport != "" {
+		for _, vulnerabilities := range vulnerabilitiesByPathMap {
+			cfp.sbomFixes = append(cfp.sbomFixes, maps.Values(vulnerabilities)...)
+		}
+	}
+	if fixNeeded {
+		return cfp.fixVulnerablePackages(repository, vulnerabilitiesByPathMap)
+	}
+	return nil
+}
+
+// Scans the working directories of the current project and adds the found vulnerabilities to vulnerabilitiesByPathMap.
+func (cfp *ScanRepositoryCmd) scanProject(repository *utils.Repository, vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) (fixNeeded bool, err error) {
 	projectFullPathWorkingDirs := utils.GetFullPathWorkingDirs(cfp.scanDetails.Project.WorkingDirs, cfp.baseWd)
 	for _, fullPathWd := range projectFullPathWorkingDirs {
-		scanResults, err := cfp.scan(fullPathWd)
+		if err = cfp.prepareWorkingDirScan(); err != nil {
+			return false, err
+		}
+		scanResults, timedOut, err := cfp.scanWorkingDir(fullPathWd)
 		if err != nil {
-			return err
+			return false, err
+		}
+		if timedOut {
+			continue
 		}
 		if cfp.analyticsService.ShouldReportEvents() {
 			cfp.analyticsService.AddScanFindingsToXscAnalyticsGeneralEventFinalize(scanResults.CountScanResultsFindings())
@@ -166,37 +711,90 @@ func (cfp *ScanRepositoryCmd) scanAndFixProject(repository *utils.Repository) er
 			}
 		}
 
+		if cfp.excludeWorkspacePackages {
+			if cfp.workspacePackages, err = utils.GetWorkspacePackages(fullPathWd); err != nil {
+				return false, err
+			}
+		}
 		// Prepare the vulnerabilities map for each working dir path
 		currPathVulnerabilities, err := cfp.getVulnerabilitiesMap(scanResults, scanResults.IsMultipleProject())
 		if err != nil {
-			return err
+			return false, err
 		}
+		cfp.excludeInstalledFixes(fullPathWd, currPathVulnerabilities)
 		if len(currPathVulnerabilities) > 0 {
 			fixNeeded = true
 		}
 		vulnerabilitiesByPathMap[fullPathWd] = currPathVulnerabilities
 	}
-	if fixNeeded {
-		return cfp.fixVulnerablePackages(repository, vulnerabilitiesByPathMap)
-	}
-	return nil
+	return
 }
 
 // Audit the dependencies of the current commit.
 func (cfp *ScanRepositoryCmd) scan(currentWorkingDir string) (*securityutils.Results, error) {
-	// Audit commit code
-	auditResults, err := cfp.scanDetails.RunInstallAndAudit(currentWorkingDir)
+	auditResults, err := cfp.audit(currentWorkingDir)
+	return cfp.processAuditResults(auditResults, err, currentWorkingDir)
+}
+
+// Audits the dependencies of the working directory, or the components of the input SBOM if provided
+func (cfp *ScanRepositoryCmd) audit(currentWorkingDir string) (*securityutils.Results, error) {
+	return cfp.scanDetails.NewWorkingDirAudit(currentWorkingDir, cfp.inputSbom).Run()
+}
+
+// Handles the results of the audit of the working directory, and records the technologies that were detected in it
+func (cfp *ScanRepositoryCmd) processAuditResults(auditResults *securityutils.Results, err error, currentWorkingDir string) (*securityutils.Results, error) {
 	if err != nil {
-		return nil, err
+		if auditResults, err = cfp.handlePartialScan(auditResults, err, currentWorkingDir); err != nil {
+			return nil, err
+		}
 	}
 	log.Info("Xray scan completed")
 	contextualAnalysisResultsExists := len(auditResults.ExtendedScanResults.ApplicabilityScanResults) > 0
 	entitledForJas := auditResults.ExtendedScanResults.EntitledForJas
 	cfp.OutputWriter.SetJasOutputFlags(entitledForJas, contextualAnalysisResultsExists)
-	cfp.projectTech = auditResults.GetScaScannedTechnologies()
+	// Projects that are fixed together may consist of several technologies
+	for _, tech := range auditResults.GetScaScannedTechnologies() {
+		if !slices.Contains(cfp.projectTech, tech) {
+			cfp.projectTech = append(cfp.projectTech, tech)
+		}
+	}
+	if err = cfp.handleUnsupportedTechnologies(auditResults.GetScaScannedTechnologies(), currentWorkingDir); err != nil {
+		return nil, err
+	}
 	return auditResults, nil
 }
 
+// Handles the detected technologies whose vulnerable dependencies Frogbot can't fix, according to the configured unsupported technology policy.
+// Unless the run fails, the vulnerabilities of these technologies are left unfixed.
+func (cfp *ScanRepositoryCmd) handleUnsupportedTechnologies(technologies []techutils.Technology, currentWorkingDir string) error {
+	var unsupportedTechnologies []string
+	for _, tech := range technologies {
+		if !packagehandlers.IsFixSupported(tech) {
+			unsupportedTechnologies = append(unsupportedTechnologies, tech.ToFormal())
+		}
+	}
+	if len(unsupportedTechnologies) == 0 {
+		return nil
+	}
+	workingDir := utils.GetRelativeWd(currentWorkingDir, cfp.baseWd)
+	if workingDir == "" {
+		workingDir = "."
+	}
+	message := fmt.Sprintf("Frogbot doesn't support fixing the vulnerabilities of %s, which was detected in '%s'", strings.Join(unsupportedTechnologies, ", "), workingDir)
+	switch cfp.onUnsupportedTech {
+	case utils.FailUnsupportedTechPolicy:
+		return fmt.Errorf("%s. Set %s to %s or %s to leave its vulnerabilities unfixed", message, utils.OnUnsupportedTechEnv, utils.SkipUnsupportedTechPolicy, utils.WarnUnsupportedTechPolicy)
+	case utils.WarnUnsupportedTechPolicy:
+		log.Warn(message + ". Its vulnerabilities are left unfixed")
+		if cfp.runSummary != nil {
+			cfp.runSummary.AddWarning(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), message)
+		}
+	default:
+		log.Info(message + ". Its vulnerabilities are left unfixed")
+	}
+	return nil
+}
+
 func (cfp *ScanRepositoryCmd) getVulnerabilitiesMap(scanResults *securityutils.Results, isMultipleRoots bool) (map[string]*utils.VulnerabilityDetails, error) {
 	vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(scanResults, isMultipleRoots)
 	if err != nil {
@@ -210,13 +808,88 @@ func (cfp *ScanRepositoryCmd) getVulnerabilitiesMap(scanResults *securityutils.R
 	return vulnerabilitiesMap, nil
 }
 
+// Records the detected vulnerabilities for the next run's baseline and removes the vulnerabilities that already appear in the current baseline.
+// On the first run, when no baseline exists, all vulnerabilities are kept.
+// In aggregate mode, all vulnerabilities are kept if at least one new vulnerability was detected, so the aggregated pull request still covers the previously reported ones.
+// Returns true if any vulnerability is left to fix.
+func (cfp *ScanRepositoryCmd) excludeBaselineVulnerabilities(vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) (fixNeeded bool) {
+	newVulnerabilitiesFound := false
+	for fullPathWd, vulnerabilities := range vulnerabilitiesByPathMap {
+		for packageName, vulnDetails := range vulnerabilities {
+			vulnerabilityKey := cfp.getBaselineVulnerabilityKey(fullPathWd, vulnDetails)
+			cfp.detectedVulnerabilities = append(cfp.detectedVulnerabilities, vulnerabilityKey)
+			if cfp.vulnerabilitiesBaseline == nil || !cfp.vulnerabilitiesBaseline.Contains(vulnerabilityKey) {
+				newVulnerabilitiesFound = true
+				continue
+			}
+			if !cfp.aggregatesFixes() {
+				log.Debug(fmt.Sprintf("Skipping '%s:%s' as it was already detected in the previous run", vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion))
+				delete(vulnerabilities, packageName)
+			}
+		}
+	}
+	if !newVulnerabilitiesFound {
+		log.Info("No new vulnerabilities were detected since the last successful run of", cfp.scanDetails.BaseBranch())
+		return false
+	}
+	for _, vulnerabilities := range vulnerabilitiesByPathMap {
+		if len(vulnerabilities) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes the vulnerable packages whose versions resolved in the lockfile of the working directory already meet their suggested fix version.
+// Xray may flag the version range declared in the descriptor, while the range was resolved to a fixed version at install time, so the fix would change nothing.
+func (cfp *ScanRepositoryCmd) excludeInstalledFixes(fullPathWd string, vulnerabilities map[string]*utils.VulnerabilityDetails) {
+	for packageName, vulnDetails := range vulnerabilities {
+		installedVersions, err := utils.GetLockfileVersions(vulnDetails.Technology, vulnDetails.ImpactedDependencyName, fullPathWd, cfp.baseWd)
+		if err != nil {
+			log.Debug(fmt.Sprintf("Couldn't resolve the installed versions of '%s': %s", vulnDetails.ImpactedDependencyName, err.Error()))
+			continue
+		}
+		if len(installedVersions) == 0 || !isFixVersionInstalled(vulnDetails.Technology, installedVersions, vulnDetails.SuggestedFixedVersion) {
+			continue
+		}
+		log.Info(fmt.Sprintf("Skipping '%s:%s' as the lockfile already resolves it to version %s, which meets the fix version %s", vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion, strings.Join(installedVersions, ", "), vulnDetails.SuggestedFixedVersion))
+		cfp.recordExcludedPackage(&vulnDetails.VulnerabilityOrViolationRow, vulnDetails.SuggestedFixedVersion, "already resolved to a fixed version in the lockfile")
+		delete(vulnerabilities, packageName)
+	}
+}
+
+// Returns true if every version of the package resolved in the lockfile is at least the fix version
+func isFixVersionInstalled(tech techutils.Technology, installedVersions []string, fixVersion string) bool {
+	for _, installedVersion := range installedVersions {
+		if compareVersions(tech, strings.TrimPrefix(installedVersion, "v"), fixVersion) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (cfp *ScanRepositoryCmd) getBaselineVulnerabilityKey(fullPathWd string, vulnDetails *utils.VulnerabilityDetails) string {
+	return filepath.ToSlash(utils.GetRelativeWd(fullPathWd, cfp.baseWd)) + ":" + utils.GetVulnerabiltiesUniqueID(vulnDetails.VulnerabilityOrViolationRow)
+}
+
 func (cfp *ScanRepositoryCmd) fixVulnerablePackages(repository *utils.Repository, vulnerabilitiesByWdMap map[string]map[string]*utils.VulnerabilityDetails) (err error) {
+	if cfp.fixedPullRequest != nil {
+		return cfp.fixPullRequestSourceBranch(vulnerabilitiesByWdMap)
+	}
 	if cfp.aggregateFixes {
 		return cfp.fixIssuesSinglePR(repository, vulnerabilitiesByWdMap)
 	}
+	if cfp.groupFixesByDir {
+		return cfp.fixIssuesByDir(repository, vulnerabilitiesByWdMap)
+	}
 	return cfp.fixIssuesSeparatePRs(repository, vulnerabilitiesByWdMap)
 }
 
+// Determines whether a fix pull request fixes several packages, either of the whole repository or of a single working directory
+func (cfp *ScanRepositoryCmd) aggregatesFixes() bool {
+	return cfp.aggregateFixes || cfp.groupFixesByDir
+}
+
 func (cfp *ScanRepositoryCmd) fixIssuesSeparatePRs(repository *utils.Repository, vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails) error {
 	var err error
 	for fullPath, vulnerabilities := range vulnerabilitiesMap {
@@ -243,8 +916,16 @@ func (cfp *ScanRepositoryCmd) fixProjectVulnerabilities(repository *utils.Reposi
 	}
 
 	// Fix every vulnerability in a separate pull request and branch
-	for _, vulnerability := range vulnerabilities {
-		if e := cfp.fixSinglePackageAndCreatePR(repository, vulnerability); e != nil {
+	for i, vulnerability := range sortByResolvedCves(vulnerabilities, cfp.cvssVersionPreference) {
+		if i > 0 {
+			// Checking out the base branch removes the files the resolve command generated, if the previous fix committed them
+			if e := cfp.runResolveCommand(context.Background(), fullProjectPath); e != nil {
+				err = errors.Join(err, e)
+				return
+			}
+		}
+		if e := cfp.fixSinglePackageAndCreatePR(repository, vulnerability, projectWorkingDir); e != nil {
+			cfp.recordUnsupportedFix(vulnerability, e)
 			err = errors.Join(err, cfp.handleUpdatePackageErrors(e))
 		}
 
@@ -258,6 +939,25 @@ func (cfp *ScanRepositoryCmd) fixProjectVulnerabilities(repository *utils.Reposi
 	return
 }
 
+// Returns the vulnerabilities ordered by the number of CVEs their fix resolves, so the pull requests of the most valuable fixes are opened first.
+// Fixes that resolve the same number of CVEs are ordered by their highest CVSS score in the preferred version.
+func sortByResolvedCves(vulnerabilities map[string]*utils.VulnerabilityDetails, cvssVersionPreference utils.CvssVersion) []*utils.VulnerabilityDetails {
+	sortedVulnerabilities := maps.Values(vulnerabilities)
+	sort.SliceStable(sortedVulnerabilities, func(i, j int) bool {
+		iCves, jCves := len(sortedVulnerabilities[i].ResolvedCves()), len(sortedVulnerabilities[j].ResolvedCves())
+		if iCves != jCves {
+			return iCves > jCves
+		}
+		iScore := utils.GetHighestCvssScore(sortedVulnerabilities[i].VulnerabilityOrViolationRow.Cves, cvssVersionPreference)
+		jScore := utils.GetHighestCvssScore(sortedVulnerabilities[j].VulnerabilityOrViolationRow.Cves, cvssVersionPreference)
+		if iScore != jScore {
+			return iScore > jScore
+		}
+		return sortedVulnerabilities[i].ImpactedDependencyName < sortedVulnerabilities[j].ImpactedDependencyName
+	})
+	return sortedVulnerabilities
+}
+
 func (cfp *ScanRepositoryCmd) fixMultiplePackages(fullProjectPath string, vulnerabilities map[string]*utils.VulnerabilityDetails) (fixedVulnerabilities []*utils.VulnerabilityDetails, err error) {
 	// Update the working directory to the project's current working directory
 	projectWorkingDir := utils.GetRelativeWd(fullProjectPath, cfp.baseWd)
@@ -275,6 +975,7 @@ func (cfp *ScanRepositoryCmd) fixMultiplePackages(fullProjectPath string, vulner
 	}
 	for _, vulnDetails := range vulnerabilities {
 		if e := cfp.updatePackageToFixedVersion(vulnDetails); e != nil {
+			cfp.recordUnsupportedFix(vulnDetails, e)
 			err = errors.Join(err, cfp.handleUpdatePackageErrors(e))
 			continue
 		}
@@ -288,16 +989,113 @@ func (cfp *ScanRepositoryCmd) fixMultiplePackages(fullProjectPath string, vulner
 // If an existing aggregated fix is present, it checks for different scan results.
 // If the scan results are the same, no action is taken.
 // Otherwise, it performs a force push to the same branch and reopens the pull request if it was closed.
-// Only one aggregated pull request should remain open at all times.
+// Only one aggregated pull request should remain open at all times, unless the fixes are split into several pull requests of bounded size.
 func (cfp *ScanRepositoryCmd) fixIssuesSinglePR(repository *utils.Repository, vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails) (err error) {
+	if cfp.maxPackagesPerPr > 0 {
+		if chunks := chunkVulnerabilities(vulnerabilitiesMap, cfp.maxPackagesPerPr); len(chunks) > 1 {
+			return cfp.fixIssuesInChunks(repository, chunks)
+		}
+	}
 	aggregatedFixBranchName := cfp.gitManager.GenerateAggregatedFixBranchName(cfp.scanDetails.BaseBranch(), cfp.projectTech)
-	existingPullRequestDetails, err := cfp.getOpenPullRequestBySourceBranch(aggregatedFixBranchName)
+	existingPullRequestDetails, err := cfp.getOpenPullRequestBySourceBranch(cfp.scanDetails.Client(), aggregatedFixBranchName)
 	if err != nil {
 		return
 	}
 	return cfp.aggregateFixAndOpenPullRequest(repository, vulnerabilitiesMap, aggregatedFixBranchName, existingPullRequestDetails)
 }
 
+// fixIssuesInChunks fixes every chunk of the vulnerabilities in its own aggregated pull request and branch.
+// Each pull request is updated independently, according to the checksum of its own chunk.
+func (cfp *ScanRepositoryCmd) fixIssuesInChunks(repository *utils.Repository, chunks []map[string]map[string]*utils.VulnerabilityDetails) (err error) {
+	defer func() {
+		cfp.aggregatedPullRequestPart = 0
+	}()
+	log.Info(fmt.Sprintf("Splitting the aggregated fix into %d pull requests of up to %d packages each", len(chunks), cfp.maxPackagesPerPr))
+	for i, chunk := range chunks {
+		cfp.aggregatedPullRequestPart = i + 1
+		partFixBranchName := cfp.gitManager.GenerateAggregatedFixPartBranchName(cfp.scanDetails.BaseBranch(), cfp.projectTech, cfp.aggregatedPullRequestPart)
+		existingPullRequestDetails, e := cfp.getOpenPullRequestBySourceBranch(cfp.scanDetails.Client(), partFixBranchName)
+		if e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+		if e = cfp.aggregateFixAndOpenPullRequest(repository, chunk, partFixBranchName, existingPullRequestDetails); e != nil {
+			err = errors.Join(err, e)
+		}
+	}
+	return
+}
+
+// fixIssuesByDir fixes the vulnerabilities of every working directory in its own pull request and branch, across the technologies of the directory.
+// Each pull request is updated independently, according to the checksum of its own directory.
+func (cfp *ScanRepositoryCmd) fixIssuesByDir(repository *utils.Repository, vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails) (err error) {
+	defer func() {
+		cfp.groupedFixDir, cfp.groupedFixTech = "", nil
+	}()
+	fullPaths := maps.Keys(vulnerabilitiesMap)
+	slices.Sort(fullPaths)
+	for _, fullPath := range fullPaths {
+		vulnerabilities := vulnerabilitiesMap[fullPath]
+		if len(vulnerabilities) == 0 {
+			continue
+		}
+		cfp.groupedFixDir, cfp.groupedFixTech = utils.GetRelativeWd(fullPath, cfp.baseWd), getVulnerabilitiesTechnologies(vulnerabilities)
+		dirFixBranchName := cfp.gitManager.GenerateDirFixBranchName(cfp.scanDetails.BaseBranch(), cfp.groupedFixDir)
+		existingPullRequestDetails, e := cfp.getOpenPullRequestBySourceBranch(cfp.scanDetails.Client(), dirFixBranchName)
+		if e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+		if e = cfp.aggregateFixAndOpenPullRequest(repository, map[string]map[string]*utils.VulnerabilityDetails{fullPath: vulnerabilities}, dirFixBranchName, existingPullRequestDetails); e != nil {
+			err = errors.Join(err, fmt.Errorf("the following errors occured while fixing vulnerabilities in '%s':\n%s", fullPath, e))
+		}
+	}
+	return
+}
+
+// Returns the technologies of the vulnerabilities, sorted by name so the titles of the pull requests are kept across runs
+func getVulnerabilitiesTechnologies(vulnerabilities map[string]*utils.VulnerabilityDetails) (technologies []techutils.Technology) {
+	for _, vulnDetails := range vulnerabilities {
+		if vulnDetails.Technology != "" && !slices.Contains(technologies, vulnDetails.Technology) {
+			technologies = append(technologies, vulnDetails.Technology)
+		}
+	}
+	slices.Sort(technologies)
+	return
+}
+
+// Splits the vulnerabilities into chunks of at most chunkSize packages.
+// The packages are sorted by name, and then by working directory, so the same packages land in the same chunk across runs.
+func chunkVulnerabilities(vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails, chunkSize int) (chunks []map[string]map[string]*utils.VulnerabilityDetails) {
+	type packageKey struct {
+		fullPath    string
+		packageName string
+	}
+	var packageKeys []packageKey
+	for fullPath, vulnerabilities := range vulnerabilitiesMap {
+		for packageName := range vulnerabilities {
+			packageKeys = append(packageKeys, packageKey{fullPath: fullPath, packageName: packageName})
+		}
+	}
+	sort.Slice(packageKeys, func(i, j int) bool {
+		if packageKeys[i].packageName != packageKeys[j].packageName {
+			return packageKeys[i].packageName < packageKeys[j].packageName
+		}
+		return packageKeys[i].fullPath < packageKeys[j].fullPath
+	})
+	for i, key := range packageKeys {
+		if i%chunkSize == 0 {
+			chunks = append(chunks, map[string]map[string]*utils.VulnerabilityDetails{})
+		}
+		chunk := chunks[len(chunks)-1]
+		if chunk[key.fullPath] == nil {
+			chunk[key.fullPath] = map[string]*utils.VulnerabilityDetails{}
+		}
+		chunk[key.fullPath][key.packageName] = vulnerabilitiesMap[key.fullPath][key.packageName]
+	}
+	return
+}
+
 // Handles possible error of update package operation
 // When the expected custom error occurs, log to debug.
 // else, return the error
@@ -311,17 +1109,31 @@ func (cfp *ScanRepositoryCmd) handleUpdatePackageErrors(err error) error {
 	case errors.As(err, &errNoChangesToCommit):
 		log.Info(err.Error())
 	default:
+		cfp.reportInstallCommandFailure(err)
 		return err
 	}
 	return nil
 }
 
+// Surfaces the full output of a failed install command, when verbose install errors are enabled.
+// The error itself only includes the tail of the output.
+func (cfp *ScanRepositoryCmd) reportInstallCommandFailure(err error) {
+	var errCommandFailed *utils.ErrCommandFailed
+	if cfp.scanDetails.Project == nil || !cfp.scanDetails.VerboseInstallErrors || !errors.As(err, &errCommandFailed) {
+		return
+	}
+	log.Warn(fmt.Sprintf("The full output of '%s':\n%s", errCommandFailed.Command, errCommandFailed.Output))
+	if cfp.runSummary != nil {
+		cfp.runSummary.AddWarning(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), errCommandFailed.Error())
+	}
+}
+
 // Creates a branch for the fixed package and open pull request against the target branch.
-// In case a branch already exists on remote, we skip it.
-func (cfp *ScanRepositoryCmd) fixSinglePackageAndCreatePR(repository *utils.Repository, vulnDetails *utils.VulnerabilityDetails) (err error) {
+// In case a branch already exists on remote, it is handled according to the existing branch policy, unless its pull request is stale and rebasing stale pull requests is enabled.
+func (cfp *ScanRepositoryCmd) fixSinglePackageAndCreatePR(repository *utils.Repository, vulnDetails *utils.VulnerabilityDetails, projectWorkingDir string) (err error) {
 	fixVersion := vulnDetails.SuggestedFixedVersion
 	log.Debug("Attempting to fix", fmt.Sprintf("%s:%s", vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion), "with", fixVersion)
-	fixBranchName, err := cfp.gitManager.GenerateFixBranchName(cfp.scanDetails.BaseBranch(), vulnDetails.ImpactedDependencyName, fixVersion)
+	fixBranchName, err := cfp.gitManager.GenerateFixBranchName(cfp.scanDetails.BaseBranch(), vulnDetails.ImpactedDependencyName, fixVersion, cfp.getFixBranchWorkingDir(projectWorkingDir))
 	if err != nil {
 		return
 	}
@@ -329,9 +1141,30 @@ func (cfp *ScanRepositoryCmd) fixSinglePackageAndCreatePR(repository *utils.Repo
 	if err != nil {
 		return
 	}
+	var existingPullRequest *vcsclient.PullRequestInfo
+	isRebase := false
 	if existsInRemote {
-		log.Info(fmt.Sprintf("A pull request updating the dependency '%s' to version '%s' already exists. Skipping...", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
-		return
+		if cfp.autoRebaseStalePrs {
+			if existingPullRequest, err = cfp.getStaleFixPullRequest(fixBranchName, cfp.gitManager.GenerateCommitMessage(vulnDetails.ImpactedDependencyName, fixVersion)); err != nil {
+				return
+			}
+			isRebase = existingPullRequest != nil
+		}
+		switch {
+		case existingPullRequest != nil:
+			log.Info(fmt.Sprintf("The base branch advanced since the pull request updating the dependency '%s' to version '%s' was opened. Rebasing the pull request...", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+		case cfp.onExistingBranch == utils.UpdateExistingBranchPolicy:
+			log.Info(fmt.Sprintf("The fix branch '%s' already exists. It will be updated if its content differs from the fix...", fixBranchName))
+			// The branch may have been pushed without a pull request, in which case a pull request is opened for it
+			if existingPullRequest, err = cfp.getOpenPullRequestBySourceBranch(cfp.scanDetails.Client(), fixBranchName); err != nil {
+				return
+			}
+		case cfp.onExistingBranch == utils.NewExistingBranchPolicy:
+			log.Info(fmt.Sprintf("The fix branch '%s' already exists. The fix will be pushed to a new branch if its content differs from the existing branch...", fixBranchName))
+		default:
+			log.Info(fmt.Sprintf("A pull request updating the dependency '%s' to version '%s' already exists. Skipping...", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+			return
+		}
 	}
 
 	workTreeIsClean, err := cfp.gitManager.IsClean()
@@ -351,14 +1184,33 @@ func (cfp *ScanRepositoryCmd) fixSinglePackageAndCreatePR(repository *utils.Repo
 	if err = cfp.updatePackageToFixedVersion(vulnDetails); err != nil {
 		return
 	}
-	if err = cfp.openFixingPullRequest(repository, fixBranchName, vulnDetails); err != nil {
+	if err = cfp.openFixingPullRequest(repository, fixBranchName, existsInRemote, isRebase, existingPullRequest, vulnDetails); err != nil {
 		return errors.Join(fmt.Errorf("failed while creating a fixing pull request for: %s with version: %s with error: ", vulnDetails.ImpactedDependencyName, fixVersion), err)
 	}
-	log.Info(fmt.Sprintf("Created Pull Request updating dependency '%s' to version '%s'", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
 	return
 }
 
-func (cfp *ScanRepositoryCmd) openFixingPullRequest(repository *utils.Repository, fixBranchName string, vulnDetails *utils.VulnerabilityDetails) (err error) {
+// Returns the working directory to tell the fix branch apart by.
+// A repository with a single working directory, as well as the root working directory, keep the fix branch names they had before working directories were told apart.
+func (cfp *ScanRepositoryCmd) getFixBranchWorkingDir(projectWorkingDir string) string {
+	if !cfp.multipleWorkingDirs {
+		return ""
+	}
+	return projectWorkingDir
+}
+
+func countWorkingDirs(projects []utils.Project) (count int) {
+	for _, project := range projects {
+		count += len(project.WorkingDirs)
+	}
+	return
+}
+
+// Commits the fix and opens its pull request.
+// If the fix branch already exists on the remote, the fix is compared with its fix, and nothing is pushed if they are equal, unless its stale pull request is rebased.
+// Otherwise, the branch is replaced by the fix on top of the current base branch and the existing pull request, if provided, is updated,
+// or the fix is pushed to a new branch, according to the existing branch policy.
+func (cfp *ScanRepositoryCmd) openFixingPullRequest(repository *utils.Repository, fixBranchName string, existsInRemote, isRebase bool, existingPullRequest *vcsclient.PullRequestInfo, vulnDetails *utils.VulnerabilityDetails) (err error) {
 	log.Debug("Checking if there are changes to commit")
 	isClean, err := cfp.gitManager.IsClean()
 	if err != nil {
@@ -368,87 +1220,234 @@ func (cfp *ScanRepositoryCmd) openFixingPullRequest(repository *utils.Repository
 		// In instances where a fix is required that Frogbot does not support, the worktree will remain clean, and there will be nothing to push
 		return &utils.ErrNothingToCommit{PackageName: vulnDetails.ImpactedDependencyName}
 	}
+	if err = cfp.writeFixManifestIfNeeded(vulnDetails); err != nil {
+		return
+	}
 	commitMessage := cfp.gitManager.GenerateCommitMessage(vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
 	if err = cfp.gitManager.AddAllAndCommit(commitMessage); err != nil {
 		return
 	}
-	if err = cfp.gitManager.Push(false, fixBranchName); err != nil {
+	forcePush := existsInRemote
+	if existsInRemote {
+		var pushBranchName string
+		if pushBranchName, err = cfp.resolveExistingFixBranch(fixBranchName, isRebase); err != nil || pushBranchName == "" {
+			return
+		}
+		if pushBranchName != fixBranchName {
+			// The fix is pushed to a new branch, so it has no pull request to update, and no branch to replace
+			fixBranchName, existingPullRequest, forcePush = pushBranchName, nil, false
+		}
+	}
+	if err = cfp.handleFixPullRequestContent(repository, fixBranchName, existingPullRequest, forcePush, vulnDetails); err != nil {
+		return
+	}
+	if existingPullRequest != nil {
+		log.Info(fmt.Sprintf("Updated Pull Request updating dependency '%s' to version '%s'", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
 		return
 	}
-	return cfp.handleFixPullRequestContent(repository, fixBranchName, nil, vulnDetails)
+	log.Info(fmt.Sprintf("Created Pull Request updating dependency '%s' to version '%s'", vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion))
+	return
 }
 
-func (cfp *ScanRepositoryCmd) handleFixPullRequestContent(repository *utils.Repository, fixBranchName string, pullRequestInfo *vcsclient.PullRequestInfo, vulnerabilities ...*utils.VulnerabilityDetails) (err error) {
+// Prepares the content of the fix pull request, and publishes the fix branch and the pull request through the pull request sink.
+// forcePush determines whether the fix branch replaces an existing branch of the same name.
+func (cfp *ScanRepositoryCmd) handleFixPullRequestContent(repository *utils.Repository, fixBranchName string, pullRequestInfo *vcsclient.PullRequestInfo, forcePush bool, vulnerabilities ...*utils.VulnerabilityDetails) (err error) {
 	pullRequestTitle, prBody, extraComments, err := cfp.preparePullRequestDetails(vulnerabilities...)
 	if err != nil {
 		return
 	}
-	// Update PR description
-	if pullRequestInfo, err = cfp.createOrUpdatePullRequest(repository, pullRequestInfo, fixBranchName, pullRequestTitle, prBody); err != nil {
-		return
+	// The draft state of an existing pull request is kept, as the VCS client can't change it
+	draft := pullRequestInfo == nil && cfp.draftOnBreaking && slices.ContainsFunc(vulnerabilities, (*utils.VulnerabilityDetails).HasBreakingChanges)
+	operation := &utils.PullRequestOperation{
+		Action:       utils.CreatePullRequestAction,
+		RepoOwner:    cfp.scanDetails.RepoOwner,
+		RepoName:     cfp.scanDetails.RepoName,
+		SourceBranch: fixBranchName,
+		TargetBranch: cfp.scanDetails.BaseBranch(),
+		ForcePush:    forcePush,
+		Draft:        draft,
+		Title:        pullRequestTitle,
+		Body:         prBody,
+		Comments:     extraComments,
+		// The reviewers of all the fixed packages are requested, in addition to the default reviewers of the repository
+		Reviewers: utils.GetPackageReviewers(cfp.packageReviewers, vulnerabilities...),
+	}
+	if cfp.aggregatesFixes() {
+		operation.Checksum = cfp.getRemoteBranchScanHash(prBody)
+	}
+	if pullRequestInfo != nil {
+		operation.Action, operation.PullRequestId, operation.TargetBranch = utils.UpdatePullRequestAction, pullRequestInfo.ID, pullRequestInfo.Target.Name
 	}
-	// Update PR extra comments
-	client := cfp.scanDetails.Client()
-	for _, comment := range extraComments {
-		if err = client.AddPullRequestComment(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, comment, int(pullRequestInfo.ID)); err != nil {
-			err = errors.New("couldn't add pull request comment: " + err.Error())
+	if cfp.dryRun {
+		if err = cfp.renderDryRunPullRequest(fixBranchName, pullRequestTitle, prBody, extraComments, draft); err != nil {
+			return
+		}
+		if err = cfp.recordDryRunPullRequest(operation, vulnerabilities); err != nil {
 			return
 		}
 	}
+	if err = cfp.emitPatch(fixBranchName); err != nil {
+		return
+	}
+	if cfp.pullRequestsQueue != nil {
+		return cfp.queuePullRequest(repository, operation, pullRequestInfo, vulnerabilities)
+	}
+	if pullRequestInfo, err = cfp.getPullRequestSink().Publish(repository, operation, pullRequestInfo); err != nil {
+		return
+	}
+	cfp.recordPublishedPullRequest(operation, pullRequestInfo, vulnerabilities)
 	return
 }
 
-func (cfp *ScanRepositoryCmd) createOrUpdatePullRequest(repository *utils.Repository, pullRequestInfo *vcsclient.PullRequestInfo, fixBranchName, pullRequestTitle, prBody string) (prInfo *vcsclient.PullRequestInfo, err error) {
+// Records the published pull request in the run summary, and for verifying its remediation after it's merged.
+// The pull requests that are written to a file aren't known yet, so they aren't recorded.
+func (cfp *ScanRepositoryCmd) recordPublishedPullRequest(operation *utils.PullRequestOperation, pullRequestInfo *vcsclient.PullRequestInfo, vulnerabilities []*utils.VulnerabilityDetails) {
+	if pullRequestInfo == nil {
+		return
+	}
+	cfp.addPullRequestToRunSummary(operation.Title, pullRequestInfo.URL, operation.Action == utils.UpdatePullRequestAction)
+	if cfp.verifyAfterMerge {
+		cfp.recordFixPullRequest(pullRequestInfo, operation.SourceBranch, vulnerabilities)
+	}
+}
+
+func (cfp *ScanRepositoryCmd) createOrUpdatePullRequest(client vcsclient.VcsClient, repository *utils.Repository, pullRequestInfo *vcsclient.PullRequestInfo, fixBranchName, pullRequestTitle, prBody string, draft bool) (prInfo *vcsclient.PullRequestInfo, err error) {
 	if pullRequestInfo == nil {
 		log.Info("Creating Pull Request from:", fixBranchName, "to:", cfp.scanDetails.BaseBranch())
-		if err = cfp.scanDetails.Client().CreatePullRequest(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, fixBranchName, cfp.scanDetails.BaseBranch(), pullRequestTitle, prBody); err != nil {
+		if err = cfp.createPullRequest(client, fixBranchName, pullRequestTitle, prBody, draft); err != nil {
 			return
 		}
-		return cfp.getOpenPullRequestBySourceBranch(fixBranchName)
+		return cfp.getOpenPullRequestBySourceBranch(client, fixBranchName)
 	}
 	log.Info("Updating Pull Request from:", fixBranchName, "to:", cfp.scanDetails.BaseBranch())
-	if err = cfp.scanDetails.Client().UpdatePullRequest(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, pullRequestTitle, prBody, pullRequestInfo.Target.Name, int(pullRequestInfo.ID), vcsutils.Open); err != nil {
+	if err = client.UpdatePullRequest(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, pullRequestTitle, prBody, pullRequestInfo.Target.Name, int(pullRequestInfo.ID), vcsutils.Open); err != nil {
 		return
 	}
 	// Delete old extra comments
-	return pullRequestInfo, utils.DeletePullRequestComments(repository, cfp.scanDetails.Client(), int(pullRequestInfo.ID))
+	return pullRequestInfo, utils.DeletePullRequestComments(repository, client, int(pullRequestInfo.ID))
+}
+
+func (cfp *ScanRepositoryCmd) createPullRequest(client vcsclient.VcsClient, fixBranchName, pullRequestTitle, prBody string, draft bool) error {
+	if !draft {
+		return client.CreatePullRequest(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, fixBranchName, cfp.scanDetails.BaseBranch(), pullRequestTitle, prBody)
+	}
+	log.Info("The fix introduces breaking changes, so the pull request is opened as a draft")
+	if cfp.scanDetails.GitProvider == vcsutils.GitHub {
+		waitForRateLimit(client)
+		return utils.CreateGitHubDraftPullRequest(cfp.scanDetails.APIEndpoint, cfp.scanDetails.Token, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, fixBranchName, cfp.scanDetails.BaseBranch(), pullRequestTitle, prBody)
+	}
+	// GitLab opens a merge request whose title starts with 'Draft:' as a draft
+	return client.CreatePullRequest(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, fixBranchName, cfp.scanDetails.BaseBranch(), gitLabDraftTitlePrefix+pullRequestTitle, prBody)
+}
+
+func (cfp *ScanRepositoryCmd) addPullRequestToRunSummary(pullRequestTitle, pullRequestUrl string, updated bool) {
+	cfp.branchPullRequests = append(cfp.branchPullRequests, outputwriter.FixPullRequestRow{Title: pullRequestTitle, Url: pullRequestUrl})
+	if cfp.runSummary == nil {
+		return
+	}
+	cfp.runSummary.AddPullRequest(fmt.Sprintf("%s/%s", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName), pullRequestTitle, pullRequestUrl, updated)
+}
+
+// Returns the message of the commit that fixes several packages, either of the whole repository or of the working directory whose fixes are grouped
+func (cfp *ScanRepositoryCmd) generateAggregatedCommitMessage() string {
+	if cfp.groupFixesByDir {
+		return cfp.gitManager.GenerateDirCommitMessage(cfp.groupedFixTech, cfp.groupedFixDir)
+	}
+	return cfp.gitManager.GenerateAggregatedCommitMessage(cfp.projectTech)
 }
 
 // openAggregatedPullRequest handles the opening or updating of a pull request when the aggregate mode is active.
 // If a pull request is already open, Frogbot will update the branch and the pull request body.
 func (cfp *ScanRepositoryCmd) openAggregatedPullRequest(repository *utils.Repository, fixBranchName string, pullRequestInfo *vcsclient.PullRequestInfo, vulnerabilities []*utils.VulnerabilityDetails) (err error) {
-	commitMessage := cfp.gitManager.GenerateAggregatedCommitMessage(cfp.projectTech)
-	if err = cfp.gitManager.AddAllAndCommit(commitMessage); err != nil {
+	if err = cfp.writeFixManifestIfNeeded(vulnerabilities...); err != nil {
 		return
 	}
-	if err = cfp.gitManager.Push(true, fixBranchName); err != nil {
+	if err = cfp.gitManager.AddAllAndCommit(cfp.generateAggregatedCommitMessage()); err != nil {
 		return
 	}
-	return cfp.handleFixPullRequestContent(repository, fixBranchName, pullRequestInfo, vulnerabilities...)
+	return cfp.handleFixPullRequestContent(repository, fixBranchName, pullRequestInfo, true, vulnerabilities...)
 }
 
-func (cfp *ScanRepositoryCmd) preparePullRequestDetails(vulnerabilitiesDetails ...*utils.VulnerabilityDetails) (prTitle, prBody string, otherComments []string, err error) {
-	if cfp.dryRun && cfp.aggregateFixes {
-		// For testings, don't compare pull request body as scan results order may change.
-		return cfp.gitManager.GenerateAggregatedPullRequestTitle(cfp.projectTech), "", []string{}, nil
+// Reads the test matrix note from the configured file in the cloned branch, so each branch is described by its own file.
+// Failing to read the file doesn't fail the run, as the note is only informational.
+func (cfp *ScanRepositoryCmd) loadTestMatrixNote() {
+	content, err := os.ReadFile(filepath.Join(cfp.baseWd, filepath.Clean(filepath.FromSlash(cfp.testMatrixNoteFile))))
+	if err != nil {
+		log.Warn(fmt.Sprintf("Failed to read the test matrix note from %s: %s", cfp.testMatrixNoteFile, err.Error()))
+		content = nil
 	}
-	vulnerabilitiesRows := utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilitiesDetails)
+	cfp.OutputWriter.SetTestMatrixNote(string(content))
+}
 
-	prBody, extraComments := utils.GenerateFixPullRequestDetails(vulnerabilitiesRows, cfp.OutputWriter)
+// Loads the open security alerts of the repository. Failing to load them doesn't fail the run, as the alerts are only referenced from the pull requests.
+func (cfp *ScanRepositoryCmd) loadSecurityAlerts() {
+	var err error
+	if cfp.securityAlerts, err = utils.GetGitHubSecurityAlerts(cfp.scanDetails.APIEndpoint, cfp.scanDetails.Token, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName); err != nil {
+		log.Warn(err.Error())
+	}
+}
 
-	if cfp.aggregateFixes {
+func (cfp *ScanRepositoryCmd) linkVulnerabilitiesToSecurityAlerts(vulnerabilitiesByPathMap map[string]map[string]*utils.VulnerabilityDetails) {
+	for _, vulnerabilities := range vulnerabilitiesByPathMap {
+		for _, vulnDetails := range vulnerabilities {
+			vulnDetails.SetSecurityAlerts(cfp.securityAlerts)
+		}
+	}
+}
+
+func (cfp *ScanRepositoryCmd) preparePullRequestDetails(vulnerabilitiesDetails ...*utils.VulnerabilityDetails) (prTitle, prBody string, otherComments []string, err error) {
+	var extraComments []string
+	if cfp.aggregatesFixes() {
+		prBody, extraComments = utils.GenerateAggregatedFixPullRequestDetails(vulnerabilitiesDetails, cfp.aggregatedSkippedPackages, cfp.OutputWriter)
+	} else {
+		prBody, extraComments = utils.GenerateFixPullRequestDetails(vulnerabilitiesDetails, cfp.OutputWriter)
+	}
+	// The fixed packages are recorded, so a fix that is reverted after the pull request is merged can be detected
+	prBody += utils.FixedPackagesMarker(vulnerabilitiesDetails)
+
+	if cfp.aggregatesFixes() {
 		var scanHash string
-		if scanHash, err = utils.VulnerabilityDetailsToMD5Hash(vulnerabilitiesRows...); err != nil {
+		if scanHash, err = cfp.getFixPullRequestChecksum(vulnerabilitiesDetails...); err != nil {
 			return
 		}
-		return cfp.gitManager.GenerateAggregatedPullRequestTitle(cfp.projectTech), prBody + outputwriter.MarkdownComment(fmt.Sprintf("Checksum: %s", scanHash)), extraComments, nil
+		prBody += utils.HiddenMarker(fmt.Sprintf("Checksum: %s", scanHash), cfp.checksumStorage)
+		if cfp.minPrUpdateInterval > 0 {
+			// The update time is recorded, so the next updates can be deferred until the interval passes
+			prBody += utils.HiddenMarker(fmt.Sprintf("%s%s", lastUpdatePrefix, time.Now().UTC().Format(time.RFC3339)), cfp.checksumStorage)
+		}
+		if cfp.groupFixesByDir {
+			return cfp.gitManager.GenerateDirPullRequestTitle(cfp.groupedFixTech, cfp.groupedFixDir, vulnerabilitiesDetails...), prBody, extraComments, nil
+		}
+		if cfp.aggregatedPullRequestPart > 0 {
+			return cfp.gitManager.GenerateAggregatedPartPullRequestTitle(cfp.projectTech, cfp.aggregatedPullRequestPart, vulnerabilitiesDetails...), prBody, extraComments, nil
+		}
+		return cfp.gitManager.GenerateAggregatedPullRequestTitle(cfp.projectTech, vulnerabilitiesDetails...), prBody, extraComments, nil
 	}
 	// In separate pull requests there is only one vulnerability
 	vulnDetails := vulnerabilitiesDetails[0]
-	pullRequestTitle := cfp.gitManager.GeneratePullRequestTitle(vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
+	pullRequestTitle := cfp.gitManager.GenerateFixPullRequestTitle(vulnDetails)
 	return pullRequestTitle, prBody, extraComments, nil
 }
 
+// Writes the pull request that would be opened on a dry run, so its title, body and checksum can be reviewed before going live.
+func (cfp *ScanRepositoryCmd) renderDryRunPullRequest(fixBranchName, pullRequestTitle, prBody string, extraComments []string, draft bool) (err error) {
+	output := cfp.dryRunOutput
+	if output == nil {
+		output = os.Stdout
+	}
+	var contentBuilder strings.Builder
+	contentBuilder.WriteString(fmt.Sprintf("%s\nPull request from: %s to: %s\nTitle: %s\n", dryRunSeparator, fixBranchName, cfp.scanDetails.BaseBranch(), pullRequestTitle))
+	if draft {
+		contentBuilder.WriteString("Draft: true\n")
+	}
+	contentBuilder.WriteString(fmt.Sprintf("%s\n%s\n", dryRunSeparator, prBody))
+	for i, comment := range extraComments {
+		contentBuilder.WriteString(fmt.Sprintf("%s\nComment %d:\n%s\n", dryRunSeparator, i+1, comment))
+	}
+	_, err = io.WriteString(output, contentBuilder.String())
+	return
+}
+
 func (cfp *ScanRepositoryCmd) cloneRepositoryAndCheckoutToBranch() (tempWd string, restoreDir func() error, err error) {
 	if cfp.dryRun {
 		tempWd = filepath.Join(cfp.dryRunRepoPath, cfp.scanDetails.RepoName)
@@ -460,35 +1459,72 @@ func (cfp *ScanRepositoryCmd) cloneRepositoryAndCheckoutToBranch() (tempWd strin
 	}
 	log.Debug("Created temp working directory:", tempWd)
 
-	// Clone the content of the repo to the new working directory
+	// Clone the content of the repo to the new working directory.
+	// The fixes are committed in this fresh clone rather than in the checkout Frogbot runs in, so uncommitted changes
+	// that prior CI steps left in that checkout never reach the fix commits, and a dirty working tree needs no handling.
 	if err = cfp.gitManager.Clone(tempWd, cfp.scanDetails.BaseBranch()); err != nil {
 		return
 	}
 
-	// 'CD' into the temp working directory
-	restoreDir, err = utils.Chdir(tempWd)
+	// The working directories are discovered relative to the repository subpath, if provided
+	if cfp.baseWd, err = getRepositoryBaseWd(tempWd, cfp.scanDetails.Git.RepoSubpath); err != nil {
+		return
+	}
+
+	// 'CD' into the base working directory
+	restoreDir, err = utils.Chdir(cfp.baseWd)
 	return
 }
 
+// Returns the directory that is treated as the root of the cloned repository.
+// If a repository subpath is provided, the directory is resolved inside the cloned repository, while the git operations still target the repository itself.
+func getRepositoryBaseWd(clonedRepoDir, repoSubpath string) (string, error) {
+	if repoSubpath == "" {
+		return clonedRepoDir, nil
+	}
+	baseWd := filepath.Join(clonedRepoDir, filepath.FromSlash(repoSubpath))
+	exists, err := fileutils.IsDirExists(baseWd, false)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("the repository subpath '%s' doesn't exist in the repository", repoSubpath)
+	}
+	log.Info("Using", repoSubpath, "as the repository root")
+	return baseWd, nil
+}
+
 // Create a vulnerabilities map - a map with 'impacted package' as a key and all the necessary information of this vulnerability as value.
 func (cfp *ScanRepositoryCmd) createVulnerabilitiesMap(scanResults *securityutils.Results, isMultipleRoots bool) (map[string]*utils.VulnerabilityDetails, error) {
 	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
 	for _, scanResult := range scanResults.GetScaScansXrayResults() {
-		if len(scanResult.Vulnerabilities) > 0 {
+		if len(scanResult.Vulnerabilities) > 0 && cfp.fixSource != utils.ViolationsFixSource {
 			vulnerabilities, err := securityutils.PrepareVulnerabilities(scanResult.Vulnerabilities, scanResults, isMultipleRoots, true)
 			if err != nil {
 				return nil, err
 			}
+			utils.IdentifyGhsaOnlyVulnerabilities(vulnerabilities, utils.GetGhsaIds(scanResult))
+			vulnerabilities = utils.ExcludeWorkspacePackages(vulnerabilities, cfp.workspacePackages)
+			vulnerabilities = cfp.filterByCves(vulnerabilities)
+			cfp.applicabilitySeverityAdjustment.AdjustSeverityScores(vulnerabilities)
+			utils.ConvertSarifPathsToRelative(&utils.IssuesCollection{Vulnerabilities: vulnerabilities}, cfp.baseWd)
+			cfp.recordBranchVulnerabilities(vulnerabilities)
 			for i := range vulnerabilities {
 				if err = cfp.addVulnerabilityToFixVersionsMap(&vulnerabilities[i], vulnerabilitiesMap); err != nil {
 					return nil, err
 				}
 			}
-		} else if len(scanResult.Violations) > 0 {
+		} else if len(scanResult.Violations) > 0 && cfp.fixSource != utils.VulnerabilitiesFixSource {
 			violations, _, _, err := securityutils.PrepareViolations(scanResult.Violations, scanResults, isMultipleRoots, true)
 			if err != nil {
 				return nil, err
 			}
+			utils.IdentifyGhsaOnlyVulnerabilities(violations, utils.GetGhsaIds(scanResult))
+			violations = utils.ExcludeWorkspacePackages(violations, cfp.workspacePackages)
+			violations = cfp.filterByCves(violations)
+			cfp.applicabilitySeverityAdjustment.AdjustSeverityScores(violations)
+			utils.ConvertSarifPathsToRelative(&utils.IssuesCollection{Vulnerabilities: violations}, cfp.baseWd)
+			cfp.recordBranchVulnerabilities(violations)
 			for i := range violations {
 				if err = cfp.addVulnerabilityToFixVersionsMap(&violations[i], vulnerabilitiesMap); err != nil {
 					return nil, err
@@ -496,27 +1532,100 @@ func (cfp *ScanRepositoryCmd) createVulnerabilitiesMap(scanResults *securityutil
 			}
 		}
 	}
+	for _, scaResult := range scanResults.ScaResults {
+		if err := cfp.addLicenseViolations(scaResult, scanResults, isMultipleRoots, vulnerabilitiesMap); err != nil {
+			return nil, err
+		}
+	}
+	cfp.selectFixVersionsWithSupportedRuntime(vulnerabilitiesMap)
+	formatSuggestedFixVersions(vulnerabilitiesMap)
+	addSecurityBackportNotes(vulnerabilitiesMap)
 	if len(vulnerabilitiesMap) > 0 {
 		log.Debug("Frogbot will attempt to resolve the following vulnerable dependencies:\n", strings.Join(maps.Keys(vulnerabilitiesMap), ",\n"))
 	}
 	return vulnerabilitiesMap, nil
 }
 
+// Records the detected vulnerabilities for the SBOMs, the JUnit and cross-branch reports, the commit comment, the tracking issues and the SLA tracking, before the fix versions are computed and modify them
+func (cfp *ScanRepositoryCmd) recordBranchVulnerabilities(vulnerabilities []formats.VulnerabilityOrViolationRow) {
+	if cfp.sbomOutput == "" && cfp.fixedSbomOutput == "" && cfp.junitOutput == "" && cfp.htmlReport == "" && cfp.crossBranchResults == nil && !cfp.commentOnCommit && !cfp.createIssuesForUnfixable && cfp.slaPolicy == nil {
+		return
+	}
+	for _, vulnerability := range vulnerabilities {
+		vulnerability.ImpactedDependencyName = normalizeImpactedPackageName(vulnerability.Technology, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+		vulnerability.FixedVersions = slices.Clone(vulnerability.FixedVersions)
+		cfp.branchVulnerabilities = append(cfp.branchVulnerabilities, vulnerability)
+	}
+}
+
+// The fix versions are compared without the 'v' prefix. The suggested fix versions are then formatted as the manifests of their technologies expect them.
+func formatSuggestedFixVersions(vulnerabilitiesMap map[string]*utils.VulnerabilityDetails) {
+	for _, vulnDetails := range vulnerabilitiesMap {
+		vulnDetails.SuggestedFixedVersion = formatFixVersion(vulnDetails.Technology, vulnDetails.SuggestedFixedVersion)
+	}
+}
+
+// Returns the fix version in the format of the technology: Go modules are versioned with a 'v' prefix, while npm, Yarn and pnpm versions have no prefix.
+func formatFixVersion(tech techutils.Technology, fixVersion string) string {
+	if fixVersion == "" {
+		return ""
+	}
+	switch tech {
+	case techutils.Go:
+		return "v" + strings.TrimPrefix(fixVersion, "v")
+	case techutils.Npm, techutils.Yarn, techutils.Pnpm:
+		return strings.TrimPrefix(fixVersion, "v")
+	}
+	return fixVersion
+}
+
+// Labels the suggested fix versions that are security backports on an older release line, so reviewers can choose between staying on the line and upgrading.
+func addSecurityBackportNotes(vulnerabilitiesMap map[string]*utils.VulnerabilityDetails) {
+	for _, vulnDetails := range vulnerabilitiesMap {
+		if backportLine := getSecurityBackportLine(vulnDetails.SuggestedFixedVersion, vulnDetails.NewestFixedVersion); backportLine != "" {
+			vulnDetails.AddFixNote(fmt.Sprintf("%s %s is a security backport on the %s line. Newer fixed releases are available on the %s line.",
+				vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion, backportLine, getReleaseLine(vulnDetails.NewestFixedVersion)))
+		}
+	}
+}
+
 func (cfp *ScanRepositoryCmd) addVulnerabilityToFixVersionsMap(vulnerability *formats.VulnerabilityOrViolationRow, vulnerabilitiesMap map[string]*utils.VulnerabilityDetails) error {
+	vulnerability.ImpactedDependencyName = normalizeImpactedPackageName(vulnerability.Technology, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
 	if len(vulnerability.FixedVersions) == 0 {
 		return nil
 	}
+	if vulnerability.Technology != "" && !packagehandlers.IsFixSupported(vulnerability.Technology) {
+		log.Debug(fmt.Sprintf("Skipping '%s:%s', as Frogbot doesn't support fixing the vulnerabilities of %s", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, vulnerability.Technology.ToFormal()))
+		return nil
+	}
+	if ignoringRule := utils.GetIgnoringRule(cfp.ignoreRules, vulnerability.ImpactPaths); ignoringRule != nil {
+		log.Debug(fmt.Sprintf("Skipping '%s:%s' (%s), as it's reached only through dependency paths that match the ignore rule '%s'", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, utils.GetVulnerabiltiesUniqueID(*vulnerability), ignoringRule.DependencyPath))
+		cfp.recordExcludedPackage(vulnerability, "", "reached only through ignored dependency paths")
+		return nil
+	}
+	if cfp.isAdjustedBelowMinSeverity(vulnerability) {
+		adjustedSeverity := cfp.applicabilitySeverityAdjustment.AdjustedSeverity(*vulnerability)
+		log.Debug(fmt.Sprintf("Skipping '%s:%s' (%s), as it isn't applicable and its %s severity is treated as %s, which is below the minimal severity", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, utils.GetVulnerabiltiesUniqueID(*vulnerability), vulnerability.Severity, adjustedSeverity))
+		cfp.recordExcludedPackage(vulnerability, "", fmt.Sprintf("not applicable, and treated as %s severity", adjustedSeverity))
+		return nil
+	}
 	if len(cfp.projectTech) == 0 {
 		cfp.projectTech = []techutils.Technology{vulnerability.Technology}
 	}
-	vulnFixVersion := getMinimalFixVersion(vulnerability.ImpactedDependencyVersion, vulnerability.FixedVersions)
+	vulnFixVersion := getMinimalFixVersion(vulnerability.Technology, vulnerability.ImpactedDependencyVersion, vulnerability.FixedVersions, cfp.fixVersionCeilingPolicy, cfp.maxVersionJump, cfp.resolveFixVersionRanges, cfp.preferStableFixVersion)
 	if vulnFixVersion == "" {
+		if cfp.fixVersionCeilingPolicy != "" || cfp.maxVersionJump != nil {
+			cfp.deferFixViolatingVersionPolicies(vulnerability)
+		}
 		return nil
 	}
 	if vulnDetails, exists := vulnerabilitiesMap[vulnerability.ImpactedDependencyName]; exists {
 		// More than one vulnerability can exist on the same impacted package.
 		// Among all possible fix versions that fix the above-impacted package, we select the maximum fix version.
 		vulnDetails.UpdateFixVersionIfMax(vulnFixVersion)
+		// The maximum fix version resolves the CVEs of all the vulnerabilities of the package
+		vulnDetails.SetCves(vulnerability.Cves)
+		vulnDetails.AddBreakingChanges(*vulnerability)
 	} else {
 		isDirectDependency, err := utils.IsDirectDependency(vulnerability.ImpactPaths)
 		if err != nil {
@@ -525,18 +1634,165 @@ func (cfp *ScanRepositoryCmd) addVulnerabilityToFixVersionsMap(vulnerability *fo
 		// First appearance of a version that fixes the current impacted package
 		newVulnDetails := utils.NewVulnerabilityDetails(*vulnerability, vulnFixVersion)
 		newVulnDetails.SetIsDirectDependency(isDirectDependency)
+		newVulnDetails.SetTransitiveImpactPath(vulnerability.ImpactPaths)
+		if cfp.applicabilitySeverityAdjustment.IsAdjusted(*vulnerability) {
+			newVulnDetails.AddFixNote(fmt.Sprintf("The vulnerability of %s isn't applicable, so its %s severity is treated as %s.", vulnerability.ImpactedDependencyName, vulnerability.Severity, cfp.applicabilitySeverityAdjustment.AdjustedSeverity(*vulnerability)))
+		}
 		vulnerabilitiesMap[vulnerability.ImpactedDependencyName] = newVulnDetails
 	}
+	vulnerabilitiesMap[vulnerability.ImpactedDependencyName].UpdateNewestFixedVersionIfMax(getNewestFixVersion(vulnerability.Technology, vulnerability.FixedVersions, cfp.resolveFixVersionRanges))
 	// Set the fixed version array to the relevant fixed version so that only that specific fixed version will be displayed
 	vulnerability.FixedVersions = []string{vulnerabilitiesMap[vulnerability.ImpactedDependencyName].SuggestedFixedVersion}
 	return nil
 }
 
+// Reports a vulnerability whose only fix crosses the boundary of the fix version ceiling policy or exceeds the allowed version jump,
+// so it's handled manually instead of being fixed. The fix is computed without either constraint, and reported under each policy it violates.
+func (cfp *ScanRepositoryCmd) deferFixViolatingVersionPolicies(vulnerability *formats.VulnerabilityOrViolationRow) {
+	fixVersion := getMinimalFixVersion(vulnerability.Technology, vulnerability.ImpactedDependencyVersion, vulnerability.FixedVersions, "", nil, cfp.resolveFixVersionRanges, cfp.preferStableFixVersion)
+	if fixVersion == "" {
+		return
+	}
+	var violations []*utils.ErrUnsupportedFix
+	if cfp.fixVersionCeilingPolicy != "" && !isWithinVersionCeiling(vulnerability.ImpactedDependencyVersion, fixVersion, cfp.fixVersionCeilingPolicy) {
+		violations = append(violations, &utils.ErrUnsupportedFix{
+			PackageName:  vulnerability.ImpactedDependencyName,
+			FixedVersion: fixVersion,
+			ErrorType:    utils.FixCrossesVersionCeiling,
+			Reason:       string(cfp.fixVersionCeilingPolicy),
+		})
+	}
+	if cfp.maxVersionJump != nil && !cfp.maxVersionJump.IsAllowed(vulnerability.ImpactedDependencyVersion, fixVersion) {
+		violations = append(violations, &utils.ErrUnsupportedFix{
+			PackageName:  vulnerability.ImpactedDependencyName,
+			FixedVersion: fixVersion,
+			ErrorType:    utils.FixExceedsVersionJump,
+			Reason:       cfp.maxVersionJump.String(),
+		})
+	}
+	if len(violations) == 0 {
+		return
+	}
+	var skipReasons []string
+	for _, violation := range violations {
+		log.Info(fmt.Sprintf("%s (%s) Skipping...", violation.Error(), utils.GetVulnerabiltiesUniqueID(*vulnerability)))
+		skipReasons = append(skipReasons, violation.Summary())
+	}
+	// A single unsupported fix is kept for each package, so the first violated policy is listed with the unfixable vulnerabilities
+	cfp.recordUnsupportedFix(utils.NewVulnerabilityDetails(*vulnerability, fixVersion), violations[0])
+	cfp.recordExcludedPackage(vulnerability, fixVersion, strings.Join(skipReasons, ", and "))
+}
+
+// Replaces the suggested fix versions that require a newer runtime than the configured one with the next eligible versions that support it.
+// The packages that have no such version are reported and aren't fixed.
+func (cfp *ScanRepositoryCmd) selectFixVersionsWithSupportedRuntime(vulnerabilitiesMap map[string]*utils.VulnerabilityDetails) {
+	if len(cfp.runtimeVersions) == 0 {
+		return
+	}
+	for packageName, vulnDetails := range vulnerabilitiesMap {
+		isEligible := func(candidate string) bool {
+			return isWithinVersionCeiling(vulnDetails.ImpactedDependencyVersion, candidate, cfp.fixVersionCeilingPolicy) &&
+				(cfp.maxVersionJump == nil || cfp.maxVersionJump.IsAllowed(vulnDetails.ImpactedDependencyVersion, candidate))
+		}
+		fixVersion, err := packagehandlers.FindFixVersionWithSupportedRuntime(vulnDetails, cfp.runtimeVersions, isEligible, cfp.metadataLookupPolicy)
+		var errUnsupportedFix *utils.ErrUnsupportedFix
+		if errors.As(err, &errUnsupportedFix) {
+			log.Info(fmt.Sprintf("%s Skipping...", errUnsupportedFix.Error()))
+			cfp.recordUnsupportedFix(vulnDetails, errUnsupportedFix)
+			cfp.recordExcludedPackage(&vulnDetails.VulnerabilityOrViolationRow, vulnDetails.SuggestedFixedVersion, errUnsupportedFix.Summary())
+			delete(vulnerabilitiesMap, packageName)
+			continue
+		}
+		if fixVersion != vulnDetails.SuggestedFixedVersion {
+			vulnDetails.SuggestedFixedVersion = fixVersion
+			vulnDetails.FixedVersions = []string{fixVersion}
+		}
+	}
+}
+
+// Returns the name of the impacted package as it appears in the manifests of the technology.
+// Xray may return the component ID instead of the name, such as npm://lodash, go://github.com/gin-gonic/gin:v1.9.0 or gav://org.yaml:snakeyaml:1.33.
+func normalizeImpactedPackageName(tech techutils.Technology, name, impactedVersion string) string {
+	if _, componentName, found := strings.Cut(name, "://"); found {
+		name = componentName
+	}
+	name = strings.TrimSuffix(name, ":"+impactedVersion)
+	if tech == techutils.Maven || tech == techutils.Gradle {
+		parts := strings.Split(name, ":")
+		if len(parts) <= 2 {
+			return name
+		}
+		// The version may also precede the type of the artifact, such as gav://org.yaml:snakeyaml:1.33:jar
+		parts = slices.DeleteFunc(parts, func(part string) bool { return part == impactedVersion })
+		if tech == techutils.Maven && len(parts) >= 4 {
+			// Artifacts with a classifier are named <group>:<artifact>:<type>:<classifier>, so they are matched to the right dependency in the pom
+			return strings.Join(parts[:4], ":")
+		}
+		// Packages are named <group>:<artifact>, so any remaining version or type is dropped
+		name = parts[0] + ":" + parts[1]
+	}
+	return name
+}
+
+// Returns true if the vulnerability isn't applicable, and the severity it is treated as is below the minimal severity of the fixes.
+// Xray filters the vulnerabilities by their original severities, before their applicability is known.
+func (cfp *ScanRepositoryCmd) isAdjustedBelowMinSeverity(vulnerability *formats.VulnerabilityOrViolationRow) bool {
+	if !cfp.applicabilitySeverityAdjustment.IsAdjusted(*vulnerability) || cfp.scanDetails == nil || cfp.scanDetails.MinSeverityFilter() == "" {
+		return false
+	}
+	return severityutils.CompareSeverity(cfp.applicabilitySeverityAdjustment.AdjustedSeverity(*vulnerability), cfp.scanDetails.MinSeverityFilter()) < 0
+}
+
+// Removes the vulnerabilities that don't match the included and excluded CVEs, so they are neither fixed nor reported
+func (cfp *ScanRepositoryCmd) filterByCves(vulnerabilities []formats.VulnerabilityOrViolationRow) []formats.VulnerabilityOrViolationRow {
+	if len(cfp.onlyCves) == 0 && len(cfp.excludeCves) == 0 {
+		return vulnerabilities
+	}
+	var included []formats.VulnerabilityOrViolationRow
+	for i := range vulnerabilities {
+		vulnerability := &vulnerabilities[i]
+		if !cfp.isCveFilterMatch(vulnerability) {
+			log.Debug(fmt.Sprintf("Skipping '%s:%s' (%s), as its CVEs don't match the included and excluded CVEs", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, utils.GetVulnerabiltiesUniqueID(*vulnerability)))
+			if len(vulnerability.FixedVersions) > 0 {
+				excluded := *vulnerability
+				excluded.ImpactedDependencyName = normalizeImpactedPackageName(excluded.Technology, excluded.ImpactedDependencyName, excluded.ImpactedDependencyVersion)
+				cfp.recordExcludedPackage(&excluded, "", "excluded by the CVE filters")
+			}
+			continue
+		}
+		included = append(included, *vulnerability)
+	}
+	return included
+}
+
+// Returns true if the vulnerability should be fixed according to the included and excluded CVEs.
+// If CVEs are included, only vulnerabilities with at least one of the included CVEs are fixed. A vulnerability with an excluded CVE is never fixed.
+func (cfp *ScanRepositoryCmd) isCveFilterMatch(vulnerability *formats.VulnerabilityOrViolationRow) bool {
+	if len(cfp.onlyCves) == 0 && len(cfp.excludeCves) == 0 {
+		return true
+	}
+	included := len(cfp.onlyCves) == 0
+	for _, cve := range vulnerability.Cves {
+		cveId := strings.ToUpper(cve.Id)
+		if slices.Contains(cfp.excludeCves, cveId) {
+			return false
+		}
+		included = included || slices.Contains(cfp.onlyCves, cveId)
+	}
+	return included
+}
+
 // Updates impacted package, can return ErrUnsupportedFix.
 func (cfp *ScanRepositoryCmd) updatePackageToFixedVersion(vulnDetails *utils.VulnerabilityDetails) (err error) {
 	if err = isBuildToolsDependency(vulnDetails); err != nil {
 		return
 	}
+	if err = packagehandlers.VerifyFixVersionAvailability(vulnDetails, cfp.versionRegistries, cfp.metadataLookupPolicy); err != nil {
+		return
+	}
+	if err = packagehandlers.VerifyFixVersionProvenance(vulnDetails, cfp.provenanceEcosystems, cfp.blockFailedProvenance, cfp.metadataLookupPolicy); err != nil {
+		return
+	}
 
 	if cfp.handlers == nil {
 		cfp.handlers = make(map[techutils.Technology]packagehandlers.PackageHandler)
@@ -550,14 +1806,49 @@ func (cfp *ScanRepositoryCmd) updatePackageToFixedVersion(vulnDetails *utils.Vul
 		return
 	}
 
-	return cfp.handlers[vulnDetails.Technology].UpdateDependency(vulnDetails)
+	if err = cfp.handlers[vulnDetails.Technology].UpdateDependency(vulnDetails); err != nil {
+		return
+	}
+	return cfp.validateFixedLockfile(vulnDetails)
+}
+
+// Writes the manifest of the fixes under the repository root, so it's included in the fix commit
+func (cfp *ScanRepositoryCmd) writeFixManifestIfNeeded(vulnerabilities ...*utils.VulnerabilityDetails) error {
+	if !cfp.writeFixManifest {
+		return nil
+	}
+	log.Debug("Writing the fix manifest to", utils.FixManifestPath)
+	if err := utils.WriteFixManifest(cfp.baseWd, vulnerabilities...); err != nil {
+		return fmt.Errorf("failed to write the fix manifest: %s", err.Error())
+	}
+	return nil
+}
+
+// Returns the checksum of the aggregated pull request. The fix manifest is accounted for, so enabling it updates the pull requests that were opened without it
+func (cfp *ScanRepositoryCmd) getFixPullRequestChecksum(vulnerabilities ...*utils.VulnerabilityDetails) (string, error) {
+	checksum, err := utils.FixPullRequestChecksum(cfp.gitManager.Hasher(), cfp.OutputWriter.PullRequestBodySections(), vulnerabilities...)
+	if err != nil || !cfp.writeFixManifest {
+		return checksum, err
+	}
+	return cfp.gitManager.Hasher().Hash(checksum, utils.FixManifestPath)
+}
+
+// The aggregated pull requests opened before the checksum was computed from the fixed packages record the checksum of the vulnerabilities rows.
+// They're in sync as long as that checksum matches, so they aren't force-pushed only because the checksum is computed differently.
+// Their checksum is replaced the next time their fixes change.
+func matchesLegacyChecksum(vulnerabilities []*utils.VulnerabilityDetails, remoteChecksum string) (bool, error) {
+	if remoteChecksum == "" {
+		return false, nil
+	}
+	legacyChecksum, err := utils.VulnerabilityDetailsToMD5Hash(utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilities)...)
+	return legacyChecksum == remoteChecksum, err
 }
 
 // The getRemoteBranchScanHash function extracts the checksum written inside the pull request body and returns it.
 func (cfp *ScanRepositoryCmd) getRemoteBranchScanHash(prBody string) string {
 	// The pattern matches the string "Checksum: <checksum>", followed by one or more word characters (letters, digits, or underscores).
 	re := regexp.MustCompile(`Checksum: (\w+)`)
-	match := re.FindStringSubmatch(prBody)
+	match := re.FindStringSubmatch(utils.RevealHiddenMarkers(prBody))
 
 	// The first element is the entire matched string, and the second element is the checksum value.
 	// If the length of match is not equal to 2, it means that the pattern was not found or the captured group is missing.
@@ -569,8 +1860,8 @@ func (cfp *ScanRepositoryCmd) getRemoteBranchScanHash(prBody string) string {
 	return match[1]
 }
 
-func (cfp *ScanRepositoryCmd) getOpenPullRequestBySourceBranch(branchName string) (prInfo *vcsclient.PullRequestInfo, err error) {
-	list, err := cfp.scanDetails.Client().ListOpenPullRequestsWithBody(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName)
+func (cfp *ScanRepositoryCmd) getOpenPullRequestBySourceBranch(client vcsclient.VcsClient, branchName string) (prInfo *vcsclient.PullRequestInfo, err error) {
+	list, err := client.ListOpenPullRequestsWithBody(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName)
 	if err != nil {
 		return
 	}
@@ -584,6 +1875,41 @@ func (cfp *ScanRepositoryCmd) getOpenPullRequestBySourceBranch(branchName string
 	return
 }
 
+// Returns the open pull request of the fix branch, if the base branch advanced since the fix branch was pushed.
+// Returns nil if the fix branch is up to date with the base branch, or if it has no open pull request.
+func (cfp *ScanRepositoryCmd) getStaleFixPullRequest(fixBranchName, commitMessage string) (prInfo *vcsclient.PullRequestInfo, err error) {
+	isStale, err := cfp.isFixBranchStale(fixBranchName, commitMessage)
+	if err != nil || !isStale {
+		return
+	}
+	if prInfo, err = cfp.getOpenPullRequestBySourceBranch(cfp.scanDetails.Client(), fixBranchName); err == nil && prInfo == nil {
+		log.Debug("The stale fix branch", fixBranchName, "has no open pull request to rebase")
+	}
+	return
+}
+
+// Determines whether the base branch advanced since the fix branch was pushed, so its fix should be applied again on top of the base branch.
+// The fix branch is expected to hold a single commit with the given message on top of the base branch, which is checked out locally.
+// A fix branch whose latest commit has another message includes commits that weren't made by Frogbot, so it isn't considered stale, to avoid dropping these commits.
+func (cfp *ScanRepositoryCmd) isFixBranchStale(fixBranchName, commitMessage string) (isStale bool, err error) {
+	baseCommitHash, err := cfp.gitManager.GetHeadCommitHash()
+	if err != nil {
+		return
+	}
+	latestCommit, err := cfp.scanDetails.Client().GetLatestCommit(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, fixBranchName)
+	if err != nil {
+		return
+	}
+	if slices.Contains(latestCommit.ParentHashes, baseCommitHash) {
+		return
+	}
+	if strings.TrimSpace(latestCommit.Message) != strings.TrimSpace(commitMessage) {
+		log.Info(fmt.Sprintf("The fix branch '%s' is behind the base branch, but it includes commits that weren't made by Frogbot. The branch isn't rebased.", fixBranchName))
+		return
+	}
+	return true, nil
+}
+
 func (cfp *ScanRepositoryCmd) aggregateFixAndOpenPullRequest(repository *utils.Repository, vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails, aggregatedFixBranchName string, existingPullRequestInfo *vcsclient.PullRequestInfo) (err error) {
 	log.Info("-----------------------------------------------------------------")
 	log.Info("Starting aggregated dependencies fix")
@@ -623,6 +1949,7 @@ func (cfp *ScanRepositoryCmd) aggregateFixAndOpenPullRequest(repository *utils.R
 		return
 	}
 	if len(fixedVulnerabilities) > 0 {
+		cfp.aggregatedSkippedPackages = cfp.getAggregatedSkippedPackages(vulnerabilitiesMap, fixedVulnerabilities)
 		if e = cfp.openAggregatedPullRequest(repository, aggregatedFixBranchName, existingPullRequestInfo, fixedVulnerabilities); e != nil {
 			err = errors.Join(err, fmt.Errorf("failed while creating aggregated pull request. Error: \n%s", e.Error()))
 		}
@@ -653,32 +1980,195 @@ func (cfp *ScanRepositoryCmd) isUpdateRequired(fixedVulnerabilities []*utils.Vul
 	}
 	log.Info("Aggregated pull request already exists, verifying if update is needed...")
 	log.Debug("Comparing current scan results to existing", prInfo.Target.Name, "scan results")
-	fixedVulnerabilitiesRows := utils.ExtractVulnerabilitiesDetailsToRows(fixedVulnerabilities)
-	currentScanHash, err := utils.VulnerabilityDetailsToMD5Hash(fixedVulnerabilitiesRows...)
+	currentScanHash, err := cfp.getFixPullRequestChecksum(fixedVulnerabilities...)
 	if err != nil {
 		return
 	}
 	remoteBranchScanHash := cfp.getRemoteBranchScanHash(prInfo.Body)
 	updateRequired = currentScanHash != remoteBranchScanHash
 	if updateRequired {
-		log.Info("The existing pull request is not in sync with the latest scan, updating pull request...")
+		var isLegacyInSync bool
+		if isLegacyInSync, err = matchesLegacyChecksum(fixedVulnerabilities, remoteBranchScanHash); err != nil {
+			return
+		}
+		updateRequired = !isLegacyInSync
 	}
+	if !updateRequired {
+		if cfp.autoRebaseStalePrs {
+			if updateRequired, err = cfp.isFixBranchStale(prInfo.Source.Name, cfp.generateAggregatedCommitMessage()); updateRequired {
+				log.Info("The existing pull request is in sync with the latest scan, but its base branch advanced. Rebasing pull request...")
+			}
+		}
+		return
+	}
+	if lastUpdate := getPullRequestLastUpdateTime(prInfo.Body); cfp.minPrUpdateInterval > 0 && !lastUpdate.IsZero() {
+		if nextUpdate := lastUpdate.Add(cfp.minPrUpdateInterval); time.Now().Before(nextUpdate) {
+			log.Info(fmt.Sprintf("The existing pull request is not in sync with the latest scan, but it was updated less than %s ago. The update is deferred until %s.", cfp.minPrUpdateInterval, nextUpdate.Format(time.RFC3339)))
+			updateRequired = false
+			return
+		}
+	}
+	log.Info("The existing pull request is not in sync with the latest scan, updating pull request...")
 	return
 }
 
+// Returns the last update time recorded inside the pull request body, or a zero time if none was recorded.
+func getPullRequestLastUpdateTime(prBody string) time.Time {
+	match := regexp.MustCompile(lastUpdatePrefix + `(\S+)\)`).FindStringSubmatch(utils.RevealHiddenMarkers(prBody))
+	if len(match) != 2 {
+		return time.Time{}
+	}
+	lastUpdate, err := time.Parse(time.RFC3339, match[1])
+	if err != nil {
+		log.Debug("Failed to parse the last update time of the pull request:", err.Error())
+		return time.Time{}
+	}
+	return lastUpdate
+}
+
 // getMinimalFixVersion find the minimal version that fixes the current impactedPackage;
-// fixVersions is a sorted array. The function returns the first version in the array, that is larger than impactedPackageVersion.
-func getMinimalFixVersion(impactedPackageVersion string, fixVersions []string) string {
-	// Trim 'v' prefix in case of Go package
+// The function returns the smallest version in fixVersions that is larger than impactedPackageVersion.
+// Versions that are equivalent under the comparison are told apart by isSmallerFixVersion, so the same version is returned regardless of the order of fixVersions.
+// If a ceiling policy is provided, versions that cross the impacted version's major or minor version are skipped.
+// If a max version jump is provided, versions that exceed it are skipped.
+// If resolveRanges is set, a concrete version is derived from fix versions that are expressed as ranges, instead of skipping them.
+func getMinimalFixVersion(tech techutils.Technology, impactedPackageVersion string, fixVersions []string, ceilingPolicy utils.FixVersionCeilingPolicy, maxVersionJump *utils.MaxVersionJump, resolveRanges, preferStable bool) (minimalFixVersion string) {
+	// Trim 'v' prefix in case of Go package. The fix versions are compared without it as well, and formatted by their technology later.
 	currVersionStr := strings.TrimPrefix(impactedPackageVersion, "v")
-	currVersion := version.NewVersion(currVersionStr)
+	// When the impacted version is a pre-release, a stable fix version may be preferred over a newer pre-release
+	preferStable = preferStable && isPreRelease(tech, currVersionStr)
+	var minimalStableFixVersion string
+	for _, fixVersion := range fixVersions {
+		fixVersionCandidates := []string{parseVersionChangeString(fixVersion)}
+		if resolveRanges {
+			fixVersionCandidates = getVersionRangeCandidates(tech, fixVersion)
+		}
+		for _, fixVersionCandidate := range fixVersionCandidates {
+			fixVersionCandidate = strings.TrimPrefix(fixVersionCandidate, "v")
+			if fixVersionCandidate != "" && compareVersions(tech, fixVersionCandidate, currVersionStr) > 0 && isWithinVersionCeiling(currVersionStr, fixVersionCandidate, ceilingPolicy) && (maxVersionJump == nil || maxVersionJump.IsAllowed(currVersionStr, fixVersionCandidate)) {
+				if minimalFixVersion == "" || isSmallerFixVersion(tech, fixVersionCandidate, minimalFixVersion) {
+					minimalFixVersion = fixVersionCandidate
+				}
+				if preferStable && !isPreRelease(tech, fixVersionCandidate) && (minimalStableFixVersion == "" || isSmallerFixVersion(tech, fixVersionCandidate, minimalStableFixVersion)) {
+					minimalStableFixVersion = fixVersionCandidate
+				}
+			}
+		}
+	}
+	if minimalStableFixVersion != "" {
+		return minimalStableFixVersion
+	}
+	return
+}
+
+// Returns true if the candidate is smaller than the current minimal fix version.
+// Odd version schemes may make two different versions equivalent under the comparison (e.g. 1.0 and 1.0.0).
+// Such a tie is broken by preferring the lexically smaller version string, so the selected fix version,
+// and the branch names and checksums derived from it, don't flip between runs.
+func isSmallerFixVersion(tech techutils.Technology, candidate, currentMinimal string) bool {
+	if comparison := compareVersions(tech, candidate, currentMinimal); comparison != 0 {
+		return comparison < 0
+	}
+	return candidate < currentMinimal
+}
+
+// Returns a positive number if the first version is newer than the second, a negative number if it's older, and 0 if they are equivalent.
+// In technologies that follow semantic versioning, a pre-release, such as 2.0.0-beta.3, is older than the release of the same version, and the build metadata is ignored.
+func compareVersions(tech techutils.Technology, first, second string) int {
+	firstRelease, firstPreRelease := splitPreRelease(tech, first)
+	secondRelease, secondPreRelease := splitPreRelease(tech, second)
+	if firstPreRelease == "" && secondPreRelease == "" {
+		// The version library returns a positive number if its argument is the newer version
+		return -version.NewVersion(first).Compare(second)
+	}
+	if comparison := -version.NewVersion(firstRelease).Compare(secondRelease); comparison != 0 {
+		return comparison
+	}
+	switch {
+	case firstPreRelease == "":
+		return 1
+	case secondPreRelease == "":
+		return -1
+	default:
+		return -version.NewVersion(firstPreRelease).Compare(secondPreRelease)
+	}
+}
+
+func isPreRelease(tech techutils.Technology, versionStr string) bool {
+	_, preRelease := splitPreRelease(tech, versionStr)
+	return preRelease != ""
+}
+
+// The technologies whose versions follow semantic versioning, in which a hyphen starts a pre-release
+var semverTechnologies = []techutils.Technology{techutils.Npm, techutils.Yarn, techutils.Pnpm, techutils.Go, techutils.Nuget, techutils.Dotnet}
+
+// Splits the version into its release and pre-release parts, such as 2.0.0 and beta.3 for 2.0.0-beta.3+build.5
+// Other technologies attach suffixes that aren't pre-releases, such as 32.0.0-jre in Maven, so their versions are returned as a whole.
+func splitPreRelease(tech techutils.Technology, versionStr string) (release, preRelease string) {
+	if !slices.Contains(semverTechnologies, tech) {
+		return versionStr, ""
+	}
+	versionStr, _, _ = strings.Cut(versionStr, "+")
+	release, preRelease, _ = strings.Cut(versionStr, "-")
+	return
+}
+
+// Returns the newest of the fix versions.
+func getNewestFixVersion(tech techutils.Technology, fixVersions []string, resolveRanges bool) (newestFixVersion string) {
 	for _, fixVersion := range fixVersions {
-		fixVersionCandidate := parseVersionChangeString(fixVersion)
-		if currVersion.Compare(fixVersionCandidate) > 0 {
-			return fixVersionCandidate
+		fixVersionCandidates := []string{parseVersionChangeString(fixVersion)}
+		if resolveRanges {
+			fixVersionCandidates = getVersionRangeCandidates(tech, fixVersion)
+		}
+		for _, fixVersionCandidate := range fixVersionCandidates {
+			fixVersionCandidate = strings.TrimPrefix(fixVersionCandidate, "v")
+			if fixVersionCandidate != "" && (newestFixVersion == "" || compareVersions(tech, fixVersionCandidate, newestFixVersion) > 0) {
+				newestFixVersion = fixVersionCandidate
+			}
 		}
 	}
-	return ""
+	return
+}
+
+// Xray lists the versions that fix a vulnerability on each of the maintained release lines.
+// If the suggested fix version is on an older release line than the newest fix version, it is a security backport on that line, and its release line is returned (e.g. 1.4.x).
+func getSecurityBackportLine(fixVersion, newestFixVersion string) string {
+	if fixVersion == "" || newestFixVersion == "" {
+		return ""
+	}
+	fixVersionLine := getReleaseLine(fixVersion)
+	newestFixVersionLine := getReleaseLine(newestFixVersion)
+	if version.NewVersion(strings.TrimSuffix(fixVersionLine, ".x")).Compare(strings.TrimSuffix(newestFixVersionLine, ".x")) <= 0 {
+		return ""
+	}
+	return fixVersionLine
+}
+
+// Returns the major and minor release line of the version, e.g. 1.4.x for 1.4.7
+func getReleaseLine(fullVersion string) string {
+	components := strings.Split(strings.TrimPrefix(fullVersion, "v"), ".")
+	return utils.GetVersionComponent(components, 0) + "." + utils.GetVersionComponent(components, 1) + ".x"
+}
+
+// Returns true if the fix version shares the major (same-major) or the major and minor (same-minor) versions of the impacted version.
+func isWithinVersionCeiling(impactedVersion, fixVersion string, ceilingPolicy utils.FixVersionCeilingPolicy) bool {
+	var sharedComponents int
+	switch ceilingPolicy {
+	case utils.SameMajorCeilingPolicy:
+		sharedComponents = 1
+	case utils.SameMinorCeilingPolicy:
+		sharedComponents = 2
+	default:
+		return true
+	}
+	impactedComponents := strings.Split(impactedVersion, ".")
+	fixComponents := strings.Split(strings.TrimPrefix(fixVersion, "v"), ".")
+	for i := 0; i < sharedComponents; i++ {
+		if utils.GetVersionComponent(impactedComponents, i) != utils.GetVersionComponent(fixComponents, i) {
+			return false
+		}
+	}
+	return true
 }
 
 // 1.0         --> 1.0 ≤ x
@@ -698,6 +2188,60 @@ func parseVersionChangeString(fixVersion string) string {
 	return latestVersion
 }
 
+// getVersionRangeCandidates returns the concrete versions that satisfy the version range, in ascending order.
+// Only the inclusive bounds are candidates, as they are versions that were published. The versions past an exclusive bound
+// can't be derived from the range itself, so a range without an inclusive bound is skipped.
+// 1.0            --> [1.0]
+// (,1.0]         --> [1.0]
+// (,1.0)         --> []
+// [1.0]          --> [1.0]
+// (1.0,)         --> []
+// [1.0,)         --> [1.0]
+// (1.0.0, 2.0.0) --> []
+// (1.0, 2.0]     --> [2.0]
+// [1.0, 2.0)     --> [1.0]
+// [1.0, 2.0]     --> [1.0, 2.0]
+func getVersionRangeCandidates(tech techutils.Technology, fixVersion string) (candidates []string) {
+	fixVersion = strings.TrimSpace(fixVersion)
+	if fixVersion == "" {
+		return
+	}
+	lowerInclusive, upperInclusive := strings.HasPrefix(fixVersion, "["), strings.HasSuffix(fixVersion, "]")
+	if !lowerInclusive && !strings.HasPrefix(fixVersion, "(") {
+		// A plain version
+		return []string{fixVersion}
+	}
+	defer func() {
+		if len(candidates) == 0 {
+			log.Debug(fmt.Sprintf("Skipping the fix version range '%s', as it has no inclusive bound to fix to", fixVersion))
+		}
+	}()
+	bounds := strings.Split(strings.TrimRight(strings.TrimLeft(fixVersion, "[("), "])"), ",")
+	if len(bounds) == 1 {
+		// An exact version, such as [1.0]
+		if lowerInclusive && upperInclusive {
+			candidates = append(candidates, strings.TrimSpace(bounds[0]))
+		}
+		return
+	}
+	lowerBound, upperBound := strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[len(bounds)-1])
+	if lowerBound != "" && lowerInclusive && isBelowUpperBound(tech, lowerBound, upperBound, upperInclusive) {
+		candidates = append(candidates, lowerBound)
+	}
+	if upperBound != "" && upperInclusive && !slices.Contains(candidates, upperBound) {
+		candidates = append(candidates, upperBound)
+	}
+	return
+}
+
+func isBelowUpperBound(tech techutils.Technology, versionStr, upperBound string, upperInclusive bool) bool {
+	if upperBound == "" {
+		return true
+	}
+	comparison := compareVersions(tech, versionStr, upperBound)
+	return comparison < 0 || (upperInclusive && comparison == 0)
+}
+
 // Skip build tools dependencies (for example, pip)
 // that are not defined in the descriptor file and cannot be fixed by a PR.
 func isBuildToolsDependency(vulnDetails *utils.VulnerabilityDetails) error {
@@ -1,17 +1,29 @@
 package scanrepository
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-github/v45/github"
 	biutils "github.com/jfrog/build-info-go/utils"
+	"github.com/jfrog/frogbot/v2/packagehandlers"
 	"github.com/jfrog/frogbot/v2/utils"
 	"github.com/jfrog/frogbot/v2/utils/outputwriter"
 	"github.com/jfrog/froggit-go/vcsclient"
@@ -19,12 +31,16 @@ import (
 	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-security/formats"
 	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 const rootTestDir = "scanrepository"
@@ -58,6 +74,11 @@ var testPackagesData = []struct {
 		commandName: "yarn",
 		commandArgs: []string{"install"},
 	},
+	{
+		packageType: techutils.Pnpm.String(),
+		commandName: "pnpm",
+		commandArgs: []string{"install"},
+	},
 	{
 		packageType: techutils.Dotnet.String(),
 		commandName: "dotnet",
@@ -179,7 +200,7 @@ func TestScanRepositoryCmd_Run(t *testing.T) {
 			}
 
 			utils.CreateDotGitWithCommit(t, testDir, port, test.testName)
-			configAggregator, err := utils.BuildRepoAggregator(client, configData, &gitTestParams, &serverParams, utils.ScanRepository)
+			configAggregator, err := utils.BuildRepoAggregator(client, [][]byte{configData}, &gitTestParams, &serverParams, utils.ScanRepository)
 			assert.NoError(t, err)
 			// Run
 			var cmd = ScanRepositoryCmd{dryRun: true, dryRunRepoPath: testDir}
@@ -219,14 +240,19 @@ func TestAggregatePullRequestLifecycle(t *testing.T) {
 	targetBranchName := "main"
 	sourceLabel := "repo:frogbot-update-npm-dependencies"
 	targetLabel := "repo:main"
-	firstBody := `
-[comment]: <> (Checksum: 4608a55b621cb6337ac93487979ac09c)
-pr body
-`
-	secondBody := `
-[comment]: <> (Checksum: 01373ac4d2c32e7da9be22f3e4b4e665)
+	// Both projects depend on mpath 0.7.0, which is fixed in 0.8.4. The pull request of the second project was opened before mongoose 5.10.10 was
+	// found vulnerable, so its checksum doesn't cover the current fixes.
+	mpathFix := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "mpath", ImpactedDependencyVersion: "0.7.0"},
+		Cves:                      []formats.CveRow{{Id: "CVE-2021-23438"}},
+	}, "0.8.4")
+	mpathChecksum, err := utils.FixedPackagesChecksum(utils.NewHasher("", 0), mpathFix)
+	require.NoError(t, err)
+	firstBody := fmt.Sprintf(`
+[comment]: <> (Checksum: %s)
 pr body
- `
+`, mpathChecksum)
+	secondBody := firstBody
 	tests := []struct {
 		testName                string
 		expectedUpdate          bool
@@ -299,7 +325,7 @@ pr body
 			// Load default configurations
 			var configData []byte
 			gitTestParams.Branches = []string{"master"}
-			configAggregator, err := utils.BuildRepoAggregator(client, configData, gitTestParams, &serverParams, utils.ScanRepository)
+			configAggregator, err := utils.BuildRepoAggregator(client, [][]byte{configData}, gitTestParams, &serverParams, utils.ScanRepository)
 			assert.NoError(t, err)
 			// Run
 			var cmd = ScanRepositoryCmd{dryRun: true, dryRunRepoPath: testDir}
@@ -341,6 +367,138 @@ func TestParseVersionChangeString(t *testing.T) {
 	}
 }
 
+func TestGetVersionRangeCandidates(t *testing.T) {
+	tests := []struct {
+		versionChangeString string
+		expectedCandidates  []string
+	}{
+		{"1.2.3", []string{"1.2.3"}},
+		{"(,1.2.3]", []string{"1.2.3"}},
+		{"(,1.2.3)", nil},
+		{"[1.2.3]", []string{"1.2.3"}},
+		{"(1.2.3,)", nil},
+		{"(1.2.3-beta,)", nil},
+		{"[1.2.3,)", []string{"1.2.3"}},
+		{"(1.2.3, 2.0.0)", nil},
+		{"(1.2.3, 1.2.4)", nil},
+		{"(1.2.3, 1.2.4]", []string{"1.2.4"}},
+		{"(1.2.3, 2.0.0]", []string{"2.0.0"}},
+		{"[1.2.3, 2.0.0)", []string{"1.2.3"}},
+		{"[1.2.3, 1.2.3)", nil},
+		{"[1.2.3, 2.0.0]", []string{"1.2.3", "2.0.0"}},
+		{"[1.2.3, 1.2.3]", []string{"1.2.3"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.versionChangeString, func(t *testing.T) {
+			assert.Equal(t, test.expectedCandidates, getVersionRangeCandidates(techutils.Maven, test.versionChangeString))
+		})
+	}
+}
+
+func TestGetMinimalFixVersionWithVersionRanges(t *testing.T) {
+	tests := []struct {
+		impactedVersionPackage string
+		fixVersions            []string
+		expected               string
+	}{
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(,1.2.3]"}, expected: "1.2.3"},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(,1.2.3)"}, expected: ""},
+		// The version past an exclusive lower bound isn't necessarily published, so it isn't fixed to
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(1.0.0,)", "[1.5.0]"}, expected: "1.5.0"},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(1.0.0, 2.0.0]"}, expected: "2.0.0"},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(1.0.0, 2.0.0)"}, expected: ""},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"[1.2.3, 2.0.0)"}, expected: "1.2.3"},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"(1.0.0, 1.0.1)", "[1.5.0]"}, expected: "1.5.0"},
+		// The lowest bound is not above the impacted version, so the upper bound is taken
+		{impactedVersionPackage: "1.5.0", fixVersions: []string{"[1.2.3, 2.0.0]"}, expected: "2.0.0"},
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"[1.2.3, 2.0.0]"}, expected: "1.2.3"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s:%v", test.impactedVersionPackage, test.fixVersions), func(t *testing.T) {
+			assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, test.fixVersions, "", nil, true, false))
+		})
+	}
+	// Without resolving ranges, open-ended ranges are skipped
+	assert.Empty(t, getMinimalFixVersion(techutils.Npm, "1.0.0", []string{"(,1.2.3]"}, "", nil, false, false))
+}
+
+func TestGetMinimalFixVersionTieBreak(t *testing.T) {
+	tests := []struct {
+		impactedVersionPackage string
+		fixVersions            []string
+		expected               string
+	}{
+		// Equivalent versions are told apart by their strings, preferring the lexically smaller one
+		{impactedVersionPackage: "0.9.0", fixVersions: []string{"[1.0.0]", "[1.0]"}, expected: "1.0"},
+		{impactedVersionPackage: "1.2.0", fixVersions: []string{"[1.2.3.0]", "[1.2.3]", "[1.3.0]"}, expected: "1.2.3"},
+		// The minimal version is selected regardless of the order of the fix versions
+		{impactedVersionPackage: "1.0.0", fixVersions: []string{"[2.0.0]", "[1.5.0]", "[1.2.0]"}, expected: "1.2.0"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s:%v", test.impactedVersionPackage, test.fixVersions), func(t *testing.T) {
+			// The selection must be stable across runs, in which the fix versions may be listed in any order
+			for i := 0; i < len(test.fixVersions); i++ {
+				rotatedFixVersions := append(append([]string{}, test.fixVersions[i:]...), test.fixVersions[:i]...)
+				assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, rotatedFixVersions, "", nil, false, false))
+				slices.Reverse(rotatedFixVersions)
+				assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, rotatedFixVersions, "", nil, false, false))
+			}
+		})
+	}
+}
+
+func TestGetMinimalFixVersionOfPreRelease(t *testing.T) {
+	tests := []struct {
+		impactedVersionPackage string
+		fixVersions            []string
+		preferStable           bool
+		expected               string
+	}{
+		// The stable release is newer than its pre-releases, while the previous release line is older
+		{impactedVersionPackage: "2.0.0-beta.3", fixVersions: []string{"1.9.9", "2.0.0"}, expected: "2.0.0"},
+		{impactedVersionPackage: "v2.0.0-beta.3", fixVersions: []string{"v1.9.9", "v2.0.0"}, expected: "2.0.0"},
+		{impactedVersionPackage: "2.0.0-beta.3", fixVersions: []string{"2.0.0-beta.2", "2.0.0-beta.10", "2.0.0"}, expected: "2.0.0-beta.10"},
+		{impactedVersionPackage: "2.0.0-beta.3", fixVersions: []string{"2.0.0-alpha.9"}, expected: ""},
+		{impactedVersionPackage: "2.0.0", fixVersions: []string{"2.0.0-rc.1", "2.0.1"}, expected: "2.0.1"},
+		// A stable fix version is preferred over a newer pre-release
+		{impactedVersionPackage: "2.0.0-beta.3", fixVersions: []string{"2.0.0-beta.10", "2.0.0", "2.1.0"}, preferStable: true, expected: "2.0.0"},
+		{impactedVersionPackage: "2.0.0-beta.3", fixVersions: []string{"2.0.0-beta.10"}, preferStable: true, expected: "2.0.0-beta.10"},
+		// The preference applies to pre-release impacted versions only
+		{impactedVersionPackage: "1.9.0", fixVersions: []string{"2.0.0-rc.1", "2.0.0"}, preferStable: true, expected: "2.0.0-rc.1"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %v", test.impactedVersionPackage, test.fixVersions), func(t *testing.T) {
+			assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, test.fixVersions, "", nil, false, test.preferStable))
+		})
+	}
+}
+
+func TestGetMinimalFixVersionWithVersionQualifier(t *testing.T) {
+	tests := []struct {
+		tech                   techutils.Technology
+		impactedVersionPackage string
+		fixVersions            []string
+		expected               string
+	}{
+		// A Maven qualifier isn't a pre-release, so 32.0.0-jre is newer than 31.1
+		{tech: techutils.Maven, impactedVersionPackage: "31.1-jre", fixVersions: []string{"32.0.0-jre"}, expected: "32.0.0-jre"},
+		// As in Maven, a qualifier that isn't a pre-release follows the release of the same version
+		{tech: techutils.Maven, impactedVersionPackage: "32.0.0", fixVersions: []string{"32.0.0-jre", "32.0.1"}, expected: "32.0.0-jre"},
+		{tech: techutils.Gradle, impactedVersionPackage: "31.1-android", fixVersions: []string{"30.0-android", "32.0.0-android"}, expected: "32.0.0-android"},
+		// In npm, the same suffix is a pre-release, which is older than its release
+		{tech: techutils.Npm, impactedVersionPackage: "32.0.0", fixVersions: []string{"32.0.0-jre", "32.0.1"}, expected: "32.0.1"},
+		{tech: techutils.Npm, impactedVersionPackage: "32.0.0-beta", fixVersions: []string{"32.0.0"}, expected: "32.0.0"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s %v", test.tech, test.impactedVersionPackage, test.fixVersions), func(t *testing.T) {
+			assert.Equal(t, test.expected, getMinimalFixVersion(test.tech, test.impactedVersionPackage, test.fixVersions, "", nil, false, false))
+		})
+	}
+	assert.False(t, isPreRelease(techutils.Maven, "32.0.0-jre"))
+	assert.True(t, isPreRelease(techutils.Npm, "32.0.0-beta.1"))
+}
+
 func TestGenerateFixBranchName(t *testing.T) {
 	tests := []struct {
 		baseBranch      string
@@ -355,7 +513,7 @@ func TestGenerateFixBranchName(t *testing.T) {
 	gitManager := utils.GitManager{}
 	for _, test := range tests {
 		t.Run(test.expectedName, func(t *testing.T) {
-			branchName, err := gitManager.GenerateFixBranchName(test.baseBranch, test.impactedPackage, test.fixVersion)
+			branchName, err := gitManager.GenerateFixBranchName(test.baseBranch, test.impactedPackage, test.fixVersion, "")
 			assert.NoError(t, err)
 			assert.Equal(t, test.expectedName, branchName)
 		})
@@ -423,12 +581,455 @@ func TestGetMinimalFixVersion(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.expected, func(t *testing.T) {
-			expected := getMinimalFixVersion(test.impactedVersionPackage, test.fixVersions)
+			expected := getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, test.fixVersions, "", nil, false, false)
 			assert.Equal(t, test.expected, expected)
 		})
 	}
 }
 
+func TestFormatSuggestedFixVersions(t *testing.T) {
+	newVulnerability := func(tech techutils.Technology, impactedVersion string, fixVersions ...string) *formats.VulnerabilityOrViolationRow {
+		return &formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "pkg", ImpactedDependencyVersion: impactedVersion},
+			FixedVersions:             fixVersions,
+			Technology:                tech,
+			ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "pkg"}}},
+		}
+	}
+	tests := []struct {
+		name          string
+		vulnerability *formats.VulnerabilityOrViolationRow
+		expected      string
+	}{
+		{name: "Go", vulnerability: newVulnerability(techutils.Go, "v1.6.2", "[1.6.22]"), expected: "v1.6.22"},
+		{name: "Go with prefixed fix version", vulnerability: newVulnerability(techutils.Go, "v1.6.2", "[v1.6.22]"), expected: "v1.6.22"},
+		{name: "npm", vulnerability: newVulnerability(techutils.Npm, "1.6.2", "[1.6.22]"), expected: "1.6.22"},
+		{name: "npm with prefixed fix version", vulnerability: newVulnerability(techutils.Npm, "1.6.2", "[v1.6.22]"), expected: "1.6.22"},
+		{name: "Maven", vulnerability: newVulnerability(techutils.Maven, "1.6.2", "[1.6.22]"), expected: "1.6.22"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfp := ScanRepositoryCmd{}
+			vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+			require.NoError(t, cfp.addVulnerabilityToFixVersionsMap(test.vulnerability, vulnerabilitiesMap))
+			formatSuggestedFixVersions(vulnerabilitiesMap)
+			require.Contains(t, vulnerabilitiesMap, "pkg")
+			assert.Equal(t, test.expected, vulnerabilitiesMap["pkg"].SuggestedFixedVersion)
+		})
+	}
+}
+
+func TestGetMinimalFixVersionWithCeilingPolicy(t *testing.T) {
+	tests := []struct {
+		name                   string
+		impactedVersionPackage string
+		fixVersions            []string
+		ceilingPolicy          utils.FixVersionCeilingPolicy
+		expected               string
+	}{
+		{name: "same-major within major", impactedVersionPackage: "1.6.2", fixVersions: []string{"1.7.0", "2.0.0"}, ceilingPolicy: utils.SameMajorCeilingPolicy, expected: "1.7.0"},
+		{name: "same-major skips crossing versions", impactedVersionPackage: "v1.6.2", fixVersions: []string{"1.5.3", "1.8.1", "2.0.0"}, ceilingPolicy: utils.SameMajorCeilingPolicy, expected: "1.8.1"},
+		{name: "same-major only crossing fix", impactedVersionPackage: "1.7.1", fixVersions: []string{"2.5.3"}, ceilingPolicy: utils.SameMajorCeilingPolicy, expected: ""},
+		{name: "same-minor within minor", impactedVersionPackage: "1.6.2", fixVersions: []string{"1.6.22", "1.7.0"}, ceilingPolicy: utils.SameMinorCeilingPolicy, expected: "1.6.22"},
+		{name: "same-minor only crossing fix", impactedVersionPackage: "1.6.2", fixVersions: []string{"1.7.0"}, ceilingPolicy: utils.SameMinorCeilingPolicy, expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, test.fixVersions, test.ceilingPolicy, nil, false, false))
+		})
+	}
+}
+
+func TestGetMinimalFixVersionWithMaxVersionJump(t *testing.T) {
+	twoMinor, err := utils.ParseMaxVersionJump("2-minor")
+	require.NoError(t, err)
+	oneMajor, err := utils.ParseMaxVersionJump("1-major")
+	require.NoError(t, err)
+	tests := []struct {
+		name                   string
+		impactedVersionPackage string
+		fixVersions            []string
+		maxVersionJump         *utils.MaxVersionJump
+		expected               string
+	}{
+		{name: "2-minor within the jump", impactedVersionPackage: "1.2.0", fixVersions: []string{"1.4.0"}, maxVersionJump: twoMinor, expected: "1.4.0"},
+		{name: "2-minor skips a 3-minor jump", impactedVersionPackage: "1.2.0", fixVersions: []string{"1.5.0"}, maxVersionJump: twoMinor, expected: ""},
+		{name: "2-minor skips a major jump", impactedVersionPackage: "v1.9.3", fixVersions: []string{"2.0.0"}, maxVersionJump: twoMinor, expected: ""},
+		{name: "1-major within the jump", impactedVersionPackage: "1.9.3", fixVersions: []string{"2.3.0"}, maxVersionJump: oneMajor, expected: "2.3.0"},
+		{name: "1-major skips a 2-major jump", impactedVersionPackage: "1.9.3", fixVersions: []string{"3.0.0"}, maxVersionJump: oneMajor, expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getMinimalFixVersion(techutils.Npm, test.impactedVersionPackage, test.fixVersions, "", test.maxVersionJump, false, false))
+		})
+	}
+}
+
+func TestDeferFixExceedingVersionJump(t *testing.T) {
+	maxVersionJump, err := utils.ParseMaxVersionJump("2-minor")
+	require.NoError(t, err)
+	cfp := ScanRepositoryCmd{maxVersionJump: maxVersionJump, unsupportedFixes: map[string]*utils.ErrUnsupportedFix{}}
+	vulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.14.0"},
+		FixedVersions:             []string{"[4.17.21]"},
+		Technology:                techutils.Npm,
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "lodash"}}},
+	}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	require.NoError(t, cfp.addVulnerabilityToFixVersionsMap(vulnerability, vulnerabilitiesMap))
+
+	// The fix requires a 3-minor jump, so it's deferred instead of fixed
+	assert.Empty(t, vulnerabilitiesMap)
+	unsupportedFix := cfp.getUnsupportedFix(*vulnerability)
+	require.NotNil(t, unsupportedFix)
+	assert.Equal(t, utils.FixExceedsVersionJump, unsupportedFix.ErrorType)
+	assert.Equal(t, "Fix exceeds allowed version jump: updating lodash to version 4.17.21 exceeds the allowed version jump of 2-minor.", unsupportedFix.Error())
+}
+
+func TestDeferFixViolatingCeilingPolicyAndVersionJump(t *testing.T) {
+	maxVersionJump, err := utils.ParseMaxVersionJump("1-major")
+	require.NoError(t, err)
+	cfp := ScanRepositoryCmd{
+		fixVersionCeilingPolicy: utils.SameMajorCeilingPolicy,
+		maxVersionJump:          maxVersionJump,
+		unsupportedFixes:        map[string]*utils.ErrUnsupportedFix{},
+		excludedPackages:        map[string]outputwriter.PackageStatusRow{},
+	}
+	vulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "pkg", ImpactedDependencyVersion: "1.0.0"},
+		FixedVersions:             []string{"3.0.0"},
+		Technology:                techutils.Npm,
+		IssueId:                   "XRAY-1",
+	}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	require.NoError(t, cfp.addVulnerabilityToFixVersionsMap(vulnerability, vulnerabilitiesMap))
+	assert.Empty(t, vulnerabilitiesMap)
+
+	// The only fix crosses the major version and jumps two majors, so it's reported under both policies
+	unsupportedFix := cfp.getUnsupportedFix(*vulnerability)
+	require.NotNil(t, unsupportedFix)
+	assert.Equal(t, utils.FixCrossesVersionCeiling, unsupportedFix.ErrorType)
+	assert.Equal(t, "3.0.0", unsupportedFix.FixedVersion)
+	skippedPackages := cfp.getAggregatedSkippedPackages(nil, nil)
+	require.Len(t, skippedPackages, 1)
+	assert.Equal(t, "3.0.0", skippedPackages[0].TargetVersion)
+	assert.Equal(t, "not allowed by the 'same-major' fix version ceiling policy, and exceeds the allowed version jump of 1-major", skippedPackages[0].SkipReason)
+}
+
+func TestAddVulnerabilityToFixVersionsMapWithCeilingPolicy(t *testing.T) {
+	cfp := ScanRepositoryCmd{fixVersionCeilingPolicy: utils.SameMajorCeilingPolicy, unsupportedFixes: map[string]*utils.ErrUnsupportedFix{}, excludedPackages: map[string]outputwriter.PackageStatusRow{}}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	// The only available fix crosses the major version, so the vulnerability is reported without being added for a fix
+	crossingVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "pkg", ImpactedDependencyVersion: "1.0.0"},
+		FixedVersions:             []string{"2.0.0"},
+		IssueId:                   "XRAY-1",
+	}
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(crossingVulnerability, vulnerabilitiesMap))
+	assert.Empty(t, vulnerabilitiesMap)
+	assert.Equal(t, []string{"2.0.0"}, crossingVulnerability.FixedVersions)
+
+	// The skip is listed with the other unfixable vulnerabilities and skipped packages
+	unsupportedFix := cfp.getUnsupportedFix(*crossingVulnerability)
+	require.NotNil(t, unsupportedFix)
+	assert.Equal(t, utils.FixCrossesVersionCeiling, unsupportedFix.ErrorType)
+	assert.Equal(t, "Only cross-boundary fix available: updating pkg to version 2.0.0 isn't allowed by the 'same-major' fix version ceiling policy.", unsupportedFix.Error())
+	skippedPackages := cfp.getAggregatedSkippedPackages(nil, nil)
+	require.Len(t, skippedPackages, 1)
+	assert.Equal(t, "2.0.0", skippedPackages[0].TargetVersion)
+	assert.Equal(t, "not allowed by the 'same-major' fix version ceiling policy", skippedPackages[0].SkipReason)
+}
+
+func TestAddVulnerabilityToFixVersionsMapMergesCves(t *testing.T) {
+	cfp := ScanRepositoryCmd{}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	impactPaths := [][]formats.ComponentRow{{{Name: "project"}, {Name: "lodash", Version: "4.17.20"}}}
+	for _, vulnerability := range []formats.VulnerabilityOrViolationRow{
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"}, FixedVersions: []string{"[4.17.21]"}, Cves: []formats.CveRow{{Id: "CVE-2020-28500"}}, ImpactPaths: impactPaths},
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"}, FixedVersions: []string{"[4.17.21]"}, Cves: []formats.CveRow{{Id: "CVE-2021-23337"}, {Id: "CVE-2020-8203"}}, ImpactPaths: impactPaths},
+	} {
+		assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(&vulnerability, vulnerabilitiesMap))
+	}
+	// The fix version of the package resolves the CVEs of all its vulnerabilities
+	assert.Equal(t, []string{"CVE-2020-28500", "CVE-2021-23337", "CVE-2020-8203"}, vulnerabilitiesMap["lodash"].ResolvedCves())
+}
+
+func TestAddVulnerabilityToFixVersionsMapWithApplicabilitySeverityAdjustment(t *testing.T) {
+	newVulnerability := func(name, severity, applicability string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: severity, SeverityNumValue: severityutils.GetSeverityPriority(severityutils.Severity(severity), jasutils.ApplicabilityStatus(applicability))},
+				ImpactedDependencyName:    name,
+				ImpactedDependencyVersion: "1.0.0",
+			},
+			Applicable:    applicability,
+			FixedVersions: []string{"[1.0.1]"},
+			Cves:          []formats.CveRow{{Id: "CVE-2024-" + name}},
+			ImpactPaths:   [][]formats.ComponentRow{{{Name: "project"}, {Name: name, Version: "1.0.0"}}},
+		}
+	}
+	adjustment, err := utils.ParseApplicabilitySeverityAdjustment("critical:low")
+	require.NoError(t, err)
+	scanDetails, err := utils.NewScanDetails(nil, nil, &utils.Git{}).SetMinSeverity("High")
+	require.NoError(t, err)
+	cfp := ScanRepositoryCmd{scanDetails: scanDetails, applicabilitySeverityAdjustment: adjustment}
+	vulnerabilities := []formats.VulnerabilityOrViolationRow{
+		newVulnerability("not-applicable-critical", "Critical", jasutils.NotApplicable.String()),
+		newVulnerability("applicable-critical", "Critical", jasutils.Applicable.String()),
+		newVulnerability("not-applicable-high", "High", jasutils.NotApplicable.String()),
+	}
+	cfp.applicabilitySeverityAdjustment.AdjustSeverityScores(vulnerabilities)
+	// The not applicable Critical is ordered as a not applicable Low, after the High
+	assert.Equal(t, "not-applicable-critical", vulnerabilities[2].ImpactedDependencyName)
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	for i := range vulnerabilities {
+		assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(&vulnerabilities[i], vulnerabilitiesMap))
+	}
+	// The not applicable Critical is treated as Low, which is below the minimal severity, so it isn't fixed
+	assert.ElementsMatch(t, []string{"applicable-critical", "not-applicable-high"}, maps.Keys(vulnerabilitiesMap))
+	assert.Equal(t, "Critical", vulnerabilities[2].Severity)
+
+	// Without a minimal severity, the not applicable Critical is fixed, and the original severity is displayed along with the one it is treated as
+	cfp.scanDetails, err = scanDetails.SetMinSeverity("Low")
+	require.NoError(t, err)
+	vulnerabilitiesMap = map[string]*utils.VulnerabilityDetails{}
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(&vulnerabilities[2], vulnerabilitiesMap))
+	require.Contains(t, vulnerabilitiesMap, "not-applicable-critical")
+	assert.Equal(t, "Critical", vulnerabilitiesMap["not-applicable-critical"].Severity)
+	assert.Equal(t, []string{"The vulnerability of not-applicable-critical isn't applicable, so its Critical severity is treated as Low."}, vulnerabilitiesMap["not-applicable-critical"].FixNotes)
+}
+
+func TestSortByResolvedCves(t *testing.T) {
+	newVulnDetails := func(name string, cves ...string) *utils.VulnerabilityDetails {
+		vulnDetails := &utils.VulnerabilityDetails{VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: name}}}
+		for _, cve := range cves {
+			vulnDetails.SetCves([]formats.CveRow{{Id: cve}})
+		}
+		return vulnDetails
+	}
+	vulnerabilities := map[string]*utils.VulnerabilityDetails{
+		"minimist": newVulnDetails("minimist", "CVE-2021-44906"),
+		"lodash":   newVulnDetails("lodash", "CVE-2020-28500", "CVE-2021-23337", "CVE-2020-8203"),
+		"json5":    newVulnDetails("json5", "CVE-2022-46175"),
+		"semver":   newVulnDetails("semver"),
+	}
+	var sortedNames []string
+	for _, vulnDetails := range sortByResolvedCves(vulnerabilities, utils.CvssV3) {
+		sortedNames = append(sortedNames, vulnDetails.ImpactedDependencyName)
+	}
+	assert.Equal(t, []string{"lodash", "json5", "minimist", "semver"}, sortedNames)
+}
+
+func TestSortByResolvedCvesWithCvssVersionPreference(t *testing.T) {
+	newVulnDetails := func(name string, cve formats.CveRow) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: name},
+			Cves:                      []formats.CveRow{cve},
+		}, "")
+	}
+	vulnerabilities := map[string]*utils.VulnerabilityDetails{
+		// Scored higher by CVSS v2, and lower by CVSS v3
+		"minimist": newVulnDetails("minimist", formats.CveRow{Id: "CVE-2021-44906", CvssV2: "7.5", CvssV3: "5.6"}),
+		// Scored only by CVSS v3
+		"json5": newVulnDetails("json5", formats.CveRow{Id: "CVE-2022-46175", CvssV3: "7.1"}),
+		// Scored only by CVSS v2
+		"debug": newVulnDetails("debug", formats.CveRow{Id: "CVE-2017-16137", CvssV2: "5.0"}),
+	}
+	testCases := []struct {
+		preference    utils.CvssVersion
+		expectedOrder []string
+	}{
+		{preference: utils.CvssV3, expectedOrder: []string{"json5", "minimist", "debug"}},
+		{preference: utils.CvssV2, expectedOrder: []string{"minimist", "json5", "debug"}},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.preference), func(t *testing.T) {
+			var sortedNames []string
+			for _, vulnDetails := range sortByResolvedCves(vulnerabilities, tc.preference) {
+				sortedNames = append(sortedNames, vulnDetails.ImpactedDependencyName)
+			}
+			assert.Equal(t, tc.expectedOrder, sortedNames)
+		})
+	}
+}
+
+func TestExcludeInstalledFixes(t *testing.T) {
+	dryRunRepoPath, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	repoDir := filepath.Join(dryRunRepoPath, "npm-repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	// The declared range of minimist was resolved at install time to a version that isn't vulnerable
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(`{"dependencies": {"minimist": "^1.2.5", "lodash": "4.17.10"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package-lock.json"), []byte(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"minimist": "^1.2.5", "lodash": "4.17.10"}},
+    "node_modules/minimist": {"version": "1.2.8"},
+    "node_modules/lodash": {"version": "4.17.10"}
+  }
+}`), 0644))
+	utils.CreateDotGitWithCommit(t, dryRunRepoPath, "", "npm-repo")
+
+	cfp := ScanRepositoryCmd{dryRun: true, dryRunRepoPath: dryRunRepoPath, baseWd: repoDir, scanDetails: &utils.ScanDetails{Git: &utils.Git{RepoName: "npm-repo", Branches: []string{"master"}}}}
+	cfp.gitManager = utils.NewGitManager().SetDryRun(true, dryRunRepoPath)
+	require.NoError(t, cfp.gitManager.Clone(repoDir, "master"))
+	restoreWd, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+
+	newVulnDetails := func(name, impactedVersion, fixVersion string) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: name, ImpactedDependencyVersion: impactedVersion},
+			Technology:                techutils.Npm,
+		}, fixVersion)
+	}
+	vulnerabilities := map[string]*utils.VulnerabilityDetails{
+		"minimist": newVulnDetails("minimist", "1.2.5", "1.2.6"),
+		"lodash":   newVulnDetails("lodash", "4.17.10", "4.17.21"),
+	}
+	cfp.excludeInstalledFixes(repoDir, vulnerabilities)
+	// The fix of lodash is still needed, as the lockfile resolves it to the vulnerable version
+	assert.Equal(t, []string{"lodash"}, maps.Keys(vulnerabilities))
+
+	// No fix branch is created for minimist
+	vulnerabilities = map[string]*utils.VulnerabilityDetails{"minimist": newVulnDetails("minimist", "1.2.5", "1.2.6")}
+	cfp.excludeInstalledFixes(repoDir, vulnerabilities)
+	require.NoError(t, cfp.fixIssuesSeparatePRs(&utils.Repository{}, map[string]map[string]*utils.VulnerabilityDetails{repoDir: vulnerabilities}))
+	branchesHashes, err := cfp.gitManager.GetLocalBranchesHashes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"master"}, maps.Keys(branchesHashes))
+}
+
+func TestAddSecurityBackportNotes(t *testing.T) {
+	cfp := ScanRepositoryCmd{}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	// The minimal fix is on the 1.4.x line, while the vulnerability is also fixed on the newer 2.3.x line
+	backportVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "backported-pkg", ImpactedDependencyVersion: "1.4.2"},
+		FixedVersions:             []string{"[1.4.7]", "[2.3.1]"},
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "backported-pkg"}}},
+		IssueId:                   "XRAY-1",
+	}
+	// The minimal fix is on the newest fixed line
+	latestLineVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "pkg", ImpactedDependencyVersion: "2.3.0"},
+		FixedVersions:             []string{"[1.4.7]", "[2.3.1]"},
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "pkg"}}},
+		IssueId:                   "XRAY-2",
+	}
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(backportVulnerability, vulnerabilitiesMap))
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(latestLineVulnerability, vulnerabilitiesMap))
+	addSecurityBackportNotes(vulnerabilitiesMap)
+
+	backportDetails := vulnerabilitiesMap["backported-pkg"]
+	assert.Equal(t, "1.4.7", backportDetails.SuggestedFixedVersion)
+	assert.Equal(t, []string{"backported-pkg 1.4.7 is a security backport on the 1.4.x line. Newer fixed releases are available on the 2.3.x line."}, backportDetails.FixNotes)
+	assert.Empty(t, vulnerabilitiesMap["pkg"].FixNotes)
+
+	vulnerabilities := []*utils.VulnerabilityDetails{backportDetails, vulnerabilitiesMap["pkg"]}
+	prBody, _ := utils.GenerateFixPullRequestDetails(vulnerabilities, &outputwriter.StandardOutput{})
+	assert.Contains(t, prBody, "security backport on the 1.4.x line")
+}
+
+func TestTransitiveDependencyPullRequestDetails(t *testing.T) {
+	cfp := ScanRepositoryCmd{}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	transitiveVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+			SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+			ImpactedDependencyName:    "minimist",
+			ImpactedDependencyVersion: "1.2.5",
+			Components:                []formats.ComponentRow{{Name: "mkdirp", Version: "0.5.5"}},
+		},
+		FixedVersions: []string{"1.2.6"},
+		ImpactPaths:   [][]formats.ComponentRow{{{Name: "root"}, {Name: "mkdirp", Version: "0.5.5"}, {Name: "minimist", Version: "1.2.5"}}},
+		IssueId:       "XRAY-1",
+	}
+	directVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "uuid", ImpactedDependencyVersion: "3.0.0"},
+		FixedVersions:             []string{"3.0.1"},
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "uuid", Version: "3.0.0"}}},
+		IssueId:                   "XRAY-2",
+	}
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(transitiveVulnerability, vulnerabilitiesMap))
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(directVulnerability, vulnerabilitiesMap))
+	assert.Equal(t, []formats.ComponentRow{{Name: "mkdirp", Version: "0.5.5"}, {Name: "minimist", Version: "1.2.5"}}, vulnerabilitiesMap["minimist"].TransitiveImpactPath)
+	assert.Empty(t, vulnerabilitiesMap["uuid"].TransitiveImpactPath)
+
+	prBody, _ := utils.GenerateFixPullRequestDetails([]*utils.VulnerabilityDetails{vulnerabilitiesMap["minimist"], vulnerabilitiesMap["uuid"]}, &outputwriter.StandardOutput{})
+	assert.Contains(t, prBody, "🔗 Transitive Dependencies")
+	assert.Contains(t, prBody, "| mkdirp:0.5.5 | minimist:1.2.5 | 1.2.6 | mkdirp:0.5.5 → minimist:1.2.5 |")
+	assert.NotContains(t, prBody, "uuid:3.0.0 →")
+}
+
+// The vulnerabilities of the vulnerable packages vuln1 and vuln2
+func getTestVulnerabilities() []services.Vulnerability {
+	return []services.Vulnerability{
+		{
+			Cves: []services.Cve{
+				{Id: "CVE-2023-1234", CvssV3Score: "9.1"},
+				{Id: "CVE-2023-4321", CvssV3Score: "8.9"},
+			},
+			Severity: "Critical",
+			Components: map[string]services.Component{
+				"vuln1": {
+					FixedVersions: []string{"1.9.1", "2.0.3", "2.0.5"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "vuln1"}}},
+				},
+			},
+		},
+		{
+			Cves: []services.Cve{
+				{Id: "CVE-2022-1234", CvssV3Score: "7.1"},
+				{Id: "CVE-2022-4321", CvssV3Score: "7.9"},
+			},
+			Severity: "High",
+			Components: map[string]services.Component{
+				"vuln2": {
+					FixedVersions: []string{"2.4.1", "2.6.3", "2.8.5"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "vuln1"}, {ComponentId: "vuln2"}}},
+				},
+			},
+		},
+	}
+}
+
+// The security violations of the vulnerable packages viol1 and viol2
+func getTestViolations() []services.Violation {
+	return []services.Violation{
+		{
+			ViolationType: "security",
+			Cves: []services.Cve{
+				{Id: "CVE-2023-1234", CvssV3Score: "9.1"},
+				{Id: "CVE-2023-4321", CvssV3Score: "8.9"},
+			},
+			Severity: "Critical",
+			Components: map[string]services.Component{
+				"viol1": {
+					FixedVersions: []string{"1.9.1", "2.0.3", "2.0.5"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "viol1"}}},
+				},
+			},
+		},
+		{
+			ViolationType: "security",
+			Cves: []services.Cve{
+				{Id: "CVE-2022-1234", CvssV3Score: "7.1"},
+				{Id: "CVE-2022-4321", CvssV3Score: "7.9"},
+			},
+			Severity: "High",
+			Components: map[string]services.Component{
+				"viol2": {
+					FixedVersions: []string{"2.4.1", "2.6.3", "2.8.5"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "viol1"}, {ComponentId: "viol2"}}},
+				},
+			},
+		},
+	}
+}
+
 func TestCreateVulnerabilitiesMap(t *testing.T) {
 	cfp := &ScanRepositoryCmd{}
 
@@ -451,34 +1052,7 @@ func TestCreateVulnerabilitiesMap(t *testing.T) {
 				ScaResults: []*xrayutils.ScaScanResult{{
 					XrayResults: []services.ScanResponse{
 						{
-							Vulnerabilities: []services.Vulnerability{
-								{
-									Cves: []services.Cve{
-										{Id: "CVE-2023-1234", CvssV3Score: "9.1"},
-										{Id: "CVE-2023-4321", CvssV3Score: "8.9"},
-									},
-									Severity: "Critical",
-									Components: map[string]services.Component{
-										"vuln1": {
-											FixedVersions: []string{"1.9.1", "2.0.3", "2.0.5"},
-											ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "vuln1"}}},
-										},
-									},
-								},
-								{
-									Cves: []services.Cve{
-										{Id: "CVE-2022-1234", CvssV3Score: "7.1"},
-										{Id: "CVE-2022-4321", CvssV3Score: "7.9"},
-									},
-									Severity: "High",
-									Components: map[string]services.Component{
-										"vuln2": {
-											FixedVersions: []string{"2.4.1", "2.6.3", "2.8.5"},
-											ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "vuln1"}, {ComponentId: "vuln2"}}},
-										},
-									},
-								},
-							},
+							Vulnerabilities: getTestVulnerabilities(),
 						},
 					},
 				}},
@@ -502,36 +1076,7 @@ func TestCreateVulnerabilitiesMap(t *testing.T) {
 				ScaResults: []*xrayutils.ScaScanResult{{
 					XrayResults: []services.ScanResponse{
 						{
-							Violations: []services.Violation{
-								{
-									ViolationType: "security",
-									Cves: []services.Cve{
-										{Id: "CVE-2023-1234", CvssV3Score: "9.1"},
-										{Id: "CVE-2023-4321", CvssV3Score: "8.9"},
-									},
-									Severity: "Critical",
-									Components: map[string]services.Component{
-										"viol1": {
-											FixedVersions: []string{"1.9.1", "2.0.3", "2.0.5"},
-											ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "viol1"}}},
-										},
-									},
-								},
-								{
-									ViolationType: "security",
-									Cves: []services.Cve{
-										{Id: "CVE-2022-1234", CvssV3Score: "7.1"},
-										{Id: "CVE-2022-4321", CvssV3Score: "7.9"},
-									},
-									Severity: "High",
-									Components: map[string]services.Component{
-										"viol2": {
-											FixedVersions: []string{"2.4.1", "2.6.3", "2.8.5"},
-											ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "viol1"}, {ComponentId: "viol2"}}},
-										},
-									},
-								},
-							},
+							Violations: getTestViolations(),
 						},
 					},
 				}},
@@ -566,6 +1111,248 @@ func TestCreateVulnerabilitiesMap(t *testing.T) {
 	}
 }
 
+func TestCreateVulnerabilitiesMapWithFixSource(t *testing.T) {
+	newScanResults := func(scanResponse services.ScanResponse) *xrayutils.Results {
+		return &xrayutils.Results{
+			ScaResults:          []*xrayutils.ScaScanResult{{XrayResults: []services.ScanResponse{scanResponse}}},
+			ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+		}
+	}
+	// With a watch, the vulnerabilities are returned along with the violations only if all the vulnerabilities are requested
+	allResults := newScanResults(services.ScanResponse{Vulnerabilities: getTestVulnerabilities(), Violations: getTestViolations()})
+	violationsResults := newScanResults(services.ScanResponse{Violations: getTestViolations()})
+	vulnerabilitiesResults := newScanResults(services.ScanResponse{Vulnerabilities: getTestVulnerabilities()})
+
+	testCases := []struct {
+		fixSource        utils.FixSource
+		scanResults      *xrayutils.Results
+		expectedPackages []string
+	}{
+		{fixSource: utils.BothFixSource, scanResults: allResults, expectedPackages: []string{"vuln1", "vuln2"}},
+		{fixSource: utils.BothFixSource, scanResults: violationsResults, expectedPackages: []string{"viol1", "viol2"}},
+		{fixSource: utils.BothFixSource, scanResults: vulnerabilitiesResults, expectedPackages: []string{"vuln1", "vuln2"}},
+		{fixSource: utils.ViolationsFixSource, scanResults: allResults, expectedPackages: []string{"viol1", "viol2"}},
+		{fixSource: utils.ViolationsFixSource, scanResults: violationsResults, expectedPackages: []string{"viol1", "viol2"}},
+		{fixSource: utils.ViolationsFixSource, scanResults: vulnerabilitiesResults, expectedPackages: []string{}},
+		{fixSource: utils.VulnerabilitiesFixSource, scanResults: allResults, expectedPackages: []string{"vuln1", "vuln2"}},
+		{fixSource: utils.VulnerabilitiesFixSource, scanResults: violationsResults, expectedPackages: []string{}},
+		{fixSource: utils.VulnerabilitiesFixSource, scanResults: vulnerabilitiesResults, expectedPackages: []string{"vuln1", "vuln2"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(string(testCase.fixSource), func(t *testing.T) {
+			cfp := &ScanRepositoryCmd{fixSource: testCase.fixSource}
+			vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(testCase.scanResults, false)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, testCase.expectedPackages, maps.Keys(vulnerabilitiesMap))
+		})
+	}
+}
+
+func TestCreateVulnerabilitiesMapWithLicenseViolations(t *testing.T) {
+	licenseViolation := services.Violation{
+		ViolationType: "license",
+		LicenseKey:    "GPL-3.0",
+		Severity:      "High",
+		Components: map[string]services.Component{
+			"npm://copyleft:1.0.0": {
+				ImpactPaths: [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "npm://copyleft:1.0.0"}}},
+			},
+		},
+	}
+	newScanResults := func(tech techutils.Technology) *xrayutils.Results {
+		return &xrayutils.Results{
+			ScaResults:          []*xrayutils.ScaScanResult{{Technology: tech, XrayResults: []services.ScanResponse{{Violations: append(getTestViolations(), licenseViolation)}}}},
+			ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+		}
+	}
+	testCases := []struct {
+		name                 string
+		tech                 techutils.Technology
+		fixLicenseViolations bool
+		workspacePackages    []string
+		expectedViolations   int
+	}{
+		{name: "reported without a fix", tech: techutils.Npm, expectedViolations: 1},
+		// The licenses of Maven packages can't be looked up, so the violation is only reported
+		{name: "unsupported fix", tech: techutils.Maven, fixLicenseViolations: true, expectedViolations: 1},
+		{name: "workspace package", tech: techutils.Npm, workspacePackages: []string{"copyleft"}},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfp := &ScanRepositoryCmd{fixLicenseViolations: test.fixLicenseViolations, workspacePackages: test.workspacePackages}
+			vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(newScanResults(test.tech), false)
+			require.NoError(t, err)
+			// The security violations are fixed as before
+			assert.ElementsMatch(t, []string{"viol1", "viol2"}, maps.Keys(vulnerabilitiesMap))
+			require.Len(t, cfp.branchLicenseViolations, test.expectedViolations)
+			if test.expectedViolations > 0 {
+				assert.Equal(t, "copyleft", cfp.branchLicenseViolations[0].ImpactedDependencyName)
+				assert.Equal(t, "1.0.0", cfp.branchLicenseViolations[0].ImpactedDependencyVersion)
+				assert.Equal(t, "GPL-3.0", cfp.branchLicenseViolations[0].LicenseKey)
+			}
+		})
+	}
+}
+
+func TestLicenseViolationRow(t *testing.T) {
+	licenseViolation := formats.LicenseRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "copyleft", ImpactedDependencyVersion: "1.0.0"},
+		LicenseKey:                "GPL-3.0",
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "copyleft", Version: "1.0.0"}}},
+	}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	vulnerability := toLicenseViolationRow(licenseViolation, techutils.Npm, "2.0.0")
+	require.NoError(t, (&ScanRepositoryCmd{}).addVulnerabilityToFixVersionsMap(&vulnerability, vulnerabilitiesMap))
+	require.Contains(t, vulnerabilitiesMap, "copyleft")
+	assert.Equal(t, "2.0.0", vulnerabilitiesMap["copyleft"].SuggestedFixedVersion)
+	assert.Equal(t, techutils.Npm, vulnerabilitiesMap["copyleft"].Technology)
+}
+
+func TestCreateVulnerabilitiesMapWithCveFilters(t *testing.T) {
+	newVulnerability := func(component string, cves ...string) services.Vulnerability {
+		vulnerability := services.Vulnerability{
+			Severity: "Critical",
+			Components: map[string]services.Component{
+				component: {
+					FixedVersions: []string{"2.17.1"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: component}}},
+				},
+			},
+		}
+		for _, cve := range cves {
+			vulnerability.Cves = append(vulnerability.Cves, services.Cve{Id: cve})
+		}
+		return vulnerability
+	}
+	scanResults := &xrayutils.Results{
+		ScaResults: []*xrayutils.ScaScanResult{{
+			XrayResults: []services.ScanResponse{{
+				Vulnerabilities: []services.Vulnerability{
+					newVulnerability("log4j-core", "CVE-2021-44228"),
+					newVulnerability("log4j-api", "CVE-2021-45046", "CVE-2021-44228"),
+					newVulnerability("commons-text", "CVE-2022-42889"),
+					newVulnerability("snakeyaml", "CVE-2022-1471"),
+				},
+			}},
+		}},
+		ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+	}
+	testCases := []struct {
+		name             string
+		onlyCves         []string
+		excludeCves      []string
+		expectedPackages []string
+	}{
+		{name: "No filters", expectedPackages: []string{"log4j-core", "log4j-api", "commons-text", "snakeyaml"}},
+		{name: "Single included CVE", onlyCves: []string{"CVE-2021-44228"}, expectedPackages: []string{"log4j-core", "log4j-api"}},
+		{name: "Excluded CVE", excludeCves: []string{"CVE-2021-45046", "CVE-2022-1471"}, expectedPackages: []string{"log4j-core", "commons-text"}},
+		{name: "Included and excluded CVEs", onlyCves: []string{"CVE-2021-44228"}, excludeCves: []string{"CVE-2021-45046"}, expectedPackages: []string{"log4j-core"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfp := &ScanRepositoryCmd{
+				onlyCves:         testCase.onlyCves,
+				excludeCves:      testCase.excludeCves,
+				junitOutput:      filepath.Join(t.TempDir(), "frogbot.xml"),
+				unsupportedFixes: map[string]*utils.ErrUnsupportedFix{},
+			}
+			vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(scanResults, false)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, testCase.expectedPackages, maps.Keys(vulnerabilitiesMap))
+
+			// The vulnerabilities that don't match the CVE filters are missing from the reports too
+			require.NoError(t, cfp.writeJunitReport())
+			content, err := os.ReadFile(cfp.junitOutput)
+			require.NoError(t, err)
+			var report utils.JunitTestSuites
+			require.NoError(t, xml.Unmarshal(content, &report))
+			var reportedPackages []string
+			for _, testSuite := range report.TestSuites {
+				reportedPackages = append(reportedPackages, strings.TrimSuffix(testSuite.Name, ":"))
+			}
+			assert.ElementsMatch(t, testCase.expectedPackages, reportedPackages)
+		})
+	}
+}
+
+func TestCreateVulnerabilitiesMapWithIgnoreRules(t *testing.T) {
+	newVulnerability := func(component, cve, fixVersion string, impactPaths ...[]string) services.Vulnerability {
+		var componentImpactPaths [][]services.ImpactPathNode
+		for _, impactPath := range impactPaths {
+			componentImpactPath := []services.ImpactPathNode{{ComponentId: "npm://my-app:1.0.0"}}
+			for _, dependency := range impactPath {
+				componentImpactPath = append(componentImpactPath, services.ImpactPathNode{ComponentId: dependency})
+			}
+			componentImpactPaths = append(componentImpactPaths, componentImpactPath)
+		}
+		return services.Vulnerability{
+			Severity:   "High",
+			Cves:       []services.Cve{{Id: cve}},
+			Components: map[string]services.Component{component: {FixedVersions: []string{fixVersion}, ImpactPaths: componentImpactPaths}},
+		}
+	}
+	scanResults := &xrayutils.Results{
+		ScaResults: []*xrayutils.ScaScanResult{{
+			XrayResults: []services.ScanResponse{{
+				Vulnerabilities: []services.Vulnerability{
+					// Reached only through the test harness
+					newVulnerability("npm://minimist:1.2.5", "CVE-2021-44906", "1.2.6", []string{"npm://jest:29.0.0", "npm://minimist:1.2.5"}),
+					// Reached through both the test harness and the production code
+					newVulnerability("npm://qs:6.5.2", "CVE-2022-24999", "6.5.3", []string{"npm://jest:29.0.0", "npm://qs:6.5.2"}, []string{"npm://express:4.17.0", "npm://qs:6.5.2"}),
+					// Reached only through the production code
+					newVulnerability("npm://semver:5.7.1", "CVE-2022-25883", "5.7.2", []string{"npm://express:4.17.0", "npm://semver:5.7.1"}),
+				},
+			}},
+		}},
+		ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+	}
+	testCases := []struct {
+		name             string
+		ignoreRules      []utils.IgnoreRule
+		expectedPackages []string
+	}{
+		{name: "No rules", expectedPackages: []string{"minimist", "qs", "semver"}},
+		{name: "Ignored dependency", ignoreRules: []utils.IgnoreRule{{DependencyPath: "jest"}}, expectedPackages: []string{"qs", "semver"}},
+		{name: "Ignored consecutive dependencies", ignoreRules: []utils.IgnoreRule{{DependencyPath: "jest > minimist"}}, expectedPackages: []string{"qs", "semver"}},
+		{name: "Ignored dependency version", ignoreRules: []utils.IgnoreRule{{DependencyPath: "jest:28.*"}}, expectedPackages: []string{"minimist", "qs", "semver"}},
+		{name: "All paths ignored", ignoreRules: []utils.IgnoreRule{{DependencyPath: "jest"}, {DependencyPath: "express > *"}}, expectedPackages: []string{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfp := &ScanRepositoryCmd{ignoreRules: testCase.ignoreRules}
+			vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(scanResults, false)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, testCase.expectedPackages, maps.Keys(vulnerabilitiesMap))
+		})
+	}
+}
+
+func TestNormalizeImpactedPackageName(t *testing.T) {
+	testCases := []struct {
+		tech            techutils.Technology
+		name            string
+		impactedVersion string
+		expectedName    string
+	}{
+		{tech: techutils.Go, name: "go://github.com/gin-gonic/gin", impactedVersion: "v1.9.0", expectedName: "github.com/gin-gonic/gin"},
+		{tech: techutils.Go, name: "go://github.com/gin-gonic/gin:v1.9.0", impactedVersion: "v1.9.0", expectedName: "github.com/gin-gonic/gin"},
+		{tech: techutils.Go, name: "golang.org/x/net", impactedVersion: "v0.7.0", expectedName: "golang.org/x/net"},
+		{tech: techutils.Npm, name: "npm://lodash", impactedVersion: "4.17.20", expectedName: "lodash"},
+		{tech: techutils.Npm, name: "npm://@types/node:18.0.0", impactedVersion: "18.0.0", expectedName: "@types/node"},
+		{tech: techutils.Yarn, name: "minimist", impactedVersion: "1.2.5", expectedName: "minimist"},
+		{tech: techutils.Maven, name: "gav://org.yaml:snakeyaml:1.33", impactedVersion: "1.33", expectedName: "org.yaml:snakeyaml"},
+		{tech: techutils.Maven, name: "org.yaml:snakeyaml", impactedVersion: "1.33", expectedName: "org.yaml:snakeyaml"},
+		{tech: techutils.Gradle, name: "gav://org.apache.logging.log4j:log4j-core:2.14.1:jar", impactedVersion: "2.14.1", expectedName: "org.apache.logging.log4j:log4j-core"},
+		{tech: techutils.Maven, name: "gav://org.apache.logging.log4j:log4j-core:2.14.1:jar", impactedVersion: "2.14.1", expectedName: "org.apache.logging.log4j:log4j-core"},
+		{tech: techutils.Maven, name: "gav://org.apache.logging.log4j:log4j-core:jar:tests:2.14.1", impactedVersion: "2.14.1", expectedName: "org.apache.logging.log4j:log4j-core:jar:tests"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedName, normalizeImpactedPackageName(testCase.tech, testCase.name, testCase.impactedVersion))
+		})
+	}
+}
+
 // Verifies unsupported packages return specific error
 // Other logic is implemented inside each package-handler.
 func TestUpdatePackageToFixedVersion(t *testing.T) {
@@ -596,6 +1383,36 @@ random body
 	assert.Equal(t, "", result)
 }
 
+func TestGetRemoteBranchScanHashWithZeroWidthChecksumStorage(t *testing.T) {
+	// A provider that sanitizes the pull request body strips the Markdown comments from it
+	commentRegex := regexp.MustCompile(`(?m)^\[comment\]: <> \(.*\)$`)
+	vulnerabilities := []*utils.VulnerabilityDetails{
+		{
+			VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "package1", ImpactedDependencyVersion: "1.0.0"},
+				Cves:                      []formats.CveRow{{Id: "CVE-2022-1234"}},
+			},
+			SuggestedFixedVersion: "1.0.1",
+		},
+	}
+	cfp := ScanRepositoryCmd{OutputWriter: &outputwriter.StandardOutput{}, gitManager: &utils.GitManager{}, aggregateFixes: true, minPrUpdateInterval: time.Hour}
+	_, prBody, _, err := cfp.preparePullRequestDetails(vulnerabilities...)
+	require.NoError(t, err)
+	checksum := cfp.getRemoteBranchScanHash(prBody)
+	require.NotEmpty(t, checksum)
+	sanitizedBody := commentRegex.ReplaceAllString(prBody, "")
+	assert.Empty(t, cfp.getRemoteBranchScanHash(sanitizedBody))
+
+	// The zero-width markers aren't comments, so the checksum and the last update time are recovered from the sanitized body
+	cfp.checksumStorage = utils.ZeroWidthChecksumStorage
+	_, prBody, _, err = cfp.preparePullRequestDetails(vulnerabilities...)
+	require.NoError(t, err)
+	assert.NotContains(t, prBody, "Checksum: ")
+	sanitizedBody = commentRegex.ReplaceAllString(prBody, "")
+	assert.Equal(t, checksum, cfp.getRemoteBranchScanHash(sanitizedBody))
+	assert.WithinDuration(t, time.Now(), getPullRequestLastUpdateTime(sanitizedBody), time.Minute)
+}
+
 func TestPreparePullRequestDetails(t *testing.T) {
 	cfp := ScanRepositoryCmd{OutputWriter: &outputwriter.StandardOutput{}, gitManager: &utils.GitManager{}}
 	cfp.OutputWriter.SetJasOutputFlags(true, false)
@@ -614,7 +1431,8 @@ func TestPreparePullRequestDetails(t *testing.T) {
 			SuggestedFixedVersion: "1.0.0",
 		},
 	}
-	expectedPrBody, expectedExtraComments := utils.GenerateFixPullRequestDetails(utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilities), cfp.OutputWriter)
+	expectedPrBody, expectedExtraComments := utils.GenerateFixPullRequestDetails(vulnerabilities, cfp.OutputWriter)
+	expectedPrBody += outputwriter.MarkdownComment("Fixed packages: package1@1.0.0")
 	prTitle, prBody, extraComments, err := cfp.preparePullRequestDetails(vulnerabilities...)
 	assert.NoError(t, err)
 	assert.Equal(t, "[🐸 Frogbot] Update version of package1 to 1.0.0", prTitle)
@@ -634,21 +1452,253 @@ func TestPreparePullRequestDetails(t *testing.T) {
 		SuggestedFixedVersion: "2.0.0",
 	})
 	cfp.aggregateFixes = true
-	expectedPrBody, expectedExtraComments = utils.GenerateFixPullRequestDetails(utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilities), cfp.OutputWriter)
-	expectedPrBody += outputwriter.MarkdownComment("Checksum: bec823edaceb5d0478b789798e819bde")
+	expectedPrBody, expectedExtraComments = utils.GenerateAggregatedFixPullRequestDetails(vulnerabilities, nil, cfp.OutputWriter)
+	expectedPrBody += outputwriter.MarkdownComment("Fixed packages: package1@1.0.0, package2@2.0.0")
+	expectedPrBody += outputwriter.MarkdownComment("Checksum: 5ea43c0ba62ce318f69dbeade18a44dd")
 	prTitle, prBody, extraComments, err = cfp.preparePullRequestDetails(vulnerabilities...)
 	assert.NoError(t, err)
 	assert.Equal(t, cfp.gitManager.GenerateAggregatedPullRequestTitle([]techutils.Technology{}), prTitle)
 	assert.Equal(t, expectedPrBody, prBody)
 	assert.ElementsMatch(t, expectedExtraComments, extraComments)
 	cfp.OutputWriter = &outputwriter.SimplifiedOutput{}
-	expectedPrBody, expectedExtraComments = utils.GenerateFixPullRequestDetails(utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilities), cfp.OutputWriter)
-	expectedPrBody += outputwriter.MarkdownComment("Checksum: bec823edaceb5d0478b789798e819bde")
+	expectedPrBody, expectedExtraComments = utils.GenerateAggregatedFixPullRequestDetails(vulnerabilities, nil, cfp.OutputWriter)
+	expectedPrBody += outputwriter.MarkdownComment("Fixed packages: package1@1.0.0, package2@2.0.0")
+	expectedPrBody += outputwriter.MarkdownComment("Checksum: 5ea43c0ba62ce318f69dbeade18a44dd")
 	prTitle, prBody, extraComments, err = cfp.preparePullRequestDetails(vulnerabilities...)
 	assert.NoError(t, err)
 	assert.Equal(t, cfp.gitManager.GenerateAggregatedPullRequestTitle([]techutils.Technology{}), prTitle)
 	assert.Equal(t, expectedPrBody, prBody)
 	assert.ElementsMatch(t, expectedExtraComments, extraComments)
+	// The checksum doesn't depend on the output format
+	assert.Equal(t, "5ea43c0ba62ce318f69dbeade18a44dd", cfp.getRemoteBranchScanHash(prBody))
+}
+
+func TestMatchesLegacyChecksum(t *testing.T) {
+	vulnerabilities := []*utils.VulnerabilityDetails{
+		utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "mpath", ImpactedDependencyVersion: "0.7.0"},
+			FixedVersions:             []string{"0.8.4"},
+			IssueId:                   "XRAY-186826",
+			Cves:                      []formats.CveRow{{Id: "CVE-2021-23438"}},
+		}, "0.8.4"),
+	}
+	legacyChecksum, err := utils.VulnerabilityDetailsToMD5Hash(utils.ExtractVulnerabilitiesDetailsToRows(vulnerabilities)...)
+	require.NoError(t, err)
+	checksum, err := utils.FixedPackagesChecksum(utils.NewHasher("", 0), vulnerabilities...)
+	require.NoError(t, err)
+	require.NotEqual(t, legacyChecksum, checksum)
+
+	// A pull request that recorded the checksum of the vulnerabilities rows is in sync with the same fixes
+	isInSync, err := matchesLegacyChecksum(vulnerabilities, legacyChecksum)
+	require.NoError(t, err)
+	assert.True(t, isInSync)
+	// Once the fixes change, it's updated
+	vulnerabilities[0].ImpactedDependencyVersion = "0.7.1"
+	isInSync, err = matchesLegacyChecksum(vulnerabilities, legacyChecksum)
+	require.NoError(t, err)
+	assert.False(t, isInSync)
+	// A pull request without a checksum is always updated
+	isInSync, err = matchesLegacyChecksum(nil, "")
+	require.NoError(t, err)
+	assert.False(t, isInSync)
+}
+
+func TestAggregatedPullRequestPackagesStatus(t *testing.T) {
+	cfp := ScanRepositoryCmd{
+		OutputWriter:     &outputwriter.StandardOutput{},
+		gitManager:       &utils.GitManager{},
+		aggregateFixes:   true,
+		unsupportedFixes: map[string]*utils.ErrUnsupportedFix{},
+		excludedPackages: map[string]outputwriter.PackageStatusRow{},
+	}
+	newVulnDetails := func(name, impactedVersion, fixVersion, severity string) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: severity},
+				ImpactedDependencyName:    name,
+				ImpactedDependencyVersion: impactedVersion,
+			},
+			FixedVersions: []string{fixVersion},
+			Cves:          []formats.CveRow{{Id: "CVE-2024-" + name}},
+		}, fixVersion)
+	}
+	minimist := newVulnDetails("minimist", "1.2.5", "1.2.6", "Critical")
+	lodash := newVulnDetails("lodash", "4.17.19", "4.17.21", "High")
+	vulnerabilitiesMap := map[string]map[string]*utils.VulnerabilityDetails{"root": {"minimist": minimist, "lodash": lodash}}
+	// lodash is an indirect dependency, and can't be fixed
+	cfp.recordUnsupportedFix(lodash, &utils.ErrUnsupportedFix{PackageName: "lodash", FixedVersion: "4.17.21", ErrorType: utils.IndirectDependencyFixNotSupported})
+	// The CVE filters exclude json5
+	cfp.recordExcludedPackage(&formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+		SeverityDetails: formats.SeverityDetails{Severity: "Medium"}, ImpactedDependencyName: "json5", ImpactedDependencyVersion: "1.0.1",
+	}}, "", "excluded by the CVE filters")
+
+	cfp.aggregatedSkippedPackages = cfp.getAggregatedSkippedPackages(vulnerabilitiesMap, []*utils.VulnerabilityDetails{minimist})
+	_, prBody, _, err := cfp.preparePullRequestDetails(minimist)
+	require.NoError(t, err)
+	assert.Contains(t, prBody, "📋 Packages Status")
+	assert.Contains(t, prBody, "| minimist | 1.2.5 | 1.2.6 | "+cfp.OutputWriter.FormattedSeverity("Critical", "")+" | ✅ Fixed |")
+	assert.Contains(t, prBody, "| json5 | 1.0.1 | - | "+cfp.OutputWriter.FormattedSeverity("Medium", "")+" | ⏭️ Skipped: excluded by the CVE filters |")
+	assert.Contains(t, prBody, "| lodash | 4.17.19 | 4.17.21 | "+cfp.OutputWriter.FormattedSeverity("High", "")+" | ⏭️ Skipped: indirect dependency |")
+	// The packages status table is above the details of the fixes
+	assert.Less(t, strings.Index(prBody, "📋 Packages Status"), strings.Index(prBody, "📦 Vulnerable Dependencies"))
+
+	// The excluded packages are only listed in the first part of a split aggregated fix
+	cfp.aggregatedPullRequestPart = 2
+	skippedPackages := cfp.getAggregatedSkippedPackages(vulnerabilitiesMap, []*utils.VulnerabilityDetails{minimist})
+	require.Len(t, skippedPackages, 1)
+	assert.Equal(t, "lodash", skippedPackages[0].PackageName)
+}
+
+func TestPreparePullRequestDetailsWithApplicabilityEvidence(t *testing.T) {
+	cfp := ScanRepositoryCmd{OutputWriter: &outputwriter.StandardOutput{}, gitManager: &utils.GitManager{}}
+	cfp.OutputWriter.SetJasOutputFlags(true, true)
+	evidence := []formats.Evidence{
+		{Location: formats.Location{File: "src/app.js", StartLine: 12, Snippet: "const merged = _.merge({}, `${userInput}`)"}},
+		{Location: formats.Location{File: "src/utils.js", StartLine: 3, Snippet: strings.Repeat("a", 200)}},
+		{Location: formats.Location{File: "src/index.js", StartLine: 40}},
+		{Location: formats.Location{File: "src/other.js", StartLine: 7, Snippet: "_.merge(a, b)"}},
+	}
+	vulnerabilities := []*utils.VulnerabilityDetails{
+		{
+			VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+				Summary: "summary",
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+					SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+					ImpactedDependencyName:    "lodash",
+					ImpactedDependencyVersion: "4.17.0",
+				},
+				Applicable:    "Applicable",
+				FixedVersions: []string{"4.17.21"},
+				Cves:          []formats.CveRow{{Id: "CVE-2021-23337", Applicability: &formats.Applicability{Status: "Applicable", Evidence: evidence}}},
+			},
+			SuggestedFixedVersion: "4.17.21",
+		},
+	}
+	_, prBody, _, err := cfp.preparePullRequestDetails(vulnerabilities...)
+	assert.NoError(t, err)
+	assert.NotContains(t, prBody, "Applicability Evidence")
+
+	cfp.OutputWriter.SetShowApplicabilityEvidence(true)
+	_, prBody, _, err = cfp.preparePullRequestDetails(vulnerabilities...)
+	assert.NoError(t, err)
+	assert.Contains(t, prBody, "<summary> <b>🔍 Applicability Evidence</b> </summary>")
+	assert.Contains(t, prBody, "**CVE-2021-23337** lodash:4.17.0")
+	assert.Contains(t, prBody, "- `src/app.js:12` `const merged = _.merge({}, '${userInput}')`")
+	assert.Contains(t, prBody, "- `src/utils.js:3` `"+strings.Repeat("a", 120)+"...`")
+	assert.Contains(t, prBody, "- `src/index.js:40`\n")
+	assert.Contains(t, prBody, "- and 1 more")
+	assert.NotContains(t, prBody, "src/other.js")
+}
+
+func TestRenderDryRunPullRequest(t *testing.T) {
+	var dryRunOutput bytes.Buffer
+	cfp := ScanRepositoryCmd{
+		OutputWriter:   &outputwriter.StandardOutput{},
+		gitManager:     &utils.GitManager{},
+		scanDetails:    utils.NewScanDetails(nil, nil, &utils.Git{}).SetBaseBranch("master"),
+		dryRun:         true,
+		dryRunOutput:   &dryRunOutput,
+		aggregateFixes: true,
+	}
+	vulnerabilities := []*utils.VulnerabilityDetails{
+		{
+			VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+				Summary: "summary",
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+					SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+					ImpactedDependencyName:    "package1",
+					ImpactedDependencyVersion: "1.0.0",
+				},
+				FixedVersions: []string{"1.0.0", "2.0.0"},
+				Cves:          []formats.CveRow{{Id: "CVE-2022-1234"}},
+			},
+			SuggestedFixedVersion: "1.0.0",
+		},
+	}
+	prTitle, prBody, extraComments, err := cfp.preparePullRequestDetails(vulnerabilities...)
+	assert.NoError(t, err)
+	assert.NoError(t, cfp.renderDryRunPullRequest("frogbot-update-dependencies-master", prTitle, prBody, extraComments, false))
+
+	scanHash, err := utils.FixedPackagesChecksum(utils.NewHasher(utils.Md5HashAlgorithm, 0), vulnerabilities...)
+	assert.NoError(t, err)
+	assert.Contains(t, dryRunOutput.String(), "Title: "+cfp.gitManager.GenerateAggregatedPullRequestTitle(nil))
+	assert.Contains(t, dryRunOutput.String(), "Pull request from: frogbot-update-dependencies-master to: master")
+	assert.Contains(t, dryRunOutput.String(), outputwriter.MarkdownComment("Checksum: "+scanHash))
+	assert.Contains(t, dryRunOutput.String(), prBody)
+}
+
+func TestChunkAggregatedPullRequests(t *testing.T) {
+	var dryRunOutput bytes.Buffer
+	cfp := ScanRepositoryCmd{
+		OutputWriter:     &outputwriter.StandardOutput{},
+		gitManager:       &utils.GitManager{},
+		scanDetails:      utils.NewScanDetails(nil, nil, &utils.Git{}).SetBaseBranch("master"),
+		dryRun:           true,
+		dryRunOutput:     &dryRunOutput,
+		aggregateFixes:   true,
+		maxPackagesPerPr: 4,
+	}
+	vulnerabilitiesMap := map[string]map[string]*utils.VulnerabilityDetails{"root": {}, "root/sub": {}}
+	for i := 0; i < 10; i++ {
+		packageName := fmt.Sprintf("package%02d", i)
+		fullPath := "root"
+		if i%2 == 1 {
+			fullPath = "root/sub"
+		}
+		vulnerabilitiesMap[fullPath][packageName] = &utils.VulnerabilityDetails{
+			VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+					SeverityDetails:           formats.SeverityDetails{Severity: "High", SeverityNumValue: 10},
+					ImpactedDependencyName:    packageName,
+					ImpactedDependencyVersion: "1.0.0",
+				},
+				Cves: []formats.CveRow{{Id: fmt.Sprintf("CVE-2024-%04d", i)}},
+			},
+			SuggestedFixedVersion: "2.0.0",
+		}
+	}
+
+	chunks := chunkVulnerabilities(vulnerabilitiesMap, cfp.maxPackagesPerPr)
+	require.Len(t, chunks, 3)
+	var chunkedPackages [][]string
+	for i, chunk := range chunks {
+		var partVulnerabilities []*utils.VulnerabilityDetails
+		for _, vulnerabilities := range chunk {
+			partVulnerabilities = append(partVulnerabilities, maps.Values(vulnerabilities)...)
+		}
+		var packageNames []string
+		for _, vulnDetails := range partVulnerabilities {
+			packageNames = append(packageNames, vulnDetails.ImpactedDependencyName)
+		}
+		sort.Strings(packageNames)
+		chunkedPackages = append(chunkedPackages, packageNames)
+
+		cfp.aggregatedPullRequestPart = i + 1
+		fixBranchName := cfp.gitManager.GenerateAggregatedFixPartBranchName("master", nil, cfp.aggregatedPullRequestPart)
+		prTitle, prBody, extraComments, err := cfp.preparePullRequestDetails(partVulnerabilities...)
+		assert.NoError(t, err)
+		assert.NoError(t, cfp.renderDryRunPullRequest(fixBranchName, prTitle, prBody, extraComments, false))
+	}
+	assert.Equal(t, [][]string{
+		{"package00", "package01", "package02", "package03"},
+		{"package04", "package05", "package06", "package07"},
+		{"package08", "package09"},
+	}, chunkedPackages)
+
+	// Each chunk is opened in its own pull request, from its own branch
+	assert.Equal(t, 3, strings.Count(dryRunOutput.String(), "Pull request from: "))
+	assert.Equal(t, 3, strings.Count(dryRunOutput.String(), "Checksum: "))
+	for part := 1; part <= 3; part++ {
+		assert.Contains(t, dryRunOutput.String(), fmt.Sprintf("Pull request from: %s to: master", cfp.gitManager.GenerateAggregatedFixPartBranchName("master", nil, part)))
+		assert.Contains(t, dryRunOutput.String(), "Title: "+cfp.gitManager.GenerateAggregatedPartPullRequestTitle(nil, part))
+	}
+
+	// The chunks don't depend on the order the vulnerabilities are iterated in
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, chunks, chunkVulnerabilities(vulnerabilitiesMap, cfp.maxPackagesPerPr))
+	}
+	// A fix set that fits in a single pull request isn't split
+	assert.Len(t, chunkVulnerabilities(vulnerabilitiesMap, 10), 1)
 }
 
 func verifyTechnologyNaming(t *testing.T, scanResponse []services.ScanResponse, expectedType string) {
@@ -680,3 +1730,626 @@ func verifyDependencyFileDiff(baseBranch string, fixBranch string, packageDescri
 	}
 	return
 }
+
+func TestExcludeBaselineVulnerabilities(t *testing.T) {
+	baseWd := t.TempDir()
+	stateDir := t.TempDir()
+	newVulnerabilitiesMap := func(packageNames ...string) map[string]map[string]*utils.VulnerabilityDetails {
+		vulnerabilities := map[string]*utils.VulnerabilityDetails{}
+		for _, packageName := range packageNames {
+			vulnerabilities[packageName] = &utils.VulnerabilityDetails{
+				VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+					ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: packageName, ImpactedDependencyVersion: "1.0.0"},
+					FixedVersions:             []string{"1.0.1"},
+					IssueId:                   "XRAY-" + packageName,
+				},
+				SuggestedFixedVersion: "1.0.1",
+			}
+		}
+		return map[string]map[string]*utils.VulnerabilityDetails{baseWd: vulnerabilities}
+	}
+	// Simulates a successful run of the branch, returning the packages left to fix
+	runBranch := func(aggregateFixes bool, packageNames ...string) (fixNeeded bool, remaining []string) {
+		cfp := ScanRepositoryCmd{
+			scanDetails:            utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot"}).SetBaseBranch("master"),
+			baseWd:                 baseWd,
+			stateDir:               stateDir,
+			aggregateFixes:         aggregateFixes,
+			onlyNewVulnerabilities: true,
+		}
+		var err error
+		cfp.vulnerabilitiesBaseline, err = utils.LoadVulnerabilitiesBaseline(stateDir, "jfrog", "frogbot", "master")
+		require.NoError(t, err)
+		vulnerabilitiesMap := newVulnerabilitiesMap(packageNames...)
+		fixNeeded = cfp.excludeBaselineVulnerabilities(vulnerabilitiesMap)
+		require.NoError(t, utils.SaveVulnerabilitiesBaseline(stateDir, utils.NewVulnerabilitiesBaseline("jfrog", "frogbot", "master", cfp.detectedVulnerabilities)))
+		for packageName := range vulnerabilitiesMap[baseWd] {
+			remaining = append(remaining, packageName)
+		}
+		return
+	}
+
+	// First run - no baseline exists, all vulnerabilities are handled
+	fixNeeded, remaining := runBranch(false, "pkg1", "pkg2")
+	assert.True(t, fixNeeded)
+	assert.ElementsMatch(t, []string{"pkg1", "pkg2"}, remaining)
+
+	// No change - nothing to handle
+	fixNeeded, _ = runBranch(false, "pkg1", "pkg2")
+	assert.False(t, fixNeeded)
+	fixNeeded, _ = runBranch(true, "pkg1", "pkg2")
+	assert.False(t, fixNeeded)
+
+	// New vulnerability - only the new vulnerability is handled
+	fixNeeded, remaining = runBranch(false, "pkg1", "pkg2", "pkg3")
+	assert.True(t, fixNeeded)
+	assert.ElementsMatch(t, []string{"pkg3"}, remaining)
+
+	// New vulnerability in aggregate mode - the previously reported vulnerabilities are kept in the aggregated pull request
+	fixNeeded, remaining = runBranch(true, "pkg1", "pkg2", "pkg3", "pkg4")
+	assert.True(t, fixNeeded)
+	assert.ElementsMatch(t, []string{"pkg1", "pkg2", "pkg3", "pkg4"}, remaining)
+}
+
+func TestGroupProjectsBySharedLockfile(t *testing.T) {
+	baseWd := t.TempDir()
+	for _, dir := range []string{filepath.Join("packages", "a"), filepath.Join("packages", "b"), "standalone"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(baseWd, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(baseWd, dir, "package.json"), []byte("{}"), 0600))
+	}
+	// The workspace packages share the root lockfile, while the standalone project has its own
+	require.NoError(t, os.WriteFile(filepath.Join(baseWd, "package-lock.json"), []byte("{}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(baseWd, "standalone", "yarn.lock"), []byte{}, 0600))
+
+	groupSharedLockfiles := true
+	repository := &utils.Repository{Params: utils.Params{Scan: utils.Scan{
+		GroupSharedLockfiles: &groupSharedLockfiles,
+		Projects: []utils.Project{
+			{WorkingDirs: []string{filepath.Join("packages", "a")}},
+			{WorkingDirs: []string{"standalone"}},
+			{WorkingDirs: []string{filepath.Join("packages", "b")}},
+		},
+	}}}
+	cfp := ScanRepositoryCmd{baseWd: baseWd}
+	projectsGroups, err := cfp.groupProjectsBySharedLockfile(repository)
+	require.NoError(t, err)
+	require.Len(t, projectsGroups, 2)
+	assert.Equal(t, []*utils.Project{&repository.Projects[0], &repository.Projects[2]}, projectsGroups[0])
+	assert.Equal(t, []*utils.Project{&repository.Projects[1]}, projectsGroups[1])
+
+	// When grouping is disabled, every project is fixed separately
+	groupSharedLockfiles = false
+	projectsGroups, err = cfp.groupProjectsBySharedLockfile(repository)
+	require.NoError(t, err)
+	assert.Len(t, projectsGroups, 3)
+}
+
+func TestScanAndFixWithRepoSubpath(t *testing.T) {
+	dryRunRepoPath, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	repoDir := filepath.Join(dryRunRepoPath, "monorepo")
+	_, err = git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	for _, dir := range []string{filepath.Join("services", "foo"), filepath.Join("services", "foo", "web"), filepath.Join("services", "bar")} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, dir, "package.json"), []byte("{}"), 0600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package-lock.json"), []byte("{}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "services", "foo", "package-lock.json"), []byte("{}"), 0600))
+
+	groupSharedLockfiles := true
+	repository := &utils.Repository{Params: utils.Params{
+		Git: utils.Git{RepoName: "monorepo", RepoSubpath: "services/foo"},
+		Scan: utils.Scan{
+			GroupSharedLockfiles: &groupSharedLockfiles,
+			Projects:             []utils.Project{{WorkingDirs: []string{utils.RootDir}}, {WorkingDirs: []string{"web"}}},
+		},
+	}}
+	cfp := ScanRepositoryCmd{dryRun: true, dryRunRepoPath: dryRunRepoPath, scanDetails: &utils.ScanDetails{Git: &repository.Git}}
+	cfp.gitManager = utils.NewGitManager().SetDryRun(true, dryRunRepoPath)
+	clonedRepoDir, restoreDir, err := cfp.cloneRepositoryAndCheckoutToBranch()
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	assert.Equal(t, repoDir, clonedRepoDir)
+
+	// The working directories are discovered relative to the subpath
+	expectedBaseWd := filepath.Join(repoDir, "services", "foo")
+	assert.Equal(t, expectedBaseWd, cfp.baseWd)
+	currentWd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, expectedBaseWd, currentWd)
+	assert.Equal(t, []string{expectedBaseWd}, utils.GetFullPathWorkingDirs(repository.Projects[0].WorkingDirs, cfp.baseWd))
+	assert.Equal(t, []string{filepath.Join(expectedBaseWd, "web")}, utils.GetFullPathWorkingDirs(repository.Projects[1].WorkingDirs, cfp.baseWd))
+
+	// Both projects share the lockfile of the subpath, rather than the lockfile of the repository root
+	projectsGroups, err := cfp.groupProjectsBySharedLockfile(repository)
+	require.NoError(t, err)
+	require.Len(t, projectsGroups, 1)
+	assert.Len(t, projectsGroups[0], 2)
+
+	// The vulnerabilities are recorded relative to the subpath
+	vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"}}, "1.2.6")
+	assert.True(t, strings.HasPrefix(cfp.getBaselineVulnerabilityKey(filepath.Join(expectedBaseWd, "web"), vulnDetails), "web:"))
+
+	// A subpath that doesn't exist in the repository fails the run
+	_, err = getRepositoryBaseWd(repoDir, "services/missing")
+	assert.ErrorContains(t, err, "services/missing")
+}
+
+func TestIsUpdateRequiredWithMinPrUpdateInterval(t *testing.T) {
+	repoDir := t.TempDir()
+	_, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	// Uncommitted fixes are required for the pull request to be updated
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{}"), 0600))
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+	cfp := ScanRepositoryCmd{OutputWriter: &outputwriter.StandardOutput{}, gitManager: gitManager, aggregateFixes: true, minPrUpdateInterval: time.Hour}
+
+	getFixedVulnerabilities := func(impactedPackages ...string) (fixedVulnerabilities []*utils.VulnerabilityDetails) {
+		for _, impactedPackage := range impactedPackages {
+			fixedVulnerabilities = append(fixedVulnerabilities, &utils.VulnerabilityDetails{
+				VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+					ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: impactedPackage, ImpactedDependencyVersion: "1.0.0"},
+					FixedVersions:             []string{"1.0.1"},
+					IssueId:                   "XRAY-" + impactedPackage,
+				},
+				SuggestedFixedVersion: "1.0.1",
+			})
+		}
+		return
+	}
+	// The first run updates the pull request, as it has no recorded update time
+	pullRequestInfo := &vcsclient.PullRequestInfo{Body: outputwriter.MarkdownComment("Checksum: 4608a55b621cb6337ac93487979ac09c")}
+	firstRunFixes := getFixedVulnerabilities("package1")
+	updateRequired, err := cfp.isUpdateRequired(firstRunFixes, pullRequestInfo)
+	assert.NoError(t, err)
+	assert.True(t, updateRequired)
+	_, pullRequestInfo.Body, _, err = cfp.preparePullRequestDetails(firstRunFixes...)
+	require.NoError(t, err)
+	lastUpdate := getPullRequestLastUpdateTime(pullRequestInfo.Body)
+	assert.WithinDuration(t, time.Now(), lastUpdate, time.Minute)
+
+	// The second run is within the interval, so its update is deferred, even though the scan results changed
+	updateRequired, err = cfp.isUpdateRequired(getFixedVulnerabilities("package1", "package2"), pullRequestInfo)
+	assert.NoError(t, err)
+	assert.False(t, updateRequired)
+
+	// Once the interval passes, the pull request is updated
+	pullRequestInfo.Body = strings.Replace(pullRequestInfo.Body, lastUpdate.Format(time.RFC3339), lastUpdate.Add(-2*time.Hour).Format(time.RFC3339), 1)
+	updateRequired, err = cfp.isUpdateRequired(getFixedVulnerabilities("package1", "package2"), pullRequestInfo)
+	assert.NoError(t, err)
+	assert.True(t, updateRequired)
+}
+
+func TestHandleUnsupportedTechnologies(t *testing.T) {
+	// A repository with an npm project, and a Conan project whose vulnerabilities Frogbot can't fix
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "package.json"), []byte(`{"name": "project"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "conanfile.txt"), []byte("[requires]\nzlib/1.2.11\n"), 0644))
+	restoreDir, err := utils.Chdir(repoPath)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	technologies := techutils.ToTechnologies(techutils.DetectedTechnologiesList())
+	require.ElementsMatch(t, []techutils.Technology{techutils.Npm, techutils.Conan}, technologies)
+
+	testCases := []struct {
+		policy           utils.UnsupportedTechPolicy
+		expectedErr      string
+		expectedWarnings []string
+	}{
+		{policy: ""},
+		{policy: utils.SkipUnsupportedTechPolicy},
+		{policy: utils.WarnUnsupportedTechPolicy, expectedWarnings: []string{"jfrog/repo: Frogbot doesn't support fixing the vulnerabilities of Conan, which was detected in '.'"}},
+		{policy: utils.FailUnsupportedTechPolicy, expectedErr: "Frogbot doesn't support fixing the vulnerabilities of Conan, which was detected in '.'. Set JF_ON_UNSUPPORTED_TECH to skip or warn"},
+	}
+	for _, test := range testCases {
+		t.Run(string(test.policy), func(t *testing.T) {
+			cfp := ScanRepositoryCmd{
+				scanDetails:       utils.NewScanDetails(nil, nil, &utils.Git{RepoOwner: "jfrog", RepoName: "repo"}),
+				baseWd:            repoPath,
+				onUnsupportedTech: test.policy,
+				runSummary:        &utils.RunSummary{},
+			}
+			err := cfp.handleUnsupportedTechnologies(technologies, repoPath)
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedWarnings, cfp.runSummary.Warnings)
+		})
+	}
+
+	// The vulnerabilities of the unsupported technology are left unfixed
+	cfp := ScanRepositoryCmd{}
+	vulnerabilitiesMap := map[string]*utils.VulnerabilityDetails{}
+	conanVulnerability := &formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "zlib", ImpactedDependencyVersion: "1.2.11"},
+		FixedVersions:             []string{"[1.2.12]"},
+		ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: "zlib"}}},
+		Technology:                techutils.Conan,
+	}
+	assert.NoError(t, cfp.addVulnerabilityToFixVersionsMap(conanVulnerability, vulnerabilitiesMap))
+	assert.Empty(t, vulnerabilitiesMap)
+}
+
+func TestLoadTestMatrixNote(t *testing.T) {
+	baseWd := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(baseWd, ".github"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseWd, ".github", "test-matrix.md"), []byte("Tested against Python 3.9-3.12"), 0600))
+	cfp := ScanRepositoryCmd{baseWd: baseWd, testMatrixNoteFile: ".github/test-matrix.md"}
+	cfp.OutputWriter = &outputwriter.StandardOutput{}
+
+	cfp.loadTestMatrixNote()
+	assert.Equal(t, "Tested against Python 3.9-3.12", cfp.OutputWriter.TestMatrixNote())
+
+	// A branch without the file has no note, and the run goes on
+	cfp.testMatrixNoteFile = ".github/missing.md"
+	cfp.loadTestMatrixNote()
+	assert.Empty(t, cfp.OutputWriter.TestMatrixNote())
+}
+
+// Writes the fixed package to a descriptor file, to fix vulnerabilities without running the package manager
+type descriptorFixHandler struct {
+	packagehandlers.CommonPackageHandler
+	descriptor string
+	// The format of the descriptor content, given the package name and its fixed version
+	contentFormat string
+}
+
+func (dfh *descriptorFixHandler) UpdateDependency(vulnDetails *utils.VulnerabilityDetails) error {
+	return os.WriteFile(dfh.descriptor, []byte(fmt.Sprintf(dfh.contentFormat, vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)), 0600)
+}
+
+var (
+	packageJsonFixHandler  = &descriptorFixHandler{descriptor: "package.json", contentFormat: `{"dependencies": {"%s": "%s"}}`}
+	requirementsFixHandler = &descriptorFixHandler{descriptor: "requirements.txt", contentFormat: "%s==%s\n"}
+)
+
+func TestRebaseStaleFixPullRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	remoteDir, repoDir := filepath.Join(tmpDir, "remote"), filepath.Join(tmpDir, "repo")
+	remote, err := git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: vcsutils.RemoteName, URLs: []string{remoteDir}})
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	commitAndPush := func(fileName, content, message, branch string) plumbing.Hash {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, fileName), []byte(content), 0600))
+		_, err = worktree.Add(fileName)
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: "maintainer", Email: "maintainer@example.com", When: time.Now()}})
+		require.NoError(t, err)
+		require.NoError(t, repo.Push(&git.PushOptions{RemoteName: vcsutils.RemoteName, RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("refs/heads/%[1]s:refs/heads/%[1]s", branch))}, Force: true}))
+		return hash
+	}
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitParams := &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot"}
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+	_, err = gitManager.SetGitParams(gitParams)
+	require.NoError(t, err)
+	fixBranchName, err := gitManager.GenerateFixBranchName("master", "minimist", "1.2.6", "")
+	require.NoError(t, err)
+
+	// A fix pull request was opened, and then the base branch advanced
+	oldBaseCommit := commitAndPush("package.json", `{"dependencies": {"minimist": "1.2.5"}}`, "Initial commit", "master")
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(fixBranchName), Create: true}))
+	commitAndPush("package.json", `{"dependencies": {"minimist": "1.2.6"}}`, gitManager.GenerateCommitMessage("minimist", "1.2.6"), fixBranchName)
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}))
+	// The fix branches aren't cloned, as only the base branch is
+	require.NoError(t, repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(fixBranchName)))
+	newBaseCommit := commitAndPush("README.md", "# Frogbot", "Add README", "master")
+
+	// A mock GitHub server that serves the latest commit of the fix branch from the remote, and records the pull request updates
+	pullRequest := fmt.Sprintf(`{"number": 7, "html_url": "https://github.com/jfrog/frogbot/pull/7", "head": {"ref": "%[1]s", "label": "jfrog:%[1]s", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}, "base": {"ref": "master", "label": "jfrog:master", "repo": {"name": "frogbot", "owner": {"login": "jfrog"}}}}`, fixBranchName)
+	var updatedPullRequests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/frogbot/commits":
+			ref, err := remote.Reference(plumbing.NewBranchReferenceName(r.URL.Query().Get("sha")), true)
+			require.NoError(t, err)
+			commit, err := remote.CommitObject(ref.Hash())
+			require.NoError(t, err)
+			commitJson, err := json.Marshal([]map[string]any{{"sha": commit.Hash.String(), "commit": map[string]any{"message": commit.Message}, "parents": []map[string]string{{"sha": commit.ParentHashes[0].String()}}}})
+			require.NoError(t, err)
+			_, err = w.Write(commitJson)
+			assert.NoError(t, err)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/frogbot/pulls":
+			_, err := w.Write([]byte("[" + pullRequest + "]"))
+			assert.NoError(t, err)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/jfrog/frogbot/pulls/7":
+			var updatedPullRequest map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&updatedPullRequest))
+			updatedPullRequests = append(updatedPullRequests, updatedPullRequest)
+			_, err := w.Write([]byte(pullRequest))
+			assert.NoError(t, err)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/comments"):
+			_, err := w.Write([]byte("[]"))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "unexpected request", "%s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:       &outputwriter.StandardOutput{},
+		gitManager:         gitManager,
+		scanDetails:        utils.NewScanDetails(client, nil, gitParams).SetBaseBranch("master"),
+		handlers:           map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: packageJsonFixHandler},
+		autoRebaseStalePrs: true,
+	}
+	repository := &utils.Repository{OutputWriter: cfp.OutputWriter, Params: utils.Params{Git: utils.Git{RepoOwner: "jfrog", RepoName: "frogbot", PullRequestDetails: vcsclient.PullRequestInfo{ID: 7, Target: vcsclient.BranchInfo{Name: "master", Repository: "frogbot", Owner: "jfrog"}}}}}
+	vulnDetails := utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+		Technology:                techutils.Npm,
+	}, "1.2.6")
+	require.NoError(t, cfp.fixSinglePackageAndCreatePR(repository, vulnDetails, ""))
+	require.NoError(t, gitManager.Checkout("master"))
+
+	// The fix is applied again on top of the advanced base branch, and the existing pull request is updated
+	fixBranchRef, err := remote.Reference(plumbing.NewBranchReferenceName(fixBranchName), true)
+	require.NoError(t, err)
+	fixCommit, err := remote.CommitObject(fixBranchRef.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{newBaseCommit}, fixCommit.ParentHashes)
+	assert.NotContains(t, fixCommit.ParentHashes, oldBaseCommit)
+	readmeFile, err := fixCommit.File("README.md")
+	require.NoError(t, err)
+	readme, err := readmeFile.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "# Frogbot", readme)
+	require.Len(t, updatedPullRequests, 1)
+	assert.Equal(t, "master", updatedPullRequests[0]["base"])
+	assert.Contains(t, updatedPullRequests[0]["title"], "minimist")
+
+	// The rebased pull request is up to date with the base branch, so the next run skips it
+	require.NoError(t, cfp.fixSinglePackageAndCreatePR(repository, vulnDetails, ""))
+	assert.Len(t, updatedPullRequests, 1)
+	sameFixBranchRef, err := remote.Reference(plumbing.NewBranchReferenceName(fixBranchName), true)
+	require.NoError(t, err)
+	assert.Equal(t, fixBranchRef.Hash(), sameFixBranchRef.Hash())
+}
+
+func TestGroupFixesByDir(t *testing.T) {
+	// A repository with a directory that mixes npm and pip, and another npm directory
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	descriptors := map[string]string{
+		"services/api/package.json":     `{"dependencies": {"minimist": "1.2.5"}}`,
+		"services/api/requirements.txt": "pyjwt==1.7.1\n",
+		"web/package.json":              `{"dependencies": {"lodash": "4.17.20"}}`,
+	}
+	for descriptor, content := range descriptors {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(descriptor)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, descriptor), []byte(content), 0600))
+		_, err = worktree.Add(descriptor)
+		require.NoError(t, err)
+	}
+	_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}})
+	require.NoError(t, err)
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+
+	// A mock GitHub server without open pull requests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/jfrog/frogbot/pulls", r.URL.Path)
+		_, err := w.Write([]byte("[]"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+	require.NoError(t, err)
+	gitParams := &utils.Git{GitProvider: vcsutils.GitHub, RepoOwner: "jfrog", RepoName: "frogbot"}
+	gitManager, err := utils.NewGitManager().SetLocalRepository()
+	require.NoError(t, err)
+	_, err = gitManager.SetGitParams(gitParams)
+	require.NoError(t, err)
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      gitManager,
+		scanDetails:     utils.NewScanDetails(client, nil, gitParams).SetBaseBranch("master"),
+		baseWd:          repoDir,
+		dryRun:          true,
+		dryRunOutput:    &strings.Builder{},
+		pullRequestSink: &discardPullRequestSink{},
+		groupFixesByDir: true,
+		handlers:        map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: packageJsonFixHandler, techutils.Pip: requirementsFixHandler},
+	}
+	newVulnerability := func(packageName, version, fixVersion string, technology techutils.Technology) *utils.VulnerabilityDetails {
+		return utils.NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: packageName, ImpactedDependencyVersion: version},
+			Technology:                technology,
+		}, fixVersion)
+	}
+	vulnerabilitiesByPathMap := map[string]map[string]*utils.VulnerabilityDetails{
+		filepath.Join(repoDir, "services", "api"): {
+			"minimist": newVulnerability("minimist", "1.2.5", "1.2.6", techutils.Npm),
+			"pyjwt":    newVulnerability("pyjwt", "1.7.1", "2.4.0", techutils.Pip),
+		},
+		filepath.Join(repoDir, "web"): {
+			"lodash": newVulnerability("lodash", "4.17.20", "4.17.21", techutils.Npm),
+		},
+	}
+	require.NoError(t, cfp.fixVulnerablePackages(&utils.Repository{}, vulnerabilitiesByPathMap))
+
+	// The npm and pip fixes of the same directory share a single pull request, whose branch is named after the directory
+	require.Len(t, cfp.DryRunResult().PullRequests, 2)
+	apiPullRequest := cfp.DryRunResult().GetPullRequest("frogbot-update-services-api-dependencies-master")
+	require.NotNil(t, apiPullRequest)
+	assert.Equal(t, gitManager.GenerateDirPullRequestTitle([]techutils.Technology{techutils.Npm, techutils.Pip}, filepath.Join("services", "api")), apiPullRequest.Title)
+	assert.Contains(t, apiPullRequest.Title, "services/api")
+	assert.Equal(t, []outputwriter.PackageStatusRow{
+		{PackageName: "minimist", CurrentVersion: "1.2.5", TargetVersion: "1.2.6", Severity: "High"},
+		{PackageName: "pyjwt", CurrentVersion: "1.7.1", TargetVersion: "2.4.0", Severity: "High"},
+	}, apiPullRequest.Packages)
+	assert.Contains(t, apiPullRequest.Patch, `+{"dependencies": {"minimist": "1.2.6"}}`)
+	assert.Contains(t, apiPullRequest.Patch, "+pyjwt==2.4.0")
+	assert.NotContains(t, apiPullRequest.Patch, "lodash")
+
+	webPullRequest := cfp.DryRunResult().GetPullRequest("frogbot-update-web-dependencies-master")
+	require.NotNil(t, webPullRequest)
+	require.Len(t, webPullRequest.Packages, 1)
+	assert.Equal(t, "lodash", webPullRequest.Packages[0].PackageName)
+	assert.NotContains(t, webPullRequest.Patch, "minimist")
+}
+
+func TestFixGhsaOnlyVulnerability(t *testing.T) {
+	// An advisory with a GHSA ID but without a CVE
+	scanResults := &xrayutils.Results{
+		ScaResults: []*xrayutils.ScaScanResult{{
+			XrayResults: []services.ScanResponse{{
+				Vulnerabilities: []services.Vulnerability{{
+					IssueId:    "XRAY-264729",
+					Severity:   "High",
+					Technology: "npm",
+					References: []string{"https://github.com/advisories/GHSA-XVCH-5gv4-984h"},
+					Components: map[string]services.Component{
+						"npm://minimist:1.2.5": {
+							FixedVersions: []string{"[1.2.6]"},
+							ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: "npm://minimist:1.2.5"}}},
+						},
+					},
+				}},
+			}},
+		}},
+		ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+	}
+
+	// The GHSA ID is used to filter the vulnerability like a CVE
+	vulnerabilitiesMap, err := (&ScanRepositoryCmd{excludeCves: []string{"GHSA-XVCH-5GV4-984H"}}).createVulnerabilitiesMap(scanResults, false)
+	require.NoError(t, err)
+	assert.Empty(t, vulnerabilitiesMap)
+
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(`{"dependencies": {"minimist": "1.2.5"}}`), 0600))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: "frogbot@jfrog.com", When: time.Now()}})
+	require.NoError(t, err)
+	restoreDir, err := utils.Chdir(repoDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreDir())
+	}()
+	gitParams := &utils.Git{RepoOwner: "jfrog", RepoName: "frogbot", IncludeCveInTitle: true}
+	gitManager, err := utils.NewGitManager().SetDryRun(true, "").SetLocalRepository()
+	require.NoError(t, err)
+	_, err = gitManager.SetGitParams(gitParams)
+	require.NoError(t, err)
+	cfp := &ScanRepositoryCmd{
+		OutputWriter:    &outputwriter.StandardOutput{},
+		gitManager:      gitManager,
+		scanDetails:     utils.NewScanDetails(nil, nil, gitParams).SetBaseBranch("master"),
+		baseWd:          repoDir,
+		dryRun:          true,
+		dryRunOutput:    &strings.Builder{},
+		pullRequestSink: &discardPullRequestSink{},
+		handlers:        map[techutils.Technology]packagehandlers.PackageHandler{techutils.Npm: packageJsonFixHandler},
+	}
+	vulnerabilitiesMap, err = cfp.createVulnerabilitiesMap(scanResults, false)
+	require.NoError(t, err)
+	require.Contains(t, vulnerabilitiesMap, "minimist")
+	assert.Equal(t, []string{"GHSA-xvch-5gv4-984h"}, vulnerabilitiesMap["minimist"].Cves)
+
+	// The vulnerability is fixed, and its pull request is rendered with its GHSA ID
+	require.NoError(t, cfp.fixVulnerablePackages(&utils.Repository{}, map[string]map[string]*utils.VulnerabilityDetails{repoDir: vulnerabilitiesMap}))
+	require.Len(t, cfp.DryRunResult().PullRequests, 1)
+	pullRequest := cfp.DryRunResult().PullRequests[0]
+	assert.Contains(t, pullRequest.Patch, `+{"dependencies": {"minimist": "1.2.6"}}`)
+	assert.True(t, strings.HasSuffix(pullRequest.Title, " - GHSA-xvch-5gv4-984h"), pullRequest.Title)
+	assert.Contains(t, pullRequest.Body, "GHSA-xvch-5gv4-984h")
+}
+
+func TestExcludeWorkspacePackageVulnerabilities(t *testing.T) {
+	// A monorepo whose internal package, which is also published, is reported as a vulnerable dependency of another workspace package
+	projectDir := t.TempDir()
+	for path, content := range map[string]string{
+		"package.json":              `{"name": "monorepo", "workspaces": ["packages/*"]}`,
+		"packages/ui/package.json":  `{"name": "@acme/ui", "version": "1.0.0"}`,
+		"packages/web/package.json": `{"name": "@acme/web", "dependencies": {"@acme/ui": "1.0.0", "minimist": "1.2.5"}}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, filepath.Dir(path)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, path), []byte(content), 0600))
+	}
+	newVulnerability := func(issueId, componentId string) services.Vulnerability {
+		return services.Vulnerability{
+			IssueId:    issueId,
+			Severity:   "High",
+			Technology: "npm",
+			Components: map[string]services.Component{
+				componentId: {
+					FixedVersions: []string{"[9.9.9]"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "root"}, {ComponentId: componentId}}},
+				},
+			},
+		}
+	}
+	scanResults := &xrayutils.Results{
+		ScaResults: []*xrayutils.ScaScanResult{{
+			XrayResults: []services.ScanResponse{{
+				Vulnerabilities: []services.Vulnerability{newVulnerability("XRAY-1", "npm://@acme/ui:1.0.0"), newVulnerability("XRAY-2", "npm://minimist:1.2.5")},
+			}},
+		}},
+		ExtendedScanResults: &xrayutils.ExtendedScanResults{},
+	}
+
+	testCases := []struct {
+		name                     string
+		excludeWorkspacePackages bool
+		expectedPackages         []string
+	}{
+		{name: "Exclude workspace packages", excludeWorkspacePackages: true, expectedPackages: []string{"minimist"}},
+		{name: "Include workspace packages", excludeWorkspacePackages: false, expectedPackages: []string{"@acme/ui", "minimist"}},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			// The SBOM output makes the vulnerabilities be recorded for the reports
+			cfp := &ScanRepositoryCmd{excludeWorkspacePackages: test.excludeWorkspacePackages, sbomOutput: filepath.Join(t.TempDir(), "sbom.json")}
+			if cfp.excludeWorkspacePackages {
+				var err error
+				cfp.workspacePackages, err = utils.GetWorkspacePackages(projectDir)
+				require.NoError(t, err)
+			}
+			vulnerabilitiesMap, err := cfp.createVulnerabilitiesMap(scanResults, false)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.expectedPackages, maps.Keys(vulnerabilitiesMap))
+			var reportedPackages []string
+			for _, vulnerability := range cfp.branchVulnerabilities {
+				reportedPackages = append(reportedPackages, vulnerability.ImpactedDependencyName)
+			}
+			assert.ElementsMatch(t, test.expectedPackages, reportedPackages)
+		})
+	}
+}
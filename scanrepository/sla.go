@@ -0,0 +1,44 @@
+package scanrepository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Records the time the vulnerabilities of the current branch were first seen, and adds the time left to remediate each of them within its SLA to the run summary.
+func (cfp *ScanRepositoryCmd) trackSlaStatuses() (err error) {
+	record, err := utils.LoadFirstSeenRecord(cfp.stateDir, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch())
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	record.Update(cfp.branchVulnerabilities, now)
+	if err = utils.SaveFirstSeenRecord(cfp.stateDir, record); err != nil {
+		return
+	}
+	cfp.addSlaStatuses(record, now)
+	return
+}
+
+func (cfp *ScanRepositoryCmd) addSlaStatuses(record *utils.FirstSeenRecord, now time.Time) {
+	for _, vulnerability := range cfp.branchVulnerabilities {
+		firstSeen, exists := record.GetFirstSeen(vulnerability)
+		if !exists {
+			continue
+		}
+		// Vulnerabilities that aren't applicable may be treated as a lower severity, with a longer SLA
+		status := cfp.slaPolicy.GetSlaStatus(cfp.applicabilitySeverityAdjustment.AdjustedSeverity(vulnerability).String(), firstSeen, now)
+		if status == nil {
+			continue
+		}
+		if status.IsOverdue() {
+			log.Warn(fmt.Sprintf("The remediation of %s %s:%s is overdue. It was first seen on %s, and its SLA ended on %s", vulnerability.Severity, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, firstSeen.Format(time.DateOnly), status.Deadline.Format(time.DateOnly)))
+		}
+		if cfp.runSummary != nil {
+			cfp.runSummary.AddSlaStatus(vulnerability, status)
+		}
+	}
+}
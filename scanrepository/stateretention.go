@@ -0,0 +1,28 @@
+package scanrepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Removes the state files of the repository's branches that no longer exist, and those that weren't updated within the configured retention.
+// A failure to prune doesn't fail the run, as the pruning only keeps the state small.
+func (cfp *ScanRepositoryCmd) pruneStateFiles(client vcsclient.VcsClient) {
+	existingBranches, err := client.ListBranches(context.Background(), cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName)
+	if err != nil || len(existingBranches) == 0 {
+		// A repository always has a branch, so an empty list is treated as unknown rather than as all the branches being deleted
+		log.Warn(fmt.Sprintf("Couldn't list the branches of %s/%s, so the state of deleted branches isn't pruned: %v", cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, err))
+		existingBranches = nil
+	}
+	if existingBranches == nil && cfp.stateRetention <= 0 {
+		return
+	}
+	if _, err = utils.PruneStateFiles(cfp.stateDir, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.stateRetention, existingBranches, time.Now()); err != nil {
+		log.Warn(fmt.Sprintf("Couldn't prune the Frogbot state files:\n%s", err.Error()))
+	}
+}
@@ -0,0 +1,158 @@
+package scanrepository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"golang.org/x/exp/slices"
+)
+
+// Records the reason the fix of a vulnerable package isn't supported, to be described in the tracking issues of its vulnerabilities
+func (cfp *ScanRepositoryCmd) recordUnsupportedFix(vulnDetails *utils.VulnerabilityDetails, err error) {
+	var errUnsupportedFix *utils.ErrUnsupportedFix
+	if cfp.unsupportedFixes != nil && errors.As(err, &errUnsupportedFix) {
+		cfp.unsupportedFixes[getPackageKey(vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion)] = errUnsupportedFix
+	}
+}
+
+// Opens or updates a tracking issue for each vulnerability of the current branch that can't be fixed.
+// The tracking issues of vulnerabilities that are no longer detected are closed.
+func (cfp *ScanRepositoryCmd) syncUnfixableVulnerabilitiesIssues() error {
+	var trackingIssues []utils.TrackingIssue
+	var detectedChecksums []string
+	for _, vulnerability := range cfp.getUniqueBranchVulnerabilities() {
+		unsupportedFix := cfp.getUnsupportedFix(vulnerability.VulnerabilityOrViolationRow)
+		for _, vulnerabilityId := range vulnerability.ids {
+			checksum, err := utils.Md5Hash(strings.Join([]string{vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, vulnerabilityId}, "|"))
+			if err != nil {
+				return err
+			}
+			detectedChecksums = append(detectedChecksums, checksum)
+			if unsupportedFix == nil {
+				continue
+			}
+			trackingIssues = append(trackingIssues, utils.TrackingIssue{
+				Title:    fmt.Sprintf("%s %s in %s %s can't be fixed automatically", outputwriter.FrogbotTitlePrefix, vulnerabilityId, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion),
+				Body:     outputwriter.UnfixableVulnerabilityContent(vulnerability.VulnerabilityOrViolationRow, string(unsupportedFix.ErrorType), strings.TrimSpace(unsupportedFix.Error()), cfp.OutputWriter),
+				Checksum: checksum,
+			})
+		}
+	}
+	return utils.SyncGitHubTrackingIssues(cfp.scanDetails.APIEndpoint, cfp.scanDetails.Token, cfp.scanDetails.RepoOwner, cfp.scanDetails.RepoName, cfp.scanDetails.BaseBranch(), trackingIssues, detectedChecksums)
+}
+
+// Returns the reason the vulnerability can't be fixed, or nil if it can be fixed
+func (cfp *ScanRepositoryCmd) getUnsupportedFix(vulnerability formats.VulnerabilityOrViolationRow) *utils.ErrUnsupportedFix {
+	if len(vulnerability.FixedVersions) == 0 {
+		return &utils.ErrUnsupportedFix{PackageName: vulnerability.ImpactedDependencyName, ErrorType: utils.NoFixVersionAvailable}
+	}
+	return cfp.unsupportedFixes[getPackageKey(vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)]
+}
+
+// Each CVE of a vulnerability is tracked separately. Vulnerabilities without CVEs are tracked by their Xray issue ID.
+func getTrackedVulnerabilityIds(vulnerability formats.VulnerabilityOrViolationRow) (ids []string) {
+	for _, cve := range vulnerability.Cves {
+		if cve.Id != "" {
+			ids = append(ids, cve.Id)
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, vulnerability.IssueId)
+	}
+	return
+}
+
+// A vulnerability of the current branch, with the IDs it's tracked by
+type trackedVulnerability struct {
+	formats.VulnerabilityOrViolationRow
+	ids []string
+}
+
+// Returns the vulnerabilities of the current branch with the IDs they're tracked by.
+// The same vulnerability may be detected in several projects of the repository, so each ID of a vulnerable package is returned once,
+// and vulnerabilities whose IDs were all returned already are omitted.
+func (cfp *ScanRepositoryCmd) getUniqueBranchVulnerabilities() (vulnerabilities []trackedVulnerability) {
+	addedIds := make(map[string]bool)
+	for _, vulnerability := range cfp.branchVulnerabilities {
+		packageKey := getPackageKey(vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+		var ids []string
+		for _, vulnerabilityId := range getTrackedVulnerabilityIds(vulnerability) {
+			if idKey := packageKey + "|" + vulnerabilityId; !addedIds[idKey] {
+				addedIds[idKey] = true
+				ids = append(ids, vulnerabilityId)
+			}
+		}
+		if len(ids) > 0 {
+			vulnerabilities = append(vulnerabilities, trackedVulnerability{VulnerabilityOrViolationRow: vulnerability, ids: ids})
+		}
+	}
+	return
+}
+
+func getPackageKey(name, version string) string {
+	return name + ":" + version
+}
+
+// Records a package that a policy excludes from fixing, so the aggregated pull request lists it as skipped
+func (cfp *ScanRepositoryCmd) recordExcludedPackage(vulnerability *formats.VulnerabilityOrViolationRow, targetVersion, reason string) {
+	if cfp.excludedPackages == nil {
+		return
+	}
+	packageKey := getPackageKey(vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+	// The first reason a package is excluded for is listed
+	if _, exists := cfp.excludedPackages[packageKey]; exists {
+		return
+	}
+	cfp.excludedPackages[packageKey] = outputwriter.PackageStatusRow{
+		PackageName:    vulnerability.ImpactedDependencyName,
+		CurrentVersion: vulnerability.ImpactedDependencyVersion,
+		TargetVersion:  targetVersion,
+		Severity:       vulnerability.Severity,
+		Applicable:     vulnerability.Applicable,
+		SkipReason:     reason,
+	}
+}
+
+// Returns the packages the aggregated pull request skips: the packages whose fix isn't supported, and the packages the policies exclude.
+// The excluded packages aren't part of any split of the aggregated fix, so they are only listed in its first pull request.
+func (cfp *ScanRepositoryCmd) getAggregatedSkippedPackages(vulnerabilitiesMap map[string]map[string]*utils.VulnerabilityDetails, fixedVulnerabilities []*utils.VulnerabilityDetails) (skippedPackages []outputwriter.PackageStatusRow) {
+	fixedPackages := make(map[string]bool, len(fixedVulnerabilities))
+	for _, vulnDetails := range fixedVulnerabilities {
+		fixedPackages[getPackageKey(vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion)] = true
+	}
+	skippedKeys := make(map[string]bool)
+	for _, vulnerabilities := range vulnerabilitiesMap {
+		for _, vulnDetails := range vulnerabilities {
+			packageKey := getPackageKey(vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion)
+			unsupportedFix, isUnsupported := cfp.unsupportedFixes[packageKey]
+			if fixedPackages[packageKey] || skippedKeys[packageKey] || !isUnsupported {
+				continue
+			}
+			skippedKeys[packageKey] = true
+			skippedPackages = append(skippedPackages, outputwriter.PackageStatusRow{
+				PackageName:    vulnDetails.ImpactedDependencyName,
+				CurrentVersion: vulnDetails.ImpactedDependencyVersion,
+				TargetVersion:  vulnDetails.SuggestedFixedVersion,
+				Severity:       vulnDetails.Severity,
+				Applicable:     vulnDetails.Applicable,
+				SkipReason:     unsupportedFix.Summary(),
+			})
+		}
+	}
+	// The excluded packages aren't recorded by working directory, so they are listed only in the aggregated pull request of the whole repository
+	if cfp.aggregatedPullRequestPart <= 1 && !cfp.groupFixesByDir {
+		for packageKey, excludedPackage := range cfp.excludedPackages {
+			if !fixedPackages[packageKey] && !skippedKeys[packageKey] {
+				skippedPackages = append(skippedPackages, excludedPackage)
+			}
+		}
+	}
+	slices.SortFunc(skippedPackages, func(a, b outputwriter.PackageStatusRow) int {
+		return strings.Compare(a.PackageName, b.PackageName)
+	})
+	return
+}
@@ -0,0 +1,122 @@
+package scanrepository
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncUnfixableVulnerabilitiesIssues(t *testing.T) {
+	trackingChecksum := func(name, version, id string) string {
+		checksum, err := utils.Md5Hash(strings.Join([]string{name, version, id}, "|"))
+		require.NoError(t, err)
+		return checksum
+	}
+	openIssues := []map[string]any{
+		// The vulnerability of this issue is no longer detected
+		{"number": 1, "title": "lodash", "body": utils.GenerateTrackingIssueBody("lodash", "master", trackingChecksum("lodash", "4.17.20", "CVE-2021-23337"))},
+		// The vulnerability of this issue is still detected, but can now be fixed
+		{"number": 2, "title": "uuid", "body": utils.GenerateTrackingIssueBody("uuid", "master", trackingChecksum("uuid", "3.0.0", "CVE-2020-0001"))},
+		// The vulnerability of this issue is still unfixable
+		{"number": 3, "title": "minimist", "body": utils.GenerateTrackingIssueBody("minimist", "master", trackingChecksum("minimist", "1.2.5", "CVE-2021-44906"))},
+		// Issues of other branches, other issues and pull requests are ignored
+		{"number": 4, "title": "lodash", "body": utils.GenerateTrackingIssueBody("lodash", "release", trackingChecksum("lodash", "4.17.20", "CVE-2021-23337"))},
+		{"number": 5, "title": "Bug", "body": "Something is broken"},
+		{"number": 6, "title": "Pull request", "body": utils.GenerateTrackingIssueBody("pr", "master", "123"), "pull_request": map[string]any{}},
+	}
+	var createdIssues []map[string]string
+	updatedIssues := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jfrog/repo/issues":
+			assert.Equal(t, "open", r.URL.Query().Get("state"))
+			assert.NoError(t, json.NewEncoder(w).Encode(openIssues))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jfrog/repo/issues":
+			createdIssues = append(createdIssues, readJsonRequestBody(t, r))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/jfrog/repo/issues/"):
+			updatedIssues[strings.TrimPrefix(r.URL.Path, "/repos/jfrog/repo/issues/")] = readJsonRequestBody(t, r)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newVulnerability := func(name, version, cve string, fixedVersions ...string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			FixedVersions:             fixedVersions,
+			Cves:                      []formats.CveRow{{Id: cve}},
+		}
+	}
+	cfp := ScanRepositoryCmd{
+		OutputWriter: &outputwriter.StandardOutput{},
+		scanDetails:  utils.NewScanDetails(nil, nil, &utils.Git{VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}, RepoOwner: "jfrog", RepoName: "repo"}).SetBaseBranch("master"),
+		branchVulnerabilities: []formats.VulnerabilityOrViolationRow{
+			newVulnerability("uuid", "3.0.0", "CVE-2020-0001", "[3.0.1]"),
+			newVulnerability("minimist", "1.2.5", "CVE-2021-44906", "[1.2.6]"),
+			newVulnerability("semver", "5.7.1", "CVE-2022-25883"),
+			// The same vulnerability in another project of the repository is tracked by the same issue
+			newVulnerability("semver", "5.7.1", "CVE-2022-25883"),
+		},
+		unsupportedFixes: map[string]*utils.ErrUnsupportedFix{},
+	}
+	minimist := utils.NewVulnerabilityDetails(cfp.branchVulnerabilities[1], "1.2.6")
+	cfp.recordUnsupportedFix(minimist, &utils.ErrUnsupportedFix{PackageName: "minimist", FixedVersion: "1.2.6", ErrorType: utils.IndirectDependencyFixNotSupported})
+	require.NoError(t, cfp.syncUnfixableVulnerabilitiesIssues())
+
+	// An issue is opened for the vulnerability without a fixed version
+	require.Len(t, createdIssues, 1)
+	assert.Equal(t, "[🐸 Frogbot] CVE-2022-25883 in semver 5.7.1 can't be fixed automatically", createdIssues[0]["title"])
+	assert.Contains(t, createdIssues[0]["body"], "Reason: `NoFixVersionAvailable`")
+	assert.Contains(t, createdIssues[0]["body"], "No fixed version of semver is available yet.")
+	assert.Contains(t, createdIssues[0]["body"], trackingChecksum("semver", "5.7.1", "CVE-2022-25883"))
+
+	// The issue of the indirect dependency is updated, and the issue of the vulnerability that is no longer detected is closed
+	require.Len(t, updatedIssues, 2)
+	assert.Contains(t, updatedIssues["3"]["body"], "Reason: `IndirectDependencyFixNotSupported`")
+	assert.Equal(t, "[🐸 Frogbot] CVE-2021-44906 in minimist 1.2.5 can't be fixed automatically", updatedIssues["3"]["title"])
+	assert.Equal(t, map[string]string{"state": "closed", "state_reason": "completed"}, updatedIssues["1"])
+}
+
+func readJsonRequestBody(t *testing.T, r *http.Request) map[string]string {
+	content, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(content, &body))
+	return body
+}
+
+func TestGetUniqueBranchVulnerabilities(t *testing.T) {
+	minimist := formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"}
+	cfp := ScanRepositoryCmd{branchVulnerabilities: []formats.VulnerabilityOrViolationRow{
+		{ImpactedDependencyDetails: minimist, Cves: []formats.CveRow{{Id: "CVE-2021-44906"}}},
+		// The same vulnerability, detected in another project of the repository
+		{ImpactedDependencyDetails: minimist, Cves: []formats.CveRow{{Id: "CVE-2021-44906"}}},
+		// Only the IDs that weren't returned already are returned
+		{ImpactedDependencyDetails: minimist, Cves: []formats.CveRow{{Id: "CVE-2021-44906"}, {Id: "CVE-2020-7598"}}},
+		// The same ID of another version is a different vulnerability
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.0"}, Cves: []formats.CveRow{{Id: "CVE-2021-44906"}}},
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "uuid", ImpactedDependencyVersion: "3.0.0"}, IssueId: "XRAY-1"},
+	}}
+	var ids [][]string
+	for _, vulnerability := range cfp.getUniqueBranchVulnerabilities() {
+		ids = append(ids, append([]string{vulnerability.ImpactedDependencyName + ":" + vulnerability.ImpactedDependencyVersion}, vulnerability.ids...))
+	}
+	assert.Equal(t, [][]string{
+		{"minimist:1.2.5", "CVE-2021-44906"},
+		{"minimist:1.2.5", "CVE-2020-7598"},
+		{"minimist:1.2.0", "CVE-2021-44906"},
+		{"uuid:3.0.0", "XRAY-1"},
+	}, ids)
+}
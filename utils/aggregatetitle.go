@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	aggregateTitlePlaceHolders = []string{DependenciesCountPlaceHolder, TechnologiesPlaceHolder, HighestSeverityPlaceHolder, CvesCountPlaceHolder}
+	placeHolderRegexp          = regexp.MustCompile(`\{[A-Z_]+}`)
+)
+
+func validateAggregateTitleFormat(format string) error {
+	for _, placeHolder := range placeHolderRegexp.FindAllString(format, -1) {
+		if !slices.Contains(aggregateTitlePlaceHolders, placeHolder) {
+			return fmt.Errorf("the provided aggregate title format '%s' is invalid. Unknown placeholder %s, the supported placeholders are: %s", format, placeHolder, strings.Join(aggregateTitlePlaceHolders, ", "))
+		}
+	}
+	return nil
+}
+
+// Renders the aggregated pull request title format with the summary of the fixed vulnerabilities.
+// A dependency is counted once even if it's fixed in several working directories.
+func formatAggregateTitle(format string, tech []techutils.Technology, vulnerabilities []*VulnerabilityDetails) string {
+	var dependencies, cves []string
+	var highestSeverity string
+	for _, vulnDetails := range vulnerabilities {
+		if dependency := vulnDetails.ImpactedDependencyName + ":" + vulnDetails.ImpactedDependencyVersion; !slices.Contains(dependencies, dependency) {
+			dependencies = append(dependencies, dependency)
+		}
+		for _, cve := range getNormalizedCves(vulnDetails) {
+			if !slices.Contains(cves, cve) {
+				cves = append(cves, cve)
+			}
+		}
+		if highestSeverity == "" || severityutils.CompareSeverity(severityutils.GetSeverity(vulnDetails.Severity), severityutils.GetSeverity(highestSeverity)) > 0 {
+			highestSeverity = vulnDetails.Severity
+		}
+	}
+	title := strings.NewReplacer(
+		DependenciesCountPlaceHolder, strconv.Itoa(len(dependencies)),
+		TechnologiesPlaceHolder, techArrayToString(tech, pullRequestTitleTechSeparator),
+		HighestSeverityPlaceHolder, highestSeverity,
+		CvesCountPlaceHolder, strconv.Itoa(len(cves)),
+	).Replace(format)
+	return normalizeWhitespaces(title)
+}
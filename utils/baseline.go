@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jfrog/gofrog/datastructures"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const baselineFileSuffix = "-baseline.json"
+
+// DefaultStateDir is the directory, relative to the directory Frogbot is executed from, in which Frogbot persists data between runs, unless JF_STATE_DIR sets another directory.
+// In CI, the directory Frogbot is executed from is usually a fresh checkout, so the state is kept between runs only if the directory is cached.
+var DefaultStateDir = filepath.Join(frogbotConfigDir, "state")
+
+// VulnerabilitiesBaseline holds the vulnerabilities that were detected in a branch during the last successful Frogbot run.
+type VulnerabilitiesBaseline struct {
+	RepoOwner       string   `json:"repoOwner"`
+	RepoName        string   `json:"repoName"`
+	Branch          string   `json:"branch"`
+	Vulnerabilities []string `json:"vulnerabilities"`
+	vulnerabilities *datastructures.Set[string]
+}
+
+func NewVulnerabilitiesBaseline(repoOwner, repoName, branch string, vulnerabilities []string) *VulnerabilitiesBaseline {
+	return &VulnerabilitiesBaseline{RepoOwner: repoOwner, RepoName: repoName, Branch: branch, Vulnerabilities: vulnerabilities}
+}
+
+// Contains returns true if the vulnerability key was recorded in the baseline.
+func (vb *VulnerabilitiesBaseline) Contains(vulnerabilityKey string) bool {
+	if vb.vulnerabilities == nil {
+		vb.vulnerabilities = datastructures.MakeSetFromElements(vb.Vulnerabilities...)
+	}
+	return vb.vulnerabilities.Exists(vulnerabilityKey)
+}
+
+// GetBaselineFilePath returns the path of the baseline file of the given branch inside the state directory.
+func GetBaselineFilePath(stateDir, repoOwner, repoName, branch string) (string, error) {
+	return getStateFilePath(stateDir, repoOwner, repoName, branch, baselineFileSuffix)
+}
+
+func getStateFilePath(stateDir, repoOwner, repoName, branch, suffix string) (string, error) {
+	hash, err := Md5Hash(repoOwner, repoName, branch)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, hash+suffix), nil
+}
+
+// LoadVulnerabilitiesBaseline reads the baseline of the given branch.
+// If no baseline was recorded yet, nil is returned.
+func LoadVulnerabilitiesBaseline(stateDir, repoOwner, repoName, branch string) (baseline *VulnerabilitiesBaseline, err error) {
+	baselinePath, err := GetBaselineFilePath(stateDir, repoOwner, repoName, branch)
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(filepath.Clean(baselinePath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug("No vulnerabilities baseline was found for branch", branch, "at", baselinePath)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the vulnerabilities baseline file at %s: %s", baselinePath, err.Error())
+	}
+	baseline = &VulnerabilitiesBaseline{}
+	if err = json.Unmarshal(content, baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse the vulnerabilities baseline file at %s: %s", baselinePath, err.Error())
+	}
+	return
+}
+
+// SaveVulnerabilitiesBaseline writes the baseline into the state directory, replacing any previous baseline of the same branch.
+func SaveVulnerabilitiesBaseline(stateDir string, baseline *VulnerabilitiesBaseline) (err error) {
+	baselinePath, err := GetBaselineFilePath(stateDir, baseline.RepoOwner, baseline.RepoName, baseline.Branch)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(stateDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the Frogbot state directory at %s: %s", stateDir, err.Error())
+	}
+	content, err := json.Marshal(baseline)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(baselinePath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write the vulnerabilities baseline file at %s: %s", baselinePath, err.Error())
+	}
+	log.Debug("Vulnerabilities baseline for branch", baseline.Branch, "was recorded at", baselinePath)
+	return
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// Matches the notes of breaking changes in an advisory, such as "Version 5.0.0 contains breaking changes to the parser API."
+	breakingChangesRegex = regexp.MustCompile(`(?i)\b(?:breaking[ -]changes?|backwards?[ -]incompatible|not backwards?[ -]compatible)\b`)
+	// Matches the end of a sentence. The dots of versions, such as 5.0.0, aren't followed by a space.
+	sentenceEndRegex = regexp.MustCompile(`[.!?]+(?:\s+|$)|\n+`)
+)
+
+// GetAdvisoryBreakingChanges returns the sentences of the vulnerability advisory that note breaking changes in its fix.
+// Xray has no dedicated field for breaking changes, so they are looked up in the remediation and details of the JFrog research.
+func GetAdvisoryBreakingChanges(vulnerability formats.VulnerabilityOrViolationRow) (notes []string) {
+	research := vulnerability.JfrogResearchInformation
+	if research == nil {
+		return
+	}
+	advisoryTexts := []string{research.Remediation, research.Details}
+	for _, reason := range research.SeverityReasons {
+		advisoryTexts = append(advisoryTexts, reason.Description)
+	}
+	for _, advisoryText := range advisoryTexts {
+		for _, sentence := range splitSentences(advisoryText) {
+			if breakingChangesRegex.MatchString(sentence) && !slices.Contains(notes, sentence) {
+				notes = append(notes, sentence)
+			}
+		}
+	}
+	return
+}
+
+func splitSentences(text string) (sentences []string) {
+	sentenceStart := 0
+	for _, sentenceEnd := range sentenceEndRegex.FindAllStringIndex(text, -1) {
+		if sentence := strings.TrimSpace(text[sentenceStart:sentenceEnd[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		sentenceStart = sentenceEnd[1]
+	}
+	if sentence := strings.TrimSpace(text[sentenceStart:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return
+}
+
+func ExtractBreakingChanges(vulnDetails []*VulnerabilityDetails) (rows []outputwriter.BreakingChangesRow) {
+	for _, vuln := range vulnDetails {
+		if vuln.HasBreakingChanges() {
+			rows = append(rows, outputwriter.BreakingChangesRow{PackageName: vuln.ImpactedDependencyName, FixVersion: vuln.SuggestedFixedVersion, Notes: vuln.BreakingChanges})
+		}
+	}
+	return
+}
+
+// CreateGitHubDraftPullRequest opens the pull request as a draft.
+// The VCS client can't open draft pull requests, so the GitHub REST API is called directly.
+func CreateGitHubDraftPullRequest(apiEndpoint, token, owner, repo, sourceBranch, targetBranch, title, body string) (err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	requestBody := map[string]any{"title": title, "body": body, "head": sourceBranch, "base": targetBranch, "draft": true}
+	if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPost, fmt.Sprintf("%s/repos/%s/%s/pulls", getGitHubApiEndpoint(apiEndpoint), owner, repo), token, requestBody); err != nil {
+		return fmt.Errorf("failed to open a draft pull request from %s to %s in %s/%s: %s", sourceBranch, targetBranch, owner, repo, err.Error())
+	}
+	return
+}
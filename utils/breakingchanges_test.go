@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAdvisoryBreakingChanges(t *testing.T) {
+	testCases := []struct {
+		name          string
+		research      *formats.JfrogResearchInformation
+		expectedNotes []string
+	}{
+		{
+			name: "breaking changes in the remediation and a severity reason",
+			research: &formats.JfrogResearchInformation{
+				Details:     "A prototype pollution in the parser. Upgrade to fix it.",
+				Remediation: "Upgrade to version 2.0.0. Version 2.0.0 contains breaking changes to the parse API! Read the migration guide",
+				SeverityReasons: []formats.JfrogResearchSeverityReason{
+					{Name: "The fix is not backward compatible", Description: "The fix is not backward compatible with Node.js 12."},
+					{Name: "Duplicated note", Description: "Version 2.0.0 contains breaking changes to the parse API!"},
+				},
+			},
+			expectedNotes: []string{"Version 2.0.0 contains breaking changes to the parse API!", "The fix is not backward compatible with Node.js 12."},
+		},
+		{
+			name:     "advisory without breaking changes",
+			research: &formats.JfrogResearchInformation{Remediation: "Upgrade to version 1.2.6."},
+		},
+		{
+			name: "vulnerability without JFrog research",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			notes := GetAdvisoryBreakingChanges(formats.VulnerabilityOrViolationRow{JfrogResearchInformation: test.research})
+			assert.Equal(t, test.expectedNotes, notes)
+		})
+	}
+}
+
+func TestBreakingChangesPullRequestDetails(t *testing.T) {
+	newVulnerability := func(cve, remediation string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "0.2.4"},
+			Cves:                      []formats.CveRow{{Id: cve}},
+			JfrogResearchInformation:  &formats.JfrogResearchInformation{Remediation: remediation},
+		}
+	}
+	vulnDetails := NewVulnerabilityDetails(newVulnerability("CVE-2021-44906", "Upgrade to 1.2.6."), "1.2.6")
+	assert.False(t, vulnDetails.HasBreakingChanges())
+	// The breaking changes of any vulnerability of the package apply to the fix
+	vulnDetails.AddBreakingChanges(newVulnerability("CVE-2020-7598", "Version 1.0.0 has breaking changes in the parsing of numbers."))
+	assert.True(t, vulnDetails.HasBreakingChanges())
+
+	description, _ := GenerateFixPullRequestDetails([]*VulnerabilityDetails{vulnDetails}, &outputwriter.StandardOutput{})
+	assert.Contains(t, description, "⚠️ **Breaking changes:** Updating minimist to 1.2.6 may introduce breaking changes. Review the changes before merging.")
+	assert.Contains(t, description, "- Version 1.0.0 has breaking changes in the parsing of numbers.")
+}
+
+func TestCreateGitHubDraftPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repos/jfrog/frogbot/pulls", r.URL.Path)
+		assert.Equal(t, "Bearer 123456", r.Header.Get("Authorization"))
+		var requestBody map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+		assert.Equal(t, map[string]any{"title": "Upgrade minimist to 1.2.6", "body": "body", "head": "frogbot-minimist", "base": "master", "draft": true}, requestBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	require.NoError(t, CreateGitHubDraftPullRequest(server.URL, "123456", "jfrog", "frogbot", "frogbot-minimist", "master", "Upgrade minimist to 1.2.6", "body"))
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer failingServer.Close()
+	assert.ErrorContains(t, CreateGitHubDraftPullRequest(failingServer.URL, "123456", "jfrog", "frogbot", "frogbot-minimist", "master", "title", "body"), "failed to open a draft pull request from frogbot-minimist to master in jfrog/frogbot")
+}
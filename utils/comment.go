@@ -87,8 +87,45 @@ func DeleteExistingPullRequestComments(repository *Repository, client vcsclient.
 	return err
 }
 
-func GenerateFixPullRequestDetails(vulnerabilities []formats.VulnerabilityOrViolationRow, writer outputwriter.OutputWriter) (description string, extraComments []string) {
-	content := outputwriter.GetPRSummaryContent(outputwriter.VulnerabilitiesContent(vulnerabilities, writer), true, false, writer)
+func GenerateFixPullRequestDetails(vulnerabilitiesDetails []*VulnerabilityDetails, writer outputwriter.OutputWriter) (description string, extraComments []string) {
+	return generateFixPullRequestDetails(vulnerabilitiesDetails, nil, writer)
+}
+
+// GenerateAggregatedFixPullRequestDetails generates the body of an aggregated fix pull request.
+// A table of the fixed packages, and of the skipped packages with the reasons they were skipped, is added above the details of the fixes.
+func GenerateAggregatedFixPullRequestDetails(vulnerabilitiesDetails []*VulnerabilityDetails, skippedPackages []outputwriter.PackageStatusRow, writer outputwriter.OutputWriter) (description string, extraComments []string) {
+	return generateFixPullRequestDetails(vulnerabilitiesDetails, append(ExtractFixedPackagesStatus(vulnerabilitiesDetails), skippedPackages...), writer)
+}
+
+func generateFixPullRequestDetails(vulnerabilitiesDetails []*VulnerabilityDetails, packagesStatus []outputwriter.PackageStatusRow, writer outputwriter.OutputWriter) (description string, extraComments []string) {
+	vulnerabilities := ExtractVulnerabilitiesDetailsToRows(vulnerabilitiesDetails)
+	content := appendIfNotEmpty(nil, outputwriter.PackagesStatusContent(packagesStatus, writer))
+	// Breaking changes, regressions, and fixes that resolve several CVEs at once, are highlighted at the top of the body
+	content = appendIfNotEmpty(content, outputwriter.BreakingChangesContent(ExtractBreakingChanges(vulnerabilitiesDetails), writer))
+	content = appendIfNotEmpty(content, outputwriter.RegressionsContent(ExtractRegressions(vulnerabilitiesDetails), writer))
+	content = appendIfNotEmpty(content, outputwriter.MultipleCvesFixesContent(ExtractMultipleCvesFixes(vulnerabilitiesDetails), writer))
+	// The test matrix note is context for verifying the updates, so it is shown before their details
+	content = appendIfNotEmpty(content, outputwriter.TestMatrixNoteContent(writer))
+	// The sections are added in their configured order
+	for _, section := range writer.PullRequestBodySections() {
+		switch section {
+		case outputwriter.SummarySection:
+			content = append(content, outputwriter.VulnerabilitiesSummaryContent(vulnerabilities, writer)...)
+		case outputwriter.CvesSection:
+			content = append(content, outputwriter.VulnerabilitiesResearchContent(vulnerabilities, writer)...)
+		case outputwriter.ImpactPathSection:
+			content = appendIfNotEmpty(content, outputwriter.TransitiveDependenciesContent(ExtractTransitiveDependencies(vulnerabilitiesDetails), writer))
+		case outputwriter.FixNotesSection:
+			content = appendIfNotEmpty(content, outputwriter.FixNotesContent(ExtractFixNotes(vulnerabilitiesDetails), writer))
+		case outputwriter.SecurityAlertsSection:
+			content = appendIfNotEmpty(content, outputwriter.SecurityAlertsContent(ExtractSecurityAlerts(vulnerabilitiesDetails), writer))
+		case outputwriter.EvidenceSection:
+			if writer.ShowApplicabilityEvidence() {
+				content = appendIfNotEmpty(content, outputwriter.ApplicabilityEvidenceContent(vulnerabilities, writer))
+			}
+		}
+	}
+	content = outputwriter.GetPRSummaryContent(content, true, false, writer)
 	if len(content) == 1 {
 		// Limit is not reached, use the entire content as the description
 		description = content[0]
@@ -105,6 +142,13 @@ func GenerateFixPullRequestDetails(vulnerabilities []formats.VulnerabilityOrViol
 	return
 }
 
+func appendIfNotEmpty(content []string, sectionContent string) []string {
+	if sectionContent == "" {
+		return content
+	}
+	return append(content, sectionContent)
+}
+
 func generatePullRequestSummaryComment(issuesCollection *IssuesCollection, writer outputwriter.OutputWriter) []string {
 	if !issuesCollection.IssuesExists() {
 		return outputwriter.GetPRSummaryContent([]string{}, false, true, writer)
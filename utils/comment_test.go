@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jfrog/frogbot/v2/utils/outputwriter"
@@ -238,3 +239,75 @@ func TestGetNewReviewComments(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateFixPullRequestDetailsSections(t *testing.T) {
+	vulnerabilities := []*VulnerabilityDetails{{
+		SuggestedFixedVersion: "1.2.6",
+		VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+			Cves:                      []formats.CveRow{{Id: "CVE-2021-44906"}},
+			Technology:                techutils.Npm,
+		},
+		TransitiveImpactPath: []formats.ComponentRow{{Name: "mkdirp", Version: "0.5.5"}, {Name: "minimist", Version: "1.2.5"}},
+	}}
+	writer := &outputwriter.StandardOutput{}
+
+	// By default, the summary precedes the impact path
+	description, _ := GenerateFixPullRequestDetails(vulnerabilities, writer)
+	assert.Less(t, strings.Index(description, "Vulnerable Dependencies"), strings.Index(description, "Transitive Dependencies"))
+
+	// The configured sections are added in their configured order, and the rest are left out
+	writer.SetPullRequestBodySections([]string{outputwriter.ImpactPathSection, outputwriter.SummarySection})
+	description, _ = GenerateFixPullRequestDetails(vulnerabilities, writer)
+	assert.Less(t, strings.Index(description, "Transitive Dependencies"), strings.Index(description, "Vulnerable Dependencies"))
+	assert.NotContains(t, description, "Research Details")
+
+	// A custom layout changes the checksum of the pull request
+	defaultChecksum, err := FixPullRequestChecksum(NewHasher(Md5HashAlgorithm, 0), outputwriter.DefaultPullRequestBodySections, vulnerabilities...)
+	assert.NoError(t, err)
+	fixesChecksum, err := FixedPackagesChecksum(NewHasher(Md5HashAlgorithm, 0), vulnerabilities...)
+	assert.NoError(t, err)
+	assert.Equal(t, fixesChecksum, defaultChecksum)
+	customChecksum, err := FixPullRequestChecksum(NewHasher(Md5HashAlgorithm, 0), writer.PullRequestBodySections(), vulnerabilities...)
+	assert.NoError(t, err)
+	assert.NotEqual(t, defaultChecksum, customChecksum)
+}
+
+func TestGenerateFixPullRequestDetailsMultipleCves(t *testing.T) {
+	lodash := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"},
+		Cves:                      []formats.CveRow{{Id: "CVE-2020-28500"}, {Id: "CVE-2021-23337"}},
+		Technology:                techutils.Npm,
+	}, "4.17.21")
+	// Another vulnerability of the package that is fixed by the same version
+	lodash.SetCves([]formats.CveRow{{Id: "CVE-2021-23337"}, {Id: "CVE-2020-8203"}})
+	minimist := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+		Cves:                      []formats.CveRow{{Id: "CVE-2021-44906"}},
+		Technology:                techutils.Npm,
+	}, "1.2.6")
+
+	description, _ := GenerateFixPullRequestDetails([]*VulnerabilityDetails{lodash, minimist}, &outputwriter.StandardOutput{})
+	assert.Contains(t, description, "🔥 **Resolves 3 CVEs** by updating lodash to 4.17.21: CVE-2020-28500, CVE-2021-23337, CVE-2020-8203")
+	assert.NotContains(t, description, "by updating minimist")
+	// The fix is highlighted before the other sections of the body
+	assert.Less(t, strings.Index(description, "Resolves 3 CVEs"), strings.Index(description, "Vulnerable Dependencies"))
+}
+
+func TestGenerateFixPullRequestDetailsTestMatrixNote(t *testing.T) {
+	vulnerabilities := []*VulnerabilityDetails{NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+		Cves:                      []formats.CveRow{{Id: "CVE-2021-44906"}},
+		Technology:                techutils.Npm,
+	}, "1.2.6")}
+	writer := &outputwriter.StandardOutput{}
+
+	// Without a configured note, the body has no test matrix note
+	description, _ := GenerateFixPullRequestDetails(vulnerabilities, writer)
+	assert.NotContains(t, description, "Test matrix")
+
+	writer.SetTestMatrixNote("This repo tests against Node 18/20/22; verify the bump across all.\n")
+	description, _ = GenerateFixPullRequestDetails(vulnerabilities, writer)
+	assert.Contains(t, description, "🧪 **Test matrix:**\nThis repo tests against Node 18/20/22; verify the bump across all.")
+	assert.Less(t, strings.Index(description, "Test matrix"), strings.Index(description, "Vulnerable Dependencies"))
+}
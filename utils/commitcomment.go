@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-client-go/http/httpclient"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	commitCommentId       = "FrogbotCommitComment"
+	commitCommentsPerPage = 100
+)
+
+// A commit comment, as returned by the GitHub REST API
+type gitHubCommitComment struct {
+	Id   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// GenerateCommitCommentContent returns the content of the Frogbot comment on a commit.
+// The checksum identifies the content, so that repeated runs on the same commit update the existing comment only when the content changes.
+func GenerateCommitCommentContent(content, checksum string) string {
+	return outputwriter.MarkdownComment(commitCommentId) + content + outputwriter.MarkdownComment(fmt.Sprintf("Checksum: %s", checksum))
+}
+
+// UpsertGitHubCommitComment adds the Frogbot comment to a commit on GitHub.
+// If the commit already has a Frogbot comment, it is updated instead, unless its checksum is unchanged.
+func UpsertGitHubCommitComment(apiEndpoint, token, owner, repo, sha, content, checksum string) (err error) {
+	client, err := httpclient.ClientBuilder().Build()
+	if err != nil {
+		return
+	}
+	apiEndpoint = getGitHubApiEndpoint(apiEndpoint)
+	existingComment, err := getGitHubFrogbotCommitComment(client.GetClient(), apiEndpoint, token, owner, repo, sha)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of commit %s: %s", sha, err.Error())
+	}
+	requestBody := map[string]string{"body": GenerateCommitCommentContent(content, checksum)}
+	if existingComment == nil {
+		log.Info("Adding a comment to commit", sha)
+		if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPost, fmt.Sprintf("%s/repos/%s/%s/commits/%s/comments", apiEndpoint, owner, repo, sha), token, requestBody); err != nil {
+			return fmt.Errorf("failed to comment on commit %s: %s", sha, err.Error())
+		}
+		return
+	}
+	if strings.Contains(existingComment.Body, fmt.Sprintf("Checksum: %s", checksum)) {
+		log.Info("The comment on commit", sha, "is up to date")
+		return
+	}
+	log.Info("Updating the comment on commit", sha)
+	if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPatch, fmt.Sprintf("%s/repos/%s/%s/comments/%d", apiEndpoint, owner, repo, existingComment.Id), token, requestBody); err != nil {
+		return fmt.Errorf("failed to update the comment on commit %s: %s", sha, err.Error())
+	}
+	return
+}
+
+// Returns the Frogbot comment on the commit, or nil if the commit has no Frogbot comment.
+func getGitHubFrogbotCommitComment(client *http.Client, apiEndpoint, token, owner, repo, sha string) (*gitHubCommitComment, error) {
+	pageUrl := fmt.Sprintf("%s/repos/%s/%s/commits/%s/comments?per_page=%d", apiEndpoint, owner, repo, sha, commitCommentsPerPage)
+	for pageUrl != "" {
+		body, header, err := sendGitHubApiRequest(client, http.MethodGet, pageUrl, token, nil)
+		if err != nil {
+			return nil, err
+		}
+		var comments []gitHubCommitComment
+		if err = json.Unmarshal(body, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			if strings.Contains(comments[i].Body, commitCommentId) {
+				return &comments[i], nil
+			}
+		}
+		pageUrl = getGitHubNextPageUrl(header)
+	}
+	return nil, nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ListMergeStrategy determines how list fields are merged when several config files define them.
+type ListMergeStrategy string
+
+const (
+	// The list of the later config file replaces the list of the earlier config file
+	ReplaceListMergeStrategy ListMergeStrategy = "replace"
+	// The items of the later config file are appended to the list of the earlier config file, skipping duplicated values
+	AppendListMergeStrategy ListMergeStrategy = "append"
+)
+
+func getListMergeStrategy() (ListMergeStrategy, error) {
+	strategy := ListMergeStrategy(strings.ToLower(getTrimmedEnv(ConfigListMergeStrategyEnv)))
+	switch strategy {
+	case "":
+		return ReplaceListMergeStrategy, nil
+	case ReplaceListMergeStrategy, AppendListMergeStrategy:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("the provided config list merge strategy '%s' is invalid. Valid values are: %s, %s", strategy, ReplaceListMergeStrategy, AppendListMergeStrategy)
+}
+
+// mergeConfigFiles merges the content of several frogbot-config.yml files into a single config.
+// The config files are merged in order, so that the values of later config files override the values of earlier ones:
+// 1. Repositories are matched by their repoName. Repositories without a repoName match each other, and unmatched repositories are added.
+// 2. Scalar fields are overridden.
+// 3. Object fields are merged recursively.
+// 4. List fields are replaced or appended according to the list merge strategy.
+func mergeConfigFiles(configFilesContent [][]byte, strategy ListMergeStrategy) ([]byte, error) {
+	var merged *yaml.Node
+	for i, configFileContent := range configFilesContent {
+		if len(configFileContent) == 0 {
+			continue
+		}
+		var document yaml.Node
+		if err := yaml.Unmarshal(configFileContent, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse config file number %d: %s", i+1, err.Error())
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		repositories := document.Content[0]
+		if repositories.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("failed to parse config file number %d: a list of repositories is expected", i+1)
+		}
+		if merged == nil {
+			merged = repositories
+			continue
+		}
+		mergeRepositories(merged, repositories, strategy)
+	}
+	if merged == nil {
+		return nil, nil
+	}
+	return yaml.Marshal(merged)
+}
+
+func mergeRepositories(base, override *yaml.Node, strategy ListMergeStrategy) {
+	for _, overrideRepository := range override.Content {
+		var matched bool
+		for i, baseRepository := range base.Content {
+			if getConfigRepoName(baseRepository) == getConfigRepoName(overrideRepository) {
+				base.Content[i] = mergeYamlNodes(baseRepository, overrideRepository, strategy)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			base.Content = append(base.Content, overrideRepository)
+		}
+	}
+}
+
+func getConfigRepoName(repository *yaml.Node) string {
+	node := repository
+	for _, key := range []string{"params", "git", "repoName"} {
+		if node = getMappingValue(node, key); node == nil {
+			return ""
+		}
+	}
+	return node.Value
+}
+
+func getMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func mergeYamlNodes(base, override *yaml.Node, strategy ListMergeStrategy) *yaml.Node {
+	if base.Kind != override.Kind {
+		return override
+	}
+	switch override.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+			if baseValue := getMappingValue(base, key.Value); baseValue != nil {
+				*baseValue = *mergeYamlNodes(baseValue, value, strategy)
+				continue
+			}
+			base.Content = append(base.Content, key, value)
+		}
+		return base
+	case yaml.SequenceNode:
+		if strategy != AppendListMergeStrategy {
+			return override
+		}
+		for _, item := range override.Content {
+			if item.Kind != yaml.ScalarNode || !containsScalar(base, item.Value) {
+				base.Content = append(base.Content, item)
+			}
+		}
+		return base
+	default:
+		return override
+	}
+}
+
+func containsScalar(sequence *yaml.Node, value string) bool {
+	for _, item := range sequence.Content {
+		if item.Kind == yaml.ScalarNode && item.Value == value {
+			return true
+		}
+	}
+	return false
+}
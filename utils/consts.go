@@ -36,30 +36,96 @@ const (
 	GitProvider     = "JF_GIT_PROVIDER"
 	GitRepoOwnerEnv = "JF_GIT_OWNER"
 	GitRepoEnv      = "JF_GIT_REPO"
+	GitReposEnv     = "JF_GIT_REPOS"
 	GitProjectEnv   = "JF_GIT_PROJECT"
 	GitUsernameEnv  = "JF_GIT_USERNAME"
+	RepoSubpathEnv  = "JF_REPO_SUBPATH"
+
+	// Provided as JF_GIT_REPOS to scan all the repositories of the owner
+	allRepositoriesWildcard = "*"
+
+	// Config files environment variables
+	ConfigFilesEnv             = "JF_CONFIG_FILES"
+	ConfigListMergeStrategyEnv = "JF_CONFIG_LIST_MERGE_STRATEGY"
 
 	// Git naming template environment variables
 	BranchNameTemplateEnv       = "JF_BRANCH_NAME_TEMPLATE"
 	CommitMessageTemplateEnv    = "JF_COMMIT_MESSAGE_TEMPLATE"
 	PullRequestTitleTemplateEnv = "JF_PULL_REQUEST_TITLE_TEMPLATE"
+	AggregateTitleFormatEnv     = "JF_AGGREGATE_TITLE_FORMAT"
 	PullRequestCommentTitleEnv  = "JF_PR_COMMENT_TITLE"
+	OutputFormatEnv             = "JF_OUTPUT_FORMAT"
 
 	// Repository environment variables - Ignored if the frogbot-config.yml file is used
 	InstallCommandEnv                  = "JF_INSTALL_DEPS_CMD"
+	LockfileValidateCommandEnv         = "JF_LOCKFILE_VALIDATE_COMMAND"
 	RequirementsFileEnv                = "JF_REQUIREMENTS_FILE"
 	WorkingDirectoryEnv                = "JF_WORKING_DIR"
 	PathExclusionsEnv                  = "JF_PATH_EXCLUSIONS"
 	jfrogWatchesEnv                    = "JF_WATCHES"
 	jfrogProjectEnv                    = "JF_PROJECT"
+	ProjectMappingEnv                  = "JF_PROJECT_MAPPING"
 	IncludeAllVulnerabilitiesEnv       = "JF_INCLUDE_ALL_VULNERABILITIES"
 	AvoidPreviousPrCommentsDeletionEnv = "JF_AVOID_PREVIOUS_PR_COMMENTS_DELETION"
 	FailOnSecurityIssuesEnv            = "JF_FAIL"
 	UseWrapperEnv                      = "JF_USE_WRAPPER"
 	DepsRepoEnv                        = "JF_DEPS_REPO"
+	YarnCacheDirEnv                    = "JF_YARN_CACHE_DIR"
+	YarnOfflineMirrorEnv               = "JF_YARN_OFFLINE_MIRROR"
+	FixPeerDepsEnv                     = "JF_FIX_PEER_DEPS"
+	UpdateAllLockfilesEnv              = "JF_UPDATE_ALL_LOCKFILES"
+	UpdateVendoredDependenciesEnv      = "JF_UPDATE_VENDORED_DEPENDENCIES"
+	InstallRetriesEnv                  = "JF_INSTALL_RETRIES"
+	InstallTimeoutEnv                  = "JF_INSTALL_TIMEOUT"
+	PerDirTimeoutEnv                   = "JF_PER_DIR_TIMEOUT"
+	VerboseInstallErrorsEnv            = "JF_VERBOSE_INSTALL_ERRORS"
 	MinSeverityEnv                     = "JF_MIN_SEVERITY"
 	FixableOnlyEnv                     = "JF_FIXABLE_ONLY"
 	AllowedLicensesEnv                 = "JF_ALLOWED_LICENSES"
+	OnlyNewVulnerabilitiesEnv          = "JF_ONLY_NEW_VULNS"
+	GroupSharedLockfilesEnv            = "JF_GROUP_SHARED_LOCKFILES"
+	FixVersionCeilingPolicyEnv         = "JF_FIX_VERSION_CEILING_POLICY"
+	MaxVersionJumpEnv                  = "JF_MAX_VERSION_JUMP"
+	VerifyVersionAvailabilityEnv       = "JF_VERIFY_VERSION_AVAILABILITY"
+	VerifyProvenanceEnv                = "JF_VERIFY_PROVENANCE"
+	BlockFailedProvenanceEnv           = "JF_BLOCK_FAILED_PROVENANCE"
+	NodeVersionEnv                     = "JF_NODE_VERSION"
+	PythonVersionEnv                   = "JF_PYTHON_VERSION"
+	ResolveFixVersionRangesEnv         = "JF_RESOLVE_FIX_VERSION_RANGES"
+	PreferStableFixVersionEnv          = "JF_PREFER_STABLE_FIX_VERSION"
+	ShowApplicabilityEvidenceEnv       = "JF_SHOW_APPLICABILITY_EVIDENCE"
+	VerifyAfterMergeEnv                = "JF_VERIFY_AFTER_MERGE"
+	OnlyCvesEnv                        = "JF_ONLY_CVES"
+	ExcludeCvesEnv                     = "JF_EXCLUDE_CVES"
+	ExcludeWorkspacePackagesEnv        = "JF_EXCLUDE_WORKSPACE_PACKAGES"
+	IgnoredDependencyPathsEnv          = "JF_IGNORED_DEPENDENCY_PATHS"
+	LinkSecurityAlertsEnv              = "JF_LINK_SECURITY_ALERTS"
+	DetectRegressionsEnv               = "JF_DETECT_REGRESSIONS"
+	SbomOutputEnv                      = "JF_SBOM_OUTPUT"
+	InputSbomEnv                       = "JF_INPUT_SBOM"
+	CommentOnCommitEnv                 = "JF_COMMENT_ON_COMMIT"
+	CreateIssuesForUnfixableEnv        = "JF_CREATE_ISSUES_FOR_UNFIXABLE"
+	DraftOnBreakingEnv                 = "JF_DRAFT_ON_BREAKING"
+	FixLicenseViolationsEnv            = "JF_FIX_LICENSE_VIOLATIONS"
+	FixedSbomOutputEnv                 = "JF_FIXED_SBOM_OUTPUT"
+	JunitOutputEnv                     = "JF_JUNIT_OUTPUT"
+	JunitFailureSeverityEnv            = "JF_JUNIT_FAILURE_SEVERITY"
+	HtmlReportEnv                      = "JF_HTML_REPORT"
+	CrossBranchReportEnv               = "JF_CROSS_BRANCH_REPORT"
+	EmitPatchesEnv                     = "JF_EMIT_PATCHES"
+	OnUnsupportedTechEnv               = "JF_ON_UNSUPPORTED_TECH"
+	ScanPhaseOrderEnv                  = "JF_SCAN_PHASE_ORDER"
+	EarlyExitOnCriticalEnv             = "JF_EARLY_EXIT_ON_CRITICAL"
+	OnPartialScanEnv                   = "JF_ON_PARTIAL_SCAN"
+	MetadataLookupPolicyEnv            = "JF_METADATA_LOOKUP_POLICY"
+	BetweenDirsCommandEnv              = "JF_BETWEEN_DIRS_COMMAND"
+	ResolveCommandEnv                  = "JF_RESOLVE_COMMAND"
+	FixSourceEnv                       = "JF_FIX_SOURCE"
+	SlaPolicyEnv                       = "JF_SLA_POLICY"
+	StateRetentionEnv                  = "JF_STATE_RETENTION"
+	StateDirEnv                        = "JF_STATE_DIR"
+	ApplicabilitySeverityAdjustEnv     = "JF_APPLICABILITY_SEVERITY_ADJUST"
+	CvssVersionPreferenceEnv           = "JF_CVSS_VERSION_PREFERENCE"
 	WatchesDelimiter                   = ","
 
 	// Email related environment variables
@@ -69,13 +135,49 @@ const (
 	SmtpServerEnv     = "JF_SMTP_SERVER"
 	EmailReceiversEnv = "JF_EMAIL_RECEIVERS"
 
+	// Run summary notifications environment variables
+	SlackWebhookEnv = "JF_SLACK_WEBHOOK"
+	TeamsWebhookEnv = "JF_TEAMS_WEBHOOK"
+	// The targets whose summary is redacted, such as targets shared outside the organization
+	RedactedSummaryTargetsEnv = "JF_REDACTED_SUMMARY_TARGETS"
+	// The title of the wiki page whose Frogbot section is replaced with the run summary
+	UpdateWikiPageEnv = "JF_UPDATE_WIKI_PAGE"
+
+	//#nosec G101 -- False positive - no hardcoded credentials.
+	GitTokenEnv             = "JF_GIT_TOKEN"
+	GitBaseBranchEnv        = "JF_GIT_BASE_BRANCH"
+	GitPullRequestIDEnv     = "JF_GIT_PULL_REQUEST_ID"
+	GitApiEndpointEnv       = "JF_GIT_API_ENDPOINT"
+	UserAgentEnv            = "JF_USER_AGENT"
+	AddCorrelationIdEnv     = "JF_ADD_CORRELATION_ID"
+	GitAggregateFixesEnv    = "JF_GIT_AGGREGATE_FIXES"
+	GroupFixesByDirEnv      = "JF_GROUP_FIXES_BY_DIR"
+	MinPrUpdateIntervalEnv  = "JF_MIN_PR_UPDATE_INTERVAL"
+	AutoRebaseStalePrsEnv   = "JF_AUTO_REBASE_STALE_PRS"
+	FixPullRequestBranchEnv = "JF_FIX_PULL_REQUEST_BRANCH"
+	MaxPackagesPerPrEnv     = "JF_MAX_PACKAGES_PER_PR"
+	PrConcurrencyEnv        = "JF_PR_CONCURRENCY"
+	PullRequestSinkEnv      = "JF_PULL_REQUEST_SINK"
+	PullRequestSinkFileEnv  = "JF_PULL_REQUEST_SINK_FILE"
+	IncludeCveInTitleEnv    = "JF_INCLUDE_CVE_IN_TITLE"
+	OnExistingBranchEnv     = "JF_ON_EXISTING_BRANCH"
+	ChecksumStorageEnv      = "JF_CHECKSUM_STORAGE"
+	HashAlgorithmEnv        = "JF_HASH_ALGORITHM"
+	HashLengthEnv           = "JF_HASH_LENGTH"
+	GitEmailAuthorEnv       = "JF_GIT_EMAIL_AUTHOR"
+	GitPushRemoteUrlEnv     = "JF_GIT_PUSH_REMOTE_URL"
 	//#nosec G101 -- False positive - no hardcoded credentials.
-	GitTokenEnv          = "JF_GIT_TOKEN"
-	GitBaseBranchEnv     = "JF_GIT_BASE_BRANCH"
-	GitPullRequestIDEnv  = "JF_GIT_PULL_REQUEST_ID"
-	GitApiEndpointEnv    = "JF_GIT_API_ENDPOINT"
-	GitAggregateFixesEnv = "JF_GIT_AGGREGATE_FIXES"
-	GitEmailAuthorEnv    = "JF_GIT_EMAIL_AUTHOR"
+	GitPushRemoteTokenEnv = "JF_GIT_PUSH_REMOTE_TOKEN"
+	ExtraCommitPathsEnv   = "JF_EXTRA_COMMIT_PATHS"
+
+	PullRequestBodySectionsEnv = "JF_PR_BODY_SECTIONS"
+	CommitExcludePathsEnv      = "JF_COMMIT_EXCLUDE_PATHS"
+	WriteFixManifestEnv        = "JF_WRITE_FIX_MANIFEST"
+	AlwaysScanEnv              = "JF_ALWAYS_SCAN"
+	PushChangedFilesEnv        = "JF_GIT_PUSH_CHANGED_FILES"
+	TestMatrixNoteEnv          = "JF_TEST_MATRIX_NOTE"
+	TestMatrixNoteFileEnv      = "JF_TEST_MATRIX_NOTE_FILE"
+	PackageReviewersEnv        = "JF_PACKAGE_REVIEWERS"
 
 	// Product ID for usage reporting
 	productId = "frogbot"
@@ -88,6 +190,12 @@ const (
 	FixVersionPlaceHolder = "{FIX_VERSION}"
 	BranchHashPlaceHolder = "{BRANCH_NAME_HASH}"
 
+	// Aggregated pull request title placeholders
+	DependenciesCountPlaceHolder = "{DEPENDENCIES_COUNT}"
+	TechnologiesPlaceHolder      = "{TECHNOLOGIES}"
+	HighestSeverityPlaceHolder   = "{HIGHEST_SEVERITY}"
+	CvesCountPlaceHolder         = "{CVES_COUNT}"
+
 	// General flags
 	AvoidExtraMessages = "JF_AVOID_EXTRA_MESSAGES"
 
@@ -108,4 +216,159 @@ const (
 	IndirectDependencyFixNotSupported   UnsupportedErrorType = "IndirectDependencyFixNotSupported"
 	BuildToolsDependencyFixNotSupported UnsupportedErrorType = "BuildToolsDependencyFixNotSupported"
 	UnsupportedForFixVulnerableVersion  UnsupportedErrorType = "UnsupportedForFixVulnerableVersion"
+	GitDependencyFixNotSupported        UnsupportedErrorType = "GitDependencyFixNotSupported"
+	NoFixVersionAvailable               UnsupportedErrorType = "NoFixVersionAvailable"
+	FixExceedsVersionJump               UnsupportedErrorType = "FixExceedsVersionJump"
+	FixCrossesVersionCeiling            UnsupportedErrorType = "FixCrossesVersionCeiling"
+	FixVersionNotAvailableOnIndex       UnsupportedErrorType = "FixVersionNotAvailableOnIndex"
+	PeerDependencyFixSkipped            UnsupportedErrorType = "PeerDependencyFixSkipped"
+	ProvenanceVerificationFailed        UnsupportedErrorType = "ProvenanceVerificationFailed"
+	FixRequiresUnsupportedRuntime       UnsupportedErrorType = "FixRequiresUnsupportedRuntime"
+	MetadataLookupFailed                UnsupportedErrorType = "MetadataLookupFailed"
+)
+
+// Policies that handle uncommitted changes in the working tree of the cloned repository
+// Policies that handle a fix branch that already exists on the remote, whether it was pushed by a previous run or by someone else
+type ExistingBranchPolicy string
+
+const (
+	// Replace the content of the existing branch with the fix, if they differ, and update its pull request
+	UpdateExistingBranchPolicy ExistingBranchPolicy = "update"
+	// Leave the existing branch as is
+	SkipExistingBranchPolicy ExistingBranchPolicy = "skip"
+	// Push the fix to a new branch, named after the existing branch with a numeric suffix, if their contents differ
+	NewExistingBranchPolicy ExistingBranchPolicy = "new"
+)
+
+// The algorithms that hash the fix branch names and the pull request checksums
+type HashAlgorithm string
+
+const (
+	Md5HashAlgorithm    HashAlgorithm = "md5"
+	Sha1HashAlgorithm   HashAlgorithm = "sha1"
+	Sha256HashAlgorithm HashAlgorithm = "sha256"
+)
+
+// The mechanisms that store the checksum of an aggregated pull request in its body
+type ChecksumStorage string
+
+const (
+	// Store the checksum in a Markdown comment
+	CommentChecksumStorage ChecksumStorage = "comment"
+	// Store the checksum in zero-width characters, for providers that strip the comments from the body
+	ZeroWidthChecksumStorage ChecksumStorage = "zero-width"
+)
+
+// The destinations of the fix pull request operations
+type PullRequestSinkType string
+
+const (
+	// Push the fix branches and open the pull requests on the Git provider
+	VcsPullRequestSink PullRequestSinkType = "vcs"
+	// Write the pull request operations to a file, for a trusted agent to carry out
+	FilePullRequestSink PullRequestSinkType = "file"
+)
+
+// The targets the summary of a run is posted to
+type SummaryTarget string
+
+const (
+	// The comment on the scanned commit
+	CommitCommentSummaryTarget SummaryTarget = "commitComment"
+	SlackSummaryTarget         SummaryTarget = "slack"
+	TeamsSummaryTarget         SummaryTarget = "teams"
+	WikiSummaryTarget          SummaryTarget = "wiki"
+)
+
+// Policies that handle technologies that are detected in the repository, but whose vulnerable dependencies Frogbot can't fix
+type UnsupportedTechPolicy string
+
+const (
+	// Log the unsupported technologies and continue, leaving their vulnerabilities unfixed
+	SkipUnsupportedTechPolicy UnsupportedTechPolicy = "skip"
+	// Like skip, and add a warning about the unsupported technologies to the run summary
+	WarnUnsupportedTechPolicy UnsupportedTechPolicy = "warn"
+	// Fail the run if an unsupported technology is detected
+	FailUnsupportedTechPolicy UnsupportedTechPolicy = "fail"
+)
+
+// The orders the SCA scan, which scans the dependencies and their applicability, and the JAS scans of the source code (secrets, IaC and SAST) run in
+type ScanPhaseOrder string
+
+const (
+	// Run the SCA and JAS scans together
+	ParallelScanPhaseOrder ScanPhaseOrder = "parallel"
+	// Run the JAS scans after the SCA scan completes
+	ScaFirstScanPhaseOrder ScanPhaseOrder = "sca-first"
+	// Run the SCA scan after the JAS scans complete
+	JasFirstScanPhaseOrder ScanPhaseOrder = "jas-first"
+)
+
+// Policies that handle scans whose results Xray returned for some of the scanned components only
+type PartialScanPolicy string
+
+const (
+	// Fail the run if some of the components weren't analyzed
+	FailPartialScanPolicy PartialScanPolicy = "fail"
+	// Fix the vulnerabilities of the analyzed components, and add a warning about the components that weren't analyzed to the run summary
+	WarnAndContinuePartialScanPolicy PartialScanPolicy = "warn-and-continue"
+)
+
+// Policies that handle the registry metadata lookups of the fix versions that fail, such as the lookups of their availability, provenance and runtime requirements
+type MetadataLookupPolicy string
+
+const (
+	// Skip the fix if the metadata of its version can't be fetched
+	RequiredMetadataLookupPolicy MetadataLookupPolicy = "required"
+	// Fix without the checks whose metadata can't be fetched, and note the skipped checks in the fix
+	BestEffortMetadataLookupPolicy MetadataLookupPolicy = "best-effort"
+	// Skip the checks that depend on the registry metadata, without looking it up
+	DisabledMetadataLookupPolicy MetadataLookupPolicy = "disabled"
+)
+
+// The scan results that drive the fixes
+type FixSource string
+
+const (
+	// Fix only the policy violations of the configured watches and project
+	ViolationsFixSource FixSource = "violations"
+	// Fix only the vulnerabilities, ignoring the violations
+	VulnerabilitiesFixSource FixSource = "vulnerabilities"
+	// Fix the vulnerabilities, which include the violating ones, or the violations if only violations were returned
+	BothFixSource FixSource = "both"
+)
+
+// Policies that limit the fix versions Frogbot may suggest, relative to the impacted version
+type FixVersionCeilingPolicy string
+
+const (
+	SameMajorCeilingPolicy FixVersionCeilingPolicy = "same-major"
+	SameMinorCeilingPolicy FixVersionCeilingPolicy = "same-minor"
+)
+
+// The version components a fix version may jump by, relative to the impacted version
+type VersionComponent string
+
+const (
+	MajorVersionComponent VersionComponent = "major"
+	MinorVersionComponent VersionComponent = "minor"
+	PatchVersionComponent VersionComponent = "patch"
+)
+
+// A Go module is vendored if its vendor directory has the manifest of the vendored modules
+const (
+	GoVendorDir          = "vendor"
+	GoVendorManifestFile = "modules.txt"
+)
+
+// The results of verifying the provenance of a fix version, as annotated in the pull request body
+type ProvenanceStatus string
+
+const (
+	// The provenance attestations of the fix version match its published artifacts
+	VerifiedProvenanceStatus ProvenanceStatus = "verified"
+	// The fix version has no provenance attestations, or they couldn't be fetched
+	UnavailableProvenanceStatus ProvenanceStatus = "unavailable"
+	// The provenance attestations of the fix version don't match its published artifacts
+	FailedProvenanceStatus ProvenanceStatus = "failed"
 )
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const crossBranchReportTitle = "Frogbot Cross-Branch Report"
+
+// CrossBranchReport holds the vulnerabilities of all the scanned branches of a repository, rendered into a Markdown matrix of branch × vulnerability
+type CrossBranchReport struct {
+	Repository string
+	// The scanned branches, in the order they were scanned
+	Branches []string
+	Rows     []*CrossBranchReportRow
+}
+
+type CrossBranchReportRow struct {
+	PackageName    string
+	PackageVersion string
+	Severity       string
+	// The CVEs of the vulnerability, or its Xray issue ID if it has no CVEs
+	VulnerabilityIds []string
+	// The branches the vulnerability was detected on
+	Branches []string
+}
+
+func NewCrossBranchReport(repository string) *CrossBranchReport {
+	return &CrossBranchReport{Repository: repository}
+}
+
+// AddBranch adds a scanned branch to the report, so it is listed even if no vulnerabilities were detected on it
+func (cbr *CrossBranchReport) AddBranch(branch string) {
+	if !slices.Contains(cbr.Branches, branch) {
+		cbr.Branches = append(cbr.Branches, branch)
+	}
+}
+
+// AddVulnerability marks the vulnerability of the package as detected on the branch.
+// The same vulnerability may be detected in several projects of the branch, so it's added once.
+func (cbr *CrossBranchReport) AddVulnerability(branch, packageName, packageVersion, severity string, vulnerabilityIds []string) {
+	cbr.AddBranch(branch)
+	for _, row := range cbr.Rows {
+		if row.PackageName == packageName && row.PackageVersion == packageVersion && slices.Equal(row.VulnerabilityIds, vulnerabilityIds) {
+			if !slices.Contains(row.Branches, branch) {
+				row.Branches = append(row.Branches, branch)
+			}
+			return
+		}
+	}
+	cbr.Rows = append(cbr.Rows, &CrossBranchReportRow{
+		PackageName:      packageName,
+		PackageVersion:   packageVersion,
+		Severity:         severity,
+		VulnerabilityIds: vulnerabilityIds,
+		Branches:         []string{branch},
+	})
+}
+
+// Sorts the rows from the most severe vulnerability, and then by the package
+func (cbr *CrossBranchReport) sortRows() {
+	sort.SliceStable(cbr.Rows, func(i, j int) bool {
+		if severityComparison := severityutils.CompareSeverity(severityutils.GetSeverity(cbr.Rows[i].Severity), severityutils.GetSeverity(cbr.Rows[j].Severity)); severityComparison != 0 {
+			return severityComparison > 0
+		}
+		if cbr.Rows[i].PackageName != cbr.Rows[j].PackageName {
+			return cbr.Rows[i].PackageName < cbr.Rows[j].PackageName
+		}
+		return cbr.Rows[i].PackageVersion < cbr.Rows[j].PackageVersion
+	})
+}
+
+// RenderCrossBranchReport renders the report into a Markdown table, with a row per vulnerability and a column per branch
+func RenderCrossBranchReport(report *CrossBranchReport) string {
+	report.sortRows()
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# %s\n\n", crossBranchReportTitle))
+	content.WriteString(fmt.Sprintf("Repository: %s\n\n", report.Repository))
+	if len(report.Rows) == 0 {
+		content.WriteString(fmt.Sprintf("No vulnerabilities were detected on the scanned branches: %s\n", strings.Join(report.Branches, ", ")))
+		return content.String()
+	}
+	headers := append([]string{"Severity", "Package", "Vulnerability"}, report.Branches...)
+	content.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	content.WriteString(strings.Repeat("| --- ", len(headers)) + "|\n")
+	for _, row := range report.Rows {
+		cells := []string{row.Severity, fmt.Sprintf("%s:%s", row.PackageName, row.PackageVersion), strings.Join(row.VulnerabilityIds, ", ")}
+		for _, branch := range report.Branches {
+			if slices.Contains(row.Branches, branch) {
+				cells = append(cells, "✔")
+			} else {
+				cells = append(cells, "")
+			}
+		}
+		content.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return content.String()
+}
+
+func WriteCrossBranchReport(report *CrossBranchReport, reportPath string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the cross-branch report at %s: %s", reportPath, err.Error())
+	}
+	if err = os.WriteFile(filepath.Clean(reportPath), []byte(RenderCrossBranchReport(report)), 0644); err != nil {
+		return fmt.Errorf("failed to write the cross-branch report at %s: %s", reportPath, err.Error())
+	}
+	log.Info("The cross-branch report was written to", reportPath)
+	return
+}
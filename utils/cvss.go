@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strconv"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+)
+
+// The CVSS version whose scores rank the vulnerabilities
+type CvssVersion string
+
+const (
+	CvssV3 CvssVersion = "v3"
+	CvssV2 CvssVersion = "v2"
+)
+
+// GetCvssScore returns the CVSS score of the CVE in the preferred version.
+// If the CVE has no score in the preferred version, its score in the other version is returned, and 0 if it has no score at all.
+func GetCvssScore(cve formats.CveRow, preference CvssVersion) float64 {
+	scores := []string{cve.CvssV3, cve.CvssV2}
+	if preference == CvssV2 {
+		scores = []string{cve.CvssV2, cve.CvssV3}
+	}
+	for _, score := range scores {
+		if parsedScore, err := strconv.ParseFloat(score, 64); err == nil {
+			return parsedScore
+		}
+	}
+	return 0
+}
+
+// GetHighestCvssScore returns the highest CVSS score of the CVEs in the preferred version, see GetCvssScore.
+func GetHighestCvssScore(cves []formats.CveRow, preference CvssVersion) (highestScore float64) {
+	for _, cve := range cves {
+		highestScore = max(highestScore, GetCvssScore(cve, preference))
+	}
+	return
+}
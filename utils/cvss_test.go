@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCvssScore(t *testing.T) {
+	bothVersions := formats.CveRow{Id: "CVE-2021-44906", CvssV2: "7.5", CvssV3: "9.8"}
+	onlyV2 := formats.CveRow{Id: "CVE-2017-16137", CvssV2: "5.0"}
+	onlyV3 := formats.CveRow{Id: "CVE-2022-46175", CvssV3: "7.1"}
+	noScore := formats.CveRow{Id: "CVE-2024-0001"}
+	testCases := []struct {
+		name          string
+		cve           formats.CveRow
+		preference    CvssVersion
+		expectedScore float64
+	}{
+		{name: "Both versions, prefer v3", cve: bothVersions, preference: CvssV3, expectedScore: 9.8},
+		{name: "Both versions, prefer v2", cve: bothVersions, preference: CvssV2, expectedScore: 7.5},
+		{name: "Only v2, prefer v3", cve: onlyV2, preference: CvssV3, expectedScore: 5.0},
+		{name: "Only v3, prefer v2", cve: onlyV3, preference: CvssV2, expectedScore: 7.1},
+		{name: "No score", cve: noScore, preference: CvssV3, expectedScore: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedScore, GetCvssScore(tc.cve, tc.preference))
+		})
+	}
+	cves := []formats.CveRow{bothVersions, onlyV2, onlyV3}
+	assert.Equal(t, 9.8, GetHighestCvssScore(cves, CvssV3))
+	assert.Equal(t, 7.5, GetHighestCvssScore(cves, CvssV2))
+}
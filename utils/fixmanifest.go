@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The file that describes the fixes of the fix commit, relative to the repository root
+const FixManifestPath = frogbotConfigDir + "/last-fix.json"
+
+// FixManifest is the machine-readable description of the fixes a fix commit applies, for downstream automation.
+// The manifest doesn't record when it was written, so an unchanged fix produces an unchanged commit.
+type FixManifest struct {
+	Fixes []FixManifestEntry `json:"fixes"`
+}
+
+type FixManifestEntry struct {
+	Package          string   `json:"package"`
+	Technology       string   `json:"technology"`
+	OldVersion       string   `json:"oldVersion"`
+	NewVersion       string   `json:"newVersion"`
+	Cves             []string `json:"cves"`
+	DirectDependency bool     `json:"directDependency"`
+}
+
+// NewFixManifest describes the fixed packages, sorted by their names and versions, as Xray may return the vulnerabilities in a different order
+func NewFixManifest(vulnerabilities ...*VulnerabilityDetails) *FixManifest {
+	manifest := &FixManifest{Fixes: make([]FixManifestEntry, 0, len(vulnerabilities))}
+	for _, vulnDetails := range vulnerabilities {
+		manifest.Fixes = append(manifest.Fixes, FixManifestEntry{
+			Package:          vulnDetails.ImpactedDependencyName,
+			Technology:       vulnDetails.Technology.String(),
+			OldVersion:       vulnDetails.ImpactedDependencyVersion,
+			NewVersion:       vulnDetails.SuggestedFixedVersion,
+			Cves:             getNormalizedCves(vulnDetails),
+			DirectDependency: vulnDetails.IsDirectDependency,
+		})
+	}
+	slices.SortFunc(manifest.Fixes, func(a, b FixManifestEntry) int {
+		if compared := strings.Compare(a.Package, b.Package); compared != 0 {
+			return compared
+		}
+		return strings.Compare(a.OldVersion, b.OldVersion)
+	})
+	return manifest
+}
+
+// WriteFixManifest writes or replaces the fix manifest under the repository root, so it's committed with the fixes it describes
+func WriteFixManifest(repoRoot string, vulnerabilities ...*VulnerabilityDetails) error {
+	content, err := json.MarshalIndent(NewFixManifest(vulnerabilities...), "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(repoRoot, filepath.FromSlash(FixManifestPath))
+	if err = os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, append(content, '\n'), 0644)
+}
+
+// Returns the path of the fix manifest relative to the root of the Git repository, which differs from the root Frogbot treats as the repository root if a repository subpath is provided
+func getRepositoryFixManifestPath(repoSubpath string) string {
+	return path.Join(repoSubpath, FixManifestPath)
+}
+
+// Returns the distinct CVE IDs that the fix resolves, in upper case and sorted
+func getNormalizedCves(vulnDetails *VulnerabilityDetails) []string {
+	cves := make([]string, 0, len(vulnDetails.Cves))
+	for _, cve := range vulnDetails.Cves {
+		if cve = strings.ToUpper(strings.TrimSpace(cve)); cve != "" && !slices.Contains(cves, cve) {
+			cves = append(cves, cve)
+		}
+	}
+	slices.Sort(cves)
+	return cves
+}
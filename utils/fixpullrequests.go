@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const fixPullRequestsFileSuffix = "-fix-pull-requests.json"
+
+// FixPullRequestsRecord holds the fix pull requests Frogbot opened on a branch, so that their remediation can be verified after they are merged.
+type FixPullRequestsRecord struct {
+	RepoOwner    string           `json:"repoOwner"`
+	RepoName     string           `json:"repoName"`
+	Branch       string           `json:"branch"`
+	PullRequests []FixPullRequest `json:"pullRequests"`
+}
+
+// FixPullRequest is a fix pull request, along with the names of the packages it fixes.
+type FixPullRequest struct {
+	ID           int64    `json:"id"`
+	URL          string   `json:"url"`
+	SourceBranch string   `json:"sourceBranch"`
+	Packages     []string `json:"packages"`
+}
+
+func NewFixPullRequestsRecord(repoOwner, repoName, branch string) *FixPullRequestsRecord {
+	return &FixPullRequestsRecord{RepoOwner: repoOwner, RepoName: repoName, Branch: branch}
+}
+
+// AddPullRequest records the pull request, replacing a previous record of the same pull request.
+func (fpr *FixPullRequestsRecord) AddPullRequest(pullRequest FixPullRequest) {
+	fpr.PullRequests = slices.DeleteFunc(fpr.PullRequests, func(recorded FixPullRequest) bool {
+		return recorded.ID == pullRequest.ID
+	})
+	fpr.PullRequests = append(fpr.PullRequests, pullRequest)
+}
+
+// LoadFixPullRequestsRecord reads the fix pull requests recorded for the given branch.
+// If no fix pull requests were recorded yet, nil is returned.
+func LoadFixPullRequestsRecord(stateDir, repoOwner, repoName, branch string) (record *FixPullRequestsRecord, err error) {
+	recordPath, err := getStateFilePath(stateDir, repoOwner, repoName, branch, fixPullRequestsFileSuffix)
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(filepath.Clean(recordPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug("No fix pull requests were recorded for branch", branch, "at", recordPath)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the fix pull requests file at %s: %s", recordPath, err.Error())
+	}
+	record = &FixPullRequestsRecord{}
+	if err = json.Unmarshal(content, record); err != nil {
+		return nil, fmt.Errorf("failed to parse the fix pull requests file at %s: %s", recordPath, err.Error())
+	}
+	return
+}
+
+// SaveFixPullRequestsRecord writes the record into the state directory, replacing any previous record of the same branch.
+func SaveFixPullRequestsRecord(stateDir string, record *FixPullRequestsRecord) (err error) {
+	recordPath, err := getStateFilePath(stateDir, record.RepoOwner, record.RepoName, record.Branch, fixPullRequestsFileSuffix)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(stateDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the Frogbot state directory at %s: %s", stateDir, err.Error())
+	}
+	content, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(recordPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write the fix pull requests file at %s: %s", recordPath, err.Error())
+	}
+	log.Debug("Fix pull requests of branch", record.Branch, "were recorded at", recordPath)
+	return
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"golang.org/x/exp/slices"
+)
+
+const ghsaIdPrefix = "GHSA"
+
+// Matches the ID of a GitHub security advisory, such as GHSA-xvch-5gv4-984h, in an issue ID or in the URL of an advisory
+var ghsaIdRegexp = regexp.MustCompile(`(?i)\bGHSA(-[23456789cfghjmpqrvwx]{4}){3}\b`)
+
+// Returns the ID of the GitHub security advisory found in the issue ID or in the references of a vulnerability, in its canonical form, such as GHSA-xvch-5gv4-984h.
+// An empty string is returned if the vulnerability has no GitHub security advisory.
+func getGhsaId(issueId string, references []string) string {
+	for _, candidate := range append([]string{issueId}, references...) {
+		if ghsaId := ghsaIdRegexp.FindString(candidate); ghsaId != "" {
+			return ghsaIdPrefix + strings.ToLower(ghsaId[len(ghsaIdPrefix):])
+		}
+	}
+	return ""
+}
+
+// GetGhsaIds maps the issue IDs of the vulnerabilities and violations of the scan to the IDs of their GitHub security advisories.
+// The advisories are looked up in the scan response, as the references of the vulnerabilities are dropped when the results are simplified.
+func GetGhsaIds(scanResponse services.ScanResponse) map[string]string {
+	ghsaIds := map[string]string{}
+	for _, vulnerability := range scanResponse.Vulnerabilities {
+		if ghsaId := getGhsaId(vulnerability.IssueId, vulnerability.References); ghsaId != "" {
+			ghsaIds[vulnerability.IssueId] = ghsaId
+		}
+	}
+	for _, violation := range scanResponse.Violations {
+		if ghsaId := getGhsaId(violation.IssueId, violation.References); ghsaId != "" {
+			ghsaIds[violation.IssueId] = ghsaId
+		}
+	}
+	return ghsaIds
+}
+
+// IdentifyGhsaOnlyVulnerabilities sets the GHSA ID as the identifier of the vulnerabilities that have a GitHub security advisory but no CVE.
+// The rest of the flow identifies the vulnerabilities by their CVEs, so the GHSA ID takes the place of the missing CVE:
+// it's rendered in the pull requests, used to aggregate the fixes, and matched by the CVEs to include or exclude.
+func IdentifyGhsaOnlyVulnerabilities(vulnerabilities []formats.VulnerabilityOrViolationRow, ghsaIds map[string]string) {
+	for i := range vulnerabilities {
+		hasCve := slices.ContainsFunc(vulnerabilities[i].Cves, func(cve formats.CveRow) bool {
+			return cve.Id != ""
+		})
+		if hasCve {
+			continue
+		}
+		ghsaId := ghsaIds[vulnerabilities[i].IssueId]
+		if ghsaId == "" {
+			if ghsaId = getGhsaId(vulnerabilities[i].IssueId, vulnerabilities[i].References); ghsaId == "" {
+				continue
+			}
+		}
+		// The CVE rows may be shared with other rows of the same vulnerability
+		cves := slices.Clone(vulnerabilities[i].Cves)
+		if len(cves) == 0 {
+			cves = append(cves, formats.CveRow{})
+		}
+		cves[0].Id = ghsaId
+		vulnerabilities[i].Cves = cves
+	}
+}
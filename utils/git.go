@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -12,10 +14,15 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
 	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	clientutils "github.com/jfrog/jfrog-client-go/utils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -29,14 +36,27 @@ import (
 const (
 	refFormat = "refs/heads/%s:refs/heads/%[1]s"
 
+	// The name of the remote the fix branches are pushed to, when it differs from the cloned remote
+	pushRemoteName = "frogbot-push"
+
 	// Timout is seconds for the git operations performed by the go-git client.
 	goGitTimeoutSeconds = 120
 
 	// Separators used to convert technologies array into string
 	fixBranchTechSeparator        = "-"
 	pullRequestTitleTechSeparator = ","
+	// Names the fix branch of the repository root, when the fixes are grouped by working directory
+	rootDirBranchName = "root"
 )
 
+// The descriptors and lock files that fixes update, which are never left out of the fix commits
+var fixDescriptorFiles = []string{
+	"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml",
+	"go.mod", "go.sum", "pom.xml", "build.gradle", "build.gradle.kts", "gradle.lockfile", "libs.versions.toml",
+	"requirements.txt", "setup.py", "Pipfile", "Pipfile.lock", "pyproject.toml", "poetry.lock",
+	"packages.config", "packages.lock.json",
+}
+
 type GitManager struct {
 	// repository represents a git repository as a .git dir.
 	localGitRepository *git.Repository
@@ -46,6 +66,10 @@ type GitManager struct {
 	remoteGitUrl string
 	// The authentication struct consisting a username/password
 	auth *githttp.BasicAuth
+	// pushRemoteUrl is the URL of the remote to push the fix branches to, if it differs from the remote the repository is cloned from
+	pushRemoteUrl string
+	// The authentication struct used to access the push remote
+	pushAuth *githttp.BasicAuth
 	// dryRun is used for testing purposes, mocking part of the git commands that requires networking
 	dryRun bool
 	// When dryRun is enabled, dryRunRepoPath specifies the repository local path to clone
@@ -56,6 +80,14 @@ type GitManager struct {
 	customTemplates CustomTemplates
 	// Git details
 	git *Git
+	// The absolute path of the Frogbot state directory, which is left out of the fix commits if it's inside the repository
+	stateDir string
+	// The CVSS version whose scores choose the CVE in the pull request titles
+	cvssVersionPreference CvssVersion
+	// Hashes the fix branch names and the pull request checksums
+	hasher *Hasher
+	// The full hashes of the fix branches generated during the run, by the shortened hashes in their names
+	branchHashes map[string]string
 }
 
 type CustomTemplates struct {
@@ -77,6 +109,63 @@ func (gm *GitManager) SetAuth(username, token string) *GitManager {
 	return gm
 }
 
+// SetPushRemote sets a remote to push the fix branches to, instead of the remote the repository is cloned from.
+func (gm *GitManager) SetPushRemote(pushRemoteUrl, username, token string) *GitManager {
+	gm.pushRemoteUrl = pushRemoteUrl
+	gm.pushAuth = toBasicAuth(username, token)
+	return gm
+}
+
+// ValidatePushRemote verifies that the push remote is reachable, so that a misconfigured push remote fails the run before any fix is made.
+func (gm *GitManager) ValidatePushRemote() error {
+	if gm.pushRemoteUrl == "" {
+		return nil
+	}
+	if _, err := gm.listPushRemoteRefs(); err != nil {
+		return fmt.Errorf("the push remote %s is not reachable: %s", removeCredentialsFromUrlIfNeeded(gm.pushRemoteUrl), err.Error())
+	}
+	return nil
+}
+
+// Returns the remote the fix branches are pushed to, and the authentication to access it.
+func (gm *GitManager) getPushRemote() (*git.Remote, *githttp.BasicAuth, error) {
+	if gm.pushRemoteUrl != "" {
+		return git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: pushRemoteName, URLs: []string{gm.pushRemoteUrl}}), gm.pushAuth, nil
+	}
+	remote, err := gm.localGitRepository.Remote(gm.remoteName)
+	return remote, gm.auth, err
+}
+
+func (gm *GitManager) listPushRemoteRefs() ([]*plumbing.Reference, error) {
+	remote, auth, err := gm.getPushRemote()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		// A reachable remote with no branches yet
+		return nil, nil
+	}
+	return refs, err
+}
+
+// GetRemoteDefaultBranch returns the default branch of the remote repository, which its HEAD reference points to.
+// The VCS client can't provide it, as the RepositoryInfo of froggit-go holds only the clone URLs and the visibility of the repository,
+// so the references of the remote are listed from its clone URL instead, the same way 'git ls-remote' does.
+func GetRemoteDefaultBranch(remoteUrl, username, token string) (string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{remoteUrl}})
+	refs, err := remote.List(&git.ListOptions{Auth: toBasicAuth(username, token)})
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("the HEAD reference of %s wasn't found", removeCredentialsFromUrlIfNeeded(remoteUrl))
+}
+
 func (gm *GitManager) SetRemoteGitUrl(remoteHttpsGitUrl string) (*GitManager, error) {
 	// Check if the .git directory exists
 	dotGitExists, err := fileutils.IsDirExists(git.GitDirName, false)
@@ -129,9 +218,28 @@ func (gm *GitManager) SetGitParams(gitParams *Git) (*GitManager, error) {
 		return nil, err
 	}
 	gm.git = gitParams
+	gm.hasher = NewHasher(HashAlgorithm(gitParams.HashAlgorithm), gitParams.HashLength)
 	return gm, nil
 }
 
+// Hasher returns the hasher of the fix branch names and the pull request checksums
+func (gm *GitManager) Hasher() *Hasher {
+	if gm.hasher == nil {
+		return NewHasher(Md5HashAlgorithm, 0)
+	}
+	return gm.hasher
+}
+
+func (gm *GitManager) SetCvssVersionPreference(cvssVersionPreference CvssVersion) *GitManager {
+	gm.cvssVersionPreference = cvssVersionPreference
+	return gm
+}
+
+func (gm *GitManager) SetStateDir(stateDir string) *GitManager {
+	gm.stateDir = stateDir
+	return gm
+}
+
 func (gm *GitManager) SetEmailAuthor(emailAuthor string) *GitManager {
 	if gm.git == nil {
 		gm.git = &Git{}
@@ -176,10 +284,24 @@ func (gm *GitManager) Clone(destinationPath, branchName string) error {
 	}
 	repo, err := git.PlainClone(destinationPath, false, cloneOptions)
 	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return &ErrEmptyBranch{BranchName: branchName}
+		}
 		return fmt.Errorf("git clone %s from %s failed with error: %s", branchName, credentialsFreeRemoteGitUrl, err.Error())
 	}
 	gm.localGitRepository = repo
 	log.Debug(fmt.Sprintf("Project cloned from %s to %s", credentialsFreeRemoteGitUrl, destinationPath))
+	return gm.verifyBranchHasCommits(branchName)
+}
+
+// Returns ErrEmptyBranch if the checked out branch has no commits, since the fixes can't be branched off it
+func (gm *GitManager) verifyBranchHasCommits(branchName string) error {
+	if _, err := gm.localGitRepository.Head(); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return &ErrEmptyBranch{BranchName: branchName}
+		}
+		return err
+	}
 	return nil
 }
 
@@ -223,6 +345,55 @@ func (gm *GitManager) createBranchAndCheckout(branchName string, create bool, ke
 	return worktree.Checkout(checkoutConfig)
 }
 
+// GetHeadCommitHash returns the hash of the commit the local repository is checked out on
+func (gm *GitManager) GetHeadCommitHash() (string, error) {
+	head, err := gm.localGitRepository.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// GetLocalBranchesHashes returns the hashes of the commits the local branches point to, by branch name
+func (gm *GitManager) GetLocalBranchesHashes() (hashes map[string]string, err error) {
+	branches, err := gm.localGitRepository.Branches()
+	if err != nil {
+		return
+	}
+	hashes = map[string]string{}
+	err = branches.ForEach(func(branch *plumbing.Reference) error {
+		hashes[branch.Name().Short()] = branch.Hash().String()
+		return nil
+	})
+	return
+}
+
+// GetChangesFromBranch returns the commit of the given branch, and the message and changes of the checked out commit relative to it.
+// The changes are in the unified diff format, so they can be applied elsewhere.
+func (gm *GitManager) GetChangesFromBranch(baseBranch string) (baseCommitHash, commitMessage, patch string, err error) {
+	baseRef, err := gm.localGitRepository.Reference(plumbing.NewBranchReferenceName(baseBranch), true)
+	if err != nil {
+		return
+	}
+	baseCommit, err := gm.localGitRepository.CommitObject(baseRef.Hash())
+	if err != nil {
+		return
+	}
+	head, err := gm.localGitRepository.Head()
+	if err != nil {
+		return
+	}
+	headCommit, err := gm.localGitRepository.CommitObject(head.Hash())
+	if err != nil {
+		return
+	}
+	changes, err := baseCommit.Patch(headCommit)
+	if err != nil {
+		return
+	}
+	return baseCommit.Hash.String(), headCommit.Message, changes.String(), nil
+}
+
 func getCurrentBranch(repository *git.Repository) (string, error) {
 	head, err := repository.Head()
 	if err != nil {
@@ -252,10 +423,16 @@ func (gm *GitManager) addAll() error {
 		return err
 	}
 	worktree.Excludes = append(worktree.Excludes, ignorePatterns...)
+	if stateDir := gm.getRepositoryStateDir(worktree); stateDir != "" {
+		worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern("/"+stateDir+"/", nil))
+	}
 	status, err := worktree.Status()
 	if err != nil {
 		return err
 	}
+	if err = gm.revertCommitExcludedPaths(worktree, status); err != nil {
+		return err
+	}
 
 	err = worktree.AddWithOptions(&git.AddOptions{All: true})
 	if err != nil {
@@ -263,13 +440,181 @@ func (gm *GitManager) addAll() error {
 	}
 	// go-git add all using AddWithOptions doesn't include deleted files, that's why we need to double-check
 	for fileName, fileStatus := range status {
-		if fileStatus.Worktree == git.Deleted {
+		if fileStatus.Worktree == git.Deleted && !gm.isCommitExcludedPath(fileName) {
 			_, err = worktree.Add(fileName)
 			if err != nil {
 				return err
 			}
 		}
 	}
+	if err = gm.addExtraCommitPaths(worktree); err != nil {
+		return err
+	}
+	return gm.addFixManifest(worktree)
+}
+
+// Returns the path of the Frogbot state directory relative to the root of the repository, or an empty string if it isn't inside the repository
+func (gm *GitManager) getRepositoryStateDir(worktree *git.Worktree) string {
+	if gm.stateDir == "" {
+		return ""
+	}
+	relativePath, err := filepath.Rel(worktree.Filesystem.Root(), gm.stateDir)
+	if err != nil || relativePath == "." || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(relativePath)
+}
+
+// Leaves the changes of the paths that match the commit exclusion patterns out of the fix commits, such as generated files that change on every install.
+// Untracked files are excluded from staging, and the changes of tracked files are reverted to their committed content.
+func (gm *GitManager) revertCommitExcludedPaths(worktree *git.Worktree, status git.Status) (err error) {
+	var headTree *object.Tree
+	for path, fileStatus := range status {
+		if !gm.isCommitExcludedPath(path) || (fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified) {
+			continue
+		}
+		if fileStatus.Worktree == git.Untracked {
+			log.Debug("Leaving the commit excluded path out of the commit:", path)
+			worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern("/"+path, nil))
+			continue
+		}
+		if headTree == nil {
+			if headTree, err = gm.getHeadTree(); err != nil {
+				return
+			}
+		}
+		log.Debug("Reverting the changes of the commit excluded path:", path)
+		if err = restoreFileFromTree(worktree, headTree, path); err != nil {
+			return fmt.Errorf("failed to revert the changes of the commit excluded path '%s': %s", path, err.Error())
+		}
+	}
+	return
+}
+
+func (gm *GitManager) getHeadTree() (*object.Tree, error) {
+	head, err := gm.localGitRepository.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := gm.localGitRepository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// Restores the file to its content in the given tree, and stages it. A file that doesn't exist in the tree is removed.
+func restoreFileFromTree(worktree *git.Worktree, tree *object.Tree, path string) error {
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		_, err = worktree.Remove(path)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return err
+	}
+	fileMode, err := file.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(worktree.Filesystem.Root(), path), []byte(content), fileMode); err != nil {
+		return err
+	}
+	_, err = worktree.Add(path)
+	return err
+}
+
+// Returns true if the path matches one of the commit exclusion patterns.
+// The descriptors and lock files that fixes update are never excluded, as the fix commits can't do without them,
+// and neither are the vendor directories of Go modules, as a module doesn't build if its vendor directory doesn't match go.mod,
+// nor the fix manifest, which describes the fixes of the commit.
+func (gm *GitManager) isCommitExcludedPath(path string) bool {
+	if gm.git == nil || len(gm.git.CommitExcludePaths) == 0 || isFixDescriptorFile(path) {
+		return false
+	}
+	if gm.git.WriteFixManifest && filepath.ToSlash(path) == getRepositoryFixManifestPath(gm.git.RepoSubpath) {
+		return false
+	}
+	pathParts := strings.Split(filepath.ToSlash(path), "/")
+	if gm.isGoVendoredPath(pathParts) {
+		return false
+	}
+	for _, pattern := range gm.git.CommitExcludePaths {
+		if gitignore.ParsePattern(pattern, nil).Match(pathParts, false) == gitignore.Exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the path is in the vendor directory of a Go module, which is identified by its modules.txt manifest
+func (gm *GitManager) isGoVendoredPath(pathParts []string) bool {
+	if gm.localGitRepository == nil {
+		return false
+	}
+	worktree, err := gm.localGitRepository.Worktree()
+	if err != nil {
+		return false
+	}
+	for i, part := range pathParts[:len(pathParts)-1] {
+		if part != GoVendorDir {
+			continue
+		}
+		vendorManifest := filepath.Join(worktree.Filesystem.Root(), filepath.Join(pathParts[:i+1]...), GoVendorManifestFile)
+		if exists, err := fileutils.IsFileExists(vendorManifest, false); err == nil && exists {
+			return true
+		}
+	}
+	return false
+}
+
+func isFixDescriptorFile(path string) bool {
+	fileName := filepath.Base(path)
+	return slices.Contains(fixDescriptorFiles, fileName) || strings.HasSuffix(fileName, ".csproj")
+}
+
+// Explicitly stages the files that match the extra commit paths, including files that are excluded by .gitignore.
+// This makes sure side effects of the fix, such as an updated checksum manifest, aren't left uncommitted.
+func (gm *GitManager) addExtraCommitPaths(worktree *git.Worktree) error {
+	if gm.git == nil {
+		return nil
+	}
+	root := worktree.Filesystem.Root()
+	for _, pattern := range gm.git.ExtraCommitPaths {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return fmt.Errorf("failed to match the extra commit path pattern '%s': %s", pattern, err.Error())
+		}
+		for _, match := range matches {
+			var relativePath string
+			if relativePath, err = filepath.Rel(root, match); err != nil {
+				return err
+			}
+			log.Debug("Adding the extra commit path:", relativePath)
+			if err = worktree.AddWithOptions(&git.AddOptions{Path: filepath.ToSlash(relativePath), SkipStatus: true}); err != nil {
+				return fmt.Errorf("git add of the extra commit path '%s' failed with error: %s", relativePath, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// Explicitly stages the fix manifest, if it's written, so it's committed even if its directory is excluded by .gitignore
+func (gm *GitManager) addFixManifest(worktree *git.Worktree) error {
+	if gm.git == nil || !gm.git.WriteFixManifest {
+		return nil
+	}
+	manifestPath := getRepositoryFixManifestPath(gm.git.RepoSubpath)
+	if exists, err := fileutils.IsFileExists(filepath.Join(worktree.Filesystem.Root(), filepath.FromSlash(manifestPath)), false); err != nil || !exists {
+		return err
+	}
+	if err := worktree.AddWithOptions(&git.AddOptions{Path: manifestPath, SkipStatus: true}); err != nil {
+		return fmt.Errorf("git add of the fix manifest '%s' failed with error: %s", manifestPath, err.Error())
+	}
 	return nil
 }
 
@@ -295,11 +640,7 @@ func (gm *GitManager) BranchExistsInRemote(branchName string) (bool, error) {
 	if gm.dryRun {
 		return false, nil
 	}
-	remote, err := gm.localGitRepository.Remote(gm.remoteName)
-	if err != nil {
-		return false, errorutils.CheckError(err)
-	}
-	refList, err := remote.List(&git.ListOptions{Auth: gm.auth})
+	refList, err := gm.listPushRemoteRefs()
 	if err != nil {
 		return false, errorutils.CheckError(err)
 	}
@@ -312,14 +653,94 @@ func (gm *GitManager) BranchExistsInRemote(branchName string) (bool, error) {
 	return false, nil
 }
 
+// IsRemoteBranchFixEqual determines whether the fix committed on the branch on the remote the fix branches are pushed to is identical to the fix of the checked out commit.
+// A fix branch holds a single commit on top of the commit of the base branch it was created from, so the changes of each commit relative to its parent are compared.
+// A branch with the same fix on top of an older commit of the base branch is therefore considered equal, however far the base branch has moved since.
+func (gm *GitManager) IsRemoteBranchFixEqual(branchName string) (bool, error) {
+	remoteBranchRef := plumbing.NewRemoteReferenceName(gm.remoteName, branchName)
+	fetchOptions := &git.FetchOptions{
+		RemoteName: gm.remoteName,
+		Auth:       gm.auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branchName), remoteBranchRef))},
+		// The parent of the fix commit is needed to compute the fix
+		Depth: 2,
+		Tags:  git.NoTags,
+	}
+	if gm.pushRemoteUrl != "" {
+		fetchOptions.RemoteURL = gm.pushRemoteUrl
+		fetchOptions.Auth = gm.pushAuth
+	}
+	if err := gm.localGitRepository.Fetch(fetchOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, fmt.Errorf("git fetch %s failed with error: %s", branchName, err.Error())
+	}
+	ref, err := gm.localGitRepository.Reference(remoteBranchRef, true)
+	if err != nil {
+		return false, err
+	}
+	remoteCommit, err := gm.localGitRepository.CommitObject(ref.Hash())
+	if err != nil {
+		return false, err
+	}
+	head, err := gm.localGitRepository.Head()
+	if err != nil {
+		return false, err
+	}
+	headCommit, err := gm.localGitRepository.CommitObject(head.Hash())
+	if err != nil {
+		return false, err
+	}
+	remoteFix, err := getCommitChanges(remoteCommit)
+	if err != nil {
+		return false, err
+	}
+	headFix, err := getCommitChanges(headCommit)
+	if err != nil {
+		return false, err
+	}
+	return maps.Equal(remoteFix, headFix), nil
+}
+
+// Returns the content hash of each file the commit changed relative to its first parent, with a zero hash for a removed file
+func getCommitChanges(commit *object.Commit) (map[string]plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	// The changes of a commit without parents are relative to an empty tree
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	commitChanges := make(map[string]plumbing.Hash, len(changes))
+	for _, change := range changes {
+		if change.To.Name == "" {
+			commitChanges[change.From.Name] = plumbing.ZeroHash
+		} else {
+			commitChanges[change.To.Name] = change.To.TreeEntry.Hash
+		}
+	}
+	return commitChanges, nil
+}
+
 func (gm *GitManager) RemoveRemoteBranch(branchName string) error {
-	remote, err := gm.localGitRepository.Remote(gm.remoteName)
+	remote, auth, err := gm.getPushRemote()
 	if err != nil {
 		return err
 	}
 	return remote.Push(&git.PushOptions{
-		Auth:     gm.auth,
-		RefSpecs: []config.RefSpec{config.RefSpec(":refs/heads/" + branchName)},
+		RemoteName: remote.Config().Name,
+		Auth:       auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(":refs/heads/" + branchName)},
 	})
 }
 
@@ -329,13 +750,19 @@ func (gm *GitManager) Push(force bool, branchName string) error {
 		// On dry run do not push to any remote
 		return nil
 	}
-	// Pushing to remote
-	if err := gm.localGitRepository.Push(&git.PushOptions{
+	pushOptions := &git.PushOptions{
 		RemoteName: gm.remoteName,
 		Auth:       gm.auth,
 		Force:      force,
 		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf(refFormat, branchName))},
-	}); err != nil {
+	}
+	if gm.pushRemoteUrl != "" {
+		// Push through the cloned remote, overriding its URL with the push remote
+		pushOptions.RemoteURL = gm.pushRemoteUrl
+		pushOptions.Auth = gm.pushAuth
+	}
+	// Pushing to remote
+	if err := gm.localGitRepository.Push(pushOptions); err != nil {
 		return fmt.Errorf("git push failed with error: %s", err.Error())
 	}
 	return nil
@@ -352,7 +779,21 @@ func (gm *GitManager) IsClean() (bool, error) {
 		return false, err
 	}
 
-	return status.IsClean(), nil
+	stateDir := gm.getRepositoryStateDir(worktree)
+	for path, fileStatus := range status {
+		if stateDir != "" && strings.HasPrefix(filepath.ToSlash(path), stateDir+"/") {
+			// The state of Frogbot is never committed
+			continue
+		}
+		if gm.isCommitExcludedPath(path) {
+			// Changes that are left out of the fix commits don't require a commit
+			continue
+		}
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 func (gm *GitManager) GenerateCommitMessage(impactedPackage string, fixVersion string) string {
@@ -397,8 +838,19 @@ func formatStringWithPlaceHolders(str, impactedPackage, fixVersion, hash, baseBr
 	return str
 }
 
-func (gm *GitManager) GenerateFixBranchName(branch string, impactedPackage string, fixVersion string) (string, error) {
-	hash, err := Md5Hash("frogbot", branch, impactedPackage, fixVersion)
+// GenerateFixBranchName returns the name of the branch that fixes the package in the given working directory.
+// The working directory should be provided only if the repository has several working directories, so the names of fixes in a single working directory are kept as they were.
+func (gm *GitManager) GenerateFixBranchName(branch string, impactedPackage string, fixVersion string, workingDir string) (string, error) {
+	hashValues := []string{"frogbot", branch, impactedPackage, fixVersion}
+	if subpath := gm.getRepoSubpath(); subpath != "" {
+		// Fixes of the same package in different subpaths of the repository are kept in separate branches
+		hashValues = append(hashValues, subpath)
+	}
+	if workingDir != "" {
+		// Fixes of the same package in different working directories of the repository are kept in separate branches
+		hashValues = append(hashValues, filepath.ToSlash(workingDir))
+	}
+	hash, err := gm.getBranchHash(hashValues...)
 	if err != nil {
 		return "", err
 	}
@@ -411,6 +863,25 @@ func (gm *GitManager) GenerateFixBranchName(branch string, impactedPackage strin
 	return formatStringWithPlaceHolders(branchFormat, fixedPackageName, fixVersion, hash, "", false), nil
 }
 
+// Returns the hash of the fix branch, shortened to the configured length. The hash depends only on the fix, so its branch is named the same in every run.
+// If the shortened hash was already given to another fix branch during the run, an error is returned rather than pushing two fixes to the same branch.
+func (gm *GitManager) getBranchHash(values ...string) (string, error) {
+	hasher := gm.Hasher()
+	fullHash, err := hasher.computeFullHash(values...)
+	if err != nil {
+		return "", err
+	}
+	if gm.branchHashes == nil {
+		gm.branchHashes = map[string]string{}
+	}
+	hash := hasher.shorten(fullHash)
+	if existingHash, taken := gm.branchHashes[hash]; taken && existingHash != fullHash {
+		return "", fmt.Errorf("the hash '%s' of the fix branch was already given to another fix branch. Please increase %s, or leave it empty to use the full hashes", hash, HashLengthEnv)
+	}
+	gm.branchHashes[hash] = fullHash
+	return hash, nil
+}
+
 func (gm *GitManager) GeneratePullRequestTitle(impactedPackage string, version string) string {
 	template := PullRequestTitleTemplate
 	pullRequestFormat := gm.customTemplates.pullRequestTitleTemplate
@@ -420,7 +891,52 @@ func (gm *GitManager) GeneratePullRequestTitle(impactedPackage string, version s
 	return formatStringWithPlaceHolders(template, impactedPackage, version, "", "", true)
 }
 
-func (gm *GitManager) GenerateAggregatedPullRequestTitle(tech []techutils.Technology) string {
+// GenerateFixPullRequestTitle returns the title of a pull request that fixes a single package.
+// If configured, the highest-severity CVE of the package is appended to the title.
+// A fix that resolves several CVEs is annotated with the number of CVEs it resolves.
+func (gm *GitManager) GenerateFixPullRequestTitle(vulnDetails *VulnerabilityDetails) string {
+	title := gm.GeneratePullRequestTitle(vulnDetails.ImpactedDependencyName, vulnDetails.SuggestedFixedVersion)
+	if gm.git != nil && gm.git.IncludeCveInTitle {
+		title += gm.formatTitleCves(vulnDetails.VulnerabilityOrViolationRow.Cves)
+	}
+	if vulnDetails.ResolvesMultipleCves() {
+		title += fmt.Sprintf(" (%s)", outputwriter.ResolvedCvesAnnotation(len(vulnDetails.ResolvedCves())))
+	}
+	return title
+}
+
+// Returns the CVE with the highest CVSS score, followed by the number of the other CVEs, for example: " - CVE-2021-23337 (+2 more)"
+func (gm *GitManager) formatTitleCves(cves []formats.CveRow) string {
+	var highestCve *formats.CveRow
+	var otherCves int
+	for i := range cves {
+		if cves[i].Id == "" {
+			continue
+		}
+		if highestCve == nil {
+			highestCve = &cves[i]
+			continue
+		}
+		otherCves++
+		if GetCvssScore(cves[i], gm.cvssVersionPreference) > GetCvssScore(*highestCve, gm.cvssVersionPreference) {
+			highestCve = &cves[i]
+		}
+	}
+	if highestCve == nil {
+		return ""
+	}
+	if otherCves == 0 {
+		return " - " + highestCve.Id
+	}
+	return fmt.Sprintf(" - %s (+%d more)", highestCve.Id, otherCves)
+}
+
+// GenerateAggregatedPullRequestTitle returns the title of the pull request that fixes the given technologies together.
+// If an aggregate title format is configured and the fixed vulnerabilities are provided, the title summarizes them by the format.
+func (gm *GitManager) GenerateAggregatedPullRequestTitle(tech []techutils.Technology, vulnerabilities ...*VulnerabilityDetails) string {
+	if gm.git != nil && gm.git.AggregateTitleFormat != "" && len(vulnerabilities) > 0 {
+		return formatAggregateTitle(gm.git.AggregateTitleFormat, tech, vulnerabilities)
+	}
 	template := gm.getPullRequestTitleTemplate(tech)
 	// If no technologies are provided, return the template as-is
 	if len(tech) == 0 {
@@ -445,7 +961,64 @@ func (gm *GitManager) GenerateAggregatedFixBranchName(baseBranch string, tech []
 	if branchFormat == "" {
 		branchFormat = AggregatedBranchNameTemplate
 	}
-	return formatStringWithPlaceHolders(branchFormat, "", "", techArrayToString(tech, fixBranchTechSeparator), baseBranch, false)
+	hash := techArrayToString(tech, fixBranchTechSeparator)
+	if subpath := gm.getRepoSubpath(); subpath != "" {
+		hash += fixBranchTechSeparator + strings.ReplaceAll(subpath, "/", fixBranchTechSeparator)
+	}
+	return formatStringWithPlaceHolders(branchFormat, "", "", hash, baseBranch, false)
+}
+
+// GenerateAggregatedFixPartBranchName returns the branch of the given part of an aggregated fix that is split into several pull requests.
+func (gm *GitManager) GenerateAggregatedFixPartBranchName(baseBranch string, tech []techutils.Technology, part int) string {
+	return fmt.Sprintf("%s-part-%d", gm.GenerateAggregatedFixBranchName(baseBranch, tech), part)
+}
+
+func (gm *GitManager) GenerateAggregatedPartPullRequestTitle(tech []techutils.Technology, part int, vulnerabilities ...*VulnerabilityDetails) string {
+	return fmt.Sprintf("%s (part %d)", gm.GenerateAggregatedPullRequestTitle(tech, vulnerabilities...), part)
+}
+
+// GenerateDirFixBranchName returns the branch of the fixes of a single working directory, when the fixes are grouped by working directory.
+// The branch is named after the directory rather than its technologies, so the fixes of the directory are kept in the same branch across runs.
+func (gm *GitManager) GenerateDirFixBranchName(baseBranch, workingDir string) string {
+	branchFormat := gm.customTemplates.branchNameTemplate
+	if branchFormat == "" {
+		branchFormat = AggregatedBranchNameTemplate
+	}
+	hash := rootDirBranchName
+	if workingDir != "" {
+		hash = strings.ReplaceAll(filepath.ToSlash(workingDir), "/", fixBranchTechSeparator)
+	}
+	if subpath := gm.getRepoSubpath(); subpath != "" {
+		hash = strings.ReplaceAll(subpath, "/", fixBranchTechSeparator) + fixBranchTechSeparator + hash
+	}
+	return formatStringWithPlaceHolders(branchFormat, "", "", hash, baseBranch, false)
+}
+
+// GenerateDirPullRequestTitle returns the title of the pull request that fixes the given technologies in a single working directory.
+func (gm *GitManager) GenerateDirPullRequestTitle(tech []techutils.Technology, workingDir string, vulnerabilities ...*VulnerabilityDetails) string {
+	title := gm.GenerateAggregatedPullRequestTitle(tech, vulnerabilities...)
+	if workingDir == "" {
+		return title
+	}
+	return fmt.Sprintf("%s in %s", title, filepath.ToSlash(workingDir))
+}
+
+// GenerateDirCommitMessage returns the message of the commit that fixes the given technologies in a single working directory.
+func (gm *GitManager) GenerateDirCommitMessage(tech []techutils.Technology, workingDir string) string {
+	template := gm.customTemplates.commitMessageTemplate
+	if template == "" {
+		// As in aggregated mode, the commit message and the pull request title are the same
+		template = gm.GenerateDirPullRequestTitle(tech, workingDir)
+	}
+	return formatStringWithPlaceHolders(template, "", "", "", "", true)
+}
+
+// Returns the directory inside the repository that Frogbot treats as the repository root, or an empty string if the root of the repository is used.
+func (gm *GitManager) getRepoSubpath() string {
+	if gm.git == nil {
+		return ""
+	}
+	return gm.git.RepoSubpath
 }
 
 // dryRunClone clones an existing repository from our testdata folder into the destination folder for testing purposes.
@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/formats"
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGitManager_GenerateCommitMessage(t *testing.T) {
@@ -92,17 +95,73 @@ func TestGitManager_GenerateFixBranchName(t *testing.T) {
 			fixVersion:      VulnerabilityDetails{SuggestedFixedVersion: "3.4.5"},
 			expected:        "just-a-branch-41b1f45136b25e3624b15999bd57a476",
 			description:     "Custom template without inputs",
+		}, {
+			gitManager:      GitManager{git: &Git{RepoSubpath: "services/foo"}},
+			impactedPackage: "mquery",
+			fixVersion:      VulnerabilityDetails{SuggestedFixedVersion: "3.4.5"},
+			expected:        "frogbot-mquery-4fa17ceba2088a623eb630287b7ffcc2",
+			description:     "Repository subpath",
 		},
 	}
 	for _, test := range testCases {
 		t.Run(test.expected, func(t *testing.T) {
-			commitMessage, err := test.gitManager.GenerateFixBranchName("md5Branch", test.impactedPackage, test.fixVersion.SuggestedFixedVersion)
+			commitMessage, err := test.gitManager.GenerateFixBranchName("md5Branch", test.impactedPackage, test.fixVersion.SuggestedFixedVersion, "")
 			assert.NoError(t, err)
 			assert.Equal(t, test.expected, commitMessage)
 		})
 	}
 }
 
+func TestGitManager_GenerateFixBranchNameInWorkingDirs(t *testing.T) {
+	gitManager := GitManager{}
+	rootBranchName, err := gitManager.GenerateFixBranchName("md5Branch", "mquery", "3.4.5", "")
+	assert.NoError(t, err)
+	frontendBranchName, err := gitManager.GenerateFixBranchName("md5Branch", "mquery", "3.4.5", "frontend")
+	assert.NoError(t, err)
+	backendBranchName, err := gitManager.GenerateFixBranchName("md5Branch", "mquery", "3.4.5", "backend")
+	assert.NoError(t, err)
+	// Fixes of the same package in different working directories are kept in separate branches
+	assert.Equal(t, "frogbot-mquery-41b1f45136b25e3624b15999bd57a476", rootBranchName)
+	assert.NotEqual(t, frontendBranchName, backendBranchName)
+	assert.NotEqual(t, rootBranchName, frontendBranchName)
+	// The working directory is hashed in the same way on all operating systems
+	nestedBranchName, err := gitManager.GenerateFixBranchName("md5Branch", "mquery", "3.4.5", filepath.Join("services", "frontend"))
+	assert.NoError(t, err)
+	expectedNestedBranchName, err := gitManager.GenerateFixBranchName("md5Branch", "mquery", "3.4.5", "services/frontend")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedNestedBranchName, nestedBranchName)
+}
+
+func TestGitManager_GenerateFixBranchNameWithShortenedHash(t *testing.T) {
+	gitManager, err := NewGitManager().SetGitParams(&Git{HashAlgorithm: string(Sha256HashAlgorithm), HashLength: 12})
+	require.NoError(t, err)
+	branchName, err := gitManager.GenerateFixBranchName("master", "mquery", "3.4.5", "")
+	require.NoError(t, err)
+	assert.Regexp(t, "^frogbot-mquery-[0-9a-f]{12}$", branchName)
+	// The same fix is given the same branch in every run
+	sameBranchName, err := gitManager.GenerateFixBranchName("master", "mquery", "3.4.5", "")
+	require.NoError(t, err)
+	assert.Equal(t, branchName, sameBranchName)
+	otherGitManager, err := NewGitManager().SetGitParams(&Git{HashAlgorithm: string(Sha256HashAlgorithm), HashLength: 12})
+	require.NoError(t, err)
+	otherRunBranchName, err := otherGitManager.GenerateFixBranchName("master", "mquery", "3.4.5", "")
+	require.NoError(t, err)
+	assert.Equal(t, branchName, otherRunBranchName)
+
+	// Different fixes don't collide at the configured length
+	otherBranchName, err := gitManager.GenerateFixBranchName("master", "mquery", "3.4.6", "")
+	require.NoError(t, err)
+	assert.Regexp(t, "^frogbot-mquery-[0-9a-f]{12}$", otherBranchName)
+	assert.NotEqual(t, branchName, otherBranchName)
+
+	// A fix whose shortened hash was already given to another fix in the run isn't given a branch, as its name mustn't depend on the order of the fixes
+	fullHash, err := gitManager.Hasher().computeFullHash("frogbot", "master", "lodash", "4.17.21")
+	require.NoError(t, err)
+	gitManager.branchHashes[fullHash[:12]] = strings.Repeat("0", len(fullHash))
+	_, err = gitManager.GenerateFixBranchName("master", "lodash", "4.17.21", "")
+	assert.ErrorContains(t, err, fmt.Sprintf("the hash '%s' of the fix branch was already given to another fix branch", fullHash[:12]))
+}
+
 func TestGitManager_GeneratePullRequestTitle(t *testing.T) {
 	testCases := []struct {
 		gitManager      GitManager
@@ -141,6 +200,53 @@ func TestGitManager_GeneratePullRequestTitle(t *testing.T) {
 	}
 }
 
+func TestGitManager_GenerateFixPullRequestTitle(t *testing.T) {
+	testCases := []struct {
+		description       string
+		includeCveInTitle bool
+		cves              []formats.CveRow
+		expected          string
+	}{
+		{
+			description: "CVE not included by default",
+			cves:        []formats.CveRow{{Id: "CVE-2021-23337", CvssV3: "7.2"}},
+			expected:    "[🐸 Frogbot] Update version of lodash to 4.17.21",
+		},
+		{
+			description:       "Single CVE",
+			includeCveInTitle: true,
+			cves:              []formats.CveRow{{Id: "CVE-2021-23337", CvssV3: "7.2"}},
+			expected:          "[🐸 Frogbot] Update version of lodash to 4.17.21 - CVE-2021-23337",
+		},
+		{
+			description:       "Multiple CVEs",
+			includeCveInTitle: true,
+			cves:              []formats.CveRow{{Id: "CVE-2020-28500", CvssV3: "5.3"}, {Id: "CVE-2021-23337", CvssV3: "7.2"}, {Id: "CVE-2020-8203", CvssV3: "7.4"}},
+			expected:          "[🐸 Frogbot] Update version of lodash to 4.17.21 - CVE-2020-8203 (+2 more) (Resolves 3 CVEs)",
+		},
+		{
+			description: "Multiple CVEs resolved by a single fix are highlighted",
+			cves:        []formats.CveRow{{Id: "CVE-2020-28500", CvssV3: "5.3"}, {Id: "CVE-2021-23337", CvssV3: "7.2"}, {Id: "CVE-2020-8203", CvssV3: "7.4"}},
+			expected:    "[🐸 Frogbot] Update version of lodash to 4.17.21 (Resolves 3 CVEs)",
+		},
+		{
+			description:       "No CVEs",
+			includeCveInTitle: true,
+			expected:          "[🐸 Frogbot] Update version of lodash to 4.17.21",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			gitManager := GitManager{git: &Git{IncludeCveInTitle: test.includeCveInTitle}}
+			vulnDetails := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+				ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash"},
+				Cves:                      test.cves,
+			}, "4.17.21")
+			assert.Equal(t, test.expected, gitManager.GenerateFixPullRequestTitle(vulnDetails))
+		})
+	}
+}
+
 func TestGitManager_GenerateAggregatedFixBranchName(t *testing.T) {
 	testCases := []struct {
 		gitManager GitManager
@@ -169,6 +275,11 @@ func TestGitManager_GenerateAggregatedFixBranchName(t *testing.T) {
 			baseBranch: "master",
 			desc:       "Custom template hash only",
 			gitManager: GitManager{customTemplates: CustomTemplates{branchNameTemplate: "[feature]-${BRANCH_NAME_HASH}"}},
+		}, {
+			expected:   "frogbot-update-Go-services-foo-dependencies-main",
+			baseBranch: "main",
+			desc:       "Repository subpath",
+			gitManager: GitManager{git: &Git{RepoSubpath: "services/foo"}},
 		},
 	}
 	for _, test := range testCases {
@@ -179,6 +290,26 @@ func TestGitManager_GenerateAggregatedFixBranchName(t *testing.T) {
 	}
 }
 
+func TestGitManager_GenerateDirFixBranchName(t *testing.T) {
+	testCases := []struct {
+		gitManager GitManager
+		workingDir string
+		expected   string
+		desc       string
+	}{
+		{gitManager: GitManager{}, workingDir: filepath.Join("services", "api"), expected: "frogbot-update-services-api-dependencies-main", desc: "Working directory"},
+		{gitManager: GitManager{}, workingDir: "", expected: "frogbot-update-root-dependencies-main", desc: "Repository root"},
+		{gitManager: GitManager{customTemplates: CustomTemplates{branchNameTemplate: "[feature]-${BRANCH_NAME_HASH}"}}, workingDir: "web", expected: "[feature]-web-main", desc: "Custom template"},
+		{gitManager: GitManager{git: &Git{RepoSubpath: "monorepo"}}, workingDir: "web", expected: "frogbot-update-monorepo-web-dependencies-main", desc: "Repository subpath"},
+	}
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.gitManager.GenerateDirFixBranchName("main", test.workingDir))
+		})
+	}
+	assert.Equal(t, "[🐸 Frogbot] Update npm,Pip dependencies in services/api", (&GitManager{}).GenerateDirPullRequestTitle([]techutils.Technology{techutils.Npm, techutils.Pip}, filepath.Join("services", "api")))
+}
+
 func TestGitManager_GenerateAggregatedCommitMessage(t *testing.T) {
 	testCases := []struct {
 		gitManager GitManager
@@ -291,6 +422,285 @@ func createFakeDotGit(t *testing.T, testPath string) *GitManager {
 	return manager
 }
 
+func TestGitManager_CloneEmptyBranch(t *testing.T) {
+	tmpDir := t.TempDir()
+	restoreWd, err := Chdir(tmpDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	// A newly created repository, without commits
+	remoteDir := filepath.Join(tmpDir, "remote")
+	_, err = git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
+	gitManager, err := NewGitManager().SetRemoteGitUrl(remoteDir)
+	require.NoError(t, err)
+	err = gitManager.Clone(filepath.Join(tmpDir, "clone"), "master")
+	var errEmptyBranch *ErrEmptyBranch
+	require.ErrorAs(t, err, &errEmptyBranch)
+	assert.Equal(t, "master", errEmptyBranch.BranchName)
+}
+
+func TestGitManager_FixBranchWithoutHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	restoreWd, err := Chdir(tmpDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	// A freshly created branch with a single descriptor file, and no commits or fix branches of Frogbot
+	repo, err := git.PlainInit(tmpDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.5"}}`), 0644))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("package.json")
+	require.NoError(t, err)
+	firstCommit, err := worktree.Commit("Initial commit", &git.CommitOptions{Author: &object.Signature{Name: "Your Name", Email: "your@email.com"}})
+	require.NoError(t, err)
+
+	gitManager := NewGitManager().SetDryRun(true, tmpDir)
+	require.NoError(t, gitManager.Clone(tmpDir, "master"))
+	_, err = gitManager.SetGitParams(&Git{EmailAuthor: frogbotAuthorEmail})
+	require.NoError(t, err)
+	require.NoError(t, gitManager.CreateBranchAndCheckout("frogbot-fix", false))
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.6"}}`), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Upgrade minimist to 1.2.6"))
+
+	baseCommitHash, commitMessage, patch, err := gitManager.GetChangesFromBranch("master")
+	require.NoError(t, err)
+	assert.Equal(t, firstCommit.String(), baseCommitHash)
+	assert.Equal(t, "Upgrade minimist to 1.2.6", commitMessage)
+	assert.Contains(t, patch, `+{"dependencies": {"minimist": "1.2.6"}}`)
+}
+
+func TestGitManager_PushToPushRemote(t *testing.T) {
+	tmpDir, err := fileutils.CreateTempDir()
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, fileutils.RemoveTempDir(tmpDir))
+	}()
+	repoDir, mirrorDir, pushRemoteDir := filepath.Join(tmpDir, "repo"), filepath.Join(tmpDir, "mirror"), filepath.Join(tmpDir, "push-remote")
+	for _, bareRepoDir := range []string{mirrorDir, pushRemoteDir} {
+		_, err = git.PlainInit(bareRepoDir, true)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, os.Mkdir(repoDir, 0755))
+	restoreWd, err := Chdir(repoDir)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	gitManager := createFakeDotGit(t, repoDir)
+	// The cloned remote is a read-only mirror
+	_, err = gitManager.localGitRepository.CreateRemote(&config.RemoteConfig{Name: vcsutils.RemoteName, URLs: []string{mirrorDir}})
+	assert.NoError(t, err)
+
+	assert.Error(t, gitManager.SetPushRemote(filepath.Join(tmpDir, "not-exist"), "", "token").ValidatePushRemote())
+	gitManager.SetPushRemote(pushRemoteDir, "", "token")
+	assert.NoError(t, gitManager.ValidatePushRemote())
+
+	// Pushes are mocked on dry runs
+	gitManager.dryRun = false
+	assert.NoError(t, gitManager.CreateBranchAndCheckout("frogbot-fix", false))
+	assert.NoError(t, gitManager.Push(false, "frogbot-fix"))
+	exists, err := gitManager.BranchExistsInRemote("frogbot-fix")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	pushRemote, err := git.PlainOpen(pushRemoteDir)
+	assert.NoError(t, err)
+	_, err = pushRemote.Reference("refs/heads/frogbot-fix", false)
+	assert.NoError(t, err)
+	mirror, err := git.PlainOpen(mirrorDir)
+	assert.NoError(t, err)
+	_, err = mirror.Reference("refs/heads/frogbot-fix", false)
+	assert.Error(t, err)
+
+	assert.NoError(t, gitManager.RemoveRemoteBranch("frogbot-fix"))
+	exists, err = gitManager.BranchExistsInRemote("frogbot-fix")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGitManager_AddExtraCommitPaths(t *testing.T) {
+	tmpDir, err := fileutils.CreateTempDir()
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, fileutils.RemoveTempDir(tmpDir))
+	}()
+	restoreWd, err := Chdir(tmpDir)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	gitManager := createFakeDotGit(t, tmpDir)
+	_, err = gitManager.SetGitParams(&Git{EmailAuthor: frogbotAuthorEmail, ExtraCommitPaths: []string{"*.sum.txt", "patches/*.patch"}})
+	assert.NoError(t, err)
+
+	// The checksum manifests and patches are generated files, which are ignored by default
+	assert.NoError(t, os.WriteFile(".gitignore", []byte("*.txt\npatches/\n"), 0644))
+	assert.NoError(t, os.Mkdir("patches", 0755))
+	// The dependency fix
+	assert.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.6"}}`), 0644))
+	// The side effects of the fix, such as files that are modified by a pre-commit hook
+	assert.NoError(t, os.WriteFile("deps.sum.txt", []byte("minimist 1.2.6 sha512-abc"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join("patches", "minimist.patch"), []byte("--- a\n+++ b"), 0644))
+	assert.NoError(t, os.WriteFile("build.txt", []byte("build output"), 0644))
+
+	assert.NoError(t, gitManager.AddAllAndCommit("Upgrade minimist to 1.2.6"))
+	head, err := gitManager.localGitRepository.Head()
+	assert.NoError(t, err)
+	commit, err := gitManager.localGitRepository.CommitObject(head.Hash())
+	assert.NoError(t, err)
+	parent, err := commit.Parent(0)
+	assert.NoError(t, err)
+	patch, err := parent.Patch(commit)
+	assert.NoError(t, err)
+	var committedFiles []string
+	for _, filePatch := range patch.FilePatches() {
+		_, to := filePatch.Files()
+		committedFiles = append(committedFiles, to.Path())
+	}
+	// Ignored files that don't match the extra commit paths are left out
+	assert.ElementsMatch(t, []string{".gitignore", "package.json", "deps.sum.txt", "patches/minimist.patch"}, committedFiles)
+}
+
+func TestGitManager_CommitExcludePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	restoreWd, err := Chdir(tmpDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	gitManager := createFakeDotGit(t, tmpDir)
+	_, err = gitManager.SetGitParams(&Git{EmailAuthor: frogbotAuthorEmail})
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(".yarn", 0755))
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.5"}}`), 0644))
+	require.NoError(t, os.WriteFile("yarn.lock", []byte("minimist@1.2.5"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(".yarn", "install-state.gz"), []byte("state 1"), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Add the project"))
+
+	// The lock file matches an exclusion pattern as well, but it's required for the fix
+	gitManager.git.CommitExcludePaths = []string{".yarn/", "*.lock"}
+	// The fix, followed by an install that regenerates its state files
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.6"}}`), 0644))
+	require.NoError(t, os.WriteFile("yarn.lock", []byte("minimist@1.2.6"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(".yarn", "install-state.gz"), []byte("state 2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(".yarn", "build-state.yml"), []byte("built"), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Upgrade minimist to 1.2.6"))
+	assert.ElementsMatch(t, []string{"package.json", "yarn.lock"}, getHeadCommitFiles(t, gitManager))
+
+	// The excluded changes of tracked files are reverted, and the new excluded files are left untracked
+	content, err := os.ReadFile(filepath.Join(".yarn", "install-state.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "state 1", string(content))
+	exists, err := fileutils.IsFileExists(filepath.Join(".yarn", "build-state.yml"), false)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	isClean, err := gitManager.IsClean()
+	require.NoError(t, err)
+	assert.True(t, isClean)
+
+	// A change of an excluded file alone doesn't require a commit
+	require.NoError(t, os.WriteFile(filepath.Join(".yarn", "install-state.gz"), []byte("state 3"), 0644))
+	isClean, err = gitManager.IsClean()
+	require.NoError(t, err)
+	assert.True(t, isClean)
+}
+
+func TestGitManager_StateDirNotCommitted(t *testing.T) {
+	tmpDir := t.TempDir()
+	restoreWd, err := Chdir(tmpDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	gitManager := createFakeDotGit(t, tmpDir)
+	_, err = gitManager.SetGitParams(&Git{EmailAuthor: frogbotAuthorEmail})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.5"}}`), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Add the project"))
+
+	// The state directory is inside the repository, as Frogbot was executed from its root
+	stateDir, err := filepath.Abs(DefaultStateDir)
+	require.NoError(t, err)
+	gitManager.SetStateDir(stateDir)
+	require.NoError(t, SaveVulnerabilitiesBaseline(stateDir, NewVulnerabilitiesBaseline("jfrog", "frogbot", "master", []string{"CVE-2021-44906"})))
+	isClean, err := gitManager.IsClean()
+	require.NoError(t, err)
+	assert.True(t, isClean)
+
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.6"}}`), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Upgrade minimist to 1.2.6"))
+	assert.Equal(t, []string{"package.json"}, getHeadCommitFiles(t, gitManager))
+}
+
+// Returns the files that were changed by the HEAD commit
+func TestGitManager_WriteFixManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	restoreWd, err := Chdir(tmpDir)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, restoreWd())
+	}()
+	gitManager := createFakeDotGit(t, tmpDir)
+	_, err = gitManager.SetGitParams(&Git{EmailAuthor: frogbotAuthorEmail, WriteFixManifest: true})
+	require.NoError(t, err)
+	// The Frogbot directory is ignored and excluded, as it holds the state files, but the fix manifest is committed regardless
+	require.NoError(t, os.WriteFile(".gitignore", []byte(".frogbot/\n"), 0644))
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.5", "lodash": "4.17.20"}}`), 0644))
+	require.NoError(t, gitManager.AddAllAndCommit("Add the project"))
+	gitManager.git.CommitExcludePaths = []string{".frogbot/"}
+
+	minimist := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+		Technology:                techutils.Npm,
+	}, "1.2.6")
+	minimist.SetCves([]formats.CveRow{{Id: "CVE-2021-44906"}})
+	minimist.SetIsDirectDependency(true)
+	lodash := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"},
+		Technology:                techutils.Npm,
+	}, "4.17.21")
+	lodash.SetCves([]formats.CveRow{{Id: "cve-2021-23337"}, {Id: "CVE-2020-28500"}, {Id: "CVE-2021-23337"}})
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"dependencies": {"minimist": "1.2.6", "lodash": "4.17.21"}}`), 0644))
+	require.NoError(t, WriteFixManifest(tmpDir, minimist, lodash))
+	require.NoError(t, gitManager.AddAllAndCommit("Upgrade minimist and lodash"))
+	assert.ElementsMatch(t, []string{"package.json", FixManifestPath}, getHeadCommitFiles(t, gitManager))
+
+	// The manifest describes the applied fixes, sorted by the names of the packages
+	head, err := gitManager.localGitRepository.Head()
+	require.NoError(t, err)
+	commit, err := gitManager.localGitRepository.CommitObject(head.Hash())
+	require.NoError(t, err)
+	manifestFile, err := commit.File(FixManifestPath)
+	require.NoError(t, err)
+	content, err := manifestFile.Contents()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fixes": [
+		{"package": "lodash", "technology": "npm", "oldVersion": "4.17.20", "newVersion": "4.17.21", "cves": ["CVE-2020-28500", "CVE-2021-23337"], "directDependency": false},
+		{"package": "minimist", "technology": "npm", "oldVersion": "1.2.5", "newVersion": "1.2.6", "cves": ["CVE-2021-44906"], "directDependency": true}
+	]}`, content)
+}
+
+func getHeadCommitFiles(t *testing.T, gitManager *GitManager) (files []string) {
+	head, err := gitManager.localGitRepository.Head()
+	require.NoError(t, err)
+	commit, err := gitManager.localGitRepository.CommitObject(head.Hash())
+	require.NoError(t, err)
+	parent, err := commit.Parent(0)
+	require.NoError(t, err)
+	patch, err := parent.Patch(commit)
+	require.NoError(t, err)
+	for _, filePatch := range patch.FilePatches() {
+		_, to := filePatch.Files()
+		files = append(files, to.Path())
+	}
+	return
+}
+
 func TestGitManager_SetRemoteGitUrl(t *testing.T) {
 	testCases := []struct {
 		description       string
@@ -389,6 +799,39 @@ func TestGetAggregatedPullRequestTitle(t *testing.T) {
 	}
 }
 
+func TestGetAggregatedPullRequestTitleWithFormat(t *testing.T) {
+	newVulnerability := func(packageName, version, severity string, cves ...string) *VulnerabilityDetails {
+		var cveRows []formats.CveRow
+		for _, cve := range cves {
+			cveRows = append(cveRows, formats.CveRow{Id: cve})
+		}
+		return NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: packageName, ImpactedDependencyVersion: version},
+			Cves:                      cveRows,
+		}, "")
+	}
+	vulnerabilities := []*VulnerabilityDetails{
+		newVulnerability("lodash", "4.17.20", "High", "CVE-2021-23337", "CVE-2020-28500"),
+		newVulnerability("minimist", "1.2.5", "Critical", "CVE-2021-44906"),
+		// The same dependency fixed in another working directory is counted once
+		newVulnerability("lodash", "4.17.20", "High", "CVE-2021-23337"),
+	}
+	tech := []techutils.Technology{techutils.Npm, techutils.Yarn}
+
+	gm := GitManager{git: &Git{AggregateTitleFormat: "[🐸 Frogbot] Update {DEPENDENCIES_COUNT} vulnerable dependencies"}}
+	assert.Equal(t, "[🐸 Frogbot] Update 2 vulnerable dependencies", gm.GenerateAggregatedPullRequestTitle(tech, vulnerabilities...))
+	assert.Equal(t, "[🐸 Frogbot] Update 2 vulnerable dependencies (part 2)", gm.GenerateAggregatedPartPullRequestTitle(tech, 2, vulnerabilities...))
+
+	gm.git.AggregateTitleFormat = "{HIGHEST_SEVERITY}: fix {CVES_COUNT} CVEs in {TECHNOLOGIES}"
+	assert.Equal(t, "Critical: fix 3 CVEs in npm,Yarn", gm.GenerateAggregatedPullRequestTitle(tech, vulnerabilities...))
+
+	// Without the fixed vulnerabilities, such as in the aggregated commit message, the default title is kept
+	assert.Equal(t, "[🐸 Frogbot] Update npm,Yarn dependencies", gm.GenerateAggregatedPullRequestTitle(tech))
+
+	assert.NoError(t, validateAggregateTitleFormat("Update {DEPENDENCIES_COUNT} dependencies"))
+	assert.ErrorContains(t, validateAggregateTitleFormat("Update {COUNT} dependencies"), "Unknown placeholder {COUNT}")
+}
+
 func TestRemoveCredentialsFromUrlIfNeeded(t *testing.T) {
 	testsCases := []struct {
 		url      string
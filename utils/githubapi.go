@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const defaultGitHubApiEndpoint = "https://api.github.com"
+
+var nextPageLinkRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func getGitHubApiEndpoint(apiEndpoint string) string {
+	if apiEndpoint == "" {
+		return defaultGitHubApiEndpoint
+	}
+	return strings.TrimSuffix(apiEndpoint, "/")
+}
+
+// Sends a request to the GitHub REST API and returns the body and the headers of the response.
+// If requestBody isn't nil, it is sent as JSON.
+func sendGitHubApiRequest(client *http.Client, method, url, token string, requestBody any) (body []byte, header http.Header, err error) {
+	var content io.Reader
+	if requestBody != nil {
+		var requestContent []byte
+		if requestContent, err = json.Marshal(requestBody); err != nil {
+			return
+		}
+		content = bytes.NewReader(requestContent)
+	}
+	req, err := http.NewRequest(method, url, content)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if requestBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	log.Debug(fmt.Sprintf("Sending HTTP %s request to: '%s'", req.Method, req.URL))
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		err = fmt.Errorf("server response: %s\n%s", resp.Status, body)
+		return
+	}
+	return body, resp.Header, nil
+}
+
+// Returns the URL of the next page of a paginated response, or an empty string if this is the last page.
+func getGitHubNextPageUrl(header http.Header) string {
+	if match := nextPageLinkRegex.FindStringSubmatch(header.Get("Link")); match != nil {
+		return match[1]
+	}
+	return ""
+}
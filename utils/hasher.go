@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"crypto"
+	// Register the hash algorithms the fix branch names and the pull request checksums may be hashed with
+	_ "crypto/md5"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Shorter hashes collide too often to identify the fix branches and the pull request checksums
+const minHashLength = 8
+
+var hashAlgorithms = map[HashAlgorithm]crypto.Hash{
+	Md5HashAlgorithm:    crypto.MD5,
+	Sha1HashAlgorithm:   crypto.SHA1,
+	Sha256HashAlgorithm: crypto.SHA256,
+}
+
+// Hasher hashes the fix branch names and the pull request checksums with the configured algorithm, shortened to the configured length.
+// The default hasher produces the full MD5 hashes, as before the algorithm and the length were configurable.
+type Hasher struct {
+	algorithm HashAlgorithm
+	// The number of hex characters the hashes are shortened to. 0 keeps the full hashes
+	length int
+}
+
+func NewHasher(algorithm HashAlgorithm, length int) *Hasher {
+	if algorithm == "" {
+		algorithm = Md5HashAlgorithm
+	}
+	return &Hasher{algorithm: algorithm, length: length}
+}
+
+func validateHashParams(algorithm HashAlgorithm, length int) error {
+	hash, supported := hashAlgorithms[algorithm]
+	if !supported {
+		return fmt.Errorf("the provided hash algorithm '%s' is invalid. Valid values are: %s, %s, %s", algorithm, Md5HashAlgorithm, Sha1HashAlgorithm, Sha256HashAlgorithm)
+	}
+	if maxLength := hex.EncodedLen(hash.Size()); length != 0 && (length < minHashLength || length > maxLength) {
+		return fmt.Errorf("the hash length of the %s algorithm must be between %d and %d, but %d was provided", algorithm, minHashLength, maxLength, length)
+	}
+	return nil
+}
+
+// Hash returns the hash of the values, shortened to the configured length
+func (h *Hasher) Hash(values ...string) (string, error) {
+	fullHash, err := h.computeFullHash(values...)
+	if err != nil {
+		return "", err
+	}
+	return h.shorten(fullHash), nil
+}
+
+func (h *Hasher) shorten(fullHash string) string {
+	if h.length == 0 || h.length >= len(fullHash) {
+		return fullHash
+	}
+	return fullHash[:h.length]
+}
+
+// computeFullHash returns the hash of the values in its full length
+func (h *Hasher) computeFullHash(values ...string) (string, error) {
+	hashAlgorithm, supported := hashAlgorithms[h.algorithm]
+	if !supported {
+		return "", fmt.Errorf("the hash algorithm '%s' isn't supported", h.algorithm)
+	}
+	hash := hashAlgorithm.New()
+	for _, value := range values {
+		if _, err := fmt.Fprint(hash, value); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasher(t *testing.T) {
+	// The default hasher keeps the hashes of the branches and the checksums of the existing pull requests
+	md5Hash, err := Md5Hash("frogbot", "master", "mquery", "3.4.5")
+	require.NoError(t, err)
+	hash, err := NewHasher("", 0).Hash("frogbot", "master", "mquery", "3.4.5")
+	require.NoError(t, err)
+	assert.Equal(t, md5Hash, hash)
+
+	hash, err = NewHasher(Sha1HashAlgorithm, 0).Hash("frogbot")
+	require.NoError(t, err)
+	assert.Len(t, hash, 40)
+	shortenedHash, err := NewHasher(Sha1HashAlgorithm, 10).Hash("frogbot")
+	require.NoError(t, err)
+	assert.Equal(t, hash[:10], shortenedHash)
+}
+
+func TestValidateHashParams(t *testing.T) {
+	assert.NoError(t, validateHashParams(Md5HashAlgorithm, 0))
+	assert.NoError(t, validateHashParams(Sha256HashAlgorithm, 64))
+	assert.ErrorContains(t, validateHashParams("crc32", 0), "the provided hash algorithm 'crc32' is invalid. Valid values are: md5, sha1, sha256")
+	assert.ErrorContains(t, validateHashParams(Sha1HashAlgorithm, 4), "the hash length of the sha1 algorithm must be between 8 and 40, but 4 was provided")
+	assert.ErrorContains(t, validateHashParams(Md5HashAlgorithm, 33), "the hash length of the md5 algorithm must be between 8 and 32, but 33 was provided")
+}
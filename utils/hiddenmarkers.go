@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+)
+
+// The bits of a zero-width marker are encoded by zero-width spaces and zero-width non-joiners, and the marker is delimited by word joiners
+const (
+	zeroWidthZeroBit    = '\u200b'
+	zeroWidthOneBit     = '\u200c'
+	zeroWidthDelimiter  = '\u2060'
+	zeroWidthByteLength = 8
+)
+
+var zeroWidthMarkerRegex = regexp.MustCompile(`\x{2060}([\x{200b}\x{200c}]+)\x{2060}`)
+
+// HiddenMarker returns a marker of the given text that isn't rendered in the pull request body, such as its checksum, according to the checksum storage.
+// Providers that strip comments from the body keep the zero-width markers, which are rendered as invisible characters.
+func HiddenMarker(text string, storage ChecksumStorage) string {
+	if storage != ZeroWidthChecksumStorage {
+		return outputwriter.MarkdownComment(text)
+	}
+	var marker strings.Builder
+	marker.WriteString("\n\n")
+	marker.WriteRune(zeroWidthDelimiter)
+	for _, textByte := range []byte(text) {
+		for bit := zeroWidthByteLength - 1; bit >= 0; bit-- {
+			if textByte&(1<<bit) == 0 {
+				marker.WriteRune(zeroWidthZeroBit)
+			} else {
+				marker.WriteRune(zeroWidthOneBit)
+			}
+		}
+	}
+	marker.WriteRune(zeroWidthDelimiter)
+	marker.WriteString("\n")
+	return marker.String()
+}
+
+// RevealHiddenMarkers decodes the zero-width markers in the body into Markdown comments, so the markers are read the same way regardless of the storage they were written with.
+func RevealHiddenMarkers(body string) string {
+	return zeroWidthMarkerRegex.ReplaceAllStringFunc(body, func(marker string) string {
+		bits := []rune(zeroWidthMarkerRegex.FindStringSubmatch(marker)[1])
+		if len(bits)%zeroWidthByteLength != 0 {
+			return marker
+		}
+		text := make([]byte, 0, len(bits)/zeroWidthByteLength)
+		for i := 0; i < len(bits); i += zeroWidthByteLength {
+			var textByte byte
+			for _, bit := range bits[i : i+zeroWidthByteLength] {
+				textByte <<= 1
+				if bit == zeroWidthOneBit {
+					textByte |= 1
+				}
+			}
+			text = append(text, textByte)
+		}
+		return outputwriter.MarkdownComment(string(text))
+	})
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const htmlReportTitle = "Frogbot Scan Report"
+
+// HtmlReport holds the results of scanning a branch, rendered into a self-contained HTML file
+type HtmlReport struct {
+	Repository  string
+	Branch      string
+	GeneratedAt time.Time
+	// Marks a report of a run that didn't push its fixes
+	DryRun bool
+	Rows   []HtmlReportRow
+}
+
+type HtmlReportRow struct {
+	PackageName    string
+	PackageVersion string
+	Severity       string
+	Applicable     string
+	// The CVEs of the vulnerability, or its Xray issue ID if it has no CVEs
+	VulnerabilityIds []string
+	FixVersion       string
+	Fixed            bool
+	// The reason the vulnerability isn't fixed
+	UnfixedReason string
+}
+
+func (hr *HtmlReport) countFixed() (fixed int) {
+	for _, row := range hr.Rows {
+		if row.Fixed {
+			fixed++
+		}
+	}
+	return
+}
+
+// Returns the number of vulnerabilities of each severity, from the most severe
+func (hr *HtmlReport) severityCounts() (counts []htmlReportSeverityCount) {
+	for _, severity := range []severityutils.Severity{severityutils.Critical, severityutils.High, severityutils.Medium, severityutils.Low, severityutils.Unknown} {
+		count := 0
+		for _, row := range hr.Rows {
+			if severityutils.GetSeverity(row.Severity) == severity {
+				count++
+			}
+		}
+		if count > 0 {
+			counts = append(counts, htmlReportSeverityCount{Severity: severity.String(), Count: count})
+		}
+	}
+	return
+}
+
+type htmlReportSeverityCount struct {
+	Severity string
+	Count    int
+}
+
+// The rank the severity column is sorted by, as the severity names aren't ordered alphabetically
+func getSeverityRank(severity string) int {
+	return severityutils.GetSeverityPriority(severityutils.GetSeverity(severity), jasutils.Applicable)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"severityRank": getSeverityRank}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.6em; }
+.details, .counts { color: #57606a; margin-bottom: 1em; }
+.counts span { display: inline-block; margin-right: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th:after { content: " \2195"; color: #8c959f; }
+tr:nth-child(even) td { background: #fafbfc; }
+.severity-Critical { color: #a40e26; font-weight: bold; }
+.severity-High { color: #d1242f; font-weight: bold; }
+.severity-Medium { color: #bc4c00; }
+.severity-Low { color: #9a6700; }
+.fixed { color: #1a7f37; }
+.unfixed { color: #57606a; }
+</style>
+</head>
+<body>
+<h1>🐸 {{.Title}}</h1>
+<div class="details">{{.Report.Repository}}{{if .Report.Branch}} · {{.Report.Branch}}{{end}} · Generated {{.Report.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}{{if .Report.DryRun}} · Dry run{{end}}</div>
+<div class="counts">
+<span><strong>Vulnerabilities:</strong> {{len .Report.Rows}}</span>
+<span><strong>Fixed:</strong> {{.Fixed}}</span>
+{{- range .SeverityCounts}}
+<span class="severity-{{.Severity}}"><strong>{{.Severity}}:</strong> {{.Count}}</span>
+{{- end}}
+</div>
+{{- if .Report.Rows}}
+<table id="vulnerabilities">
+<thead>
+<tr><th>Package</th><th>Version</th><th>Severity</th><th>Applicable</th><th>Vulnerabilities</th><th>Fix Version</th><th>Status</th></tr>
+</thead>
+<tbody>
+{{- range .Report.Rows}}
+<tr>
+<td>{{.PackageName}}</td>
+<td>{{.PackageVersion}}</td>
+<td class="severity-{{.Severity}}" data-sort="{{severityRank .Severity}}">{{.Severity}}</td>
+<td>{{.Applicable}}</td>
+<td>{{range $i, $id := .VulnerabilityIds}}{{if $i}}, {{end}}{{$id}}{{end}}</td>
+<td>{{.FixVersion}}</td>
+{{- if .Fixed}}
+<td class="fixed">✅ Fixed</td>
+{{- else}}
+<td class="unfixed">Not fixed: {{.UnfixedReason}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No vulnerabilities were detected.</p>
+{{- end}}
+<script>
+document.querySelectorAll("#vulnerabilities th").forEach(function (header, column) {
+  header.addEventListener("click", function () {
+    var body = header.closest("table").tBodies[0];
+    var ascending = header.dataset.order !== "asc";
+    header.dataset.order = ascending ? "asc" : "desc";
+    var cellValue = function (row) {
+      var cell = row.cells[column];
+      return cell.dataset.sort !== undefined ? Number(cell.dataset.sort) : cell.textContent.trim().toLowerCase();
+    };
+    Array.from(body.rows).sort(function (first, second) {
+      var a = cellValue(first), b = cellValue(second);
+      return (a < b ? -1 : a > b ? 1 : 0) * (ascending ? 1 : -1);
+    }).forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// RenderHtmlReport renders the report into a self-contained HTML document, with inline styles and scripts.
+func RenderHtmlReport(report *HtmlReport) (string, error) {
+	var content bytes.Buffer
+	err := htmlReportTemplate.Execute(&content, struct {
+		Title          string
+		Report         *HtmlReport
+		Fixed          int
+		SeverityCounts []htmlReportSeverityCount
+	}{Title: htmlReportTitle, Report: report, Fixed: report.countFixed(), SeverityCounts: report.severityCounts()})
+	return content.String(), err
+}
+
+func WriteHtmlReport(report *HtmlReport, reportPath string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the HTML report at %s: %s", reportPath, err.Error())
+	}
+	content, err := RenderHtmlReport(report)
+	if err != nil {
+		return fmt.Errorf("failed to write the HTML report at %s: %s", reportPath, err.Error())
+	}
+	if err = os.WriteFile(filepath.Clean(reportPath), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write the HTML report at %s: %s", reportPath, err.Error())
+	}
+	log.Info("The HTML report was written to", reportPath)
+	return
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+)
+
+const dependencyPathSeparator = ">"
+
+// IgnoreRule ignores the vulnerabilities that are reached through matching dependency paths, such as the dependencies of a test harness.
+type IgnoreRule struct {
+	// A pattern of consecutive dependencies in the dependency path, separated by '>', such as jest or jest > *.
+	// Each dependency is matched by its name or by <name>:<version>, and may contain wildcards.
+	DependencyPath string `yaml:"dependencyPath,omitempty"`
+	Reason         string `yaml:"reason,omitempty"`
+}
+
+func (ir *IgnoreRule) validate() error {
+	if strings.TrimSpace(ir.DependencyPath) == "" {
+		return fmt.Errorf("the dependency path of the ignore rule '%s' is empty", ir.Reason)
+	}
+	for _, dependencyPattern := range ir.dependencyPatterns() {
+		if _, err := path.Match(dependencyPattern, ""); err != nil {
+			return fmt.Errorf("invalid dependency path pattern '%s' in the ignore rules: %s", ir.DependencyPath, err.Error())
+		}
+	}
+	return nil
+}
+
+func (ir *IgnoreRule) dependencyPatterns() (patterns []string) {
+	for _, dependencyPattern := range strings.Split(ir.DependencyPath, dependencyPathSeparator) {
+		patterns = append(patterns, strings.TrimSpace(dependencyPattern))
+	}
+	return
+}
+
+// Returns true if the pattern of the rule matches consecutive dependencies anywhere in the impact path
+func (ir *IgnoreRule) matchesImpactPath(impactPath []formats.ComponentRow) bool {
+	patterns := ir.dependencyPatterns()
+	for start := 0; start+len(patterns) <= len(impactPath); start++ {
+		matched := true
+		for i, dependencyPattern := range patterns {
+			if !isDependencyMatch(dependencyPattern, impactPath[start+i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func isDependencyMatch(dependencyPattern string, dependency formats.ComponentRow) bool {
+	// The patterns are validated when the rules are loaded
+	if matched, _ := path.Match(dependencyPattern, dependency.Name); matched {
+		return true
+	}
+	matched, _ := path.Match(dependencyPattern, dependency.Name+":"+dependency.Version)
+	return matched
+}
+
+// GetIgnoringRule returns the rule that ignores the vulnerability, or nil if it should be fixed.
+// A vulnerability is ignored only if every one of its impact paths is matched by a rule, so it's still fixed if a single path reaches it through other dependencies.
+func GetIgnoringRule(ignoreRules []IgnoreRule, impactPaths [][]formats.ComponentRow) *IgnoreRule {
+	if len(ignoreRules) == 0 || len(impactPaths) == 0 {
+		return nil
+	}
+	var ignoringRule *IgnoreRule
+	for _, impactPath := range impactPaths {
+		pathRule := getImpactPathRule(ignoreRules, impactPath)
+		if pathRule == nil {
+			return nil
+		}
+		if ignoringRule == nil {
+			ignoringRule = pathRule
+		}
+	}
+	return ignoringRule
+}
+
+func getImpactPathRule(ignoreRules []IgnoreRule, impactPath []formats.ComponentRow) *IgnoreRule {
+	for i := range ignoreRules {
+		if ignoreRules[i].matchesImpactPath(impactPath) {
+			return &ignoreRules[i]
+		}
+	}
+	return nil
+}
+
+// Reads the ignore rules from the environment, as dependency path patterns separated by commas
+func readIgnoreRulesFromEnv() (ignoreRules []IgnoreRule, err error) {
+	dependencyPaths, err := readArrayParamFromEnv(IgnoredDependencyPathsEnv, ",")
+	if err != nil {
+		return
+	}
+	for _, dependencyPath := range dependencyPaths {
+		ignoreRules = append(ignoreRules, IgnoreRule{DependencyPath: dependencyPath})
+	}
+	return
+}
@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	xrayUtils "github.com/jfrog/jfrog-client-go/xray/services/utils"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The ID of the root of the dependency trees, when the SBOM doesn't describe the project itself
+const sbomRootComponentId = "root"
+
+// The technologies and the Xray component ID prefixes of the package URL types that can be fixed
+var sbomPurlTypes = map[string]struct {
+	tech       techutils.Technology
+	xrayPrefix string
+}{
+	"npm":    {techutils.Npm, "npm://"},
+	"pypi":   {techutils.Pip, "pypi://"},
+	"maven":  {techutils.Maven, "gav://"},
+	"golang": {techutils.Go, "go://"},
+	"nuget":  {techutils.Nuget, "nuget://"},
+}
+
+// The components of an SBOM and the dependencies between them, identified by their references in the SBOM
+type sbomGraph struct {
+	// The reference of the described project, if the SBOM describes it
+	root         string
+	components   map[string]string
+	technologies map[string]techutils.Technology
+	dependencies map[string][]string
+}
+
+// LoadSbomDependencyTrees reads a CycloneDX or SPDX JSON SBOM and returns the dependency tree of each technology in it.
+// The direct dependencies are the ones the described project depends on. If the SBOM has no dependencies, all its components are considered direct.
+// Components whose package URLs can't be fixed by Frogbot are left out.
+func LoadSbomDependencyTrees(sbomPath string) (dependencyTrees map[techutils.Technology]*xrayUtils.GraphNode, err error) {
+	content, err := os.ReadFile(filepath.Clean(sbomPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SBOM at %s: %s", sbomPath, err.Error())
+	}
+	var format struct {
+		BomFormat   string `json:"bomFormat"`
+		SpdxVersion string `json:"spdxVersion"`
+	}
+	if err = json.Unmarshal(content, &format); err != nil {
+		return nil, fmt.Errorf("failed to parse the SBOM at %s. Only JSON SBOMs are supported: %s", sbomPath, err.Error())
+	}
+	var graph *sbomGraph
+	switch {
+	case format.BomFormat == "CycloneDX":
+		graph, err = parseCycloneDxSbom(content)
+	case format.SpdxVersion != "":
+		graph, err = parseSpdxSbom(content)
+	default:
+		err = errors.New("the format isn't CycloneDX or SPDX")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the SBOM at %s: %s", sbomPath, err.Error())
+	}
+	return graph.createDependencyTrees(), nil
+}
+
+func parseCycloneDxSbom(content []byte) (graph *sbomGraph, err error) {
+	bom := cdx.NewBOM()
+	if err = json.Unmarshal(content, bom); err != nil {
+		return
+	}
+	graph = newSbomGraph()
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		graph.root = bom.Metadata.Component.BOMRef
+		graph.addComponent(graph.root, bom.Metadata.Component.PackageURL)
+	}
+	if bom.Components != nil {
+		for _, component := range *bom.Components {
+			graph.addComponent(component.BOMRef, component.PackageURL)
+		}
+	}
+	if bom.Dependencies != nil {
+		for _, dependency := range *bom.Dependencies {
+			if dependency.Dependencies != nil {
+				graph.dependencies[dependency.Ref] = append(graph.dependencies[dependency.Ref], *dependency.Dependencies...)
+			}
+		}
+	}
+	return
+}
+
+// The fields of an SPDX JSON document that describe its packages and the dependencies between them
+type spdxDocument struct {
+	DocumentDescribes []string `json:"documentDescribes"`
+	Packages          []struct {
+		SpdxId       string `json:"SPDXID"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+	Relationships []struct {
+		SpdxElementId      string `json:"spdxElementId"`
+		RelatedSpdxElement string `json:"relatedSpdxElement"`
+		RelationshipType   string `json:"relationshipType"`
+	} `json:"relationships"`
+}
+
+func parseSpdxSbom(content []byte) (graph *sbomGraph, err error) {
+	var document spdxDocument
+	if err = json.Unmarshal(content, &document); err != nil {
+		return
+	}
+	graph = newSbomGraph()
+	if len(document.DocumentDescribes) > 0 {
+		graph.root = document.DocumentDescribes[0]
+	}
+	for _, spdxPackage := range document.Packages {
+		var purl string
+		for _, externalRef := range spdxPackage.ExternalRefs {
+			if externalRef.ReferenceType == "purl" {
+				purl = externalRef.ReferenceLocator
+			}
+		}
+		graph.addComponent(spdxPackage.SpdxId, purl)
+	}
+	for _, relationship := range document.Relationships {
+		switch relationship.RelationshipType {
+		case "DESCRIBES":
+			if graph.root == "" {
+				graph.root = relationship.RelatedSpdxElement
+			}
+		case "DEPENDS_ON":
+			graph.dependencies[relationship.SpdxElementId] = append(graph.dependencies[relationship.SpdxElementId], relationship.RelatedSpdxElement)
+		case "DEPENDENCY_OF":
+			graph.dependencies[relationship.RelatedSpdxElement] = append(graph.dependencies[relationship.RelatedSpdxElement], relationship.SpdxElementId)
+		}
+	}
+	return
+}
+
+func newSbomGraph() *sbomGraph {
+	return &sbomGraph{components: map[string]string{}, technologies: map[string]techutils.Technology{}, dependencies: map[string][]string{}}
+}
+
+func (sg *sbomGraph) addComponent(ref, purl string) {
+	if ref == "" || purl == "" {
+		return
+	}
+	tech, componentId, err := purlToXrayComponentId(purl)
+	if err != nil {
+		log.Debug(fmt.Sprintf("Skipping the SBOM component %s: %s", purl, err.Error()))
+		return
+	}
+	sg.components[ref], sg.technologies[ref] = componentId, tech
+}
+
+func (sg *sbomGraph) createDependencyTrees() map[techutils.Technology]*xrayUtils.GraphNode {
+	rootId := sbomRootComponentId
+	if componentId, exists := sg.components[sg.root]; exists {
+		rootId = componentId
+	}
+	dependencyTrees := map[techutils.Technology]*xrayUtils.GraphNode{}
+	for _, ref := range sg.getDirectDependencies() {
+		tech, exists := sg.technologies[ref]
+		if !exists || ref == sg.root {
+			continue
+		}
+		if _, exists = dependencyTrees[tech]; !exists {
+			dependencyTrees[tech] = &xrayUtils.GraphNode{Id: rootId}
+		}
+		sg.addDependencyNode(dependencyTrees[tech], ref)
+	}
+	return dependencyTrees
+}
+
+// Returns the dependencies of the described project, or the components no other component depends on if the project's dependencies are unknown
+func (sg *sbomGraph) getDirectDependencies() (directDependencies []string) {
+	if directDependencies = sg.dependencies[sg.root]; len(directDependencies) > 0 {
+		return
+	}
+	transitiveDependencies := map[string]bool{}
+	for _, dependencies := range sg.dependencies {
+		for _, dependency := range dependencies {
+			transitiveDependencies[dependency] = true
+		}
+	}
+	refs := maps.Keys(sg.components)
+	slices.Sort(refs)
+	for _, ref := range refs {
+		if !transitiveDependencies[ref] {
+			directDependencies = append(directDependencies, ref)
+		}
+	}
+	return
+}
+
+func (sg *sbomGraph) addDependencyNode(parent *xrayUtils.GraphNode, ref string) {
+	componentId, exists := sg.components[ref]
+	if !exists {
+		return
+	}
+	node := &xrayUtils.GraphNode{Id: componentId, Parent: parent}
+	// Dependency cycles are cut where the component already appears in the path
+	if node.NodeHasLoop() {
+		return
+	}
+	parent.Nodes = append(parent.Nodes, node)
+	for _, dependency := range sg.dependencies[ref] {
+		sg.addDependencyNode(node, dependency)
+	}
+}
+
+// Returns the technology and the Xray component ID of the package URL, such as npm://minimist:1.2.5 for pkg:npm/minimist@1.2.5
+func purlToXrayComponentId(purl string) (tech techutils.Technology, componentId string, err error) {
+	purlPath, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return "", "", errors.New("the package URL must start with 'pkg:'")
+	}
+	purlPath, _, _ = strings.Cut(purlPath, "#")
+	purlPath, _, _ = strings.Cut(purlPath, "?")
+	purlType, namespaceAndName, _ := strings.Cut(purlPath, "/")
+	purlTypeDetails, supported := sbomPurlTypes[strings.ToLower(purlType)]
+	if !supported {
+		return "", "", fmt.Errorf("the package URL type '%s' isn't supported", purlType)
+	}
+	versionIndex := strings.LastIndex(namespaceAndName, "@")
+	if versionIndex <= 0 {
+		return "", "", errors.New("the package URL has no version")
+	}
+	name, version := namespaceAndName[:versionIndex], namespaceAndName[versionIndex+1:]
+	if name, err = url.PathUnescape(name); err != nil {
+		return
+	}
+	if version, err = url.PathUnescape(version); err != nil {
+		return
+	}
+	if purlTypeDetails.tech == techutils.Maven {
+		// Maven components are identified by <group>:<artifact>
+		name = strings.Replace(name, "/", ":", 1)
+	}
+	return purlTypeDetails.tech, purlTypeDetails.xrayPrefix + name + ":" + version, nil
+}
+
+// FlattenDependencyTree returns a tree whose root depends directly on every unique component of the given tree, as Xray scans the dependency graph.
+func FlattenDependencyTree(dependencyTree *xrayUtils.GraphNode) *xrayUtils.GraphNode {
+	flatTree := &xrayUtils.GraphNode{Id: dependencyTree.Id}
+	componentIds := map[string]bool{}
+	var addNodes func(node *xrayUtils.GraphNode)
+	addNodes = func(node *xrayUtils.GraphNode) {
+		for _, child := range node.Nodes {
+			if !componentIds[child.Id] {
+				componentIds[child.Id] = true
+				flatTree.Nodes = append(flatTree.Nodes, &xrayUtils.GraphNode{Id: child.Id})
+			}
+			addNodes(child)
+		}
+	}
+	addNodes(dependencyTree)
+	return flatTree
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/commands/audit/sca"
+	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	xrayUtils "github.com/jfrog/jfrog-client-go/xray/services/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCycloneDxSbom = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"bom-ref": "app", "type": "application", "name": "my-app", "version": "1.0.0", "purl": "pkg:npm/my-app@1.0.0"}},
+  "components": [
+    {"bom-ref": "express", "type": "library", "name": "express", "version": "4.17.0", "purl": "pkg:npm/express@4.17.0"},
+    {"bom-ref": "qs", "type": "library", "name": "qs", "version": "6.5.2", "purl": "pkg:npm/qs@6.5.2"},
+    {"bom-ref": "types-node", "type": "library", "name": "@types/node", "version": "18.0.0", "purl": "pkg:npm/%40types/node@18.0.0"},
+    {"bom-ref": "snakeyaml", "type": "library", "name": "snakeyaml", "version": "1.33", "purl": "pkg:maven/org.yaml/snakeyaml@1.33?type=jar"},
+    {"bom-ref": "libc", "type": "library", "name": "libc", "version": "2.31", "purl": "pkg:deb/debian/libc@2.31"}
+  ],
+  "dependencies": [
+    {"ref": "app", "dependsOn": ["express", "types-node", "snakeyaml", "libc"]},
+    {"ref": "express", "dependsOn": ["qs"]}
+  ]
+}`
+
+const testSpdxSbom = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "packages": [
+    {"SPDXID": "SPDXRef-app", "name": "my-app"},
+    {"SPDXID": "SPDXRef-gin", "name": "gin", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/github.com/gin-gonic/gin@v1.9.0"}]},
+    {"SPDXID": "SPDXRef-net", "name": "net", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/golang.org/x/net@v0.7.0"}]}
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-app"},
+    {"spdxElementId": "SPDXRef-app", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-gin"},
+    {"spdxElementId": "SPDXRef-net", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-gin"}
+  ]
+}`
+
+func TestLoadSbomDependencyTrees(t *testing.T) {
+	cycloneDxTrees := loadTestSbom(t, testCycloneDxSbom)
+	assert.Len(t, cycloneDxTrees, 2)
+	assert.Equal(t, "npm://my-app:1.0.0", cycloneDxTrees[techutils.Npm].Id)
+	assert.Equal(t, []string{"npm://express:4.17.0", "npm://@types/node:18.0.0"}, getNodeIds(cycloneDxTrees[techutils.Npm].Nodes))
+	assert.Equal(t, []string{"npm://qs:6.5.2"}, getNodeIds(cycloneDxTrees[techutils.Npm].Nodes[0].Nodes))
+	assert.Equal(t, []string{"gav://org.yaml:snakeyaml:1.33"}, getNodeIds(cycloneDxTrees[techutils.Maven].Nodes))
+	assert.Equal(t, []string{"npm://express:4.17.0", "npm://qs:6.5.2", "npm://@types/node:18.0.0"}, getNodeIds(FlattenDependencyTree(cycloneDxTrees[techutils.Npm]).Nodes))
+
+	// The described package has no package URL, so the tree has a generic root
+	spdxTrees := loadTestSbom(t, testSpdxSbom)
+	assert.Len(t, spdxTrees, 1)
+	assert.Equal(t, sbomRootComponentId, spdxTrees[techutils.Go].Id)
+	assert.Equal(t, []string{"go://github.com/gin-gonic/gin:v1.9.0"}, getNodeIds(spdxTrees[techutils.Go].Nodes))
+	assert.Equal(t, []string{"go://golang.org/x/net:v0.7.0"}, getNodeIds(spdxTrees[techutils.Go].Nodes[0].Nodes))
+
+	// Without dependencies, all the components are direct dependencies
+	flatTrees := loadTestSbom(t, `{"bomFormat": "CycloneDX", "components": [{"bom-ref": "qs", "purl": "pkg:npm/qs@6.5.2"}, {"bom-ref": "express", "purl": "pkg:npm/express@4.17.0"}]}`)
+	assert.Equal(t, []string{"npm://express:4.17.0", "npm://qs:6.5.2"}, getNodeIds(flatTrees[techutils.Npm].Nodes))
+
+	_, err := LoadSbomDependencyTrees(writeTestSbom(t, `{"name": "not an SBOM"}`))
+	assert.ErrorContains(t, err, "the format isn't CycloneDX or SPDX")
+}
+
+func TestSbomScanImpactPaths(t *testing.T) {
+	npmTree := loadTestSbom(t, testCycloneDxSbom)[techutils.Npm]
+	// The vulnerable components, as returned by the Xray scan of the flat tree
+	scanResponses := sca.BuildImpactPathsForScanResponse([]services.ScanResponse{{
+		Vulnerabilities: []services.Vulnerability{
+			{IssueId: "XRAY-1", Severity: "High", Components: map[string]services.Component{"npm://express:4.17.0": {FixedVersions: []string{"[4.17.3]"}}}},
+			{IssueId: "XRAY-2", Severity: "High", Components: map[string]services.Component{"npm://qs:6.5.2": {FixedVersions: []string{"[6.5.3]"}}}},
+		},
+	}}, []*xrayUtils.GraphNode{npmTree})
+	results := &xrayutils.Results{ScaResults: []*xrayutils.ScaScanResult{{Technology: techutils.Npm, XrayResults: scanResponses}}, ExtendedScanResults: &xrayutils.ExtendedScanResults{}}
+	vulnerabilities, err := xrayutils.PrepareVulnerabilities(scanResponses[0].Vulnerabilities, results, false, true)
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 2)
+
+	// A component the project depends on directly is fixed in the manifest, while a transitive-only component is reported
+	for _, vulnerability := range vulnerabilities {
+		isDirectDependency, err := IsDirectDependency(vulnerability.ImpactPaths)
+		require.NoError(t, err)
+		assert.Equal(t, vulnerability.ImpactedDependencyName == "express", isDirectDependency, vulnerability.ImpactedDependencyName)
+	}
+}
+
+func loadTestSbom(t *testing.T, content string) map[techutils.Technology]*xrayUtils.GraphNode {
+	dependencyTrees, err := LoadSbomDependencyTrees(writeTestSbom(t, content))
+	require.NoError(t, err)
+	return dependencyTrees
+}
+
+func writeTestSbom(t *testing.T, content string) string {
+	sbomPath := filepath.Join(t.TempDir(), "sbom.json")
+	require.NoError(t, os.WriteFile(sbomPath, []byte(content), 0600))
+	return sbomPath
+}
+
+func getNodeIds(nodes []*xrayUtils.GraphNode) (ids []string) {
+	for _, node := range nodes {
+		ids = append(ids, node.Id)
+	}
+	return
+}
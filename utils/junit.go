@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const junitReportName = "Frogbot"
+
+// JunitTestSuites is the root element of a JUnit XML report
+type JunitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []JunitTestSuite `xml:"testsuite"`
+}
+
+type JunitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []JunitTestCase `xml:"testcase"`
+}
+
+// JunitTestCase passes unless it has a failure or is skipped
+type JunitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JunitFailure `xml:"failure,omitempty"`
+	Skipped   *JunitSkipped `xml:"skipped,omitempty"`
+}
+
+type JunitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+type JunitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func NewJunitReport() *JunitTestSuites {
+	return &JunitTestSuites{Name: junitReportName}
+}
+
+// AddTestCase adds the test case to the suite with the given name, which is created if it doesn't exist yet.
+func (jts *JunitTestSuites) AddTestCase(suiteName string, testCase JunitTestCase) {
+	suiteIndex := -1
+	for i := range jts.TestSuites {
+		if jts.TestSuites[i].Name == suiteName {
+			suiteIndex = i
+			break
+		}
+	}
+	if suiteIndex == -1 {
+		jts.TestSuites = append(jts.TestSuites, JunitTestSuite{Name: suiteName})
+		suiteIndex = len(jts.TestSuites) - 1
+	}
+	testSuite := &jts.TestSuites[suiteIndex]
+	testCase.ClassName = suiteName
+	testSuite.TestCases = append(testSuite.TestCases, testCase)
+	testSuite.Tests++
+	jts.Tests++
+	switch {
+	case testCase.Failure != nil:
+		testSuite.Failures++
+		jts.Failures++
+	case testCase.Skipped != nil:
+		testSuite.Skipped++
+		jts.Skipped++
+	}
+}
+
+func WriteJunitReport(report *JunitTestSuites, reportPath string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the JUnit report at %s: %s", reportPath, err.Error())
+	}
+	content, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write the JUnit report at %s: %s", reportPath, err.Error())
+	}
+	if err = os.WriteFile(filepath.Clean(reportPath), append([]byte(xml.Header), content...), 0644); err != nil {
+		return fmt.Errorf("failed to write the JUnit report at %s: %s", reportPath, err.Error())
+	}
+	log.Info("The JUnit report was written to", reportPath)
+	return
+}
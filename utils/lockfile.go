@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	pipenvLockfileName = "Pipfile.lock"
+	poetryLockfileName = "poetry.lock"
+)
+
+// Matches the characters Python treats as equivalent in package names
+var pythonPackageNameSeparatorsRegex = regexp.MustCompile(`[-_.]+`)
+
+// GetLockfileVersions returns the versions of the package resolved in the lockfile of the working directory.
+// The lockfile of npm, yarn and pnpm workspaces may reside in a parent directory, up to the base working directory.
+// An empty list is returned if the technology has no supported lockfile, or if the lockfile doesn't list the package.
+func GetLockfileVersions(tech techutils.Technology, packageName, fullPathWd, baseWd string) (versions []string, err error) {
+	var lockfilePath string
+	switch tech {
+	case techutils.Npm, techutils.Yarn, techutils.Pnpm:
+		if lockfilePath, err = GetSharedLockfilePath(fullPathWd, baseWd); err != nil || lockfilePath == "" {
+			return
+		}
+	case techutils.Pipenv:
+		lockfilePath = filepath.Join(fullPathWd, pipenvLockfileName)
+	case techutils.Poetry:
+		lockfilePath = filepath.Join(fullPathWd, poetryLockfileName)
+	default:
+		return
+	}
+	exists, err := fileutils.IsFileExists(lockfilePath, false)
+	if err != nil || !exists {
+		return
+	}
+	content, err := os.ReadFile(filepath.Clean(lockfilePath))
+	if err != nil {
+		return
+	}
+	switch filepath.Base(lockfilePath) {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		versions, err = getNpmLockfileVersions(content, packageName)
+	case "yarn.lock":
+		versions = getYarnLockfileVersions(content, packageName)
+	case "pnpm-lock.yaml":
+		versions = getPnpmLockfileVersions(content, packageName)
+	case pipenvLockfileName:
+		versions, err = getPipenvLockfileVersions(content, packageName)
+	case poetryLockfileName:
+		versions = getPoetryLockfileVersions(content, packageName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the lockfile at %s: %s", lockfilePath, err.Error())
+	}
+	return
+}
+
+type npmLockfile struct {
+	// Lockfile version 2 and above, by the path of the installed package
+	Packages map[string]npmLockfileInstalledPackage `json:"packages,omitempty"`
+	// Lockfile version 1, by package name
+	Dependencies map[string]npmLockfilePackage `json:"dependencies,omitempty"`
+}
+
+type npmLockfileInstalledPackage struct {
+	Version string `json:"version,omitempty"`
+}
+
+type npmLockfilePackage struct {
+	Version      string                        `json:"version,omitempty"`
+	Dependencies map[string]npmLockfilePackage `json:"dependencies,omitempty"`
+}
+
+func getNpmLockfileVersions(content []byte, packageName string) (versions []string, err error) {
+	var lockfile npmLockfile
+	if err = json.Unmarshal(content, &lockfile); err != nil {
+		return
+	}
+	if len(lockfile.Packages) > 0 {
+		for installPath, lockfilePackage := range lockfile.Packages {
+			if index := strings.LastIndex(installPath, "node_modules/"); index >= 0 && installPath[index+len("node_modules/"):] == packageName {
+				versions = appendVersion(versions, lockfilePackage.Version)
+			}
+		}
+		return
+	}
+	return getNpmLockfileV1Versions(lockfile.Dependencies, packageName, versions), nil
+}
+
+// The dependencies of lockfile version 1 are nested under the packages that install them in their own node_modules
+func getNpmLockfileV1Versions(dependencies map[string]npmLockfilePackage, packageName string, versions []string) []string {
+	for name, lockfilePackage := range dependencies {
+		if name == packageName {
+			versions = appendVersion(versions, lockfilePackage.Version)
+		}
+		versions = getNpmLockfileV1Versions(lockfilePackage.Dependencies, packageName, versions)
+	}
+	return versions
+}
+
+// Every entry of yarn.lock is headed by the descriptors it resolves (e.g. "minimist@^1.2.0", minimist@^1.2.5:), followed by its indented fields.
+// The version field is written as 'version "1.2.6"' in yarn 1, and as 'version: 1.2.6' in later versions.
+func getYarnLockfileVersions(content []byte, packageName string) (versions []string) {
+	var inPackageEntry bool
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inPackageEntry = isYarnEntryOfPackage(strings.TrimSuffix(line, ":"), packageName)
+			continue
+		}
+		if !inPackageEntry {
+			continue
+		}
+		if field := strings.TrimSpace(line); strings.HasPrefix(field, "version") {
+			versions = appendVersion(versions, strings.Trim(strings.TrimPrefix(field, "version"), `:" `))
+		}
+	}
+	return
+}
+
+func isYarnEntryOfPackage(entryHeader, packageName string) bool {
+	for _, descriptor := range strings.Split(entryHeader, ",") {
+		descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+		// The name of a scoped package starts with '@', so the range is separated by the last '@'
+		if index := strings.LastIndex(descriptor, "@"); index > 0 && descriptor[:index] == packageName {
+			return true
+		}
+	}
+	return false
+}
+
+// The packages of pnpm-lock.yaml are keyed by their name and version: /minimist/1.2.6 in lockfile version 5, /minimist@1.2.6 in version 6,
+// and minimist@1.2.6 in version 9. The version may be followed by the resolved peer dependencies, such as 1.2.6(react@18.2.0).
+func getPnpmLockfileVersions(content []byte, packageName string) (versions []string) {
+	var inPackages bool
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inPackages = strings.TrimSpace(line) == "packages:"
+			continue
+		}
+		// The package keys are indented once under the packages section
+		if !inPackages || !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") {
+			continue
+		}
+		packageKey := strings.TrimPrefix(strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `'"`), "/")
+		packageKey, _, _ = strings.Cut(packageKey, "(")
+		for _, separator := range []string{"@", "/"} {
+			if packageVersion, found := strings.CutPrefix(packageKey, packageName+separator); found {
+				versions = appendVersion(versions, packageVersion)
+				break
+			}
+		}
+	}
+	return
+}
+
+type pipenvLockfile struct {
+	Default map[string]pipenvLockfilePackage `json:"default,omitempty"`
+	Develop map[string]pipenvLockfilePackage `json:"develop,omitempty"`
+}
+
+type pipenvLockfilePackage struct {
+	// The pinned version, such as ==1.2.6
+	Version string `json:"version,omitempty"`
+}
+
+func getPipenvLockfileVersions(content []byte, packageName string) (versions []string, err error) {
+	var lockfile pipenvLockfile
+	if err = json.Unmarshal(content, &lockfile); err != nil {
+		return
+	}
+	for _, lockfilePackages := range []map[string]pipenvLockfilePackage{lockfile.Default, lockfile.Develop} {
+		for name, lockfilePackage := range lockfilePackages {
+			if normalizePythonPackageName(name) == normalizePythonPackageName(packageName) {
+				versions = appendVersion(versions, strings.TrimPrefix(lockfilePackage.Version, "=="))
+			}
+		}
+	}
+	return
+}
+
+// Every package of poetry.lock is a [[package]] table, listing its name and version.
+func getPoetryLockfileVersions(content []byte, packageName string) (versions []string) {
+	var name, packageVersion string
+	var inPackage bool
+	addPackageVersion := func() {
+		if inPackage && normalizePythonPackageName(name) == normalizePythonPackageName(packageName) {
+			versions = appendVersion(versions, packageVersion)
+		}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			addPackageVersion()
+			inPackage, name, packageVersion = line == "[[package]]", "", ""
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !inPackage || !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "name":
+			name = strings.Trim(strings.TrimSpace(value), `"`)
+		case "version":
+			packageVersion = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	addPackageVersion()
+	return
+}
+
+func normalizePythonPackageName(packageName string) string {
+	return strings.ToLower(pythonPackageNameSeparatorsRegex.ReplaceAllString(packageName, "-"))
+}
+
+func appendVersion(versions []string, packageVersion string) []string {
+	if packageVersion == "" || slices.Contains(versions, packageVersion) {
+		return versions
+	}
+	return append(versions, packageVersion)
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLockfileVersions(t *testing.T) {
+	testCases := []struct {
+		name             string
+		tech             techutils.Technology
+		lockfileName     string
+		lockfileContent  string
+		packageName      string
+		expectedVersions []string
+	}{
+		{
+			name:         "npm lockfile version 3",
+			tech:         techutils.Npm,
+			lockfileName: "package-lock.json",
+			lockfileContent: `{"lockfileVersion": 3, "packages": {
+  "": {"dependencies": {"minimist": "^1.2.5"}},
+  "node_modules/minimist": {"version": "1.2.8"},
+  "node_modules/mkdirp/node_modules/minimist": {"version": "0.0.8"},
+  "node_modules/@types/minimist": {"version": "1.2.2"}
+}}`,
+			packageName:      "minimist",
+			expectedVersions: []string{"1.2.8", "0.0.8"},
+		},
+		{
+			name:         "npm lockfile version 1",
+			tech:         techutils.Npm,
+			lockfileName: "package-lock.json",
+			lockfileContent: `{"lockfileVersion": 1, "dependencies": {
+  "minimist": {"version": "1.2.8"},
+  "mkdirp": {"version": "0.5.1", "requires": {"minimist": "0.0.8"}, "dependencies": {"minimist": {"version": "0.0.8"}}}
+}}`,
+			packageName:      "minimist",
+			expectedVersions: []string{"1.2.8", "0.0.8"},
+		},
+		{
+			name:         "yarn 1 lockfile",
+			tech:         techutils.Yarn,
+			lockfileName: "yarn.lock",
+			lockfileContent: `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@types/minimist@^1.2.0":
+  version "1.2.2"
+
+minimist@^1.2.0, minimist@^1.2.5:
+  version "1.2.8"
+  resolved "https://registry.yarnpkg.com/minimist/-/minimist-1.2.8.tgz"
+`,
+			packageName:      "minimist",
+			expectedVersions: []string{"1.2.8"},
+		},
+		{
+			name:         "yarn 2 lockfile with a scoped package",
+			tech:         techutils.Yarn,
+			lockfileName: "yarn.lock",
+			lockfileContent: `__metadata:
+  version: 6
+
+"@types/minimist@npm:^1.2.0":
+  version: 1.2.2
+  resolution: "@types/minimist@npm:1.2.2"
+
+"minimist@npm:^1.2.5":
+  version: 1.2.8
+`,
+			packageName:      "@types/minimist",
+			expectedVersions: []string{"1.2.2"},
+		},
+		{
+			name:         "pnpm lockfile version 6",
+			tech:         techutils.Pnpm,
+			lockfileName: "pnpm-lock.yaml",
+			lockfileContent: `lockfileVersion: '6.0'
+
+dependencies:
+  minimist:
+    specifier: ^1.2.5
+    version: 1.2.8
+
+packages:
+
+  /minimist@1.2.8:
+    resolution: {integrity: sha512-abc}
+    dev: false
+
+  /minimist-options@4.1.0:
+    resolution: {integrity: sha512-def}
+`,
+			packageName:      "minimist",
+			expectedVersions: []string{"1.2.8"},
+		},
+		{
+			name:         "pnpm lockfile version 5",
+			tech:         techutils.Pnpm,
+			lockfileName: "pnpm-lock.yaml",
+			lockfileContent: `lockfileVersion: 5.4
+
+packages:
+
+  /@types/minimist/1.2.2:
+    resolution: {integrity: sha512-abc}
+`,
+			packageName:      "@types/minimist",
+			expectedVersions: []string{"1.2.2"},
+		},
+		{
+			name:             "pipenv lockfile",
+			tech:             techutils.Pipenv,
+			lockfileName:     "Pipfile.lock",
+			lockfileContent:  `{"default": {"pyjwt": {"version": "==2.4.0"}}, "develop": {"PyJWT": {"version": "==2.4.0"}}}`,
+			packageName:      "PyJWT",
+			expectedVersions: []string{"2.4.0"},
+		},
+		{
+			name:         "poetry lockfile",
+			tech:         techutils.Poetry,
+			lockfileName: "poetry.lock",
+			lockfileContent: `[[package]]
+name = "pyjwt"
+version = "2.4.0"
+description = "JSON Web Token implementation in Python"
+
+[package.extras]
+crypto = ["cryptography (>=3.3.1)"]
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+`,
+			packageName:      "PyJWT",
+			expectedVersions: []string{"2.4.0"},
+		},
+		{
+			name:            "package not in the lockfile",
+			tech:            techutils.Npm,
+			lockfileName:    "package-lock.json",
+			lockfileContent: `{"lockfileVersion": 3, "packages": {"node_modules/lodash": {"version": "4.17.21"}}}`,
+			packageName:     "minimist",
+		},
+		{
+			name:            "technology without a supported lockfile",
+			tech:            techutils.Maven,
+			lockfileName:    "pom.xml",
+			lockfileContent: `<project></project>`,
+			packageName:     "org.jfrog:frogbot",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			baseWd := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(baseWd, test.lockfileName), []byte(test.lockfileContent), 0644))
+			versions, err := GetLockfileVersions(test.tech, test.packageName, baseWd, baseWd)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.expectedVersions, versions)
+		})
+	}
+}
+
+func TestGetLockfileVersionsOfWorkspace(t *testing.T) {
+	// The lockfile of the workspace is in the root directory of the repository
+	baseWd := t.TempDir()
+	workingDir := filepath.Join(baseWd, "packages", "app")
+	require.NoError(t, os.MkdirAll(workingDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseWd, "package-lock.json"), []byte(`{"lockfileVersion": 3, "packages": {"node_modules/minimist": {"version": "1.2.8"}}}`), 0644))
+	versions, err := GetLockfileVersions(techutils.Npm, "minimist", workingDir, baseWd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.8"}, versions)
+
+	// A malformed lockfile is reported
+	require.NoError(t, os.WriteFile(filepath.Join(baseWd, "package-lock.json"), []byte(`{`), 0644))
+	_, err = GetLockfileVersions(techutils.Npm, "minimist", workingDir, baseWd)
+	assert.ErrorContains(t, err, "failed to parse the lockfile")
+}
@@ -0,0 +1,485 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	notificationTitle                = "🐸 Frogbot Run Summary"
+	notificationMaxListedPrs         = 10
+	notificationMaxListedVulns       = 10
+	notificationRequestTimeout       = 30 * time.Second
+	teamsAdaptiveCardContentType     = "application/vnd.microsoft.card.adaptive"
+	teamsAdaptiveCardSchema          = "http://adaptivecards.io/schemas/adaptive-card.json"
+	teamsAdaptiveCardSchemaVersion   = "1.4"
+	slackMarkdownTextType            = "mrkdwn"
+	slackPlainTextType               = "plain_text"
+	pullRequestOpenedNotificationOp  = "opened"
+	pullRequestUpdatedNotificationOp = "updated"
+)
+
+type NotificationsDetails struct {
+	SlackWebhook string `yaml:"-"`
+	TeamsWebhook string `yaml:"-"`
+	// The targets whose summary omits the details of the vulnerabilities, showing only the vulnerable packages and severity counts
+	RedactedSummaryTargets []string `yaml:"redactedSummaryTargets,omitempty"`
+	// The title of the wiki page whose Frogbot section is replaced with the run summary
+	UpdateWikiPage string `yaml:"updateWikiPage,omitempty"`
+}
+
+// IsRedacted returns true if the summary posted to the given target should be redacted
+func (nd NotificationsDetails) IsRedacted(target SummaryTarget) bool {
+	return slices.Contains(nd.RedactedSummaryTargets, string(target))
+}
+
+// RunSummary holds the pull requests Frogbot opened or updated during a run, to be posted to the configured channels.
+type RunSummary struct {
+	PullRequests []PullRequestSummary
+	// Issues that didn't fail the run, but require attention
+	Warnings []string
+	// The vulnerabilities detected in the scanned branches
+	Vulnerabilities []formats.VulnerabilityOrViolationRow
+	// The time left to remediate the vulnerabilities within their SLA, by vulnerability unique ID
+	SlaStatuses map[string]*SlaStatus
+	// The dependencies whose licenses aren't allowed, detected in the scanned branches
+	LicenseViolations []formats.LicenseRow
+	// The outcome of each repository scanned in a multi repository run
+	Repositories []RepositoryScanSummary
+}
+
+type RepositoryScanSummary struct {
+	Repository      string
+	PullRequests    int
+	Vulnerabilities int
+	// The error the scan of the repository failed with, empty if it succeeded
+	Error string
+}
+
+type PullRequestSummary struct {
+	Repository string
+	Title      string
+	URL        string
+	Updated    bool
+}
+
+func (rs *RunSummary) AddPullRequest(repository, title, url string, updated bool) {
+	rs.PullRequests = append(rs.PullRequests, PullRequestSummary{Repository: repository, Title: title, URL: url, Updated: updated})
+}
+
+func (rs *RunSummary) AddWarning(repository, warning string) {
+	rs.Warnings = append(rs.Warnings, fmt.Sprintf("%s: %s", repository, warning))
+}
+
+// AddRepository records the outcome of scanning the repository, with the pull requests and vulnerabilities added to the summary since its scan started.
+func (rs *RunSummary) AddRepository(repository string, pullRequests, vulnerabilities int, err error) {
+	repositorySummary := RepositoryScanSummary{Repository: repository, PullRequests: pullRequests, Vulnerabilities: vulnerabilities}
+	if err != nil {
+		repositorySummary.Error = err.Error()
+	}
+	rs.Repositories = append(rs.Repositories, repositorySummary)
+}
+
+func (rs *RunSummary) getFailedRepositories() (failed []RepositoryScanSummary) {
+	for _, repository := range rs.Repositories {
+		if repository.Error != "" {
+			failed = append(failed, repository)
+		}
+	}
+	return
+}
+
+// GetRepositoriesSummary returns a line for each repository scanned in a multi repository run, headed by the number of repositories scanned and failed
+func (rs *RunSummary) GetRepositoriesSummary() string {
+	lines := []string{getRepositoriesHeadline(rs)}
+	for _, repository := range rs.Repositories {
+		lines = append(lines, getRepositorySummaryLine(repository))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (rs *RunSummary) AddVulnerabilities(vulnerabilities ...formats.VulnerabilityOrViolationRow) {
+	rs.Vulnerabilities = append(rs.Vulnerabilities, vulnerabilities...)
+}
+
+func (rs *RunSummary) AddLicenseViolations(licenseViolations ...formats.LicenseRow) {
+	rs.LicenseViolations = append(rs.LicenseViolations, licenseViolations...)
+}
+
+// AddSlaStatus records the SLA status of the vulnerability.
+// If the vulnerability was detected in several branches, the status with the earliest deadline is kept.
+func (rs *RunSummary) AddSlaStatus(vulnerability formats.VulnerabilityOrViolationRow, status *SlaStatus) {
+	if rs.SlaStatuses == nil {
+		rs.SlaStatuses = map[string]*SlaStatus{}
+	}
+	vulnerabilityKey := GetVulnerabiltiesUniqueID(vulnerability)
+	if current, exists := rs.SlaStatuses[vulnerabilityKey]; !exists || status.Deadline.Before(current.Deadline) {
+		rs.SlaStatuses[vulnerabilityKey] = status
+	}
+}
+
+func (rs *RunSummary) getSlaStatus(vulnerability formats.VulnerabilityOrViolationRow) *SlaStatus {
+	return rs.SlaStatuses[GetVulnerabiltiesUniqueID(vulnerability)]
+}
+
+func (rs *RunSummary) countOverdue() (overdue int) {
+	for _, vulnerability := range rs.Vulnerabilities {
+		if status := rs.getSlaStatus(vulnerability); status != nil && status.IsOverdue() {
+			overdue++
+		}
+	}
+	return
+}
+
+func (rs *RunSummary) counts() (opened, updated int) {
+	for _, pr := range rs.PullRequests {
+		if pr.Updated {
+			updated++
+		} else {
+			opened++
+		}
+	}
+	return
+}
+
+// SendRunSummaryNotifications posts the run summary to the configured Slack and Microsoft Teams incoming webhooks.
+// The summary of a target configured as redacted lists the vulnerable packages and their severity counts, without the details of the vulnerabilities.
+// Notification failures are logged and never fail the run.
+func SendRunSummaryNotifications(details NotificationsDetails, summary *RunSummary) {
+	if details.SlackWebhook != "" {
+		if err := postRunSummary(details.SlackWebhook, getSlackRunSummaryPayload(summary, details.IsRedacted(SlackSummaryTarget))); err != nil {
+			log.Warn("Failed to send the run summary to Slack:", err.Error())
+		}
+	}
+	if details.TeamsWebhook != "" {
+		if err := postRunSummary(details.TeamsWebhook, getTeamsRunSummaryPayload(summary, details.IsRedacted(TeamsSummaryTarget))); err != nil {
+			log.Warn("Failed to send the run summary to Microsoft Teams:", err.Error())
+		}
+	}
+}
+
+func postRunSummary(webhookUrl string, payload any) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: notificationRequestTimeout}
+	resp, err := client.Post(webhookUrl, "application/json", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+func getRunSummaryHeadline(summary *RunSummary) string {
+	opened, updated := summary.counts()
+	headline := fmt.Sprintf("Frogbot opened %d and updated %d pull requests", opened, updated)
+	if len(summary.Repositories) > 0 {
+		headline += fmt.Sprintf(" across %d repositories", len(summary.Repositories))
+	}
+	return headline
+}
+
+func getRepositoriesHeadline(summary *RunSummary) string {
+	return fmt.Sprintf("Scanned %d repositories, %d failed", len(summary.Repositories), len(summary.getFailedRepositories()))
+}
+
+func getRepositorySummaryLine(repository RepositoryScanSummary) string {
+	if repository.Error != "" {
+		return fmt.Sprintf("%s: failed: %s", repository.Repository, repository.Error)
+	}
+	return fmt.Sprintf("%s: %d pull requests, %d vulnerabilities", repository.Repository, repository.PullRequests, repository.Vulnerabilities)
+}
+
+// Returns the pull requests to list in the summary, and the number of pull requests left out.
+func getListedPullRequests(summary *RunSummary) ([]PullRequestSummary, int) {
+	if len(summary.PullRequests) <= notificationMaxListedPrs {
+		return summary.PullRequests, 0
+	}
+	return summary.PullRequests[:notificationMaxListedPrs], len(summary.PullRequests) - notificationMaxListedPrs
+}
+
+func getVulnerabilitiesHeadline(summary *RunSummary) string {
+	headline := fmt.Sprintf("Detected vulnerabilities: %d (%s)", len(summary.Vulnerabilities), outputwriter.SeverityCountsContent(summary.Vulnerabilities))
+	if overdue := summary.countOverdue(); overdue > 0 {
+		headline += fmt.Sprintf(", %d overdue", overdue)
+	}
+	return headline
+}
+
+// Returns a line for each vulnerability to list in the summary, and the number of lines left out.
+// A redacted summary lists the vulnerable packages and their severity counts instead of the vulnerabilities.
+func getListedVulnerabilities(summary *RunSummary, redacted bool) (lines []string, leftOut int) {
+	if redacted {
+		for _, row := range outputwriter.GetRedactedVulnerabilityRows(summary.Vulnerabilities) {
+			lines = append(lines, fmt.Sprintf("%s: %s", row.ImpactedDependency, row.SeverityCounts))
+		}
+	} else {
+		var overdueLines []string
+		for _, vulnerability := range summary.Vulnerabilities {
+			line := fmt.Sprintf("%s %s %s: %s", vulnerability.Severity, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, strings.Join(getVulnerabilityIds(vulnerability), ", "))
+			status := summary.getSlaStatus(vulnerability)
+			if status == nil {
+				lines = append(lines, line)
+				continue
+			}
+			line += fmt.Sprintf(" (%s)", status)
+			if status.IsOverdue() {
+				overdueLines = append(overdueLines, line)
+			} else {
+				lines = append(lines, line)
+			}
+		}
+		// The overdue vulnerabilities are listed first, so they aren't left out
+		lines = append(overdueLines, lines...)
+	}
+	if len(lines) <= notificationMaxListedVulns {
+		return lines, 0
+	}
+	return lines[:notificationMaxListedVulns], len(lines) - notificationMaxListedVulns
+}
+
+// Returns a line for each license violation to list in the summary, and the number of lines left out
+func getListedLicenseViolations(summary *RunSummary) (lines []string, leftOut int) {
+	for _, licenseViolation := range summary.LicenseViolations {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", licenseViolation.ImpactedDependencyName, licenseViolation.ImpactedDependencyVersion, licenseViolation.LicenseKey))
+	}
+	if len(lines) <= notificationMaxListedVulns {
+		return lines, 0
+	}
+	return lines[:notificationMaxListedVulns], len(lines) - notificationMaxListedVulns
+}
+
+// Returns the CVE ids of the vulnerability, or its Xray issue id if it has no CVEs
+func getVulnerabilityIds(vulnerability formats.VulnerabilityOrViolationRow) (ids []string) {
+	for _, cve := range vulnerability.Cves {
+		ids = append(ids, cve.Id)
+	}
+	if len(ids) == 0 {
+		ids = append(ids, vulnerability.IssueId)
+	}
+	return
+}
+
+func getPullRequestOperation(pr PullRequestSummary) string {
+	if pr.Updated {
+		return pullRequestUpdatedNotificationOp
+	}
+	return pullRequestOpenedNotificationOp
+}
+
+// Slack Block Kit message
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func getSlackRunSummaryPayload(summary *RunSummary, redacted bool) slackMessage {
+	opened, updated := summary.counts()
+	message := slackMessage{
+		Text: getRunSummaryHeadline(summary),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: slackPlainTextType, Text: notificationTitle}},
+			{Type: "section", Fields: []slackText{
+				{Type: slackMarkdownTextType, Text: fmt.Sprintf("*Opened pull requests:*\n%d", opened)},
+				{Type: slackMarkdownTextType, Text: fmt.Sprintf("*Updated pull requests:*\n%d", updated)},
+			}},
+		},
+	}
+	if len(summary.Repositories) > 0 {
+		repositoriesText := fmt.Sprintf("*%s*", getRepositoriesHeadline(summary))
+		for _, repository := range summary.getFailedRepositories() {
+			repositoriesText += "\n• " + getRepositorySummaryLine(repository)
+		}
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: slackMarkdownTextType, Text: repositoriesText}})
+	}
+	if len(summary.Warnings) > 0 {
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: slackMarkdownTextType, Text: "*Warnings:*\n• " + strings.Join(summary.Warnings, "\n• ")}})
+	}
+	if len(summary.Vulnerabilities) > 0 {
+		listedVulnerabilities, leftOutVulnerabilities := getListedVulnerabilities(summary, redacted)
+		vulnerabilitiesText := fmt.Sprintf("*%s*\n• %s", getVulnerabilitiesHeadline(summary), strings.Join(listedVulnerabilities, "\n• "))
+		if leftOutVulnerabilities > 0 {
+			vulnerabilitiesText += fmt.Sprintf("\n_and %d more_", leftOutVulnerabilities)
+		}
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: slackMarkdownTextType, Text: vulnerabilitiesText}})
+	}
+	if len(summary.LicenseViolations) > 0 {
+		listedLicenseViolations, leftOutLicenseViolations := getListedLicenseViolations(summary)
+		licenseViolationsText := fmt.Sprintf("*Violated licenses: %d*\n• %s", len(summary.LicenseViolations), strings.Join(listedLicenseViolations, "\n• "))
+		if leftOutLicenseViolations > 0 {
+			licenseViolationsText += fmt.Sprintf("\n_and %d more_", leftOutLicenseViolations)
+		}
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: slackMarkdownTextType, Text: licenseViolationsText}})
+	}
+	listedPullRequests, leftOut := getListedPullRequests(summary)
+	if len(listedPullRequests) == 0 {
+		return message
+	}
+	var prsBuilder strings.Builder
+	for _, pr := range listedPullRequests {
+		prsBuilder.WriteString(fmt.Sprintf("• <%s|%s> (%s, %s)\n", pr.URL, pr.Title, pr.Repository, getPullRequestOperation(pr)))
+	}
+	if leftOut > 0 {
+		prsBuilder.WriteString(fmt.Sprintf("_and %d more_", leftOut))
+	}
+	message.Blocks = append(message.Blocks,
+		slackBlock{Type: "divider"},
+		slackBlock{Type: "section", Text: &slackText{Type: slackMarkdownTextType, Text: strings.TrimSuffix(prsBuilder.String(), "\n")}})
+	return message
+}
+
+// GetMarkdownRunSummary returns the run summary as Markdown, for the targets that render Markdown, such as wiki pages
+func GetMarkdownRunSummary(summary *RunSummary, redacted bool) string {
+	opened, updated := summary.counts()
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("## %s\n\n", notificationTitle))
+	content.WriteString(fmt.Sprintf("- **Opened pull requests:** %d\n- **Updated pull requests:** %d\n", opened, updated))
+	writeSection := func(headline string, lines []string, leftOut int) {
+		content.WriteString(fmt.Sprintf("\n### %s\n\n", headline))
+		for _, line := range lines {
+			content.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		if leftOut > 0 {
+			content.WriteString(fmt.Sprintf("\n_and %d more_\n", leftOut))
+		}
+	}
+	if len(summary.Repositories) > 0 {
+		var lines []string
+		for _, repository := range summary.getFailedRepositories() {
+			lines = append(lines, getRepositorySummaryLine(repository))
+		}
+		writeSection(getRepositoriesHeadline(summary), lines, 0)
+	}
+	if len(summary.Warnings) > 0 {
+		writeSection("Warnings", summary.Warnings, 0)
+	}
+	if len(summary.Vulnerabilities) > 0 {
+		listedVulnerabilities, leftOutVulnerabilities := getListedVulnerabilities(summary, redacted)
+		writeSection(getVulnerabilitiesHeadline(summary), listedVulnerabilities, leftOutVulnerabilities)
+	}
+	if len(summary.LicenseViolations) > 0 {
+		listedLicenseViolations, leftOutLicenseViolations := getListedLicenseViolations(summary)
+		writeSection(fmt.Sprintf("Violated licenses: %d", len(summary.LicenseViolations)), listedLicenseViolations, leftOutLicenseViolations)
+	}
+	if listedPullRequests, leftOut := getListedPullRequests(summary); len(listedPullRequests) > 0 {
+		var lines []string
+		for _, pr := range listedPullRequests {
+			lines = append(lines, fmt.Sprintf("[%s](%s) (%s, %s)", pr.Title, pr.URL, pr.Repository, getPullRequestOperation(pr)))
+		}
+		writeSection("Pull requests", lines, leftOut)
+	}
+	return content.String()
+}
+
+// Microsoft Teams Adaptive Card message
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []teamsCardBlock `json:"body"`
+}
+
+type teamsCardBlock struct {
+	Type   string      `json:"type"`
+	Text   string      `json:"text,omitempty"`
+	Weight string      `json:"weight,omitempty"`
+	Size   string      `json:"size,omitempty"`
+	Wrap   bool        `json:"wrap,omitempty"`
+	Facts  []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func getTeamsRunSummaryPayload(summary *RunSummary, redacted bool) teamsMessage {
+	opened, updated := summary.counts()
+	body := []teamsCardBlock{
+		{Type: "TextBlock", Text: notificationTitle, Weight: "Bolder", Size: "Medium"},
+		{Type: "FactSet", Facts: []teamsFact{
+			{Title: "Opened pull requests", Value: fmt.Sprint(opened)},
+			{Title: "Updated pull requests", Value: fmt.Sprint(updated)},
+		}},
+	}
+	if len(summary.Repositories) > 0 {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: getRepositoriesHeadline(summary), Weight: "Bolder", Wrap: true})
+		for _, repository := range summary.getFailedRepositories() {
+			body = append(body, teamsCardBlock{Type: "TextBlock", Text: "❌ " + getRepositorySummaryLine(repository), Wrap: true})
+		}
+	}
+	for _, warning := range summary.Warnings {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: "⚠️ " + warning, Wrap: true})
+	}
+	if len(summary.Vulnerabilities) > 0 {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: getVulnerabilitiesHeadline(summary), Weight: "Bolder", Wrap: true})
+		listedVulnerabilities, leftOutVulnerabilities := getListedVulnerabilities(summary, redacted)
+		for _, line := range listedVulnerabilities {
+			body = append(body, teamsCardBlock{Type: "TextBlock", Text: "- " + line, Wrap: true})
+		}
+		if leftOutVulnerabilities > 0 {
+			body = append(body, teamsCardBlock{Type: "TextBlock", Text: fmt.Sprintf("and %d more", leftOutVulnerabilities), Wrap: true})
+		}
+	}
+	if len(summary.LicenseViolations) > 0 {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: fmt.Sprintf("Violated licenses: %d", len(summary.LicenseViolations)), Weight: "Bolder", Wrap: true})
+		listedLicenseViolations, leftOutLicenseViolations := getListedLicenseViolations(summary)
+		for _, line := range listedLicenseViolations {
+			body = append(body, teamsCardBlock{Type: "TextBlock", Text: "- " + line, Wrap: true})
+		}
+		if leftOutLicenseViolations > 0 {
+			body = append(body, teamsCardBlock{Type: "TextBlock", Text: fmt.Sprintf("and %d more", leftOutLicenseViolations), Wrap: true})
+		}
+	}
+	listedPullRequests, leftOut := getListedPullRequests(summary)
+	for _, pr := range listedPullRequests {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: fmt.Sprintf("- [%s](%s) (%s, %s)", pr.Title, pr.URL, pr.Repository, getPullRequestOperation(pr)), Wrap: true})
+	}
+	if leftOut > 0 {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: fmt.Sprintf("and %d more", leftOut), Wrap: true})
+	}
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: teamsAdaptiveCardContentType,
+			Content:     teamsCard{Schema: teamsAdaptiveCardSchema, Type: "AdaptiveCard", Version: teamsAdaptiveCardSchemaVersion, Body: body},
+		}},
+	}
+}
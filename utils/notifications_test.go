@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSlackRunSummaryPayload(t *testing.T) {
+	summary := &RunSummary{}
+	summary.AddPullRequest("jfrog/frogbot", "[🐸 Frogbot] Update version of minimist to 1.2.6", "https://github.com/jfrog/frogbot/pull/1", false)
+	summary.AddPullRequest("jfrog/frogbot", "[🐸 Frogbot] Update version of uuid to 9.0.0", "https://github.com/jfrog/frogbot/pull/2", false)
+	summary.AddPullRequest("jfrog/frogbot", "[🐸 Frogbot] Update npm dependencies", "https://github.com/jfrog/frogbot/pull/3", true)
+
+	content, err := json.Marshal(getSlackRunSummaryPayload(summary, false))
+	require.NoError(t, err)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(content, &payload))
+
+	assert.Equal(t, "Frogbot opened 2 and updated 1 pull requests", payload["text"])
+	blocks, ok := payload["blocks"].([]any)
+	require.True(t, ok)
+	require.Len(t, blocks, 4)
+	assert.Equal(t, "header", blocks[0].(map[string]any)["type"])
+
+	counts := blocks[1].(map[string]any)
+	assert.Equal(t, "section", counts["type"])
+	fields := counts["fields"].([]any)
+	require.Len(t, fields, 2)
+	assert.Equal(t, map[string]any{"type": "mrkdwn", "text": "*Opened pull requests:*\n2"}, fields[0])
+	assert.Equal(t, map[string]any{"type": "mrkdwn", "text": "*Updated pull requests:*\n1"}, fields[1])
+
+	assert.Equal(t, "divider", blocks[2].(map[string]any)["type"])
+	pullRequests := blocks[3].(map[string]any)["text"].(map[string]any)
+	assert.Equal(t, "mrkdwn", pullRequests["type"])
+	assert.Equal(t, "• <https://github.com/jfrog/frogbot/pull/1|[🐸 Frogbot] Update version of minimist to 1.2.6> (jfrog/frogbot, opened)\n"+
+		"• <https://github.com/jfrog/frogbot/pull/2|[🐸 Frogbot] Update version of uuid to 9.0.0> (jfrog/frogbot, opened)\n"+
+		"• <https://github.com/jfrog/frogbot/pull/3|[🐸 Frogbot] Update npm dependencies> (jfrog/frogbot, updated)", pullRequests["text"])
+}
+
+func TestGetSlackRunSummaryPayloadWithWarnings(t *testing.T) {
+	summary := &RunSummary{}
+	summary.AddWarning("jfrog/frogbot", "Frogbot doesn't support fixing the vulnerabilities of Conan, which was detected in '.'")
+
+	content, err := json.Marshal(getSlackRunSummaryPayload(summary, false))
+	require.NoError(t, err)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(content, &payload))
+	blocks, ok := payload["blocks"].([]any)
+	require.True(t, ok)
+	require.Len(t, blocks, 3)
+	warnings := blocks[2].(map[string]any)["text"].(map[string]any)
+	assert.Equal(t, "*Warnings:*\n• jfrog/frogbot: Frogbot doesn't support fixing the vulnerabilities of Conan, which was detected in '.'", warnings["text"])
+}
+
+func TestGetSlackRunSummaryPayloadWithLicenseViolations(t *testing.T) {
+	summary := &RunSummary{}
+	summary.AddLicenseViolations(formats.LicenseRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "copyleft", ImpactedDependencyVersion: "1.0.0"},
+		LicenseKey:                "GPL-3.0",
+	})
+
+	content, err := json.Marshal(getSlackRunSummaryPayload(summary, false))
+	require.NoError(t, err)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(content, &payload))
+	blocks, ok := payload["blocks"].([]any)
+	require.True(t, ok)
+	require.Len(t, blocks, 3)
+	licenseViolations := blocks[2].(map[string]any)["text"].(map[string]any)
+	assert.Equal(t, "*Violated licenses: 1*\n• copyleft 1.0.0: GPL-3.0", licenseViolations["text"])
+}
+
+func TestGetSlackRunSummaryPayloadWithRepositories(t *testing.T) {
+	summary := &RunSummary{}
+	summary.AddPullRequest("jfrog/frogbot", "[🐸 Frogbot] Update version of minimist to 1.2.6", "https://github.com/jfrog/frogbot/pull/1", false)
+	summary.AddRepository("jfrog/frogbot", 1, 2, nil)
+	summary.AddRepository("jfrog/jfrog-cli", 0, 0, errors.New("repository not found"))
+
+	assert.Equal(t, "Scanned 2 repositories, 1 failed\n"+
+		"jfrog/frogbot: 1 pull requests, 2 vulnerabilities\n"+
+		"jfrog/jfrog-cli: failed: repository not found", summary.GetRepositoriesSummary())
+
+	content, err := json.Marshal(getSlackRunSummaryPayload(summary, false))
+	require.NoError(t, err)
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(content, &payload))
+	assert.Equal(t, "Frogbot opened 1 and updated 0 pull requests across 2 repositories", payload["text"])
+	blocks, ok := payload["blocks"].([]any)
+	require.True(t, ok)
+	require.Len(t, blocks, 5)
+	repositories := blocks[2].(map[string]any)["text"].(map[string]any)
+	assert.Equal(t, "*Scanned 2 repositories, 1 failed*\n• jfrog/jfrog-cli: failed: repository not found", repositories["text"])
+}
+
+func TestSendRunSummaryNotifications(t *testing.T) {
+	var slackRequestBody, teamsRequestBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		switch r.URL.Path {
+		case "/slack":
+			slackRequestBody = body
+			w.WriteHeader(http.StatusOK)
+		case "/teams":
+			teamsRequestBody = body
+			// Notification failures must not fail the run
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	summary := &RunSummary{}
+	summary.AddPullRequest("jfrog/frogbot", "title", "https://github.com/jfrog/frogbot/pull/1", false)
+	SendRunSummaryNotifications(NotificationsDetails{SlackWebhook: server.URL + "/slack", TeamsWebhook: server.URL + "/teams"}, summary)
+	assert.Contains(t, string(slackRequestBody), `"blocks"`)
+	assert.Contains(t, string(teamsRequestBody), teamsAdaptiveCardContentType)
+}
+
+func TestSendRedactedRunSummaryNotifications(t *testing.T) {
+	requestBodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requestBodies[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	newVulnerability := func(name, version, severity, cve string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			Cves:                      []formats.CveRow{{Id: cve}},
+		}
+	}
+	summary := &RunSummary{}
+	summary.AddVulnerabilities(
+		newVulnerability("lodash", "4.17.20", "Critical", "CVE-2021-23337"),
+		newVulnerability("lodash", "4.17.20", "High", "CVE-2020-28500"),
+		newVulnerability("minimist", "1.2.5", "High", "CVE-2021-44906"),
+	)
+	// The same run posts a detailed summary to Slack and a redacted summary to Teams
+	SendRunSummaryNotifications(NotificationsDetails{SlackWebhook: server.URL + "/slack", TeamsWebhook: server.URL + "/teams", RedactedSummaryTargets: []string{string(TeamsSummaryTarget)}}, summary)
+
+	assert.Contains(t, requestBodies["/slack"], "Detected vulnerabilities: 3 (1 Critical, 2 High)")
+	assert.Contains(t, requestBodies["/slack"], "Critical lodash 4.17.20: CVE-2021-23337")
+	assert.Contains(t, requestBodies["/slack"], "High minimist 1.2.5: CVE-2021-44906")
+
+	assert.Contains(t, requestBodies["/teams"], "Detected vulnerabilities: 3 (1 Critical, 2 High)")
+	assert.Contains(t, requestBodies["/teams"], "- lodash 4.17.20: 1 Critical, 1 High")
+	assert.Contains(t, requestBodies["/teams"], "- minimist 1.2.5: 1 High")
+	assert.NotContains(t, requestBodies["/teams"], "CVE-")
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/jfrog/froggit-go/vcsutils"
 	"github.com/jfrog/jfrog-cli-security/formats"
 	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
 )
 
 const (
@@ -21,8 +23,31 @@ const (
 	contextualAnalysisTitle = "📦🔍 Contextual Analysis CVE Vulnerability"
 	iacTitle                = "🛠️ Infrastructure as Code Vulnerability"
 	sastTitle               = "🎯 Static Application Security Testing (SAST) Vulnerability"
+
+	transitiveDependenciesTitle = "🔗 Transitive Dependencies"
+	securityAlertsTitle         = "🛡️ Security Alerts"
+	commitSummaryTitle          = "🐸 Frogbot Scan Summary"
+	applicabilityEvidenceTitle  = "🔍 Applicability Evidence"
+	packagesStatusTitle         = "📋 Packages Status"
+	maxEvidencesPerCve          = 3
+	maxEvidenceSnippetLength    = 120
+	// Bounds the packages status table, so it doesn't push the details of the fixes out of the pull request body
+	maxPackagesStatusRows = 50
 )
 
+// The sections of a fix pull request body
+const (
+	SummarySection        = "summary"
+	CvesSection           = "cves"
+	ImpactPathSection     = "impact-path"
+	FixNotesSection       = "fix-notes"
+	SecurityAlertsSection = "security-alerts"
+	EvidenceSection       = "evidence"
+)
+
+// The sections of a fix pull request body, in their default order
+var DefaultPullRequestBodySections = []string{SummarySection, CvesSection, ImpactPathSection, FixNotesSection, SecurityAlertsSection, EvidenceSection}
+
 var (
 	CommentGeneratedByFrogbot    = MarkAsLink("🐸 JFrog Frogbot", FrogbotDocumentationUrl)
 	jasFeaturesMsgWhenNotEnabled = MarkAsBold("Frogbot") + " also supports " + MarkAsBold("Contextual Analysis, Secret Detection, IaC and SAST Vulnerabilities Scanning") + ". This features are included as part of the " + MarkAsLink("JFrog Advanced Security", "https://jfrog.com/advanced-security") + " package, which isn't enabled on your system."
@@ -119,14 +144,38 @@ func VulnerabilitiesContent(vulnerabilities []formats.VulnerabilityOrViolationRo
 	if len(vulnerabilities) == 0 {
 		return []string{}
 	}
-	content = append(content, writer.MarkAsTitle(vulnerableDependenciesTitle, 2))
-	content = append(content, vulnerabilitiesSummaryContent(vulnerabilities, writer))
+	content = append(content, VulnerabilitiesSummaryContent(vulnerabilities, writer)...)
+	if writer.IsRedacted() {
+		// The research details describe the CVEs of the vulnerabilities
+		return
+	}
 	content = append(content, vulnerabilityDetailsContent(vulnerabilities, writer)...)
 	return
 }
 
+// VulnerabilitiesSummaryContent returns the title of the vulnerable dependencies and their summary table
+func VulnerabilitiesSummaryContent(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) []string {
+	if len(vulnerabilities) == 0 {
+		return []string{}
+	}
+	return []string{writer.MarkAsTitle(vulnerableDependenciesTitle, 2), vulnerabilitiesSummaryContent(vulnerabilities, writer)}
+}
+
+// VulnerabilitiesResearchContent returns the research details of the vulnerabilities, split to comments if they exceed the size limit
+func VulnerabilitiesResearchContent(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) []string {
+	return vulnerabilityDetailsContent(vulnerabilities, writer)
+}
+
 func vulnerabilitiesSummaryContent(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) string {
 	var contentBuilder strings.Builder
+	if writer.IsRedacted() {
+		WriteContent(&contentBuilder,
+			writer.MarkAsTitle("✍️ Summary", 3),
+			fmt.Sprintf("%s vulnerabilities were detected: %s", MarkAsBold(fmt.Sprint(len(vulnerabilities))), SeverityCountsContent(vulnerabilities)),
+			writer.MarkInCenter(getRedactedVulnerabilitiesSummaryTable(vulnerabilities, writer)),
+		)
+		return contentBuilder.String()
+	}
 	WriteContent(&contentBuilder,
 		writer.MarkAsTitle("✍️ Summary", 3),
 		writer.MarkInCenter(getVulnerabilitiesSummaryTable(vulnerabilities, writer)),
@@ -134,6 +183,53 @@ func vulnerabilitiesSummaryContent(vulnerabilities []formats.VulnerabilityOrViol
 	return contentBuilder.String()
 }
 
+// Lists the vulnerable packages and the number of their vulnerabilities of each severity, without the details of the vulnerabilities
+func getRedactedVulnerabilitiesSummaryTable(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) string {
+	table := NewMarkdownTable("IMPACTED DEPENDENCY", "VULNERABILITIES").SetDelimiter(writer.Separator())
+	for _, row := range GetRedactedVulnerabilityRows(vulnerabilities) {
+		table.AddRow(row.ImpactedDependency, row.SeverityCounts)
+	}
+	return table.Build()
+}
+
+// RedactedVulnerabilityRow is a vulnerable package along with the number of its vulnerabilities of each severity
+type RedactedVulnerabilityRow struct {
+	ImpactedDependency string
+	SeverityCounts     string
+}
+
+// GetRedactedVulnerabilityRows groups the vulnerabilities by their impacted packages, in the order the packages are first detected
+func GetRedactedVulnerabilityRows(vulnerabilities []formats.VulnerabilityOrViolationRow) (rows []RedactedVulnerabilityRow) {
+	var packages []string
+	vulnerabilitiesByPackage := map[string][]formats.VulnerabilityOrViolationRow{}
+	for _, vulnerability := range vulnerabilities {
+		impactedDependency := fmt.Sprintf("%s %s", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+		if _, exists := vulnerabilitiesByPackage[impactedDependency]; !exists {
+			packages = append(packages, impactedDependency)
+		}
+		vulnerabilitiesByPackage[impactedDependency] = append(vulnerabilitiesByPackage[impactedDependency], vulnerability)
+	}
+	for _, impactedDependency := range packages {
+		rows = append(rows, RedactedVulnerabilityRow{ImpactedDependency: impactedDependency, SeverityCounts: SeverityCountsContent(vulnerabilitiesByPackage[impactedDependency])})
+	}
+	return
+}
+
+// SeverityCountsContent returns the number of vulnerabilities of each severity, from the most severe, for example: "1 Critical, 2 High"
+func SeverityCountsContent(vulnerabilities []formats.VulnerabilityOrViolationRow) string {
+	counts := map[severityutils.Severity]int{}
+	for _, vulnerability := range vulnerabilities {
+		counts[severityutils.GetSeverity(vulnerability.Severity)]++
+	}
+	var severityCounts []string
+	for _, severity := range []severityutils.Severity{severityutils.Critical, severityutils.High, severityutils.Medium, severityutils.Low, severityutils.Unknown} {
+		if counts[severity] > 0 {
+			severityCounts = append(severityCounts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	return strings.Join(severityCounts, ", ")
+}
+
 func getVulnerabilitiesSummaryTable(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) string {
 	// Construct table
 	columns := []string{"SEVERITY"}
@@ -265,6 +361,242 @@ func getVulnerabilityDescriptionIdentifier(cveRows []formats.CveRow, xrayId stri
 	return fmt.Sprintf("[ %s ]", identifier)
 }
 
+// TransitiveDependencyRow describes a fix of a vulnerable transitive dependency, and the direct dependency that brings it in.
+type TransitiveDependencyRow struct {
+	// The path from the direct dependency down to the vulnerable dependency
+	ImpactPath []formats.ComponentRow
+	FixVersion string
+}
+
+func TransitiveDependenciesContent(rows []TransitiveDependencyRow, writer OutputWriter) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder, writer.MarkAsTitle(transitiveDependenciesTitle, 3))
+	table := NewMarkdownTable("DIRECT DEPENDENCY", "VULNERABLE TRANSITIVE DEPENDENCY", "FIXED VERSION", "IMPACT PATH").SetDelimiter(writer.Separator())
+	for _, row := range rows {
+		directDependency, vulnerableDependency := row.ImpactPath[0], row.ImpactPath[len(row.ImpactPath)-1]
+		var impactPath []string
+		for _, component := range row.ImpactPath {
+			impactPath = append(impactPath, fmt.Sprintf("%s:%s", component.Name, component.Version))
+		}
+		table.AddRow(
+			fmt.Sprintf("%s:%s", directDependency.Name, directDependency.Version),
+			fmt.Sprintf("%s:%s", vulnerableDependency.Name, vulnerableDependency.Version),
+			row.FixVersion,
+			strings.Join(impactPath, " → "),
+		)
+	}
+	WriteContent(&contentBuilder, writer.MarkInCenter(table.Build()))
+	return contentBuilder.String()
+}
+
+// SecurityAlertRow describes a security alert of the VCS provider, such as a Dependabot alert on GitHub.
+type SecurityAlertRow struct {
+	Number      int
+	Url         string
+	PackageName string
+	CveId       string
+	GhsaId      string
+}
+
+func SecurityAlertsContent(alerts []SecurityAlertRow, writer OutputWriter) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder,
+		writer.MarkAsTitle(securityAlertsTitle, 3),
+		"This pull request addresses the following security alerts:",
+	)
+	table := NewMarkdownTable("ALERT", "PACKAGE", "ADVISORY").SetDelimiter(writer.Separator())
+	for _, alert := range alerts {
+		advisory := alert.CveId
+		if alert.GhsaId != "" {
+			advisory = fmt.Sprintf("%s (%s)", alert.CveId, alert.GhsaId)
+		}
+		table.AddRow(MarkAsLink(fmt.Sprintf("#%d", alert.Number), alert.Url), alert.PackageName, advisory)
+	}
+	WriteContent(&contentBuilder, writer.MarkInCenter(table.Build()))
+	return contentBuilder.String()
+}
+
+// FixPullRequestRow describes a fix pull request that Frogbot opened or updated.
+type FixPullRequestRow struct {
+	Title string
+	Url   string
+}
+
+// CommitSummaryContent summarizes the vulnerabilities detected in a commit and the pull requests that fix them.
+func CommitSummaryContent(vulnerabilities []formats.VulnerabilityOrViolationRow, pullRequests []FixPullRequestRow, writer OutputWriter) string {
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder, writer.MarkAsTitle(commitSummaryTitle, 2))
+	if len(vulnerabilities) == 0 {
+		WriteContent(&contentBuilder, "No vulnerable dependencies were detected in this commit.")
+	} else {
+		WriteContent(&contentBuilder, vulnerabilitiesSummaryContent(vulnerabilities, writer))
+	}
+	if len(pullRequests) > 0 {
+		WriteContent(&contentBuilder, writer.MarkAsTitle("🛠️ Fix Pull Requests", 3))
+		for _, pullRequest := range pullRequests {
+			WriteContent(&contentBuilder, "- "+MarkAsLink(pullRequest.Title, pullRequest.Url))
+		}
+	}
+	WriteContent(&contentBuilder, footer(writer))
+	return contentBuilder.String()
+}
+
+// UnfixableVulnerabilityContent describes a vulnerability that Frogbot can't fix, and the reason it can't be fixed.
+func UnfixableVulnerabilityContent(vulnerability formats.VulnerabilityOrViolationRow, reasonCode, reason string, writer OutputWriter) string {
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder,
+		writer.MarkAsTitle("🚫 Unfixable Vulnerability", 2),
+		vulnerabilitiesSummaryContent([]formats.VulnerabilityOrViolationRow{vulnerability}, writer),
+		writer.MarkAsTitle("❓ Why Frogbot Can't Fix It", 3),
+		fmt.Sprintf("Reason: %s\n\n%s", MarkAsQuote(reasonCode), reason),
+		footer(writer),
+	)
+	return contentBuilder.String()
+}
+
+func RemediationConfirmedContent(packages []string, branch string, writer OutputWriter) string {
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder,
+		writer.MarkAsTitle("✅ Remediation Confirmed", 2),
+		fmt.Sprintf("The vulnerabilities fixed by this pull request are no longer detected on the %s branch: %s", MarkAsQuote(branch), strings.Join(packages, ", ")),
+		footer(writer),
+	)
+	return contentBuilder.String()
+}
+
+// MultipleCvesFixRow is a fix of a single package that resolves more than one CVE
+type MultipleCvesFixRow struct {
+	PackageName string
+	FixVersion  string
+	Cves        []string
+}
+
+// ResolvedCvesAnnotation highlights the number of CVEs a fix resolves, for example: "Resolves 3 CVEs"
+func ResolvedCvesAnnotation(cvesCount int) string {
+	return fmt.Sprintf("Resolves %d CVEs", cvesCount)
+}
+
+// MultipleCvesFixesContent highlights the fixes that resolve several CVEs at once, as they are the most valuable fixes to review.
+func MultipleCvesFixesContent(rows []MultipleCvesFixRow, writer OutputWriter) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	for _, row := range rows {
+		WriteContent(&contentBuilder, fmt.Sprintf("🔥 %s by updating %s to %s: %s", MarkAsBold(ResolvedCvesAnnotation(len(row.Cves))), row.PackageName, row.FixVersion, strings.Join(row.Cves, ", ")))
+	}
+	return contentBuilder.String()
+}
+
+// TestMatrixNoteContent adds the configured note about the runtime versions the project is tested against, so the updates are verified across all of them.
+func TestMatrixNoteContent(writer OutputWriter) string {
+	note := strings.TrimSpace(writer.TestMatrixNote())
+	if note == "" {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder, fmt.Sprintf("🧪 %s", MarkAsBold("Test matrix:")))
+	WriteContent(&contentBuilder, note)
+	return contentBuilder.String()
+}
+
+// BreakingChangesRow is a fixed package whose fix version the advisories note breaking changes in
+type BreakingChangesRow struct {
+	PackageName string
+	FixVersion  string
+	Notes       []string
+}
+
+// BreakingChangesContent warns about the fixes that may break the code, so they are reviewed before merging.
+func BreakingChangesContent(rows []BreakingChangesRow, writer OutputWriter) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	for _, row := range rows {
+		WriteContent(&contentBuilder, fmt.Sprintf("⚠️ %s Updating %s to %s may introduce breaking changes. Review the changes before merging.", MarkAsBold("Breaking changes:"), row.PackageName, row.FixVersion))
+		for _, note := range row.Notes {
+			WriteContent(&contentBuilder, "- "+note)
+		}
+	}
+	return contentBuilder.String()
+}
+
+// RegressionRow is a vulnerable package that a merged fix pull request had already upgraded, before the fix was reverted
+type RegressionRow struct {
+	PackageName       string
+	VulnerableVersion string
+	FixVersion        string
+	PullRequestUrl    string
+}
+
+// RegressionsContent escalates the vulnerabilities that were reintroduced after they were fixed.
+func RegressionsContent(rows []RegressionRow, writer OutputWriter) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	for _, row := range rows {
+		WriteContent(&contentBuilder, fmt.Sprintf("🔁 %s %s %s was reintroduced, after it was fixed by updating it to %s in %s.", MarkAsBold("Regression:"), row.PackageName, row.VulnerableVersion, row.FixVersion, row.PullRequestUrl))
+	}
+	return contentBuilder.String()
+}
+
+func FixNotesContent(notes []string, writer OutputWriter) string {
+	if len(notes) == 0 {
+		return ""
+	}
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder, writer.MarkAsTitle("📝 Notes", 3))
+	for _, note := range notes {
+		WriteContent(&contentBuilder, "- "+note)
+	}
+	return contentBuilder.String()
+}
+
+// ApplicabilityEvidenceContent lists the code locations that reach the vulnerable functions of the applicable CVEs.
+// The section is collapsed, and the number of locations and the snippets length are bounded to keep the pull request readable.
+func ApplicabilityEvidenceContent(vulnerabilities []formats.VulnerabilityOrViolationRow, writer OutputWriter) string {
+	var contentBuilder strings.Builder
+	for _, vulnerability := range vulnerabilities {
+		for _, cve := range vulnerability.Cves {
+			if cve.Applicability == nil || cve.Applicability.Status != jasutils.Applicable.String() || len(cve.Applicability.Evidence) == 0 {
+				continue
+			}
+			WriteContent(&contentBuilder, fmt.Sprintf("%s %s:%s", MarkAsBold(cve.Id), vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion))
+			for i, evidence := range cve.Applicability.Evidence {
+				if i == maxEvidencesPerCve {
+					WriteContent(&contentBuilder, fmt.Sprintf("- and %d more", len(cve.Applicability.Evidence)-maxEvidencesPerCve))
+					break
+				}
+				WriteContent(&contentBuilder, getEvidenceContent(evidence))
+			}
+		}
+	}
+	if contentBuilder.Len() == 0 {
+		return ""
+	}
+	return writer.MarkAsDetails(applicabilityEvidenceTitle, 3, contentBuilder.String())
+}
+
+func getEvidenceContent(evidence formats.Evidence) string {
+	location := MarkAsQuote(fmt.Sprintf("%s:%d", evidence.File, evidence.StartLine))
+	snippet := strings.Join(strings.Fields(evidence.Snippet), " ")
+	if snippet == "" {
+		return "- " + location
+	}
+	if len(snippet) > maxEvidenceSnippetLength {
+		snippet = snippet[:maxEvidenceSnippetLength] + "..."
+	}
+	return fmt.Sprintf("- %s %s", location, MarkAsQuote(strings.ReplaceAll(snippet, "`", "'")))
+}
+
 func LicensesContent(licenses []formats.LicenseRow, writer OutputWriter) string {
 	if len(licenses) == 0 {
 		return ""
@@ -377,3 +709,38 @@ func sastDataFlowLocationsReviewContent(flow []formats.Location) string {
 	}
 	return contentBuilder.String()
 }
+
+// PackageStatusRow is a vulnerable package of an aggregated pull request, and whether the pull request fixes it
+type PackageStatusRow struct {
+	PackageName    string
+	CurrentVersion string
+	TargetVersion  string
+	Severity       string
+	Applicable     string
+	// The reason the package is skipped, empty if it's fixed
+	SkipReason string
+}
+
+// PackagesStatusContent lists the packages of an aggregated pull request, and whether each of them was fixed or skipped.
+func PackagesStatusContent(rows []PackageStatusRow, writer OutputWriter) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	table := NewMarkdownTable("PACKAGE", "CURRENT VERSION", "TARGET VERSION", "SEVERITY", "STATUS").SetDelimiter(writer.Separator())
+	for i, row := range rows {
+		if i == maxPackagesStatusRows {
+			break
+		}
+		status := "✅ Fixed"
+		if row.SkipReason != "" {
+			status = "⏭️ Skipped: " + row.SkipReason
+		}
+		table.AddRow(row.PackageName, row.CurrentVersion, row.TargetVersion, writer.FormattedSeverity(row.Severity, row.Applicable), status)
+	}
+	var contentBuilder strings.Builder
+	WriteContent(&contentBuilder, writer.MarkAsTitle(packagesStatusTitle, 3), writer.MarkInCenter(table.Build()))
+	if len(rows) > maxPackagesStatusRows {
+		WriteContent(&contentBuilder, fmt.Sprintf("and %d more packages", len(rows)-maxPackagesStatusRows))
+	}
+	return contentBuilder.String()
+}
@@ -2,6 +2,7 @@ package outputwriter
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jfrog/froggit-go/vcsutils"
@@ -408,6 +409,34 @@ func TestVulnerabilitiesContent(t *testing.T) {
 	}
 }
 
+func TestRedactedVulnerabilitiesContent(t *testing.T) {
+	newVulnerability := func(name, version, severity, cve string) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{
+			Summary:                   "Summary " + cve,
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}, ImpactedDependencyName: name, ImpactedDependencyVersion: version},
+			FixedVersions:             []string{"[9.9.9]"},
+			Cves:                      []formats.CveRow{{Id: cve, CvssV3: "9.8"}},
+			ImpactPaths:               [][]formats.ComponentRow{{{Name: "root"}, {Name: name, Version: version}}},
+		}
+	}
+	vulnerabilities := []formats.VulnerabilityOrViolationRow{
+		newVulnerability("lodash", "4.17.20", "Critical", "CVE-2021-23337"),
+		newVulnerability("lodash", "4.17.20", "High", "CVE-2020-28500"),
+		newVulnerability("minimist", "1.2.5", "Medium", "CVE-2021-44906"),
+	}
+	for _, writer := range []OutputWriter{&StandardOutput{}, &SimplifiedOutput{}} {
+		writer.SetRedacted(true)
+		content := strings.Join(VulnerabilitiesContent(vulnerabilities, writer), "\n")
+		assert.Contains(t, content, "**3** vulnerabilities were detected: 1 Critical, 1 High, 1 Medium")
+		assert.Contains(t, content, "lodash 4.17.20 | 1 Critical, 1 High")
+		assert.Contains(t, content, "minimist 1.2.5 | 1 Medium")
+		for _, omitted := range []string{"CVE-", "9.8", "root", "9.9.9"} {
+			assert.NotContains(t, content, omitted)
+		}
+		assert.NotContains(t, CommitSummaryContent(vulnerabilities, nil, writer), "CVE-")
+	}
+}
+
 func TestLicensesContent(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -95,10 +95,18 @@ type OutputWriter interface {
 	IsEntitledForJas() bool
 	SetAvoidExtraMessages(avoidExtraMessages bool)
 	AvoidExtraMessages() bool
+	SetShowApplicabilityEvidence(showApplicabilityEvidence bool)
+	ShowApplicabilityEvidence() bool
+	SetPullRequestBodySections(sections []string)
+	PullRequestBodySections() []string
 	SetPullRequestCommentTitle(pullRequestCommentTitle string)
 	PullRequestCommentTitle() string
+	SetTestMatrixNote(testMatrixNote string)
+	TestMatrixNote() string
 	SetHasInternetConnection(connected bool)
 	HasInternetConnection() bool
+	SetRedacted(redacted bool)
+	IsRedacted() bool
 	SizeLimit(comment bool) int
 	SetSizeLimit(client vcsclient.VcsClient)
 	// VCS info
@@ -114,14 +122,18 @@ type OutputWriter interface {
 }
 
 type MarkdownOutput struct {
-	pullRequestCommentTitle string
-	avoidExtraMessages      bool
-	showCaColumn            bool
-	entitledForJas          bool
-	hasInternetConnection   bool
-	descriptionSizeLimit    int
-	commentSizeLimit        int
-	vcsProvider             vcsutils.VcsProvider
+	pullRequestCommentTitle   string
+	testMatrixNote            string
+	avoidExtraMessages        bool
+	showApplicabilityEvidence bool
+	pullRequestBodySections   []string
+	showCaColumn              bool
+	entitledForJas            bool
+	hasInternetConnection     bool
+	redacted                  bool
+	descriptionSizeLimit      int
+	commentSizeLimit          int
+	vcsProvider               vcsutils.VcsProvider
 }
 
 type CommentDecorator func(int, string) string
@@ -142,6 +154,36 @@ func (mo *MarkdownOutput) AvoidExtraMessages() bool {
 	return mo.avoidExtraMessages
 }
 
+func (mo *MarkdownOutput) SetShowApplicabilityEvidence(showApplicabilityEvidence bool) {
+	mo.showApplicabilityEvidence = showApplicabilityEvidence
+}
+
+func (mo *MarkdownOutput) ShowApplicabilityEvidence() bool {
+	return mo.showApplicabilityEvidence
+}
+
+func (mo *MarkdownOutput) SetPullRequestBodySections(sections []string) {
+	mo.pullRequestBodySections = sections
+}
+
+// PullRequestBodySections returns the sections of a fix pull request body, in their configured order.
+// If no sections are configured, the default sections are returned.
+func (mo *MarkdownOutput) PullRequestBodySections() []string {
+	if len(mo.pullRequestBodySections) == 0 {
+		return DefaultPullRequestBodySections
+	}
+	return mo.pullRequestBodySections
+}
+
+// SetTestMatrixNote sets a note about the runtime versions the project is tested against, which is added to the fix pull requests body.
+func (mo *MarkdownOutput) SetTestMatrixNote(testMatrixNote string) {
+	mo.testMatrixNote = testMatrixNote
+}
+
+func (mo *MarkdownOutput) TestMatrixNote() string {
+	return mo.testMatrixNote
+}
+
 func (mo *MarkdownOutput) SetHasInternetConnection(connected bool) {
 	mo.hasInternetConnection = connected
 }
@@ -150,6 +192,16 @@ func (mo *MarkdownOutput) HasInternetConnection() bool {
 	return mo.hasInternetConnection
 }
 
+// SetRedacted sets whether the vulnerabilities are rendered without their details, such as CVE ids, CVSS scores and impact paths.
+// A redacted summary only shows the vulnerable packages and the number of their vulnerabilities of each severity, so it can be shared outside the organization.
+func (mo *MarkdownOutput) SetRedacted(redacted bool) {
+	mo.redacted = redacted
+}
+
+func (mo *MarkdownOutput) IsRedacted() bool {
+	return mo.redacted
+}
+
 func (mo *MarkdownOutput) SetJasOutputFlags(entitled, showCaColumn bool) {
 	mo.entitledForJas = entitled
 	mo.showCaColumn = showCaColumn
@@ -194,9 +246,38 @@ func GetMarkdownSizeLimit(client vcsclient.VcsClient) int {
 	return limit
 }
 
-func GetCompatibleOutputWriter(provider vcsutils.VcsProvider) OutputWriter {
+// OutputFormat determines how the messages are styled on the Git provider
+type OutputFormat string
+
+const (
+	// Markdown with HTML tags, such as collapsible <details> sections
+	StandardOutputFormat OutputFormat = "standard"
+	// Plain markdown, for Git providers that don't render HTML tags. Collapsible sections are rendered as flat sections.
+	SimplifiedOutputFormat OutputFormat = "simplified"
+)
+
+// GetProviderOutputFormat returns the output format that is supported by the Git provider.
+func GetProviderOutputFormat(provider vcsutils.VcsProvider) OutputFormat {
 	switch provider {
-	case vcsutils.BitbucketServer:
+	case vcsutils.BitbucketServer, vcsutils.AzureRepos:
+		return SimplifiedOutputFormat
+	default:
+		return StandardOutputFormat
+	}
+}
+
+func GetCompatibleOutputWriter(provider vcsutils.VcsProvider) OutputWriter {
+	return GetOutputWriter(provider, "")
+}
+
+// GetOutputWriter returns the output writer of the provided format.
+// If no format is provided, the format that is supported by the Git provider is used.
+func GetOutputWriter(provider vcsutils.VcsProvider, format OutputFormat) OutputWriter {
+	if format == "" {
+		format = GetProviderOutputFormat(provider)
+	}
+	switch format {
+	case SimplifiedOutputFormat:
 		return &SimplifiedOutput{MarkdownOutput{vcsProvider: provider, hasInternetConnection: true}}
 	default:
 		return &StandardOutput{MarkdownOutput{vcsProvider: provider, hasInternetConnection: true}}
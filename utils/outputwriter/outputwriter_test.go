@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jfrog/froggit-go/vcsutils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,6 +20,29 @@ func TestMarkdownComment(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestGetOutputWriter(t *testing.T) {
+	testCases := []struct {
+		provider       vcsutils.VcsProvider
+		format         OutputFormat
+		expectedWriter OutputWriter
+	}{
+		{provider: vcsutils.GitHub, expectedWriter: &StandardOutput{}},
+		{provider: vcsutils.GitLab, expectedWriter: &StandardOutput{}},
+		{provider: vcsutils.BitbucketCloud, expectedWriter: &StandardOutput{}},
+		{provider: vcsutils.BitbucketServer, expectedWriter: &SimplifiedOutput{}},
+		{provider: vcsutils.AzureRepos, expectedWriter: &SimplifiedOutput{}},
+		{provider: vcsutils.GitHub, format: SimplifiedOutputFormat, expectedWriter: &SimplifiedOutput{}},
+		{provider: vcsutils.AzureRepos, format: StandardOutputFormat, expectedWriter: &StandardOutput{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.provider.String()+"-"+string(tc.format), func(t *testing.T) {
+			writer := GetOutputWriter(tc.provider, tc.format)
+			assert.IsType(t, tc.expectedWriter, writer)
+			assert.Equal(t, tc.provider, writer.VcsProvider())
+		})
+	}
+}
+
 func TestMarkAsBold(t *testing.T) {
 	testCases := []struct {
 		input          string
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Reads the reviewers of the packages from entries in the format of <package-pattern>=<reviewer>,<reviewer>, such as golang.org/x/crypto=alice,my-org/crypto-team
+func parsePackageReviewers(value string) (packageReviewers map[string][]string, err error) {
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		pattern, reviewers, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("the package reviewers entry '%s' is invalid. The expected format is <package-pattern>=<reviewer>,<reviewer>, such as golang.org/x/crypto=alice,my-org/crypto-team", entry)
+		}
+		if packageReviewers == nil {
+			packageReviewers = map[string][]string{}
+		}
+		for _, reviewer := range strings.Split(reviewers, ",") {
+			if reviewer = strings.TrimSpace(reviewer); reviewer != "" {
+				packageReviewers[strings.TrimSpace(pattern)] = append(packageReviewers[strings.TrimSpace(pattern)], reviewer)
+			}
+		}
+	}
+	return
+}
+
+func validatePackageReviewers(packageReviewers map[string][]string) error {
+	for pattern, reviewers := range packageReviewers {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("the package pattern of the reviewers %s is empty", strings.Join(reviewers, ", "))
+		}
+		if len(reviewers) == 0 {
+			return fmt.Errorf("no reviewers are set for the package pattern '%s'", pattern)
+		}
+	}
+	return nil
+}
+
+// GetPackageReviewers returns the sorted reviewers of the packages the vulnerabilities are fixed in.
+// A pattern matches the whole package name case-insensitively, and '*' matches any sequence of characters, such as 'golang.org/x/*' or '@noble/*'.
+func GetPackageReviewers(packageReviewers map[string][]string, vulnerabilities ...*VulnerabilityDetails) []string {
+	reviewers := map[string]bool{}
+	for pattern, patternReviewers := range packageReviewers {
+		patternRegexp := packagePatternToRegexp(pattern)
+		for _, vulnerability := range vulnerabilities {
+			if !patternRegexp.MatchString(vulnerability.ImpactedDependencyName) {
+				continue
+			}
+			for _, reviewer := range patternReviewers {
+				reviewers[strings.TrimPrefix(reviewer, "@")] = true
+			}
+			break
+		}
+	}
+	sortedReviewers := maps.Keys(reviewers)
+	slices.Sort(sortedReviewers)
+	return sortedReviewers
+}
+
+func packagePatternToRegexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+}
+
+// RequestGitHubPullRequestReviewers requests the reviews of the users and teams on the pull request.
+// Teams are given in the format of <organization>/<team-slug>.
+// The VCS client can't request reviewers, so the GitHub REST API is called directly.
+func RequestGitHubPullRequestReviewers(apiEndpoint, token, owner, repo string, pullRequestId int64, reviewers []string) (err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	users, teams := []string{}, []string{}
+	for _, reviewer := range reviewers {
+		if _, team, isTeam := strings.Cut(reviewer, "/"); isTeam {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+	requestBody := map[string]any{"reviewers": users, "team_reviewers": teams}
+	if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPost, fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", getGitHubApiEndpoint(apiEndpoint), owner, repo, pullRequestId), token, requestBody); err != nil {
+		return fmt.Errorf("failed to request the reviews of %s on pull request #%d in %s/%s: %s", strings.Join(reviewers, ", "), pullRequestId, owner, repo, err.Error())
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePackageReviewers(t *testing.T) {
+	packageReviewers, err := parsePackageReviewers(" golang.org/x/crypto = alice, my-org/crypto-team ;@noble/*=bob;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"golang.org/x/crypto": {"alice", "my-org/crypto-team"}, "@noble/*": {"bob"}}, packageReviewers)
+
+	packageReviewers, err = parsePackageReviewers("")
+	require.NoError(t, err)
+	assert.Empty(t, packageReviewers)
+
+	_, err = parsePackageReviewers("golang.org/x/crypto")
+	assert.ErrorContains(t, err, "the package reviewers entry 'golang.org/x/crypto' is invalid")
+
+	assert.ErrorContains(t, validatePackageReviewers(map[string][]string{"golang.org/x/crypto": nil}), "no reviewers are set for the package pattern 'golang.org/x/crypto'")
+	assert.ErrorContains(t, validatePackageReviewers(map[string][]string{" ": {"alice"}}), "the package pattern of the reviewers alice is empty")
+}
+
+func TestGetPackageReviewers(t *testing.T) {
+	packageReviewers := map[string][]string{
+		"golang.org/x/crypto": {"alice", "my-org/crypto-team"},
+		"@noble/*":            {"@bob", "alice"},
+		"openssl":             {"carol"},
+	}
+	vulnerability := func(packageName string) *VulnerabilityDetails {
+		return &VulnerabilityDetails{VulnerabilityOrViolationRow: formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: packageName}}}
+	}
+	testCases := []struct {
+		name              string
+		packages          []string
+		expectedReviewers []string
+	}{
+		{name: "exact match", packages: []string{"golang.org/x/crypto"}, expectedReviewers: []string{"alice", "my-org/crypto-team"}},
+		{name: "wildcard match", packages: []string{"@noble/hashes"}, expectedReviewers: []string{"alice", "bob"}},
+		{name: "case-insensitive match", packages: []string{"OpenSSL"}, expectedReviewers: []string{"carol"}},
+		{name: "partial name doesn't match", packages: []string{"golang.org/x/crypto/v2", "pyopenssl"}, expectedReviewers: []string{}},
+		{name: "union of an aggregated fix", packages: []string{"golang.org/x/crypto", "@noble/curves", "openssl", "lodash"}, expectedReviewers: []string{"alice", "bob", "carol", "my-org/crypto-team"}},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var vulnerabilities []*VulnerabilityDetails
+			for _, packageName := range test.packages {
+				vulnerabilities = append(vulnerabilities, vulnerability(packageName))
+			}
+			assert.Equal(t, test.expectedReviewers, GetPackageReviewers(packageReviewers, vulnerabilities...))
+		})
+	}
+}
@@ -7,13 +7,18 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jfrog/frogbot/v2/utils/outputwriter"
 	securityutils "github.com/jfrog/jfrog-cli-security/utils"
 	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"golang.org/x/exp/slices"
 
 	"github.com/jfrog/build-info-go/utils"
 	"github.com/jfrog/froggit-go/vcsclient"
@@ -32,6 +37,8 @@ var (
 	errFrogbotConfigNotFound = fmt.Errorf("%s wasn't found in the Frogbot directory and its subdirectories. Assuming all the configuration is stored as environment variables", FrogbotConfigFile)
 	// Possible Config file path's to Frogbot Management repository
 	osFrogbotConfigPath = filepath.Join(frogbotConfigDir, FrogbotConfigFile)
+	// Matches a reference to the .git directory, such as 'rm -rf .git' or 'rm -rf ./.git/refs'
+	gitDirReferenceRegexp = regexp.MustCompile(`(^|[\s/'"=])\.git($|[\s/'"])`)
 )
 
 type FrogbotDetails struct {
@@ -52,40 +59,106 @@ type Repository struct {
 	Params       `yaml:"params,omitempty"`
 	OutputWriter outputwriter.OutputWriter
 	Server       coreconfig.ServerDetails
+	// The repository params of the branches that have overrides, by branch name
+	branchRepositories map[string]*Repository
+}
+
+// GetBranchRepository returns the repository with the overrides of the given branch applied.
+// If the branch has no overrides, the repository itself is returned.
+func (r *Repository) GetBranchRepository(branch string) *Repository {
+	if branchRepository, exists := r.branchRepositories[branch]; exists {
+		return branchRepository
+	}
+	return r
 }
 
 func (r *Repository) setOutputWriterDetails() {
-	r.OutputWriter = outputwriter.GetCompatibleOutputWriter(r.Params.GitProvider)
+	r.OutputWriter = outputwriter.GetOutputWriter(r.Params.GitProvider, outputwriter.OutputFormat(r.Params.OutputFormat))
 	r.OutputWriter.SetAvoidExtraMessages(r.Params.AvoidExtraMessages)
 	r.OutputWriter.SetPullRequestCommentTitle(r.Params.PullRequestCommentTitle)
+	r.OutputWriter.SetShowApplicabilityEvidence(r.Params.ShowApplicabilityEvidence)
+	r.OutputWriter.SetPullRequestBodySections(r.Params.PullRequestBodySections)
 }
 
 type Params struct {
-	Scan          `yaml:"scan,omitempty"`
-	Git           `yaml:"git,omitempty"`
-	JFrogPlatform `yaml:"jfrogPlatform,omitempty"`
+	Scan            `yaml:"scan,omitempty"`
+	Git             `yaml:"git,omitempty"`
+	JFrogPlatform   `yaml:"jfrogPlatform,omitempty"`
+	BranchOverrides []BranchOverride `yaml:"branchOverrides,omitempty"`
 }
 
 func (p *Params) setDefaultsIfNeeded(gitParamsFromEnv *Git, commandName string) error {
 	if err := p.Git.setDefaultsIfNeeded(gitParamsFromEnv, commandName); err != nil {
 		return err
 	}
-	if err := p.JFrogPlatform.setDefaultsIfNeeded(); err != nil {
+	if err := p.JFrogPlatform.setDefaultsIfNeeded(p.Git.RepoName); err != nil {
 		return err
 	}
 	return p.Scan.setDefaultsIfNeeded()
 }
 
+// BranchOverride holds scan and git params that override the params of the repository on the matching branches.
+// The precedence of the params is: branch override > repository config > environment variables.
+type BranchOverride struct {
+	// Branch names or patterns, such as release/*
+	Branches []string  `yaml:"branches,omitempty"`
+	Scan     yaml.Node `yaml:"scan,omitempty"`
+	Git      yaml.Node `yaml:"git,omitempty"`
+}
+
+func (bo *BranchOverride) matches(branch string) (bool, error) {
+	for _, pattern := range bo.Branches {
+		matched, err := path.Match(pattern, branch)
+		if err != nil {
+			return false, fmt.Errorf("invalid branch pattern '%s' in %s: %s", pattern, FrogbotConfigFile, err.Error())
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Applies the overrides on top of the given params, so only the fields that are set in the overrides are replaced
+func (bo *BranchOverride) apply(params *Params) (err error) {
+	if !bo.Scan.IsZero() {
+		if err = bo.Scan.Decode(&params.Scan); err != nil {
+			return fmt.Errorf("failed to parse the scan overrides of branches %s: %s", strings.Join(bo.Branches, ", "), err.Error())
+		}
+	}
+	if !bo.Git.IsZero() {
+		if err = bo.Git.Decode(&params.Git); err != nil {
+			return fmt.Errorf("failed to parse the git overrides of branches %s: %s", strings.Join(bo.Branches, ", "), err.Error())
+		}
+	}
+	return
+}
+
 type Project struct {
-	InstallCommand      string   `yaml:"installCommand,omitempty"`
-	PipRequirementsFile string   `yaml:"pipRequirementsFile,omitempty"`
-	WorkingDirs         []string `yaml:"workingDirs,omitempty"`
-	PathExclusions      []string `yaml:"pathExclusions,omitempty"`
-	UseWrapper          *bool    `yaml:"useWrapper,omitempty"`
-	DepsRepo            string   `yaml:"repository,omitempty"`
-	InstallCommandName  string
-	InstallCommandArgs  []string
-	IsRecursiveScan     bool
+	InstallCommand      string            `yaml:"installCommand,omitempty"`
+	InstallCommands     map[string]string `yaml:"installCommands,omitempty"`
+	PipRequirementsFile string            `yaml:"pipRequirementsFile,omitempty"`
+	WorkingDirs         []string          `yaml:"workingDirs,omitempty"`
+	PathExclusions      []string          `yaml:"pathExclusions,omitempty"`
+	UseWrapper          *bool             `yaml:"useWrapper,omitempty"`
+	DepsRepo            string            `yaml:"repository,omitempty"`
+	YarnCacheDir        string            `yaml:"yarnCacheDir,omitempty"`
+	YarnOfflineMirror   bool              `yaml:"yarnOfflineMirror,omitempty"`
+	FixPeerDeps         *bool             `yaml:"fixPeerDeps,omitempty"`
+	UpdateAllLockfiles  bool              `yaml:"updateAllLockfiles,omitempty"`
+	InstallRetries      int               `yaml:"installRetries,omitempty"`
+	InstallTimeout      string            `yaml:"installTimeout,omitempty"`
+	// The time each working directory may be scanned for before its scan is killed and the run continues with the other working directories
+	PerDirTimeout string `yaml:"perDirTimeout,omitempty"`
+	// Regenerates the vendored copies of the fixed dependencies, such as the vendor directory of Go modules and the bundled dependencies of npm packages
+	UpdateVendoredDependencies *bool `yaml:"updateVendoredDependencies,omitempty"`
+	// Surfaces the full output of failed install commands in the log, and their failure in the run summary
+	VerboseInstallErrors bool `yaml:"verboseInstallErrors,omitempty"`
+	// Commands per technology that validate the regenerated lockfile of each fix, before its pull request is opened
+	LockfileValidateCommands map[string]string `yaml:"lockfileValidateCommands,omitempty"`
+	InstallCommandName       string
+	InstallCommandArgs       []string
+	IsRecursiveScan          bool
 }
 
 func (p *Project) setDefaultsIfNeeded() error {
@@ -118,24 +191,310 @@ func (p *Project) setDefaultsIfNeeded() error {
 	if p.InstallCommand != "" {
 		setProjectInstallCommand(p.InstallCommand, p)
 	}
+	if err := p.validateInstallCommands(); err != nil {
+		return err
+	}
+	if err := p.setLockfileValidateCommands(); err != nil {
+		return err
+	}
 	if p.PipRequirementsFile == "" {
 		p.PipRequirementsFile = getTrimmedEnv(RequirementsFileEnv)
 	}
 	if p.DepsRepo == "" {
 		p.DepsRepo = getTrimmedEnv(DepsRepoEnv)
 	}
+	if p.YarnCacheDir == "" {
+		p.YarnCacheDir = getTrimmedEnv(YarnCacheDirEnv)
+	}
+	if p.YarnCacheDir != "" {
+		// The cache directory is shared by all the working directories, so it is resolved before the working directory changes
+		yarnCacheDir, err := filepath.Abs(p.YarnCacheDir)
+		if err != nil {
+			return err
+		}
+		p.YarnCacheDir = yarnCacheDir
+	}
+	if !p.YarnOfflineMirror {
+		yarnOfflineMirror, err := getBoolEnv(YarnOfflineMirrorEnv, false)
+		if err != nil {
+			return err
+		}
+		p.YarnOfflineMirror = yarnOfflineMirror
+	}
+	if p.FixPeerDeps == nil {
+		fixPeerDeps, err := getBoolEnv(FixPeerDepsEnv, true)
+		if err != nil {
+			return err
+		}
+		p.FixPeerDeps = &fixPeerDeps
+	}
+	if !p.UpdateAllLockfiles {
+		updateAllLockfiles, err := getBoolEnv(UpdateAllLockfilesEnv, false)
+		if err != nil {
+			return err
+		}
+		p.UpdateAllLockfiles = updateAllLockfiles
+	}
+	if p.UpdateVendoredDependencies == nil {
+		updateVendoredDependencies, err := getBoolEnv(UpdateVendoredDependenciesEnv, true)
+		if err != nil {
+			return err
+		}
+		p.UpdateVendoredDependencies = &updateVendoredDependencies
+	}
+	if err := p.setInstallRetryPolicy(); err != nil {
+		return err
+	}
+	return p.setPerDirTimeout()
+}
+
+// Reads the number of times a failed install command is retried, and the timeout after which a hung install command is killed
+func (p *Project) setInstallRetryPolicy() error {
+	if p.InstallRetries == 0 {
+		if installRetries := getTrimmedEnv(InstallRetriesEnv); installRetries != "" {
+			var err error
+			if p.InstallRetries, err = strconv.Atoi(installRetries); err != nil {
+				return fmt.Errorf("failed to parse the install retries '%s'. Please provide a number: %s", installRetries, err.Error())
+			}
+		}
+	}
+	if p.InstallRetries < 0 {
+		return fmt.Errorf("the install retries must not be negative, but %d was provided", p.InstallRetries)
+	}
+	if p.InstallTimeout == "" {
+		p.InstallTimeout = getTrimmedEnv(InstallTimeoutEnv)
+	}
+	if !p.VerboseInstallErrors {
+		verboseInstallErrors, err := getBoolEnv(VerboseInstallErrorsEnv, false)
+		if err != nil {
+			return err
+		}
+		p.VerboseInstallErrors = verboseInstallErrors
+	}
+	if p.InstallTimeout != "" {
+		installTimeout, err := time.ParseDuration(p.InstallTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse the install timeout '%s'. Please provide a duration, such as 10m: %s", p.InstallTimeout, err.Error())
+		}
+		if installTimeout <= 0 {
+			return fmt.Errorf("the install timeout must be positive, but %s was provided", p.InstallTimeout)
+		}
+	}
 	return nil
 }
 
+// GetInstallRetryPolicy returns the number of times a failed install command is retried, and the timeout of each attempt.
+// A zero timeout means the install command isn't limited in time.
+func (p *Project) GetInstallRetryPolicy() (retries int, timeout time.Duration) {
+	// The timeout is validated when the project is configured
+	timeout, _ = time.ParseDuration(p.InstallTimeout)
+	return p.InstallRetries, timeout
+}
+
+func (p *Project) setPerDirTimeout() error {
+	if p.PerDirTimeout == "" {
+		p.PerDirTimeout = getTrimmedEnv(PerDirTimeoutEnv)
+	}
+	if p.PerDirTimeout == "" {
+		return nil
+	}
+	perDirTimeout, err := time.ParseDuration(p.PerDirTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse the per directory timeout '%s'. Please provide a duration, such as 15m: %s", p.PerDirTimeout, err.Error())
+	}
+	if perDirTimeout <= 0 {
+		return fmt.Errorf("the per directory timeout must be positive, but %s was provided", p.PerDirTimeout)
+	}
+	return nil
+}
+
+// GetPerDirTimeout returns the time each working directory may be scanned for. Zero means the scans aren't limited in time.
+func (p *Project) GetPerDirTimeout() time.Duration {
+	// The timeout is validated when the project is configured
+	perDirTimeout, _ := time.ParseDuration(p.PerDirTimeout)
+	return perDirTimeout
+}
+
+func (p *Project) validateInstallCommands() error {
+	for tech, installCommand := range p.InstallCommands {
+		if !slices.Contains(techutils.GetAllTechnologiesList(), techutils.Technology(tech)) {
+			return fmt.Errorf("the install command '%s' is set for an unknown technology: %s", installCommand, tech)
+		}
+		if strings.TrimSpace(installCommand) == "" {
+			return fmt.Errorf("the install command of %s is empty", tech)
+		}
+	}
+	return nil
+}
+
+// Reads the lockfile validation commands from entries in the format of <technology>=<command>, such as npm=npm ci --dry-run
+func (p *Project) setLockfileValidateCommands() error {
+	if len(p.LockfileValidateCommands) == 0 {
+		// The commands are split without removing their spaces, unlike the other array parameters
+		for _, entry := range strings.Split(getTrimmedEnv(LockfileValidateCommandEnv), ";") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			tech, command, found := strings.Cut(entry, "=")
+			if !found {
+				return fmt.Errorf("the lockfile validation command '%s' is invalid. The expected format is <technology>=<command>, such as npm=npm ci --dry-run", entry)
+			}
+			if p.LockfileValidateCommands == nil {
+				p.LockfileValidateCommands = map[string]string{}
+			}
+			p.LockfileValidateCommands[strings.TrimSpace(tech)] = strings.TrimSpace(command)
+		}
+	}
+	for tech, command := range p.LockfileValidateCommands {
+		if !slices.Contains(techutils.GetAllTechnologiesList(), techutils.Technology(tech)) {
+			return fmt.Errorf("the lockfile validation command '%s' is set for an unknown technology: %s", command, tech)
+		}
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("the lockfile validation command of %s is empty", tech)
+		}
+		if gitDirReferenceRegexp.MatchString(command) {
+			return fmt.Errorf("the lockfile validation command '%s' must not reference the .git directory", command)
+		}
+	}
+	return nil
+}
+
+// GetLockfileValidateCommand returns the command that validates the lockfile of the given technology, or an empty string if none is set
+func (p *Project) GetLockfileValidateCommand(tech techutils.Technology) string {
+	return p.LockfileValidateCommands[tech.String()]
+}
+
+// GetInstallCommand returns the install command of the given technology.
+// If no install command is set for the technology, the install command of the project is returned.
+func (p *Project) GetInstallCommand(tech techutils.Technology) (name string, args []string) {
+	if installCommand, exists := p.InstallCommands[tech.String()]; exists {
+		parts := strings.Fields(installCommand)
+		return parts[0], parts[1:]
+	}
+	return p.InstallCommandName, p.InstallCommandArgs
+}
+
 type Scan struct {
-	IncludeAllVulnerabilities       bool      `yaml:"includeAllVulnerabilities,omitempty"`
-	FixableOnly                     bool      `yaml:"fixableOnly,omitempty"`
-	FailOnSecurityIssues            *bool     `yaml:"failOnSecurityIssues,omitempty"`
-	AvoidPreviousPrCommentsDeletion bool      `yaml:"avoidPreviousPrCommentsDeletion,omitempty"`
-	MinSeverity                     string    `yaml:"minSeverity,omitempty"`
-	AllowedLicenses                 []string  `yaml:"allowedLicenses,omitempty"`
-	Projects                        []Project `yaml:"projects,omitempty"`
+	IncludeAllVulnerabilities       bool         `yaml:"includeAllVulnerabilities,omitempty"`
+	FixableOnly                     bool         `yaml:"fixableOnly,omitempty"`
+	OnlyNewVulnerabilities          bool         `yaml:"onlyNewVulnerabilities,omitempty"`
+	ResolveFixVersionRanges         bool         `yaml:"resolveFixVersionRanges,omitempty"`
+	PreferStableFixVersion          bool         `yaml:"preferStableFixVersion,omitempty"`
+	ShowApplicabilityEvidence       bool         `yaml:"showApplicabilityEvidence,omitempty"`
+	VerifyAfterMerge                bool         `yaml:"verifyAfterMerge,omitempty"`
+	LinkSecurityAlerts              bool         `yaml:"linkSecurityAlerts,omitempty"`
+	DetectRegressions               bool         `yaml:"detectRegressions,omitempty"`
+	CommentOnCommit                 bool         `yaml:"commentOnCommit,omitempty"`
+	CreateIssuesForUnfixable        bool         `yaml:"createIssuesForUnfixable,omitempty"`
+	DraftOnBreaking                 bool         `yaml:"draftOnBreaking,omitempty"`
+	FixLicenseViolations            bool         `yaml:"fixLicenseViolations,omitempty"`
+	FailOnSecurityIssues            *bool        `yaml:"failOnSecurityIssues,omitempty"`
+	GroupSharedLockfiles            *bool        `yaml:"groupSharedLockfiles,omitempty"`
+	ExcludeWorkspacePackages        *bool        `yaml:"excludeWorkspacePackages,omitempty"`
+	AvoidPreviousPrCommentsDeletion bool         `yaml:"avoidPreviousPrCommentsDeletion,omitempty"`
+	MinSeverity                     string       `yaml:"minSeverity,omitempty"`
+	FixVersionCeilingPolicy         string       `yaml:"fixVersionCeilingPolicy,omitempty"`
+	MaxVersionJump                  string       `yaml:"maxVersionJump,omitempty"`
+	SlaPolicy                       string       `yaml:"slaPolicy,omitempty"`
+	StateRetention                  string       `yaml:"stateRetention,omitempty"`
+	StateDir                        string       `yaml:"stateDir,omitempty"`
+	ApplicabilitySeverityAdjust     string       `yaml:"applicabilitySeverityAdjust,omitempty"`
+	SbomOutput                      string       `yaml:"sbomOutput,omitempty"`
+	InputSbom                       string       `yaml:"inputSbom,omitempty"`
+	FixedSbomOutput                 string       `yaml:"fixedSbomOutput,omitempty"`
+	JunitOutput                     string       `yaml:"junitOutput,omitempty"`
+	JunitFailureSeverity            string       `yaml:"junitFailureSeverity,omitempty"`
+	HtmlReport                      string       `yaml:"htmlReport,omitempty"`
+	CrossBranchReport               string       `yaml:"crossBranchReport,omitempty"`
+	EmitPatches                     string       `yaml:"emitPatches,omitempty"`
+	OnUnsupportedTech               string       `yaml:"onUnsupportedTech,omitempty"`
+	OnPartialScan                   string       `yaml:"onPartialScan,omitempty"`
+	ScanPhaseOrder                  string       `yaml:"scanPhaseOrder,omitempty"`
+	EarlyExitOnCritical             bool         `yaml:"earlyExitOnCritical,omitempty"`
+	BetweenDirsCommand              string       `yaml:"betweenDirsCommand,omitempty"`
+	ResolveCommand                  string       `yaml:"resolveCommand,omitempty"`
+	FixSource                       string       `yaml:"fixSource,omitempty"`
+	CvssVersionPreference           string       `yaml:"cvssVersionPreference,omitempty"`
+	AllowedLicenses                 []string     `yaml:"allowedLicenses,omitempty"`
+	OnlyCves                        []string     `yaml:"onlyCves,omitempty"`
+	ExcludeCves                     []string     `yaml:"excludeCves,omitempty"`
+	VerifyVersionAvailability       []string     `yaml:"verifyVersionAvailability,omitempty"`
+	VerifyProvenance                []string     `yaml:"verifyProvenance,omitempty"`
+	BlockFailedProvenance           bool         `yaml:"blockFailedProvenance,omitempty"`
+	NodeVersion                     string       `yaml:"nodeVersion,omitempty"`
+	PythonVersion                   string       `yaml:"pythonVersion,omitempty"`
+	MetadataLookupPolicy            string       `yaml:"metadataLookupPolicy,omitempty"`
+	IgnoreRules                     []IgnoreRule `yaml:"ignoreRules,omitempty"`
+	Projects                        []Project    `yaml:"projects,omitempty"`
 	EmailDetails                    `yaml:",inline"`
+	NotificationsDetails            `yaml:",inline"`
+}
+
+// Reads the order the SCA and JAS scan phases run in, and whether the later phase is skipped once the earlier one found a Critical issue.
+// The early exit requires the phases to run one after the other, so they run SCA first unless another order is configured.
+func (s *Scan) setScanPhasesDefaults() (err error) {
+	e := &ErrMissingEnv{}
+	if s.ScanPhaseOrder == "" {
+		if err = readParamFromEnv(ScanPhaseOrderEnv, &s.ScanPhaseOrder); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if !s.EarlyExitOnCritical {
+		if s.EarlyExitOnCritical, err = getBoolEnv(EarlyExitOnCriticalEnv, false); err != nil {
+			return
+		}
+	}
+	if s.ScanPhaseOrder == "" {
+		s.ScanPhaseOrder = string(ParallelScanPhaseOrder)
+		if s.EarlyExitOnCritical {
+			s.ScanPhaseOrder = string(ScaFirstScanPhaseOrder)
+		}
+	}
+	if !slices.Contains([]ScanPhaseOrder{ParallelScanPhaseOrder, ScaFirstScanPhaseOrder, JasFirstScanPhaseOrder}, ScanPhaseOrder(s.ScanPhaseOrder)) {
+		return fmt.Errorf("the provided scan phase order '%s' is invalid. Valid values are: %s, %s, %s", s.ScanPhaseOrder, ParallelScanPhaseOrder, ScaFirstScanPhaseOrder, JasFirstScanPhaseOrder)
+	}
+	if s.EarlyExitOnCritical && ScanPhaseOrder(s.ScanPhaseOrder) == ParallelScanPhaseOrder {
+		return fmt.Errorf("exiting the scan early on Critical issues requires the scan phases to run one after the other, but the '%s' scan phase order was provided", ParallelScanPhaseOrder)
+	}
+	return nil
+}
+
+// Reads the versions of the runtimes the fix versions must support, which are validated to be numeric versions, such as 18.19.0
+func (s *Scan) setRuntimeVersionsDefaults() (err error) {
+	e := &ErrMissingEnv{}
+	for env, runtimeVersion := range map[string]*string{NodeVersionEnv: &s.NodeVersion, PythonVersionEnv: &s.PythonVersion} {
+		if *runtimeVersion == "" {
+			if err = readParamFromEnv(env, runtimeVersion); err != nil && !e.IsMissingEnvErr(err) {
+				return
+			}
+		}
+		if *runtimeVersion = strings.TrimPrefix(strings.TrimSpace(*runtimeVersion), "v"); *runtimeVersion != "" && !runtimeVersionRegexp.MatchString(*runtimeVersion) {
+			return fmt.Errorf("the provided %s '%s' is invalid. The expected format is a numeric version, such as 18.19.0", env, *runtimeVersion)
+		}
+	}
+	return nil
+}
+
+// GetRuntimeVersions returns the configured runtime versions by the ecosystems whose packages declare the runtimes they require
+func (s *Scan) GetRuntimeVersions() RuntimeVersions {
+	runtimeVersions := RuntimeVersions{}
+	if s.NodeVersion != "" {
+		runtimeVersions["npm"] = s.NodeVersion
+	}
+	if s.PythonVersion != "" {
+		runtimeVersions["pypi"] = s.PythonVersion
+	}
+	return runtimeVersions
+}
+
+// CVE IDs are compared case-insensitively, so they are kept in upper case
+func normalizeCves(cves []string) (normalized []string) {
+	for _, cve := range cves {
+		if cve = strings.ToUpper(strings.TrimSpace(cve)); cve != "" {
+			normalized = append(normalized, cve)
+		}
+	}
+	return
 }
 
 type EmailDetails struct {
@@ -173,6 +532,26 @@ func (s *Scan) SetEmailDetails() error {
 	return nil
 }
 
+func (s *Scan) SetNotificationsDetails() (err error) {
+	s.SlackWebhook = getTrimmedEnv(SlackWebhookEnv)
+	s.TeamsWebhook = getTrimmedEnv(TeamsWebhookEnv)
+	if s.UpdateWikiPage == "" {
+		s.UpdateWikiPage = getTrimmedEnv(UpdateWikiPageEnv)
+	}
+	if len(s.RedactedSummaryTargets) == 0 {
+		e := &ErrMissingEnv{}
+		if s.RedactedSummaryTargets, err = readArrayParamFromEnv(RedactedSummaryTargetsEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	for _, target := range s.RedactedSummaryTargets {
+		if !slices.Contains([]SummaryTarget{CommitCommentSummaryTarget, SlackSummaryTarget, TeamsSummaryTarget, WikiSummaryTarget}, SummaryTarget(target)) {
+			return fmt.Errorf("the redacted summary target '%s' is invalid. Possible values are: %s, %s, %s, %s", target, CommitCommentSummaryTarget, SlackSummaryTarget, TeamsSummaryTarget, WikiSummaryTarget)
+		}
+	}
+	return nil
+}
+
 func (s *Scan) setDefaultsIfNeeded() (err error) {
 	e := &ErrMissingEnv{}
 	if !s.IncludeAllVulnerabilities {
@@ -190,6 +569,62 @@ func (s *Scan) setDefaultsIfNeeded() (err error) {
 			return
 		}
 	}
+	if !s.OnlyNewVulnerabilities {
+		if s.OnlyNewVulnerabilities, err = getBoolEnv(OnlyNewVulnerabilitiesEnv, false); err != nil {
+			return
+		}
+	}
+	// Off by default, so the fix versions that are expressed as ranges are skipped unless opted in
+	if !s.ResolveFixVersionRanges {
+		if s.ResolveFixVersionRanges, err = getBoolEnv(ResolveFixVersionRangesEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.PreferStableFixVersion {
+		if s.PreferStableFixVersion, err = getBoolEnv(PreferStableFixVersionEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.VerifyAfterMerge {
+		if s.VerifyAfterMerge, err = getBoolEnv(VerifyAfterMergeEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.LinkSecurityAlerts {
+		if s.LinkSecurityAlerts, err = getBoolEnv(LinkSecurityAlertsEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.DetectRegressions {
+		if s.DetectRegressions, err = getBoolEnv(DetectRegressionsEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.CommentOnCommit {
+		if s.CommentOnCommit, err = getBoolEnv(CommentOnCommitEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.CreateIssuesForUnfixable {
+		if s.CreateIssuesForUnfixable, err = getBoolEnv(CreateIssuesForUnfixableEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.DraftOnBreaking {
+		if s.DraftOnBreaking, err = getBoolEnv(DraftOnBreakingEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.ShowApplicabilityEvidence {
+		if s.ShowApplicabilityEvidence, err = getBoolEnv(ShowApplicabilityEvidenceEnv, false); err != nil {
+			return
+		}
+	}
+	if !s.FixLicenseViolations {
+		if s.FixLicenseViolations, err = getBoolEnv(FixLicenseViolationsEnv, false); err != nil {
+			return
+		}
+	}
 	if s.FailOnSecurityIssues == nil {
 		var failOnSecurityIssues bool
 		if failOnSecurityIssues, err = getBoolEnv(FailOnSecurityIssuesEnv, true); err != nil {
@@ -197,6 +632,20 @@ func (s *Scan) setDefaultsIfNeeded() (err error) {
 		}
 		s.FailOnSecurityIssues = &failOnSecurityIssues
 	}
+	if s.GroupSharedLockfiles == nil {
+		var groupSharedLockfiles bool
+		if groupSharedLockfiles, err = getBoolEnv(GroupSharedLockfilesEnv, true); err != nil {
+			return
+		}
+		s.GroupSharedLockfiles = &groupSharedLockfiles
+	}
+	if s.ExcludeWorkspacePackages == nil {
+		var excludeWorkspacePackages bool
+		if excludeWorkspacePackages, err = getBoolEnv(ExcludeWorkspacePackagesEnv, true); err != nil {
+			return
+		}
+		s.ExcludeWorkspacePackages = &excludeWorkspacePackages
+	}
 	if s.MinSeverity == "" {
 		if err = readParamFromEnv(MinSeverityEnv, &s.MinSeverity); err != nil && !e.IsMissingEnvErr(err) {
 			return
@@ -209,6 +658,198 @@ func (s *Scan) setDefaultsIfNeeded() (err error) {
 		}
 		s.MinSeverity = severity.String()
 	}
+	if s.FixVersionCeilingPolicy == "" {
+		if err = readParamFromEnv(FixVersionCeilingPolicyEnv, &s.FixVersionCeilingPolicy); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.FixVersionCeilingPolicy != "" {
+		if s.FixVersionCeilingPolicy != string(SameMajorCeilingPolicy) && s.FixVersionCeilingPolicy != string(SameMinorCeilingPolicy) {
+			return fmt.Errorf("the provided fix version ceiling policy '%s' is invalid. Valid values are: %s, %s", s.FixVersionCeilingPolicy, SameMajorCeilingPolicy, SameMinorCeilingPolicy)
+		}
+	}
+	if s.MaxVersionJump == "" {
+		if err = readParamFromEnv(MaxVersionJumpEnv, &s.MaxVersionJump); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.MaxVersionJump != "" {
+		if _, err = ParseMaxVersionJump(s.MaxVersionJump); err != nil {
+			return
+		}
+	}
+	if len(s.VerifyVersionAvailability) == 0 {
+		if s.VerifyVersionAvailability, err = readArrayParamFromEnv(VerifyVersionAvailabilityEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if _, err = ParseVersionRegistries(s.VerifyVersionAvailability); err != nil {
+		return
+	}
+	if len(s.VerifyProvenance) == 0 {
+		if s.VerifyProvenance, err = readArrayParamFromEnv(VerifyProvenanceEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.VerifyProvenance, err = ParseProvenanceEcosystems(s.VerifyProvenance); err != nil {
+		return
+	}
+	if !s.BlockFailedProvenance {
+		if s.BlockFailedProvenance, err = getBoolEnv(BlockFailedProvenanceEnv, false); err != nil {
+			return
+		}
+	}
+	if err = s.setRuntimeVersionsDefaults(); err != nil {
+		return
+	}
+	if s.SlaPolicy == "" {
+		if err = readParamFromEnv(SlaPolicyEnv, &s.SlaPolicy); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.SlaPolicy != "" {
+		if _, err = ParseSlaPolicy(s.SlaPolicy); err != nil {
+			return
+		}
+	}
+	if s.StateRetention == "" {
+		if err = readParamFromEnv(StateRetentionEnv, &s.StateRetention); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.StateRetention != "" {
+		if _, err = ParseStateRetention(s.StateRetention); err != nil {
+			return
+		}
+	}
+	if s.StateDir == "" {
+		if err = readParamFromEnv(StateDirEnv, &s.StateDir); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.ApplicabilitySeverityAdjust == "" {
+		if err = readParamFromEnv(ApplicabilitySeverityAdjustEnv, &s.ApplicabilitySeverityAdjust); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.ApplicabilitySeverityAdjust != "" {
+		if _, err = ParseApplicabilitySeverityAdjustment(s.ApplicabilitySeverityAdjust); err != nil {
+			return
+		}
+	}
+	if s.SbomOutput == "" {
+		if err = readParamFromEnv(SbomOutputEnv, &s.SbomOutput); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.InputSbom == "" {
+		if err = readParamFromEnv(InputSbomEnv, &s.InputSbom); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.FixedSbomOutput == "" {
+		if err = readParamFromEnv(FixedSbomOutputEnv, &s.FixedSbomOutput); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.JunitOutput == "" {
+		if err = readParamFromEnv(JunitOutputEnv, &s.JunitOutput); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.HtmlReport == "" {
+		if err = readParamFromEnv(HtmlReportEnv, &s.HtmlReport); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.CrossBranchReport == "" {
+		if err = readParamFromEnv(CrossBranchReportEnv, &s.CrossBranchReport); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.EmitPatches == "" {
+		if err = readParamFromEnv(EmitPatchesEnv, &s.EmitPatches); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.JunitFailureSeverity == "" {
+		if err = readParamFromEnv(JunitFailureSeverityEnv, &s.JunitFailureSeverity); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.JunitFailureSeverity != "" {
+		var severity severityutils.Severity
+		if severity, err = severityutils.ParseSeverity(s.JunitFailureSeverity, false); err != nil {
+			return
+		}
+		s.JunitFailureSeverity = severity.String()
+	}
+	if s.OnUnsupportedTech == "" {
+		if err = readParamFromEnv(OnUnsupportedTechEnv, &s.OnUnsupportedTech); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.OnUnsupportedTech != "" && !slices.Contains([]UnsupportedTechPolicy{SkipUnsupportedTechPolicy, WarnUnsupportedTechPolicy, FailUnsupportedTechPolicy}, UnsupportedTechPolicy(s.OnUnsupportedTech)) {
+		return fmt.Errorf("the provided unsupported technology policy '%s' is invalid. Valid values are: %s, %s, %s", s.OnUnsupportedTech, SkipUnsupportedTechPolicy, WarnUnsupportedTechPolicy, FailUnsupportedTechPolicy)
+	}
+	if s.OnPartialScan == "" {
+		if err = readParamFromEnv(OnPartialScanEnv, &s.OnPartialScan); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.OnPartialScan != "" && !slices.Contains([]PartialScanPolicy{FailPartialScanPolicy, WarnAndContinuePartialScanPolicy}, PartialScanPolicy(s.OnPartialScan)) {
+		return fmt.Errorf("the provided partial scan policy '%s' is invalid. Valid values are: %s, %s", s.OnPartialScan, FailPartialScanPolicy, WarnAndContinuePartialScanPolicy)
+	}
+	if s.MetadataLookupPolicy == "" {
+		if err = readParamFromEnv(MetadataLookupPolicyEnv, &s.MetadataLookupPolicy); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+		if s.MetadataLookupPolicy == "" {
+			s.MetadataLookupPolicy = string(BestEffortMetadataLookupPolicy)
+		}
+	}
+	if !slices.Contains([]MetadataLookupPolicy{RequiredMetadataLookupPolicy, BestEffortMetadataLookupPolicy, DisabledMetadataLookupPolicy}, MetadataLookupPolicy(s.MetadataLookupPolicy)) {
+		return fmt.Errorf("the provided metadata lookup policy '%s' is invalid. Valid values are: %s, %s, %s", s.MetadataLookupPolicy, RequiredMetadataLookupPolicy, BestEffortMetadataLookupPolicy, DisabledMetadataLookupPolicy)
+	}
+	if err = s.setScanPhasesDefaults(); err != nil {
+		return
+	}
+	if s.FixSource == "" {
+		if err = readParamFromEnv(FixSourceEnv, &s.FixSource); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if s.FixSource == "" {
+		s.FixSource = string(BothFixSource)
+	}
+	if !slices.Contains([]FixSource{ViolationsFixSource, VulnerabilitiesFixSource, BothFixSource}, FixSource(s.FixSource)) {
+		return fmt.Errorf("the provided fix source '%s' is invalid. Valid values are: %s, %s, %s", s.FixSource, ViolationsFixSource, VulnerabilitiesFixSource, BothFixSource)
+	}
+	if s.CvssVersionPreference == "" {
+		s.CvssVersionPreference = strings.ToLower(getTrimmedEnv(CvssVersionPreferenceEnv))
+	}
+	if s.CvssVersionPreference == "" {
+		s.CvssVersionPreference = string(CvssV3)
+	}
+	if !slices.Contains([]CvssVersion{CvssV3, CvssV2}, CvssVersion(s.CvssVersionPreference)) {
+		return fmt.Errorf("the provided CVSS version preference '%s' is invalid. Valid values are: %s, %s", s.CvssVersionPreference, CvssV3, CvssV2)
+	}
+	if s.BetweenDirsCommand == "" {
+		if err = readParamFromEnv(BetweenDirsCommandEnv, &s.BetweenDirsCommand); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if gitDirReferenceRegexp.MatchString(s.BetweenDirsCommand) {
+		return fmt.Errorf("the command to run between working directories '%s' must not reference the .git directory", s.BetweenDirsCommand)
+	}
+	if s.ResolveCommand == "" {
+		if err = readParamFromEnv(ResolveCommandEnv, &s.ResolveCommand); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if gitDirReferenceRegexp.MatchString(s.ResolveCommand) {
+		return fmt.Errorf("the resolve command '%s' must not reference the .git directory", s.ResolveCommand)
+	}
 	if len(s.Projects) == 0 {
 		s.Projects = append(s.Projects, Project{})
 	}
@@ -217,26 +858,110 @@ func (s *Scan) setDefaultsIfNeeded() (err error) {
 			return
 		}
 	}
+	if len(s.OnlyCves) == 0 {
+		if s.OnlyCves, err = readArrayParamFromEnv(OnlyCvesEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	if len(s.ExcludeCves) == 0 {
+		if s.ExcludeCves, err = readArrayParamFromEnv(ExcludeCvesEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	s.OnlyCves, s.ExcludeCves = normalizeCves(s.OnlyCves), normalizeCves(s.ExcludeCves)
+	if len(s.IgnoreRules) == 0 {
+		if s.IgnoreRules, err = readIgnoreRulesFromEnv(); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	for i := range s.IgnoreRules {
+		if err = s.IgnoreRules[i].validate(); err != nil {
+			return
+		}
+	}
 	for i := range s.Projects {
 		if err = s.Projects[i].setDefaultsIfNeeded(); err != nil {
 			return
 		}
 	}
+	if err = s.SetNotificationsDetails(); err != nil {
+		return
+	}
 	err = s.SetEmailDetails()
 	return
 }
 
 type JFrogPlatform struct {
-	Watches         []string `yaml:"watches,omitempty"`
+	Watches         []string         `yaml:"watches,omitempty"`
+	JFrogProjectKey string           `yaml:"jfrogProjectKey,omitempty"`
+	ProjectMappings []ProjectMapping `yaml:"projectMappings,omitempty"`
+}
+
+// ProjectMapping maps repositories to the JFrog project and watches their scans run in, for Frogbot instances that serve several tenants.
+type ProjectMapping struct {
+	// Repository names or patterns, such as payments-*
+	Repositories    []string `yaml:"repositories,omitempty"`
 	JFrogProjectKey string   `yaml:"jfrogProjectKey,omitempty"`
+	Watches         []string `yaml:"watches,omitempty"`
 }
 
-func (jp *JFrogPlatform) setDefaultsIfNeeded() (err error) {
+func (pm *ProjectMapping) matches(repoName string) (bool, error) {
+	for _, pattern := range pm.Repositories {
+		matched, err := path.Match(pattern, repoName)
+		if err != nil {
+			return false, fmt.Errorf("invalid repository pattern '%s' in the JFrog project mappings: %s", pattern, err.Error())
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Reads the project mappings from the environment, in the format <repository pattern>=<project key>, separated by semicolons
+func readProjectMappingsFromEnv() (projectMappings []ProjectMapping, err error) {
+	mappings, err := readArrayParamFromEnv(ProjectMappingEnv, ";")
+	if err != nil {
+		return
+	}
+	for _, mapping := range mappings {
+		pattern, projectKey, found := strings.Cut(mapping, "=")
+		if !found || strings.TrimSpace(pattern) == "" || strings.TrimSpace(projectKey) == "" {
+			return nil, fmt.Errorf("invalid JFrog project mapping '%s' in %s. The expected format is <repository pattern>=<project key>", mapping, ProjectMappingEnv)
+		}
+		projectMappings = append(projectMappings, ProjectMapping{Repositories: []string{strings.TrimSpace(pattern)}, JFrogProjectKey: strings.TrimSpace(projectKey)})
+	}
+	return
+}
+
+func (jp *JFrogPlatform) setDefaultsIfNeeded(repoName string) (err error) {
 	e := &ErrMissingEnv{}
+	if len(jp.ProjectMappings) == 0 {
+		if jp.ProjectMappings, err = readProjectMappingsFromEnv(); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+		err = nil
+	}
+	for i := range jp.ProjectMappings {
+		var matched bool
+		if matched, err = jp.ProjectMappings[i].matches(repoName); err != nil {
+			return
+		}
+		if matched {
+			// The first matching mapping determines the project of the repository, and its watches if it has any
+			jp.JFrogProjectKey = jp.ProjectMappings[i].JFrogProjectKey
+			if len(jp.ProjectMappings[i].Watches) > 0 {
+				jp.Watches = jp.ProjectMappings[i].Watches
+			}
+			log.Debug(fmt.Sprintf("Repository %s is mapped to the JFrog project '%s'", repoName, jp.JFrogProjectKey))
+			break
+		}
+	}
 	if len(jp.Watches) == 0 {
 		if jp.Watches, err = readArrayParamFromEnv(jfrogWatchesEnv, WatchesDelimiter); err != nil && !e.IsMissingEnvErr(err) {
 			return
 		}
+		err = nil
 	}
 
 	if jp.JFrogProjectKey == "" {
@@ -254,16 +979,45 @@ type Git struct {
 	vcsclient.VcsInfo
 	RepoOwner                string
 	RepoName                 string   `yaml:"repoName,omitempty"`
+	RepoNames                []string `yaml:"-"`
 	Branches                 []string `yaml:"branches,omitempty"`
 	BranchNameTemplate       string   `yaml:"branchNameTemplate,omitempty"`
 	CommitMessageTemplate    string   `yaml:"commitMessageTemplate,omitempty"`
 	PullRequestTitleTemplate string   `yaml:"pullRequestTitleTemplate,omitempty"`
+	AggregateTitleFormat     string   `yaml:"aggregateTitleFormat,omitempty"`
+	IncludeCveInTitle        bool     `yaml:"includeCveInTitle,omitempty"`
 	PullRequestCommentTitle  string   `yaml:"pullRequestCommentTitle,omitempty"`
 	AvoidExtraMessages       bool     `yaml:"avoidExtraMessages,omitempty"`
 	EmailAuthor              string   `yaml:"emailAuthor,omitempty"`
 	AggregateFixes           bool     `yaml:"aggregateFixes,omitempty"`
+	GroupFixesByDir          bool     `yaml:"groupFixesByDir,omitempty"`
+	MinPrUpdateInterval      string   `yaml:"minPrUpdateInterval,omitempty"`
+	AutoRebaseStalePrs       bool     `yaml:"autoRebaseStalePrs,omitempty"`
+	FixPullRequestBranch     bool     `yaml:"fixPullRequestBranch,omitempty"`
+	MaxPackagesPerPr         int      `yaml:"maxPackagesPerPr,omitempty"`
+	PrConcurrency            int      `yaml:"prConcurrency,omitempty"`
+	PullRequestSink          string   `yaml:"pullRequestSink,omitempty"`
+	PullRequestSinkFile      string   `yaml:"pullRequestSinkFile,omitempty"`
+	RepoSubpath              string   `yaml:"repoSubpath,omitempty"`
+	OutputFormat             string   `yaml:"outputFormat,omitempty"`
+	PushRemoteUrl            string   `yaml:"pushRemoteUrl,omitempty"`
+	PushRemoteToken          string   `yaml:"-"`
+	ExtraCommitPaths         []string `yaml:"extraCommitPaths,omitempty"`
+	WriteFixManifest         bool     `yaml:"writeFixManifest,omitempty"`
+	CommitExcludePaths       []string `yaml:"commitExcludePaths,omitempty"`
+	OnExistingBranch         string   `yaml:"onExistingBranch,omitempty"`
+	ChecksumStorage          string   `yaml:"checksumStorage,omitempty"`
+	HashAlgorithm            string   `yaml:"hashAlgorithm,omitempty"`
+	HashLength               int      `yaml:"hashLength,omitempty"`
+	PullRequestBodySections  []string `yaml:"pullRequestBodySections,omitempty"`
+	TestMatrixNote           string   `yaml:"testMatrixNote,omitempty"`
+	TestMatrixNoteFile       string   `yaml:"testMatrixNoteFile,omitempty"`
+	AlwaysScan               bool     `yaml:"alwaysScan,omitempty"`
+	PushChangedFiles         []string `yaml:"-"`
 	PullRequestDetails       vcsclient.PullRequestInfo
 	RepositoryCloneUrl       string
+	// The reviewers requested on the fix pull requests of the packages that match each pattern, in addition to the default reviewers
+	PackageReviewers map[string][]string `yaml:"packageReviewers,omitempty"`
 }
 
 func (g *Git) setDefaultsIfNeeded(gitParamsFromEnv *Git, commandName string) (err error) {
@@ -282,6 +1036,12 @@ func (g *Git) setDefaultsIfNeeded(gitParamsFromEnv *Git, commandName string) (er
 			g.EmailAuthor = frogbotAuthorEmail
 		}
 	}
+	if g.OutputFormat == "" {
+		g.OutputFormat = strings.ToLower(getTrimmedEnv(OutputFormatEnv))
+	}
+	if g.OutputFormat != "" && g.OutputFormat != string(outputwriter.StandardOutputFormat) && g.OutputFormat != string(outputwriter.SimplifiedOutputFormat) {
+		return fmt.Errorf("the provided output format '%s' is invalid. Valid values are: %s, %s", g.OutputFormat, outputwriter.StandardOutputFormat, outputwriter.SimplifiedOutputFormat)
+	}
 	if commandName == ScanPullRequest {
 		if err = g.extractScanPullRequestEnvParams(gitParamsFromEnv); err != nil {
 			return
@@ -309,12 +1069,8 @@ func (g *Git) extractScanPullRequestEnvParams(gitParamsFromEnv *Git) (err error)
 
 func (g *Git) extractScanRepositoryEnvParams(gitParamsFromEnv *Git) (err error) {
 	// Continue to extract ScanRepository related env params
-	noBranchesProvidedViaConfig := len(g.Branches) == 0
-	noBranchesProvidedViaEnv := len(gitParamsFromEnv.Branches) == 0
-	if noBranchesProvidedViaConfig {
-		if noBranchesProvidedViaEnv {
-			return errors.New("no branches were provided. Please set your branches using the `JF_GIT_BASE_BRANCH` environment variable or by configuring them in the frogbot-config.yml file")
-		}
+	// If no branches are provided, the default branch of the repository is detected later
+	if len(g.Branches) == 0 {
 		g.Branches = gitParamsFromEnv.Branches
 	}
 	if g.BranchNameTemplate == "" {
@@ -330,14 +1086,219 @@ func (g *Git) extractScanRepositoryEnvParams(gitParamsFromEnv *Git) (err error)
 	if g.PullRequestTitleTemplate == "" {
 		g.PullRequestTitleTemplate = getTrimmedEnv(PullRequestTitleTemplateEnv)
 	}
+	if g.AggregateTitleFormat == "" {
+		g.AggregateTitleFormat = getTrimmedEnv(AggregateTitleFormatEnv)
+	}
+	if err = validateAggregateTitleFormat(g.AggregateTitleFormat); err != nil {
+		return
+	}
 	if !g.AggregateFixes {
 		if g.AggregateFixes, err = getBoolEnv(GitAggregateFixesEnv, false); err != nil {
 			return
 		}
 	}
+	if !g.GroupFixesByDir {
+		if g.GroupFixesByDir, err = getBoolEnv(GroupFixesByDirEnv, false); err != nil {
+			return
+		}
+	}
+	if g.OnExistingBranch == "" {
+		if g.OnExistingBranch = strings.ToLower(getTrimmedEnv(OnExistingBranchEnv)); g.OnExistingBranch == "" {
+			g.OnExistingBranch = string(SkipExistingBranchPolicy)
+		}
+	}
+	if !slices.Contains([]ExistingBranchPolicy{UpdateExistingBranchPolicy, SkipExistingBranchPolicy, NewExistingBranchPolicy}, ExistingBranchPolicy(g.OnExistingBranch)) {
+		return fmt.Errorf("the provided existing fix branch policy '%s' is invalid. Valid values are: %s, %s, %s", g.OnExistingBranch, UpdateExistingBranchPolicy, SkipExistingBranchPolicy, NewExistingBranchPolicy)
+	}
+	if g.ChecksumStorage == "" {
+		g.ChecksumStorage = strings.ToLower(getTrimmedEnv(ChecksumStorageEnv))
+	}
+	if g.ChecksumStorage == "" {
+		g.ChecksumStorage = string(CommentChecksumStorage)
+	}
+	if !slices.Contains([]ChecksumStorage{CommentChecksumStorage, ZeroWidthChecksumStorage}, ChecksumStorage(g.ChecksumStorage)) {
+		return fmt.Errorf("the provided checksum storage '%s' is invalid. Valid values are: %s, %s", g.ChecksumStorage, CommentChecksumStorage, ZeroWidthChecksumStorage)
+	}
+	if g.HashAlgorithm == "" {
+		if g.HashAlgorithm = strings.ToLower(getTrimmedEnv(HashAlgorithmEnv)); g.HashAlgorithm == "" {
+			g.HashAlgorithm = string(Md5HashAlgorithm)
+		}
+	}
+	if g.HashLength == 0 {
+		if hashLength := getTrimmedEnv(HashLengthEnv); hashLength != "" {
+			if g.HashLength, err = strconv.Atoi(hashLength); err != nil {
+				return fmt.Errorf("failed to parse the hash length '%s'. Please provide a number: %s", hashLength, err.Error())
+			}
+		}
+	}
+	if err = validateHashParams(HashAlgorithm(g.HashAlgorithm), g.HashLength); err != nil {
+		return
+	}
+	if !g.IncludeCveInTitle {
+		if g.IncludeCveInTitle, err = getBoolEnv(IncludeCveInTitleEnv, false); err != nil {
+			return
+		}
+	}
+	if g.MinPrUpdateInterval == "" {
+		g.MinPrUpdateInterval = getTrimmedEnv(MinPrUpdateIntervalEnv)
+	}
+	if g.MinPrUpdateInterval != "" {
+		if _, err = time.ParseDuration(g.MinPrUpdateInterval); err != nil {
+			return fmt.Errorf("failed to parse the minimal pull request update interval '%s'. Please provide a duration, such as 12h: %s", g.MinPrUpdateInterval, err.Error())
+		}
+	}
+	if !g.AutoRebaseStalePrs {
+		if g.AutoRebaseStalePrs, err = getBoolEnv(AutoRebaseStalePrsEnv, false); err != nil {
+			return
+		}
+	}
+	if !g.FixPullRequestBranch {
+		if g.FixPullRequestBranch, err = getBoolEnv(FixPullRequestBranchEnv, false); err != nil {
+			return
+		}
+	}
+	// The pull request ID is inherited by any job of a pull request, so its source branch is fixed only if requested explicitly
+	if g.FixPullRequestBranch && g.PullRequestDetails.ID == 0 {
+		return fmt.Errorf("fixing the source branch of a pull request was requested, but no pull request ID has been provided. Please configure it by using the `%s` environment variable", GitPullRequestIDEnv)
+	}
+	if g.MaxPackagesPerPr == 0 {
+		if maxPackagesPerPr := getTrimmedEnv(MaxPackagesPerPrEnv); maxPackagesPerPr != "" {
+			if g.MaxPackagesPerPr, err = strconv.Atoi(maxPackagesPerPr); err != nil {
+				return fmt.Errorf("failed to parse the maximal number of packages per pull request '%s'. Please provide a number: %s", maxPackagesPerPr, err.Error())
+			}
+		}
+	}
+	if g.MaxPackagesPerPr < 0 {
+		return fmt.Errorf("the maximal number of packages per pull request must not be negative, but %d was provided", g.MaxPackagesPerPr)
+	}
+	if g.PrConcurrency == 0 {
+		if prConcurrency := getTrimmedEnv(PrConcurrencyEnv); prConcurrency != "" {
+			if g.PrConcurrency, err = strconv.Atoi(prConcurrency); err != nil {
+				return fmt.Errorf("failed to parse the number of pull requests to open concurrently '%s'. Please provide a number: %s", prConcurrency, err.Error())
+			}
+		}
+	}
+	if g.PrConcurrency < 0 {
+		return fmt.Errorf("the number of pull requests to open concurrently must not be negative, but %d was provided", g.PrConcurrency)
+	}
+	if g.PullRequestSink == "" {
+		if g.PullRequestSink = strings.ToLower(getTrimmedEnv(PullRequestSinkEnv)); g.PullRequestSink == "" {
+			g.PullRequestSink = string(VcsPullRequestSink)
+		}
+	}
+	if g.PullRequestSink != string(VcsPullRequestSink) && g.PullRequestSink != string(FilePullRequestSink) {
+		return fmt.Errorf("the provided pull request sink '%s' is invalid. Valid values are: %s, %s", g.PullRequestSink, VcsPullRequestSink, FilePullRequestSink)
+	}
+	if g.PullRequestSinkFile == "" {
+		g.PullRequestSinkFile = getTrimmedEnv(PullRequestSinkFileEnv)
+	}
+	if g.PullRequestSink == string(FilePullRequestSink) && g.PullRequestSinkFile == "" {
+		return fmt.Errorf("the %s pull request sink requires a file to write the pull request operations to. Please set it in the %s environment variable", FilePullRequestSink, PullRequestSinkFileEnv)
+	}
+	if g.RepoSubpath == "" {
+		g.RepoSubpath = getTrimmedEnv(RepoSubpathEnv)
+	}
+	if g.RepoSubpath, err = cleanRepoSubpath(g.RepoSubpath); err != nil {
+		return
+	}
+	if g.PushRemoteUrl == "" {
+		g.PushRemoteUrl = getTrimmedEnv(GitPushRemoteUrlEnv)
+	}
+	if g.PushRemoteUrl != "" {
+		// If no dedicated token is provided, the Git token is used to push to the push remote as well
+		if g.PushRemoteToken = getTrimmedEnv(GitPushRemoteTokenEnv); g.PushRemoteToken == "" {
+			g.PushRemoteToken = g.Token
+		}
+	}
+	if len(g.ExtraCommitPaths) == 0 {
+		e := &ErrMissingEnv{}
+		if g.ExtraCommitPaths, err = readArrayParamFromEnv(ExtraCommitPathsEnv, ";"); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	for _, pattern := range g.ExtraCommitPaths {
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("the extra commit path pattern '%s' is invalid: %s", pattern, err.Error())
+		}
+	}
+	if !g.WriteFixManifest {
+		if g.WriteFixManifest, err = getBoolEnv(WriteFixManifestEnv, false); err != nil {
+			return
+		}
+	}
+	if len(g.CommitExcludePaths) == 0 {
+		e := &ErrMissingEnv{}
+		if g.CommitExcludePaths, err = readArrayParamFromEnv(CommitExcludePathsEnv, ";"); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	for _, pattern := range g.CommitExcludePaths {
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("the commit exclude path pattern '%s' is invalid: %s", pattern, err.Error())
+		}
+	}
+	if len(g.PullRequestBodySections) == 0 {
+		e := &ErrMissingEnv{}
+		if g.PullRequestBodySections, err = readArrayParamFromEnv(PullRequestBodySectionsEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return
+		}
+	}
+	g.PullRequestBodySections = getKnownPullRequestBodySections(g.PullRequestBodySections)
+	if g.TestMatrixNote == "" {
+		g.TestMatrixNote = getTrimmedEnv(TestMatrixNoteEnv)
+	}
+	if g.TestMatrixNoteFile == "" {
+		g.TestMatrixNoteFile = getTrimmedEnv(TestMatrixNoteFileEnv)
+	}
+	if g.TestMatrixNote != "" && g.TestMatrixNoteFile != "" {
+		return fmt.Errorf("the test matrix note can be provided either as text or as a file, but both %s and %s were set", TestMatrixNoteEnv, TestMatrixNoteFileEnv)
+	}
+	if !g.AlwaysScan {
+		if g.AlwaysScan, err = getBoolEnv(AlwaysScanEnv, false); err != nil {
+			return
+		}
+	}
+	g.PushChangedFiles = parsePushChangedFiles(getTrimmedEnv(PushChangedFilesEnv))
+	if len(g.PackageReviewers) == 0 {
+		if g.PackageReviewers, err = parsePackageReviewers(getTrimmedEnv(PackageReviewersEnv)); err != nil {
+			return
+		}
+	}
+	return validatePackageReviewers(g.PackageReviewers)
+}
+
+// Returns the known sections of the pull request body, in their configured order.
+// Unknown sections are left out with a warning.
+func getKnownPullRequestBodySections(sections []string) (knownSections []string) {
+	for _, section := range sections {
+		section = strings.ToLower(strings.TrimSpace(section))
+		if !slices.Contains(outputwriter.DefaultPullRequestBodySections, section) {
+			log.Warn(fmt.Sprintf("Ignoring the unknown pull request body section '%s'. Valid sections are: %s", section, strings.Join(outputwriter.DefaultPullRequestBodySections, ", ")))
+			continue
+		}
+		if !slices.Contains(knownSections, section) {
+			knownSections = append(knownSections, section)
+		}
+	}
 	return
 }
 
+// Returns the subpath in a clean form, relative to the repository root.
+// An error is returned if the subpath points outside the repository.
+func cleanRepoSubpath(subpath string) (string, error) {
+	if subpath == "" {
+		return "", nil
+	}
+	cleanSubpath := filepath.ToSlash(filepath.Clean(strings.Trim(filepath.ToSlash(subpath), "/")))
+	if cleanSubpath == "." {
+		return "", nil
+	}
+	if cleanSubpath == ".." || strings.HasPrefix(cleanSubpath, "../") {
+		return "", fmt.Errorf("the repository subpath '%s' points outside the repository. Please set %s to a directory inside the repository", subpath, RepoSubpathEnv)
+	}
+	return cleanSubpath, nil
+}
+
 func validateHashPlaceHolder(template string) error {
 	if template == "" {
 		return nil
@@ -387,14 +1348,37 @@ func GetFrogbotDetails(commandName string) (frogbotDetails *FrogbotDetails, err
 
 // getConfigAggregator returns a RepoAggregator based on frogbot-config.yml and environment variables.
 func getConfigAggregator(gitClient vcsclient.VcsClient, gitParamsFromEnv *Git, jfrogServer *coreconfig.ServerDetails, commandName string) (RepoAggregator, error) {
+	configFilesContent, err := getConfigFilesContent(gitClient, gitParamsFromEnv, commandName)
+	if err != nil {
+		return nil, err
+	}
+	return BuildRepoAggregator(gitClient, configFilesContent, gitParamsFromEnv, jfrogServer, commandName)
+}
+
+// getConfigFilesContent retrieves the content of the config files provided in the JF_CONFIG_FILES environment variable, followed by the content of the frogbot-config.yml file.
+func getConfigFilesContent(gitClient vcsclient.VcsClient, gitParamsFromEnv *Git, commandName string) (configFilesContent [][]byte, err error) {
+	e := &ErrMissingEnv{}
+	configFilesPaths, err := readArrayParamFromEnv(ConfigFilesEnv, ",")
+	if err != nil && !e.IsMissingEnvErr(err) {
+		return
+	}
+	for _, configFilePath := range configFilesPaths {
+		var configFileContent []byte
+		if configFileContent, err = os.ReadFile(filepath.Clean(configFilePath)); err != nil {
+			return nil, fmt.Errorf("an error occurd while reading the config file at: %s\n%s", configFilePath, err.Error())
+		}
+		log.Debug(fmt.Sprintf("The content of %s that will be used is:\n%s", configFilePath, string(configFileContent)))
+		configFilesContent = append(configFilesContent, configFileContent)
+	}
 	configFileContent, err := getConfigFileContent(gitClient, gitParamsFromEnv, commandName)
 	if err != nil {
 		return nil, err
 	}
 	if configFileContent != nil {
 		log.Debug(fmt.Sprintf("The content of %s that will be used is:\n%s", FrogbotConfigFile, string(configFileContent)))
+		configFilesContent = append(configFilesContent, configFileContent)
 	}
-	return BuildRepoAggregator(gitClient, configFileContent, gitParamsFromEnv, jfrogServer, commandName)
+	return
 }
 
 // getConfigFileContent retrieves the content of the frogbot-config.yml file
@@ -420,27 +1404,166 @@ func getConfigFileContent(gitClient vcsclient.VcsClient, gitParamsFromEnv *Git,
 	return configFileContent, err
 }
 
-// BuildRepoAggregator receives the content of a frogbot-config.yml file, along with the Git (built from environment variables) and ServerDetails parameters.
+// BuildRepoAggregator receives the content of the frogbot-config.yml files, along with the Git (built from environment variables) and ServerDetails parameters.
+// The config files are merged in order, so that later config files override earlier ones. See mergeConfigFiles for the merge rules.
 // Returns a RepoAggregator instance with all the defaults and necessary fields.
-func BuildRepoAggregator(gitClient vcsclient.VcsClient, configFileContent []byte, gitParamsFromEnv *Git, server *coreconfig.ServerDetails, commandName string) (resultAggregator RepoAggregator, err error) {
+func BuildRepoAggregator(gitClient vcsclient.VcsClient, configFilesContent [][]byte, gitParamsFromEnv *Git, server *coreconfig.ServerDetails, commandName string) (resultAggregator RepoAggregator, err error) {
+	listMergeStrategy, err := getListMergeStrategy()
+	if err != nil {
+		return
+	}
+	configFileContent, err := mergeConfigFiles(configFilesContent, listMergeStrategy)
+	if err != nil {
+		return
+	}
 	var cleanAggregator RepoAggregator
 	// Unmarshal the frogbot-config.yml file if exists
 	if cleanAggregator, err = unmarshalFrogbotConfigYaml(configFileContent); err != nil {
 		return
 	}
-	for _, repository := range cleanAggregator {
-		repository.Server = *server
-		if err = repository.Params.setDefaultsIfNeeded(gitParamsFromEnv, commandName); err != nil {
+	var repoNames []string
+	if commandName == ScanMultipleRepositories {
+		if repoNames, err = getMultipleRepositoriesNames(gitClient, gitParamsFromEnv); err != nil {
 			return
 		}
-		repository.setOutputWriterDetails()
-		repository.OutputWriter.SetSizeLimit(gitClient)
-		resultAggregator = append(resultAggregator, repository)
+	}
+	for i, repository := range cleanAggregator {
+		if repository.RepoName != "" || len(repoNames) == 0 {
+			if err = repository.build(gitClient, configFileContent, i, gitParamsFromEnv, server, commandName); err != nil {
+				return
+			}
+			resultAggregator = append(resultAggregator, repository)
+			continue
+		}
+		// A config entry without a repository name is applied to each of the provided repositories.
+		// The entry is parsed again for each repository, so the repositories don't share params.
+		for _, repoName := range repoNames {
+			var repositoryAggregator RepoAggregator
+			if repositoryAggregator, err = unmarshalFrogbotConfigYaml(configFileContent); err != nil {
+				return
+			}
+			repoRepository := repositoryAggregator[i]
+			repoRepository.RepoName = repoName
+			if err = repoRepository.build(gitClient, configFileContent, i, gitParamsFromEnv, server, commandName); err != nil {
+				return
+			}
+			resultAggregator = append(resultAggregator, repoRepository)
+		}
 	}
 
 	return
 }
 
+// Sets the defaults and the branch params of the repository built from the config entry at the given index
+func (r *Repository) build(gitClient vcsclient.VcsClient, configFileContent []byte, repositoryIndex int, gitParamsFromEnv *Git, server *coreconfig.ServerDetails, commandName string) (err error) {
+	r.Server = *server
+	if err = r.Params.setDefaultsIfNeeded(gitParamsFromEnv, commandName); err != nil {
+		return
+	}
+	if commandName == ScanRepository || commandName == ScanMultipleRepositories {
+		if err = r.setDefaultBranchIfNeeded(gitClient); err != nil {
+			if commandName == ScanRepository {
+				return
+			}
+			// A repository whose branches couldn't be detected shouldn't fail the scan of the other repositories
+			log.Warn(err.Error())
+			err = nil
+		}
+	}
+	r.setOutputWriterDetails()
+	r.OutputWriter.SetSizeLimit(gitClient)
+	return r.buildBranchRepositories(gitClient, configFileContent, repositoryIndex, gitParamsFromEnv, commandName)
+}
+
+// Returns the repositories to scan in a multi repository run, provided by the JF_GIT_REPOS environment variable.
+// The '*' value lists all the repositories of the owner.
+func getMultipleRepositoriesNames(gitClient vcsclient.VcsClient, gitParamsFromEnv *Git) ([]string, error) {
+	if !slices.Equal(gitParamsFromEnv.RepoNames, []string{allRepositoriesWildcard}) {
+		return gitParamsFromEnv.RepoNames, nil
+	}
+	ownersRepositories, err := gitClient.ListRepositories(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the repositories of %s: %s", gitParamsFromEnv.RepoOwner, err.Error())
+	}
+	repoNames := slices.Clone(ownersRepositories[gitParamsFromEnv.RepoOwner])
+	if len(repoNames) == 0 {
+		return nil, fmt.Errorf("no repositories of %s were found. Please make sure the provided token has access to them", gitParamsFromEnv.RepoOwner)
+	}
+	slices.Sort(repoNames)
+	log.Info(fmt.Sprintf("Scanning %d repositories of %s", len(repoNames), gitParamsFromEnv.RepoOwner))
+	return repoNames, nil
+}
+
+// Scans the default branch of the repository when no branches are provided
+func (r *Repository) setDefaultBranchIfNeeded(gitClient vcsclient.VcsClient) error {
+	if len(r.Branches) > 0 {
+		return nil
+	}
+	repositoryInfo, err := gitClient.GetRepositoryInfo(context.Background(), r.RepoOwner, r.RepoName)
+	if err != nil {
+		return err
+	}
+	defaultBranch, err := GetRemoteDefaultBranch(repositoryInfo.CloneInfo.HTTP, r.Username, r.Token)
+	if err != nil {
+		return fmt.Errorf("no branches were provided, and the default branch of %s/%s couldn't be detected: %s. Please set your branches using the `JF_GIT_BASE_BRANCH` environment variable or by configuring them in the frogbot-config.yml file", r.RepoOwner, r.RepoName, err.Error())
+	}
+	log.Info("No branches were provided. Scanning the default branch of", r.RepoOwner+"/"+r.RepoName+":", defaultBranch)
+	r.Branches = []string{defaultBranch}
+	return nil
+}
+
+// Builds the params of the repository branches that match a branch override.
+// The params of each branch are parsed again from the config file, so the overrides don't affect the params of the other branches.
+func (r *Repository) buildBranchRepositories(gitClient vcsclient.VcsClient, configFileContent []byte, repositoryIndex int, gitParamsFromEnv *Git, commandName string) (err error) {
+	if len(r.BranchOverrides) == 0 {
+		return
+	}
+	r.branchRepositories = make(map[string]*Repository)
+	for _, branch := range r.Branches {
+		var branchOverride *BranchOverride
+		if branchOverride, err = r.getBranchOverride(branch); err != nil {
+			return
+		}
+		if branchOverride == nil {
+			continue
+		}
+		var branchAggregator RepoAggregator
+		if branchAggregator, err = unmarshalFrogbotConfigYaml(configFileContent); err != nil {
+			return
+		}
+		branchRepository := branchAggregator[repositoryIndex]
+		branchRepository.RepoName = r.RepoName
+		branchRepository.Server = r.Server
+		if err = branchOverride.apply(&branchRepository.Params); err != nil {
+			return
+		}
+		branchRepository.Branches = []string{branch}
+		branchRepository.BranchOverrides = nil
+		if err = branchRepository.Params.setDefaultsIfNeeded(gitParamsFromEnv, commandName); err != nil {
+			return
+		}
+		branchRepository.setOutputWriterDetails()
+		branchRepository.OutputWriter.SetSizeLimit(gitClient)
+		log.Debug("Applying the branch overrides of", strings.Join(branchOverride.Branches, ", "), "on branch", branch)
+		r.branchRepositories[branch] = &branchRepository
+	}
+	return
+}
+
+// Returns the first branch override that matches the branch, or nil if no override matches it
+func (r *Repository) getBranchOverride(branch string) (*BranchOverride, error) {
+	for i := range r.BranchOverrides {
+		matched, err := r.BranchOverrides[i].matches(branch)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return &r.BranchOverrides[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // unmarshalFrogbotConfigYaml uses the yaml.Unmarshaler interface to parse the yamlContent.
 // If there is no config file, the function returns a RepoAggregator with an empty repository.
 func unmarshalFrogbotConfigYaml(yamlContent []byte) (result RepoAggregator, err error) {
@@ -521,6 +1644,12 @@ func extractGitParamsFromEnvs(commandName string) (*Git, error) {
 	if err = readParamFromEnv(GitRepoEnv, &gitEnvParams.RepoName); err != nil && commandName != ScanMultipleRepositories {
 		return nil, err
 	}
+	// [Optional] Set the repositories to scan in a multi repository run
+	if commandName == ScanMultipleRepositories {
+		if gitEnvParams.RepoNames, err = readArrayParamFromEnv(GitReposEnv, ","); err != nil && !e.IsMissingEnvErr(err) {
+			return nil, err
+		}
+	}
 
 	// Set Bitbucket Server username
 	// Mandatory only for Bitbucket Server, this authentication detail is required for performing git operations.
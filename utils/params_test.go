@@ -1,22 +1,31 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jfrog/froggit-go/vcsclient"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 
 	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 var (
 	configParamsTestFile          = filepath.Join("..", "testdata", "config", "frogbot-config-test-params.yml")
 	configEmptyScanParamsTestFile = filepath.Join("..", "testdata", "config", "frogbot-config-empty-scan.yml")
+	configBranchOverridesTestFile = filepath.Join("..", "testdata", "config", "frogbot-config-branch-overrides.yml")
 )
 
 func TestExtractParamsFromEnvError(t *testing.T) {
@@ -175,7 +184,7 @@ func TestExtractAndAssertRepoParams(t *testing.T) {
 	assert.NoError(t, err)
 	configFileContent, err := ReadConfigFromFileSystem(configParamsTestFile)
 	assert.NoError(t, err)
-	configAggregator, err := BuildRepoAggregator(nil, configFileContent, gitParams, server, ScanRepository)
+	configAggregator, err := BuildRepoAggregator(nil, [][]byte{configFileContent}, gitParams, server, ScanRepository)
 	assert.NoError(t, err)
 	for _, repo := range configAggregator {
 		for projectI, project := range repo.Projects {
@@ -202,6 +211,89 @@ func TestExtractAndAssertRepoParams(t *testing.T) {
 	}
 }
 
+func TestBuildRepoAggregatorWithMultipleConfigFiles(t *testing.T) {
+	baseConfig := []byte(`
+- params:
+    git:
+      repoName: frogbot
+      branches: [master]
+      emailAuthor: org@jfrog.com
+    scan:
+      minSeverity: Low
+      fixableOnly: true
+      allowedLicenses: [MIT, Apache-2.0]
+    jfrogPlatform:
+      watches: [org-watch]
+`)
+	repoConfig := []byte(`
+- params:
+    git:
+      repoName: frogbot
+      emailAuthor: repo@jfrog.com
+    scan:
+      minSeverity: High
+      allowedLicenses: [ISC, MIT]
+- params:
+    git:
+      repoName: other-repo
+      branches: [main]
+`)
+	testCases := []struct {
+		strategy                ListMergeStrategy
+		expectedAllowedLicenses []string
+	}{
+		{strategy: "", expectedAllowedLicenses: []string{"ISC", "MIT"}},
+		{strategy: ReplaceListMergeStrategy, expectedAllowedLicenses: []string{"ISC", "MIT"}},
+		{strategy: AppendListMergeStrategy, expectedAllowedLicenses: []string{"MIT", "Apache-2.0", "ISC"}},
+	}
+	for _, test := range testCases {
+		t.Run(string(test.strategy), func(t *testing.T) {
+			SetEnvAndAssert(t, map[string]string{
+				JFrogUrlEnv:                "http://127.0.0.1:8081",
+				JFrogTokenEnv:              "token",
+				GitProvider:                string(GitHub),
+				GitRepoOwnerEnv:            "jfrog",
+				GitRepoEnv:                 "frogbot",
+				GitTokenEnv:                "123456789",
+				ConfigListMergeStrategyEnv: string(test.strategy),
+			})
+			defer func() {
+				assert.NoError(t, SanitizeEnv())
+			}()
+			server, err := extractJFrogCredentialsFromEnvs()
+			assert.NoError(t, err)
+			gitParams, err := extractGitParamsFromEnvs(ScanRepository)
+			assert.NoError(t, err)
+			configAggregator, err := BuildRepoAggregator(nil, [][]byte{baseConfig, repoConfig}, gitParams, server, ScanRepository)
+			require.NoError(t, err)
+			require.Len(t, configAggregator, 2)
+
+			repo := configAggregator[0]
+			assert.Equal(t, "frogbot", repo.RepoName)
+			// Scalars of the later config file override the earlier ones
+			assert.Equal(t, "repo@jfrog.com", repo.EmailAuthor)
+			assert.Equal(t, "High", repo.MinSeverity)
+			// Fields that are missing in the later config file are kept
+			assert.True(t, repo.FixableOnly)
+			assert.Equal(t, []string{"master"}, repo.Branches)
+			assert.Equal(t, []string{"org-watch"}, repo.Watches)
+			// Lists are merged according to the list merge strategy
+			assert.Equal(t, test.expectedAllowedLicenses, repo.AllowedLicenses)
+
+			// Repositories that exist only in the later config file are added
+			assert.Equal(t, "other-repo", configAggregator[1].RepoName)
+			assert.Equal(t, []string{"main"}, configAggregator[1].Branches)
+		})
+	}
+
+	SetEnvAndAssert(t, map[string]string{ConfigListMergeStrategyEnv: "merge"})
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+	_, err := BuildRepoAggregator(nil, [][]byte{baseConfig, repoConfig}, &Git{}, &config.ServerDetails{}, ScanRepository)
+	assert.ErrorContains(t, err, "merge")
+}
+
 func TestBuildRepoAggregatorWithEmptyScan(t *testing.T) {
 	SetEnvAndAssert(t, map[string]string{
 		JFrogUrlEnv:     "http://127.0.0.1:8081",
@@ -220,7 +312,7 @@ func TestBuildRepoAggregatorWithEmptyScan(t *testing.T) {
 	assert.NoError(t, err)
 	configFileContent, err := ReadConfigFromFileSystem(configEmptyScanParamsTestFile)
 	assert.NoError(t, err)
-	configAggregator, err := BuildRepoAggregator(nil, configFileContent, gitParams, server, ScanRepository)
+	configAggregator, err := BuildRepoAggregator(nil, [][]byte{configFileContent}, gitParams, server, ScanRepository)
 	assert.NoError(t, err)
 	assert.Len(t, configAggregator, 1)
 	assert.Equal(t, frogbotAuthorEmail, configAggregator[0].EmailAuthor)
@@ -242,6 +334,92 @@ func TestBuildRepoAggregatorWithEmptyScan(t *testing.T) {
 	assert.True(t, *project.UseWrapper)
 }
 
+func TestBuildRepoAggregatorWithBranchOverrides(t *testing.T) {
+	SetEnvAndAssert(t, map[string]string{
+		JFrogUrlEnv:     "http://127.0.0.1:8081",
+		JFrogTokenEnv:   "token",
+		GitProvider:     string(GitHub),
+		GitRepoOwnerEnv: "jfrog",
+		GitRepoEnv:      "frogbot",
+		GitTokenEnv:     "123456789",
+	})
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+	server, err := extractJFrogCredentialsFromEnvs()
+	assert.NoError(t, err)
+	gitParams, err := extractGitParamsFromEnvs(ScanRepository)
+	assert.NoError(t, err)
+	configFileContent, err := ReadConfigFromFileSystem(configBranchOverridesTestFile)
+	assert.NoError(t, err)
+	configAggregator, err := BuildRepoAggregator(nil, [][]byte{configFileContent}, gitParams, server, ScanRepository)
+	require.NoError(t, err)
+	require.Len(t, configAggregator, 1)
+	repository := &configAggregator[0]
+
+	// The main branch uses the params of the repository
+	mainRepository := repository.GetBranchRepository("main")
+	assert.Same(t, repository, mainRepository)
+	assert.True(t, mainRepository.AggregateFixes)
+	assert.Empty(t, mainRepository.MinSeverity)
+
+	// The release branch uses the overrides, along with the params of the repository that weren't overridden
+	releaseRepository := repository.GetBranchRepository("release/1.0")
+	assert.NotSame(t, mainRepository, releaseRepository)
+	assert.False(t, releaseRepository.AggregateFixes)
+	assert.Equal(t, "High", releaseRepository.MinSeverity)
+	assert.True(t, releaseRepository.FixableOnly)
+	assert.Equal(t, []string{"release/1.0"}, releaseRepository.Branches)
+	assert.Equal(t, "branch-overrides-proj", releaseRepository.RepoName)
+	require.Len(t, releaseRepository.Projects, 1)
+	assert.Equal(t, []string{"a"}, releaseRepository.Projects[0].WorkingDirs)
+	assert.NotNil(t, releaseRepository.OutputWriter)
+	assert.Equal(t, server.Url, releaseRepository.Server.Url)
+}
+
+func TestBuildRepoAggregatorWithRepoNames(t *testing.T) {
+	SetEnvAndAssert(t, map[string]string{
+		JFrogUrlEnv:      "http://127.0.0.1:8081",
+		JFrogTokenEnv:    "token",
+		GitProvider:      string(GitHub),
+		GitRepoOwnerEnv:  "jfrog",
+		GitReposEnv:      "repo-b, repo-a",
+		GitTokenEnv:      "123456789",
+		GitBaseBranchEnv: "master",
+	})
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+	server, err := extractJFrogCredentialsFromEnvs()
+	assert.NoError(t, err)
+	gitParams, err := extractGitParamsFromEnvs(ScanMultipleRepositories)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"repo-b", "repo-a"}, gitParams.RepoNames)
+	configFileContent := []byte(`
+- params:
+    git:
+      repoName: configured-repo
+- params:
+    scan:
+      minSeverity: High
+`)
+	configAggregator, err := BuildRepoAggregator(nil, [][]byte{configFileContent}, gitParams, server, ScanMultipleRepositories)
+	require.NoError(t, err)
+	require.Len(t, configAggregator, 3)
+
+	// The config entry with a repository name is kept as is
+	assert.Equal(t, "configured-repo", configAggregator[0].RepoName)
+	assert.Empty(t, configAggregator[0].MinSeverity)
+	// The config entry without a repository name is applied to each of the provided repositories
+	for i, repoName := range []string{"repo-b", "repo-a"} {
+		repository := configAggregator[i+1]
+		assert.Equal(t, repoName, repository.RepoName)
+		assert.Equal(t, "jfrog", repository.RepoOwner)
+		assert.Equal(t, "High", repository.MinSeverity)
+		assert.Equal(t, []string{"master"}, repository.Branches)
+	}
+}
+
 func testExtractAndAssertProjectParams(t *testing.T, project Project) {
 	assert.Equal(t, "nuget", project.InstallCommandName)
 	assert.Equal(t, []string{"restore"}, project.InstallCommandArgs)
@@ -288,6 +466,41 @@ func extractAndAssertParamsFromEnv(t *testing.T, platformUrl, basicAuth bool, co
 	}
 }
 
+// A version control client that only returns the info of the repository
+type repositoryInfoClient struct {
+	vcsclient.VcsClient
+	repositoryInfo vcsclient.RepositoryInfo
+}
+
+func (c *repositoryInfoClient) GetRepositoryInfo(context.Context, string, string) (vcsclient.RepositoryInfo, error) {
+	return c.repositoryInfo, nil
+}
+
+func TestSetDefaultBranchIfNeeded(t *testing.T) {
+	// The default branch of the remote repository is 'main'
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInitWithOptions(remoteDir, &git.PlainInitOptions{InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")}})
+	require.NoError(t, err)
+	worktree, err := remote.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(remoteDir, "README.md"), []byte("frogbot"), 0644))
+	_, err = worktree.Add("README.md")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{Author: &object.Signature{Name: "frogbot", Email: frogbotAuthorEmail}})
+	require.NoError(t, err)
+	// The RepositoryInfo of the VCS client has no default branch, so the client provides only the clone URL the default branch is listed from
+	client := &repositoryInfoClient{repositoryInfo: vcsclient.RepositoryInfo{CloneInfo: vcsclient.CloneInfo{HTTP: remoteDir}}}
+
+	repository := &Repository{Params: Params{Git: Git{RepoOwner: "jfrog", RepoName: "frogbot"}}}
+	require.NoError(t, repository.setDefaultBranchIfNeeded(client))
+	assert.Equal(t, []string{"main"}, repository.Branches)
+
+	// Explicitly provided branches are scanned as is
+	repository.Branches = []string{"master"}
+	require.NoError(t, repository.setDefaultBranchIfNeeded(client))
+	assert.Equal(t, []string{"master"}, repository.Branches)
+}
+
 func TestExtractInstallationCommandFromEnv(t *testing.T) {
 	defer func() {
 		assert.NoError(t, SanitizeEnv())
@@ -321,6 +534,448 @@ func TestExtractInstallationCommandFromEnv(t *testing.T) {
 	assert.Equal(t, []string{"b", "--flagName=flagValue"}, project.InstallCommandArgs)
 }
 
+func TestProjectInstallCommandPerTechnology(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	var project Project
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+installCommand: npm ci
+installCommands:
+  npm: npm install --legacy-peer-deps
+  pip: pip install -r requirements-dev.txt
+`), &project))
+	assert.NoError(t, project.setDefaultsIfNeeded())
+
+	name, args := project.GetInstallCommand(techutils.Npm)
+	assert.Equal(t, "npm", name)
+	assert.Equal(t, []string{"install", "--legacy-peer-deps"}, args)
+	name, args = project.GetInstallCommand(techutils.Pip)
+	assert.Equal(t, "pip", name)
+	assert.Equal(t, []string{"install", "-r", "requirements-dev.txt"}, args)
+	// Technologies without an install command fall back to the install command of the project
+	name, args = project.GetInstallCommand(techutils.Go)
+	assert.Equal(t, "npm", name)
+	assert.Equal(t, []string{"ci"}, args)
+
+	project = Project{InstallCommands: map[string]string{"unknown": "unknown install"}}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "unknown technology")
+	project = Project{InstallCommands: map[string]string{"npm": " "}}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "the install command of npm is empty")
+}
+
+func TestProjectInstallRetryPolicy(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	project := &Project{}
+	assert.NoError(t, project.setDefaultsIfNeeded())
+	retries, timeout := project.GetInstallRetryPolicy()
+	assert.Zero(t, retries)
+	assert.Zero(t, timeout)
+
+	project = &Project{}
+	SetEnvAndAssert(t, map[string]string{InstallRetriesEnv: "3", InstallTimeoutEnv: "10m"})
+	assert.NoError(t, project.setDefaultsIfNeeded())
+	retries, timeout = project.GetInstallRetryPolicy()
+	assert.Equal(t, 3, retries)
+	assert.Equal(t, 10*time.Minute, timeout)
+
+	project = &Project{}
+	SetEnvAndAssert(t, map[string]string{InstallRetriesEnv: "many"})
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "failed to parse the install retries 'many'")
+	project = &Project{InstallRetries: -1}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "the install retries must not be negative")
+	project = &Project{InstallRetries: 1, InstallTimeout: "forever"}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "failed to parse the install timeout 'forever'")
+	project = &Project{InstallRetries: 1, InstallTimeout: "-1m"}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "the install timeout must be positive")
+}
+
+func TestProjectPerDirTimeout(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	project := &Project{}
+	assert.NoError(t, project.setDefaultsIfNeeded())
+	assert.Zero(t, project.GetPerDirTimeout())
+
+	project = &Project{}
+	SetEnvAndAssert(t, map[string]string{PerDirTimeoutEnv: "15m"})
+	assert.NoError(t, project.setDefaultsIfNeeded())
+	assert.Equal(t, 15*time.Minute, project.GetPerDirTimeout())
+
+	project = &Project{PerDirTimeout: "forever"}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "failed to parse the per directory timeout 'forever'")
+	project = &Project{PerDirTimeout: "0s"}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "the per directory timeout must be positive")
+}
+
+func TestProjectLockfileValidateCommands(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	project := &Project{}
+	SetEnvAndAssert(t, map[string]string{LockfileValidateCommandEnv: "npm=npm ci --dry-run; go=go mod verify"})
+	assert.NoError(t, project.setDefaultsIfNeeded())
+	assert.Equal(t, "npm ci --dry-run", project.GetLockfileValidateCommand(techutils.Npm))
+	assert.Equal(t, "go mod verify", project.GetLockfileValidateCommand(techutils.Go))
+	assert.Empty(t, project.GetLockfileValidateCommand(techutils.Pip))
+
+	project = &Project{}
+	SetEnvAndAssert(t, map[string]string{LockfileValidateCommandEnv: "npm ci --dry-run"})
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "the lockfile validation command 'npm ci --dry-run' is invalid")
+	project = &Project{LockfileValidateCommands: map[string]string{"cobol": "make verify"}}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "is set for an unknown technology: cobol")
+	project = &Project{LockfileValidateCommands: map[string]string{"npm": "rm -rf .git"}}
+	assert.ErrorContains(t, project.setDefaultsIfNeeded(), "must not reference the .git directory")
+}
+
+func TestExtractRedactedSummaryTargetsFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.SetNotificationsDetails())
+	assert.False(t, scan.IsRedacted(SlackSummaryTarget))
+
+	SetEnvAndAssert(t, map[string]string{RedactedSummaryTargetsEnv: "teams,commitComment"})
+	scan = &Scan{}
+	assert.NoError(t, scan.SetNotificationsDetails())
+	assert.False(t, scan.IsRedacted(SlackSummaryTarget))
+	assert.True(t, scan.IsRedacted(TeamsSummaryTarget))
+	assert.True(t, scan.IsRedacted(CommitCommentSummaryTarget))
+
+	SetEnvAndAssert(t, map[string]string{RedactedSummaryTargetsEnv: "mirror"})
+	scan = &Scan{}
+	assert.ErrorContains(t, scan.SetNotificationsDetails(), "the redacted summary target 'mirror' is invalid")
+}
+
+func TestExtractBetweenDirsCommandFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	SetEnvAndAssert(t, map[string]string{BetweenDirsCommandEnv: "git clean -fdx node_modules"})
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, "git clean -fdx node_modules", scan.BetweenDirsCommand)
+
+	for _, command := range []string{"rm -rf .git", "rm -rf ./.git/refs", "find . -path '.git' -delete"} {
+		SetEnvAndAssert(t, map[string]string{BetweenDirsCommandEnv: command})
+		scan = &Scan{}
+		assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "must not reference the .git directory", command)
+	}
+
+	// Files that only start with .git may be referenced
+	SetEnvAndAssert(t, map[string]string{BetweenDirsCommandEnv: "cat .gitignore"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+}
+
+func TestExtractFixSourceFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, string(BothFixSource), scan.FixSource)
+
+	SetEnvAndAssert(t, map[string]string{FixSourceEnv: "violations"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, string(ViolationsFixSource), scan.FixSource)
+
+	SetEnvAndAssert(t, map[string]string{FixSourceEnv: "policies"})
+	scan = &Scan{}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the provided fix source 'policies' is invalid")
+}
+
+func TestExtractFixVersionCeilingPolicyFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Empty(t, scan.FixVersionCeilingPolicy)
+
+	scan = &Scan{}
+	SetEnvAndAssert(t, map[string]string{FixVersionCeilingPolicyEnv: "same-major"})
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, string(SameMajorCeilingPolicy), scan.FixVersionCeilingPolicy)
+
+	scan = &Scan{}
+	SetEnvAndAssert(t, map[string]string{FixVersionCeilingPolicyEnv: "same-patch"})
+	assert.Error(t, scan.setDefaultsIfNeeded())
+}
+
+func TestExtractIgnoreRulesFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Empty(t, scan.IgnoreRules)
+
+	scan = &Scan{}
+	SetEnvAndAssert(t, map[string]string{IgnoredDependencyPathsEnv: "jest, karma > *"})
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, []IgnoreRule{{DependencyPath: "jest"}, {DependencyPath: "karma>*"}}, scan.IgnoreRules)
+
+	// The rules of the config file take precedence over the environment
+	scan = &Scan{IgnoreRules: []IgnoreRule{{DependencyPath: "mocha", Reason: "Test harness"}}}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, []IgnoreRule{{DependencyPath: "mocha", Reason: "Test harness"}}, scan.IgnoreRules)
+
+	scan = &Scan{IgnoreRules: []IgnoreRule{{DependencyPath: "jest > [a-"}}}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "invalid dependency path pattern 'jest > [a-'")
+
+	scan = &Scan{IgnoreRules: []IgnoreRule{{Reason: "Test harness"}}}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the dependency path of the ignore rule 'Test harness' is empty")
+}
+
+func TestExtractVersionRegistriesFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	SetEnvAndAssert(t, map[string]string{VerifyVersionAvailabilityEnv: "npm=https://registry.npmjs.org, NPM=https://mirror.example.com/npm/, go=https://proxy.golang.org"})
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	versionRegistries, err := ParseVersionRegistries(scan.VerifyVersionAvailability)
+	assert.NoError(t, err)
+	ecosystem, registries := versionRegistries.GetRegistries(techutils.Pnpm)
+	assert.Equal(t, "npm", ecosystem)
+	assert.Equal(t, []string{"https://registry.npmjs.org", "https://mirror.example.com/npm"}, registries)
+	_, registries = versionRegistries.GetRegistries(techutils.Maven)
+	assert.Empty(t, registries)
+
+	scan = &Scan{VerifyVersionAvailability: []string{"cargo=https://crates.io"}}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the ecosystem 'cargo' of the provided version registry is invalid. Valid ecosystems are: go, maven, npm, nuget, pypi")
+	scan = &Scan{VerifyVersionAvailability: []string{"https://registry.npmjs.org"}}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the provided version registry 'https://registry.npmjs.org' is invalid")
+}
+
+func TestExtractProvenanceParamsFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Empty(t, scan.VerifyProvenance)
+	assert.False(t, scan.BlockFailedProvenance)
+
+	SetEnvAndAssert(t, map[string]string{VerifyProvenanceEnv: "npm, PyPI", BlockFailedProvenanceEnv: "true"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, []string{"npm", "pypi"}, scan.VerifyProvenance)
+	assert.True(t, scan.BlockFailedProvenance)
+
+	scan = &Scan{VerifyProvenance: []string{"maven"}}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "verifying the provenance of the maven ecosystem isn't supported. Valid ecosystems are: npm, pypi")
+}
+
+func TestExtractRuntimeVersionsFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Empty(t, scan.GetRuntimeVersions())
+
+	SetEnvAndAssert(t, map[string]string{NodeVersionEnv: "v18.19.0", PythonVersionEnv: "3.10"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, RuntimeVersions{"npm": "18.19.0", "pypi": "3.10"}, scan.GetRuntimeVersions())
+
+	scan = &Scan{NodeVersion: "lts/hydrogen"}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the provided JF_NODE_VERSION 'lts/hydrogen' is invalid")
+}
+
+func TestExtractMetadataLookupPolicyFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, string(BestEffortMetadataLookupPolicy), scan.MetadataLookupPolicy)
+
+	scan = &Scan{}
+	SetEnvAndAssert(t, map[string]string{MetadataLookupPolicyEnv: "required"})
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, string(RequiredMetadataLookupPolicy), scan.MetadataLookupPolicy)
+
+	scan = &Scan{}
+	SetEnvAndAssert(t, map[string]string{MetadataLookupPolicyEnv: "strict"})
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the provided metadata lookup policy 'strict' is invalid")
+}
+
+func TestExtractFixPullRequestBranchFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	// The ID of the pull request alone doesn't fix its source branch
+	git := &Git{RepoName: "frogbot"}
+	assert.NoError(t, git.setDefaultsIfNeeded(&Git{PullRequestDetails: vcsclient.PullRequestInfo{ID: 7}}, ScanRepository))
+	assert.False(t, git.FixPullRequestBranch)
+
+	SetEnvAndAssert(t, map[string]string{FixPullRequestBranchEnv: "TRUE"})
+	git = &Git{RepoName: "frogbot"}
+	assert.NoError(t, git.setDefaultsIfNeeded(&Git{PullRequestDetails: vcsclient.PullRequestInfo{ID: 7}}, ScanRepository))
+	assert.True(t, git.FixPullRequestBranch)
+
+	git = &Git{RepoName: "frogbot"}
+	assert.ErrorContains(t, git.setDefaultsIfNeeded(&Git{}, ScanRepository), "no pull request ID has been provided")
+}
+
+func TestExtractStateRetentionFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	SetEnvAndAssert(t, map[string]string{StateRetentionEnv: "90d"})
+	scan := &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, "90d", scan.StateRetention)
+
+	SetEnvAndAssert(t, map[string]string{StateDirEnv: "/var/lib/frogbot/state"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setDefaultsIfNeeded())
+	assert.Equal(t, "/var/lib/frogbot/state", scan.StateDir)
+
+	scan = &Scan{StateRetention: "quarter"}
+	assert.ErrorContains(t, scan.setDefaultsIfNeeded(), "the provided state retention 'quarter' is invalid")
+}
+
+func TestExtractScanPhasesFromEnv(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	scan := &Scan{}
+	assert.NoError(t, scan.setScanPhasesDefaults())
+	assert.Equal(t, string(ParallelScanPhaseOrder), scan.ScanPhaseOrder)
+	assert.False(t, scan.EarlyExitOnCritical)
+
+	// Exiting early requires the phases to run one after the other
+	SetEnvAndAssert(t, map[string]string{EarlyExitOnCriticalEnv: "true"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setScanPhasesDefaults())
+	assert.Equal(t, string(ScaFirstScanPhaseOrder), scan.ScanPhaseOrder)
+	assert.True(t, scan.EarlyExitOnCritical)
+
+	SetEnvAndAssert(t, map[string]string{ScanPhaseOrderEnv: "jas-first"})
+	scan = &Scan{}
+	assert.NoError(t, scan.setScanPhasesDefaults())
+	assert.Equal(t, string(JasFirstScanPhaseOrder), scan.ScanPhaseOrder)
+
+	scan = &Scan{ScanPhaseOrder: "parallel"}
+	assert.ErrorContains(t, scan.setScanPhasesDefaults(), "exiting the scan early on Critical issues requires the scan phases to run one after the other")
+	scan = &Scan{ScanPhaseOrder: "sast-first"}
+	assert.ErrorContains(t, scan.setScanPhasesDefaults(), "the provided scan phase order 'sast-first' is invalid")
+}
+
+func TestJFrogPlatformProjectMappings(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SanitizeEnv())
+	}()
+
+	var jfrogPlatform JFrogPlatform
+	require.NoError(t, yaml.Unmarshal([]byte(`
+watches: [global-watch]
+jfrogProjectKey: global
+projectMappings:
+  - repositories: [payments-*, billing]
+    jfrogProjectKey: fin
+  - repositories: [web-*]
+    jfrogProjectKey: web
+    watches: [web-watch]
+`), &jfrogPlatform))
+	testCases := []struct {
+		repoName           string
+		expectedProjectKey string
+		expectedWatches    []string
+	}{
+		// A mapping without watches keeps the watches of the configuration
+		{repoName: "payments-api", expectedWatches: []string{"global-watch"}, expectedProjectKey: "fin"},
+		{repoName: "billing", expectedWatches: []string{"global-watch"}, expectedProjectKey: "fin"},
+		{repoName: "web-frontend", expectedWatches: []string{"web-watch"}, expectedProjectKey: "web"},
+		{repoName: "infra", expectedWatches: []string{"global-watch"}, expectedProjectKey: "global"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.repoName, func(t *testing.T) {
+			repoJFrogPlatform := jfrogPlatform
+			require.NoError(t, repoJFrogPlatform.setDefaultsIfNeeded(testCase.repoName))
+			scanDetails := NewScanDetails(nil, nil, &Git{RepoName: testCase.repoName}).SetXrayGraphScanParams(repoJFrogPlatform.Watches, repoJFrogPlatform.JFrogProjectKey, false)
+			if len(testCase.expectedWatches) > 0 {
+				// Watches take precedence over the project in the scan
+				assert.Equal(t, testCase.expectedWatches, scanDetails.XrayGraphScanParams.Watches)
+				return
+			}
+			assert.Equal(t, testCase.expectedProjectKey, scanDetails.XrayGraphScanParams.ProjectKey)
+			assert.Empty(t, scanDetails.XrayGraphScanParams.Watches)
+		})
+	}
+
+	// The mappings can also be provided as an environment variable
+	SetEnvAndAssert(t, map[string]string{ProjectMappingEnv: "payments-*=fin; web-*=web"})
+	jfrogPlatform = JFrogPlatform{}
+	require.NoError(t, jfrogPlatform.setDefaultsIfNeeded("web-frontend"))
+	assert.Equal(t, "web", jfrogPlatform.JFrogProjectKey)
+	assert.Len(t, jfrogPlatform.ProjectMappings, 2)
+
+	// The mappings of the environment variable have no watches, so they keep the watches of JF_WATCHES
+	SetEnvAndAssert(t, map[string]string{jfrogWatchesEnv: "watch-1, watch-2"})
+	jfrogPlatform = JFrogPlatform{}
+	require.NoError(t, jfrogPlatform.setDefaultsIfNeeded("payments-api"))
+	assert.Equal(t, "fin", jfrogPlatform.JFrogProjectKey)
+	assert.Equal(t, []string{"watch-1", "watch-2"}, jfrogPlatform.Watches)
+
+	SetEnvAndAssert(t, map[string]string{ProjectMappingEnv: "payments-*"})
+	jfrogPlatform = JFrogPlatform{}
+	assert.ErrorContains(t, jfrogPlatform.setDefaultsIfNeeded("payments-api"), "invalid JFrog project mapping")
+}
+
+func TestCleanRepoSubpath(t *testing.T) {
+	testCases := []struct {
+		subpath     string
+		expected    string
+		expectedErr bool
+	}{
+		{subpath: "", expected: ""},
+		{subpath: ".", expected: ""},
+		{subpath: "services/foo", expected: "services/foo"},
+		{subpath: "./services/foo/", expected: "services/foo"},
+		{subpath: "/services//foo", expected: "services/foo"},
+		{subpath: "services/../foo", expected: "foo"},
+		{subpath: "../foo", expectedErr: true},
+		{subpath: "services/../..", expectedErr: true},
+	}
+	for _, test := range testCases {
+		t.Run(test.subpath, func(t *testing.T) {
+			cleanSubpath, err := cleanRepoSubpath(test.subpath)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, cleanSubpath)
+		})
+	}
+}
+
 func TestGenerateConfigAggregatorFromEnv(t *testing.T) {
 	SetEnvAndAssert(t, map[string]string{
 		JFrogUrlEnv:                        "",
@@ -541,7 +1196,7 @@ func TestBuildMergedRepoAggregator(t *testing.T) {
 		User:           "admin",
 		Password:       "password",
 	}
-	repoAggregator, err := BuildRepoAggregator(nil, fileContent, gitParams, &server, ScanRepository)
+	repoAggregator, err := BuildRepoAggregator(nil, [][]byte{fileContent}, gitParams, &server, ScanRepository)
 	assert.NoError(t, err)
 
 	repo := repoAggregator[0]
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type PullRequestAction string
+
+const (
+	CreatePullRequestAction PullRequestAction = "create"
+	UpdatePullRequestAction PullRequestAction = "update"
+)
+
+// PullRequestOperation is the creation or update of a fix pull request, along with everything needed to carry it out:
+// the changes of the fix branch, and the content of the pull request.
+type PullRequestOperation struct {
+	Action       PullRequestAction `json:"action"`
+	RepoOwner    string            `json:"repoOwner"`
+	RepoName     string            `json:"repoName"`
+	SourceBranch string            `json:"sourceBranch"`
+	TargetBranch string            `json:"targetBranch"`
+	// Determines whether the source branch replaces an existing branch of the same name
+	ForcePush bool `json:"forcePush"`
+	// Determines whether the pull request is opened as a draft
+	Draft bool `json:"draft,omitempty"`
+	// The commit of the target branch the fix branch was created from
+	BaseCommit    string `json:"baseCommit,omitempty"`
+	CommitMessage string `json:"commitMessage,omitempty"`
+	// The changes of the fix branch relative to the base commit, in the unified diff format
+	Patch string `json:"patch,omitempty"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// The checksum of the scan results an aggregated pull request fixes, as written in its body
+	Checksum string   `json:"checksum,omitempty"`
+	Comments []string `json:"comments,omitempty"`
+	// The reviewers to request, in addition to the default reviewers of the repository
+	Reviewers []string `json:"reviewers,omitempty"`
+	// The ID of the pull request to update
+	PullRequestId int64 `json:"pullRequestId,omitempty"`
+}
+
+// AppendPullRequestOperation appends the operation to the file as a line of JSON, so a consumer can carry out the operations in order.
+func AppendPullRequestOperation(filePath string, operation *PullRequestOperation) (err error) {
+	content, err := json.Marshal(operation)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the pull request operations file at %s: %s", filePath, err.Error())
+	}
+	file, err := os.OpenFile(filepath.Clean(filePath), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the pull request operations file at %s: %s", filePath, err.Error())
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	if _, err = file.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("failed to write to the pull request operations file at %s: %s", filePath, err.Error())
+	}
+	return
+}
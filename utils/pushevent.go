@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Requirements files are often split by environment, such as requirements-dev.txt
+var requirementsFileRegexp = regexp.MustCompile(`^requirements.*\.txt$`)
+
+// IsPushEvent returns true if the CI system runs the command on a push to a branch, rather than on a schedule or manually
+func IsPushEvent() bool {
+	switch {
+	case os.Getenv("GITHUB_EVENT_NAME") == "push":
+		return true
+	case os.Getenv("CI_PIPELINE_SOURCE") == "push":
+		return true
+	case os.Getenv("BUILD_REASON") == "IndividualCI" || os.Getenv("BUILD_REASON") == "BatchedCI":
+		return true
+	}
+	return false
+}
+
+// IsDependencyFile returns true if the file is a dependency descriptor or a lock file, whose changes may change the detected vulnerabilities
+func IsDependencyFile(filePath string) bool {
+	filePath = strings.ReplaceAll(filePath, "\\", "/")
+	return isFixDescriptorFile(filePath) || requirementsFileRegexp.MatchString(path.Base(filePath))
+}
+
+// The changed files are separated by commas or new lines, as printed by 'git diff --name-only'
+func parsePushChangedFiles(changedFiles string) (files []string) {
+	for _, file := range strings.FieldsFunc(changedFiles, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return
+}
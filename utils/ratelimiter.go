@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out the requests to the Git provider by a minimal interval, including requests that are sent concurrently.
+type RateLimiter struct {
+	interval    time.Duration
+	mutex       sync.Mutex
+	nextRequest time.Time
+}
+
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval}
+}
+
+// Wait blocks until the next request may be sent.
+// Every call reserves its own time slot, so concurrent callers are released one interval apart.
+func (rl *RateLimiter) Wait() {
+	rl.mutex.Lock()
+	requestTime := rl.nextRequest
+	if now := time.Now(); requestTime.Before(now) {
+		requestTime = now
+	}
+	rl.nextRequest = requestTime.Add(rl.interval)
+	rl.mutex.Unlock()
+	time.Sleep(time.Until(requestTime))
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	interval := 50 * time.Millisecond
+	rateLimiter := NewRateLimiter(interval)
+	start := time.Now()
+	var releaseTimes []time.Duration
+	var mutex sync.Mutex
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			rateLimiter.Wait()
+			mutex.Lock()
+			defer mutex.Unlock()
+			releaseTimes = append(releaseTimes, time.Since(start))
+		}()
+	}
+	waitGroup.Wait()
+
+	// The concurrent callers are released one interval apart, and the first one right away
+	sort.Slice(releaseTimes, func(i, j int) bool { return releaseTimes[i] < releaseTimes[j] })
+	assert.Less(t, releaseTimes[0], interval)
+	for i := 1; i < len(releaseTimes); i++ {
+		assert.GreaterOrEqual(t, releaseTimes[i]-releaseTimes[i-1], interval-5*time.Millisecond)
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/gofrog/version"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	fixedPackagesPrefix        = "Fixed packages: "
+	mergedPullRequestsPageSize = 100
+	// Limits the history that is checked on repositories with many closed pull requests
+	maxMergedPullRequestsPages = 10
+)
+
+// Matches the packages recorded in the body of a fix pull request, e.g. [comment]: <> (Fixed packages: minimist@1.2.6, lodash@4.17.21)
+var fixedPackagesRegex = regexp.MustCompile(`\[comment]: <> \(` + fixedPackagesPrefix + `([^)\n]*)\)`)
+
+// MergedFix is a package that a merged fix pull request upgraded to a fixed version
+type MergedFix struct {
+	PackageName    string
+	FixVersion     string
+	PullRequestUrl string
+}
+
+// A pull request, as returned by the GitHub REST API
+type gitHubPullRequest struct {
+	Number   int     `json:"number"`
+	HtmlUrl  string  `json:"html_url"`
+	Body     string  `json:"body"`
+	MergedAt *string `json:"merged_at"`
+}
+
+// FixedPackagesMarker records the packages a fix pull request upgrades, and their fix versions, in the pull request body.
+// The marker is read back from merged pull requests to detect fixes that were reverted.
+func FixedPackagesMarker(vulnDetails []*VulnerabilityDetails) string {
+	var fixedPackages []string
+	for _, vuln := range vulnDetails {
+		fixedPackages = append(fixedPackages, vuln.ImpactedDependencyName+"@"+vuln.SuggestedFixedVersion)
+	}
+	return outputwriter.MarkdownComment(fixedPackagesPrefix + strings.Join(fixedPackages, ", "))
+}
+
+// Returns the fixes recorded in the body of a fix pull request
+func parseFixedPackagesMarker(body, pullRequestUrl string) (fixes []MergedFix) {
+	match := fixedPackagesRegex.FindStringSubmatch(body)
+	if match == nil {
+		return
+	}
+	for _, fixedPackage := range strings.Split(match[1], ", ") {
+		// Package names may start with '@', while versions never contain it
+		separatorIndex := strings.LastIndex(fixedPackage, "@")
+		if separatorIndex <= 0 {
+			continue
+		}
+		fixes = append(fixes, MergedFix{PackageName: fixedPackage[:separatorIndex], FixVersion: fixedPackage[separatorIndex+1:], PullRequestUrl: pullRequestUrl})
+	}
+	return
+}
+
+// GetGitHubMergedFixes lists the fixes of the Frogbot pull requests that were merged into the given branch of a GitHub repository.
+// The VCS client only lists open pull requests, so the GitHub REST API is called directly.
+func GetGitHubMergedFixes(apiEndpoint, token, owner, repo, branch string) (fixes []MergedFix, err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	// The most recently updated pull requests are listed first, so the history that is checked is the recent one
+	pageUrl := fmt.Sprintf("%s/repos/%s/%s/pulls?state=closed&base=%s&sort=updated&direction=desc&per_page=%d", getGitHubApiEndpoint(apiEndpoint), owner, repo, url.QueryEscape(branch), mergedPullRequestsPageSize)
+	for page := 0; pageUrl != "" && page < maxMergedPullRequestsPages; page++ {
+		body, header, e := sendGitHubApiRequest(client.GetClient(), http.MethodGet, pageUrl, token, nil)
+		if e != nil {
+			return nil, fmt.Errorf("failed to list the merged pull requests of %s/%s: %s", owner, repo, e.Error())
+		}
+		var pullRequests []gitHubPullRequest
+		if err = json.Unmarshal(body, &pullRequests); err != nil {
+			return
+		}
+		for _, pullRequest := range pullRequests {
+			if pullRequest.MergedAt == nil || !outputwriter.IsFrogbotComment(pullRequest.Body) {
+				continue
+			}
+			fixes = append(fixes, parseFixedPackagesMarker(pullRequest.Body, pullRequest.HtmlUrl)...)
+		}
+		pageUrl = getGitHubNextPageUrl(header)
+	}
+	log.Debug(fmt.Sprintf("Found %d fixes in the merged fix pull requests of %s/%s", len(fixes), owner, repo))
+	return
+}
+
+// FindRegression returns the merged fix that upgraded the package beyond the given vulnerable version, or nil if there is none.
+// Such a fix was reverted, reintroducing the vulnerability.
+func FindRegression(mergedFixes []MergedFix, packageName, vulnerableVersion string) *MergedFix {
+	vulnerableVersion = strings.TrimPrefix(vulnerableVersion, "v")
+	for i := range mergedFixes {
+		if mergedFixes[i].PackageName != packageName {
+			continue
+		}
+		if version.NewVersion(vulnerableVersion).Compare(strings.TrimPrefix(mergedFixes[i].FixVersion, "v")) > 0 {
+			return &mergedFixes[i]
+		}
+	}
+	return nil
+}
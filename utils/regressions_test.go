@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedPackagesMarker(t *testing.T) {
+	vulnerabilities := []*VulnerabilityDetails{
+		NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "@babel/traverse"}}, "7.23.2"),
+		NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "org.yaml:snakeyaml"}}, "2.0"),
+	}
+	body := "Fix pull request body" + FixedPackagesMarker(vulnerabilities)
+	assert.Equal(t, []MergedFix{
+		{PackageName: "@babel/traverse", FixVersion: "7.23.2", PullRequestUrl: "url"},
+		{PackageName: "org.yaml:snakeyaml", FixVersion: "2.0", PullRequestUrl: "url"},
+	}, parseFixedPackagesMarker(body, "url"))
+	assert.Empty(t, parseFixedPackagesMarker("Fix pull request body", "url"))
+}
+
+func TestFindRegression(t *testing.T) {
+	mergedFixes := []MergedFix{{PackageName: "github.com/gin-gonic/gin", FixVersion: "v1.9.1", PullRequestUrl: "url"}}
+	assert.Equal(t, &mergedFixes[0], FindRegression(mergedFixes, "github.com/gin-gonic/gin", "v1.9.0"))
+	// The package was upgraded to the fix version or beyond it
+	assert.Nil(t, FindRegression(mergedFixes, "github.com/gin-gonic/gin", "v1.9.1"))
+	assert.Nil(t, FindRegression(mergedFixes, "github.com/gin-gonic/gin", "v1.10.0"))
+	assert.Nil(t, FindRegression(mergedFixes, "github.com/gin-gonic/other", "v1.0.0"))
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-client-go/http/httpclient"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// The header that carries the correlation ID of the run, shared by all the requests of the run
+const CorrelationIdHeader = "X-Correlation-Id"
+
+// The hosts of the APIs the VCS clients send their requests to when no API endpoint is provided
+var defaultGitProviderApiHosts = map[vcsutils.VcsProvider]string{
+	vcsutils.GitHub:     "api.github.com",
+	vcsutils.GitLab:     "gitlab.com",
+	vcsutils.AzureRepos: "dev.azure.com",
+}
+
+// taggingRoundTripper sets the User-Agent and the correlation ID of the run on the requests it sends to the Git provider,
+// so the traffic of Frogbot can be told apart from the traffic of other bots in the audit logs of the Git provider.
+// The requests to other hosts, such as webhooks and package registries, are sent as is.
+type taggingRoundTripper struct {
+	base            http.RoundTripper
+	gitProviderHost string
+	userAgent       string
+	correlationId   string
+}
+
+func (trt *taggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != trt.gitProviderHost {
+		return trt.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", trt.userAgent)
+	if trt.correlationId != "" {
+		req.Header.Set(CorrelationIdHeader, trt.correlationId)
+	}
+	return trt.base.RoundTrip(req)
+}
+
+// DefaultUserAgent returns the User-Agent of the requests of Frogbot, unless JF_USER_AGENT overrides it
+func DefaultUserAgent() string {
+	return "frogbot/" + FrogbotVersion
+}
+
+// The transport that tags the requests of the run, and the state it replaced, which is restored by ResetRequestTagging
+var (
+	requestTaggingTransport *taggingRoundTripper
+	userAgentBeforeTagging  string
+)
+
+// SetRequestTagging sets the User-Agent on the requests to Xray and the Git provider, and the correlation ID on the requests to the Git provider.
+// The requests Frogbot sends to the Git provider directly are tagged by the client of newGitProviderHttpClient. The VCS client can't be given an HTTP client,
+// so the default transport it sends its requests with is wrapped until ResetRequestTagging is called. The wrapper tags only the requests to the host
+// of the Git provider, so the requests other clients send with the default transport aren't tagged. The GitLab client has a transport of its own,
+// so its requests aren't tagged. An empty correlationId adds no correlation header.
+func SetRequestTagging(gitProviderHost, userAgent, correlationId string) {
+	ResetRequestTagging()
+	userAgentBeforeTagging = clientutils.GetUserAgent()
+	clientutils.SetUserAgent(userAgent)
+	requestTaggingTransport = &taggingRoundTripper{base: http.DefaultTransport, gitProviderHost: gitProviderHost, userAgent: userAgent, correlationId: correlationId}
+	http.DefaultTransport = requestTaggingTransport
+}
+
+// ResetRequestTagging restores the default transport and the User-Agent that were replaced by SetRequestTagging
+func ResetRequestTagging() {
+	if requestTaggingTransport == nil {
+		return
+	}
+	http.DefaultTransport = requestTaggingTransport.base
+	clientutils.SetUserAgent(userAgentBeforeTagging)
+	requestTaggingTransport = nil
+}
+
+// Builds the client of the requests Frogbot sends to the Git provider directly, which tags them like the requests of the VCS client
+func newGitProviderHttpClient() (*httpclient.HttpClient, error) {
+	builder := httpclient.ClientBuilder()
+	if requestTaggingTransport != nil {
+		builder.SetHttpClient(&http.Client{Transport: requestTaggingTransport})
+	}
+	return builder.Build()
+}
+
+// SetRequestTaggingFromEnv reads the Git provider, the User-Agent and whether to add a correlation ID from the environment,
+// and tags the requests of the run accordingly. It's called before the environment is sanitized, and undone by ResetRequestTagging.
+func SetRequestTaggingFromEnv() (err error) {
+	gitProvider, err := extractVcsProviderFromEnv()
+	if err != nil {
+		return
+	}
+	gitProviderHost, err := getGitProviderApiHost(gitProvider, getTrimmedEnv(GitApiEndpointEnv))
+	if err != nil {
+		return
+	}
+	userAgent := getTrimmedEnv(UserAgentEnv)
+	isUserAgentSet := userAgent != ""
+	if !isUserAgentSet {
+		userAgent = DefaultUserAgent()
+	}
+	addCorrelationId, err := getBoolEnv(AddCorrelationIdEnv, false)
+	if err != nil {
+		return
+	}
+	var correlationId string
+	if addCorrelationId {
+		if correlationId, err = generateCorrelationId(); err != nil {
+			return
+		}
+		log.Info("The requests to the Git provider are sent with the correlation ID:", correlationId)
+	}
+	if gitProvider == vcsutils.GitLab && (isUserAgentSet || addCorrelationId) {
+		log.Warn(fmt.Sprintf("The requests of the GitLab client can't be tagged, so only the requests Frogbot sends to GitLab directly are sent with the tags set by %s and %s", UserAgentEnv, AddCorrelationIdEnv))
+	}
+	SetRequestTagging(gitProviderHost, userAgent, correlationId)
+	return
+}
+
+// Returns the host the VCS client of the Git provider sends its requests to
+func getGitProviderApiHost(gitProvider vcsutils.VcsProvider, apiEndpoint string) (string, error) {
+	if apiEndpoint == "" {
+		return defaultGitProviderApiHosts[gitProvider], nil
+	}
+	parsedUrl, err := url.Parse(apiEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return parsedUrl.Host, nil
+}
+
+func generateCorrelationId() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
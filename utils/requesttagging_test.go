@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/httputils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRequestTaggingFromEnv(t *testing.T) {
+	defaultTransport, defaultUserAgent := http.DefaultTransport, clientutils.GetUserAgent()
+	defer ResetRequestTagging()
+
+	var requestsHeaders []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsHeaders = append(requestsHeaders, r.Header.Clone())
+		_, err := w.Write([]byte(`{"clone_url": "https://github.com/jfrog/frogbot.git", "visibility": "public"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	// The requests to other hosts, such as webhooks, are sent with the default transport as well
+	var otherHostHeaders http.Header
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherHostHeaders = r.Header.Clone()
+	}))
+	defer otherServer.Close()
+	restoreGitEnv := SetEnvsAndAssertWithCallback(t, map[string]string{GitProvider: string(GitHub), GitApiEndpointEnv: server.URL})
+	defer restoreGitEnv()
+	sendRequests := func() {
+		client, err := vcsclient.NewClientBuilder(vcsutils.GitHub).ApiEndpoint(server.URL).Token("123456").Build()
+		require.NoError(t, err)
+		_, err = client.GetRepositoryInfo(context.Background(), "jfrog", "frogbot")
+		require.NoError(t, err)
+		// The requests Frogbot sends to the Git provider directly are tagged as well
+		directClient, err := newGitProviderHttpClient()
+		require.NoError(t, err)
+		_, _, _, err = directClient.SendGet(server.URL, true, httputils.HttpClientDetails{}, "")
+		require.NoError(t, err)
+		response, err := http.Post(otherServer.URL, "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+	}
+
+	// By default, the requests are sent with the User-Agent of Frogbot, without a correlation ID
+	require.NoError(t, SetRequestTaggingFromEnv())
+	sendRequests()
+	require.Len(t, requestsHeaders, 2)
+	assert.Equal(t, DefaultUserAgent(), requestsHeaders[0].Get("User-Agent"))
+	assert.Empty(t, requestsHeaders[0].Get(CorrelationIdHeader))
+	assert.Equal(t, DefaultUserAgent(), clientutils.GetUserAgent())
+
+	// All the requests of a run share the same correlation ID
+	requestsHeaders = nil
+	restoreEnv := SetEnvsAndAssertWithCallback(t, map[string]string{UserAgentEnv: "security-bot/1.0", AddCorrelationIdEnv: "true"})
+	defer restoreEnv()
+	require.NoError(t, SetRequestTaggingFromEnv())
+	sendRequests()
+	require.Len(t, requestsHeaders, 2)
+	for _, headers := range requestsHeaders {
+		assert.Equal(t, "security-bot/1.0", headers.Get("User-Agent"))
+		assert.Len(t, headers.Get(CorrelationIdHeader), 32)
+	}
+	assert.Equal(t, requestsHeaders[0].Get(CorrelationIdHeader), requestsHeaders[1].Get(CorrelationIdHeader))
+	assert.Equal(t, "security-bot/1.0", clientutils.GetUserAgent())
+	// Only the requests to the Git provider are tagged
+	assert.NotEqual(t, "security-bot/1.0", otherHostHeaders.Get("User-Agent"))
+	assert.Empty(t, otherHostHeaders.Get(CorrelationIdHeader))
+	// The transport is wrapped once, however many times the tagging is set
+	assert.Equal(t, defaultTransport, http.DefaultTransport.(*taggingRoundTripper).base)
+
+	// The default transport and User-Agent are restored once the command is done
+	ResetRequestTagging()
+	assert.Equal(t, defaultTransport, http.DefaultTransport)
+	assert.Equal(t, defaultUserAgent, clientutils.GetUserAgent())
+
+	SetEnvAndAssert(t, map[string]string{AddCorrelationIdEnv: "maybe"})
+	assert.ErrorContains(t, SetRequestTaggingFromEnv(), AddCorrelationIdEnv)
+}
+
+func TestGetGitProviderApiHost(t *testing.T) {
+	testCases := []struct {
+		gitProvider  vcsutils.VcsProvider
+		apiEndpoint  string
+		expectedHost string
+	}{
+		{gitProvider: vcsutils.GitHub, expectedHost: "api.github.com"},
+		{gitProvider: vcsutils.GitHub, apiEndpoint: "https://github.acme.com/api/v3", expectedHost: "github.acme.com"},
+		{gitProvider: vcsutils.BitbucketServer, apiEndpoint: "https://bitbucket.acme.com:7990/rest", expectedHost: "bitbucket.acme.com:7990"},
+		{gitProvider: vcsutils.AzureRepos, expectedHost: "dev.azure.com"},
+	}
+	for _, test := range testCases {
+		t.Run(test.gitProvider.String()+test.apiEndpoint, func(t *testing.T) {
+			host, err := getGitProviderApiHost(test.gitProvider, test.apiEndpoint)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedHost, host)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const sbomVulnerabilitiesSource = "JFrog Xray"
+
+// The package URL types of the technologies, see https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst
+var purlTypes = map[techutils.Technology]string{
+	techutils.Npm:    "npm",
+	techutils.Yarn:   "npm",
+	techutils.Pnpm:   "npm",
+	techutils.Pip:    "pypi",
+	techutils.Pipenv: "pypi",
+	techutils.Poetry: "pypi",
+	techutils.Maven:  "maven",
+	techutils.Gradle: "maven",
+	techutils.Go:     "golang",
+	techutils.Nuget:  "nuget",
+	techutils.Dotnet: "nuget",
+}
+
+// CreateCycloneDxSbom creates a CycloneDX SBOM of the components that appear in the impact paths of the vulnerabilities,
+// annotated with VEX entries of the vulnerabilities.
+// If fixes are provided, the SBOM reflects the state after the fixes are applied: the fixed components are upgraded to their fix versions, and their vulnerabilities are resolved.
+func CreateCycloneDxSbom(vulnerabilities []formats.VulnerabilityOrViolationRow, fixes []*VulnerabilityDetails) *cdx.BOM {
+	fixVersions := make(map[string]string)
+	for _, fix := range fixes {
+		fixVersions[getSbomComponentKey(fix.ImpactedDependencyName, fix.ImpactedDependencyVersion)] = fix.SuggestedFixedVersion
+	}
+	components := make(map[string]cdx.Component)
+	var componentRefs []string
+	addComponent := func(tech techutils.Technology, name, version string) string {
+		if fixVersion, exists := fixVersions[getSbomComponentKey(name, version)]; exists {
+			version = fixVersion
+		}
+		ref := getPackageUrl(tech, name, version)
+		if _, exists := components[ref]; !exists {
+			components[ref] = cdx.Component{BOMRef: ref, Type: cdx.ComponentTypeLibrary, Name: name, Version: version, PackageURL: ref}
+			componentRefs = append(componentRefs, ref)
+		}
+		return ref
+	}
+
+	var sbomVulnerabilities []cdx.Vulnerability
+	for _, vulnerability := range vulnerabilities {
+		for _, impactPath := range vulnerability.ImpactPaths {
+			// The first component of an impact path is the project itself
+			for i := 1; i < len(impactPath); i++ {
+				addComponent(vulnerability.Technology, impactPath[i].Name, impactPath[i].Version)
+			}
+		}
+		componentRef := addComponent(vulnerability.Technology, vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)
+		fixVersion, isFixed := fixVersions[getSbomComponentKey(vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion)]
+		for _, cve := range getSbomVulnerabilityCves(vulnerability) {
+			sbomVulnerability := cdx.Vulnerability{
+				BOMRef:      cve.Id + "/" + componentRef,
+				ID:          cve.Id,
+				Source:      &cdx.Source{Name: sbomVulnerabilitiesSource},
+				Ratings:     getSbomVulnerabilityRatings(vulnerability.Severity, cve.CvssV3),
+				Description: vulnerability.Summary,
+				Affects:     &[]cdx.Affects{{Ref: componentRef}},
+			}
+			if isFixed {
+				sbomVulnerability.Analysis = &cdx.VulnerabilityAnalysis{
+					State:  cdx.IASResolved,
+					Detail: fmt.Sprintf("Fixed by upgrading %s from %s to %s", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, fixVersion),
+				}
+			} else {
+				sbomVulnerability.Analysis = &cdx.VulnerabilityAnalysis{State: getImpactAnalysisState(vulnerability, cve)}
+				if len(vulnerability.FixedVersions) > 0 {
+					sbomVulnerability.Recommendation = fmt.Sprintf("Upgrade %s to %s", vulnerability.ImpactedDependencyName, strings.Join(vulnerability.FixedVersions, ", "))
+				}
+			}
+			sbomVulnerabilities = append(sbomVulnerabilities, sbomVulnerability)
+		}
+	}
+
+	bom := cdx.NewBOM()
+	bom.Metadata = &cdx.Metadata{
+		Tools: &cdx.ToolsChoice{Components: &[]cdx.Component{{Type: cdx.ComponentTypeApplication, Name: "Frogbot", Version: FrogbotVersion}}},
+	}
+	sbomComponents := make([]cdx.Component, 0, len(componentRefs))
+	for _, ref := range componentRefs {
+		sbomComponents = append(sbomComponents, components[ref])
+	}
+	bom.Components = &sbomComponents
+	bom.Vulnerabilities = &sbomVulnerabilities
+	return bom
+}
+
+// WriteCycloneDxSbom writes the SBOM to the given path in the CycloneDX JSON format.
+func WriteCycloneDxSbom(bom *cdx.BOM, sbomPath string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(sbomPath), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of the SBOM at %s: %s", sbomPath, err.Error())
+	}
+	sbomFile, err := os.Create(filepath.Clean(sbomPath))
+	if err != nil {
+		return fmt.Errorf("failed to create the SBOM at %s: %s", sbomPath, err.Error())
+	}
+	defer func() {
+		if closeErr := sbomFile.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if err = cdx.NewBOMEncoder(sbomFile, cdx.BOMFileFormatJSON).SetPretty(true).Encode(bom); err != nil {
+		return fmt.Errorf("failed to write the SBOM at %s: %s", sbomPath, err.Error())
+	}
+	log.Info("The CycloneDX SBOM was written to", sbomPath)
+	return
+}
+
+func getSbomComponentKey(name, version string) string {
+	return name + "@" + version
+}
+
+// Returns the package URL of the component, such as pkg:npm/minimist@1.2.5
+func getPackageUrl(tech techutils.Technology, name, version string) string {
+	purlType, exists := purlTypes[tech]
+	if !exists {
+		purlType = "generic"
+	}
+	if purlType == "maven" {
+		// Maven components are named <group>:<artifact>
+		name = strings.Replace(name, ":", "/", 1)
+	}
+	return fmt.Sprintf("pkg:%s/%s@%s", purlType, name, version)
+}
+
+// Vulnerabilities without CVEs are identified by their Xray issue ID
+func getSbomVulnerabilityCves(vulnerability formats.VulnerabilityOrViolationRow) []formats.CveRow {
+	if len(vulnerability.Cves) > 0 {
+		return vulnerability.Cves
+	}
+	return []formats.CveRow{{Id: vulnerability.IssueId}}
+}
+
+func getSbomVulnerabilityRatings(severity, cvssV3 string) *[]cdx.VulnerabilityRating {
+	rating := cdx.VulnerabilityRating{Source: &cdx.Source{Name: sbomVulnerabilitiesSource}, Severity: cdx.Severity(strings.ToLower(severity))}
+	if rating.Severity == "" {
+		rating.Severity = cdx.SeverityUnknown
+	}
+	if score, err := strconv.ParseFloat(cvssV3, 64); err == nil {
+		rating.Score = &score
+		rating.Method = cdx.ScoringMethodCVSSv3
+	}
+	return &[]cdx.VulnerabilityRating{rating}
+}
+
+// The impact analysis state of a vulnerability that isn't fixed is derived from the contextual analysis of its CVE
+func getImpactAnalysisState(vulnerability formats.VulnerabilityOrViolationRow, cve formats.CveRow) cdx.ImpactAnalysisState {
+	applicability := vulnerability.Applicable
+	if cve.Applicability != nil {
+		applicability = cve.Applicability.Status
+	}
+	switch applicability {
+	case jasutils.Applicable.String():
+		return cdx.IASExploitable
+	case jasutils.NotApplicable.String():
+		return cdx.IASNotAffected
+	default:
+		return cdx.IASInTriage
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCycloneDxSbom(t *testing.T) {
+	vulnerabilities := []formats.VulnerabilityOrViolationRow{
+		{
+			Summary: "Prototype Pollution in minimist",
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "Critical"},
+				ImpactedDependencyName:    "minimist",
+				ImpactedDependencyVersion: "1.2.5",
+				Components:                []formats.ComponentRow{{Name: "mkdirp", Version: "0.5.5"}},
+			},
+			FixedVersions: []string{"[1.2.6]"},
+			Cves:          []formats.CveRow{{Id: "CVE-2021-44906", CvssV3: "9.8", Applicability: &formats.Applicability{Status: "Applicable"}}},
+			Technology:    techutils.Npm,
+			ImpactPaths: [][]formats.ComponentRow{{
+				{Name: "my-project", Version: "1.0.0"}, {Name: "mkdirp", Version: "0.5.5"}, {Name: "minimist", Version: "1.2.5"},
+			}},
+		},
+		{
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{
+				SeverityDetails:           formats.SeverityDetails{Severity: "High"},
+				ImpactedDependencyName:    "org.yaml:snakeyaml",
+				ImpactedDependencyVersion: "1.33",
+			},
+			IssueId:    "XRAY-123",
+			Applicable: "Not Applicable",
+			Technology: techutils.Maven,
+		},
+	}
+
+	// The SBOM of the vulnerable state
+	bom := CreateCycloneDxSbom(vulnerabilities, nil)
+	require.NotNil(t, bom.Components)
+	assert.Equal(t, []cdx.Component{
+		{BOMRef: "pkg:npm/mkdirp@0.5.5", Type: cdx.ComponentTypeLibrary, Name: "mkdirp", Version: "0.5.5", PackageURL: "pkg:npm/mkdirp@0.5.5"},
+		{BOMRef: "pkg:npm/minimist@1.2.5", Type: cdx.ComponentTypeLibrary, Name: "minimist", Version: "1.2.5", PackageURL: "pkg:npm/minimist@1.2.5"},
+		{BOMRef: "pkg:maven/org.yaml/snakeyaml@1.33", Type: cdx.ComponentTypeLibrary, Name: "org.yaml:snakeyaml", Version: "1.33", PackageURL: "pkg:maven/org.yaml/snakeyaml@1.33"},
+	}, *bom.Components)
+	require.NotNil(t, bom.Vulnerabilities)
+	require.Len(t, *bom.Vulnerabilities, 2)
+	minimistVulnerability := (*bom.Vulnerabilities)[0]
+	assert.Equal(t, "CVE-2021-44906", minimistVulnerability.ID)
+	assert.Equal(t, []cdx.Affects{{Ref: "pkg:npm/minimist@1.2.5"}}, *minimistVulnerability.Affects)
+	assert.Equal(t, cdx.IASExploitable, minimistVulnerability.Analysis.State)
+	assert.Equal(t, "Upgrade minimist to [1.2.6]", minimistVulnerability.Recommendation)
+	require.Len(t, *minimistVulnerability.Ratings, 1)
+	assert.Equal(t, cdx.SeverityCritical, (*minimistVulnerability.Ratings)[0].Severity)
+	assert.Equal(t, 9.8, *(*minimistVulnerability.Ratings)[0].Score)
+	snakeyamlVulnerability := (*bom.Vulnerabilities)[1]
+	assert.Equal(t, "XRAY-123", snakeyamlVulnerability.ID)
+	assert.Equal(t, []cdx.Affects{{Ref: "pkg:maven/org.yaml/snakeyaml@1.33"}}, *snakeyamlVulnerability.Affects)
+	assert.Equal(t, cdx.IASNotAffected, snakeyamlVulnerability.Analysis.State)
+	assert.Equal(t, cdx.SeverityHigh, (*snakeyamlVulnerability.Ratings)[0].Severity)
+
+	// The SBOM of the fixed state
+	fix := NewVulnerabilityDetails(vulnerabilities[0], "1.2.6")
+	fixedBom := CreateCycloneDxSbom(vulnerabilities, []*VulnerabilityDetails{fix})
+	assert.Equal(t, "1.2.6", (*fixedBom.Components)[1].Version)
+	assert.Equal(t, "pkg:npm/minimist@1.2.6", (*fixedBom.Components)[1].PackageURL)
+	minimistVulnerability = (*fixedBom.Vulnerabilities)[0]
+	assert.Equal(t, []cdx.Affects{{Ref: "pkg:npm/minimist@1.2.6"}}, *minimistVulnerability.Affects)
+	assert.Equal(t, cdx.IASResolved, minimistVulnerability.Analysis.State)
+	assert.Equal(t, "Fixed by upgrading minimist from 1.2.5 to 1.2.6", minimistVulnerability.Analysis.Detail)
+	assert.Equal(t, cdx.IASNotAffected, (*fixedBom.Vulnerabilities)[1].Analysis.State)
+
+	// The SBOM is written in the CycloneDX JSON format
+	sbomPath := filepath.Join(t.TempDir(), "sbom", "frogbot-sbom.cdx.json")
+	require.NoError(t, WriteCycloneDxSbom(bom, sbomPath))
+	sbomFile, err := os.Open(sbomPath)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sbomFile.Close())
+	}()
+	decodedBom := cdx.NewBOM()
+	require.NoError(t, cdx.NewBOMDecoder(sbomFile, cdx.BOMFileFormatJSON).Decode(decodedBom))
+	assert.Equal(t, *bom.Components, *decodedBom.Components)
+	assert.Len(t, *decodedBom.Vulnerabilities, 2)
+}
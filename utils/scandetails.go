@@ -6,18 +6,40 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jfrog/froggit-go/vcsclient"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-security/commands/audit"
+	"github.com/jfrog/jfrog-cli-security/commands/audit/sca"
+	"github.com/jfrog/jfrog-cli-security/formats/sarifutils"
 	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
 	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-cli-security/utils/xray"
 	"github.com/jfrog/jfrog-cli-security/utils/xray/scangraph"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
+	xrayUtils "github.com/jfrog/jfrog-client-go/xray/services/utils"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
+var (
+	// The scans of the SCA phase. The contextual analysis runs with the SCA scan, as it analyzes the applicability of the vulnerabilities it finds
+	scaPhaseScans = []xrayutils.SubScanType{xrayutils.ScaScan, xrayutils.ContextualAnalysisScan}
+	// The scans of the JAS phase, which scan the source code itself
+	jasPhaseScans = []xrayutils.SubScanType{xrayutils.SecretsScan, xrayutils.IacScan, xrayutils.SastScan}
+	// Runs the audit of the scans, replaceable for testing purposes
+	runAudit = audit.RunAudit
+)
+
+// Lockfiles that may be shared by several projects, such as the root lockfile of npm, yarn and pnpm workspaces
+var sharedLockfileNames = []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"}
+
 type ScanDetails struct {
 	*Project
 	*Git
@@ -28,6 +50,8 @@ type ScanDetails struct {
 	fixableOnly              bool
 	minSeverityFilter        severityutils.Severity
 	baseBranch               string
+	scanPhaseOrder           ScanPhaseOrder
+	earlyExitOnCritical      bool
 }
 
 func NewScanDetails(client vcsclient.VcsClient, server *config.ServerDetails, git *Git) *ScanDetails {
@@ -66,6 +90,13 @@ func (sc *ScanDetails) SetMinSeverity(minSeverity string) (*ScanDetails, error)
 	return sc, nil
 }
 
+// SetScanPhases sets the order the SCA and JAS scan phases run in, and whether the later phase is skipped once the earlier one found a Critical issue
+func (sc *ScanDetails) SetScanPhases(order string, earlyExitOnCritical bool) *ScanDetails {
+	sc.scanPhaseOrder = ScanPhaseOrder(order)
+	sc.earlyExitOnCritical = earlyExitOnCritical
+	return sc
+}
+
 func (sc *ScanDetails) SetBaseBranch(branch string) *ScanDetails {
 	sc.baseBranch = branch
 	return sc
@@ -75,6 +106,18 @@ func (sc *ScanDetails) Client() vcsclient.VcsClient {
 	return sc.client
 }
 
+// NewVcsClient builds another client of the Git provider.
+// The clients aren't safe for concurrent use, so requests that are sent concurrently are sent with clients of their own.
+func (sc *ScanDetails) NewVcsClient() (vcsclient.VcsClient, error) {
+	return vcsclient.NewClientBuilder(sc.GitProvider).
+		ApiEndpoint(strings.TrimSuffix(sc.APIEndpoint, "/")).
+		Token(sc.Token).
+		Project(sc.Git.VcsInfo.Project).
+		Logger(log.GetLogger()).
+		Username(sc.Username).
+		Build()
+}
+
 func (sc *ScanDetails) BaseBranch() string {
 	return sc.baseBranch
 }
@@ -138,7 +181,31 @@ func createXrayScanParams(watches []string, project string, includeLicenses bool
 	return
 }
 
+// RunInstallAndAudit runs the SCA and JAS scans of the working directories, either together or one phase after the other, in the configured order.
+// When the phases run one after the other and early exit is enabled, the later phase is skipped if the earlier one found a Critical issue.
 func (sc *ScanDetails) RunInstallAndAudit(workDirs ...string) (auditResults *xrayutils.Results, err error) {
+	if sc.scanPhaseOrder == "" || sc.scanPhaseOrder == ParallelScanPhaseOrder {
+		return sc.runAuditPhase(nil, workDirs)
+	}
+	phases, phaseNames := [][]xrayutils.SubScanType{scaPhaseScans, jasPhaseScans}, []string{"SCA", "JAS"}
+	if sc.scanPhaseOrder == JasFirstScanPhaseOrder {
+		phases[0], phases[1], phaseNames[0], phaseNames[1] = phases[1], phases[0], phaseNames[1], phaseNames[0]
+	}
+	log.Info(fmt.Sprintf("Running the %s scans before the %s scans", phaseNames[0], phaseNames[1]))
+	if auditResults, err = sc.runAuditPhase(phases[0], workDirs); auditResults == nil {
+		return
+	}
+	if sc.earlyExitOnCritical && hasCriticalIssue(auditResults) {
+		log.Info(fmt.Sprintf("The %s scans found a Critical issue. Skipping the %s scans...", phaseNames[0], phaseNames[1]))
+		return
+	}
+	laterResults, laterErr := sc.runAuditPhase(phases[1], workDirs)
+	err = errors.Join(err, laterErr)
+	mergeAuditResults(auditResults, laterResults)
+	return
+}
+
+func (sc *ScanDetails) runAuditPhase(scansToPerform []xrayutils.SubScanType, workDirs []string) (auditResults *xrayutils.Results, err error) {
 	auditBasicParams := (&xrayutils.AuditBasicParams{}).
 		SetPipRequirementsFile(sc.PipRequirementsFile).
 		SetUseWrapper(*sc.UseWrapper).
@@ -146,7 +213,8 @@ func (sc *ScanDetails) RunInstallAndAudit(workDirs ...string) (auditResults *xra
 		SetIgnoreConfigFile(true).
 		SetServerDetails(sc.ServerDetails).
 		SetInstallCommandName(sc.InstallCommandName).
-		SetInstallCommandArgs(sc.InstallCommandArgs).SetUseJas(true)
+		SetInstallCommandArgs(sc.InstallCommandArgs).SetUseJas(true).
+		SetScansToPerform(scansToPerform)
 
 	auditParams := audit.NewAuditParams().
 		SetWorkingDirs(workDirs).
@@ -156,7 +224,7 @@ func (sc *ScanDetails) RunInstallAndAudit(workDirs ...string) (auditResults *xra
 		SetCommonGraphScanParams(sc.CreateCommonGraphScanParams())
 	auditParams.SetExclusions(sc.PathExclusions).SetIsRecursiveScan(sc.IsRecursiveScan)
 
-	auditResults, err = audit.RunAudit(auditParams)
+	auditResults, err = runAudit(auditParams)
 
 	if auditResults != nil {
 		err = errors.Join(err, auditResults.ScansErr)
@@ -164,6 +232,102 @@ func (sc *ScanDetails) RunInstallAndAudit(workDirs ...string) (auditResults *xra
 	return
 }
 
+// Returns true if the SCA scan found a Critical vulnerability or violation, or if the JAS scans found an issue of the error level.
+// The JAS scanners report their most severe issues with the error level, which is reported as High, as they don't report Critical issues.
+func hasCriticalIssue(results *xrayutils.Results) bool {
+	for _, xrayResult := range results.GetScaScansXrayResults() {
+		for _, vulnerability := range xrayResult.Vulnerabilities {
+			if severityutils.GetSeverity(vulnerability.Severity) == severityutils.Critical {
+				return true
+			}
+		}
+		for _, violation := range xrayResult.Violations {
+			if severityutils.GetSeverity(violation.Severity) == severityutils.Critical {
+				return true
+			}
+		}
+	}
+	extendedResults := results.ExtendedScanResults
+	for _, runs := range [][]*sarif.Run{extendedResults.SecretsScanResults, extendedResults.IacScanResults, extendedResults.SastScanResults} {
+		for _, run := range runs {
+			for _, result := range run.Results {
+				if sarifutils.GetResultLevel(result) == severityutils.LevelError.String() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Adds the results of the later scan phase to the results of the earlier one
+func mergeAuditResults(results, laterResults *xrayutils.Results) {
+	if laterResults == nil {
+		return
+	}
+	results.ScaResults = append(results.ScaResults, laterResults.ScaResults...)
+	results.ScansErr = errors.Join(results.ScansErr, laterResults.ScansErr)
+	if results.XrayVersion == "" {
+		results.XrayVersion = laterResults.XrayVersion
+	}
+	if results.MultiScanId == "" {
+		results.MultiScanId = laterResults.MultiScanId
+	}
+	extendedResults, laterExtendedResults := results.ExtendedScanResults, laterResults.ExtendedScanResults
+	extendedResults.EntitledForJas = extendedResults.EntitledForJas || laterExtendedResults.EntitledForJas
+	extendedResults.ApplicabilityScanResults = append(extendedResults.ApplicabilityScanResults, laterExtendedResults.ApplicabilityScanResults...)
+	extendedResults.SecretsScanResults = append(extendedResults.SecretsScanResults, laterExtendedResults.SecretsScanResults...)
+	extendedResults.IacScanResults = append(extendedResults.IacScanResults, laterExtendedResults.IacScanResults...)
+	extendedResults.SastScanResults = append(extendedResults.SastScanResults, laterExtendedResults.SastScanResults...)
+}
+
+// RunSbomScan scans the components of the given SBOM with Xray, instead of resolving the dependencies of the project in the working directory.
+// The impact paths of the issues are built from the dependencies that are recorded in the SBOM.
+func (sc *ScanDetails) RunSbomScan(sbomPath, workingDir string) (auditResults *xrayutils.Results, err error) {
+	dependencyTrees, err := LoadSbomDependencyTrees(sbomPath)
+	if err != nil {
+		return
+	}
+	_, xrayVersion, err := xray.CreateXrayServiceManagerAndGetVersion(sc.ServerDetails)
+	if err != nil {
+		return
+	}
+	auditResults = xrayutils.NewAuditResults()
+	auditResults.XrayVersion = xrayVersion
+	technologies := maps.Keys(dependencyTrees)
+	slices.Sort(technologies)
+	for _, tech := range technologies {
+		commonParams := sc.CreateCommonGraphScanParams()
+		scanGraphParams := scangraph.NewScanGraphParams().
+			SetServerDetails(sc.ServerDetails).
+			SetXrayGraphScanParams(&services.XrayGraphScanParams{
+				RepoPath:               commonParams.RepoPath,
+				Watches:                commonParams.Watches,
+				ScanType:               commonParams.ScanType,
+				ProjectKey:             commonParams.ProjectKey,
+				IncludeVulnerabilities: commonParams.IncludeVulnerabilities,
+				IncludeLicenses:        commonParams.IncludeLicenses,
+				XscVersion:             commonParams.XscVersion,
+				MultiScanId:            commonParams.MultiScanId,
+			}).
+			SetXrayVersion(xrayVersion).
+			SetFixableOnly(sc.FixableOnly()).
+			SetSeverityLevel(sc.MinSeverityFilter().String())
+		var techResults []services.ScanResponse
+		if techResults, err = sca.RunXrayDependenciesTreeScanGraph(*FlattenDependencyTree(dependencyTrees[tech]), tech, scanGraphParams); err != nil {
+			return
+		}
+		auditResults.ScaResults = append(auditResults.ScaResults, &xrayutils.ScaScanResult{
+			Target:                workingDir,
+			Technology:            tech,
+			XrayResults:           sca.BuildImpactPathsForScanResponse(techResults, []*xrayUtils.GraphNode{dependencyTrees[tech]}),
+			Descriptors:           []string{sbomPath},
+			IsMultipleRootProject: clientutils.Pointer(false),
+		})
+	}
+	return
+}
+
 func (sc *ScanDetails) SetXscGitInfoContext(scannedBranch, gitProject string, client vcsclient.VcsClient) *ScanDetails {
 	XscGitInfoContext, err := sc.createGitInfoContext(scannedBranch, gitProject, client)
 	if err != nil {
@@ -202,6 +366,27 @@ func (sc *ScanDetails) createGitInfoContext(scannedBranch, gitProject string, cl
 	return
 }
 
+// GetSharedLockfilePath returns the path of the lockfile that owns the given working directory.
+// The lockfile is searched in the working directory and its parent directories, up to the base working directory.
+// If no lockfile is found, an empty string is returned.
+func GetSharedLockfilePath(fullPathWd, baseWd string) (string, error) {
+	for currentDir := filepath.Clean(fullPathWd); ; currentDir = filepath.Dir(currentDir) {
+		for _, lockfileName := range sharedLockfileNames {
+			lockfilePath := filepath.Join(currentDir, lockfileName)
+			exists, err := fileutils.IsFileExists(lockfilePath, false)
+			if err != nil {
+				return "", err
+			}
+			if exists {
+				return lockfilePath, nil
+			}
+		}
+		if currentDir == filepath.Clean(baseWd) || currentDir == filepath.Dir(currentDir) {
+			return "", nil
+		}
+	}
+}
+
 func GetFullPathWorkingDirs(workingDirs []string, baseWd string) []string {
 	var fullPathWds []string
 	if len(workingDirs) != 0 {
@@ -1,9 +1,18 @@
 package utils
 
 import (
-	"github.com/stretchr/testify/assert"
 	"path/filepath"
 	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/commands/audit"
+	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 )
 
 func TestCreateXrayScanParams(t *testing.T) {
@@ -41,3 +50,52 @@ func TestGetFullPathWorkingDirs(t *testing.T) {
 		assert.Contains(t, fullPathWds, expectedWd)
 	}
 }
+
+func TestRunInstallAndAuditScanPhases(t *testing.T) {
+	criticalScaResults := func() *xrayutils.Results {
+		results := xrayutils.NewAuditResults()
+		results.ScaResults = []*xrayutils.ScaScanResult{{Technology: techutils.Npm, XrayResults: []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Severity: "Critical"}}}}}}
+		return results
+	}
+	jasResults := func() *xrayutils.Results {
+		results := xrayutils.NewAuditResults()
+		results.ExtendedScanResults.SecretsScanResults = []*sarif.Run{sarif.NewRunWithInformationURI("secrets", "")}
+		return results
+	}
+	testCases := []struct {
+		name                string
+		order               ScanPhaseOrder
+		earlyExitOnCritical bool
+		expectedPhases      [][]xrayutils.SubScanType
+		expectedSecretsRuns int
+	}{
+		{name: "parallel", order: ParallelScanPhaseOrder, expectedPhases: [][]xrayutils.SubScanType{nil}},
+		{name: "sca first", order: ScaFirstScanPhaseOrder, expectedPhases: [][]xrayutils.SubScanType{scaPhaseScans, jasPhaseScans}, expectedSecretsRuns: 1},
+		{name: "jas first", order: JasFirstScanPhaseOrder, expectedPhases: [][]xrayutils.SubScanType{jasPhaseScans, scaPhaseScans}, expectedSecretsRuns: 1},
+		{name: "early exit skips jas", order: ScaFirstScanPhaseOrder, earlyExitOnCritical: true, expectedPhases: [][]xrayutils.SubScanType{scaPhaseScans}},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var actualPhases [][]xrayutils.SubScanType
+			defaultRunAudit := runAudit
+			runAudit = func(auditParams *audit.AuditParams) (*xrayutils.Results, error) {
+				actualPhases = append(actualPhases, auditParams.ScansToPerform())
+				if slices.Equal(auditParams.ScansToPerform(), jasPhaseScans) {
+					return jasResults(), nil
+				}
+				return criticalScaResults(), nil
+			}
+			defer func() {
+				runAudit = defaultRunAudit
+			}()
+			scanDetails := NewScanDetails(nil, nil, nil).SetProject(&Project{UseWrapper: clientutils.Pointer(false)}).SetScanPhases(string(test.order), test.earlyExitOnCritical)
+			scanDetails.XrayGraphScanParams = createXrayScanParams(nil, "", false)
+			results, err := scanDetails.RunInstallAndAudit("project")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPhases, actualPhases)
+			// The results of both phases are merged
+			assert.Len(t, results.ScaResults, 1)
+			assert.Len(t, results.ExtendedScanResults.SecretsScanResults, test.expectedSecretsRuns)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const securityAlertsPageSize = 100
+
+// A Dependabot alert, as returned by the GitHub REST API
+type gitHubSecurityAlert struct {
+	Number     int    `json:"number"`
+	HtmlUrl    string `json:"html_url"`
+	Dependency struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+	} `json:"dependency"`
+	SecurityAdvisory struct {
+		GhsaId string `json:"ghsa_id"`
+		CveId  string `json:"cve_id"`
+	} `json:"security_advisory"`
+}
+
+// GetGitHubSecurityAlerts lists the open Dependabot alerts of a GitHub repository.
+// The VCS client doesn't expose the security alerts, so the GitHub REST API is called directly.
+func GetGitHubSecurityAlerts(apiEndpoint, token, owner, repo string) (alerts []outputwriter.SecurityAlertRow, err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	pageUrl := fmt.Sprintf("%s/repos/%s/%s/dependabot/alerts?state=open&per_page=%d", getGitHubApiEndpoint(apiEndpoint), owner, repo, securityAlertsPageSize)
+	for pageUrl != "" {
+		var pageAlerts []gitHubSecurityAlert
+		if pageAlerts, pageUrl, err = getGitHubSecurityAlertsPage(client.GetClient(), pageUrl, token); err != nil {
+			return nil, fmt.Errorf("failed to list the security alerts of %s/%s: %s", owner, repo, err.Error())
+		}
+		for _, alert := range pageAlerts {
+			alerts = append(alerts, outputwriter.SecurityAlertRow{
+				Number:      alert.Number,
+				Url:         alert.HtmlUrl,
+				PackageName: alert.Dependency.Package.Name,
+				CveId:       alert.SecurityAdvisory.CveId,
+				GhsaId:      alert.SecurityAdvisory.GhsaId,
+			})
+		}
+	}
+	log.Debug(fmt.Sprintf("Found %d open security alerts in %s/%s", len(alerts), owner, repo))
+	return
+}
+
+// Returns the alerts of the page and the URL of the next page, or an empty string if this is the last page.
+func getGitHubSecurityAlertsPage(client *http.Client, pageUrl, token string) (alerts []gitHubSecurityAlert, nextPageUrl string, err error) {
+	body, header, err := sendGitHubApiRequest(client, http.MethodGet, pageUrl, token, nil)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(body, &alerts); err != nil {
+		return
+	}
+	nextPageUrl = getGitHubNextPageUrl(header)
+	return
+}
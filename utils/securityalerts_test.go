@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGitHubSecurityAlerts(t *testing.T) {
+	var serverUrl string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/jfrog/frogbot/dependabot/alerts", r.URL.Path)
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "Bearer 123456", r.Header.Get("Authorization"))
+		var body string
+		if r.URL.Query().Get("after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/jfrog/frogbot/dependabot/alerts?state=open&after=cursor>; rel="next"`, serverUrl))
+			body = `[{"number": 1, "html_url": "https://github.com/jfrog/frogbot/security/dependabot/1", "dependency": {"package": {"ecosystem": "npm", "name": "minimist"}}, "security_advisory": {"ghsa_id": "GHSA-xvch-5gv4-984h", "cve_id": "CVE-2021-44906"}}]`
+		} else {
+			body = `[{"number": 2, "html_url": "https://github.com/jfrog/frogbot/security/dependabot/2", "dependency": {"package": {"ecosystem": "npm", "name": "lodash"}}, "security_advisory": {"ghsa_id": "GHSA-35jh-r3h4-6jhm", "cve_id": "CVE-2021-23337"}}]`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+
+	alerts, err := GetGitHubSecurityAlerts(server.URL+"/", "123456", "jfrog", "frogbot")
+	require.NoError(t, err)
+	assert.Equal(t, []outputwriter.SecurityAlertRow{
+		{Number: 1, Url: "https://github.com/jfrog/frogbot/security/dependabot/1", PackageName: "minimist", CveId: "CVE-2021-44906", GhsaId: "GHSA-xvch-5gv4-984h"},
+		{Number: 2, Url: "https://github.com/jfrog/frogbot/security/dependabot/2", PackageName: "lodash", CveId: "CVE-2021-23337", GhsaId: "GHSA-35jh-r3h4-6jhm"},
+	}, alerts)
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failingServer.Close()
+	_, err = GetGitHubSecurityAlerts(failingServer.URL, "123456", "jfrog", "frogbot")
+	assert.ErrorContains(t, err, "failed to list the security alerts of jfrog/frogbot")
+}
+
+func TestSecurityAlertsPullRequestDetails(t *testing.T) {
+	alerts := []outputwriter.SecurityAlertRow{
+		{Number: 1, Url: "https://github.com/jfrog/frogbot/security/dependabot/1", PackageName: "minimist", CveId: "CVE-2021-44906", GhsaId: "GHSA-xvch-5gv4-984h"},
+		{Number: 2, Url: "https://github.com/jfrog/frogbot/security/dependabot/2", PackageName: "minimist", CveId: "CVE-2020-7598"},
+		{Number: 3, Url: "https://github.com/jfrog/frogbot/security/dependabot/3", PackageName: "lodash", CveId: "CVE-2021-44906"},
+	}
+	vulnDetails := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "Minimist", ImpactedDependencyVersion: "1.2.5"},
+		Cves:                      []formats.CveRow{{Id: "CVE-2021-44906"}},
+	}, "1.2.6")
+	// Only the alert of the same package and CVE is addressed by the fix
+	vulnDetails.SetSecurityAlerts(alerts)
+	assert.Equal(t, alerts[:1], vulnDetails.SecurityAlerts)
+
+	description, _ := GenerateFixPullRequestDetails([]*VulnerabilityDetails{vulnDetails, vulnDetails}, &outputwriter.StandardOutput{})
+	assert.Contains(t, description, "🛡️ Security Alerts")
+	assert.Contains(t, description, "| [#1](https://github.com/jfrog/frogbot/security/dependabot/1) | minimist | CVE-2021-44906 (GHSA-xvch-5gv4-984h) |")
+	assert.NotContains(t, description, "dependabot/2")
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"golang.org/x/exp/slices"
+)
+
+// The severities that can be adjusted, from the lowest to the highest
+var adjustableSeverities = []severityutils.Severity{severityutils.Low, severityutils.Medium, severityutils.High, severityutils.Critical}
+
+// ApplicabilitySeverityAdjustment maps the severities of vulnerabilities that aren't applicable to the lower severities they are treated as.
+// Severities that aren't mapped aren't adjusted.
+type ApplicabilitySeverityAdjustment map[severityutils.Severity]severityutils.Severity
+
+// ParseApplicabilitySeverityAdjustment parses the number of levels to lower the severities by, such as 2,
+// or a comma-separated list of <severity>:<severity> pairs, such as critical:low,high:low.
+func ParseApplicabilitySeverityAdjustment(severityAdjustment string) (ApplicabilitySeverityAdjustment, error) {
+	adjustment := ApplicabilitySeverityAdjustment{}
+	if levels, err := strconv.Atoi(strings.TrimSpace(severityAdjustment)); err == nil {
+		if levels <= 0 {
+			return nil, fmt.Errorf("the provided number of severity levels to lower '%s' must be positive", severityAdjustment)
+		}
+		for i, severity := range adjustableSeverities {
+			adjustment[severity] = adjustableSeverities[max(i-levels, 0)]
+		}
+		return adjustment, nil
+	}
+	for _, entry := range strings.Split(severityAdjustment, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		severityName, adjustedSeverityName, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("the provided severity adjustment entry '%s' is invalid. The expected format is <severity>:<severity>, such as critical:low, or a number of levels to lower the severities by", entry)
+		}
+		severity, err := severityutils.ParseSeverity(strings.TrimSpace(severityName), false)
+		if err != nil {
+			return nil, err
+		}
+		adjustedSeverity, err := severityutils.ParseSeverity(strings.TrimSpace(adjustedSeverityName), false)
+		if err != nil {
+			return nil, err
+		}
+		if severityutils.CompareSeverity(adjustedSeverity, severity) > 0 {
+			return nil, fmt.Errorf("the %s severity can't be adjusted to the higher %s severity", severity, adjustedSeverity)
+		}
+		adjustment[severity] = adjustedSeverity
+	}
+	return adjustment, nil
+}
+
+// AdjustedSeverity returns the severity the vulnerability is treated as, which is lowered if the vulnerability isn't applicable.
+func (asa ApplicabilitySeverityAdjustment) AdjustedSeverity(vulnerability formats.VulnerabilityOrViolationRow) severityutils.Severity {
+	severity, err := severityutils.ParseSeverity(vulnerability.Severity, false)
+	if err != nil || vulnerability.Applicable != jasutils.NotApplicable.String() {
+		return severity
+	}
+	if adjustedSeverity, exists := asa[severity]; exists {
+		return adjustedSeverity
+	}
+	return severity
+}
+
+// AdjustSeverityScores lowers the severity scores of the vulnerabilities that aren't applicable, and orders the vulnerabilities by their scores again.
+// The severities themselves aren't changed, so the original severities are still displayed.
+func (asa ApplicabilitySeverityAdjustment) AdjustSeverityScores(vulnerabilities []formats.VulnerabilityOrViolationRow) {
+	if len(asa) == 0 {
+		return
+	}
+	for i := range vulnerabilities {
+		if !asa.IsAdjusted(vulnerabilities[i]) {
+			continue
+		}
+		vulnerabilities[i].SeverityNumValue = severityutils.GetSeverityPriority(asa.AdjustedSeverity(vulnerabilities[i]), jasutils.NotApplicable)
+	}
+	slices.SortStableFunc(vulnerabilities, func(a, b formats.VulnerabilityOrViolationRow) int {
+		return b.SeverityNumValue - a.SeverityNumValue
+	})
+}
+
+// IsAdjusted returns true if the vulnerability is treated as a lower severity than its original one.
+func (asa ApplicabilitySeverityAdjustment) IsAdjusted(vulnerability formats.VulnerabilityOrViolationRow) bool {
+	severity, err := severityutils.ParseSeverity(vulnerability.Severity, false)
+	return err == nil && asa.AdjustedSeverity(vulnerability) != severity
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/jasutils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApplicabilitySeverityAdjustment(t *testing.T) {
+	adjustment, err := ParseApplicabilitySeverityAdjustment("critical:low, High:Medium")
+	assert.NoError(t, err)
+	assert.Equal(t, ApplicabilitySeverityAdjustment{severityutils.Critical: severityutils.Low, severityutils.High: severityutils.Medium}, adjustment)
+
+	// Lowering by a number of levels stops at the Low severity
+	adjustment, err = ParseApplicabilitySeverityAdjustment("2")
+	assert.NoError(t, err)
+	assert.Equal(t, ApplicabilitySeverityAdjustment{
+		severityutils.Critical: severityutils.Medium,
+		severityutils.High:     severityutils.Low,
+		severityutils.Medium:   severityutils.Low,
+		severityutils.Low:      severityutils.Low,
+	}, adjustment)
+
+	_, err = ParseApplicabilitySeverityAdjustment("0")
+	assert.ErrorContains(t, err, "must be positive")
+	_, err = ParseApplicabilitySeverityAdjustment("critical")
+	assert.ErrorContains(t, err, "the provided severity adjustment entry 'critical' is invalid")
+	_, err = ParseApplicabilitySeverityAdjustment("low:critical")
+	assert.ErrorContains(t, err, "the Low severity can't be adjusted to the higher Critical severity")
+}
+
+func TestApplicabilityAdjustedSeverity(t *testing.T) {
+	adjustment := ApplicabilitySeverityAdjustment{severityutils.Critical: severityutils.Low}
+	newVulnerability := func(severity string, applicability jasutils.ApplicabilityStatus) formats.VulnerabilityOrViolationRow {
+		return formats.VulnerabilityOrViolationRow{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: severity}}, Applicable: applicability.String()}
+	}
+	assert.Equal(t, severityutils.Low, adjustment.AdjustedSeverity(newVulnerability("Critical", jasutils.NotApplicable)))
+	assert.True(t, adjustment.IsAdjusted(newVulnerability("Critical", jasutils.NotApplicable)))
+	// Only vulnerabilities that aren't applicable are adjusted
+	assert.Equal(t, severityutils.Critical, adjustment.AdjustedSeverity(newVulnerability("Critical", jasutils.ApplicabilityUndetermined)))
+	assert.False(t, adjustment.IsAdjusted(newVulnerability("Critical", jasutils.Applicable)))
+	assert.Equal(t, severityutils.High, adjustment.AdjustedSeverity(newVulnerability("High", jasutils.NotApplicable)))
+	// Without an adjustment, the severities are kept
+	assert.Equal(t, severityutils.Critical, ApplicabilitySeverityAdjustment(nil).AdjustedSeverity(newVulnerability("Critical", jasutils.NotApplicable)))
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	firstSeenFileSuffix = "-first-seen.json"
+	day                 = 24 * time.Hour
+)
+
+// SlaPolicy maps severities to the time their vulnerabilities must be remediated within, counted from the time they were first seen.
+// Severities that aren't mapped have no SLA.
+type SlaPolicy map[string]time.Duration
+
+// ParseSlaPolicy parses a comma-separated list of <severity>:<time> pairs, such as critical:7d,high:30d.
+// The time is a number of days, such as 7d, or a duration, such as 72h.
+func ParseSlaPolicy(slaPolicy string) (SlaPolicy, error) {
+	policy := SlaPolicy{}
+	for _, entry := range strings.Split(slaPolicy, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		severityName, slaTime, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("the provided SLA policy entry '%s' is invalid. The expected format is <severity>:<time>, such as critical:7d", entry)
+		}
+		severity, err := severityutils.ParseSeverity(strings.TrimSpace(severityName), false)
+		if err != nil {
+			return nil, err
+		}
+		sla, err := parseSlaTime(strings.TrimSpace(slaTime))
+		if err != nil {
+			return nil, fmt.Errorf("the provided SLA time '%s' of the %s severity is invalid. Please provide a number of days, such as 7d, or a duration, such as 72h", slaTime, severity)
+		}
+		policy[severity.String()] = sla
+	}
+	return policy, nil
+}
+
+func parseSlaTime(slaTime string) (sla time.Duration, err error) {
+	if days, found := strings.CutSuffix(slaTime, "d"); found {
+		var daysCount int
+		if daysCount, err = strconv.Atoi(days); err != nil {
+			return
+		}
+		sla = time.Duration(daysCount) * day
+	} else if sla, err = time.ParseDuration(slaTime); err != nil {
+		return
+	}
+	if sla <= 0 {
+		err = errors.New("the SLA time must be positive")
+	}
+	return
+}
+
+// GetSlaStatus returns the SLA status of a vulnerability of the given severity that was first seen at the given time, or nil if the severity has no SLA.
+func (sp SlaPolicy) GetSlaStatus(severity string, firstSeen, now time.Time) *SlaStatus {
+	parsedSeverity, err := severityutils.ParseSeverity(severity, false)
+	if err != nil {
+		return nil
+	}
+	sla, exists := sp[parsedSeverity.String()]
+	if !exists {
+		return nil
+	}
+	deadline := firstSeen.Add(sla)
+	return &SlaStatus{Deadline: deadline, Remaining: deadline.Sub(now)}
+}
+
+// SlaStatus is the time left to remediate a vulnerability within its SLA
+type SlaStatus struct {
+	Deadline time.Time
+	// Negative if the SLA was breached
+	Remaining time.Duration
+}
+
+func (ss *SlaStatus) IsOverdue() bool {
+	return ss.Remaining < 0
+}
+
+func (ss *SlaStatus) String() string {
+	if ss.IsOverdue() {
+		return "🚨 SLA overdue by " + formatSlaDuration(-ss.Remaining)
+	}
+	return "⏳ " + formatSlaDuration(ss.Remaining) + " left in SLA"
+}
+
+// Formats the duration in whole days, or in whole hours if it's shorter than a day
+func formatSlaDuration(duration time.Duration) string {
+	if duration >= day {
+		return fmt.Sprintf("%dd", duration/day)
+	}
+	return fmt.Sprintf("%dh", duration/time.Hour)
+}
+
+// FirstSeenRecord holds the time each vulnerability of a branch was first seen, so the time left to remediate it within its SLA can be computed.
+type FirstSeenRecord struct {
+	RepoOwner string               `json:"repoOwner"`
+	RepoName  string               `json:"repoName"`
+	Branch    string               `json:"branch"`
+	FirstSeen map[string]time.Time `json:"firstSeen"`
+}
+
+func NewFirstSeenRecord(repoOwner, repoName, branch string) *FirstSeenRecord {
+	return &FirstSeenRecord{RepoOwner: repoOwner, RepoName: repoName, Branch: branch, FirstSeen: map[string]time.Time{}}
+}
+
+// Update records the vulnerabilities that weren't seen before as first seen now.
+// The vulnerabilities that are no longer detected are removed, so the SLA of a vulnerability that is reintroduced starts over.
+func (fsr *FirstSeenRecord) Update(vulnerabilities []formats.VulnerabilityOrViolationRow, now time.Time) {
+	firstSeen := make(map[string]time.Time, len(vulnerabilities))
+	for _, vulnerability := range vulnerabilities {
+		vulnerabilityKey := GetVulnerabiltiesUniqueID(vulnerability)
+		if seen, exists := fsr.FirstSeen[vulnerabilityKey]; exists {
+			firstSeen[vulnerabilityKey] = seen
+		} else if _, exists = firstSeen[vulnerabilityKey]; !exists {
+			firstSeen[vulnerabilityKey] = now
+		}
+	}
+	fsr.FirstSeen = firstSeen
+}
+
+// GetFirstSeen returns the time the vulnerability was first seen, and false if it wasn't recorded.
+func (fsr *FirstSeenRecord) GetFirstSeen(vulnerability formats.VulnerabilityOrViolationRow) (firstSeen time.Time, exists bool) {
+	firstSeen, exists = fsr.FirstSeen[GetVulnerabiltiesUniqueID(vulnerability)]
+	return
+}
+
+// LoadFirstSeenRecord reads the time the vulnerabilities of the given branch were first seen.
+// If no record exists yet, an empty record is returned.
+func LoadFirstSeenRecord(stateDir, repoOwner, repoName, branch string) (record *FirstSeenRecord, err error) {
+	recordPath, err := getStateFilePath(stateDir, repoOwner, repoName, branch, firstSeenFileSuffix)
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(filepath.Clean(recordPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug("No first seen vulnerabilities were recorded for branch", branch, "at", recordPath)
+			return NewFirstSeenRecord(repoOwner, repoName, branch), nil
+		}
+		return nil, fmt.Errorf("failed to read the first seen vulnerabilities file at %s: %s", recordPath, err.Error())
+	}
+	record = NewFirstSeenRecord(repoOwner, repoName, branch)
+	if err = json.Unmarshal(content, record); err != nil {
+		return nil, fmt.Errorf("failed to parse the first seen vulnerabilities file at %s: %s", recordPath, err.Error())
+	}
+	return
+}
+
+// SaveFirstSeenRecord writes the record into the state directory, replacing any previous record of the same branch.
+func SaveFirstSeenRecord(stateDir string, record *FirstSeenRecord) (err error) {
+	recordPath, err := getStateFilePath(stateDir, record.RepoOwner, record.RepoName, record.Branch, firstSeenFileSuffix)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(stateDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the Frogbot state directory at %s: %s", stateDir, err.Error())
+	}
+	content, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(recordPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write the first seen vulnerabilities file at %s: %s", recordPath, err.Error())
+	}
+	log.Debug("First seen vulnerabilities of branch", record.Branch, "were recorded at", recordPath)
+	return
+}
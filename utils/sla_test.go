@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSlaPolicy(t *testing.T) {
+	policy, err := ParseSlaPolicy("critical:7d, High:72h")
+	require.NoError(t, err)
+	assert.Equal(t, SlaPolicy{"Critical": 7 * 24 * time.Hour, "High": 72 * time.Hour}, policy)
+
+	for _, invalidPolicy := range []string{"critical", "urgent:7d", "critical:week", "critical:0d"} {
+		_, err = ParseSlaPolicy(invalidPolicy)
+		assert.Error(t, err, invalidPolicy)
+	}
+}
+
+func TestSlaStatusOfVulnerabilitySeenInThePast(t *testing.T) {
+	policy, err := ParseSlaPolicy("critical:7d,high:30d")
+	require.NoError(t, err)
+	now := time.Now()
+	critical := formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "Critical"}, ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"},
+		IssueId:                   "XRAY-140575",
+	}
+	high := formats.VulnerabilityOrViolationRow{
+		ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+		IssueId:                   "XRAY-209002",
+	}
+
+	// The first seen times are kept between runs
+	stateDir := t.TempDir()
+	record, err := LoadFirstSeenRecord(stateDir, "jfrog", "frogbot", "master")
+	require.NoError(t, err)
+	record.Update([]formats.VulnerabilityOrViolationRow{critical}, now.Add(-10*24*time.Hour))
+	require.NoError(t, SaveFirstSeenRecord(stateDir, record))
+	record, err = LoadFirstSeenRecord(stateDir, "jfrog", "frogbot", "master")
+	require.NoError(t, err)
+	record.Update([]formats.VulnerabilityOrViolationRow{critical, high}, now)
+
+	// A Critical seen 10 days ago is overdue with a 7 days SLA
+	firstSeen, exists := record.GetFirstSeen(critical)
+	require.True(t, exists)
+	status := policy.GetSlaStatus(critical.Severity, firstSeen, now)
+	require.NotNil(t, status)
+	assert.True(t, status.IsOverdue())
+	assert.Equal(t, "🚨 SLA overdue by 3d", status.String())
+
+	firstSeen, exists = record.GetFirstSeen(high)
+	require.True(t, exists)
+	status = policy.GetSlaStatus(high.Severity, firstSeen, now)
+	require.NotNil(t, status)
+	assert.False(t, status.IsOverdue())
+	assert.Equal(t, "⏳ 30d left in SLA", status.String())
+
+	// Severities without an SLA have no status
+	assert.Nil(t, policy.GetSlaStatus("Medium", now, now))
+
+	// A vulnerability that is no longer detected is removed, so its SLA starts over if it's reintroduced
+	record.Update([]formats.VulnerabilityOrViolationRow{high}, now)
+	_, exists = record.GetFirstSeen(critical)
+	assert.False(t, exists)
+
+	// The overdue vulnerabilities are highlighted in the run summary
+	summary := &RunSummary{}
+	summary.AddVulnerabilities(high, critical)
+	summary.AddSlaStatus(high, policy.GetSlaStatus(high.Severity, now, now))
+	summary.AddSlaStatus(critical, policy.GetSlaStatus(critical.Severity, now.Add(-10*24*time.Hour), now))
+	assert.Equal(t, "Detected vulnerabilities: 2 (1 Critical, 1 High), 1 overdue", getVulnerabilitiesHeadline(summary))
+	lines, _ := getListedVulnerabilities(summary, false)
+	assert.Equal(t, []string{
+		"Critical lodash 4.17.20: XRAY-140575 (🚨 SLA overdue by 3d)",
+		"High minimist 1.2.5: XRAY-209002 (⏳ 30d left in SLA)",
+	}, lines)
+}
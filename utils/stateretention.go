@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+// The suffixes of the files Frogbot persists in the state directory, one file of each kind per branch
+var stateFileSuffixes = []string{baselineFileSuffix, fixPullRequestsFileSuffix, firstSeenFileSuffix}
+
+// The fields every state file starts with, which identify the branch the file belongs to
+type stateFileHeader struct {
+	RepoOwner string `json:"repoOwner"`
+	RepoName  string `json:"repoName"`
+	Branch    string `json:"branch"`
+}
+
+// ParseStateRetention parses the time the state files are retained for since they were last updated.
+// The time is a number of days, such as 90d, or a duration, such as 720h.
+func ParseStateRetention(stateRetention string) (time.Duration, error) {
+	retention, err := parseSlaTime(strings.TrimSpace(stateRetention))
+	if err != nil {
+		return 0, fmt.Errorf("the provided state retention '%s' is invalid. Please provide a number of days, such as 90d, or a duration, such as 720h", stateRetention)
+	}
+	return retention, nil
+}
+
+// PruneStateFiles removes the state files of the repository that weren't updated within the retention, if it's positive,
+// and the state files of the branches that no longer exist, if the existing branches are known.
+// The state files of other repositories, which may share the state directory, and files that aren't state files are kept.
+func PruneStateFiles(stateDir, repoOwner, repoName string, retention time.Duration, existingBranches []string, now time.Time) (prunedFiles []string, err error) {
+	entries, err := os.ReadDir(stateDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the Frogbot state directory at %s: %s", stateDir, err.Error())
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.ContainsFunc(stateFileSuffixes, func(suffix string) bool { return strings.HasSuffix(entry.Name(), suffix) }) {
+			continue
+		}
+		statePath := filepath.Join(stateDir, entry.Name())
+		header, info, e := readStateFileHeader(statePath)
+		if e != nil {
+			log.Debug(fmt.Sprintf("Skipping the retention of the state file at %s: %s", statePath, e.Error()))
+			continue
+		}
+		if header.RepoOwner != repoOwner || header.RepoName != repoName {
+			continue
+		}
+		var reason string
+		switch {
+		case existingBranches != nil && !slices.Contains(existingBranches, header.Branch):
+			reason = fmt.Sprintf("the branch %s no longer exists", header.Branch)
+		case retention > 0 && now.Sub(info.ModTime()) > retention:
+			reason = fmt.Sprintf("the state of branch %s wasn't updated since %s", header.Branch, info.ModTime().UTC().Format(time.RFC3339))
+		default:
+			continue
+		}
+		if err = os.Remove(statePath); err != nil {
+			return prunedFiles, fmt.Errorf("failed to remove the state file at %s: %s", statePath, err.Error())
+		}
+		log.Info(fmt.Sprintf("Removed the state file %s, as %s", entry.Name(), reason))
+		prunedFiles = append(prunedFiles, statePath)
+	}
+	return
+}
+
+func readStateFileHeader(statePath string) (header *stateFileHeader, info os.FileInfo, err error) {
+	if info, err = os.Stat(statePath); err != nil {
+		return
+	}
+	content, err := os.ReadFile(filepath.Clean(statePath))
+	if err != nil {
+		return
+	}
+	header = &stateFileHeader{}
+	err = json.Unmarshal(content, header)
+	return
+}
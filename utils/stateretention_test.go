@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStateRetention(t *testing.T) {
+	retention, err := ParseStateRetention("90d")
+	require.NoError(t, err)
+	assert.Equal(t, 90*24*time.Hour, retention)
+	retention, err = ParseStateRetention("720h")
+	require.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, retention)
+
+	for _, invalidRetention := range []string{"", "90", "month", "0d", "-1h"} {
+		_, err = ParseStateRetention(invalidRetention)
+		assert.Error(t, err, invalidRetention)
+	}
+}
+
+func TestPruneStateFiles(t *testing.T) {
+	stateDir := t.TempDir()
+	now := time.Now()
+	require.NoError(t, SaveVulnerabilitiesBaseline(stateDir, NewVulnerabilitiesBaseline("jfrog", "frogbot", "master", []string{"XRAY-1"})))
+	require.NoError(t, SaveFixPullRequestsRecord(stateDir, NewFixPullRequestsRecord("jfrog", "frogbot", "master")))
+	// The state of a branch that wasn't scanned for a long time
+	require.NoError(t, SaveVulnerabilitiesBaseline(stateDir, NewVulnerabilitiesBaseline("jfrog", "frogbot", "release", []string{"XRAY-2"})))
+	releasePath := getTestStateFilePath(t, stateDir, "jfrog", "frogbot", "release", baselineFileSuffix)
+	stale := now.Add(-100 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(releasePath, stale, stale))
+	// The state of a deleted branch
+	require.NoError(t, SaveFirstSeenRecord(stateDir, NewFirstSeenRecord("jfrog", "frogbot", "feature")))
+	// The stale state of another repository that shares the state directory
+	require.NoError(t, SaveVulnerabilitiesBaseline(stateDir, NewVulnerabilitiesBaseline("jfrog", "froggit-go", "feature", nil)))
+	otherRepoPath := getTestStateFilePath(t, stateDir, "jfrog", "froggit-go", "feature", baselineFileSuffix)
+	require.NoError(t, os.Chtimes(otherRepoPath, stale, stale))
+	// A file that isn't a state file
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "notes.json"), []byte("{}"), 0644))
+
+	prunedFiles, err := PruneStateFiles(stateDir, "jfrog", "frogbot", 90*24*time.Hour, []string{"master", "release"}, now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{releasePath, getTestStateFilePath(t, stateDir, "jfrog", "frogbot", "feature", firstSeenFileSuffix)}, prunedFiles)
+
+	// The current state is retained
+	for _, retainedPath := range []string{
+		getTestStateFilePath(t, stateDir, "jfrog", "frogbot", "master", baselineFileSuffix),
+		getTestStateFilePath(t, stateDir, "jfrog", "frogbot", "master", fixPullRequestsFileSuffix),
+		otherRepoPath,
+		filepath.Join(stateDir, "notes.json"),
+	} {
+		assert.FileExists(t, retainedPath)
+	}
+	for _, prunedPath := range prunedFiles {
+		assert.NoFileExists(t, prunedPath)
+	}
+
+	// Without a retention and with unknown branches nothing is pruned
+	prunedFiles, err = PruneStateFiles(stateDir, "jfrog", "frogbot", 0, nil, now.Add(1000*24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, prunedFiles)
+
+	// A missing state directory has nothing to prune
+	prunedFiles, err = PruneStateFiles(filepath.Join(stateDir, "missing"), "jfrog", "frogbot", time.Hour, nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, prunedFiles)
+}
+
+func getTestStateFilePath(t *testing.T, stateDir, repoOwner, repoName, branch, suffix string) string {
+	statePath, err := getStateFilePath(stateDir, repoOwner, repoName, branch, suffix)
+	require.NoError(t, err)
+	return statePath
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	trackingIssueId       = "FrogbotTrackingIssue"
+	trackingIssuesPerPage = 100
+)
+
+var trackingIssueMetadataRegex = regexp.MustCompile(`Branch: (\S+) Checksum: (\w+)`)
+
+// TrackingIssue is an issue Frogbot opens to track a vulnerability it can't fix.
+// The checksum identifies the tracked vulnerability, so each vulnerability is tracked by a single issue.
+type TrackingIssue struct {
+	Title    string
+	Body     string
+	Checksum string
+}
+
+// An issue, as returned by the GitHub REST API
+type gitHubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// Pull requests are listed as issues as well, and have this field set
+	PullRequest *json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// GenerateTrackingIssueBody returns the body of a tracking issue, marked with the branch and the checksum of the tracked vulnerability.
+func GenerateTrackingIssueBody(content, branch, checksum string) string {
+	return outputwriter.MarkdownComment(trackingIssueId) + content + outputwriter.MarkdownComment(fmt.Sprintf("Branch: %s Checksum: %s", branch, checksum))
+}
+
+// SyncGitHubTrackingIssues opens an issue for each of the given tracking issues on GitHub, or updates the issue that already tracks the same vulnerability.
+// The open tracking issues of the branch whose checksums aren't in detectedChecksums are closed, as their vulnerabilities are no longer detected.
+func SyncGitHubTrackingIssues(apiEndpoint, token, owner, repo, branch string, trackingIssues []TrackingIssue, detectedChecksums []string) (err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	apiEndpoint = getGitHubApiEndpoint(apiEndpoint)
+	openIssues, err := getGitHubOpenTrackingIssues(client.GetClient(), apiEndpoint, token, owner, repo, branch)
+	if err != nil {
+		return fmt.Errorf("failed to list the issues of %s/%s: %s", owner, repo, err.Error())
+	}
+	issuesUrl := fmt.Sprintf("%s/repos/%s/%s/issues", apiEndpoint, owner, repo)
+	for _, trackingIssue := range trackingIssues {
+		body := GenerateTrackingIssueBody(trackingIssue.Body, branch, trackingIssue.Checksum)
+		openIssue, exists := openIssues[trackingIssue.Checksum]
+		if !exists {
+			log.Info("Opening an issue:", trackingIssue.Title)
+			if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPost, issuesUrl, token, map[string]string{"title": trackingIssue.Title, "body": body}); err != nil {
+				return fmt.Errorf("failed to open the issue '%s': %s", trackingIssue.Title, err.Error())
+			}
+			continue
+		}
+		// The vulnerability is still tracked, so its issue isn't closed
+		delete(openIssues, trackingIssue.Checksum)
+		if openIssue.Title == trackingIssue.Title && openIssue.Body == body {
+			continue
+		}
+		log.Info(fmt.Sprintf("Updating issue #%d: %s", openIssue.Number, trackingIssue.Title))
+		if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPatch, fmt.Sprintf("%s/%d", issuesUrl, openIssue.Number), token, map[string]string{"title": trackingIssue.Title, "body": body}); err != nil {
+			return fmt.Errorf("failed to update issue #%d: %s", openIssue.Number, err.Error())
+		}
+	}
+	for checksum, staleIssue := range openIssues {
+		if slices.Contains(detectedChecksums, checksum) {
+			continue
+		}
+		log.Info(fmt.Sprintf("Closing issue #%d, as its vulnerability is no longer detected: %s", staleIssue.Number, staleIssue.Title))
+		if _, _, err = sendGitHubApiRequest(client.GetClient(), http.MethodPatch, fmt.Sprintf("%s/%d", issuesUrl, staleIssue.Number), token, map[string]string{"state": "closed", "state_reason": "completed"}); err != nil {
+			return fmt.Errorf("failed to close issue #%d: %s", staleIssue.Number, err.Error())
+		}
+	}
+	return
+}
+
+// Returns the open tracking issues of the branch, mapped by their checksums
+func getGitHubOpenTrackingIssues(client *http.Client, apiEndpoint, token, owner, repo, branch string) (map[string]gitHubIssue, error) {
+	trackingIssues := make(map[string]gitHubIssue)
+	pageUrl := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&per_page=%d", apiEndpoint, owner, repo, trackingIssuesPerPage)
+	for pageUrl != "" {
+		body, header, err := sendGitHubApiRequest(client, http.MethodGet, pageUrl, token, nil)
+		if err != nil {
+			return nil, err
+		}
+		var issues []gitHubIssue
+		if err = json.Unmarshal(body, &issues); err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.PullRequest != nil || !strings.Contains(issue.Body, trackingIssueId) {
+				continue
+			}
+			if match := trackingIssueMetadataRegex.FindStringSubmatch(issue.Body); match != nil && match[1] == branch {
+				trackingIssues[match[2]] = issue
+			}
+		}
+		pageUrl = getGitHubNextPageUrl(header)
+	}
+	return trackingIssues, nil
+}
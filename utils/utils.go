@@ -28,6 +28,7 @@ import (
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/owenrumney/go-sarif/v2/sarif"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -35,19 +36,33 @@ const (
 	ScanAllPullRequests      = "scan-all-pull-requests"
 	ScanRepository           = "scan-repository"
 	ScanMultipleRepositories = "scan-multiple-repositories"
-	RootDir                  = "."
-	branchNameRegex          = `[~^:?\\\[\]@{}*]`
+	// The hidden command that audits a working directory in a process of its own
+	AuditWorkingDir = "audit-working-dir"
+	RootDir         = "."
+	branchNameRegex = `[~^:?\\\[\]@{}*]`
 
 	// Branch validation error messages
-	branchInvalidChars             = "branch name cannot contain the following chars  ~, ^, :, ?, *, [, ], @, {, }"
-	branchInvalidPrefix            = "branch name cannot start with '-' "
-	branchCharsMaxLength           = 255
-	branchInvalidLength            = "branch name length exceeded " + string(rune(branchCharsMaxLength)) + " chars"
-	skipIndirectVulnerabilitiesMsg = "\n%s is an indirect dependency that will not be updated to version %s.\nFixing indirect dependencies can potentially cause conflicts with other dependencies that depend on the previous version.\nFrogbot skips this to avoid potential incompatibilities and breaking changes."
-	skipBuildToolDependencyMsg     = "Skipping vulnerable package %s since it is not defined in your package descriptor file. " +
+	branchInvalidChars              = "branch name cannot contain the following chars  ~, ^, :, ?, *, [, ], @, {, }"
+	branchInvalidPrefix             = "branch name cannot start with '-' "
+	branchCharsMaxLength            = 255
+	branchInvalidLength             = "branch name length exceeded " + string(rune(branchCharsMaxLength)) + " chars"
+	skipIndirectVulnerabilitiesMsg  = "\n%s is an indirect dependency that will not be updated to version %s.\nFixing indirect dependencies can potentially cause conflicts with other dependencies that depend on the previous version.\nFrogbot skips this to avoid potential incompatibilities and breaking changes."
+	skipGitDependencyMsg            = "Skipping vulnerable package %s since it is declared with a git or URL specifier that can't be updated to version %s: %s"
+	noFixVersionMsg                 = "No fixed version of %s is available yet."
+	fixExceedsVersionJumpMsg        = "Fix exceeds allowed version jump: updating %s to version %s exceeds the allowed version jump of %s."
+	fixCrossesVersionCeilingMsg     = "Only cross-boundary fix available: updating %s to version %s isn't allowed by the '%s' fix version ceiling policy."
+	fixVersionNotAvailableMsg       = "Skipping vulnerable package %s since version %s isn't available on the configured package indexes: %s"
+	provenanceVerificationFailedMsg = "Skipping vulnerable package %s since the provenance verification of version %s failed: %s"
+	unsupportedRuntimeMsg           = "Skipping vulnerable package %s since version %s and the newer eligible versions require an unsupported runtime: %s"
+	metadataLookupFailedMsg         = "Skipping vulnerable package %s since the metadata of version %s couldn't be fetched from the registry, and it's required: %s"
+	skipPeerDependencyMsg           = "Skipping vulnerable package %s since it is a peer dependency, and fixing peer dependencies is disabled. Update %s to version %s after reviewing its compatibility with the consumers of the package."
+	skipBuildToolDependencyMsg      = "Skipping vulnerable package %s since it is not defined in your package descriptor file. " +
 		"Update %s version to %s to fix this vulnerability."
 	JfrogHomeDirEnv = "JFROG_CLI_HOME_DIR"
 
+	// The number of lines at the end of a failed command output that are included in its error
+	commandOutputTailLines = 30
+
 	// Sarif run output tool annotator
 	sarifToolName = "JFrog Frogbot"
 )
@@ -67,21 +82,103 @@ type ErrUnsupportedFix struct {
 	PackageName  string
 	FixedVersion string
 	ErrorType    UnsupportedErrorType
+	// The reason the fix isn't supported, if it depends on the specific dependency
+	Reason string
 }
 
 type ErrNothingToCommit struct {
 	PackageName string
 }
 
+// ErrEmptyBranch is returned when the scanned branch has no commits, as in a newly created repository
+type ErrEmptyBranch struct {
+	BranchName string
+}
+
+// ErrCommandFailed is returned when a package manager command fails, and holds the full output the command printed
+type ErrCommandFailed struct {
+	Command string
+	// Why the command failed, such as its exit status
+	Reason string
+	Output string
+}
+
 // Custom error for unsupported fixes
-// Currently we hold two unsupported reasons, indirect and build tools dependencies.
+// Currently we hold eleven unsupported reasons, indirect, build tools and git specifier dependencies, vulnerabilities without a fixed version, fixes that exceed the allowed version jump or cross the fix version ceiling,
+// fixed versions that aren't available on the configured package indexes, peer dependencies when fixing them is disabled, fixed versions whose provenance verification failed when it blocks the fix,
+// fixed versions that require a newer runtime than the configured one, and fixed versions whose required registry metadata couldn't be fetched.
+// Summary returns a short description of the reason the fix isn't supported, to be listed next to the package
+func (err *ErrUnsupportedFix) Summary() string {
+	switch err.ErrorType {
+	case NoFixVersionAvailable:
+		return "no fix version is available"
+	case IndirectDependencyFixNotSupported:
+		return "indirect dependency"
+	case GitDependencyFixNotSupported:
+		return "declared with a git or URL specifier"
+	case FixExceedsVersionJump:
+		return "exceeds the allowed version jump of " + err.Reason
+	case FixCrossesVersionCeiling:
+		return fmt.Sprintf("not allowed by the '%s' fix version ceiling policy", err.Reason)
+	case FixVersionNotAvailableOnIndex:
+		return "the fix version isn't available on the package indexes"
+	case PeerDependencyFixSkipped:
+		return "peer dependency"
+	case ProvenanceVerificationFailed:
+		return "the provenance verification of the fix version failed"
+	case FixRequiresUnsupportedRuntime:
+		return "fix requires unsupported runtime"
+	case MetadataLookupFailed:
+		return "the metadata of the fix version couldn't be fetched"
+	case UnsupportedForFixVulnerableVersion:
+		return "the vulnerable version can't be fixed"
+	}
+	return "not declared in the package descriptor"
+}
+
 func (err *ErrUnsupportedFix) Error() string {
-	if err.ErrorType == IndirectDependencyFixNotSupported {
+	switch err.ErrorType {
+	case NoFixVersionAvailable:
+		return fmt.Sprintf(noFixVersionMsg, err.PackageName)
+	case IndirectDependencyFixNotSupported:
 		return fmt.Sprintf(skipIndirectVulnerabilitiesMsg, err.PackageName, err.FixedVersion)
+	case GitDependencyFixNotSupported:
+		return fmt.Sprintf(skipGitDependencyMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case FixExceedsVersionJump:
+		return fmt.Sprintf(fixExceedsVersionJumpMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case FixCrossesVersionCeiling:
+		return fmt.Sprintf(fixCrossesVersionCeilingMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case FixVersionNotAvailableOnIndex:
+		return fmt.Sprintf(fixVersionNotAvailableMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case PeerDependencyFixSkipped:
+		return fmt.Sprintf(skipPeerDependencyMsg, err.PackageName, err.PackageName, err.FixedVersion)
+	case ProvenanceVerificationFailed:
+		return fmt.Sprintf(provenanceVerificationFailedMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case FixRequiresUnsupportedRuntime:
+		return fmt.Sprintf(unsupportedRuntimeMsg, err.PackageName, err.FixedVersion, err.Reason)
+	case MetadataLookupFailed:
+		return fmt.Sprintf(metadataLookupFailedMsg, err.PackageName, err.FixedVersion, err.Reason)
 	}
 	return fmt.Sprintf(skipBuildToolDependencyMsg, err.PackageName, err.PackageName, err.FixedVersion)
 }
 
+func (err *ErrEmptyBranch) Error() string {
+	return fmt.Sprintf("branch '%s' has no commits", err.BranchName)
+}
+
+func (err *ErrCommandFailed) Error() string {
+	return fmt.Sprintf("'%s' command %s\n%s", err.Command, err.Reason, err.OutputTail())
+}
+
+// OutputTail returns the last lines of the command output, where package managers usually print the cause of the failure
+func (err *ErrCommandFailed) OutputTail() string {
+	lines := strings.Split(strings.TrimRight(err.Output, "\n"), "\n")
+	if len(lines) <= commandOutputTailLines {
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("... (%d earlier lines omitted)\n%s", len(lines)-commandOutputTailLines, strings.Join(lines[len(lines)-commandOutputTailLines:], "\n"))
+}
+
 func (err *ErrNothingToCommit) Error() string {
 	return fmt.Sprintf("there were no changes to commit after fixing the package '%s'.\n"+
 		"Note: Frogbot currently cannot address certain vulnerabilities in some package managers, which may result in the absence of changes", err.PackageName)
@@ -96,6 +193,18 @@ type VulnerabilityDetails struct {
 	IsDirectDependency bool
 	// Cves as a list of string
 	Cves []string
+	// Notes about the applied fix, added to the pull request body
+	FixNotes []string
+	// The newest version that fixes the vulnerability, which may be on a newer release line than the suggested fix version
+	NewestFixedVersion string
+	// For transitive dependencies, the path from the direct dependency down to the vulnerable dependency
+	TransitiveImpactPath []formats.ComponentRow
+	// Security alerts of the VCS provider that are addressed by fixing the vulnerability
+	SecurityAlerts []outputwriter.SecurityAlertRow
+	// The merged fix of the package that was reverted, if the vulnerability is a regression
+	Regression *MergedFix
+	// The breaking changes the advisories of the vulnerabilities note in the fix
+	BreakingChanges []string
 }
 
 func NewVulnerabilityDetails(vulnerability formats.VulnerabilityOrViolationRow, fixVersion string) *VulnerabilityDetails {
@@ -104,19 +213,70 @@ func NewVulnerabilityDetails(vulnerability formats.VulnerabilityOrViolationRow,
 		SuggestedFixedVersion:       fixVersion,
 	}
 	vulnDetails.SetCves(vulnerability.Cves)
+	vulnDetails.AddBreakingChanges(vulnerability)
 	return vulnDetails
 }
 
+// AddBreakingChanges records the breaking changes the advisory of the vulnerability notes in the fix
+func (vd *VulnerabilityDetails) AddBreakingChanges(vulnerability formats.VulnerabilityOrViolationRow) {
+	for _, note := range GetAdvisoryBreakingChanges(vulnerability) {
+		if !slices.Contains(vd.BreakingChanges, note) {
+			vd.BreakingChanges = append(vd.BreakingChanges, note)
+		}
+	}
+}
+
+func (vd *VulnerabilityDetails) HasBreakingChanges() bool {
+	return len(vd.BreakingChanges) > 0
+}
+
 func (vd *VulnerabilityDetails) SetIsDirectDependency(isDirectDependency bool) {
 	vd.IsDirectDependency = isDirectDependency
 }
 
+// SetTransitiveImpactPath records the path from the direct dependency down to the vulnerable dependency, out of the first impact path.
+// The first component of an impact path is the project itself, so the path is recorded only if it has more than one dependency.
+func (vd *VulnerabilityDetails) SetTransitiveImpactPath(impactPaths [][]formats.ComponentRow) {
+	if len(impactPaths) == 0 || len(impactPaths[0]) < 3 {
+		return
+	}
+	vd.TransitiveImpactPath = impactPaths[0][1:]
+}
+
+// SetSecurityAlerts records the alerts that were raised for the vulnerable package and one of the vulnerability CVEs.
+func (vd *VulnerabilityDetails) SetSecurityAlerts(alerts []outputwriter.SecurityAlertRow) {
+	vd.SecurityAlerts = nil
+	for _, alert := range alerts {
+		if strings.EqualFold(alert.PackageName, vd.ImpactedDependencyName) && alert.CveId != "" && slices.Contains(vd.Cves, alert.CveId) {
+			vd.SecurityAlerts = append(vd.SecurityAlerts, alert)
+		}
+	}
+}
+
 func (vd *VulnerabilityDetails) SetCves(cves []formats.CveRow) {
 	for _, cve := range cves {
-		vd.Cves = append(vd.Cves, cve.Id)
+		// Several vulnerabilities of the same package may share a CVE
+		if !slices.Contains(vd.Cves, cve.Id) {
+			vd.Cves = append(vd.Cves, cve.Id)
+		}
 	}
 }
 
+// ResolvedCves returns the CVEs the suggested fix version resolves
+func (vd *VulnerabilityDetails) ResolvedCves() (cves []string) {
+	for _, cve := range vd.Cves {
+		if cve != "" {
+			cves = append(cves, cve)
+		}
+	}
+	return
+}
+
+// ResolvesMultipleCves returns true if the fix resolves more than one CVE, which makes it a high-value fix to review
+func (vd *VulnerabilityDetails) ResolvesMultipleCves() bool {
+	return len(vd.ResolvedCves()) > 1
+}
+
 func (vd *VulnerabilityDetails) UpdateFixVersionIfMax(fixVersion string) {
 	// Update vd.FixVersion as the maximum version if found a new version that is greater than the previous maximum version.
 	if vd.SuggestedFixedVersion == "" || version.NewVersion(vd.SuggestedFixedVersion).Compare(fixVersion) > 0 {
@@ -124,6 +284,83 @@ func (vd *VulnerabilityDetails) UpdateFixVersionIfMax(fixVersion string) {
 	}
 }
 
+func (vd *VulnerabilityDetails) UpdateNewestFixedVersionIfMax(fixVersion string) {
+	if fixVersion == "" {
+		return
+	}
+	if vd.NewestFixedVersion == "" || version.NewVersion(vd.NewestFixedVersion).Compare(fixVersion) > 0 {
+		vd.NewestFixedVersion = fixVersion
+	}
+}
+
+func (vd *VulnerabilityDetails) AddFixNote(note string) {
+	vd.FixNotes = append(vd.FixNotes, note)
+}
+
+func ExtractMultipleCvesFixes(vulnDetails []*VulnerabilityDetails) (rows []outputwriter.MultipleCvesFixRow) {
+	for _, vuln := range vulnDetails {
+		if vuln.ResolvesMultipleCves() {
+			rows = append(rows, outputwriter.MultipleCvesFixRow{PackageName: vuln.ImpactedDependencyName, FixVersion: vuln.SuggestedFixedVersion, Cves: vuln.ResolvedCves()})
+		}
+	}
+	return
+}
+
+// ExtractFixedPackagesStatus returns the status rows of the fixed packages, sorted by name
+func ExtractFixedPackagesStatus(vulnDetails []*VulnerabilityDetails) (rows []outputwriter.PackageStatusRow) {
+	for _, vuln := range vulnDetails {
+		rows = append(rows, outputwriter.PackageStatusRow{
+			PackageName:    vuln.ImpactedDependencyName,
+			CurrentVersion: vuln.ImpactedDependencyVersion,
+			TargetVersion:  vuln.SuggestedFixedVersion,
+			Severity:       vuln.Severity,
+			Applicable:     vuln.Applicable,
+		})
+	}
+	slices.SortFunc(rows, func(a, b outputwriter.PackageStatusRow) int {
+		return strings.Compare(a.PackageName, b.PackageName)
+	})
+	return
+}
+
+func ExtractRegressions(vulnDetails []*VulnerabilityDetails) (rows []outputwriter.RegressionRow) {
+	for _, vuln := range vulnDetails {
+		if vuln.Regression != nil {
+			rows = append(rows, outputwriter.RegressionRow{PackageName: vuln.ImpactedDependencyName, VulnerableVersion: vuln.ImpactedDependencyVersion, FixVersion: vuln.Regression.FixVersion, PullRequestUrl: vuln.Regression.PullRequestUrl})
+		}
+	}
+	return
+}
+
+func ExtractFixNotes(vulnDetails []*VulnerabilityDetails) (notes []string) {
+	for _, vuln := range vulnDetails {
+		notes = append(notes, vuln.FixNotes...)
+	}
+	return
+}
+
+func ExtractTransitiveDependencies(vulnDetails []*VulnerabilityDetails) (rows []outputwriter.TransitiveDependencyRow) {
+	for _, vuln := range vulnDetails {
+		if len(vuln.TransitiveImpactPath) == 0 {
+			continue
+		}
+		rows = append(rows, outputwriter.TransitiveDependencyRow{ImpactPath: vuln.TransitiveImpactPath, FixVersion: vuln.SuggestedFixedVersion})
+	}
+	return
+}
+
+// ExtractSecurityAlerts returns the security alerts addressed by the vulnerabilities fixes, where each alert appears once.
+func ExtractSecurityAlerts(vulnDetails []*VulnerabilityDetails) (alerts []outputwriter.SecurityAlertRow) {
+	for _, vuln := range vulnDetails {
+		for _, alert := range vuln.SecurityAlerts {
+			if !slices.ContainsFunc(alerts, func(added outputwriter.SecurityAlertRow) bool { return added.Number == alert.Number }) {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+	return
+}
+
 func ExtractVulnerabilitiesDetailsToRows(vulnDetails []*VulnerabilityDetails) []formats.VulnerabilityOrViolationRow {
 	var rows []formats.VulnerabilityOrViolationRow
 	for _, vuln := range vulnDetails {
@@ -225,6 +462,33 @@ func VulnerabilityDetailsToMD5Hash(vulnerabilities ...formats.VulnerabilityOrVio
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// FixPullRequestChecksum returns the checksum recorded in an aggregated fix pull request.
+// A custom layout of the pull request body is part of the checksum, so changing the layout updates the pull request.
+func FixPullRequestChecksum(hasher *Hasher, bodySections []string, vulnerabilities ...*VulnerabilityDetails) (string, error) {
+	checksum, err := FixedPackagesChecksum(hasher, vulnerabilities...)
+	if err != nil || slices.Equal(bodySections, outputwriter.DefaultPullRequestBodySections) {
+		return checksum, err
+	}
+	return hasher.Hash(checksum, strings.Join(bodySections, ","))
+}
+
+// FixedPackagesChecksum returns the checksum of the fixed packages, their current and fix versions and the CVEs the fixes resolve.
+// The rendered body isn't hashed, as it may change for non-substantive reasons, such as research details or links that are omitted when they aren't available,
+// so only a change of the fixes updates the pull request. The fixes are sorted, as Xray may return the vulnerabilities in a different order.
+func FixedPackagesChecksum(hasher *Hasher, vulnerabilities ...*VulnerabilityDetails) (string, error) {
+	var keys []string
+	for _, vulnDetails := range vulnerabilities {
+		ids := getNormalizedCves(vulnDetails)
+		if len(ids) == 0 {
+			// Vulnerabilities without a CVE or a GHSA ID are identified by their Xray issue
+			ids = append(ids, vulnDetails.IssueId)
+		}
+		keys = append(keys, strings.Join([]string{vulnDetails.ImpactedDependencyName, vulnDetails.ImpactedDependencyVersion, vulnDetails.SuggestedFixedVersion, strings.Join(ids, ",")}, "|"))
+	}
+	slices.Sort(keys)
+	return hasher.Hash(strings.Join(keys, "\n"))
+}
+
 func UploadSarifResultsToGithubSecurityTab(scanResults *xrayutils.Results, repo *Repository, branch string, client vcsclient.VcsClient) error {
 	report, err := GenerateFrogbotSarifReport(scanResults, scanResults.IsMultipleProject(), repo.AllowedLicenses)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/jfrog/jfrog-cli-security/utils/techutils"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestChdir(t *testing.T) {
@@ -148,6 +149,55 @@ func TestFixVersionsMapToMd5Hash(t *testing.T) {
 	}
 }
 
+func TestFixedPackagesChecksum(t *testing.T) {
+	newFixes := func(summary string, fixNotes ...string) []*VulnerabilityDetails {
+		lodash := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			Summary:                   summary,
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "High"}, ImpactedDependencyName: "lodash", ImpactedDependencyVersion: "4.17.20"},
+			Cves:                      []formats.CveRow{{Id: "CVE-2021-23337"}, {Id: "CVE-2020-28500"}},
+			Technology:                techutils.Npm,
+		}, "4.17.21")
+		lodash.FixNotes = fixNotes
+		minimist := NewVulnerabilityDetails(formats.VulnerabilityOrViolationRow{
+			Summary:                   summary,
+			ImpactedDependencyDetails: formats.ImpactedDependencyDetails{SeverityDetails: formats.SeverityDetails{Severity: "Critical"}, ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"},
+			IssueId:                   "XRAY-264729",
+			Technology:                techutils.Npm,
+		}, "1.2.6")
+		return []*VulnerabilityDetails{lodash, minimist}
+	}
+	fixes := newFixes("Prototype pollution")
+	checksum, err := FixedPackagesChecksum(NewHasher(Md5HashAlgorithm, 0), fixes...)
+	require.NoError(t, err)
+
+	// The rendered body differs cosmetically, such as by a rephrased summary, a note, the order of the fixes and the order and case of the CVEs
+	cosmeticFixes := newFixes("Prototype pollution in the merge functions", "See the changelog of lodash for the details.")
+	cosmeticFixes[0].Cves = []string{"cve-2020-28500", "CVE-2021-23337"}
+	cosmeticFixes[0], cosmeticFixes[1] = cosmeticFixes[1], cosmeticFixes[0]
+	body, _ := GenerateAggregatedFixPullRequestDetails(fixes, nil, &outputwriter.StandardOutput{})
+	cosmeticBody, _ := GenerateAggregatedFixPullRequestDetails(cosmeticFixes, nil, &outputwriter.StandardOutput{})
+	assert.NotEqual(t, body, cosmeticBody)
+	cosmeticChecksum, err := FixedPackagesChecksum(NewHasher(Md5HashAlgorithm, 0), cosmeticFixes...)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, cosmeticChecksum)
+
+	// A substantive change of the fixes changes the checksum
+	for _, change := range []func(fixes []*VulnerabilityDetails){
+		func(fixes []*VulnerabilityDetails) { fixes[0].SuggestedFixedVersion = "4.17.22" },
+		func(fixes []*VulnerabilityDetails) { fixes[0].Cves = append(fixes[0].Cves, "CVE-2020-8203") },
+		func(fixes []*VulnerabilityDetails) { fixes[1].ImpactedDependencyVersion = "1.2.4" },
+	} {
+		changedFixes := newFixes("Prototype pollution")
+		change(changedFixes)
+		changedChecksum, err := FixedPackagesChecksum(NewHasher(Md5HashAlgorithm, 0), changedFixes...)
+		require.NoError(t, err)
+		assert.NotEqual(t, checksum, changedChecksum)
+	}
+	changedChecksum, err := FixedPackagesChecksum(NewHasher(Md5HashAlgorithm, 0), fixes[:1]...)
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, changedChecksum)
+}
+
 func TestGetRelativeWd(t *testing.T) {
 	fullPath := filepath.Join("a", "b", "c", "d", "e")
 	baseWd := filepath.Join("a", "b", "c")
@@ -446,3 +496,19 @@ func TestIsUrlAccessible(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMaxVersionJump(t *testing.T) {
+	maxVersionJump, err := ParseMaxVersionJump("2-Minor")
+	assert.NoError(t, err)
+	assert.Equal(t, &MaxVersionJump{Count: 2, Component: MinorVersionComponent}, maxVersionJump)
+	assert.Equal(t, "2-minor", maxVersionJump.String())
+	assert.True(t, maxVersionJump.IsAllowed("1.2.3", "1.4.0-rc.1"))
+	assert.False(t, maxVersionJump.IsAllowed("1.2.3", "1.5.0"))
+	// Versions that can't be measured are allowed
+	assert.True(t, maxVersionJump.IsAllowed("1.2.3", "1.5.Final"))
+
+	for _, invalid := range []string{"2", "minor", "two-minor", "2-build", "-1-major"} {
+		_, err = ParseMaxVersionJump(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
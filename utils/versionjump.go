@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxVersionJump limits how far a fix version may be from the impacted version, such as 2 minor versions.
+// A jump in a more significant component always exceeds the limit, so a limit of 2 minor versions doesn't allow any major version jump.
+type MaxVersionJump struct {
+	Count     int
+	Component VersionComponent
+}
+
+// ParseMaxVersionJump parses a version jump in the format of <count>-<component>, such as 2-minor or 1-major
+func ParseMaxVersionJump(maxVersionJump string) (*MaxVersionJump, error) {
+	countStr, component, found := strings.Cut(strings.ToLower(strings.TrimSpace(maxVersionJump)), "-")
+	count, err := strconv.Atoi(countStr)
+	if !found || err != nil || count < 0 || (VersionComponent(component) != MajorVersionComponent && VersionComponent(component) != MinorVersionComponent && VersionComponent(component) != PatchVersionComponent) {
+		return nil, fmt.Errorf("the provided max version jump '%s' is invalid. The expected format is <count>-<%s|%s|%s>, such as 2-minor", maxVersionJump, MajorVersionComponent, MinorVersionComponent, PatchVersionComponent)
+	}
+	return &MaxVersionJump{Count: count, Component: VersionComponent(component)}, nil
+}
+
+func (mvj *MaxVersionJump) String() string {
+	return fmt.Sprintf("%d-%s", mvj.Count, mvj.Component)
+}
+
+// IsAllowed returns true if the jump from the impacted version to the fix version doesn't exceed the limit.
+// Versions whose components aren't numeric can't be measured, and are therefore allowed.
+func (mvj *MaxVersionJump) IsAllowed(impactedVersion, fixVersion string) bool {
+	impactedComponents, impactedOk := getNumericVersionComponents(impactedVersion)
+	fixComponents, fixOk := getNumericVersionComponents(fixVersion)
+	if !impactedOk || !fixOk {
+		return true
+	}
+	var limitedComponent int
+	switch mvj.Component {
+	case MajorVersionComponent:
+		limitedComponent = 0
+	case MinorVersionComponent:
+		limitedComponent = 1
+	default:
+		limitedComponent = 2
+	}
+	for i := 0; i < limitedComponent; i++ {
+		if fixComponents[i] != impactedComponents[i] {
+			return fixComponents[i] < impactedComponents[i]
+		}
+	}
+	return fixComponents[limitedComponent]-impactedComponents[limitedComponent] <= mvj.Count
+}
+
+// Returns the major, minor and patch components of the version. Missing components are considered 0.
+// Pre-release and build metadata, such as -rc.1 or +build, are ignored.
+func getNumericVersionComponents(fullVersion string) (components [3]int, ok bool) {
+	fullVersion = strings.TrimPrefix(fullVersion, "v")
+	if index := strings.IndexAny(fullVersion, "-+"); index >= 0 {
+		fullVersion = fullVersion[:index]
+	}
+	versionParts := strings.Split(fullVersion, ".")
+	for i := 0; i < len(components) && i < len(versionParts); i++ {
+		number, err := strconv.Atoi(versionParts[i])
+		if err != nil {
+			return components, false
+		}
+		components[i] = number
+	}
+	return components, true
+}
+
+// GetVersionComponent returns the component of the split version at the index. Missing components are treated as zeros, i.e. 1.2 is equivalent to 1.2.0
+func GetVersionComponent(components []string, index int) string {
+	if index >= len(components) {
+		return "0"
+	}
+	return components[index]
+}
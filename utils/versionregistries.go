@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The package ecosystems whose registries can be checked for the fix versions, and the technologies that install packages from them
+var registryEcosystemTechnologies = map[string][]techutils.Technology{
+	"npm":   {techutils.Npm, techutils.Yarn, techutils.Pnpm},
+	"pypi":  {techutils.Pip, techutils.Pipenv, techutils.Poetry},
+	"maven": {techutils.Maven, techutils.Gradle},
+	"go":    {techutils.Go},
+	"nuget": {techutils.Nuget, techutils.Dotnet},
+}
+
+// The package ecosystems whose registries publish provenance attestations the fix versions can be verified with
+var provenanceEcosystems = []string{"npm", "pypi"}
+
+var runtimeVersionRegexp = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// RuntimeVersions maps the package ecosystems to the version of the runtime their fix versions must support, such as npm to the version of Node.js
+type RuntimeVersions map[string]string
+
+// VersionRegistries maps the package ecosystems to the registries and mirrors the fix versions must be available on before they are suggested.
+type VersionRegistries map[string][]string
+
+// ParseVersionRegistries parses entries in the format of <ecosystem>=<registry URL>, such as npm=https://registry.npmjs.org.
+// An ecosystem may be listed more than once, to check the fix versions on each of its mirrors.
+func ParseVersionRegistries(entries []string) (VersionRegistries, error) {
+	registries := VersionRegistries{}
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		ecosystem, registryUrl, found := strings.Cut(entry, "=")
+		ecosystem, registryUrl = strings.ToLower(strings.TrimSpace(ecosystem)), strings.TrimSpace(registryUrl)
+		if !found || registryUrl == "" {
+			return nil, fmt.Errorf("the provided version registry '%s' is invalid. The expected format is <ecosystem>=<registry URL>, such as npm=https://registry.npmjs.org", entry)
+		}
+		if _, supported := registryEcosystemTechnologies[ecosystem]; !supported {
+			ecosystems := maps.Keys(registryEcosystemTechnologies)
+			slices.Sort(ecosystems)
+			return nil, fmt.Errorf("the ecosystem '%s' of the provided version registry is invalid. Valid ecosystems are: %s", ecosystem, strings.Join(ecosystems, ", "))
+		}
+		if parsedUrl, err := url.Parse(registryUrl); err != nil || parsedUrl.Scheme == "" || parsedUrl.Host == "" {
+			return nil, fmt.Errorf("the URL of the provided version registry '%s' is invalid", entry)
+		}
+		registries[ecosystem] = append(registries[ecosystem], strings.TrimSuffix(registryUrl, "/"))
+	}
+	return registries, nil
+}
+
+// ParseProvenanceEcosystems parses the ecosystems whose fix versions are verified with their provenance attestations, such as npm.
+func ParseProvenanceEcosystems(entries []string) (ecosystems []string, err error) {
+	for _, entry := range entries {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry == "" {
+			continue
+		}
+		if !slices.Contains(provenanceEcosystems, entry) {
+			return nil, fmt.Errorf("verifying the provenance of the %s ecosystem isn't supported. Valid ecosystems are: %s", entry, strings.Join(provenanceEcosystems, ", "))
+		}
+		ecosystems = append(ecosystems, entry)
+	}
+	return
+}
+
+// GetRegistries returns the ecosystem of the technology, and the registries its fix versions must be available on
+func (vr VersionRegistries) GetRegistries(tech techutils.Technology) (ecosystem string, registries []string) {
+	for ecosystem, technologies := range registryEcosystemTechnologies {
+		if slices.Contains(technologies, tech) {
+			return ecosystem, vr[ecosystem]
+		}
+	}
+	return "", nil
+}
+
+// RedactRegistryUrls returns the URLs of the registries without the credentials they may contain, so they can be logged and reported
+func RedactRegistryUrls(registryUrls []string) string {
+	var redactedUrls []string
+	for _, registryUrl := range registryUrls {
+		if parsedUrl, err := url.Parse(registryUrl); err == nil {
+			registryUrl = parsedUrl.Redacted()
+		}
+		redactedUrls = append(redactedUrls, registryUrl)
+	}
+	return strings.Join(redactedUrls, ", ")
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jfrog/frogbot/v2/utils/outputwriter"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	defaultGitLabApiEndpoint = "https://gitlab.com/api/v4"
+	wikiSummaryStartMarker   = "FrogbotRunSummaryStart"
+	wikiSummaryEndMarker     = "FrogbotRunSummaryEnd"
+)
+
+// A wiki page, as returned by the GitLab REST API
+type gitLabWikiPage struct {
+	Content string `json:"content"`
+}
+
+// UpdateWikiPageWithRunSummary replaces the Frogbot section of the configured wiki page with the run summary, so the content that was added to the page manually is preserved.
+// The page is created if it doesn't exist. Wikis are updated through the GitLab API, so the page isn't updated on other Git providers.
+func UpdateWikiPageWithRunSummary(repository *Repository, summary *RunSummary) (err error) {
+	if repository.UpdateWikiPage == "" {
+		return
+	}
+	if repository.GitProvider != vcsutils.GitLab {
+		log.Warn(fmt.Sprintf("Updating the wiki page '%s' with the run summary isn't supported on %s. Skipping...", repository.UpdateWikiPage, repository.GitProvider.String()))
+		return
+	}
+	summaryContent := GetMarkdownRunSummary(summary, repository.IsRedacted(WikiSummaryTarget))
+	if err = upsertGitLabWikiPageSection(repository.APIEndpoint, repository.Token, repository.RepoOwner, repository.RepoName, repository.UpdateWikiPage, summaryContent); err != nil {
+		return fmt.Errorf("failed to update the wiki page '%s' with the run summary: %s", repository.UpdateWikiPage, err.Error())
+	}
+	return
+}
+
+// ReplaceWikiSummarySection returns the content of the wiki page with its Frogbot section replaced by the summary.
+// If the page has no Frogbot section yet, the section is appended to the page.
+func ReplaceWikiSummarySection(pageContent, summaryContent string) string {
+	startMarker, endMarker := outputwriter.MarkdownComment(wikiSummaryStartMarker), outputwriter.MarkdownComment(wikiSummaryEndMarker)
+	section := startMarker + summaryContent + endMarker
+	start := strings.Index(pageContent, strings.TrimSpace(startMarker))
+	end := strings.Index(pageContent, strings.TrimSpace(endMarker))
+	if start == -1 || end < start {
+		return strings.TrimRight(pageContent, "\n") + section
+	}
+	return strings.TrimRight(pageContent[:start], "\n") + section + strings.TrimPrefix(pageContent[end+len(strings.TrimSpace(endMarker)):], "\n")
+}
+
+func upsertGitLabWikiPageSection(apiEndpoint, token, owner, repo, title, summaryContent string) (err error) {
+	client, err := newGitProviderHttpClient()
+	if err != nil {
+		return
+	}
+	if apiEndpoint == "" {
+		apiEndpoint = defaultGitLabApiEndpoint
+	}
+	wikisUrl := fmt.Sprintf("%s/projects/%s/wikis", strings.TrimSuffix(apiEndpoint, "/"), url.PathEscape(owner+"/"+repo))
+	// GitLab replaces the spaces in the titles of the pages with hyphens in their slugs
+	pageUrl := fmt.Sprintf("%s/%s", wikisUrl, url.PathEscape(strings.ReplaceAll(title, " ", "-")))
+	body, statusCode, err := sendGitLabApiRequest(client.GetClient(), http.MethodGet, pageUrl, token, nil)
+	if err != nil && statusCode != http.StatusNotFound {
+		return
+	}
+	if statusCode == http.StatusNotFound {
+		log.Info("Creating the wiki page", title)
+		_, _, err = sendGitLabApiRequest(client.GetClient(), http.MethodPost, wikisUrl, token, map[string]string{"title": title, "content": ReplaceWikiSummarySection("", summaryContent)})
+		return
+	}
+	var page gitLabWikiPage
+	if err = json.Unmarshal(body, &page); err != nil {
+		return
+	}
+	updatedContent := ReplaceWikiSummarySection(page.Content, summaryContent)
+	if updatedContent == page.Content {
+		log.Info("The wiki page", title, "is up to date")
+		return
+	}
+	log.Info("Updating the wiki page", title)
+	_, _, err = sendGitLabApiRequest(client.GetClient(), http.MethodPut, pageUrl, token, map[string]string{"content": updatedContent})
+	return
+}
+
+// Sends a request to the GitLab REST API and returns the body and the status code of the response.
+// If requestBody isn't nil, it is sent as JSON.
+func sendGitLabApiRequest(client *http.Client, method, url, token string, requestBody any) (body []byte, statusCode int, err error) {
+	var content io.Reader
+	if requestBody != nil {
+		var requestContent []byte
+		if requestContent, err = json.Marshal(requestBody); err != nil {
+			return
+		}
+		content = bytes.NewReader(requestContent)
+	}
+	req, err := http.NewRequest(method, url, content)
+	if err != nil {
+		return
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+	if requestBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	log.Debug(fmt.Sprintf("Sending HTTP %s request to: '%s'", req.Method, req.URL))
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return body, resp.StatusCode, fmt.Errorf("server response: %s\n%s", resp.Status, body)
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/froggit-go/vcsclient"
+	"github.com/jfrog/froggit-go/vcsutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceWikiSummarySection(t *testing.T) {
+	const manualContent = "# Security Posture\n\nOwned by the platform team."
+	// The section is appended to a page without a Frogbot section
+	pageContent := ReplaceWikiSummarySection(manualContent, "Opened 1 pull request\n")
+	assert.Equal(t, manualContent+"\n\n[comment]: <> (FrogbotRunSummaryStart)\nOpened 1 pull request\n\n\n[comment]: <> (FrogbotRunSummaryEnd)\n", pageContent)
+
+	// Only the delimited section is replaced, and the content around it is preserved
+	pageContent += "\n## Exceptions\n\nlodash is accepted until Q3.\n"
+	updatedContent := ReplaceWikiSummarySection(pageContent, "Opened 2 pull requests\n")
+	assert.Equal(t, manualContent+"\n\n[comment]: <> (FrogbotRunSummaryStart)\nOpened 2 pull requests\n\n\n[comment]: <> (FrogbotRunSummaryEnd)\n\n## Exceptions\n\nlodash is accepted until Q3.\n", updatedContent)
+	assert.NotContains(t, updatedContent, "Opened 1 pull request")
+
+	// Replacing the section with the same summary leaves the page as is
+	assert.Equal(t, updatedContent, ReplaceWikiSummarySection(updatedContent, "Opened 2 pull requests\n"))
+}
+
+func TestUpdateWikiPageWithRunSummary(t *testing.T) {
+	const pageContent = "Owned by the platform team.\n\n[comment]: <> (FrogbotRunSummaryStart)\nOutdated summary\n\n[comment]: <> (FrogbotRunSummaryEnd)\nManual notes\n"
+	var updatedContent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "123456", r.Header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "/projects/jfrog%2Ffrogbot/wikis/Security-Posture", r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			content, err := json.Marshal(gitLabWikiPage{Content: pageContent})
+			assert.NoError(t, err)
+			_, err = w.Write(content)
+			assert.NoError(t, err)
+		case http.MethodPut:
+			var page gitLabWikiPage
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&page))
+			updatedContent = page.Content
+			_, err := w.Write([]byte("{}"))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "unexpected request", "%s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	summary := &RunSummary{}
+	summary.AddPullRequest("jfrog/frogbot", "[🐸 Frogbot] Update version of minimist to 1.2.6", "https://gitlab.com/jfrog/frogbot/-/merge_requests/1", false)
+	repository := &Repository{Params: Params{
+		Git:  Git{GitProvider: vcsutils.GitLab, VcsInfo: vcsclient.VcsInfo{APIEndpoint: server.URL, Token: "123456"}, RepoOwner: "jfrog", RepoName: "frogbot"},
+		Scan: Scan{NotificationsDetails: NotificationsDetails{UpdateWikiPage: "Security Posture"}},
+	}}
+	require.NoError(t, UpdateWikiPageWithRunSummary(repository, summary))
+	assert.True(t, strings.HasPrefix(updatedContent, "Owned by the platform team.\n\n[comment]: <> (FrogbotRunSummaryStart)\n## 🐸 Frogbot Run Summary"))
+	assert.Contains(t, updatedContent, "- [[🐸 Frogbot] Update version of minimist to 1.2.6](https://gitlab.com/jfrog/frogbot/-/merge_requests/1) (jfrog/frogbot, opened)")
+	assert.NotContains(t, updatedContent, "Outdated summary")
+	assert.True(t, strings.HasSuffix(updatedContent, "[comment]: <> (FrogbotRunSummaryEnd)\nManual notes\n"))
+
+	// Wikis aren't updated on the providers without a wiki API
+	repository.GitProvider = vcsutils.GitHub
+	updatedContent = ""
+	require.NoError(t, UpdateWikiPageWithRunSummary(repository, summary))
+	assert.Empty(t, updatedContent)
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+// WorkingDirAudit holds the details the audit of a working directory runs with, so it can run in a process of its own and be killed if it hangs
+type WorkingDirAudit struct {
+	WorkingDir string
+	// The SBOM whose components are scanned instead of the dependencies of the working directory, if provided
+	InputSbom           string
+	Project             *Project
+	XrayGraphScanParams *services.XrayGraphScanParams
+	ServerDetails       *config.ServerDetails
+	FixableOnly         bool
+	MinSeverityFilter   severityutils.Severity
+	ScanPhaseOrder      ScanPhaseOrder
+	EarlyExitOnCritical bool
+}
+
+type workingDirAuditResults struct {
+	Results *xrayutils.Results
+	Error   string
+}
+
+func (sc *ScanDetails) NewWorkingDirAudit(workingDir, inputSbom string) *WorkingDirAudit {
+	return &WorkingDirAudit{
+		WorkingDir:          workingDir,
+		InputSbom:           inputSbom,
+		Project:             sc.Project,
+		XrayGraphScanParams: sc.XrayGraphScanParams,
+		ServerDetails:       sc.ServerDetails,
+		FixableOnly:         sc.fixableOnly,
+		MinSeverityFilter:   sc.minSeverityFilter,
+		ScanPhaseOrder:      sc.scanPhaseOrder,
+		EarlyExitOnCritical: sc.earlyExitOnCritical,
+	}
+}
+
+// Run audits the dependencies of the working directory, or the components of the input SBOM if provided
+func (wda *WorkingDirAudit) Run() (*xrayutils.Results, error) {
+	scanDetails := &ScanDetails{
+		Project:             wda.Project,
+		XrayGraphScanParams: wda.XrayGraphScanParams,
+		ServerDetails:       wda.ServerDetails,
+		fixableOnly:         wda.FixableOnly,
+		minSeverityFilter:   wda.MinSeverityFilter,
+		scanPhaseOrder:      wda.ScanPhaseOrder,
+		earlyExitOnCritical: wda.EarlyExitOnCritical,
+	}
+	if wda.InputSbom != "" {
+		log.Info("Scanning the components of the SBOM at", wda.InputSbom, "instead of resolving the dependencies of the project")
+		return scanDetails.RunSbomScan(wda.InputSbom, wda.WorkingDir)
+	}
+	return scanDetails.RunInstallAndAudit(wda.WorkingDir)
+}
+
+// RunWorkingDirAuditProcess runs the audit of the working directory that is described in the audit file, and writes its results to the results file.
+// It's the entry point of the process the audit runs in when the scans of the working directories are limited by a timeout.
+func RunWorkingDirAuditProcess(auditFile, resultsFile string) error {
+	workingDirAudit, err := ReadWorkingDirAudit(auditFile)
+	if err != nil {
+		return err
+	}
+	results, auditErr := workingDirAudit.Run()
+	return WriteWorkingDirAuditResults(resultsFile, results, auditErr)
+}
+
+func WriteWorkingDirAudit(auditFile string, workingDirAudit *WorkingDirAudit) error {
+	content, err := json.Marshal(workingDirAudit)
+	if err != nil {
+		return err
+	}
+	// The file holds the credentials of the server
+	return os.WriteFile(auditFile, content, 0600)
+}
+
+func ReadWorkingDirAudit(auditFile string) (*WorkingDirAudit, error) {
+	content, err := os.ReadFile(auditFile)
+	if err != nil {
+		return nil, err
+	}
+	workingDirAudit := &WorkingDirAudit{}
+	if err = json.Unmarshal(content, workingDirAudit); err != nil {
+		return nil, fmt.Errorf("failed to parse the audit of the working directory: %s", err.Error())
+	}
+	return workingDirAudit, nil
+}
+
+// WriteWorkingDirAuditResults writes the results of the audit along with its error, as the results of a partial scan come with an error
+func WriteWorkingDirAuditResults(resultsFile string, results *xrayutils.Results, auditErr error) error {
+	auditResults := workingDirAuditResults{Results: results}
+	if auditErr != nil {
+		auditResults.Error = auditErr.Error()
+	}
+	if results != nil {
+		// The error is written along with the results, as errors aren't serializable
+		results.ScansErr = nil
+	}
+	content, err := json.Marshal(auditResults)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(resultsFile, content, 0600)
+}
+
+func ReadWorkingDirAuditResults(resultsFile string) (*xrayutils.Results, error) {
+	content, err := os.ReadFile(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+	var auditResults workingDirAuditResults
+	if err = json.Unmarshal(content, &auditResults); err != nil {
+		return nil, fmt.Errorf("failed to parse the results of the audit of the working directory: %s", err.Error())
+	}
+	if auditResults.Results != nil && auditResults.Results.ExtendedScanResults == nil {
+		auditResults.Results.ExtendedScanResults = &xrayutils.ExtendedScanResults{}
+	}
+	if auditResults.Error != "" {
+		return auditResults.Results, errors.New(auditResults.Error)
+	}
+	return auditResults.Results, nil
+}
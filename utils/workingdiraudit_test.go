@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	xrayutils "github.com/jfrog/jfrog-cli-security/utils"
+	"github.com/jfrog/jfrog-cli-security/utils/severityutils"
+	"github.com/jfrog/jfrog-cli-security/utils/techutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingDirAuditFiles(t *testing.T) {
+	scanDetails, err := NewScanDetails(nil, &config.ServerDetails{XrayUrl: "https://myjfrog.jfrog.io/xray/"}, &Git{}).
+		SetProject(&Project{WorkingDirs: []string{"a"}, InstallCommandName: "npm", InstallCommandArgs: []string{"ci"}}).
+		SetXrayGraphScanParams([]string{"watch"}, "", false).
+		SetFixableOnly(true).
+		SetScanPhases(string(JasFirstScanPhaseOrder), true).
+		SetMinSeverity("High")
+	require.NoError(t, err)
+	auditFile := filepath.Join(t.TempDir(), "audit.json")
+	require.NoError(t, WriteWorkingDirAudit(auditFile, scanDetails.NewWorkingDirAudit("/repo/a", "sbom.json")))
+
+	// The audit runs in another process with the details of the scan
+	workingDirAudit, err := ReadWorkingDirAudit(auditFile)
+	require.NoError(t, err)
+	assert.Equal(t, &WorkingDirAudit{
+		WorkingDir:          "/repo/a",
+		InputSbom:           "sbom.json",
+		Project:             scanDetails.Project,
+		XrayGraphScanParams: scanDetails.XrayGraphScanParams,
+		ServerDetails:       scanDetails.ServerDetails,
+		FixableOnly:         true,
+		MinSeverityFilter:   severityutils.High,
+		ScanPhaseOrder:      JasFirstScanPhaseOrder,
+		EarlyExitOnCritical: true,
+	}, workingDirAudit)
+
+	// The results of a partial scan are returned along with its error
+	results := xrayutils.NewAuditResults()
+	results.ScaResults = []*xrayutils.ScaScanResult{{Target: "/repo/a", Technology: techutils.Npm}}
+	results.ScansErr = errors.New("scan failed")
+	resultsFile := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, WriteWorkingDirAuditResults(resultsFile, results, errors.New("scan failed")))
+	readResults, err := ReadWorkingDirAuditResults(resultsFile)
+	assert.EqualError(t, err, "scan failed")
+	require.NotNil(t, readResults)
+	assert.Equal(t, results.ScaResults, readResults.ScaResults)
+	assert.NotNil(t, readResults.ExtendedScanResults)
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	npmWorkspaceDescriptor  = "package.json"
+	pnpmWorkspaceDescriptor = "pnpm-workspace.yaml"
+	goWorkspaceDescriptor   = "go.work"
+	goModuleDescriptor      = "go.mod"
+	// A workspace pattern that ends with this suffix matches the nested directories at any depth
+	recursiveWorkspacePatternSuffix = "/**"
+)
+
+// GetWorkspacePackages returns the names of the packages that are maintained in the workspaces of the project in the given directory.
+// The workspaces are declared in the workspaces field of package.json (npm and Yarn), in pnpm-workspace.yaml and in the use directives of go.work.
+// These packages are developed in the repository rather than installed from a registry, so their vulnerabilities can't be fixed by a version bump.
+func GetWorkspacePackages(dir string) (packages []string, err error) {
+	npmPatterns, err := getNpmWorkspacePatterns(dir)
+	if err != nil {
+		return
+	}
+	pnpmPatterns, err := getPnpmWorkspacePatterns(dir)
+	if err != nil {
+		return
+	}
+	for _, workspaceDir := range resolveWorkspacePatterns(dir, append(npmPatterns, pnpmPatterns...)) {
+		var packageName string
+		if packageName, err = getNpmPackageName(workspaceDir); err != nil {
+			return
+		}
+		if packageName != "" && !slices.Contains(packages, packageName) {
+			packages = append(packages, packageName)
+		}
+	}
+	goModules, err := getGoWorkspaceModules(dir)
+	if err != nil {
+		return
+	}
+	packages = append(packages, goModules...)
+	if len(packages) > 0 {
+		log.Debug(fmt.Sprintf("Found the following workspace packages in '%s': %s", dir, strings.Join(packages, ", ")))
+	}
+	return
+}
+
+// ExcludeWorkspacePackages removes the vulnerabilities of the workspace packages
+func ExcludeWorkspacePackages(vulnerabilities []formats.VulnerabilityOrViolationRow, workspacePackages []string) []formats.VulnerabilityOrViolationRow {
+	if len(workspacePackages) == 0 {
+		return vulnerabilities
+	}
+	var included []formats.VulnerabilityOrViolationRow
+	for _, vulnerability := range vulnerabilities {
+		if slices.Contains(workspacePackages, vulnerability.ImpactedDependencyName) {
+			log.Debug(fmt.Sprintf("Skipping '%s:%s' (%s), as it is a workspace package that is maintained in the repository", vulnerability.ImpactedDependencyName, vulnerability.ImpactedDependencyVersion, GetVulnerabiltiesUniqueID(vulnerability)))
+			continue
+		}
+		included = append(included, vulnerability)
+	}
+	return included
+}
+
+// The workspaces field of package.json is either a list of patterns, or an object with a packages list of patterns (Yarn Classic)
+func getNpmWorkspacePatterns(dir string) ([]string, error) {
+	content, err := readWorkspaceDescriptor(filepath.Join(dir, npmWorkspaceDescriptor))
+	if err != nil || content == nil {
+		return nil, err
+	}
+	var descriptor struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err = json.Unmarshal(content, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", npmWorkspaceDescriptor, err.Error())
+	}
+	if len(descriptor.Workspaces) == 0 {
+		return nil, nil
+	}
+	var patterns []string
+	if json.Unmarshal(descriptor.Workspaces, &patterns) == nil {
+		return patterns, nil
+	}
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+	if err = json.Unmarshal(descriptor.Workspaces, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse the workspaces of %s: %s", npmWorkspaceDescriptor, err.Error())
+	}
+	return workspaces.Packages, nil
+}
+
+func getPnpmWorkspacePatterns(dir string) ([]string, error) {
+	content, err := readWorkspaceDescriptor(filepath.Join(dir, pnpmWorkspaceDescriptor))
+	if err != nil || content == nil {
+		return nil, err
+	}
+	var descriptor struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err = yaml.Unmarshal(content, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", pnpmWorkspaceDescriptor, err.Error())
+	}
+	return descriptor.Packages, nil
+}
+
+// Returns the directories that match the workspace patterns. Patterns that start with '!' exclude the directories they match.
+func resolveWorkspacePatterns(dir string, patterns []string) (workspaceDirs []string) {
+	var excludedDirs []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+		if excludedPattern, isExclusion := strings.CutPrefix(pattern, "!"); isExclusion {
+			excludedDirs = append(excludedDirs, matchWorkspacePattern(dir, strings.TrimPrefix(excludedPattern, "./"))...)
+			continue
+		}
+		workspaceDirs = append(workspaceDirs, matchWorkspacePattern(dir, pattern)...)
+	}
+	return slices.DeleteFunc(workspaceDirs, func(workspaceDir string) bool {
+		return slices.Contains(excludedDirs, workspaceDir)
+	})
+}
+
+func matchWorkspacePattern(dir, pattern string) (matches []string) {
+	if pattern == "" {
+		return
+	}
+	if rootPattern, isRecursive := strings.CutSuffix(pattern, recursiveWorkspacePatternSuffix); isRecursive {
+		roots, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(rootPattern)))
+		for _, root := range roots {
+			_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil || !entry.IsDir() {
+					return nil
+				}
+				if entry.Name() == "node_modules" {
+					return filepath.SkipDir
+				}
+				matches = append(matches, path)
+				return nil
+			})
+		}
+		return
+	}
+	// A malformed pattern doesn't match any directory
+	matches, _ = filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+	return
+}
+
+// Returns the name in the package.json of the directory, or an empty string if it has none
+func getNpmPackageName(dir string) (string, error) {
+	content, err := readWorkspaceDescriptor(filepath.Join(dir, npmWorkspaceDescriptor))
+	if err != nil || content == nil {
+		return "", err
+	}
+	var descriptor struct {
+		Name string `json:"name"`
+	}
+	if err = json.Unmarshal(content, &descriptor); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %s", filepath.Join(dir, npmWorkspaceDescriptor), err.Error())
+	}
+	return descriptor.Name, nil
+}
+
+// Returns the paths of the modules in the use directives of go.work
+func getGoWorkspaceModules(dir string) (modules []string, err error) {
+	content, err := readWorkspaceDescriptor(filepath.Join(dir, goWorkspaceDescriptor))
+	if err != nil || content == nil {
+		return
+	}
+	for _, moduleDir := range parseGoDirectives(content, "use") {
+		var moduleContent []byte
+		if moduleContent, err = readWorkspaceDescriptor(filepath.Join(dir, filepath.FromSlash(moduleDir), goModuleDescriptor)); err != nil {
+			return
+		}
+		if modulePaths := parseGoDirectives(moduleContent, "module"); len(modulePaths) > 0 {
+			modules = append(modules, modulePaths[0])
+		}
+	}
+	return
+}
+
+// Returns the arguments of the directive, whether it is declared in a single line or in a block, such as 'use ./a' and 'use (\n./a\n./b\n)'
+func parseGoDirectives(content []byte, directive string) (arguments []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	inBlock := false
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			arguments = append(arguments, strings.Trim(line, `"`))
+		case strings.HasPrefix(line, directive+" ") || strings.HasPrefix(line, directive+"("):
+			argument := strings.TrimSpace(strings.TrimPrefix(line, directive))
+			if argument == "(" {
+				inBlock = true
+			} else if argument != "" {
+				arguments = append(arguments, strings.Trim(argument, `"`))
+			}
+		}
+	}
+	return
+}
+
+// Returns the content of the descriptor, or nil if it doesn't exist
+func readWorkspaceDescriptor(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %s", path, err.Error())
+	}
+	return content, nil
+}
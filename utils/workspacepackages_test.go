@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-security/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWorkspacePackages(t *testing.T) {
+	testCases := []struct {
+		name             string
+		files            map[string]string
+		expectedPackages []string
+	}{
+		{
+			name:  "No workspaces",
+			files: map[string]string{"package.json": `{"name": "app", "dependencies": {"minimist": "1.2.5"}}`},
+		},
+		{
+			name: "npm workspaces",
+			files: map[string]string{
+				"package.json":                  `{"name": "monorepo", "workspaces": ["packages/*", "!packages/legacy", "tools/**"]}`,
+				"packages/ui/package.json":      `{"name": "@acme/ui"}`,
+				"packages/api/package.json":     `{"name": "@acme/api"}`,
+				"packages/legacy/package.json":  `{"name": "@acme/legacy"}`,
+				"tools/lint/rules/package.json": `{"name": "@acme/lint-rules"}`,
+			},
+			expectedPackages: []string{"@acme/api", "@acme/ui", "@acme/lint-rules"},
+		},
+		{
+			name: "Yarn Classic workspaces",
+			files: map[string]string{
+				"package.json":             `{"name": "monorepo", "workspaces": {"packages": ["packages/*"], "nohoist": ["**/react"]}}`,
+				"packages/ui/package.json": `{"name": "@acme/ui"}`,
+			},
+			expectedPackages: []string{"@acme/ui"},
+		},
+		{
+			name: "pnpm workspaces",
+			files: map[string]string{
+				"pnpm-workspace.yaml":      "packages:\n  - 'packages/*'\n",
+				"packages/ui/package.json": `{"name": "@acme/ui"}`,
+			},
+			expectedPackages: []string{"@acme/ui"},
+		},
+		{
+			name: "Go workspaces",
+			files: map[string]string{
+				"go.work":       "go 1.22\n\nuse (\n\t./api // The API server\n\t./lib\n)\n",
+				"api/go.mod":    "module github.com/acme/api\n\ngo 1.22\n",
+				"lib/go.mod":    "module github.com/acme/lib\n\ngo 1.22\n",
+				"unused/go.mod": "module github.com/acme/unused\n\ngo 1.22\n",
+			},
+			expectedPackages: []string{"github.com/acme/api", "github.com/acme/lib"},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+			}
+			packages, err := GetWorkspacePackages(dir)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.expectedPackages, packages)
+		})
+	}
+}
+
+func TestExcludeWorkspacePackages(t *testing.T) {
+	vulnerabilities := []formats.VulnerabilityOrViolationRow{
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "@acme/ui", ImpactedDependencyVersion: "1.0.0"}},
+		{ImpactedDependencyDetails: formats.ImpactedDependencyDetails{ImpactedDependencyName: "minimist", ImpactedDependencyVersion: "1.2.5"}},
+	}
+	assert.Equal(t, vulnerabilities, ExcludeWorkspacePackages(vulnerabilities, nil))
+	assert.Equal(t, vulnerabilities[1:], ExcludeWorkspacePackages(vulnerabilities, []string{"@acme/ui"}))
+}